@@ -0,0 +1,105 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// apiHeadersTransport adds the headers of the instance and the user agent to every provider API request
+type apiHeadersTransport struct {
+	base http.RoundTripper
+	// headers of the self-hosted instances, by host name, so they are never sent to another host
+	hostHeaders map[string]map[string]string
+	userAgent   string
+}
+
+func (t *apiHeadersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the original request
+	req = req.Clone(req.Context())
+	for name, value := range t.hostHeaders[strings.ToLower(req.URL.Hostname())] {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
+
+// newAPIClient creates the HTTP client used for the provider API calls (but not for the Git transport)
+func newAPIClient(globalConfig *GlobalConfig) *http.Client {
+	return &http.Client{
+		Transport: &apiHeadersTransport{
+			base:        &tokenRotationTransport{base: http.DefaultTransport, tokenFiles: globalConfig.tokenFiles},
+			hostHeaders: globalConfig.apiHeaders,
+			userAgent:   getUserAgent(globalConfig),
+		},
+	}
+}
+
+// publicProviderAPIURLs are the APIs of the services hosted by their vendors, receiving the global headers
+var publicProviderAPIURLs = []string{
+	"https://gitlab.com",
+	"https://api.github.com",
+	"https://dev.azure.com",
+	bitbucketAPIURL,
+}
+
+// resolveAPIHeaders reads the global headers and the ones of the self-hosted instances (their values can be paths of
+// files with them), registered as secrets so they are never logged, and returns them by host name. The global headers
+// are sent to the APIs of every provider, the public ones and the configured instances, which override them.
+func resolveAPIHeaders(
+	globalConfig *GlobalConfig, maxFileSize int64, strict bool,
+) (map[string]map[string]string, error) {
+	hostHeaders := make(map[string]map[string]string)
+	addHeaders := func(headers map[string]string, rawURLs ...string) error {
+		resolved := make(map[string]string, len(headers))
+		for name, value := range headers {
+			if _, err := handleTokenFile(name+" header", &value, maxFileSize, strict); err != nil {
+				return err
+			}
+			resolved[name] = value
+			registerSecret(value)
+		}
+
+		for _, rawURL := range rawURLs {
+			// the invalid URLs are reported when the instances are loaded
+			parsedURL, err := parseProviderURL(rawURL)
+			if len(resolved) == 0 || err != nil {
+				continue
+			}
+			host := strings.ToLower(parsedURL.Hostname())
+			if hostHeaders[host] == nil {
+				hostHeaders[host] = make(map[string]string)
+			}
+			maps.Copy(hostHeaders[host], resolved)
+		}
+		return nil
+	}
+
+	gitHubEnterprise := globalConfig.GitHubEnterprise
+	providerURLs := append(slices.Clone(publicProviderAPIURLs), gitHubEnterprise.BaseURL, gitHubEnterprise.APIURL)
+	for _, provider := range globalConfig.Providers {
+		providerURLs = append(providerURLs, provider.BaseURL)
+	}
+	if err := addHeaders(globalConfig.APIHeaders, providerURLs...); err != nil {
+		return nil, err
+	}
+
+	for _, provider := range globalConfig.Providers {
+		if err := addHeaders(provider.APIHeaders, provider.BaseURL); err != nil {
+			return nil, err
+		}
+	}
+	if err := addHeaders(gitHubEnterprise.APIHeaders, gitHubEnterprise.BaseURL, gitHubEnterprise.APIURL); err != nil {
+		return nil, err
+	}
+	return hostHeaders, nil
+}
+
+// getUserAgent returns the configured user agent, identifying the AutoBump version by default
+func getUserAgent(globalConfig *GlobalConfig) string {
+	if globalConfig.UserAgent != "" {
+		return globalConfig.UserAgent
+	}
+	return "autobump/" + version
+}
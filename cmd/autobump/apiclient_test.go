@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIClient_SendsHeadersAndUserAgent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	globalConfig := &GlobalConfig{
+		UserAgent:  "company-automation/1.0",
+		apiHeaders: map[string]map[string]string{"127.0.0.1": {"X-Api-Key": "gateway-key"}},
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "go-gitlab")
+
+	// Act
+	resp, err := newAPIClient(globalConfig).Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "gateway-key", received.Get("X-Api-Key"))
+	assert.Equal(t, "company-automation/1.0", received.Get("User-Agent"))
+	assert.Equal(t, "go-gitlab", req.Header.Get("User-Agent"), "the original request must not be modified")
+}
+
+func TestNewAPIClient_KeepsHeadersToTheirHost(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	globalConfig := &GlobalConfig{
+		apiHeaders: map[string]map[string]string{"gitlab.mycompany.com": {"X-Api-Key": "gateway-key"}},
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	// Act
+	resp, err := newAPIClient(globalConfig).Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Empty(t, received.Get("X-Api-Key"))
+	assert.Equal(t, "autobump/"+version, received.Get("User-Agent"))
+}
+
+func TestResolveAPIHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	keyPath := filepath.Join(t.TempDir(), "gateway_api_key")
+	require.NoError(t, os.WriteFile(keyPath, []byte("file-gateway-key\n"), 0o600))
+	globalConfig := &GlobalConfig{
+		Providers: []ProviderConfig{
+			{
+				Type:       "gitlab",
+				BaseURL:    "https://GitLab.mycompany.com/gitlab",
+				APIHeaders: map[string]string{"X-Api-Key": keyPath},
+			},
+			{Type: "gitlab", BaseURL: "https://tools.mycompany.com"},
+		},
+		GitHubEnterprise: GitHubEnterpriseConfig{
+			BaseURL:    "https://github.mycompany.com",
+			APIURL:     "https://api.github.mycompany.com",
+			APIHeaders: map[string]string{"X-Gateway": "github-gateway-key"},
+		},
+	}
+
+	// Act
+	hostHeaders, err := resolveAPIHeaders(globalConfig, defaultMaxFileSize, false)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"gitlab.mycompany.com":     {"X-Api-Key": "file-gateway-key"},
+		"github.mycompany.com":     {"X-Gateway": "github-gateway-key"},
+		"api.github.mycompany.com": {"X-Gateway": "github-gateway-key"},
+	}, hostHeaders)
+	assert.Equal(t, keyPath, globalConfig.Providers[0].APIHeaders["X-Api-Key"], "the configuration must not be modified")
+}
+
+func TestResolveAPIHeaders_GlobalHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{
+		APIHeaders: map[string]string{"X-Api-Key": "global-key", "X-Team": "platform"},
+		Providers: []ProviderConfig{
+			{Type: "gitlab", BaseURL: "https://gitlab.mycompany.com", APIHeaders: map[string]string{"X-Api-Key": "gitlab-key"}},
+		},
+		GitHubEnterprise: GitHubEnterpriseConfig{BaseURL: "https://github.mycompany.com"},
+	}
+
+	// Act
+	hostHeaders, err := resolveAPIHeaders(globalConfig, defaultMaxFileSize, false)
+
+	// Assert
+	require.NoError(t, err)
+	globalHeaders := map[string]string{"X-Api-Key": "global-key", "X-Team": "platform"}
+	assert.Equal(t, map[string]map[string]string{
+		"gitlab.com":           globalHeaders,
+		"api.github.com":       globalHeaders,
+		"dev.azure.com":        globalHeaders,
+		"api.bitbucket.org":    globalHeaders,
+		"github.mycompany.com": globalHeaders,
+		"gitlab.mycompany.com": {"X-Api-Key": "gitlab-key", "X-Team": "platform"},
+	}, hostHeaders)
+}
+
+func TestGetUserAgent_Default(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	userAgent := getUserAgent(&GlobalConfig{})
+
+	// Assert
+	assert.Equal(t, "autobump/"+version, userAgent)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// changelogArchiveSuffix is added to the name of the CHANGELOG to name its archive (e.g. "CHANGELOG-archive.md")
+const changelogArchiveSuffix = "-archive"
+
+var ErrInvalidChangelogArchive = errors.New("invalid changelog archive settings")
+
+var (
+	// archiveAgeRegex matches the age of the archived releases (e.g. "90d", "6w", "18m" or "2y")
+	archiveAgeRegex = regexp.MustCompile(`^(\d+)([dwmy])$`)
+	// linkDefinitionRegex matches the reference-style link definitions, capturing their label
+	linkDefinitionRegex = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*\S`)
+)
+
+// changelogArchive is the content moved from the CHANGELOG into its archive
+type changelogArchive struct {
+	lines    []string // new content of the archive
+	releases []string // versions moved into the archive, newest first
+}
+
+// validateChangelogArchive checks the thresholds of the releases kept in the CHANGELOG
+func validateChangelogArchive(changelogConfig ChangelogConfig) error {
+	if changelogConfig.MaxReleasesInMainFile < 0 {
+		return fmt.Errorf(
+			"%w: max_releases_in_main_file must be positive, got %d",
+			ErrInvalidChangelogArchive, changelogConfig.MaxReleasesInMainFile,
+		)
+	}
+	if changelogConfig.ArchiveReleasesOlderThan != "" {
+		if _, err := getArchiveCutoff(changelogConfig.ArchiveReleasesOlderThan, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isChangelogArchiveEnabled checks whether the old releases are moved out of the CHANGELOG
+func isChangelogArchiveEnabled(changelogConfig ChangelogConfig) bool {
+	return changelogConfig.MaxReleasesInMainFile > 0 || changelogConfig.ArchiveReleasesOlderThan != ""
+}
+
+// getArchiveCutoff returns the date before which the releases are archived, given their age (e.g. "2y")
+func getArchiveCutoff(age string, releaseDate time.Time) (time.Time, error) {
+	match := archiveAgeRegex.FindStringSubmatch(age)
+	if match == nil {
+		return time.Time{}, fmt.Errorf(
+			"%w: archive_releases_older_than %q (expected a number of days, weeks, months or years, e.g. 2y)",
+			ErrInvalidChangelogArchive, age,
+		)
+	}
+
+	amount, err := strconv.Atoi(match[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: archive_releases_older_than %q: %w", ErrInvalidChangelogArchive, age, err)
+	}
+	switch match[2] {
+	case "d":
+		return releaseDate.AddDate(0, 0, -amount), nil
+	case "w":
+		return releaseDate.AddDate(0, 0, -7*amount), nil
+	case "m":
+		return releaseDate.AddDate(0, -amount, 0), nil
+	default:
+		return releaseDate.AddDate(-amount, 0, 0), nil
+	}
+}
+
+// getChangelogArchivePath returns the path of the archive next to the CHANGELOG
+func getChangelogArchivePath(changelogPath string) string {
+	extension := filepath.Ext(changelogPath)
+	return strings.TrimSuffix(changelogPath, extension) + changelogArchiveSuffix + extension
+}
+
+// archiveChangelogReleases moves the old releases of the new CHANGELOG content into its archive, which is written
+// right away and recorded to be staged in the bump commit. The CHANGELOG content is returned without them.
+func archiveChangelogReleases(ctx *RepoContext, changelogPath string, lines []string) ([]string, error) {
+	changelogConfig := getChangelogConfig(ctx)
+	if !isChangelogArchiveEnabled(changelogConfig) {
+		return lines, nil
+	}
+
+	archivePath := getChangelogArchivePath(changelogPath)
+	var archiveLines []string
+	if _, err := os.Stat(archivePath); err == nil {
+		archiveLines, err = readLines(archivePath, getMaxFileSize(ctx.globalConfig))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lines, archive, err := archiveReleases(lines, archiveLines, changelogConfig, filepath.Base(changelogPath))
+	if err != nil {
+		return nil, err
+	}
+	if len(archive.releases) == 0 {
+		return lines, nil
+	}
+	ctx.logger().Infof("Moving %d releases into %s", len(archive.releases), filepath.Base(archivePath))
+	err = writeLines(archivePath, archive.lines)
+	if err != nil {
+		return nil, err
+	}
+	ctx.state.changelogArchive = archivePath
+	ctx.state.archivedReleases = archive.releases
+	return lines, nil
+}
+
+// archiveReleases moves the releases beyond the thresholds, verbatim, from the CHANGELOG lines into the archive
+// lines, along with the link definitions they use. The cut happens at the first release to be archived, so the
+// releases below it are archived too, and the releases already in the archive are never added twice.
+func archiveReleases(
+	lines []string,
+	archiveLines []string,
+	changelogConfig ChangelogConfig,
+	changelogName string,
+) ([]string, changelogArchive, error) {
+	frontMatter, body := splitFrontMatter(lines)
+	footerStart := getChangelogFooterStart(body)
+
+	cut, err := findArchiveCut(body[:footerStart], changelogConfig)
+	if err != nil || cut < 0 {
+		return lines, changelogArchive{}, err
+	}
+
+	moved := trimBlankLines(body[cut:footerStart])
+	kept := trimBlankLines(body[:cut])
+	footer := body[footerStart:]
+
+	archive := changelogArchive{lines: addToArchive(archiveLines, moved, footer, changelogName)}
+	for _, line := range moved {
+		if isReleaseHeader(line) {
+			archive.releases = append(archive.releases, versionHeaderRegex.FindStringSubmatch(line)[1])
+		}
+	}
+
+	// the definitions are only removed when the moved releases were the last ones using them
+	var newFooter []string
+	for _, line := range footer {
+		match := linkDefinitionRegex.FindStringSubmatch(line)
+		if match != nil && usesLinkLabel(moved, match[1]) && !usesLinkLabel(kept, match[1]) {
+			continue
+		}
+		newFooter = append(newFooter, line)
+	}
+	archiveLink := getArchiveLink(filepath.Base(getChangelogArchivePath(changelogName)))
+	if !slices.Contains(newFooter, archiveLink) {
+		newFooter = append([]string{archiveLink, ""}, trimBlankLines(newFooter)...)
+	}
+
+	newLines := slices.Concat(frontMatter, kept, []string{""}, trimBlankLines(newFooter))
+	return newLines, archive, nil
+}
+
+// findArchiveCut returns the position of the first release to be archived, or -1 when all of them are kept
+func findArchiveCut(lines []string, changelogConfig ChangelogConfig) (int, error) {
+	var cutoff time.Time
+	if changelogConfig.ArchiveReleasesOlderThan != "" {
+		var err error
+		cutoff, err = getArchiveCutoff(changelogConfig.ArchiveReleasesOlderThan, changelogConfig.Date)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	releases := 0
+	for index, line := range lines {
+		if !isReleaseHeader(line) {
+			continue
+		}
+		releases++
+		if changelogConfig.MaxReleasesInMainFile > 0 && releases > changelogConfig.MaxReleasesInMainFile {
+			return index, nil
+		}
+		if match := releaseDateRegex.FindStringSubmatch(line); match != nil && !cutoff.IsZero() {
+			date, err := time.Parse("2006-01-02", match[1])
+			if err == nil && date.Before(cutoff) {
+				return index, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// getChangelogFooterStart returns the position of the footer of the CHANGELOG: the link definitions
+// and the link to the archive at the end of the file
+func getChangelogFooterStart(lines []string) int {
+	start := len(lines)
+	for index := len(lines) - 1; index >= 0; index-- {
+		line := lines[index]
+		if strings.TrimSpace(line) != "" && !linkDefinitionRegex.MatchString(line) && !isArchiveLink(line) {
+			break
+		}
+		if strings.TrimSpace(line) != "" {
+			start = index
+		}
+	}
+	return start
+}
+
+// addToArchive adds the moved releases above the releases of the archive (created when it doesn't exist yet),
+// skipping the ones already there (e.g. by an interrupted run), and carries the link definitions they use
+// from the CHANGELOG footer
+func addToArchive(archiveLines, moved, changelogFooter []string, changelogName string) []string {
+	if len(archiveLines) == 0 {
+		archiveLines = []string{
+			"# Archived Changelog",
+			"",
+			fmt.Sprintf("The releases moved out of [%s](%s), to keep it short.", changelogName, changelogName),
+		}
+	}
+
+	var sections []string
+	skipping := false
+	for _, line := range moved {
+		if isReleaseHeader(line) {
+			skipping = slices.Contains(archiveLines, line)
+		}
+		if !skipping {
+			sections = append(sections, line)
+		}
+	}
+	sections = trimBlankLines(sections)
+	if len(sections) == 0 {
+		return archiveLines
+	}
+
+	footerStart := getChangelogFooterStart(archiveLines)
+	head := archiveLines[:footerStart]
+	firstRelease := slices.IndexFunc(head, isReleaseHeader)
+	if firstRelease < 0 {
+		firstRelease = len(head)
+	}
+
+	footer := slices.Clone(archiveLines[footerStart:])
+	for _, line := range changelogFooter {
+		match := linkDefinitionRegex.FindStringSubmatch(line)
+		if match != nil && usesLinkLabel(sections, match[1]) && !slices.Contains(footer, line) {
+			footer = append(footer, line)
+		}
+	}
+
+	newLines := slices.Concat(trimBlankLines(head[:firstRelease]), []string{""}, sections)
+	if previous := trimBlankLines(head[firstRelease:]); len(previous) > 0 {
+		newLines = slices.Concat(newLines, []string{""}, previous)
+	}
+	if len(footer) > 0 {
+		newLines = slices.Concat(newLines, []string{""}, trimBlankLines(footer))
+	}
+	return newLines
+}
+
+// usesLinkLabel checks whether the lines refer to the link label (e.g. "[1.0.0]" or "[notes][1.0.0]")
+func usesLinkLabel(lines []string, label string) bool {
+	reference := "[" + strings.ToLower(label) + "]"
+	for _, line := range lines {
+		if linkDefinitionRegex.MatchString(line) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(line), reference) {
+			return true
+		}
+	}
+	return false
+}
+
+// getArchiveLink returns the line of the CHANGELOG footer linking the archive
+func getArchiveLink(archiveName string) string {
+	return fmt.Sprintf("The older releases are in [%s](%s).", archiveName, archiveName)
+}
+
+// isArchiveLink checks whether the line is the link to the archive written in the CHANGELOG footer
+func isArchiveLink(line string) bool {
+	return strings.HasPrefix(line, "The older releases are in [") && strings.Contains(line, changelogArchiveSuffix)
+}
+
+// trimBlankLines removes the blank lines at the start and at the end of the lines
+func trimBlankLines(lines []string) []string {
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[start:end]
+}
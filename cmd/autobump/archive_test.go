@@ -0,0 +1,227 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogLongHistory = changelogTemplate + `
+
+## [1.3.0] - 2026-06-01
+
+### Added
+
+- Added the [export][exports] of the reports.
+
+## [1.2.0] - 2025-09-01
+
+### Fixed
+
+- Fixed the login redirection.
+
+## [1.1.0] - 2023-05-01
+
+### Added
+
+- Added the [dashboards][dashboards].
+
+## [1.0.0] - 2022-01-01
+
+### Added
+
+- Added the login.
+
+[Unreleased]: https://github.com/acme/app/compare/1.3.0...HEAD
+[1.3.0]: https://github.com/acme/app/compare/1.2.0...1.3.0
+[1.2.0]: https://github.com/acme/app/compare/1.1.0...1.2.0
+[1.1.0]: https://github.com/acme/app/compare/1.0.0...1.1.0
+[1.0.0]: https://github.com/acme/app/releases/tag/1.0.0
+[exports]: https://docs.acme.com/exports
+[dashboards]: https://docs.acme.com/dashboards`
+
+func TestArchiveReleases_CutsAtTheMaximumOfReleases(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogLongHistory, "\n")
+	changelogConfig := ChangelogConfig{MaxReleasesInMainFile: 2}
+
+	// Act
+	newLines, archive, err := archiveReleases(lines, nil, changelogConfig, "CHANGELOG.md")
+
+	// Assert
+	require.NoError(t, err)
+	content := strings.Join(newLines, "\n")
+	assert.Equal(t, []string{"1.1.0", "1.0.0"}, archive.releases)
+	assert.Contains(t, content, "## [1.2.0] - 2025-09-01\n\n### Fixed\n\n- Fixed the login redirection.\n\n"+
+		"The older releases are in [CHANGELOG-archive.md](CHANGELOG-archive.md).\n\n[Unreleased]:")
+	assert.NotContains(t, content, "## [1.1.0]")
+	assert.NotContains(t, content, "[dashboards]:")
+	assert.Contains(t, content, "[1.2.0]: https://github.com/acme/app/compare/1.1.0...1.2.0")
+	assert.Contains(t, content, "[exports]: https://docs.acme.com/exports")
+}
+
+func TestArchiveReleases_CutsAtTheAgeOfReleases(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogLongHistory, "\n")
+	changelogConfig := ChangelogConfig{
+		ArchiveReleasesOlderThan: "2y",
+		Date:                     time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	_, archive, err := archiveReleases(lines, nil, changelogConfig, "CHANGELOG.md")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.1.0", "1.0.0"}, archive.releases)
+}
+
+func TestArchiveReleases_CarriesTheLinks(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogLongHistory, "\n")
+	changelogConfig := ChangelogConfig{MaxReleasesInMainFile: 2}
+
+	// Act
+	_, archive, err := archiveReleases(lines, nil, changelogConfig, "CHANGELOG.md")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, `# Archived Changelog
+
+The releases moved out of [CHANGELOG.md](CHANGELOG.md), to keep it short.
+
+## [1.1.0] - 2023-05-01
+
+### Added
+
+- Added the [dashboards][dashboards].
+
+## [1.0.0] - 2022-01-01
+
+### Added
+
+- Added the login.
+
+[1.1.0]: https://github.com/acme/app/compare/1.0.0...1.1.0
+[1.0.0]: https://github.com/acme/app/releases/tag/1.0.0
+[dashboards]: https://docs.acme.com/dashboards`, strings.Join(archive.lines, "\n"))
+}
+
+func TestArchiveReleases_IsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogLongHistory, "\n")
+	changelogConfig := ChangelogConfig{MaxReleasesInMainFile: 2}
+	firstLines, firstArchive, err := archiveReleases(lines, nil, changelogConfig, "CHANGELOG.md")
+	require.NoError(t, err)
+
+	// Act
+	secondLines, secondArchive, secondErr := archiveReleases(
+		firstLines, firstArchive.lines, changelogConfig, "CHANGELOG.md",
+	)
+	interruptedLines, interruptedArchive, interruptedErr := archiveReleases(
+		lines, firstArchive.lines, changelogConfig, "CHANGELOG.md",
+	)
+
+	// Assert
+	require.NoError(t, secondErr)
+	require.NoError(t, interruptedErr)
+	assert.Equal(t, firstLines, secondLines)
+	assert.Empty(t, secondArchive.releases)
+	assert.Equal(t, firstLines, interruptedLines)
+	assert.Equal(t, firstArchive.lines, interruptedArchive.lines)
+}
+
+func TestArchiveReleases_AddsAboveTheArchivedReleases(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogLongHistory, "\n")
+	_, firstArchive, err := archiveReleases(lines, nil, ChangelogConfig{MaxReleasesInMainFile: 2}, "CHANGELOG.md")
+	require.NoError(t, err)
+
+	// Act
+	_, archive, archiveErr := archiveReleases(
+		lines, firstArchive.lines, ChangelogConfig{MaxReleasesInMainFile: 1}, "CHANGELOG.md",
+	)
+
+	// Assert
+	require.NoError(t, archiveErr)
+	content := strings.Join(archive.lines, "\n")
+	assert.Equal(t, []string{"1.2.0", "1.1.0", "1.0.0"}, archive.releases)
+	assert.Less(t, strings.Index(content, "## [1.2.0]"), strings.Index(content, "## [1.1.0]"))
+	assert.Equal(t, 1, strings.Count(content, "## [1.1.0]"))
+	assert.Contains(t, content, "[1.2.0]: https://github.com/acme/app/compare/1.1.0...1.2.0")
+}
+
+func TestArchiveChangelogReleases_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	lines := strings.Split(changelogLongHistory, "\n")
+	ctx := &RepoContext{globalConfig: &GlobalConfig{}, projectConfig: &ProjectConfig{Path: dir}}
+
+	// Act
+	newLines, err := archiveChangelogReleases(ctx, changelogPath, lines)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, lines, newLines)
+	assert.NoFileExists(t, filepath.Join(dir, "CHANGELOG-archive.md"))
+	assert.Empty(t, ctx.state.changelogArchive)
+}
+
+func TestArchiveChangelogReleases_WritesTheArchive(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	lines := strings.Split(changelogLongHistory, "\n")
+	globalConfig := &GlobalConfig{Changelog: ChangelogConfig{MaxReleasesInMainFile: 3}}
+	ctx := &RepoContext{globalConfig: globalConfig, projectConfig: &ProjectConfig{Path: dir}}
+
+	// Act
+	_, err := archiveChangelogReleases(ctx, changelogPath, lines)
+
+	// Assert
+	require.NoError(t, err)
+	content, readErr := os.ReadFile(filepath.Join(dir, "CHANGELOG-archive.md"))
+	require.NoError(t, readErr)
+	assert.Contains(t, string(content), "## [1.0.0] - 2022-01-01")
+	assert.Equal(t, filepath.Join(dir, "CHANGELOG-archive.md"), ctx.state.changelogArchive)
+	assert.Equal(t, []string{"1.0.0"}, ctx.state.archivedReleases)
+}
+
+func TestValidateChangelogArchive(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	valid := ChangelogConfig{ArchiveReleasesOlderThan: "18m", MaxReleasesInMainFile: 50}
+	invalidAge := ChangelogConfig{ArchiveReleasesOlderThan: "two years"}
+	negativeMaximum := ChangelogConfig{MaxReleasesInMainFile: -1}
+
+	// Act
+	validErr := validateChangelogArchive(valid)
+	invalidAgeErr := validateChangelogArchive(invalidAge)
+	negativeMaximumErr := validateChangelogArchive(negativeMaximum)
+
+	// Assert
+	require.NoError(t, validErr)
+	require.ErrorIs(t, invalidAgeErr, ErrInvalidChangelogArchive)
+	require.ErrorIs(t, negativeMaximumErr, ErrInvalidChangelogArchive)
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	authProviderGitLab = "gitlab"
+	authProviderGitHub = "github"
+
+	defaultGitLabHost = "gitlab.com"
+	defaultGitHubHost = "github.com"
+
+	// tokens expiring within this margin are renewed before being used
+	tokenExpiryMargin = time.Minute
+	// maximum time waiting for the user to authorize the device
+	deviceFlowTimeout = 15 * time.Minute
+)
+
+var (
+	ErrUnknownAuthProvider = errors.New("unknown authentication provider")
+	ErrMissingClientID     = errors.New("missing OAuth application client ID")
+)
+
+// StoredCredential is a token obtained with the OAuth device flow
+type StoredCredential struct {
+	Host         string    `json:"host"`
+	ClientID     string    `json:"client_id"`
+	TokenURL     string    `json:"token_url"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// getHostBaseURL returns the base URL of the host, HTTPS unless the scheme is given
+func getHostBaseURL(host string) string {
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return strings.TrimSuffix(host, "/")
+	}
+	return "https://" + strings.TrimSuffix(host, "/")
+}
+
+// parseAuthProvider returns the provider of the stored credentials named on the command line,
+// by its name or one of its aliases (e.g. "gh")
+func parseAuthProvider(name string) (string, error) {
+	serviceType, err := parseServiceType(name)
+	if err != nil {
+		return "", err
+	}
+
+	provider := serviceType.String()
+	if provider != authProviderGitLab && provider != authProviderGitHub {
+		return "", fmt.Errorf(
+			"%w: %s (supported: %s, %s)", ErrUnknownAuthProvider, provider, authProviderGitLab, authProviderGitHub,
+		)
+	}
+	return provider, nil
+}
+
+// getDefaultAuthHost returns the public host of the provider
+func getDefaultAuthHost(provider string) string {
+	if provider == authProviderGitHub {
+		return defaultGitHubHost
+	}
+	return defaultGitLabHost
+}
+
+// getOAuthConfig returns the OAuth device flow settings of the provider on the given host
+func getOAuthConfig(provider, host, clientID string) (*oauth2.Config, error) {
+	if clientID == "" {
+		return nil, ErrMissingClientID
+	}
+
+	switch provider {
+	case authProviderGitLab:
+		baseURL := getHostBaseURL(host)
+		return &oauth2.Config{
+			ClientID: clientID,
+			Endpoint: oauth2.Endpoint{
+				DeviceAuthURL: baseURL + "/oauth/authorize_device",
+				TokenURL:      baseURL + "/oauth/token",
+			},
+			Scopes: []string{"api", "write_repository"},
+		}, nil
+	case authProviderGitHub:
+		baseURL := getHostBaseURL(host)
+		return &oauth2.Config{
+			ClientID: clientID,
+			Endpoint: oauth2.Endpoint{
+				DeviceAuthURL: baseURL + "/login/device/code",
+				TokenURL:      baseURL + "/login/oauth/access_token",
+			},
+			Scopes: []string{"repo"},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAuthProvider, provider)
+	}
+}
+
+// loginWithDeviceFlow authenticates with the OAuth device flow and stores the token of the provider on the host
+func loginWithDeviceFlow(
+	ctx context.Context,
+	provider, host, clientID string,
+	store credentialStore,
+	output io.Writer,
+) error {
+	if host == "" {
+		host = getDefaultAuthHost(provider)
+	}
+
+	oauthConfig, err := getOAuthConfig(provider, host, clientID)
+	if err != nil {
+		return err
+	}
+
+	deviceAuth, err := oauthConfig.DeviceAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start the device authorization: %w", err)
+	}
+
+	verificationURI := deviceAuth.VerificationURI
+	if deviceAuth.VerificationURIComplete != "" {
+		verificationURI = deviceAuth.VerificationURIComplete
+	}
+	fmt.Fprintf(output, "Open %s and enter the code: %s\n", verificationURI, deviceAuth.UserCode)
+	fmt.Fprintln(output, "Waiting for the authorization...")
+
+	token, err := oauthConfig.DeviceAccessToken(ctx, deviceAuth)
+	if err != nil {
+		return fmt.Errorf("failed to get the access token: %w", err)
+	}
+	registerSecret(token.AccessToken)
+	registerSecret(token.RefreshToken)
+
+	credentials, err := store.read()
+	if err != nil {
+		return err
+	}
+	credentials[getCredentialKey(provider, host)] = StoredCredential{
+		Host:         host,
+		ClientID:     clientID,
+		TokenURL:     oauthConfig.Endpoint.TokenURL,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+	err = store.write(credentials)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(output, "Logged in to %s, the credential was stored in %s\n", host, store)
+	return nil
+}
+
+// loadStoredToken returns the stored access token of the provider on the host, renewing it when expired
+func loadStoredToken(ctx context.Context, provider, host string, store credentialStore) (string, error) {
+	credentials, err := store.read()
+	if err != nil {
+		return "", err
+	}
+
+	key := getCredentialKey(provider, host)
+	credential, found := credentials[key]
+	if !found {
+		return "", nil
+	}
+	registerSecret(credential.AccessToken)
+	registerSecret(credential.RefreshToken)
+
+	expired := !credential.Expiry.IsZero() && time.Now().Add(tokenExpiryMargin).After(credential.Expiry)
+	if expired && credential.RefreshToken != "" {
+		log.Infof("Renewing the expired %s access token of %s", provider, credential.Host)
+		oauthConfig := &oauth2.Config{
+			ClientID: credential.ClientID,
+			Endpoint: oauth2.Endpoint{TokenURL: credential.TokenURL},
+		}
+
+		var token *oauth2.Token
+		token, err = oauthConfig.TokenSource(ctx, &oauth2.Token{
+			RefreshToken: credential.RefreshToken,
+			Expiry:       credential.Expiry,
+		}).Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to renew the %s access token: %w", provider, err)
+		}
+		registerSecret(token.AccessToken)
+		registerSecret(token.RefreshToken)
+
+		credential.AccessToken = token.AccessToken
+		if token.RefreshToken != "" {
+			credential.RefreshToken = token.RefreshToken
+		}
+		credential.Expiry = token.Expiry
+		credentials[key] = credential
+
+		err = store.write(credentials)
+		if err != nil {
+			return "", err
+		}
+	} else if expired {
+		log.Warnf("The stored %s access token of %s has expired, run \"autobump auth %s\"",
+			provider, credential.Host, provider)
+	}
+
+	return credential.AccessToken, nil
+}
+
+// resolveStoredToken fills the token with the stored credential of the provider on the host when it isn't configured
+func resolveStoredToken(provider, host string, token *string) {
+	if *token != "" {
+		return
+	}
+
+	store, err := openDefaultCredentialStore()
+	if err != nil {
+		log.Debugf("Skipping the stored credentials: %v", err)
+		return
+	}
+
+	storedToken, err := loadStoredToken(context.Background(), provider, host, store)
+	if err != nil {
+		log.Errorf("failed to load the stored %s credential of %s: %v", provider, host, err)
+		return
+	}
+	if storedToken != "" {
+		log.Infof("Using the stored %s credential of %s", provider, host)
+		*token = storedToken
+	}
+}
+
+// resolveStoredTokens fills the tokens of GitLab and GitHub which aren't configured with the stored credentials
+// of their instances: the first self-hosted GitLab of "providers" (gitlab.com without any), github.com,
+// and the GitHub Enterprise Server instance
+func resolveStoredTokens(globalConfig *GlobalConfig) {
+	gitLabHost := defaultGitLabHost
+	for _, provider := range globalConfig.Providers {
+		if serviceType, err := parseServiceType(provider.Type); err == nil && serviceType == GITLAB {
+			gitLabHost = provider.BaseURL
+			break
+		}
+	}
+	resolveStoredToken(authProviderGitLab, gitLabHost, &globalConfig.GitLabAccessToken)
+	resolveStoredToken(authProviderGitHub, defaultGitHubHost, &globalConfig.GitHubAccessToken)
+	if globalConfig.GitHubEnterprise.BaseURL != "" {
+		resolveStoredToken(
+			authProviderGitHub, globalConfig.GitHubEnterprise.BaseURL, &globalConfig.GitHubEnterprise.AccessToken,
+		)
+	}
+}
+
+// writeAuthStatus writes the providers and hosts with stored credentials, without the tokens
+func writeAuthStatus(store credentialStore, output io.Writer) error {
+	credentials, err := store.read()
+	if err != nil {
+		return err
+	}
+
+	if len(credentials) == 0 {
+		fmt.Fprintln(output, "Not logged in to any provider")
+		return nil
+	}
+
+	keys := make([]string, 0, len(credentials))
+	for key := range credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		credential := credentials[key]
+		provider, _, _ := strings.Cut(key, "@")
+		status := "no expiry"
+		if !credential.Expiry.IsZero() {
+			if time.Now().After(credential.Expiry) {
+				status = "expired"
+				if credential.RefreshToken != "" {
+					status += ", renewed on the next use"
+				}
+			} else {
+				status = "expires at " + credential.Expiry.Format(time.RFC3339)
+			}
+		}
+		fmt.Fprintf(output, "%s: logged in to %s (%s)\n", provider, credential.Host, status)
+	}
+	return nil
+}
+
+// logout removes the stored credential of the provider on the host, all the credentials of the provider
+// when no host is given, or all of them when no provider is given
+func logout(store credentialStore, provider, host string, output io.Writer) error {
+	credentials, err := store.read()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for key := range credentials {
+		keyProvider, _, _ := strings.Cut(key, "@")
+		if provider == "" || (keyProvider == provider && (host == "" || key == getCredentialKey(provider, host))) {
+			delete(credentials, key)
+			removed++
+		}
+	}
+
+	target := provider
+	if host != "" {
+		target += " on " + host
+	}
+	if removed == 0 && provider != "" {
+		fmt.Fprintf(output, "Not logged in to %s\n", target)
+		return nil
+	}
+
+	err = store.write(credentials)
+	if err != nil {
+		return err
+	}
+
+	if provider == "" {
+		fmt.Fprintln(output, "Logged out of all providers")
+	} else {
+		fmt.Fprintf(output, "Logged out of %s\n", target)
+	}
+	return nil
+}
+
+// openDefaultCredentialStore opens the store of the credentials of the current user
+func openDefaultCredentialStore() (credentialStore, error) {
+	credentialsDir, err := getCredentialsDir()
+	if err != nil {
+		return nil, err
+	}
+	return openCredentialStore(credentialsDir)
+}
+
+// runDeviceFlowLogin runs the device flow of the provider, waiting for the user at most deviceFlowTimeout
+func runDeviceFlowLogin(provider, host, clientID string, output io.Writer) error {
+	store, err := openDefaultCredentialStore()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
+	defer cancel()
+	return loginWithDeviceFlow(ctx, provider, host, clientID, store, output)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeviceFlowServer mocks the GitLab device flow, answering "authorization_pending" to the first poll
+func newDeviceFlowServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var tokenRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/authorize_device", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "client-id", r.FormValue("client_id"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "device-code",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": "https://gitlab.example.com/oauth/device",
+			"expires_in":       300,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("grant_type") == "refresh_token" {
+			assert.Equal(t, "refresh-token", r.FormValue("refresh_token"))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token":  "renewed-token",
+				"refresh_token": "renewed-refresh-token",
+				"token_type":    "Bearer",
+				"expires_in":    7200,
+			})
+			return
+		}
+
+		assert.Equal(t, "device-code", r.FormValue("device_code"))
+		if tokenRequests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": "authorization_pending"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access-token",
+			"refresh_token": "refresh-token",
+			"token_type":    "Bearer",
+			"expires_in":    7200,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &tokenRequests
+}
+
+// newCredentialsFile returns a store of the credentials in a temporary encrypted file
+func newCredentialsFile(t *testing.T) encryptedFileStore {
+	t.Helper()
+
+	return encryptedFileStore{
+		path:       filepath.Join(t.TempDir(), "autobump", credentialsFileName),
+		passphrase: "test-credentials-passphrase",
+	}
+}
+
+func TestLoginWithDeviceFlow_StoresToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, tokenRequests := newDeviceFlowServer(t)
+	store := newCredentialsFile(t)
+	var output bytes.Buffer
+
+	// Act
+	err := loginWithDeviceFlow(context.Background(), authProviderGitLab, server.URL, "client-id", store, &output)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "https://gitlab.example.com/oauth/device")
+	assert.Contains(t, output.String(), "ABCD-EFGH")
+	assert.NotContains(t, output.String(), "access-token")
+	assert.Equal(t, int32(2), tokenRequests.Load())
+
+	data, err := os.ReadFile(store.path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "access-token")
+	info, err := os.Stat(store.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	credentials, err := store.read()
+	require.NoError(t, err)
+	credential := credentials[getCredentialKey(authProviderGitLab, server.URL)]
+	assert.Equal(t, "access-token", credential.AccessToken)
+	assert.Equal(t, "refresh-token", credential.RefreshToken)
+	assert.Equal(t, server.URL+"/oauth/token", credential.TokenURL)
+}
+
+func TestLoginWithDeviceFlow_MissingClientID(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	store := newCredentialsFile(t)
+
+	// Act
+	err := loginWithDeviceFlow(context.Background(), authProviderGitLab, "", "", store, &bytes.Buffer{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrMissingClientID)
+	assert.NoFileExists(t, store.path)
+}
+
+func TestGetOAuthConfig_UnknownProvider(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := getOAuthConfig("bitbucket", "bitbucket.org", "client-id")
+
+	// Assert
+	require.ErrorIs(t, err, ErrUnknownAuthProvider)
+}
+
+func TestParseAuthProvider(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	github, githubErr := parseAuthProvider("GH")
+	gitlab, gitlabErr := parseAuthProvider("Git-Lab")
+	_, unsupportedErr := parseAuthProvider("ado")
+	_, unknownErr := parseAuthProvider("gitlub")
+
+	// Assert
+	require.NoError(t, githubErr)
+	assert.Equal(t, authProviderGitHub, github)
+	require.NoError(t, gitlabErr)
+	assert.Equal(t, authProviderGitLab, gitlab)
+	require.ErrorIs(t, unsupportedErr, ErrUnknownAuthProvider)
+	require.ErrorIs(t, unknownErr, ErrUnknownService)
+}
+
+func TestGetOAuthConfig_GitHubEndpoints(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	oauthConfig, err := getOAuthConfig(authProviderGitHub, defaultGitHubHost, "client-id")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/login/device/code", oauthConfig.Endpoint.DeviceAuthURL)
+	assert.Equal(t, "https://github.com/login/oauth/access_token", oauthConfig.Endpoint.TokenURL)
+}
+
+func TestLoadStoredToken_RenewsExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, _ := newDeviceFlowServer(t)
+	store := newCredentialsFile(t)
+	key := getCredentialKey(authProviderGitLab, server.URL)
+	require.NoError(t, store.write(map[string]StoredCredential{
+		key: {
+			Host:         server.URL,
+			ClientID:     "client-id",
+			TokenURL:     server.URL + "/oauth/token",
+			AccessToken:  "expired-token",
+			RefreshToken: "refresh-token",
+			Expiry:       time.Now().Add(-time.Hour),
+		},
+	}))
+
+	// Act
+	token, err := loadStoredToken(context.Background(), authProviderGitLab, server.URL, store)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "renewed-token", token)
+
+	credentials, err := store.read()
+	require.NoError(t, err)
+	assert.Equal(t, "renewed-refresh-token", credentials[key].RefreshToken)
+	assert.True(t, credentials[key].Expiry.After(time.Now()))
+}
+
+func TestLoadStoredToken_ValidToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	store := newCredentialsFile(t)
+	require.NoError(t, store.write(map[string]StoredCredential{
+		"github@github.com":        {Host: defaultGitHubHost, AccessToken: "github-token"},
+		"github@github.company.io": {Host: "https://github.company.io", AccessToken: "enterprise-token"},
+	}))
+
+	// Act
+	token, err := loadStoredToken(context.Background(), authProviderGitHub, defaultGitHubHost, store)
+	enterpriseToken, enterpriseErr := loadStoredToken(
+		context.Background(), authProviderGitHub, "https://GitHub.company.io/", store,
+	)
+	missingToken, missingErr := loadStoredToken(context.Background(), authProviderGitLab, defaultGitLabHost, store)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "github-token", token)
+	require.NoError(t, enterpriseErr)
+	assert.Equal(t, "enterprise-token", enterpriseToken)
+	require.NoError(t, missingErr)
+	assert.Empty(t, missingToken)
+}
+
+func TestWriteAuthStatus_HidesTokens(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	store := newCredentialsFile(t)
+	require.NoError(t, store.write(map[string]StoredCredential{
+		"gitlab@gitlab.company.io": {
+			Host:         "gitlab.company.io",
+			AccessToken:  "secret-access-token",
+			RefreshToken: "secret-refresh-token",
+			Expiry:       time.Now().Add(-time.Hour),
+		},
+		"github@github.com": {Host: defaultGitHubHost, AccessToken: "secret-github-token"},
+	}))
+	var output bytes.Buffer
+
+	// Act
+	err := writeAuthStatus(store, &output)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t,
+		"github: logged in to github.com (no expiry)\n"+
+			"gitlab: logged in to gitlab.company.io (expired, renewed on the next use)\n",
+		output.String(),
+	)
+	assert.NotContains(t, output.String(), "secret")
+}
+
+func TestLogout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider string
+		host     string
+		expected []string
+	}{
+		{
+			name:     "should remove the credentials of the provider on all its hosts",
+			provider: authProviderGitLab,
+			expected: []string{"github@github.com"},
+		},
+		{
+			name:     "should remove the credential of the provider on the host",
+			provider: authProviderGitLab,
+			host:     "https://gitlab.company.io",
+			expected: []string{"github@github.com", "gitlab@gitlab.com"},
+		},
+		{
+			name: "should remove all the credentials without a provider",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			store := newCredentialsFile(t)
+			require.NoError(t, store.write(map[string]StoredCredential{
+				"gitlab@gitlab.com":        {Host: defaultGitLabHost, AccessToken: "gitlab-token"},
+				"gitlab@gitlab.company.io": {Host: "gitlab.company.io", AccessToken: "company-token"},
+				"github@github.com":        {Host: defaultGitHubHost, AccessToken: "github-token"},
+			}))
+
+			// Act
+			err := logout(store, test.provider, test.host, &bytes.Buffer{})
+
+			// Assert
+			require.NoError(t, err)
+			credentials, err := store.read()
+			require.NoError(t, err)
+			keys := make([]string, 0, len(credentials))
+			for key := range credentials {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, test.expected, keys)
+		})
+	}
+}
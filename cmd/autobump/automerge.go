@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	// autoMergeTrain adds the merge request to the merge train, merged once its merged results pipeline succeeds
+	autoMergeTrain = "merge-train"
+	// autoMergeWhenPipelineSucceeds merges the merge request once its pipeline succeeds (or right away without any)
+	autoMergeWhenPipelineSucceeds = "merge-when-pipeline-succeeds"
+)
+
+// AutoMergeResult is how the pull request was set to be merged, written in the run report
+type AutoMergeResult struct {
+	Mechanism string `json:"mechanism"`
+	// whether a rebase was requested first, since the merge method of the repository requires it
+	RebaseRequested bool `json:"rebase_requested,omitempty"`
+	// preconditions of the merge which aren't met yet (e.g. the missing approvals), so the humans know why
+	// the pull request isn't merged
+	UnmetPreconditions []string `json:"unmet_preconditions,omitempty"`
+	// why the provider rejected the merge, when it did
+	Error string `json:"error,omitempty"`
+}
+
+// selectGitLabAutoMerge chooses how the merge request is merged from the merge settings of the project:
+// the merge train when enabled (requiring the merged results pipelines), and the merge when the pipeline succeeds
+// otherwise, rebasing first when the merge method requires a linear history
+func selectGitLabAutoMerge(project *gitlab.Project) AutoMergeResult {
+	if project.MergeTrainsEnabled && project.MergePipelinesEnabled {
+		return AutoMergeResult{Mechanism: autoMergeTrain}
+	}
+	return AutoMergeResult{
+		Mechanism:       autoMergeWhenPipelineSucceeds,
+		RebaseRequested: project.MergeMethod == gitlab.FastForwardMerge || project.MergeMethod == gitlab.RebaseMerge,
+	}
+}
+
+// getGitLabUnmetPreconditions returns the preconditions of the merge request which aren't met yet
+func getGitLabUnmetPreconditions(
+	project *gitlab.Project,
+	state *projectState,
+	mergeRequest *gitlab.MergeRequest,
+) []string {
+	var preconditions []string
+	if missing := state.pullRequestApprovals.getMissingApprovals(); missing > 0 {
+		preconditions = append(preconditions, fmt.Sprintf("%d approvals missing", missing))
+	}
+	if project.OnlyAllowMergeIfPipelineSucceeds {
+		switch {
+		case mergeRequest.HeadPipeline == nil:
+			preconditions = append(preconditions, "the pipeline must succeed, it isn't reported yet")
+		case mergeRequest.HeadPipeline.Status != "success":
+			preconditions = append(preconditions,
+				fmt.Sprintf("the pipeline must succeed, it is %s", mergeRequest.HeadPipeline.Status))
+		}
+	}
+	if project.OnlyAllowMergeIfAllDiscussionsAreResolved && state.lintSuggestions != nil {
+		preconditions = append(preconditions,
+			fmt.Sprintf("%d lint suggestions must be resolved", len(state.lintSuggestions.additions)))
+	}
+	return preconditions
+}
+
+// enableGitLabAutoMerge sets the merge request to be merged with the mechanism fitting the merge settings of
+// the project. It is best effort: the merge request already exists, so the rejections are only logged and reported.
+func enableGitLabAutoMerge(
+	gitlabClient *gitlab.Client,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	projectID int,
+	mergeRequest *gitlab.MergeRequest,
+	logger *log.Entry,
+) {
+	if !projectConfig.AutoMerge {
+		return
+	}
+
+	project, _, err := gitlabClient.Projects.GetProject(projectID, nil)
+	if err != nil {
+		logger.Warnf("Unable to read the merge settings of the project: %v", err)
+		state.autoMerge = &AutoMergeResult{Error: err.Error()}
+		return
+	}
+
+	result := selectGitLabAutoMerge(project)
+	result.UnmetPreconditions = getGitLabUnmetPreconditions(project, state, mergeRequest)
+	state.autoMerge = &result
+
+	if result.RebaseRequested {
+		_, err = gitlabClient.MergeRequests.RebaseMergeRequest(projectID, mergeRequest.IID, nil)
+		if err != nil {
+			logger.Warnf("Unable to rebase the merge request before merging it: %v", err)
+		}
+	}
+
+	switch result.Mechanism {
+	case autoMergeTrain:
+		_, _, err = gitlabClient.MergeTrains.AddMergeRequestToMergeTrain(
+			projectID, mergeRequest.IID, &gitlab.AddMergeRequestToMergeTrainOptions{WhenPipelineSucceeds: gitlab.Ptr(true)},
+		)
+	default:
+		_, _, err = gitlabClient.MergeRequests.AcceptMergeRequest(
+			projectID, mergeRequest.IID, &gitlab.AcceptMergeRequestOptions{MergeWhenPipelineSucceeds: gitlab.Ptr(true)},
+		)
+	}
+	if err != nil {
+		logger.Warnf("GitLab rejected the %s of the merge request: %v", result.Mechanism, err)
+		result.Error = err.Error()
+		return
+	}
+
+	logger.Infof("The merge request is set to be merged (%s)", result.Mechanism)
+	for _, precondition := range result.UnmetPreconditions {
+		logger.Infof("The merge request isn't merged until met: %s", precondition)
+	}
+}
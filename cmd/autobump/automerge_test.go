@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableGitLabAutoMerge_AddsToTheMergeTrain(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /projects/42": `{"id": 42, "merge_trains_enabled": true, "merge_pipelines_enabled": true,
+			"merge_method": "ff", "only_allow_merge_if_pipeline_succeeds": true}`,
+		"PUT /projects/42/merge_requests/3/rebase":        `{"rebase_in_progress": true}`,
+		"POST /projects/42/merge_trains/merge_requests/3": `[]`,
+	})
+	projectConfig := &ProjectConfig{
+		AutoMerge: true,
+	}
+	state := &projectState{pullRequestApprovals: &PullRequestApprovals{Required: 2}}
+
+	// Act
+	enableGitLabAutoMerge(client, projectConfig, state, 42, newGitLabMergeRequest(t, `{"iid": 3}`), standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 2, "the merge train rebases the merge requests by itself")
+	assert.Equal(t, "/api/v4/projects/42/merge_trains/merge_requests/3", requests[1].path)
+	assert.JSONEq(t, `{"when_pipeline_succeeds": true}`, requests[1].body)
+	assert.Equal(t, &AutoMergeResult{
+		Mechanism:          autoMergeTrain,
+		UnmetPreconditions: []string{"2 approvals missing", "the pipeline must succeed, it isn't reported yet"},
+	}, state.autoMerge)
+}
+
+func TestEnableGitLabAutoMerge_RebasesAndMergesWhenThePipelineSucceeds(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /projects/42": `{"id": 42, "merge_method": "rebase_merge", "only_allow_merge_if_pipeline_succeeds": true,
+			"only_allow_merge_if_all_discussions_are_resolved": true}`,
+		"PUT /projects/42/merge_requests/3/rebase": `{"rebase_in_progress": true}`,
+		"PUT /projects/42/merge_requests/3/merge":  `{"iid": 3, "merge_when_pipeline_succeeds": true}`,
+	})
+	projectConfig := &ProjectConfig{
+		AutoMerge: true,
+	}
+	state := &projectState{
+		lintSuggestions: &lintSuggestions{additions: []lintSuggestion{{Line: 9}}},
+	}
+	mergeRequest := newGitLabMergeRequest(t, `{"iid": 3, "head_pipeline": {"id": 1, "status": "running"}}`)
+
+	// Act
+	enableGitLabAutoMerge(client, projectConfig, state, 42, mergeRequest, standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "/api/v4/projects/42/merge_requests/3/rebase", requests[1].path)
+	assert.Equal(t, "/api/v4/projects/42/merge_requests/3/merge", requests[2].path)
+	assert.JSONEq(t, `{"merge_when_pipeline_succeeds": true}`, requests[2].body)
+	assert.Equal(t, &AutoMergeResult{
+		Mechanism:          autoMergeWhenPipelineSucceeds,
+		RebaseRequested:    true,
+		UnmetPreconditions: []string{"the pipeline must succeed, it is running", "1 lint suggestions must be resolved"},
+	}, state.autoMerge)
+}
+
+func TestEnableGitLabAutoMerge_ReportsTheRejection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /projects/42": `{"id": 42, "merge_method": "merge"}`,
+	})
+	projectConfig := &ProjectConfig{AutoMerge: true}
+	state := &projectState{}
+
+	// Act
+	enableGitLabAutoMerge(client, projectConfig, state, 42, newGitLabMergeRequest(t, `{"iid": 3}`), standardLogEntry())
+
+	// Assert
+	assert.Len(t, getRequests(), 2, "the merge method shouldn't request a rebase")
+	require.NotNil(t, state.autoMerge)
+	assert.Equal(t, autoMergeWhenPipelineSucceeds, state.autoMerge.Mechanism)
+	assert.False(t, state.autoMerge.RebaseRequested)
+	assert.Contains(t, state.autoMerge.Error, "404")
+}
+
+func TestEnableGitLabAutoMerge_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{})
+	projectConfig := &ProjectConfig{}
+	state := &projectState{}
+
+	// Act
+	enableGitLabAutoMerge(client, projectConfig, state, 42, newGitLabMergeRequest(t, `{"iid": 3}`), standardLogEntry())
+
+	// Assert
+	assert.Empty(t, getRequests())
+	assert.Nil(t, state.autoMerge)
+}
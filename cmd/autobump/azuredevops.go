@@ -9,17 +9,24 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	log "github.com/sirupsen/logrus"
 )
 
-const contextTimeout = 10
+// maximum time of the Azure DevOps API requests
+const contextTimeout = 10 * time.Second
+
+// azureDevOpsHost is the host of the Azure DevOps API
+const azureDevOpsHost = "dev.azure.com"
 
 var (
 	ErrUnknownURLType            = errors.New("unknown remote URL type")
 	ErrFailedToCreatePullRequest = errors.New("failed to create pull request")
+	ErrFailedToFetchRepository   = errors.New("failed to fetch repository info")
 )
 
 // AzureDevOpsInfo struct to hold organization, project, and repo info
@@ -29,20 +36,31 @@ type AzureDevOpsInfo struct {
 	RepositoryID     string
 }
 
-// RepoInfo struct to hold repository id answer
+// RepoInfo struct to hold repository id and default branch answer
 type RepoInfo struct {
-	ID string `json:"id"`
+	ID            string `json:"id"`
+	DefaultBranch string `json:"defaultBranch"`
+}
+
+// PullRequestInfo struct to hold the created pull request answer
+type PullRequestInfo struct {
+	ID int `json:"pullRequestId"`
 }
 
 // TODO: this should be better using an Adapter pattern (interface with many providers and implementing the methods)
+//
+// createAzureDevOpsPullRequest creates a new pull request on Azure DevOps, returning its URL
 func createAzureDevOpsPullRequest(
 	globalConfig *GlobalConfig,
 	projectConfig *ProjectConfig,
+	state *projectState,
 	repo *git.Repository,
 	sourceBranch string,
+	targetBranch string,
 	newVersion string,
-) error {
-	log.Info("Creating Azure DevOps pull request")
+	logger *log.Entry,
+) (string, error) {
+	logger.Info("Creating Azure DevOps pull request")
 
 	var personalAccessToken string
 	if projectConfig.ProjectAccessToken != "" {
@@ -51,70 +69,175 @@ func createAzureDevOpsPullRequest(
 		personalAccessToken = globalConfig.AzureDevOpsAccessToken
 	}
 
-	azureInfo, err := GetAzureDevOpsInfo(repo, personalAccessToken)
+	azureInfo, err := GetAzureDevOpsInfo(globalConfig, repo, personalAccessToken, logger)
 	if err != nil {
-		return err
-	}
-
-	// TODO: refactor to use this library: https://github.com/microsoft/azure-devops-go-api
-	url := fmt.Sprintf(
-		"https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullrequests?api-version=6.0",
-		azureInfo.OrganizationName,
-		azureInfo.ProjectName,
-		azureInfo.RepositoryID,
-	)
-	prTitle := "chore(bump): bumped version to " + newVersion
-	payload := map[string]interface{}{
-		"sourceRefName": "refs/heads/" + sourceBranch,
-		"targetRefName": "refs/heads/main",
-		"title":         prTitle,
-	}
-
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return "", err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payloadBytes))
+	req, err := buildAzureDevOpsPullRequestRequest(
+		ctx,
+		azureInfo,
+		personalAccessToken,
+		sourceBranch,
+		targetBranch,
+		getPullRequestTitle(globalConfig, projectConfig, state, newVersion, logger),
+		getPullRequestDescription(globalConfig, projectConfig, state, logger),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set(
-		"Authorization",
-		"Basic "+base64.StdEncoding.EncodeToString([]byte(":"+personalAccessToken)),
-	)
-
-	log.Infof("POST %s", url)
-	client := &http.Client{}
+	logger.Infof("POST %s", req.URL)
+	client := newAPIClient(globalConfig)
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to create pull request: %w", err)
+		return "", fmt.Errorf("failed to create pull request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf(
-			"%w: %d - %s",
-			ErrFailedToCreatePullRequest,
-			resp.StatusCode,
-			body,
+		err = fmt.Errorf("%w: %d - %s", ErrFailedToCreatePullRequest, resp.StatusCode, body)
+		// TF401027: the identity doesn't have the permission to contribute to pull requests
+		if bytes.Contains(body, []byte("TF401027")) {
+			return "", fmt.Errorf("%w: %w", ErrPullRequestForbidden, err)
+		}
+		return "", classifyPullRequestError(resp.StatusCode, err)
+	}
+
+	var pullRequest PullRequestInfo
+	_ = json.Unmarshal(body, &pullRequest)
+
+	logger.Info("Successfully created Azure DevOps pull request")
+	if pullRequest.ID == 0 {
+		return "", nil
+	}
+	postAzureDevOpsLintSuggestions(
+		globalConfig, projectConfig, state, azureInfo, personalAccessToken, pullRequest.ID, logger,
+	)
+	state.pullRequest = newAzureDevOpsPullRequestHandle(
+		globalConfig, azureInfo, personalAccessToken, pullRequest.ID,
+	)
+	remoteURL, _ := getRemoteRepoURL(repo)
+	return fmt.Sprintf("%s/pullrequest/%d", getRepositoryWebURL(remoteURL), pullRequest.ID), nil
+}
+
+// newAzureDevOpsPullRequestHandle reaches the pull request once created
+func newAzureDevOpsPullRequestHandle(
+	globalConfig *GlobalConfig,
+	azureInfo AzureDevOpsInfo,
+	personalAccessToken string,
+	pullRequestID int,
+) *pullRequestHandle {
+	client := newAPIClient(globalConfig)
+	send := func(method string, payload map[string]interface{}) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+		defer cancel()
+
+		req, err := buildAzureDevOpsPullRequestItemRequest(
+			ctx, azureInfo, personalAccessToken, pullRequestID, method, payload,
 		)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach the pull request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to reach the pull request: %d - %s", resp.StatusCode, body)
+		}
+		return body, nil
+	}
+
+	return &pullRequestHandle{
+		getMergeability: func() (string, error) {
+			body, err := send(http.MethodGet, nil)
+			if err != nil {
+				return "", err
+			}
+			var pullRequest struct {
+				MergeStatus string `json:"mergeStatus"`
+			}
+			err = json.Unmarshal(body, &pullRequest)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse the pull request: %w", err)
+			}
+			return getAzureDevOpsMergeability(pullRequest.MergeStatus), nil
+		},
+		update: func(title string, description string) error {
+			_, err := send(http.MethodPatch, map[string]interface{}{"title": title, "description": description})
+			return err
+		},
+		pollInterval: mergeabilityPollInterval,
+	}
+}
+
+// getAzureDevOpsMergeability reads the mergeability from the merge status of the pull request
+func getAzureDevOpsMergeability(mergeStatus string) string {
+	switch mergeStatus {
+	case "notSet", "queued":
+		return mergeabilityChecking
+	case "conflicts":
+		return mergeabilityConflicted
+	case "succeeded", "rejectedByPolicy":
+		return mergeabilityMergeable
+	default:
+		return mergeabilityUnknown
 	}
+}
+
+// buildAzureDevOpsPullRequestItemRequest builds the request reading the pull request (without payload)
+// or updating it, without sending it
+func buildAzureDevOpsPullRequestItemRequest(
+	ctx context.Context,
+	azureInfo AzureDevOpsInfo,
+	personalAccessToken string,
+	pullRequestID int,
+	method string,
+	payload map[string]interface{},
+) (*http.Request, error) {
+	url := fmt.Sprintf(
+		"https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%d?api-version=6.0",
+		azureInfo.OrganizationName,
+		azureInfo.ProjectName,
+		azureInfo.RepositoryID,
+		pullRequestID,
+	)
 
-	log.Info("Successfully created Azure DevOps pull request")
-	return nil
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewBuffer(payloadBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setAzureDevOpsAuthorization(req, personalAccessToken)
+	return req, nil
 }
 
 // GetAzureDevOpsInfo extracts organization, project, and repo information from the remote URL
 func GetAzureDevOpsInfo(
+	globalConfig *GlobalConfig,
 	repo *git.Repository,
 	personalAccessToken string,
+	logger *log.Entry,
 ) (AzureDevOpsInfo, error) {
 	var info AzureDevOpsInfo
 	remoteURL, err := getRemoteRepoURL(repo)
@@ -122,65 +245,283 @@ func GetAzureDevOpsInfo(
 		return info, err
 	}
 
-	var organizationName, projectName, repositoryName string
-	parts := strings.Split(remoteURL, "/")
-
-	switch {
-	case strings.HasPrefix(remoteURL, "git@"):
-		organizationName = parts[1]
-		projectName = parts[2]
-		repositoryName = parts[3]
-	case strings.HasPrefix(remoteURL, "https://"):
-		organizationName = parts[3]
-		projectName = parts[4]
-		repositoryName = parts[5]
-	default:
-		return info, fmt.Errorf("%w: %s", ErrUnknownURLType, remoteURL)
+	organizationName, projectName, repositoryName, err := parseAzureDevOpsURL(remoteURL)
+	if err != nil {
+		return info, err
 	}
 
-	// fetch repositoryId using Azure DevOps API
-	url := fmt.Sprintf(
-		"https://dev.azure.com/%s/%s/_apis/git/repositories/%s?api-version=6.0",
+	metadata, err := getAzureDevOpsRepoMetadata(
+		globalConfig,
+		newAPIClient(globalConfig),
 		organizationName,
 		projectName,
 		repositoryName,
+		personalAccessToken,
+		logger,
 	)
+	if err != nil {
+		return info, err
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
-	defer cancel()
+	return AzureDevOpsInfo{
+		OrganizationName: organizationName,
+		ProjectName:      projectName,
+		RepositoryID:     metadata.ID,
+	}, nil
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// getAzureDevOpsRepoMetadata returns the metadata of the repository, fetched once per run
+func getAzureDevOpsRepoMetadata(
+	globalConfig *GlobalConfig,
+	client *http.Client,
+	organizationName string,
+	projectName string,
+	repositoryName string,
+	personalAccessToken string,
+	logger *log.Entry,
+) (RepoMetadata, error) {
+	key := repoMetadataKey{
+		host:         azureDevOpsHost,
+		organization: organizationName,
+		project:      projectName,
+		repository:   repositoryName,
+	}
+	return globalConfig.repoMetadata.get(key, func() (RepoMetadata, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+		defer cancel()
+
+		// fetch repositoryId using Azure DevOps API
+		req, err := buildAzureDevOpsRepositoryRequest(
+			ctx,
+			organizationName,
+			projectName,
+			repositoryName,
+			personalAccessToken,
+		)
+		if err != nil {
+			return RepoMetadata{}, err
+		}
+
+		logger.Infof("GET %s", req.URL)
+		resp, err := client.Do(req)
+		if err != nil {
+			return RepoMetadata{}, fmt.Errorf("%w: %w", ErrFailedToFetchRepository, err)
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return RepoMetadata{}, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return RepoMetadata{}, classifyPullRequestError(
+				resp.StatusCode,
+				fmt.Errorf("%w: %d - %s", ErrFailedToFetchRepository, resp.StatusCode, bodyBytes),
+			)
+		}
+
+		var repoInfo RepoInfo
+		err = json.Unmarshal(bodyBytes, &repoInfo)
+		if err != nil {
+			return RepoMetadata{}, fmt.Errorf("failed to unmarshal response body: %w", err)
+		}
+		return RepoMetadata{
+			ID:            repoInfo.ID,
+			DefaultBranch: strings.TrimPrefix(repoInfo.DefaultBranch, "refs/heads/"),
+		}, nil
+	})
+}
+
+// buildAzureDevOpsPullRequestRequest builds the request creating the pull request, without sending it
+func buildAzureDevOpsPullRequestRequest(
+	ctx context.Context,
+	azureInfo AzureDevOpsInfo,
+	personalAccessToken string,
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) (*http.Request, error) {
+	// TODO: refactor to use this library: https://github.com/microsoft/azure-devops-go-api
+	url := fmt.Sprintf(
+		"https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullrequests?api-version=6.0",
+		azureInfo.OrganizationName,
+		azureInfo.ProjectName,
+		azureInfo.RepositoryID,
+	)
+	payload := map[string]interface{}{
+		"sourceRefName": "refs/heads/" + sourceBranch,
+		"targetRefName": "refs/heads/" + targetBranch,
+		"title":         title,
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+
+	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return info, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req.Header.Set(
-		"Authorization",
-		"Basic "+base64.StdEncoding.EncodeToString([]byte(":"+personalAccessToken)),
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	setAzureDevOpsAuthorization(req, personalAccessToken)
+	return req, nil
+}
+
+// postAzureDevOpsLintSuggestions starts a thread on each CHANGELOG line with a lint suggestion. Azure DevOps can't
+// apply the suggestions with one click, so the threads write the fixed line. The failures are only logged.
+func postAzureDevOpsLintSuggestions(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	azureInfo AzureDevOpsInfo,
+	personalAccessToken string,
+	pullRequestID int,
+	logger *log.Entry,
+) {
+	suggestions := state.lintSuggestions
+	if suggestions == nil {
+		return
+	}
+
+	client := newAPIClient(globalConfig)
+	for _, suggestion := range suggestions.additions {
+		err := func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+			defer cancel()
+
+			req, err := buildAzureDevOpsThreadRequest(
+				ctx, azureInfo, personalAccessToken, pullRequestID, suggestions.path, suggestion,
+			)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to create the thread: %w", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("failed to create the thread: %d - %s", resp.StatusCode, body)
+			}
+			return nil
+		}()
+		if err != nil {
+			logger.Warnf("Unable to suggest the fix of line %d of %s: %v", suggestion.Line, suggestions.path, err)
+		}
+	}
+}
+
+// buildAzureDevOpsThreadRequest builds the request starting a thread on a line of the pull request, without sending it
+func buildAzureDevOpsThreadRequest(
+	ctx context.Context,
+	azureInfo AzureDevOpsInfo,
+	personalAccessToken string,
+	pullRequestID int,
+	path string,
+	suggestion lintSuggestion,
+) (*http.Request, error) {
+	url := fmt.Sprintf(
+		"https://dev.azure.com/%s/%s/_apis/git/repositories/%s/pullRequests/%d/threads?api-version=6.0",
+		azureInfo.OrganizationName,
+		azureInfo.ProjectName,
+		azureInfo.RepositoryID,
+		pullRequestID,
 	)
+	payload := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{"parentCommentId": 0, "content": formatLintSuggestionThread(suggestion), "commentType": 1},
+		},
+		"status": "active",
+		"threadContext": map[string]interface{}{
+			"filePath":       "/" + path,
+			"rightFileStart": map[string]int{"line": suggestion.Line, "offset": 1},
+			"rightFileEnd":   map[string]int{"line": suggestion.Line, "offset": len(suggestion.Original) + 1},
+		},
+	}
 
-	log.Infof("GET %s", url)
-	resp, err := client.Do(req)
+	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return info, fmt.Errorf("failed to fetch repository info: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		return info, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	var repoInfo RepoInfo
-	err = json.Unmarshal(bodyBytes, &repoInfo)
+	req.Header.Set("Content-Type", "application/json")
+	setAzureDevOpsAuthorization(req, personalAccessToken)
+	return req, nil
+}
+
+// buildAzureDevOpsRepositoryRequest builds the request fetching the repository information, without sending it
+func buildAzureDevOpsRepositoryRequest(
+	ctx context.Context,
+	organizationName string,
+	projectName string,
+	repositoryName string,
+	personalAccessToken string,
+) (*http.Request, error) {
+	url := fmt.Sprintf(
+		"https://dev.azure.com/%s/%s/_apis/git/repositories/%s?api-version=6.0",
+		organizationName,
+		projectName,
+		repositoryName,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return info, fmt.Errorf("failed to unmarshal response body: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return AzureDevOpsInfo{
-		OrganizationName: organizationName,
-		ProjectName:      projectName,
-		RepositoryID:     repoInfo.ID,
-	}, nil
+	setAzureDevOpsAuthorization(req, personalAccessToken)
+	return req, nil
+}
+
+// setAzureDevOpsAuthorization authenticates the request with the personal access token
+func setAzureDevOpsAuthorization(req *http.Request, personalAccessToken string) {
+	req.Header.Set(
+		"Authorization",
+		"Basic "+base64.StdEncoding.EncodeToString([]byte(":"+personalAccessToken)),
+	)
+}
+
+// parseAzureDevOpsURL extracts the organization, project, and repository names from the remote URL
+func parseAzureDevOpsURL(remoteURL string) (string, string, string, error) {
+	var segments []string
+	switch {
+	case isSSHURL(remoteURL):
+		// e.g. git@ssh.dev.azure.com:v3/organization/project/repository
+		// or ssh://git@ssh.dev.azure.com:22/v3/organization/project/repository
+		_, path, found := parseSSHURL(remoteURL)
+		if !found {
+			return "", "", "", fmt.Errorf("%w: %s", ErrUnknownURLType, remoteURL)
+		}
+		segments = strings.Split(strings.TrimPrefix(path, "v3/"), "/")
+	case strings.HasPrefix(remoteURL, "https://"):
+		// e.g. https://dev.azure.com/organization/project/_git/repository
+		uri, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", "", fmt.Errorf("%w: %s", ErrUnknownURLType, remoteURL)
+		}
+		for _, segment := range strings.Split(strings.Trim(uri.Path, "/"), "/") {
+			if segment != "_git" {
+				segments = append(segments, segment)
+			}
+		}
+	default:
+		return "", "", "", fmt.Errorf("%w: %s", ErrUnknownURLType, remoteURL)
+	}
+
+	if len(segments) != 3 { //nolint:mnd // organization, project and repository
+		return "", "", "", fmt.Errorf("%w: %s", ErrUnknownURLType, remoteURL)
+	}
+	return segments[0], segments[1], segments[2], nil
 }
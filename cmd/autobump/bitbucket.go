@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// bitbucketAPIURL is the base URL of the Bitbucket Cloud REST API
+const bitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// BitbucketInfo identifies the repository in the Bitbucket API
+type BitbucketInfo struct {
+	Workspace      string
+	RepositorySlug string
+}
+
+// bitbucketPullRequest is the part of a Bitbucket pull request read by AutoBump
+type bitbucketPullRequest struct {
+	ID    int `json:"id"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// getBitbucketInfo reads the workspace and the repository slug from the remote URL of the repository
+func getBitbucketInfo(repo *git.Repository) (BitbucketInfo, error) {
+	remoteURL, err := getRemoteRepoURL(repo)
+	if err != nil {
+		return BitbucketInfo{}, err
+	}
+	return parseBitbucketURL(remoteURL)
+}
+
+// parseBitbucketURL reads the workspace and the repository slug from a remote URL (HTTPS or SSH),
+// e.g. "https://bitbucket.org/workspace/repository.git" or "git@bitbucket.org:workspace/repository.git"
+func parseBitbucketURL(remoteURL string) (BitbucketInfo, error) {
+	webURL, err := url.Parse(getRepositoryWebURL(remoteURL))
+	if err != nil {
+		return BitbucketInfo{}, fmt.Errorf("%w: %w", ErrCannotParseRepoURL, err)
+	}
+
+	workspace, repositorySlug, found := strings.Cut(strings.Trim(webURL.Path, "/"), "/")
+	if !found || workspace == "" || repositorySlug == "" || strings.Contains(repositorySlug, "/") {
+		return BitbucketInfo{}, fmt.Errorf("%w: %s", ErrCannotParseRepoURL, remoteURL)
+	}
+	return BitbucketInfo{Workspace: workspace, RepositorySlug: repositorySlug}, nil
+}
+
+// setBitbucketAuthorization authenticates the request with an app password ("username:app_password"),
+// or with an access token of the workspace, the project or the repository
+func setBitbucketAuthorization(req *http.Request, credentials string) {
+	if username, appPassword, found := strings.Cut(credentials, ":"); found {
+		req.SetBasicAuth(username, appPassword)
+		return
+	}
+	if credentials != "" {
+		req.Header.Set("Authorization", "Bearer "+credentials)
+	}
+}
+
+// createBitbucketPullRequest creates a new pull request on Bitbucket Cloud, returning its URL.
+// The open pull request of the branch is returned instead when there is already one.
+func createBitbucketPullRequest(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	repo *git.Repository,
+	sourceBranch string,
+	targetBranch string,
+	newVersion string,
+	logger *log.Entry,
+) (string, error) {
+	logger.Info("Creating Bitbucket pull request")
+
+	credentials := firstNonEmpty(projectConfig.ProjectAccessToken, globalConfig.BitbucketAccessToken)
+	bitbucketInfo, err := getBitbucketInfo(repo)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+	client := newAPIClient(globalConfig)
+
+	existingURL, err := findBitbucketPullRequest(ctx, client, bitbucketInfo, credentials, sourceBranch)
+	if err != nil {
+		return "", err
+	}
+	if existingURL != "" {
+		logger.Infof("The pull request of %s already exists: %s", sourceBranch, existingURL)
+		return existingURL, nil
+	}
+
+	req, err := buildBitbucketPullRequestRequest(
+		ctx,
+		bitbucketInfo,
+		credentials,
+		sourceBranch,
+		targetBranch,
+		getPullRequestTitle(globalConfig, projectConfig, state, newVersion, logger),
+		getPullRequestDescription(globalConfig, projectConfig, state, logger),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("POST %s", req.URL)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated {
+		return "", classifyPullRequestError(
+			resp.StatusCode, fmt.Errorf("%w: %d - %s", ErrFailedToCreatePullRequest, resp.StatusCode, body),
+		)
+	}
+
+	var pullRequest bitbucketPullRequest
+	_ = json.Unmarshal(body, &pullRequest)
+
+	logger.Info("Successfully created Bitbucket pull request")
+	return pullRequest.Links.HTML.Href, nil
+}
+
+// findBitbucketPullRequest returns the URL of the open pull request of the branch, empty when there is none
+func findBitbucketPullRequest(
+	ctx context.Context,
+	client *http.Client,
+	bitbucketInfo BitbucketInfo,
+	credentials string,
+	sourceBranch string,
+) (string, error) {
+	req, err := buildBitbucketPullRequestSearchRequest(ctx, bitbucketInfo, credentials, sourceBranch)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the pull requests: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", classifyPullRequestError(
+			resp.StatusCode, fmt.Errorf("failed to list the pull requests: %d - %s", resp.StatusCode, body),
+		)
+	}
+	return parseBitbucketPullRequestSearch(body)
+}
+
+// parseBitbucketPullRequestSearch returns the URL of the first pull request found, empty when none was
+func parseBitbucketPullRequestSearch(body []byte) (string, error) {
+	var page struct {
+		Values []bitbucketPullRequest `json:"values"`
+	}
+	err := json.Unmarshal(body, &page)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the pull requests: %w", err)
+	}
+	if len(page.Values) == 0 {
+		return "", nil
+	}
+	return page.Values[0].Links.HTML.Href, nil
+}
+
+// buildBitbucketPullRequestSearchRequest builds the request listing the open pull requests of the branch
+func buildBitbucketPullRequestSearchRequest(
+	ctx context.Context,
+	bitbucketInfo BitbucketInfo,
+	credentials string,
+	sourceBranch string,
+) (*http.Request, error) {
+	query := url.Values{}
+	query.Set("state", "OPEN")
+	query.Set("q", fmt.Sprintf("source.branch.name = %q", sourceBranch))
+	requestURL := fmt.Sprintf(
+		"%s/repositories/%s/%s/pullrequests?%s",
+		bitbucketAPIURL, bitbucketInfo.Workspace, bitbucketInfo.RepositorySlug, query.Encode(),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	setBitbucketAuthorization(req, credentials)
+	return req, nil
+}
+
+// buildBitbucketPullRequestRequest builds the request creating the pull request with the Bitbucket 2.0 REST API
+func buildBitbucketPullRequestRequest(
+	ctx context.Context,
+	bitbucketInfo BitbucketInfo,
+	credentials string,
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) (*http.Request, error) {
+	requestURL := fmt.Sprintf(
+		"%s/repositories/%s/%s/pullrequests", bitbucketAPIURL, bitbucketInfo.Workspace, bitbucketInfo.RepositorySlug,
+	)
+	payload := map[string]interface{}{
+		"title":       title,
+		"source":      map[string]interface{}{"branch": map[string]string{"name": sourceBranch}},
+		"destination": map[string]interface{}{"branch": map[string]string{"name": targetBranch}},
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	setBitbucketAuthorization(req, credentials)
+	return req, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBitbucketURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		remoteURL string
+	}{
+		{name: "should parse the HTTPS URL", remoteURL: "https://bitbucket.org/acme/api.git"},
+		{name: "should parse the HTTPS URL with a username", remoteURL: "https://alice@bitbucket.org/acme/api.git"},
+		{name: "should parse the SSH URL", remoteURL: "git@bitbucket.org:acme/api.git"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			bitbucketInfo, err := parseBitbucketURL(test.remoteURL)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, BitbucketInfo{Workspace: "acme", RepositorySlug: "api"}, bitbucketInfo)
+		})
+	}
+}
+
+func TestParseBitbucketURL_WithoutRepository(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := parseBitbucketURL("https://bitbucket.org/acme")
+
+	// Assert
+	require.ErrorIs(t, err, ErrCannotParseRepoURL)
+}
+
+func TestBuildBitbucketPullRequestRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	bitbucketInfo := BitbucketInfo{Workspace: "acme", RepositorySlug: "api"}
+
+	// Act
+	req, err := buildBitbucketPullRequestRequest(
+		context.Background(), bitbucketInfo, "alice:app-password", "chore/bump-1.1.0", "main",
+		"chore(bump): bumped version to 1.1.0", "### Added",
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.bitbucket.org/2.0/repositories/acme/api/pullrequests", req.URL.String())
+	username, password, found := req.BasicAuth()
+	assert.True(t, found, "the app password should be sent with basic authentication")
+	assert.Equal(t, "alice", username)
+	assert.Equal(t, "app-password", password)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "chore(bump): bumped version to 1.1.0",
+		"description": "### Added",
+		"source": {"branch": {"name": "chore/bump-1.1.0"}},
+		"destination": {"branch": {"name": "main"}}
+	}`, string(body))
+}
+
+func TestBuildBitbucketPullRequestSearchRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	bitbucketInfo := BitbucketInfo{Workspace: "acme", RepositorySlug: "api"}
+
+	// Act
+	req, err := buildBitbucketPullRequestSearchRequest(
+		context.Background(), bitbucketInfo, "workspace-token", "chore/bump-1.1.0",
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/2.0/repositories/acme/api/pullrequests", req.URL.Path)
+	assert.Equal(t, "OPEN", req.URL.Query().Get("state"))
+	assert.Equal(t, `source.branch.name = "chore/bump-1.1.0"`, req.URL.Query().Get("q"))
+	assert.Equal(t, "Bearer workspace-token", req.Header.Get("Authorization"))
+}
+
+func TestParseBitbucketPullRequestSearch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "should return the open pull request of the branch",
+			body:     `{"values": [{"id": 7, "links": {"html": {"href": "https://bitbucket.org/acme/api/pull-requests/7"}}}]}`,
+			expected: "https://bitbucket.org/acme/api/pull-requests/7",
+		},
+		{name: "should return nothing without pull request", body: `{"values": []}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			pullRequestURL, err := parseBitbucketPullRequestSearch([]byte(test.body))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, pullRequestURL)
+		})
+	}
+}
+
+func TestGetAuthMethods_Bitbucket(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{BitbucketAccessToken: "alice:app-password"}
+	projectConfig := &ProjectConfig{ProjectAccessToken: "repository-token"}
+
+	// Act
+	authMethods, err := getAuthMethods(
+		"https://bitbucket.org/acme/api.git", "AutoBump", globalConfig, projectConfig, standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []transport.AuthMethod{
+		&http.BasicAuth{Username: "x-token-auth", Password: "repository-token"},
+		&http.BasicAuth{Username: "alice", Password: "app-password"},
+	}, authMethods)
+}
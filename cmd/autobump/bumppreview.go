@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// bumpPreviewNone is the bump of the refs adding no entries to the bumping sections
+const bumpPreviewNone = "none"
+
+// formats of the bump preview
+const (
+	bumpPreviewFormatText = "text"
+	bumpPreviewFormatJSON = "json"
+)
+
+var (
+	ErrInvalidBumpPreviewFormat = errors.New("invalid preview format")
+	ErrNoUnreleasedSection      = errors.New("the CHANGELOG has no \"Unreleased\" section")
+)
+
+// BumpPreview is the bump resulting from the CHANGELOG entries added by the head ref, released from the base ref
+type BumpPreview struct {
+	BaseRef         string              `json:"base_ref"`
+	HeadRef         string              `json:"head_ref"`
+	PreviousVersion string              `json:"previous_version"`
+	NextVersion     string              `json:"next_version,omitempty"`
+	Bump            string              `json:"bump"`
+	AddedEntries    map[string][]string `json:"added_entries"`
+}
+
+// getUnreleasedBounds returns where the lines of the "Unreleased" section start and end in the CHANGELOG,
+// or -1 when there is no such section
+func getUnreleasedBounds(lines []string) (int, int) {
+	start := -1
+	for index := getFrontMatterLength(lines); index < len(lines); index++ {
+		if start == -1 {
+			if strings.Contains(lines[index], "[Unreleased]") {
+				start = index + 1
+			}
+			continue
+		}
+		if versionHeaderRegex.MatchString(lines[index]) {
+			return start, index
+		}
+	}
+	return start, len(lines)
+}
+
+// keepAddedEntries returns the "Unreleased" section of the head ref without the entries already in the base ref,
+// keeping the headings to know the sections of the added entries
+func keepAddedEntries(baseSection []string, headSection []string) []string {
+	existing := make(map[string]int, len(baseSection))
+	for _, line := range baseSection {
+		existing[strings.TrimSpace(line)]++
+	}
+
+	added := make([]string, 0, len(headSection))
+	for _, line := range headSection {
+		trimmedLine := strings.TrimSpace(line)
+		isEntry := trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#")
+		if isEntry && existing[trimmedLine] > 0 {
+			existing[trimmedLine]--
+			continue
+		}
+		added = append(added, line)
+	}
+	return added
+}
+
+// previewBump computes the bump of the CHANGELOG entries added by the head ref, with the same rules as a release
+// of the base ref. Both CHANGELOG files are read from the trees of the commits, without touching the worktree.
+func previewBump(
+	repo *git.Repository,
+	baseRef string,
+	headRef string,
+	changelogFile string,
+	changelogConfig ChangelogConfig,
+	maxFileSize int64,
+) (*BumpPreview, error) {
+	readRefLines := func(ref string) ([]string, error) {
+		commit, err := resolveBaseRef(repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		return readCommitLines(commit, changelogFile, ref, maxFileSize)
+	}
+	baseLines, err := readRefLines(baseRef)
+	if err != nil {
+		return nil, err
+	}
+	headLines, err := readRefLines(headRef)
+	if err != nil {
+		return nil, err
+	}
+
+	baseStart, baseEnd := getUnreleasedBounds(baseLines)
+	if baseStart == -1 {
+		return nil, fmt.Errorf("%w: %s in %s", ErrNoUnreleasedSection, changelogFile, baseRef)
+	}
+	var addedEntries []string
+	if headStart, headEnd := getUnreleasedBounds(headLines); headStart != -1 {
+		addedEntries = keepAddedEntries(baseLines[baseStart:baseEnd], headLines[headStart:headEnd])
+	}
+
+	// the CHANGELOG of the base ref, whose "Unreleased" section only has the added entries
+	lines := make([]string, 0, len(baseLines)+len(addedEntries))
+	lines = append(lines, baseLines[:baseStart]...)
+	lines = append(lines, addedEntries...)
+	lines = append(lines, baseLines[baseEnd:]...)
+
+	summary, err := getUnreleasedSummary(lines, changelogConfig)
+	if err != nil {
+		return nil, err
+	}
+	preview := &BumpPreview{
+		BaseRef:         baseRef,
+		HeadRef:         headRef,
+		PreviousVersion: versionString(summary.LatestVersion),
+		Bump:            bumpPreviewNone,
+		AddedEntries:    summary.SectionEntries,
+	}
+	if summary.Empty {
+		return preview, nil
+	}
+
+	nextVersion, _, err := processChangelog(lines, changelogConfig)
+	if err != nil {
+		return nil, err
+	}
+	preview.NextVersion = versionString(nextVersion)
+	preview.Bump = getBumpKind(summary.LatestVersion, nextVersion, changelogConfig.VersioningScheme)
+	return preview, nil
+}
+
+// writeBumpPreview writes the preview as text for the humans, or as JSON for the bots
+func writeBumpPreview(output io.Writer, preview *BumpPreview, format string) error {
+	var err error
+	switch format {
+	case "", bumpPreviewFormatText:
+		_, err = io.WriteString(output, renderBumpPreview(preview))
+	case bumpPreviewFormatJSON:
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(preview)
+	default:
+		return fmt.Errorf(
+			"%w: %q (expected %s or %s)", ErrInvalidBumpPreviewFormat, format, bumpPreviewFormatText, bumpPreviewFormatJSON,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the preview: %w", err)
+	}
+	return nil
+}
+
+// renderBumpPreview renders the added entries by section, followed by the bump they result in
+func renderBumpPreview(preview *BumpPreview) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("CHANGELOG entries added by %s to %s:\n", preview.HeadRef, preview.BaseRef))
+	if len(preview.AddedEntries) == 0 {
+		builder.WriteString("\nNone.\n")
+	}
+	for _, section := range getSectionsOrder(preview.AddedEntries) {
+		if len(preview.AddedEntries[section]) == 0 {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("\n### %s\n\n", section))
+		for _, entry := range preview.AddedEntries[section] {
+			builder.WriteString(getEntryHeadline(entry) + "\n")
+		}
+	}
+
+	if preview.Bump == bumpPreviewNone {
+		builder.WriteString(fmt.Sprintf("\nThese entries don't bump the version (%s).\n", preview.PreviousVersion))
+	} else {
+		builder.WriteString(fmt.Sprintf(
+			"\nThese entries result in a %s bump: %s → %s.\n", preview.Bump, preview.PreviousVersion, preview.NextVersion,
+		))
+	}
+	return builder.String()
+}
+
+// runBumpPreview previews the bump of the entries added by the head ref, in the repository of the current directory
+func runBumpPreview(output io.Writer, config *Config) error {
+	globalConfig, err := readStandaloneConfig(config.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	changelogConfig := globalConfig.Changelog
+	changelogConfig.Date = getReleaseDate(globalConfig)
+	changelogConfig.VersionPolicy = globalConfig.VersionPolicy
+
+	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return fmt.Errorf("could not open repository: %w", err)
+	}
+
+	preview, err := previewBump(
+		repo, config.previewBaseRef, config.previewHeadRef, "CHANGELOG.md", changelogConfig, getMaxFileSize(globalConfig),
+	)
+	if err != nil {
+		return err
+	}
+	return writeBumpPreview(output, preview, config.previewFormat)
+}
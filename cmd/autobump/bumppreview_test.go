@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBumpPreviewRepo creates a repository whose "main" branch has the original CHANGELOG,
+// and whose "feature" branch replaces it with the given one
+func newBumpPreviewRepo(t *testing.T, featureChangelog string) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+	baseHash := commitFile(t, repo, "CHANGELOG.md", changelogOriginal+"\n")
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", baseHash)))
+	featureHash := commitFile(t, repo, "CHANGELOG.md", featureChangelog+"\n")
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature", featureHash)))
+	return repo
+}
+
+func TestPreviewBump_EntriesAddedToTwoSections(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureChangelog := strings.Replace(
+		changelogOriginal,
+		"- Another new feature.\n",
+		"- Another new feature.\n- Exported the release notes.\n\n### Fixed\n\n- Fixed the links of the tags.\n",
+		1,
+	)
+	repo := newBumpPreviewRepo(t, featureChangelog)
+
+	// Act
+	preview, err := previewBump(repo, "main", "feature", "CHANGELOG.md", ChangelogConfig{}, defaultMaxFileSize)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "minor", preview.Bump)
+	assert.Equal(t, "1.0.1", preview.PreviousVersion)
+	assert.Equal(t, "1.1.0", preview.NextVersion)
+	assert.Equal(t, map[string][]string{
+		"Added": {"- Exported the release notes."},
+		"Fixed": {"- Fixed the links of the tags."},
+	}, preview.AddedEntries, "the entries of the base ref aren't previewed")
+}
+
+func TestPreviewBump_EntriesAddedToNonBumpingSections(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureChangelog := strings.Replace(
+		changelogOriginal,
+		"- Another new feature.\n",
+		"- Another new feature.\n\n### Internal\n\n- Upgraded the CI images.\n",
+		1,
+	)
+	repo := newBumpPreviewRepo(t, featureChangelog)
+	changelogConfig := ChangelogConfig{NonBumpingSections: []string{"Internal"}}
+
+	// Act
+	preview, err := previewBump(repo, "main", "feature", "CHANGELOG.md", changelogConfig, defaultMaxFileSize)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, bumpPreviewNone, preview.Bump)
+	assert.Empty(t, preview.NextVersion)
+	assert.Equal(t, map[string][]string{"Internal": {"- Upgraded the CI images."}}, preview.AddedEntries)
+}
+
+func TestPreviewBump_UnknownRef(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repo := newBumpPreviewRepo(t, changelogOriginal)
+
+	// Act
+	_, err := previewBump(repo, "main", "missing", "CHANGELOG.md", ChangelogConfig{}, defaultMaxFileSize)
+
+	// Assert
+	require.ErrorIs(t, err, ErrBaseRefNotFound)
+}
+
+func TestWriteBumpPreview(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	preview := &BumpPreview{
+		BaseRef:         "origin/main",
+		HeadRef:         "HEAD",
+		PreviousVersion: "1.0.1",
+		NextVersion:     "1.1.0",
+		Bump:            "minor",
+		AddedEntries:    map[string][]string{"Added": {"- Exported the release notes."}},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		var output bytes.Buffer
+
+		// Act
+		err := writeBumpPreview(&output, preview, bumpPreviewFormatText)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "CHANGELOG entries added by HEAD to origin/main:\n\n### Added\n\n- Exported the release notes.\n"+
+			"\nThese entries result in a minor bump: 1.0.1 → 1.1.0.\n", output.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		var output bytes.Buffer
+
+		// Act
+		err := writeBumpPreview(&output, preview, bumpPreviewFormatJSON)
+
+		// Assert
+		require.NoError(t, err)
+		var decoded BumpPreview
+		require.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+		assert.Equal(t, *preview, decoded)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		err := writeBumpPreview(&bytes.Buffer{}, preview, "yaml")
+
+		// Assert
+		require.ErrorIs(t, err, ErrInvalidBumpPreviewFormat)
+	})
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBranchTemplate        = "chore/bump-{{.Version}}"
+	defaultCommitMessageTemplate = "chore(bump): bumped version to {{.Version}}"
+)
+
+var ErrInvalidBumpTemplate = errors.New("invalid template")
+
+// bumpTemplateData holds the variables available in the templates of the bump branch, commit message and
+// pull request title
+type bumpTemplateData struct {
+	// released version, with its prefix (e.g. "v1.5.0" with "version_prefix: v")
+	Version string
+	Project string
+	Date    string
+}
+
+// pullRequestTemplateData holds the variables available in the template of the pull request body
+type pullRequestTemplateData struct {
+	bumpTemplateData
+	// changes of the release, per section
+	Changelog string
+	// notes written by AutoBump in the default body (e.g. the migration notes), without the changes of the release
+	Notes string
+}
+
+// getBranchTemplate returns the template of the bump branch, the project one overriding the global one
+func getBranchTemplate(globalConfig *GlobalConfig, projectConfig *ProjectConfig) string {
+	return firstNonEmpty(projectConfig.BranchTemplate, globalConfig.BranchTemplate, defaultBranchTemplate)
+}
+
+// getCommitMessageTemplate returns the template of the bump commit subject, which is also the title of the pull
+// request by default, the project one overriding the global one
+func getCommitMessageTemplate(globalConfig *GlobalConfig, projectConfig *ProjectConfig) string {
+	return firstNonEmpty(
+		projectConfig.CommitMessageTemplate, globalConfig.CommitMessageTemplate, defaultCommitMessageTemplate,
+	)
+}
+
+// getPullRequestTitleTemplate returns the template of the pull request title, empty when it is the commit subject
+func getPullRequestTitleTemplate(globalConfig *GlobalConfig, projectConfig *ProjectConfig) string {
+	return firstNonEmpty(projectConfig.PullRequestTitleTemplate, globalConfig.PullRequestTitleTemplate)
+}
+
+// getPullRequestBodyTemplate returns the template of the pull request body, empty when it is the default body
+func getPullRequestBodyTemplate(globalConfig *GlobalConfig, projectConfig *ProjectConfig) string {
+	return firstNonEmpty(projectConfig.PullRequestBodyTemplate, globalConfig.PullRequestBodyTemplate)
+}
+
+// validateBumpTemplates checks that the templates of the bump and of its pull request render with every variable,
+// the branch being a valid git branch name
+func validateBumpTemplates(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
+	data := bumpTemplateData{Version: "1.0.0", Project: "project", Date: "2006-01-02"}
+	branchName, err := renderBumpTemplate(getBranchTemplate(globalConfig, projectConfig), data)
+	if err != nil {
+		return fmt.Errorf("branch_template: %w", err)
+	}
+	if plumbing.NewBranchReferenceName(branchName).Validate() != nil {
+		return fmt.Errorf("branch_template: %w: %q isn't a valid branch name", ErrInvalidBumpTemplate, branchName)
+	}
+	_, err = renderBumpTemplate(getCommitMessageTemplate(globalConfig, projectConfig), data)
+	if err != nil {
+		return fmt.Errorf("commit_message_template: %w", err)
+	}
+
+	if text := getPullRequestTitleTemplate(globalConfig, projectConfig); text != "" {
+		_, err = renderBumpTemplate(text, data)
+		if err != nil {
+			return fmt.Errorf("pr_title_template: %w", err)
+		}
+	}
+	if text := getPullRequestBodyTemplate(globalConfig, projectConfig); text != "" {
+		_, err = renderBumpTemplate(text, pullRequestTemplateData{
+			bumpTemplateData: data,
+			Changelog:        "### Added\n\n- added the feature",
+			Notes:            "notes",
+		})
+		if err != nil {
+			return fmt.Errorf("pr_body_template: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderBumpTemplate replaces the template variables (e.g. {{.Version}} and {{.Project}}), refusing the empty results
+func renderBumpTemplate(text string, data any) (string, error) {
+	tmpl, err := template.New("bump").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidBumpTemplate, err)
+	}
+	var builder strings.Builder
+	err = tmpl.Execute(&builder, data)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidBumpTemplate, err)
+	}
+	rendered := strings.TrimSpace(builder.String())
+	if rendered == "" {
+		return "", fmt.Errorf("%w: %q renders nothing", ErrInvalidBumpTemplate, text)
+	}
+	return rendered, nil
+}
+
+// getBumpTemplateData returns the variables of the templates for the release of the project
+func getBumpTemplateData(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	releaseName string,
+) bumpTemplateData {
+	return bumpTemplateData{
+		Version: state.resolvedVersionPrefix + releaseName,
+		Project: projectConfig.Name,
+		Date:    getReleaseDate(globalConfig).Format("2006-01-02"),
+	}
+}
+
+// renderProjectBumpTemplate renders the template for the release of the project, falling back to the default
+// template when it fails
+func renderProjectBumpTemplate(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	text string,
+	defaultText string,
+	releaseName string,
+	logger *log.Entry,
+) string {
+	data := getBumpTemplateData(globalConfig, projectConfig, state, releaseName)
+	rendered, err := renderBumpTemplate(text, data)
+	if err != nil {
+		logger.Errorf("Failed to render the template, using the default one: %v", err)
+		rendered, _ = renderBumpTemplate(defaultText, data)
+	}
+	return rendered
+}
+
+// getBumpBranchName returns the name of the branch of the bump to the release
+func getBumpBranchName(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	releaseName string,
+	logger *log.Entry,
+) string {
+	return renderProjectBumpTemplate(
+		globalConfig,
+		projectConfig,
+		state,
+		getBranchTemplate(globalConfig, projectConfig),
+		defaultBranchTemplate,
+		releaseName,
+		logger,
+	)
+}
+
+// getBumpCommitSubject returns the subject of the commit bumping the project to the release
+func getBumpCommitSubject(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	releaseName string,
+	logger *log.Entry,
+) string {
+	return renderProjectBumpTemplate(
+		globalConfig,
+		projectConfig,
+		state,
+		getCommitMessageTemplate(globalConfig, projectConfig),
+		defaultCommitMessageTemplate,
+		releaseName,
+		logger,
+	)
+}
+
+// getPullRequestTitle returns the title of the pull request, which is the commit subject unless "pr_title_template"
+// is set. The pull requests of the yanks and of the propagations keep their own title.
+func getPullRequestTitle(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	newVersion string,
+	logger *log.Entry,
+) string {
+	text := getPullRequestTitleTemplate(globalConfig, projectConfig)
+	if text == "" || state.commitSubject != "" || state.yankNotes != "" {
+		return getCommitSubject(globalConfig, projectConfig, state, newVersion, logger)
+	}
+	return renderProjectBumpTemplate(
+		globalConfig, projectConfig, state, text, getCommitMessageTemplate(globalConfig, projectConfig), newVersion,
+		logger,
+	)
+}
+
+// renderPullRequestBody renders the template of the pull request body, falling back to the default body when
+// it fails. The body may be empty, e.g. "{{.Notes}}" when there are no notes.
+func renderPullRequestBody(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	text string,
+	notes string,
+	defaultBody string,
+	logger *log.Entry,
+) string {
+	tmpl, err := template.New("pr_body").Option("missingkey=error").Parse(text)
+	if err == nil {
+		var builder strings.Builder
+		err = tmpl.Execute(&builder, pullRequestTemplateData{
+			bumpTemplateData: getBumpTemplateData(globalConfig, projectConfig, state, projectConfig.NewVersion),
+			Changelog:        strings.TrimSpace(state.changelogExcerpt),
+			Notes:            notes,
+		})
+		if err == nil {
+			return strings.TrimSpace(builder.String())
+		}
+	}
+	logger.Errorf("Failed to render the pull request body template, using the default one: %v", err)
+	return defaultBody
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBumpBranchName_Templates(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{
+		BranchTemplate:        "release/{{.Version}}",
+		CommitMessageTemplate: "build: release {{.Project}} {{.Version}} on {{.Date}}",
+		releaseDate:           time.Date(1984, time.January, 2, 0, 0, 0, 0, time.UTC),
+	}
+	projectConfig := &ProjectConfig{Name: "payments"}
+	state := &projectState{resolvedVersionPrefix: versionPrefixV}
+
+	// Act
+	branchName := getBumpBranchName(globalConfig, projectConfig, state, "1.1.0", standardLogEntry())
+	title := getCommitSubject(globalConfig, projectConfig, state, "1.1.0", standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "release/v1.1.0", branchName)
+	assert.Equal(t, "build: release payments v1.1.0 on 1984-01-02", title)
+}
+
+func TestGetBumpBranchName_ProjectTemplate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{BranchTemplate: "release/{{.Version}}"}
+	projectConfig := &ProjectConfig{Name: "payments", BranchTemplate: "bump/{{.Project}}-{{.Version}}"}
+	state := &projectState{}
+
+	// Act
+	branchName := getBumpBranchName(globalConfig, projectConfig, state, "1.1.0", standardLogEntry())
+	title := getCommitSubject(globalConfig, projectConfig, state, "1.1.0", standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "bump/payments-1.1.0", branchName)
+	assert.Equal(t, "chore(bump): bumped version to 1.1.0", title)
+}
+
+func TestValidateBumpTemplates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		globalConfig  *GlobalConfig
+		projectConfig *ProjectConfig
+		valid         bool
+	}{
+		{name: "should accept the default templates", globalConfig: &GlobalConfig{}, valid: true},
+		{
+			name:         "should accept every variable",
+			globalConfig: &GlobalConfig{BranchTemplate: "release/{{.Project}}/{{.Date}}-{{.Version}}"},
+			valid:        true,
+		},
+		{
+			name:         "should refuse an unknown variable",
+			globalConfig: &GlobalConfig{CommitMessageTemplate: "chore: {{.Release}}"},
+		},
+		{name: "should refuse a malformed template", globalConfig: &GlobalConfig{BranchTemplate: "bump/{{.Version"}},
+		{name: "should refuse an invalid branch name", globalConfig: &GlobalConfig{BranchTemplate: "bump {{.Version}}"}},
+		{
+			name:         "should accept the changes of the release in the pull request body",
+			globalConfig: &GlobalConfig{PullRequestBodyTemplate: "## {{.Version}}\n\n{{.Changelog}}\n\n{{.Notes}}"},
+			valid:        true,
+		},
+		{
+			name:         "should refuse the changes of the release in the pull request title",
+			globalConfig: &GlobalConfig{PullRequestTitleTemplate: "{{.Changelog}}"},
+		},
+		{
+			name:          "should check the template of the project",
+			globalConfig:  &GlobalConfig{},
+			projectConfig: &ProjectConfig{BranchTemplate: "{{if false}}x{{end}}"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			projectConfig := test.projectConfig
+			if projectConfig == nil {
+				projectConfig = &ProjectConfig{}
+			}
+
+			// Act
+			err := validateBumpTemplates(test.globalConfig, projectConfig)
+
+			// Assert
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidBumpTemplate)
+			}
+		})
+	}
+}
+
+func TestGetPullRequestTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		state    *projectState
+		expected string
+	}{
+		{
+			name:     "should render the template",
+			state:    &projectState{},
+			expected: "Release payments 1.1.0",
+		},
+		{
+			name:     "should keep the title of the yanks",
+			state:    &projectState{yankNotes: formatYankNotes("1.1.0", "")},
+			expected: "chore(yank): yanked version 1.1.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			globalConfig := &GlobalConfig{PullRequestTitleTemplate: "Release {{.Project}} {{.Version}}"}
+			projectConfig := &ProjectConfig{Name: "payments"}
+
+			// Act
+			title := getPullRequestTitle(globalConfig, projectConfig, test.state, "1.1.0", standardLogEntry())
+
+			// Assert
+			assert.Equal(t, test.expected, title)
+		})
+	}
+}
+
+func TestGetPullRequestDescription_ChangelogExcerpt(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectConfig := &ProjectConfig{}
+	state := &projectState{
+		changelogExcerpt: "### Added\n\n- added the reports\n",
+		runNotes:         "Run 42.",
+	}
+
+	// Act
+	description := getPullRequestDescription(&GlobalConfig{}, projectConfig, state, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "These are the changes of this release:\n\n### Added\n\n- added the reports\n\nRun 42.", description)
+}
+
+func TestGetPullRequestDescription_BodyTemplate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{
+		PullRequestBodyTemplate: "Release of {{.Project}} {{.Version}}:\n\n{{.Changelog}}\n\n{{.Notes}}",
+	}
+	projectConfig := &ProjectConfig{
+		Name:       "payments",
+		NewVersion: "1.1.0",
+	}
+	state := &projectState{
+		changelogExcerpt: "### Added\n\n- added the reports\n",
+		runNotes:         "Run 42.",
+	}
+
+	// Act
+	description := getPullRequestDescription(globalConfig, projectConfig, state, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "Release of payments 1.1.0:\n\n### Added\n\n- added the reports\n\nRun 42.", description)
+}
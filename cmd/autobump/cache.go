@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultsCacheTTL is how long the defaults downloaded from the AutoBump repository are served without a refresh
+const defaultsCacheTTL = 24 * time.Hour
+
+var ErrFailedToDownload = errors.New("failed to download file")
+
+// cachedDefault is a file of the AutoBump repository cached locally, along with its validation
+type cachedDefault struct {
+	name     string
+	validate func(data []byte) error
+}
+
+// cachedDefaults are the files downloaded from the AutoBump repository that are cached locally
+var cachedDefaults = map[string]cachedDefault{
+	defaultConfigURL: {
+		name: "default-config.yaml",
+		validate: func(data []byte) error {
+			_, err := decodeConfig(data)
+			return err
+		},
+	},
+	defaultChangelogURL: {
+		name: "CHANGELOG.template.md",
+		validate: func(data []byte) error {
+			return validateChangelogTemplate(string(data))
+		},
+	},
+}
+
+// defaultsCache caches the defaults downloaded from the AutoBump repository (e.g. the languages config)
+var defaultsCache = newDownloadCache(getDefaultsCacheDir(), defaultsCacheTTL)
+
+// downloadCache keeps downloaded files on disk, serving them while fresh and refreshing them in the background
+// once stale, so most runs don't need the network
+type downloadCache struct {
+	dir      string
+	ttl      time.Duration
+	refresh  bool // ignore the cached files, as with "--refresh-defaults"
+	readOnly bool // never write the cached files, while a batch run holds the lock of the directory
+
+	refreshes sync.WaitGroup
+}
+
+// newDownloadCache creates a cache in the given directory, caching nothing when the directory is empty
+func newDownloadCache(dir string, ttl time.Duration) *downloadCache {
+	return &downloadCache{dir: dir, ttl: ttl}
+}
+
+// getDefaultsCacheDir returns the directory of the cache (e.g. "~/.cache/autobump")
+func getDefaultsCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "autobump")
+}
+
+// get returns the content of the URL, from the cache when available.
+// Stale files are still served while refreshed in the background, and invalid ones are discarded.
+func (cache *downloadCache) get(url string, name string, validate func(data []byte) error) ([]byte, error) {
+	if cache.dir == "" {
+		return downloadFile(url)
+	}
+
+	cachePath := filepath.Join(cache.dir, name)
+	if !cache.refresh {
+		data, modTime, err := readCacheEntry(cachePath, validate)
+		if err == nil {
+			if time.Since(modTime) >= cache.ttl && !cache.readOnly {
+				log.Debugf("Cached %s is stale, refreshing it in the background", name)
+				cache.refreshes.Add(1)
+				go func() {
+					defer cache.refreshes.Done()
+					cache.update(url, cachePath, validate)
+				}()
+			}
+			return data, nil
+		}
+		if !os.IsNotExist(err) && !cache.readOnly {
+			log.Debugf("Discarding the cached %s: %v", name, err)
+			_ = os.Remove(cachePath)
+			_ = os.Remove(cachePath + ".etag")
+		}
+	}
+
+	data, etag, _, err := fetchWithETag(url, "")
+	if err != nil {
+		return nil, err
+	}
+	if !cache.readOnly && (validate == nil || validate(data) == nil) {
+		writeCacheEntry(cachePath, data, etag)
+	}
+	return data, nil
+}
+
+// wait waits for the background refreshes to finish
+func (cache *downloadCache) wait() {
+	cache.refreshes.Wait()
+}
+
+// update refreshes a cached file, only downloading it again when it changed
+func (cache *downloadCache) update(url string, cachePath string, validate func(data []byte) error) {
+	etag, _ := os.ReadFile(cachePath + ".etag")
+	data, newETag, notModified, err := fetchWithETag(url, string(etag))
+	if err != nil {
+		log.Debugf("Failed to refresh %s: %v", url, err)
+		return
+	}
+
+	if notModified {
+		now := time.Now()
+		_ = os.Chtimes(cachePath, now, now)
+		return
+	}
+	if validate == nil || validate(data) == nil {
+		writeCacheEntry(cachePath, data, newETag)
+	}
+}
+
+// readCacheEntry reads a cached file, returning its modification time
+func readCacheEntry(cachePath string, validate func(data []byte) error) ([]byte, time.Time, error) {
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if validate != nil {
+		err = validate(data)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	return data, info.ModTime(), nil
+}
+
+// writeCacheEntry writes a file to the cache atomically, ignoring failures since the cache is optional
+func writeCacheEntry(cachePath string, data []byte, etag string) {
+	err := os.MkdirAll(filepath.Dir(cachePath), 0o700)
+	if err != nil {
+		log.Debugf("Failed to create the cache directory: %v", err)
+		return
+	}
+
+	temporaryFile, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*")
+	if err != nil {
+		log.Debugf("Failed to write the cache: %v", err)
+		return
+	}
+	defer os.Remove(temporaryFile.Name())
+
+	_, err = temporaryFile.Write(data)
+	closeErr := temporaryFile.Close()
+	if err != nil || closeErr != nil {
+		log.Debugf("Failed to write the cache: %v", errors.Join(err, closeErr))
+		return
+	}
+
+	err = os.Rename(temporaryFile.Name(), cachePath)
+	if err != nil {
+		log.Debugf("Failed to write the cache: %v", err)
+		return
+	}
+
+	if etag == "" {
+		_ = os.Remove(cachePath + ".etag")
+		return
+	}
+	_ = os.WriteFile(cachePath+".etag", []byte(etag), 0o600)
+}
+
+// fetchWithETag downloads a file, unless it didn't change since the given ETag
+func fetchWithETag(url string, etag string) ([]byte, string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create download request: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("%w: %s returned %d", ErrFailedToDownload, url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, resp.Header.Get("ETag"), false, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer serves the content with an ETag, counting the downloads (the 304 answers are not counted)
+func newCountingServer(t *testing.T, content string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestDownloadCache_FetchesOnceWithinTTL(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, downloads := newCountingServer(t, "languages: {}\n")
+	cache := newDownloadCache(t.TempDir(), time.Hour)
+
+	for range 3 {
+		// Act
+		data, err := cache.get(server.URL, "default-config.yaml", nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "languages: {}\n", string(data))
+	}
+	cache.wait()
+	assert.Equal(t, int32(1), downloads.Load())
+}
+
+func TestDownloadCache_StaleEntryServedAndRefreshed(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, downloads := newCountingServer(t, "fresh")
+	cacheDir := t.TempDir()
+	cachePath := filepath.Join(cacheDir, "default-config.yaml")
+	require.NoError(t, os.WriteFile(cachePath, []byte("stale"), 0o600))
+	staleTime := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(cachePath, staleTime, staleTime))
+	cache := newDownloadCache(cacheDir, 24*time.Hour)
+
+	// Act
+	data, err := cache.get(server.URL, "default-config.yaml", nil)
+	cache.wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "stale", string(data))
+	assert.Equal(t, int32(1), downloads.Load())
+	refreshed, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", string(refreshed))
+}
+
+func TestDownloadCache_NotModifiedRenewsTheEntry(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, downloads := newCountingServer(t, "content")
+	cacheDir := t.TempDir()
+	cache := newDownloadCache(cacheDir, 24*time.Hour)
+	_, err := cache.get(server.URL, "default-config.yaml", nil)
+	require.NoError(t, err)
+	cachePath := filepath.Join(cacheDir, "default-config.yaml")
+	staleTime := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(cachePath, staleTime, staleTime))
+
+	// Act
+	_, err = cache.get(server.URL, "default-config.yaml", nil)
+	cache.wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+	info, err := os.Stat(cachePath)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+}
+
+func TestDownloadCache_CorruptEntryDiscarded(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, downloads := newCountingServer(t, "languages: {}\n")
+	cacheDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "default-config.yaml"), []byte("{{{"), 0o600))
+	cache := newDownloadCache(cacheDir, time.Hour)
+	validate := cachedDefaults[defaultConfigURL].validate
+
+	// Act
+	data, err := cache.get(server.URL, "default-config.yaml", validate)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "languages: {}\n", string(data))
+	assert.Equal(t, int32(1), downloads.Load())
+}
+
+func TestDownloadCache_Refresh(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, downloads := newCountingServer(t, "content")
+	cache := newDownloadCache(t.TempDir(), time.Hour)
+	cache.refresh = true
+
+	// Act
+	_, err := cache.get(server.URL, "default-config.yaml", nil)
+	require.NoError(t, err)
+	_, err = cache.get(server.URL, "default-config.yaml", nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), downloads.Load())
+}
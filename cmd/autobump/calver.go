@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	versioningSchemeSemVer = "semver"
+	versioningSchemeCalVer = "calver"
+	defaultCalVerFormat    = "YYYY.0M.MICRO"
+	calVerMicroToken       = "MICRO"
+	maxCalVerSegments      = 3
+)
+
+var (
+	ErrInvalidVersioningScheme = errors.New("invalid versioning scheme")
+	ErrInvalidCalVerFormat     = errors.New("invalid CalVer format")
+	ErrCalVerAlreadyReleased   = errors.New("a version was already released for the current date")
+)
+
+// calVerDateTokens renders the date segments supported in the CalVer format
+var calVerDateTokens = map[string]func(date time.Time) string{
+	"YYYY": func(date time.Time) string { return strconv.Itoa(date.Year()) },
+	"YY":   func(date time.Time) string { return strconv.Itoa(date.Year() % 100) },      //nolint:mnd // two-digit year
+	"0Y":   func(date time.Time) string { return fmt.Sprintf("%02d", date.Year()%100) }, //nolint:mnd // two-digit year
+	"MM":   func(date time.Time) string { return strconv.Itoa(int(date.Month())) },
+	"0M":   func(date time.Time) string { return fmt.Sprintf("%02d", int(date.Month())) },
+	"DD":   func(date time.Time) string { return strconv.Itoa(date.Day()) },
+	"0D":   func(date time.Time) string { return fmt.Sprintf("%02d", date.Day()) },
+}
+
+// validateVersioningScheme checks the versioning scheme and the CalVer format of a project
+func validateVersioningScheme(projectConfig *ProjectConfig) error {
+	switch projectConfig.VersioningScheme {
+	case "", versioningSchemeSemVer:
+		return nil
+	case versioningSchemeCalVer:
+		return validateCalVerFormat(getCalVerFormat(projectConfig.CalVerFormat))
+	default:
+		return fmt.Errorf(
+			"%w: %s (expected %s or %s)",
+			ErrInvalidVersioningScheme,
+			projectConfig.VersioningScheme,
+			versioningSchemeSemVer,
+			versioningSchemeCalVer,
+		)
+	}
+}
+
+// validateCalVerFormat checks that the format only uses known segments and can be parsed as a version
+func validateCalVerFormat(format string) error {
+	segments := strings.Split(format, ".")
+	if len(segments) > maxCalVerSegments {
+		return fmt.Errorf("%w: %s has more than %d segments", ErrInvalidCalVerFormat, format, maxCalVerSegments)
+	}
+
+	for index, segment := range segments {
+		if segment == calVerMicroToken {
+			if index != len(segments)-1 {
+				return fmt.Errorf("%w: %s must be the last segment", ErrInvalidCalVerFormat, calVerMicroToken)
+			}
+			continue
+		}
+		if _, exists := calVerDateTokens[segment]; !exists {
+			return fmt.Errorf("%w: unknown segment %s", ErrInvalidCalVerFormat, segment)
+		}
+	}
+	return nil
+}
+
+// getCalVerFormat returns the configured CalVer format or the default one
+func getCalVerFormat(format string) string {
+	if format == "" {
+		return defaultCalVerFormat
+	}
+	return format
+}
+
+// getNextCalVer derives the next version from the date, incrementing the micro segment
+// when the previous version was released in the same period (e.g. the same month)
+func getNextCalVer(previousVersion semver.Version, format string, date time.Time) (*semver.Version, error) {
+	format = getCalVerFormat(format)
+	err := validateCalVerFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	formatSegments := strings.Split(format, ".")
+	previousSegments := strings.Split(strings.TrimPrefix(previousVersion.Original(), "v"), ".")
+	samePeriod := len(previousSegments) == len(formatSegments)
+
+	segments := make([]string, 0, len(formatSegments))
+	for index, token := range formatSegments {
+		if token == calVerMicroToken {
+			micro := 0
+			if samePeriod {
+				previousMicro, _ := strconv.Atoi(previousSegments[index])
+				micro = previousMicro + 1
+			}
+			segments = append(segments, strconv.Itoa(micro))
+			continue
+		}
+
+		segment := calVerDateTokens[token](date)
+		// the segments are compared as numbers, so "2024.6" and "2024.06" are the same period
+		if samePeriod && !equalNumbers(segment, previousSegments[index]) {
+			samePeriod = false
+		}
+		segments = append(segments, segment)
+	}
+
+	if samePeriod && formatSegments[len(formatSegments)-1] != calVerMicroToken {
+		return nil, fmt.Errorf("%w: %s", ErrCalVerAlreadyReleased, previousVersion.Original())
+	}
+
+	nextVersion, err := semver.NewVersion(strings.Join(segments, "."))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCalVerFormat, err)
+	}
+	return nextVersion, nil
+}
+
+// equalNumbers compares two numeric strings ignoring the leading zeros
+func equalNumbers(first string, second string) bool {
+	firstNumber, firstErr := strconv.Atoi(first)
+	secondNumber, secondErr := strconv.Atoi(second)
+	return firstErr == nil && secondErr == nil && firstNumber == secondNumber
+}
+
+// versionString returns the version as it is written, keeping the zero padding used by CalVer
+func versionString(version *semver.Version) string {
+	return strings.TrimPrefix(version.Original(), "v")
+}
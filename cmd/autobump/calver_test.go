@@ -0,0 +1,127 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNextCalVer_SameMonth(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	previousVersion := semver.MustParse("2024.06.1")
+	date := time.Date(2024, time.June, 20, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	nextVersion, err := getNextCalVer(*previousVersion, "", date)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2024.06.2", versionString(nextVersion))
+}
+
+func TestGetNextCalVer_MonthRollover(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	previousVersion := semver.MustParse("2024.12.3")
+	date := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	nextVersion, err := getNextCalVer(*previousVersion, "YYYY.0M.MICRO", date)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2025.01.0", versionString(nextVersion))
+}
+
+func TestGetNextCalVer_WithoutMicroAlreadyReleased(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	previousVersion := semver.MustParse("24.6.5")
+	date := time.Date(2024, time.June, 5, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	_, err := getNextCalVer(*previousVersion, "YY.MM.DD", date)
+
+	// Assert
+	require.ErrorIs(t, err, ErrCalVerAlreadyReleased)
+}
+
+func TestValidateCalVerFormat_Invalid(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	require.NoError(t, validateCalVerFormat("0Y.0M.0D"))
+	require.ErrorIs(t, validateCalVerFormat("YYYY.0M.0D.MICRO"), ErrInvalidCalVerFormat)
+	require.ErrorIs(t, validateCalVerFormat("YYYY.MICRO.0M"), ErrInvalidCalVerFormat)
+	require.ErrorIs(t, validateCalVerFormat("YYYY.QQ"), ErrInvalidCalVerFormat)
+}
+
+func TestFindLatestVersion_CalVerAcrossYears(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogTemplate+`
+
+## [2024.02.0] - 2024-02-01
+
+## [2023.12.4] - 2023-12-20`, "\n")
+
+	// Act
+	version, err := findLatestVersion(changelog)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2024.02.0", versionString(version))
+}
+
+func TestProcessChangelog_CalVer(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogTemplate+`
+
+### Added
+
+- **BREAKING CHANGE:** new feature.
+
+## [2020.01.0] - 2020-01-01`, "\n")
+	expectedVersion := time.Now().Format("2006.01") + ".0"
+
+	// Act
+	version, newChangelog, err := processChangelog(
+		changelog,
+		ChangelogConfig{VersioningScheme: versioningSchemeCalVer},
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expectedVersion, versionString(version))
+	assert.Contains(t, strings.Join(newChangelog, "\n"), "## ["+expectedVersion+"] - ")
+	assert.Contains(t, strings.Join(newChangelog, "\n"), "## [2020.01.0] - 2020-01-01")
+}
+
+func TestUpdateVersion_CalVer(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newVersionFilesFixture(t)
+	projectConfig.NewVersion = versionString(semver.MustParse("2024.06.0"))
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	content, err := readLines(filepath.Join(projectConfig.Path, "version.txt"), defaultMaxFileSize)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"version=2024.06.0"}, content)
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -16,18 +18,54 @@ import (
 const defaultChangelogURL = "https://raw.githubusercontent.com/rios0rios0/" +
 	"autobump/main/configs/CHANGELOG.template.md"
 
+// versionHeaderStyle describes how the released version headers are written in the CHANGELOG
+type versionHeaderStyle struct {
+	inlineLink bool   // e.g. "## [1.2.0](https://github.com/org/repo/compare/v1.1.0...v1.2.0) - 2024-05-01"
+	tagPrefix  string // prefix of the tags used in the inline links
+}
+
+var (
+	// versionHeaderRegex matches the version headers, with an optional inline link
+	versionHeaderRegex = regexp.MustCompile(`^\s*##\s*\[([^\]]+)\](?:\(([^)\s]*)\))?`)
+
+	// sectionHeadingRegex matches the section headings with any level, capturing the heading after the hashes
+	sectionHeadingRegex = regexp.MustCompile(`(?i)^\s*#+\s*((?:Added|Changed|Deprecated|Removed|Fixed|Security)\b.*)$`)
+
+	// conflictMarkerRegex matches the markers left by Git in the files with unresolved merge conflicts
+	conflictMarkerRegex = regexp.MustCompile(`^(<{7} |={7}$|>{7} )`)
+
+	// conventionalPrefixRegex matches the Conventional Commits prefixes written in the entries
+	conventionalPrefixRegex = regexp.MustCompile(
+		`(?i)^(feat|feature|fix|chore|docs|refactor|perf|test|tests|build|ci|style|revert|security)(\([^)]*\))?(!)?:\s*`,
+	)
+
+	// bulletRegex matches the bullet of the entries, nested or not, capturing its indentation
+	bulletRegex = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+)
+
 var (
 	ErrNoVersionFoundInChangelog  = errors.New("no version found in the changelog")
 	ErrNoChangesFoundInUnreleased = errors.New("no changes found in the unreleased section")
+	ErrChangelogOutsideRepository = errors.New("the changelog links to a file outside the repository")
+	ErrChangelogIsLFSPointer      = errors.New("the changelog is tracked by Git LFS and only its pointer is available")
+	ErrChangelogConflictMarkers   = errors.New("the changelog has merge conflict markers")
 )
 
-func updateChangelogFile(changelogPath string) (*semver.Version, error) {
-	lines, err := readLines(changelogPath)
+// lfsPointerSignature is the first line of the pointer files that replace the files tracked by Git LFS
+const lfsPointerSignature = "version https://git-lfs"
+
+func updateChangelogFile(ctx *RepoContext, changelogPath string) (*semver.Version, error) {
+	lines, err := readLines(changelogPath, getMaxFileSize(ctx.globalConfig))
 	if err != nil {
 		return nil, err
 	}
 
-	version, newContent, err := processChangelog(lines)
+	version, newContent, err := processChangelog(lines, getChangelogConfig(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	newContent, err = archiveChangelogReleases(ctx, changelogPath, newContent)
 	if err != nil {
 		return nil, err
 	}
@@ -40,13 +78,62 @@ func updateChangelogFile(changelogPath string) (*semver.Version, error) {
 	return version, nil
 }
 
-func getNextVersion(changelogPath string) (*semver.Version, error) {
-	lines, err := readLines(changelogPath)
+// resolveChangelogPath follows the CHANGELOG when it is a symlink, so the target is updated
+// and the link itself is preserved. The target must be inside the repository.
+func resolveChangelogPath(projectPath string, changelogPath string, logger *log.Entry) (string, error) {
+	info, err := os.Lstat(changelogPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat changelog file: %w", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return changelogPath, nil
+	}
+
+	relativePath, err := ensureWithinRepo(projectPath, changelogPath)
+	if errors.Is(err, ErrPathOutsideRepository) {
+		return "", fmt.Errorf("%w: %w", ErrChangelogOutsideRepository, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("CHANGELOG is a symlink, updating its target %s", relativePath)
+	return filepath.Join(projectPath, relativePath), nil
+}
+
+// isLFSPointer checks whether the lines are the content of a Git LFS pointer file instead of the real file
+func isLFSPointer(lines []string) bool {
+	return len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), lfsPointerSignature)
+}
+
+// findConflictMarker returns the line number (starting at 1) of the first merge conflict marker, or 0 without any
+func findConflictMarker(lines []string) int {
+	for index, line := range lines {
+		if conflictMarkerRegex.MatchString(line) {
+			return index + 1
+		}
+	}
+	return 0
+}
+
+// checkConflictMarkers refuses the CHANGELOG with merge conflict markers, unless they are ignored
+func checkConflictMarkers(lines []string, changelogConfig ChangelogConfig) error {
+	if changelogConfig.IgnoreConflictMarkers {
+		return nil
+	}
+	if line := findConflictMarker(lines); line > 0 {
+		return fmt.Errorf("%w: line %d", ErrChangelogConflictMarkers, line)
+	}
+	return nil
+}
+
+func getNextVersion(ctx *RepoContext, changelogPath string) (*semver.Version, error) {
+	lines, err := readChangelogLines(ctx, changelogPath)
 	if err != nil {
 		return nil, err
 	}
 
-	version, _, err := processChangelog(lines)
+	version, _, err := processChangelog(lines, getChangelogConfig(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -54,21 +141,36 @@ func getNextVersion(changelogPath string) (*semver.Version, error) {
 	return version, nil
 }
 
-// createChangelogIfNotExists create an empty CHANGELOG file if it doesn't exist
-func createChangelogIfNotExists(changelogPath string) (bool, error) {
+// getChangelogConfig returns the CHANGELOG settings along with the repository information used to build links
+func getChangelogConfig(ctx *RepoContext) ChangelogConfig {
+	changelogConfig := ctx.globalConfig.Changelog
+	changelogConfig.VersioningScheme = ctx.projectConfig.VersioningScheme
+	changelogConfig.CalVerFormat = ctx.projectConfig.CalVerFormat
+	changelogConfig.Date = getReleaseDate(ctx.globalConfig)
+	changelogConfig.VersionStreams = ctx.projectConfig.VersionStreams
+	changelogConfig.DefaultVersionStream = ctx.projectConfig.DefaultVersionStream
+	changelogConfig.VersionPolicy = ctx.globalConfig.VersionPolicy
+	changelogConfig.VersionPolicyDir = ctx.projectConfig.Path
+	changelogConfig.VersionPrefix = ctx.state.resolvedVersionPrefix
+	changelogConfig.logEntry = ctx.logEntry
+	if ctx.repo != nil {
+		changelogConfig.RepositoryURL, _ = getRemoteRepoURL(ctx.repo)
+	}
+	return changelogConfig
+}
+
+// createChangelogIfNotExists create a CHANGELOG file from the template if it doesn't exist
+func createChangelogIfNotExists(ctx *RepoContext, changelogPath string) (bool, error) {
 	if _, err := os.Stat(changelogPath); os.IsNotExist(err) {
-		log.Warnf("Creating empty CHANGELOG file at '%s'.", changelogPath)
-		var fileContent []byte
-		fileContent, err = downloadFile(defaultChangelogURL)
-		if err != nil {
-			log.Errorf("It wasn't possible to download the CHANGELOG model file: %v", err)
-		}
+		ctx.logger().Warnf("Creating empty CHANGELOG file at '%s'.", changelogPath)
+		fileContent, source := getChangelogContent(ctx.globalConfig, ctx.projectConfig, ctx.logger())
 
 		err = os.WriteFile(changelogPath, fileContent, 0o644) //nolint:gosec // the CHANGLOG file is not sensitive
 		if err != nil {
-			log.Errorf("Error creating CHANGELOG file: %v", err)
+			ctx.logger().Errorf("Error creating CHANGELOG file: %v", err)
 			return false, fmt.Errorf("error creating CHANGELOG file: %w", err)
 		}
+		ctx.globalConfig.templateCache.record(source)
 
 		return false, nil
 	}
@@ -76,29 +178,154 @@ func createChangelogIfNotExists(changelogPath string) (bool, error) {
 	return true, nil
 }
 
-func isChangelogUnreleasedEmpty(lines []string) (bool, error) {
+// UnreleasedSummary describes the content found in the "Unreleased" section of the CHANGELOG
+type UnreleasedSummary struct {
+	Empty             bool                // whether there are no entries to be released
+	CandidateLines    int                 // amount of non-blank lines that could be entries
+	RecognizedEntries int                 // amount of lines recognized as entries of a section
+	CarriedEntries    int                 // amount of entries of the non-bumping sections
+	SectionCounts     map[string]int      // amount of entries recognized per section
+	SectionEntries    map[string][]string // entries recognized per section
+	LatestVersion     *semver.Version     // latest released version
+	UnreleasedLine    int                 // position of the "Unreleased" heading in the file
+	UnrecognizedLines []int               // positions of the candidate lines not recognized as entries
+}
+
+// changelogLine is a line of the CHANGELOG along with its position (starting at 1) in the file
+type changelogLine struct {
+	number int
+	text   string
+}
+
+// getUnreleasedSummary parses the "Unreleased" section the same way it is done when bumping,
+// to report how many lines were seen and how many of them were recognized as entries
+func getUnreleasedSummary(lines []string, changelogConfig ChangelogConfig) (UnreleasedSummary, error) {
 	latestVersion, err := findLatestVersion(lines)
 	if err != nil {
-		return true, err
+		return UnreleasedSummary{
+			Empty:          true,
+			SectionCounts:  make(map[string]int),
+			SectionEntries: make(map[string][]string),
+		}, err
+	}
+
+	summary := summarizeUnreleased(lines, changelogConfig, func(line string) bool {
+		return isVersionHeader(line, latestVersion)
+	})
+	summary.LatestVersion = latestVersion
+	return summary, nil
+}
+
+// summarizeUnreleased builds the summary of the "Unreleased" section, which ends at the given release header.
+// The section is empty when it only has entries of the non-bumping sections.
+func summarizeUnreleased(
+	lines []string,
+	changelogConfig ChangelogConfig,
+	isReleaseHeader func(line string) bool,
+) UnreleasedSummary {
+	nonBumpingSections := changelogConfig.NonBumpingSections
+	summary := UnreleasedSummary{
+		Empty:          true,
+		SectionCounts:  make(map[string]int),
+		SectionEntries: make(map[string][]string),
 	}
 
+	var unreleasedLines []changelogLine
 	unreleased := false
-	for _, line := range lines {
+	frontMatterLength := getFrontMatterLength(lines)
+	for index, line := range lines {
+		if index < frontMatterLength {
+			continue
+		}
 		if strings.Contains(line, "[Unreleased]") {
 			unreleased = true
-		} else if strings.HasPrefix(line, fmt.Sprintf("## [%s]", latestVersion.String())) {
+			summary.UnreleasedLine = index + 1
+			continue
+		} else if isReleaseHeader(line) {
 			unreleased = false
 		}
 
 		if unreleased {
-			re := regexp.MustCompile(`^\s*-\s*[^ ]+`)
-			if match := re.MatchString(line); match {
-				return false, nil
+			unreleasedLines = append(unreleasedLines, changelogLine{number: index + 1, text: line})
+		}
+	}
+
+	// copy the section to avoid changing the original lines when fixing the headings
+	unreleasedSection := make([]string, 0, len(unreleasedLines))
+	for _, line := range unreleasedLines {
+		unreleasedSection = append(unreleasedSection, line.text)
+	}
+	fixSectionHeadings(unreleasedSection, getSectionAliases(changelogConfig))
+
+	sections := newChangelogSections(nonBumpingSections)
+	majorChanges, minorChanges, patchChanges := 0, 0, 0
+	recognized := parseUnreleasedIntoSections(
+		unreleasedSection,
+		sections,
+		nil,
+		nil,
+		nonBumpingSections,
+		&majorChanges,
+		&minorChanges,
+		&patchChanges,
+		changelogConfig.logger(),
+	)
+
+	reportOrphanEntries := getChangelogProfile(changelogConfig).reportOrphanEntries
+	for index, line := range unreleasedLines {
+		trimmedLine := strings.TrimSpace(line.text)
+		if trimmedLine != "" && trimmedLine != "-" && !strings.HasPrefix(trimmedLine, "#") {
+			summary.CandidateLines++
+			if !recognized[index] && reportOrphanEntries {
+				summary.UnrecognizedLines = append(summary.UnrecognizedLines, line.number)
 			}
 		}
 	}
 
-	return true, nil
+	for header, section := range sections {
+		if len(*section) > 0 {
+			summary.SectionCounts[header] = len(*section)
+			summary.SectionEntries[header] = *section
+			summary.RecognizedEntries += len(*section)
+			if slices.Contains(nonBumpingSections, header) {
+				summary.CarriedEntries += len(*section)
+			}
+		}
+	}
+	summary.Empty = summary.RecognizedEntries == summary.CarriedEntries
+
+	return summary
+}
+
+// changelogSectionsOrder is the order of the Keep a Changelog sections in a release
+var changelogSectionsOrder = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// newChangelogSections creates the sections supported in the CHANGELOG, indexed by their headings,
+// along with the custom sections (e.g. the non-bumping "Internal" section)
+func newChangelogSections(customSections []string) map[string]*[]string {
+	sections := make(map[string]*[]string, len(changelogSectionsOrder)+len(customSections))
+	for _, header := range changelogSectionsOrder {
+		sections[header] = &[]string{}
+	}
+	for _, header := range customSections {
+		if _, exists := sections[header]; !exists {
+			sections[header] = &[]string{}
+		}
+	}
+	return sections
+}
+
+// getSectionsOrder returns the headings of the sections in the order they are released:
+// the Keep a Changelog sections first, then the custom sections sorted alphabetically
+func getSectionsOrder[T any](sections map[string]T) []string {
+	var customSections []string
+	for header := range sections {
+		if !slices.Contains(changelogSectionsOrder, header) {
+			customSections = append(customSections, header)
+		}
+	}
+	sort.Strings(customSections)
+	return append(slices.Clone(changelogSectionsOrder), customSections...)
 }
 
 func findLatestVersion(lines []string) (*semver.Version, error) {
@@ -106,7 +333,7 @@ func findLatestVersion(lines []string) (*semver.Version, error) {
 	versionRegex := regexp.MustCompile(`^\s*##\s*\[([^\]]+)\]`)
 
 	var latestVersion *semver.Version
-	for _, line := range lines {
+	for _, line := range lines[getFrontMatterLength(lines):] {
 		if versionMatch := versionRegex.FindStringSubmatch(line); versionMatch != nil {
 			// Skip the "Unreleased" version
 			if versionMatch[1] == "Unreleased" {
@@ -115,7 +342,6 @@ func findLatestVersion(lines []string) (*semver.Version, error) {
 
 			version, err := semver.NewVersion(versionMatch[1])
 			if err != nil {
-				log.Errorf("Error parsing version '%s': %v", versionMatch[1], err)
 				return nil, fmt.Errorf("error parsing version '%s': %w", versionMatch[1], err)
 			}
 
@@ -132,33 +358,45 @@ func findLatestVersion(lines []string) (*semver.Version, error) {
 	return latestVersion, nil
 }
 
-func processChangelog(lines []string) (*semver.Version, []string, error) {
+func processChangelog(lines []string, changelogConfig ChangelogConfig) (*semver.Version, []string, error) {
+	// the front matter is kept as it is, above the processed content
+	frontMatter, lines := splitFrontMatter(lines)
+
 	// Variables to hold the new content
 	var newContent []string
 	var unreleasedSection []string
 	unreleased := false
 
+	err := checkConflictMarkers(lines, changelogConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Find the latest version in the changelog
 	latestVersion, err := findLatestVersion(lines)
 	if err != nil {
-		log.Errorf("Error finding latest version: %v", err)
 		return nil, nil, err
 	}
-	log.Infof("Previous version: %s", latestVersion)
+	changelogConfig.logger().Infof("Previous version: %s", versionString(latestVersion))
+	headerStyle := detectVersionHeaderStyle(lines)
 
 	nextVersion := *latestVersion
 	for _, line := range lines {
 		if strings.Contains(line, "[Unreleased]") {
 			unreleased = true
-		} else if strings.HasPrefix(line, fmt.Sprintf("## [%s]", latestVersion.String())) {
+		} else if isVersionHeader(line, latestVersion) {
 			unreleased = false
 			if len(unreleasedSection) > 0 {
 				// Process the unreleased section
 				var updatedSection []string
 				var updatedVersion *semver.Version
-				updatedSection, updatedVersion, err = updateSection(unreleasedSection, nextVersion)
+				updatedSection, updatedVersion, err = updateSection(
+					unreleasedSection,
+					nextVersion,
+					changelogConfig,
+					headerStyle,
+				)
 				if err != nil {
-					log.Errorf("Error updating section: %v", err)
 					return nil, nil, err
 				}
 				// Add the updated section to the new content
@@ -175,25 +413,176 @@ func processChangelog(lines []string) (*semver.Version, []string, error) {
 		}
 	}
 
-	log.Infof("Next calculated version: %s", nextVersion)
-	return &nextVersion, newContent, nil
+	changelogConfig.logger().Infof("Next calculated version: %s", versionString(&nextVersion))
+	return &nextVersion, joinFrontMatter(frontMatter, newContent), nil
 }
 
-// fixSectionHeadings fixes the section headings in the unreleased section
-func fixSectionHeadings(unreleasedSection []string) {
-	re := regexp.MustCompile(`(?i)^\s*#+\s*(Added|Changed|Deprecated|Removed|Fixed|Security)`)
+// normalizeEntries converts the entries to the same style, see normalizeEntry.
+// The section headings must be fixed first, since the section of each entry is read from them.
+func normalizeEntries(unreleasedSection []string, logger *log.Entry) {
+	section := ""
 	for i, line := range unreleasedSection {
-		if re.MatchString(line) {
-			correctedLine := "### " + strings.TrimSpace(strings.ReplaceAll(line, "#", ""))
-			unreleasedSection[i] = correctedLine
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "#") {
+			section = strings.TrimSpace(strings.TrimLeft(trimmedLine, "#"))
+			continue
 		}
+		unreleasedSection[i] = normalizeEntry(line, section, logger)
 	}
 }
 
-// makeNewSections creates new section contents for the beginning of the CHANGELOG file
+// normalizeEntry converts the entry of the section to the same style: dashes as bullets, no trailing whitespaces
+// and no Conventional Commits prefix of the type of the section (e.g. "fix:" under "Fixed"), which already conveys
+// it. The prefix of another type is kept with a warning, since the entry may be in the wrong section.
+func normalizeEntry(line string, section string, logger *log.Entry) string {
+	match := bulletRegex.FindStringSubmatch(line)
+	if match == nil {
+		return line
+	}
+
+	text := strings.TrimRight(line[len(match[0]):], " \t")
+	if prefix := conventionalPrefixRegex.FindStringSubmatch(text); prefix != nil {
+		typeSection, found := commitTypeSections[strings.ToLower(prefix[1])]
+		if found && strings.HasPrefix(section, typeSection) {
+			text = stripConventionalPrefix(text)
+		} else {
+			logger.Warnf("Keeping the prefix of the entry %q, its type doesn't match the section %q", text, section)
+		}
+	}
+	return match[1] + "- " + text
+}
+
+// stripConventionalPrefix removes the Conventional Commits prefix of the entry text,
+// turning the "!" marker of the breaking changes into the breaking change prefix
+func stripConventionalPrefix(text string) string {
+	prefix := conventionalPrefixRegex.FindStringSubmatch(text)
+	if prefix == nil {
+		return text
+	}
+
+	text = text[len(prefix[0]):]
+	if prefix[3] != "" && !strings.HasPrefix(text, "**BREAKING CHANGE:**") {
+		text = "**BREAKING CHANGE:** " + text
+	}
+	return text
+}
+
+// fixSectionHeadings fixes the level of the section headings in the unreleased section and translates the
+// headings written in other languages (e.g. "Añadido" to "Added"), keeping the rest of the heading verbatim
+// and ignoring the lines inside fenced code blocks. It returns the translated heading of each section.
+func fixSectionHeadings(unreleasedSection []string, aliases []sectionAlias) map[string]string {
+	translatedHeadings := make(map[string]string)
+	insideCodeBlock := false
+	for i, line := range unreleasedSection {
+		if isCodeFence(line) {
+			insideCodeBlock = !insideCodeBlock
+			continue
+		}
+		if insideCodeBlock {
+			continue
+		}
+
+		if match := sectionHeadingRegex.FindStringSubmatch(line); match != nil {
+			unreleasedSection[i] = "### " + match[1]
+		} else if match = anyHeadingRegex.FindStringSubmatch(line); match != nil {
+			if alias, found := findSectionAlias(match[1], aliases); found {
+				heading := match[1][:len(alias.alias)]
+				unreleasedSection[i] = "### " + alias.section + match[1][len(heading):]
+				if _, exists := translatedHeadings[alias.section]; !exists {
+					translatedHeadings[alias.section] = heading
+				}
+			}
+		}
+	}
+	return translatedHeadings
+}
+
+// isCodeFence checks whether the line opens or closes a fenced code block
+func isCodeFence(line string) bool {
+	trimmedLine := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmedLine, "```") || strings.HasPrefix(trimmedLine, "~~~")
+}
+
+// parseVersionHeader reads the version and the inline link of a version header, whatever its style
+// (e.g. "## [1.2.0] - 2024-05-01" or "## [1.2.0](https://github.com/org/repo/compare/v1.1.0...v1.2.0)")
+func parseVersionHeader(line string) (string, string, bool) {
+	match := versionHeaderRegex.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// isVersionHeader tells whether the line is the header of the released version, whatever its style
+func isVersionHeader(line string, version *semver.Version) bool {
+	headerVersion, _, found := parseVersionHeader(line)
+	return found && headerVersion == version.Original()
+}
+
+// detectVersionHeaderStyle detects the style of the most recent released version header: the one of the highest
+// version, or the first one when the versions aren't semantic (e.g. the prefixed headers of the version streams)
+func detectVersionHeaderStyle(lines []string) versionHeaderStyle {
+	var latestVersion *semver.Version
+	latestHeader, latestLink := "", ""
+	for _, line := range lines {
+		headerVersion, link, found := parseVersionHeader(line)
+		if !found || headerVersion == "Unreleased" {
+			continue
+		}
+
+		version, err := semver.NewVersion(headerVersion)
+		if err != nil {
+			version = nil
+		}
+		isHigher := version != nil && (latestVersion == nil || version.GreaterThan(latestVersion))
+		if latestHeader == "" || isHigher {
+			latestVersion, latestHeader, latestLink = version, headerVersion, link
+		}
+	}
+
+	style := versionHeaderStyle{inlineLink: latestLink != ""}
+	if latestLink != "" && strings.Contains(latestLink, "v"+latestHeader) {
+		style.tagPrefix = "v"
+	}
+	return style
+}
+
+// formatVersionHeader creates the header of a new version following the style of the previous headers
+func formatVersionHeader(
+	previousVersion semver.Version,
+	nextVersion semver.Version,
+	repositoryURL string,
+	style versionHeaderStyle,
+	releaseDate time.Time,
+	versionPrefix string,
+	logger *log.Entry,
+) string {
+	date := releaseDate.Format("2006-01-02")
+	if style.inlineLink {
+		// the tags already carry the "v" written before the version in the header
+		tagPrefix := style.tagPrefix
+		if strings.HasSuffix(versionPrefix, tagPrefix) {
+			tagPrefix = ""
+		}
+		compareURL := buildCompareURL(
+			repositoryURL,
+			tagPrefix+versionPrefix+versionString(&previousVersion),
+			tagPrefix+versionPrefix+versionString(&nextVersion),
+		)
+		if compareURL != "" {
+			return fmt.Sprintf("## [%s%s](%s) - %s", versionPrefix, versionString(&nextVersion), compareURL, date)
+		}
+		logger.Warn("Unable to build the compare link from the remote URL, using a plain version header")
+	}
+	return fmt.Sprintf("## [%s%s] - %s", versionPrefix, versionString(&nextVersion), date)
+}
+
+// makeNewSections creates new section contents for the beginning of the CHANGELOG file,
+// writing the sections with their translated heading when they have one
 func makeNewSections(
 	sections map[string]*[]string,
-	nextVersion semver.Version,
+	versionHeader string,
+	headings map[string]string,
 ) []string {
 	var newSection []string
 	// Create a new unreleased section
@@ -201,21 +590,21 @@ func makeNewSections(
 	newSection = append(newSection, "")
 
 	// Create the new section with the next version and the current date
-	newSection = append(
-		newSection,
-		fmt.Sprintf("## [%s] - %s", nextVersion.String(), time.Now().Format("2006-01-02")),
-	)
+	newSection = append(newSection, versionHeader)
 	// add a blank line between sections
 	newSection = append(newSection, "")
 
 	// Add the sections to the newly created release section
-	keys := []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
-	for _, key := range keys {
+	for _, key := range getSectionsOrder(sections) {
 		section := sections[key]
 
 		// Append sections only if they have content
-		if len(*section) > 0 {
-			newSection = append(newSection, "### "+key)
+		if section != nil && len(*section) > 0 {
+			heading := key
+			if translatedHeading, found := headings[key]; found {
+				heading = translatedHeading
+			}
+			newSection = append(newSection, "### "+heading)
 			newSection = append(newSection, "")
 			newSection = append(newSection, *section...)
 			newSection = append(newSection, "")
@@ -224,19 +613,94 @@ func makeNewSections(
 	return newSection
 }
 
+// limitSectionEntries keeps the first entries of a section along with all its breaking changes,
+// summarizing the remaining ones in a final entry
+func limitSectionEntries(entries []string, maxEntries int) []string {
+	if maxEntries <= 0 || len(entries) <= maxEntries {
+		return entries
+	}
+
+	kept := make([]string, 0, maxEntries)
+	omitted := 0
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, breakingEntryPrefix) || len(kept) < maxEntries-1 {
+			kept = append(kept, entry)
+		} else {
+			omitted++
+		}
+	}
+
+	switch omitted {
+	case 0:
+	case 1:
+		kept = append(kept, "- …and 1 more change")
+	default:
+		kept = append(kept, fmt.Sprintf("- …and %d more changes", omitted))
+	}
+	return kept
+}
+
+// hasSummarizedSections checks whether any section has more entries than the maximum
+func hasSummarizedSections(sectionEntries map[string][]string, maxEntries int) bool {
+	if maxEntries <= 0 {
+		return false
+	}
+	for _, entries := range sectionEntries {
+		if len(entries) > maxEntries {
+			return true
+		}
+	}
+	return false
+}
+
+// formatReleaseNotes lists all the entries of the release per section, in the same order as the CHANGELOG
+func formatReleaseNotes(sectionEntries map[string][]string, changelogConfig ChangelogConfig) string {
+	profile := getChangelogProfile(changelogConfig)
+	var releaseNotes []string
+	for _, key := range getSectionsOrder(sectionEntries) {
+		if len(sectionEntries[key]) == 0 {
+			continue
+		}
+
+		entries := append([]string(nil), sectionEntries[key]...)
+		profile.sortEntries(entries)
+		releaseNotes = append(releaseNotes, "### "+key, "")
+		releaseNotes = append(releaseNotes, entries...)
+		releaseNotes = append(releaseNotes, "")
+	}
+	return strings.Join(releaseNotes, "\n")
+}
+
+// parseUnreleasedIntoSections splits the entries into their sections and counts the changes,
+// returning the indexes of the lines recognized as entries. The entries of the non-bumping sections
+// are carried into their sections without being counted.
 func parseUnreleasedIntoSections(
 	unreleasedSection []string,
 	sections map[string]*[]string,
 	currentSection *[]string,
+	classifiers []entryClassifier,
+	nonBumpingSections []string,
 	majorChanges, minorChanges, patchChanges *int,
-) {
-	for _, line := range unreleasedSection {
+	logger *log.Entry,
+) map[int]bool {
+	recognized := make(map[int]bool)
+	notes := findMigrationNotes(unreleasedSection)
+	currentHeader := ""
+	for index, line := range unreleasedSection {
 		trimmedLine := strings.TrimSpace(line)
 
+		// the migration notes are attached to their entries, so they are sorted along with them
+		if _, isNote := notes[index]; isNote && currentSection != nil && len(*currentSection) > 0 {
+			(*currentSection)[len(*currentSection)-1] += "\n" + line
+			recognized[index] = true
+			continue
+		}
+
 		// Check if the line is a section header
 		for header := range sections {
 			if strings.HasPrefix(trimmedLine, "### "+header) {
 				currentSection = sections[header]
+				currentHeader = header
 			}
 		}
 
@@ -244,46 +708,72 @@ func parseUnreleasedIntoSections(
 		if currentSection != nil && trimmedLine != "" && trimmedLine != "-" &&
 			!strings.HasPrefix(trimmedLine, "##") {
 			*currentSection = append(*currentSection, line)
+			recognized[index] = true
+
+			if slices.Contains(nonBumpingSections, currentHeader) {
+				logger.Infof("Entry %q carried, non-bumping (section %q)", trimmedLine, currentHeader)
+				continue
+			}
 
 			// Increment the change counters based on the line content
-			switch {
-			case strings.HasPrefix(line, "- **BREAKING CHANGE:**"):
+			level, rule := classifyEntry(line, currentSection == sections["Added"], classifiers)
+			if rule != nil {
+				logger.Infof("Entry %q classified as a %s change by the rule %q", trimmedLine, level, rule.Pattern)
+			}
+			switch level {
+			case changeLevelMajor:
 				*majorChanges++
-			case currentSection == sections["Added"]:
+			case changeLevelMinor:
 				*minorChanges++
 			default:
 				*patchChanges++
 			}
 		}
 	}
+	return recognized
 }
 
 func updateSection(
 	unreleasedSection []string,
 	nextVersion semver.Version,
+	changelogConfig ChangelogConfig,
+	headerStyle versionHeaderStyle,
 ) ([]string, *semver.Version, error) {
 	// Fix the section headings
-	fixSectionHeadings(unreleasedSection)
+	translatedHeadings := fixSectionHeadings(unreleasedSection, getSectionAliases(changelogConfig))
 
-	sections := map[string]*[]string{
-		"Added":      {},
-		"Changed":    {},
-		"Deprecated": {},
-		"Removed":    {},
-		"Fixed":      {},
-		"Security":   {},
+	// Normalize the entries style, once their sections are known
+	if changelogConfig.NormalizeEntries {
+		normalizeEntries(unreleasedSection, changelogConfig.logger())
 	}
 
+	// Remove the duplicated entries, before counting the changes
+	profile := getChangelogProfile(changelogConfig)
+	var removals []dedupRemoval
+	if profile.deduplicate {
+		unreleasedSection, removals = deduplicateEntries(unreleasedSection, changelogConfig)
+	}
+
+	sections := newChangelogSections(changelogConfig.NonBumpingSections)
+
 	var currentSection *[]string
 	majorChanges, minorChanges, patchChanges := 0, 0, 0
 
+	classifiers, err := getEntryClassifiers(changelogConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	parseUnreleasedIntoSections(
 		unreleasedSection,
 		sections,
 		currentSection,
+		classifiers,
+		changelogConfig.NonBumpingSections,
 		&majorChanges,
 		&minorChanges,
 		&patchChanges,
+		changelogConfig.logger(),
 	)
 
 	// If no changes were found, return an error
@@ -291,20 +781,64 @@ func updateSection(
 		return nil, nil, ErrNoChangesFoundInUnreleased
 	}
 
+	releaseDate := changelogConfig.Date
+	if releaseDate.IsZero() {
+		releaseDate = time.Now()
+	}
+
+	previousVersion := nextVersion
+	policy := changelogConfig.VersionPolicy
+	var bump string
 	switch {
+	case changelogConfig.VersioningScheme == versioningSchemeCalVer:
+		// CalVer versions are derived from the date, regardless of the kind of changes
+		calVer, err := getNextCalVer(previousVersion, changelogConfig.CalVerFormat, releaseDate)
+		if err != nil {
+			return nil, nil, err
+		}
+		nextVersion, bump = *calVer, versioningSchemeCalVer
+	case majorChanges > 0 && isPre1BreakingMinor(policy, previousVersion):
+		nextVersion, bump = nextVersion.IncMinor(), "minor"
 	case majorChanges > 0:
-		nextVersion = nextVersion.IncMajor()
+		nextVersion, bump = nextVersion.IncMajor(), "major"
 	case minorChanges > 0:
-		nextVersion = nextVersion.IncMinor()
+		nextVersion, bump = nextVersion.IncMinor(), "minor"
 	case patchChanges > 0:
-		nextVersion = nextVersion.IncPatch()
+		nextVersion, bump = nextVersion.IncPatch(), "patch"
 	}
 
-	// Sort the items inside the sections alphabetically
+	removedDuplicates := make([]string, 0, len(removals))
+	for _, removal := range removals {
+		removedDuplicates = append(removedDuplicates, removal.String())
+	}
+	nextVersion, err = applyVersionPolicyCommand(policy, changelogConfig.VersionPolicyDir, versionPolicyInput{
+		PreviousVersion:   versionString(&previousVersion),
+		ComputedVersion:   versionString(&nextVersion),
+		Bump:              bump,
+		MajorChanges:      majorChanges,
+		MinorChanges:      minorChanges,
+		PatchChanges:      patchChanges,
+		RemovedDuplicates: removedDuplicates,
+	}, nextVersion, previousVersion, changelogConfig.logger())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sort the items inside the sections alphabetically, as the formatting profile does
 	for _, section := range sections {
-		sort.Strings(*section)
+		profile.sortEntries(*section)
+		*section = limitSectionEntries(*section, changelogConfig.MaxEntriesPerSection)
 	}
 
-	newSection := makeNewSections(sections, nextVersion)
+	versionHeader := formatVersionHeader(
+		previousVersion,
+		nextVersion,
+		changelogConfig.RepositoryURL,
+		headerStyle,
+		releaseDate,
+		changelogConfig.VersionPrefix,
+		changelogConfig.logger(),
+	)
+	newSection := makeNewSections(sections, versionHeader, getOutputHeadings(changelogConfig, translatedHeadings))
 	return newSection, &nextVersion, nil
 }
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,32 +48,32 @@ const changelogExpected = changelogTemplate + `
 
 - New feature.`
 
-func TestIsChangelogUnreleasedEmpty_False(t *testing.T) {
+func TestGetUnreleasedSummary_False(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
 	changelog := strings.Split(changelogOriginal, "\n")
 
 	// Act
-	result, err := isChangelogUnreleasedEmpty(changelog)
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{})
 
 	// Assert
 	require.NoError(t, err)
-	assert.False(t, result)
+	assert.False(t, result.Empty)
 }
 
-func TestIsChangelogUnreleasedEmpty_True(t *testing.T) {
+func TestGetUnreleasedSummary_True(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
 	changelog := strings.Split(changelogTemplate, "\n")
 
 	// Act
-	result, err := isChangelogUnreleasedEmpty(changelog)
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{})
 
 	// Assert
 	require.ErrorIs(t, err, ErrNoVersionFoundInChangelog)
-	assert.True(t, result)
+	assert.True(t, result.Empty)
 }
 
 func TestFindLatestVersion_Success(t *testing.T) {
@@ -112,7 +114,7 @@ func TestProcessChangelog_Success(t *testing.T) {
 	changelog := strings.Split(changelogOriginal, "\n")
 
 	// Act
-	version, newChangelog, err := processChangelog(changelog)
+	version, newChangelog, err := processChangelog(changelog, ChangelogConfig{})
 
 	// Assert
 	require.NoError(t, err)
@@ -136,8 +138,652 @@ func TestProcessChangelog_NoPreviousVersions(t *testing.T) {
 	changelog := strings.Split(changelogTemplate, "\n")
 
 	// Act
-	_, _, err := processChangelog(changelog)
+	_, _, err := processChangelog(changelog, ChangelogConfig{})
 
 	// Assert
 	require.ErrorIs(t, err, ErrNoVersionFoundInChangelog)
 }
+
+func TestGetUnreleasedSummary_AsteriskBullets(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(strings.Replace(changelogOriginal, "- Another", "* Another", 1), "\n")
+
+	// Act
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.Empty)
+}
+
+func TestGetUnreleasedSummary_SectionCounts(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogOriginal, "\n")
+
+	// Act
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.CandidateLines)
+	assert.Equal(t, 1, result.RecognizedEntries)
+	assert.Equal(t, map[string]int{"Added": 1}, result.SectionCounts)
+}
+
+func TestGetUnreleasedSummary_EntriesAboveSectionHeading(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(`# Changelog
+
+## [Unreleased]
+
+- An entry without section.
+- Another entry without section.
+
+### Added
+
+-
+
+## [1.0.0] - 2024-01-01
+
+### Added
+
+- Initial release.`, "\n")
+
+	// Act
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Empty)
+	assert.Equal(t, 2, result.CandidateLines)
+	assert.Equal(t, 0, result.RecognizedEntries)
+	assert.Empty(t, result.SectionCounts)
+}
+
+func TestNormalizeEntries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		heading  string
+		entry    string
+		expected string
+	}{
+		{"### Fixed", "* fixed the asterisk bullet", "- fixed the asterisk bullet"},
+		{"### Fixed", "+ fixed the plus bullet", "- fixed the plus bullet"},
+		{"### Fixed", "- Fix: fixed the prefix", "- fixed the prefix"},
+		{"### Fixed", "- fix(parser): fixed the scoped prefix", "- fixed the scoped prefix"},
+		{"### Added", "- feat!: removed the old API", "- **BREAKING CHANGE:** removed the old API"},
+		{"### Changed", "- perf: sped up the parser", "- sped up the parser"},
+		{"### Fixed", "- fixed the trailing whitespaces  \t", "- fixed the trailing whitespaces"},
+		{"### Fixed", "  * nested entry", "  - nested entry"},
+		{"### Fixed", "### Fixed", "### Fixed"},
+	}
+
+	for _, testCase := range testCases {
+		// Arrange
+		section := []string{testCase.heading, testCase.entry}
+
+		// Act
+		normalizeEntries(section, standardLogEntry())
+
+		// Assert
+		assert.Equal(t, testCase.expected, section[1])
+	}
+}
+
+func TestNormalizeEntries_PrefixOfAnotherSection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	logger, hook := test.NewNullLogger()
+	section := []string{
+		"### Added",
+		"- fix: fixed the login  ",
+		"- chore: upgraded the CI images",
+		"",
+		"### Internal",
+		"- feat: added the metrics",
+	}
+
+	// Act
+	normalizeEntries(section, log.NewEntry(logger))
+
+	// Assert
+	assert.Equal(t, []string{
+		"### Added",
+		"- fix: fixed the login",
+		"- chore: upgraded the CI images",
+		"",
+		"### Internal",
+		"- feat: added the metrics",
+	}, section)
+	var warnings []string
+	for _, entry := range hook.AllEntries() {
+		warnings = append(warnings, entry.Message)
+	}
+	assert.Equal(t, []string{
+		`Keeping the prefix of the entry "fix: fixed the login", its type doesn't match the section "Added"`,
+		`Keeping the prefix of the entry "chore: upgraded the CI images", its type doesn't match the section "Added"`,
+		`Keeping the prefix of the entry "feat: added the metrics", its type doesn't match the section "Internal"`,
+	}, warnings)
+}
+
+func TestProcessChangelog_NormalizeEntriesKeepsHistory(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	history := "## [1.0.1] - 1984-01-01\n\n### Fixed\n\n* fix: old entry  \n+ feat: another old entry"
+	changelog := strings.Split(changelogTemplate+"\n\n### Fixed\n\n* fix(core): new entry\n\n"+history, "\n")
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, ChangelogConfig{NormalizeEntries: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.2", version.String())
+
+	newChangelogString := strings.Join(newChangelog, "\n")
+	assert.Contains(t, newChangelogString, "### Fixed\n\n- new entry\n")
+	assert.True(t, strings.HasSuffix(newChangelogString, history))
+}
+
+func TestProcessChangelog_InlineLinkHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(strings.Replace(
+		changelogOriginal,
+		"## [1.0.1] - 1984-01-01",
+		"## [1.0.1](https://github.com/org/repo/compare/v1.0.0...v1.0.1) - 1984-01-01",
+		1,
+	), "\n")
+	changelogConfig := ChangelogConfig{RepositoryURL: "git@github.com:org/repo.git"}
+
+	// Act
+	_, newChangelog, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, newChangelog, fmt.Sprintf(
+		"## [1.1.0](https://github.com/org/repo/compare/v1.0.1...v1.1.0) - %s",
+		time.Now().Format("2006-01-02"),
+	))
+}
+
+func TestProcessChangelog_InlineLinkHeadersWithoutRemote(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(strings.Replace(
+		changelogOriginal,
+		"## [1.0.1] - 1984-01-01",
+		"## [1.0.1](https://github.com/org/repo/compare/1.0.0...1.0.1) - 1984-01-01",
+		1,
+	), "\n")
+
+	// Act
+	_, newChangelog, err := processChangelog(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, newChangelog, "## [1.1.0] - "+time.Now().Format("2006-01-02"))
+}
+
+func TestProcessChangelog_ReleaseHeaderWithoutSpace(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(
+		strings.Replace(changelogOriginal, "## [1.0.1] - 1984-01-01", "##[1.0.1] - 1984-01-01", 1), "\n",
+	)
+	releaseDate := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, ChangelogConfig{Date: releaseDate})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", version.String())
+	assert.Equal(t, strings.Replace(
+		fmt.Sprintf(changelogExpected, "2024-06-08"), "## [1.0.1] - 1984-01-01", "##[1.0.1] - 1984-01-01", 1,
+	), strings.Join(newChangelog, "\n"))
+}
+
+func TestDetectVersionHeaderStyle(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		headers  string
+		expected versionHeaderStyle
+	}{
+		{
+			name:     "plain style",
+			headers:  "## [Unreleased]\n\n## [1.1.0] - 2024-05-01\n\n## [1.0.0] - 2024-01-01",
+			expected: versionHeaderStyle{},
+		},
+		{
+			name:     "inline style",
+			headers:  "## [Unreleased]\n\n## [1.1.0](https://gitlab.com/g/p/-/compare/1.0.0...1.1.0) - 2024-05-01",
+			expected: versionHeaderStyle{inlineLink: true},
+		},
+		{
+			name: "mixed style with the most recent inline",
+			headers: "## [Unreleased]\n\n## [1.1.0](https://github.com/o/r/compare/v1.0.0...v1.1.0) - 2024-05-01" +
+				"\n\n## [1.0.0] - 2024-01-01",
+			expected: versionHeaderStyle{inlineLink: true, tagPrefix: "v"},
+		},
+		{
+			name: "mixed style with the most recent plain",
+			headers: "## [Unreleased]\n\n## [1.1.0] - 2024-05-01" +
+				"\n\n## [1.0.0](https://github.com/o/r/compare/v0.9.0...v1.0.0) - 2024-01-01",
+			expected: versionHeaderStyle{},
+		},
+		{
+			name: "mixed style with the most recent inline listed after an older plain one",
+			headers: "## [Unreleased]\n\n## [1.0.0] - 2024-01-01" +
+				"\n\n## [1.1.0](https://github.com/o/r/compare/v1.0.0...v1.1.0) - 2024-05-01",
+			expected: versionHeaderStyle{inlineLink: true, tagPrefix: "v"},
+		},
+		{
+			name: "mixed style with the most recent plain listed after an older inline one",
+			headers: "## [Unreleased]\n\n## [1.0.0](https://github.com/o/r/compare/v0.9.0...v1.0.0) - 2024-01-01" +
+				"\n\n##[1.1.0] - 2024-05-01",
+			expected: versionHeaderStyle{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		// Act
+		style := detectVersionHeaderStyle(strings.Split(testCase.headers, "\n"))
+
+		// Assert
+		assert.Equal(t, testCase.expected, style, testCase.name)
+	}
+}
+
+func TestBuildCompareURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		remoteURL string
+		expected  string
+	}{
+		{"git@github.com:org/repo.git", "https://github.com/org/repo/compare/v1...v2"},
+		{"https://gitlab.com/group/project.git", "https://gitlab.com/group/project/-/compare/v1...v2"},
+		{
+			"git@ssh.dev.azure.com:v3/org/project/repo",
+			"https://dev.azure.com/org/project/_git/repo/branchCompare?baseVersion=GTv1&targetVersion=GTv2",
+		},
+		{"https://example.com/repo.git", ""},
+	}
+
+	for _, testCase := range testCases {
+		// Act & Assert
+		assert.Equal(t, testCase.expected, buildCompareURL(testCase.remoteURL, "v1", "v2"))
+	}
+}
+
+func TestIsLFSPointer(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	pointer := []string{
+		"version https://git-lfs.github.com/spec/v1",
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+		"size 12345",
+	}
+
+	// Act & Assert
+	assert.True(t, isLFSPointer(pointer))
+	assert.False(t, isLFSPointer(strings.Split(changelogOriginal, "\n")))
+	assert.False(t, isLFSPointer(nil))
+}
+
+func TestFixSectionHeadings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	section := []string{
+		"## [Unreleased]",
+		"#### Added (#123 follow-ups)",
+		"#Fixed",
+		"- Fixed the parser.",
+		"```markdown",
+		"#### Added",
+		"```",
+		"## Changed",
+		"- Added #45 to the list.",
+	}
+
+	// Act
+	fixSectionHeadings(section, nil)
+
+	// Assert
+	assert.Equal(t, []string{
+		"## [Unreleased]",
+		"### Added (#123 follow-ups)",
+		"### Fixed",
+		"- Fixed the parser.",
+		"```markdown",
+		"#### Added",
+		"```",
+		"### Changed",
+		"- Added #45 to the list.",
+	}, section)
+}
+
+func TestLimitSectionEntries_Cutoff(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	entries := []string{"- Change 1.", "- Change 2.", "- Change 3.", "- Change 4.", "- Change 5."}
+
+	// Act
+	limited := limitSectionEntries(entries, 3)
+
+	// Assert
+	assert.Equal(t, []string{"- Change 1.", "- Change 2.", "- …and 3 more changes"}, limited)
+}
+
+func TestLimitSectionEntries_BreakingChangesKept(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	entries := []string{
+		"- Change 1.",
+		"- Change 2.",
+		"- Change 3.",
+		"- **BREAKING CHANGE:** Removed the v1 API.",
+	}
+
+	// Act
+	limited := limitSectionEntries(entries, 2)
+
+	// Assert
+	assert.Equal(t, []string{
+		"- Change 1.",
+		"- **BREAKING CHANGE:** Removed the v1 API.",
+		"- …and 2 more changes",
+	}, limited)
+}
+
+func TestProcessChangelog_MaxEntriesPerSection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Replace(
+		changelogOriginal,
+		"- Another new feature.",
+		"- Feature A.\n- Feature B.\n- Feature C.",
+		1,
+	)
+	changelogConfig := ChangelogConfig{MaxEntriesPerSection: 2}
+
+	// Act
+	_, newChangelog, err := processChangelog(strings.Split(changelog, "\n"), changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	content := strings.Join(newChangelog, "\n")
+	assert.Contains(t, content, "- Feature A.\n- …and 2 more changes")
+	assert.NotContains(t, content, "- Feature C.")
+}
+
+func TestGetPullRequestDescription_FullReleaseNotes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sectionEntries := map[string][]string{
+		"Added": {"- Feature C.", "- Feature A.", "- Feature B."},
+		"Fixed": {"- Fix A."},
+	}
+	projectConfig := &ProjectConfig{}
+	state := &projectState{}
+	if hasSummarizedSections(sectionEntries, 2) {
+		state.releaseNotes = formatReleaseNotes(sectionEntries, ChangelogConfig{})
+	}
+
+	// Act
+	description := getPullRequestDescription(&GlobalConfig{}, projectConfig, state, standardLogEntry())
+
+	// Assert
+	assert.Contains(
+		t,
+		description,
+		"### Added\n\n- Feature A.\n- Feature B.\n- Feature C.\n\n### Fixed\n\n- Fix A.\n",
+	)
+}
+
+func TestGetPullRequestDescription_NothingSummarized(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sectionEntries := map[string][]string{"Added": {"- Feature A."}}
+	projectConfig := &ProjectConfig{}
+	state := &projectState{}
+	if hasSummarizedSections(sectionEntries, 2) {
+		state.releaseNotes = formatReleaseNotes(sectionEntries, ChangelogConfig{})
+	}
+
+	// Act
+	description := getPullRequestDescription(&GlobalConfig{}, projectConfig, state, standardLogEntry())
+
+	// Assert
+	assert.Empty(t, description)
+}
+
+const changelogConflictInUnreleased = changelogTemplate + `
+
+### Added
+
+<<<<<<< HEAD
+- Another new feature.
+=======
+- A different new feature.
+>>>>>>> feature/other
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.`
+
+const changelogConflictInRelease = changelogTemplate + `
+
+### Added
+
+- Another new feature.
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+<<<<<<< HEAD
+- New feature.
+=======
+- New feature, but better.
+>>>>>>> hotfix/1.0.1`
+
+func TestFindConflictMarker_InsideUnreleased(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogConflictInUnreleased, "\n")
+
+	// Act
+	line := findConflictMarker(lines)
+
+	// Assert
+	assert.Equal(t, 12, line)
+}
+
+func TestFindConflictMarker_InsideRelease(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogConflictInRelease, "\n")
+
+	// Act
+	line := findConflictMarker(lines)
+
+	// Assert
+	assert.Equal(t, 18, line)
+}
+
+func TestFindConflictMarker_NoMarkers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := []string{"<<<<<<<HEAD", "========", "- a => b", "- >>>>>>> quoted"}
+
+	// Act
+	line := findConflictMarker(lines)
+	originalLine := findConflictMarker(strings.Split(changelogOriginal, "\n"))
+
+	// Assert
+	assert.Zero(t, line)
+	assert.Zero(t, originalLine)
+}
+
+func TestProcessChangelog_ConflictMarkersRefused(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogConflictInRelease, "\n")
+
+	// Act
+	version, newContent, err := processChangelog(lines, ChangelogConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrChangelogConflictMarkers)
+	assert.ErrorContains(t, err, "line 18")
+	assert.Nil(t, version)
+	assert.Nil(t, newContent)
+}
+
+func TestProcessChangelog_ConflictMarkersIgnored(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogConflictInRelease, "\n")
+
+	// Act
+	version, _, err := processChangelog(lines, ChangelogConfig{IgnoreConflictMarkers: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", version.String())
+}
+
+const changelogOnlyInternal = changelogTemplate + `
+
+### Internal
+
+- Migrated the CI to the new runners.
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.`
+
+const changelogInternalAndFixed = changelogTemplate + `
+
+### Fixed
+
+- Fixed the pagination.
+
+### Internal
+
+- Migrated the CI to the new runners.
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.`
+
+func TestGetUnreleasedSummary_OnlyNonBumpingEntries(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogOnlyInternal, "\n")
+
+	// Act
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{NonBumpingSections: []string{"Internal", "Documentation"}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Empty)
+	assert.Equal(t, 1, result.CarriedEntries)
+	assert.Empty(t, result.UnrecognizedLines)
+}
+
+func TestProcessChangelog_OnlyNonBumpingEntries(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogOnlyInternal, "\n")
+	changelogConfig := ChangelogConfig{NonBumpingSections: []string{"Internal"}}
+
+	// Act
+	_, _, err := processChangelog(lines, changelogConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrNoChangesFoundInUnreleased)
+}
+
+func TestProcessChangelog_NonBumpingEntriesCarried(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogInternalAndFixed, "\n")
+	releaseDate := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	changelogConfig := ChangelogConfig{NonBumpingSections: []string{"Internal"}, Date: releaseDate}
+
+	// Act
+	version, newContent, err := processChangelog(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.2", version.String())
+	assert.Equal(t, changelogTemplate+`
+
+## [1.0.2] - 2024-06-01
+
+### Fixed
+
+- Fixed the pagination.
+
+### Internal
+
+- Migrated the CI to the new runners.
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.`, strings.Join(newContent, "\n"))
+}
+
+func TestGetUnreleasedSummary_NonBumpingSectionsNotConfigured(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogInternalAndFixed, "\n")
+
+	// Act
+	result, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.Empty)
+	assert.Zero(t, result.CarriedEntries)
+}
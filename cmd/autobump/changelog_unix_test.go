@@ -0,0 +1,72 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveChangelogPath_PreservesSymlink(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(projectPath, "docs"), 0o755))
+	targetPath := filepath.Join(projectPath, "docs", "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(targetPath, []byte(changelogOriginal), 0o600))
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	require.NoError(t, os.Symlink(filepath.Join("docs", "CHANGELOG.md"), changelogPath))
+
+	// Act
+	resolvedPath, err := resolveChangelogPath(projectPath, changelogPath, standardLogEntry())
+	require.NoError(t, err)
+	require.NoError(t, writeLines(resolvedPath, strings.Split(changelogTemplate, "\n")))
+
+	// Assert
+	assert.Equal(t, targetPath, resolvedPath)
+	info, err := os.Lstat(changelogPath)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "the CHANGELOG symlink must be preserved")
+	content, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "## [Unreleased]")
+	assert.NotContains(t, string(content), "## [1.0.1]")
+}
+
+func TestResolveChangelogPath_OutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	outsidePath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(outsidePath, []byte(changelogOriginal), 0o600))
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	require.NoError(t, os.Symlink(outsidePath, changelogPath))
+
+	// Act
+	_, err := resolveChangelogPath(projectPath, changelogPath, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrChangelogOutsideRepository)
+}
+
+func TestResolveChangelogPath_RegularFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelogPath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(changelogPath, []byte(changelogOriginal), 0o600))
+
+	// Act
+	resolvedPath, err := resolveChangelogPath(filepath.Dir(changelogPath), changelogPath, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, changelogPath, resolvedPath)
+}
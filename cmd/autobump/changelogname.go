@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// canonicalChangelogName is the name of the CHANGELOG file written by AutoBump
+const canonicalChangelogName = "CHANGELOG.md"
+
+// findChangelogName returns the name of the CHANGELOG among the names of a directory: the canonical name
+// when present, or else a name differing only in the case (e.g. "Changelog.md")
+func findChangelogName(names []string) (string, bool) {
+	found := ""
+	for _, name := range names {
+		if name == canonicalChangelogName {
+			return name, true
+		}
+		if found == "" && strings.EqualFold(name, canonicalChangelogName) {
+			found = name
+		}
+	}
+	return found, found != ""
+}
+
+// findChangelogPath returns the path of the CHANGELOG of the project as named on disk, so a differently-cased
+// file is read, written and staged under its own name (instead of adding "CHANGELOG.md" next to it on the
+// case-sensitive remotes). The canonical path is returned when the project has no CHANGELOG.
+func findChangelogPath(projectConfig *ProjectConfig, logger *log.Entry) string {
+	name, found := findDirChangelogName(projectConfig.Path)
+	if !found {
+		return filepath.Join(projectConfig.Path, canonicalChangelogName)
+	}
+
+	if name != canonicalChangelogName {
+		reportFinding(Finding{
+			Level:   findingNotice,
+			File:    name,
+			Message: fmt.Sprintf("the CHANGELOG is named %s instead of %s", name, canonicalChangelogName),
+		}, logger)
+	}
+	return filepath.Join(projectConfig.Path, name)
+}
+
+// findDirChangelogName returns the name of the CHANGELOG in the directory listing, regardless of the case
+func findDirChangelogName(dir string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return findChangelogName(names)
+}
+
+// addChangelogToWorktree stages the CHANGELOG under its name on disk, or renamed to "CHANGELOG.md"
+// when it has another case and "normalize_changelog_filename" is set
+func addChangelogToWorktree(ctx *RepoContext, changelogPath string) error {
+	name := filepath.Base(changelogPath)
+	if name == canonicalChangelogName || !strings.EqualFold(name, canonicalChangelogName) ||
+		!ctx.projectConfig.NormalizeChangelogFilename {
+		return addFileToWorktree(ctx, changelogPath)
+	}
+
+	info, err := os.Stat(changelogPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat changelog file: %w", err)
+	}
+	content, err := os.ReadFile(changelogPath)
+	if err != nil {
+		return fmt.Errorf("failed to read changelog file: %w", err)
+	}
+	relativePath, err := getWorktreePath(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+
+	// the file is removed before writing the canonical one, since both are the same file on the
+	// case-insensitive filesystems
+	ctx.logger().Infof("Renaming %s to %s", relativePath, canonicalChangelogName)
+	_, err = ctx.worktree.Remove(relativePath)
+	if err != nil {
+		return fmt.Errorf("failed to remove changelog file %s: %w", relativePath, err)
+	}
+	canonicalPath := filepath.Join(filepath.Dir(changelogPath), canonicalChangelogName)
+	err = os.WriteFile(canonicalPath, content, info.Mode())
+	if err != nil {
+		return fmt.Errorf("failed to write changelog file: %w", err)
+	}
+	return addFileToWorktree(ctx, canonicalPath)
+}
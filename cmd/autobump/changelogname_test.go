@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMixedCaseChangelogRepo creates a repository whose CHANGELOG is named "Changelog.md", and updates it
+func newMixedCaseChangelogRepo(t *testing.T, normalize bool) (*RepoContext, string) {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	repo, err := git.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, "Changelog.md", changelogOriginal+"\n")
+
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: projectPath, Name: "api", NormalizeChangelogFilename: normalize},
+	}
+	require.NoError(t, setupRepo(ctx))
+	changelogPath := findChangelogPath(ctx.projectConfig, standardLogEntry())
+	require.NoError(t, os.WriteFile(changelogPath, []byte(changelogOriginal+"\n- Updated.\n"), 0o600))
+	return ctx, changelogPath
+}
+
+func TestFindChangelogName(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	mixedCase, mixedCaseFound := findChangelogName([]string{"README.md", "Changelog.md", "go.mod"})
+	canonical, canonicalFound := findChangelogName([]string{"changelog.md", "CHANGELOG.md"})
+	_, missingFound := findChangelogName([]string{"CHANGES.md", "CHANGELOG.txt"})
+
+	// Assert
+	assert.True(t, mixedCaseFound)
+	assert.Equal(t, "Changelog.md", mixedCase)
+	assert.True(t, canonicalFound)
+	assert.Equal(t, "CHANGELOG.md", canonical)
+	assert.False(t, missingFound)
+}
+
+func TestAddChangelogToWorktree_KeepsTheNameOnDisk(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, changelogPath := newMixedCaseChangelogRepo(t, false)
+
+	// Act
+	err := addChangelogToWorktree(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Changelog.md", filepath.Base(changelogPath))
+	status, err := ctx.worktree.Status()
+	require.NoError(t, err)
+	require.Len(t, status, 1)
+	assert.Equal(t, git.Modified, status.File("Changelog.md").Staging)
+}
+
+func TestAddChangelogToWorktree_NormalizesTheName(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, changelogPath := newMixedCaseChangelogRepo(t, true)
+
+	// Act
+	err := addChangelogToWorktree(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	status, err := ctx.worktree.Status()
+	require.NoError(t, err)
+	require.Len(t, status, 2)
+	assert.Equal(t, git.Deleted, status.File("Changelog.md").Staging)
+	assert.Equal(t, git.Added, status.File("CHANGELOG.md").Staging)
+	content, err := os.ReadFile(filepath.Join(ctx.projectConfig.Path, "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- Updated.")
+}
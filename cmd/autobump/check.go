@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+var ErrChangelogCheckFailed = errors.New("the CHANGELOG doesn't follow the Keep a Changelog format")
+
+// streamHeaderRegex matches the names of the release headers of the version streams, capturing their prefix
+// (e.g. "app-" for "## [app-1.4.0]")
+var streamHeaderRegex = regexp.MustCompile(`^([A-Za-z][\w.]*-)v?\d`)
+
+// findStreamHeaderPrefixes returns the header prefixes of the version streams released in the CHANGELOG,
+// in the order of their first release, none when its versions aren't released by streams
+func findStreamHeaderPrefixes(lines []string) []string {
+	var prefixes []string
+	for _, line := range lines[getFrontMatterLength(lines):] {
+		if !isReleaseHeader(line) {
+			continue
+		}
+		match := streamHeaderRegex.FindStringSubmatch(versionHeaderRegex.FindStringSubmatch(line)[1])
+		if match != nil && !slices.Contains(prefixes, match[1]) {
+			prefixes = append(prefixes, match[1])
+		}
+	}
+	return prefixes
+}
+
+// checkChangelog returns the findings of the CHANGELOG parsed the same way it is done when bumping: the conflict
+// markers, the release headers which aren't versions (of the version streams, when the CHANGELOG has some),
+// the missing "Unreleased" section, the entries which would not be released, the "Unreleased" section without
+// entries unless allowed, and the findings of the lint rules. The problems which would stop a bump are errors.
+func checkChangelog(
+	lines []string,
+	globalConfig *GlobalConfig,
+	projectPath string,
+	changelogFile string,
+	allowEmpty bool,
+) ([]Finding, error) {
+	if isLFSPointer(lines) {
+		return []Finding{{
+			Level: findingError, File: changelogFile, Line: 1, Message: ErrChangelogIsLFSPointer.Error(),
+		}}, nil
+	}
+
+	var findings []Finding
+	addError := func(line int, message string) {
+		findings = append(findings, Finding{Level: findingError, File: changelogFile, Line: line, Message: message})
+	}
+
+	changelogConfig := globalConfig.Changelog
+	if line := findConflictMarker(lines); line > 0 && !changelogConfig.IgnoreConflictMarkers {
+		addError(line, ErrChangelogConflictMarkers.Error())
+	}
+
+	var summary UnreleasedSummary
+	if prefixes := findStreamHeaderPrefixes(lines); len(prefixes) > 0 {
+		for _, prefix := range prefixes {
+			if _, err := findLatestStreamVersion(lines, prefix); err != nil {
+				addError(1, err.Error())
+			}
+		}
+		summary = getStreamsUnreleasedSummary(lines, changelogConfig)
+	} else {
+		var err error
+		summary, err = getUnreleasedSummary(lines, changelogConfig)
+		if err != nil {
+			addError(1, err.Error())
+			// the "Unreleased" section is still checked, up to the first release
+			summary = summarizeUnreleased(lines, changelogConfig, isReleaseHeader)
+		}
+	}
+
+	switch {
+	case summary.UnreleasedLine == 0:
+		addError(1, "no \"## [Unreleased]\" section")
+	case summary.Empty && summary.CarriedEntries > 0 && !allowEmpty:
+		addError(summary.UnreleasedLine, fmt.Sprintf(
+			"the \"Unreleased\" section only has %d entries of the non-bumping sections", summary.CarriedEntries,
+		))
+	case summary.Empty && summary.CandidateLines == 0 && !allowEmpty:
+		addError(summary.UnreleasedLine, "the \"Unreleased\" section has no entries")
+	}
+	// the lines which would not be released fail the check, while they are only warned about when bumping
+	for _, finding := range getUnreleasedFindings(summary, changelogFile) {
+		finding.Level = findingError
+		findings = append(findings, finding)
+	}
+
+	lintFindings, err := getLintFindings(
+		globalConfig.ChangelogLint, projectPath, lines, summary.UnreleasedLine, changelogFile,
+	)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, lintFindings...)
+
+	slices.SortStableFunc(findings, func(a, b Finding) int { return a.Line - b.Line })
+	return findings, nil
+}
+
+// writeChangelogCheck reports the findings of the CHANGELOG, failing when any of them is an error
+func writeChangelogCheck(output io.Writer, changelogFile string, findings []Finding) error {
+	errorCount := 0
+	for _, finding := range findings {
+		reportFinding(finding, standardLogEntry())
+		if finding.Level == findingError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%w: %s", ErrChangelogCheckFailed, changelogFile)
+	}
+
+	message := changelogFile + " follows the Keep a Changelog format"
+	if warningCount := len(findings) - errorCount; warningCount > 0 {
+		message += fmt.Sprintf(" (warnings: %d)", warningCount)
+	}
+	_, err := fmt.Fprintln(output, message)
+	if err != nil {
+		return fmt.Errorf("failed to write the CHANGELOG check: %w", err)
+	}
+	return nil
+}
+
+// runCheck checks the format of the CHANGELOG without bumping anything, with the CHANGELOG and lint settings of the
+// config file (if any), e.g. the non-bumping sections, the section aliases and the spell check
+func runCheck(output io.Writer, configPath string, changelogPath string, allowEmpty bool) error {
+	globalConfig, err := readStandaloneConfig(configPath)
+	if err != nil {
+		return err
+	}
+	lines, err := readLines(changelogPath, getMaxFileSize(globalConfig))
+	if err != nil {
+		return err
+	}
+
+	findings, err := checkChangelog(lines, globalConfig, filepath.Dir(changelogPath), changelogPath, allowEmpty)
+	if err != nil {
+		return err
+	}
+	return writeChangelogCheck(output, changelogPath, findings)
+}
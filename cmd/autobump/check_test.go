@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckChangelog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		content      string
+		globalConfig *GlobalConfig
+		allowEmpty   bool
+		expected     []Finding
+	}{
+		{
+			name:    "should accept a well-formed CHANGELOG",
+			content: changelogOriginal,
+		},
+		{
+			name:    "should require the entries of the Unreleased section",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n-\n\n## [1.0.0] - 2024-01-01\n",
+			expected: []Finding{
+				{Level: findingError, Line: 3, Message: "the \"Unreleased\" section has no entries"},
+			},
+		},
+		{
+			name:       "should accept the empty Unreleased section when allowed",
+			content:    "# Changelog\n\n## [Unreleased]\n\n### Added\n\n-\n\n## [1.0.0] - 2024-01-01\n",
+			allowEmpty: true,
+		},
+		{
+			name:    "should require the Unreleased section",
+			content: "# Changelog\n\n## [1.0.0] - 2024-01-01\n\n### Added\n\n- added the reports\n",
+			expected: []Finding{
+				{Level: findingError, Line: 1, Message: "no \"## [Unreleased]\" section"},
+			},
+		},
+		{
+			name: "should report the entries which would not be released",
+			content: "# Changelog\n\n## [Unreleased]\n\n- added the reports\n\n### Fixed\n\n- fixed the crash\n\n" +
+				"## [1.0.0] - 2024-01-01\n",
+			expected: []Finding{
+				{
+					Level:   findingError,
+					Line:    5,
+					Message: "line is not under a known section heading, it will not be released",
+				},
+			},
+		},
+		{
+			name: "should report the versions which can't be parsed",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Fixed\n\n- fixed the crash\n\n## [next]\n\n" +
+				"## [1.0.0] - 2024-01-01\n",
+			expected: []Finding{
+				{Level: findingError, Line: 1, Message: "error parsing version 'next': Invalid Semantic Version"},
+			},
+		},
+		{
+			name: "should accept the versions of the version streams",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- [app] added the reports\n\n" +
+				"## [app-1.4.0] - 2024-01-02\n\n## [api-2.0.0] - 2024-01-01\n",
+		},
+		{
+			name: "should report the entries of the non-bumping sections alone",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Internal\n\n- bumped the linters\n\n" +
+				"## [1.0.0] - 2024-01-01\n",
+			globalConfig: &GlobalConfig{Changelog: ChangelogConfig{NonBumpingSections: []string{"Internal"}}},
+			expected: []Finding{
+				{
+					Level:   findingError,
+					Line:    3,
+					Message: "the \"Unreleased\" section only has 1 entries of the non-bumping sections",
+				},
+			},
+		},
+		{
+			name: "should report the lint findings at their level",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Fixed\n\n- fixed the crahs\n\n" +
+				"## [1.0.0] - 2024-01-01\n",
+			globalConfig: &GlobalConfig{ChangelogLint: ChangelogLintConfig{Spellcheck: true}},
+			expected: []Finding{
+				{Level: findingWarning, Line: 7, Message: "possible typos: crahs"},
+			},
+		},
+		{
+			name: "should report the conflict markers",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Fixed\n\n<<<<<<< HEAD\n- fixed the crash\n\n" +
+				"## [1.0.0] - 2024-01-01\n",
+			expected: []Finding{
+				{Level: findingError, Line: 7, Message: ErrChangelogConflictMarkers.Error()},
+			},
+		},
+		{
+			name:    "should report the Unreleased section without any recognized entry",
+			content: "# Changelog\n\n## [Unreleased]\n\n- added the reports\n\n## [1.0.0] - 2024-01-01\n",
+			expected: []Finding{
+				{
+					Level:   findingError,
+					Line:    3,
+					Message: "Unreleased has 1 lines but 0 recognized entries — check formatting",
+				},
+				{
+					Level:   findingError,
+					Line:    5,
+					Message: "line is not under a known section heading, it will not be released",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			lines := strings.Split(test.content, "\n")
+			globalConfig := test.globalConfig
+			if globalConfig == nil {
+				globalConfig = &GlobalConfig{}
+			}
+			for index := range test.expected {
+				test.expected[index].File = "CHANGELOG.md"
+			}
+
+			// Act
+			findings, err := checkChangelog(lines, globalConfig, t.TempDir(), "CHANGELOG.md", test.allowEmpty)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, findings)
+		})
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		content        string
+		expectedErr    error
+		expectedOutput string
+	}{
+		{
+			name:           "should tell the CHANGELOG follows the format",
+			content:        changelogOriginal,
+			expectedOutput: " follows the Keep a Changelog format\n",
+		},
+		{
+			name: "should fail with the problems",
+			content: "# Changelog\n\n## [Unreleased]\n\n### Internal\n\n- bumped the linters\n\n" +
+				"## [1.0.0] - 2024-01-01\n",
+			expectedErr: ErrChangelogCheckFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			dir := t.TempDir()
+			configPath := filepath.Join(dir, "autobump.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte("changelog:\n  non_bumping_sections: [Internal]\n"), 0o600))
+			changelogPath := filepath.Join(dir, "CHANGELOG.md")
+			require.NoError(t, os.WriteFile(changelogPath, []byte(test.content), 0o600))
+			var output bytes.Buffer
+
+			// Act
+			err := runCheck(&output, configPath, changelogPath, false)
+
+			// Assert
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				assert.Empty(t, output.String())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, changelogPath+test.expectedOutput, output.String())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	changeLevelMajor = "major"
+	changeLevelMinor = "minor"
+	changeLevelPatch = "patch"
+)
+
+var ErrInvalidEntryClassification = errors.New("invalid entry classification rule")
+
+// defaultDependencyUpdateRules are the rules enabled by "classify_dependency_updates",
+// matching the entries written by tools like Renovate and Dependabot
+var defaultDependencyUpdateRules = []EntryClassificationRule{
+	{
+		Pattern: `(?i)^\s*[-*+]\s+(added|updated|bumped|upgraded|downgraded|pinned)\b.*\bdependenc(y|ies)\b`,
+		Level:   changeLevelPatch,
+	},
+	{
+		Pattern: `(?i)^\s*[-*+]\s+(bumped|bump|updated|upgraded)\s+\S+\s+from\s+\S+\s+to\s+\S+\s*$`,
+		Level:   changeLevelPatch,
+	},
+	{
+		Pattern: `(?i)^\s*[-*+]\s+(chore|build|fix)\(deps(-dev)?\):`,
+		Level:   changeLevelPatch,
+	},
+}
+
+// EntryClassificationRule forces the change level of the entries matching the pattern
+type EntryClassificationRule struct {
+	Pattern string `yaml:"pattern"`
+	Level   string `yaml:"level"`
+}
+
+// entryClassifier is a compiled entry classification rule
+type entryClassifier struct {
+	rule  EntryClassificationRule
+	regex *regexp.Regexp
+}
+
+// getEntryClassifiers compiles the configured rules, followed by the dependency update rules when enabled
+func getEntryClassifiers(changelogConfig ChangelogConfig) ([]entryClassifier, error) {
+	rules := changelogConfig.EntryClassification
+	if changelogConfig.ClassifyDependencyUpdates {
+		rules = append(rules[:len(rules):len(rules)], defaultDependencyUpdateRules...)
+	}
+
+	classifiers := make([]entryClassifier, 0, len(rules))
+	for index, rule := range rules {
+		switch rule.Level {
+		case changeLevelMajor, changeLevelMinor, changeLevelPatch:
+		default:
+			return nil, fmt.Errorf("%w: entry_classification[%d] has the unknown level %q",
+				ErrInvalidEntryClassification, index, rule.Level)
+		}
+
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry_classification[%d]: %w", ErrInvalidEntryClassification, index, err)
+		}
+		classifiers = append(classifiers, entryClassifier{rule: rule, regex: regex})
+	}
+	return classifiers, nil
+}
+
+// classifyEntry returns the change level of the entry, along with the rule forcing it (if any)
+func classifyEntry(line string, added bool, classifiers []entryClassifier) (string, *EntryClassificationRule) {
+	for _, classifier := range classifiers {
+		if classifier.regex.MatchString(line) {
+			return classifier.rule.Level, &classifier.rule
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(line, breakingEntryPrefix):
+		return changeLevelMajor, nil
+	case added:
+		return changeLevelMinor, nil
+	default:
+		return changeLevelPatch, nil
+	}
+}
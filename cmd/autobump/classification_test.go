@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogWithDependencyUpdate = changelogTemplate + `
+
+### Added
+
+- added dependency github.com/stretchr/testify v1.9.0
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.`
+
+func TestProcessChangelog_DependencyUpdateStaysPatch(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithDependencyUpdate, "\n")
+	changelogConfig := ChangelogConfig{ClassifyDependencyUpdates: true}
+
+	// Act
+	version, _, err := processChangelog(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.2", version.String())
+}
+
+func TestProcessChangelog_DependencyUpdateIsMinorWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithDependencyUpdate, "\n")
+
+	// Act
+	version, _, err := processChangelog(lines, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", version.String())
+}
+
+func TestProcessChangelog_FeatureStaysMinor(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogOriginal, "\n")
+	changelogConfig := ChangelogConfig{ClassifyDependencyUpdates: true}
+
+	// Act
+	version, _, err := processChangelog(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", version.String())
+}
+
+func TestClassifyEntry_NamesTheRuleThatFired(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	rule := EntryClassificationRule{Pattern: `^- (added|updated) .*dependen`, Level: changeLevelPatch}
+	classifiers, err := getEntryClassifiers(ChangelogConfig{EntryClassification: []EntryClassificationRule{rule}})
+	require.NoError(t, err)
+
+	// Act
+	level, firedRule := classifyEntry("- updated the dependencies of the parser", true, classifiers)
+
+	// Assert
+	assert.Equal(t, changeLevelPatch, level)
+	require.NotNil(t, firedRule)
+	assert.Equal(t, rule.Pattern, firedRule.Pattern)
+}
+
+func TestClassifyEntry_FallsBackToTheSection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	classifiers, err := getEntryClassifiers(ChangelogConfig{ClassifyDependencyUpdates: true})
+	require.NoError(t, err)
+
+	// Act
+	addedLevel, addedRule := classifyEntry("- added the export command", true, classifiers)
+	breakingLevel, _ := classifyEntry("- **BREAKING CHANGE:** removed the v1 API", false, classifiers)
+
+	// Assert
+	assert.Equal(t, changeLevelMinor, addedLevel)
+	assert.Nil(t, addedRule)
+	assert.Equal(t, changeLevelMajor, breakingLevel)
+}
+
+func TestGetEntryClassifiers_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	invalidLevel := ChangelogConfig{EntryClassification: []EntryClassificationRule{{Pattern: "deps", Level: "huge"}}}
+	invalidPattern := ChangelogConfig{EntryClassification: []EntryClassificationRule{{Pattern: "(", Level: "patch"}}}
+
+	// Act
+	_, levelErr := getEntryClassifiers(invalidLevel)
+	_, patternErr := getEntryClassifiers(invalidPattern)
+
+	// Assert
+	require.ErrorIs(t, levelErr, ErrInvalidEntryClassification)
+	require.ErrorIs(t, patternErr, ErrInvalidEntryClassification)
+}
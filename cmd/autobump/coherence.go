@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// invocation modes of AutoBump, some options only have an effect in one of them
+const (
+	invocationSingle   = "single"
+	invocationBatch    = "batch"
+	invocationValidate = "validate"
+)
+
+// coherenceInput is the effective configuration checked by the coherence rules
+type coherenceInput struct {
+	globalConfig    *GlobalConfig
+	globalGitConfig *config.Config
+	mode            string
+}
+
+// coherenceRule finds the options without effect given another setting or the invocation mode,
+// returning a warning naming both settings and what to change
+type coherenceRule func(input coherenceInput) []string
+
+// coherenceRules are the checks run after the validation, a new check is one more entry
+var coherenceRules = []coherenceRule{
+	checkGpgKeyPathWithoutSigning,
+	checkOnLimitWithoutLimits,
+	checkBatchOnlyOptions,
+	checkPrecheckWithoutRemoteProjects,
+	checkPropagateToPrivateWithoutPropagation,
+	checkCalVerFormatWithoutCalVer,
+	checkUnsupportedServices,
+}
+
+// getCoherenceWarnings runs every coherence rule against the configuration
+func getCoherenceWarnings(input coherenceInput) []string {
+	var warnings []string
+	for _, rule := range coherenceRules {
+		warnings = append(warnings, rule(input)...)
+	}
+	return warnings
+}
+
+// warnIncoherentConfig logs the options without effect once, before processing any project
+func warnIncoherentConfig(globalConfig *GlobalConfig, mode string) {
+	globalGitConfig, err := getGlobalGitConfig()
+	if err != nil {
+		log.Debugf("Unable to read the global Git config for the coherence checks: %v", err)
+	}
+
+	for _, warning := range getCoherenceWarnings(coherenceInput{
+		globalConfig:    globalConfig,
+		globalGitConfig: globalGitConfig,
+		mode:            mode,
+	}) {
+		log.Warn(warning)
+	}
+}
+
+// writeConfigValidation writes the result of the validation, along with the options without effect
+func writeConfigValidation(output io.Writer, globalConfig *GlobalConfig, globalGitConfig *config.Config) error {
+	warnings := getCoherenceWarnings(coherenceInput{
+		globalConfig:    globalConfig,
+		globalGitConfig: globalGitConfig,
+		mode:            invocationValidate,
+	})
+
+	var err error
+	if len(warnings) == 0 {
+		_, err = fmt.Fprintln(output, "The configuration is valid")
+	} else {
+		_, err = fmt.Fprintln(output, "The configuration is valid, but some options have no effect:")
+		for _, warning := range warnings {
+			if err == nil {
+				_, err = fmt.Fprintf(output, "- %s\n", warning)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the validation: %w", err)
+	}
+	return nil
+}
+
+func checkGpgKeyPathWithoutSigning(input coherenceInput) []string {
+	// the key is used regardless of the Git config when signing always, and never used when never signing
+	switch input.globalConfig.SignCommits {
+	case signCommitsAlways:
+		return nil
+	case signCommitsNever:
+		if input.globalConfig.GpgKeyPath != "" {
+			return []string{"gpg_key_path is set, but sign_commits is \"never\": remove gpg_key_path or sign_commits"}
+		}
+		return nil
+	}
+	if input.globalConfig.GpgKeyPath == "" || input.globalGitConfig == nil {
+		return nil
+	}
+
+	gitConfig := input.globalGitConfig.Raw
+	switch {
+	case gitConfig.Section("gpg").Option("format") == "ssh":
+		return []string{
+			"gpg_key_path is set, but gpg.format is \"ssh\" in the global Git config, so the key is never read: " +
+				"remove gpg_key_path or unset gpg.format",
+		}
+	case gitConfig.Section("commit").Option("gpgsign") != "true":
+		return []string{
+			"gpg_key_path is set, but commit.gpgsign isn't enabled in the global Git config, so the bump commits " +
+				"are only signed in the repositories enabling it: run \"git config --global commit.gpgsign true\" " +
+				"or remove gpg_key_path",
+		}
+	default:
+		return nil
+	}
+}
+
+func checkOnLimitWithoutLimits(input coherenceInput) []string {
+	globalConfig := input.globalConfig
+	if globalConfig.OnLimit == "" || globalConfig.MaxPRsPerRun > 0 || globalConfig.MaxPRsPerOrg > 0 {
+		return nil
+	}
+	return []string{
+		"on_limit is set, but neither max_prs_per_run nor max_prs_per_org is, so no limit is ever reached: " +
+			"set one of them or remove on_limit",
+	}
+}
+
+func checkBatchOnlyOptions(input coherenceInput) []string {
+	if input.mode != invocationSingle {
+		return nil
+	}
+
+	globalConfig := input.globalConfig
+	batchOnlyOptions := []struct {
+		name string
+		set  bool
+	}{
+		{name: "max_prs_per_run", set: globalConfig.MaxPRsPerRun > 0},
+		{name: "max_prs_per_org", set: globalConfig.MaxPRsPerOrg > 0},
+		{name: "digest_out", set: globalConfig.DigestOut != ""},
+		{name: "digest_webhook", set: globalConfig.DigestWebhook != ""},
+		{name: "precheck_unreleased", set: globalConfig.PrecheckUnreleased},
+	}
+
+	var warnings []string
+	for _, option := range batchOnlyOptions {
+		if option.set {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s is set, but it only applies to the \"batch\" command: run \"autobump batch\" or remove %s",
+				option.name, option.name,
+			))
+		}
+	}
+	return warnings
+}
+
+func checkPrecheckWithoutRemoteProjects(input coherenceInput) []string {
+	globalConfig := input.globalConfig
+	if input.mode == invocationSingle || !globalConfig.PrecheckUnreleased || len(globalConfig.Projects) == 0 {
+		return nil
+	}
+
+	for _, project := range globalConfig.Projects {
+		if isRemoteProject(project.Path) {
+			return nil
+		}
+	}
+	return []string{
+		"precheck_unreleased is set, but all the projects are local paths, which are never cloned: " +
+			"use the Git URLs of the projects or remove precheck_unreleased",
+	}
+}
+
+func checkPropagateToPrivateWithoutPropagation(input coherenceInput) []string {
+	var warnings []string
+	for projectIndex, project := range input.globalConfig.Projects {
+		if project.PropagateToPrivate && !project.WorkspacePropagation {
+			warnings = append(warnings, fmt.Sprintf(
+				"projects[%d].propagate_to_private is set, but projects[%d].workspace_propagation isn't, "+
+					"so the version isn't propagated to any member: set workspace_propagation or remove "+
+					"propagate_to_private",
+				projectIndex, projectIndex,
+			))
+		}
+	}
+	return warnings
+}
+
+func checkCalVerFormatWithoutCalVer(input coherenceInput) []string {
+	var warnings []string
+	for projectIndex, project := range input.globalConfig.Projects {
+		if project.CalVerFormat != "" && project.VersioningScheme != versioningSchemeCalVer {
+			warnings = append(warnings, fmt.Sprintf(
+				"projects[%d].calver_format is set, but projects[%d].versioning_scheme isn't %q, "+
+					"so the project is versioned with SemVer: set versioning_scheme to %q or remove calver_format",
+				projectIndex, projectIndex, versioningSchemeCalVer, versioningSchemeCalVer,
+			))
+		}
+	}
+	return warnings
+}
+
+func checkUnsupportedServices(input coherenceInput) []string {
+	var warnings []string
+	for projectIndex, project := range input.globalConfig.Projects {
+		if !isRemoteProject(project.Path) {
+			continue
+		}
+
+		serviceType := getServiceTypeByURL(project.Path)
+		if err := checkPullRequestSupport(serviceType); err != nil {
+			warnings = append(warnings, fmt.Sprintf(
+				"projects[%d]: %v, so its bump branch is only pushed (%s)",
+				projectIndex, err, pullRequestStatusPushedNoPR,
+			))
+		}
+		service := getServiceInfo(serviceType)
+		if input.globalConfig.PrecheckUnreleased && !service.capabilities.ReadContents {
+			warnings = append(warnings, fmt.Sprintf(
+				"projects[%d]: precheck_unreleased is set, but the CHANGELOG of %s can't be fetched without cloning, "+
+					"so the project is always cloned",
+				projectIndex, service.name,
+			))
+		}
+	}
+	return warnings
+}
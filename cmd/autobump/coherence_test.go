@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitConfig creates a Git config with the given options of the sections
+func newGitConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+
+	gitConfig := config.NewConfig()
+	require.NoError(t, gitConfig.Unmarshal([]byte(content)))
+	return gitConfig
+}
+
+func TestGetCoherenceWarnings_GpgKeyPathWithoutSigning(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := coherenceInput{
+		globalConfig:    &GlobalConfig{GpgKeyPath: "/keys/bump.asc"},
+		globalGitConfig: newGitConfig(t, "[commit]\n\tgpgsign = false\n"),
+		mode:            invocationBatch,
+	}
+
+	// Act
+	warnings := getCoherenceWarnings(input)
+
+	// Assert
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "gpg_key_path")
+	assert.Contains(t, warnings[0], "commit.gpgsign")
+}
+
+func TestGetCoherenceWarnings_GpgKeyPathWithSigning(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := coherenceInput{
+		globalConfig:    &GlobalConfig{GpgKeyPath: "/keys/bump.asc"},
+		globalGitConfig: newGitConfig(t, "[commit]\n\tgpgsign = true\n"),
+		mode:            invocationBatch,
+	}
+
+	// Act
+	warnings := getCoherenceWarnings(input)
+
+	// Assert
+	assert.Empty(t, warnings)
+}
+
+func TestGetCoherenceWarnings_GpgKeyPathWithSignCommits(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	gitConfig := newGitConfig(t, "[commit]\n\tgpgsign = false\n")
+	always := coherenceInput{
+		globalConfig:    &GlobalConfig{GpgKeyPath: "/keys/bump.asc", SignCommits: signCommitsAlways},
+		globalGitConfig: gitConfig,
+		mode:            invocationBatch,
+	}
+	never := coherenceInput{
+		globalConfig:    &GlobalConfig{GpgKeyPath: "/keys/bump.asc", SignCommits: signCommitsNever},
+		globalGitConfig: gitConfig,
+		mode:            invocationBatch,
+	}
+
+	// Act
+	alwaysWarnings := getCoherenceWarnings(always)
+	neverWarnings := getCoherenceWarnings(never)
+
+	// Assert
+	assert.Empty(t, alwaysWarnings, "the key is used regardless of commit.gpgsign")
+	require.Len(t, neverWarnings, 1)
+	assert.Contains(t, neverWarnings[0], "sign_commits is \"never\"")
+}
+
+func TestGetCoherenceWarnings_BatchOnlyOptionsInSingleMode(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{MaxPRsPerRun: 5, OnLimit: onLimitSkip, DigestOut: "/tmp/digest.md"}
+
+	// Act
+	singleWarnings := getCoherenceWarnings(coherenceInput{globalConfig: globalConfig, mode: invocationSingle})
+	batchWarnings := getCoherenceWarnings(coherenceInput{globalConfig: globalConfig, mode: invocationBatch})
+
+	// Assert
+	require.Len(t, singleWarnings, 2)
+	assert.Contains(t, singleWarnings[0], "max_prs_per_run is set, but it only applies to the \"batch\" command")
+	assert.Contains(t, singleWarnings[1], "digest_out is set")
+	assert.Empty(t, batchWarnings)
+}
+
+func TestGetCoherenceWarnings_OnLimitWithoutLimits(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := coherenceInput{globalConfig: &GlobalConfig{OnLimit: onLimitSkip}, mode: invocationValidate}
+
+	// Act
+	warnings := getCoherenceWarnings(input)
+
+	// Assert
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "on_limit is set, but neither max_prs_per_run nor max_prs_per_org is")
+}
+
+func TestGetCoherenceWarnings_ProjectOptions(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := coherenceInput{
+		globalConfig: &GlobalConfig{
+			PrecheckUnreleased: true,
+			Projects: []ProjectConfig{
+				{Path: "/home/user/web", PropagateToPrivate: true},
+				{Path: "/home/user/api", CalVerFormat: "YYYY.MM.MICRO"},
+				{Path: "/home/user/ui", WorkspacePropagation: true, PropagateToPrivate: true},
+			},
+		},
+		mode: invocationBatch,
+	}
+
+	// Act
+	warnings := getCoherenceWarnings(input)
+
+	// Assert
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], "precheck_unreleased is set, but all the projects are local paths")
+	assert.Contains(t, warnings[1], "projects[0].propagate_to_private is set")
+	assert.Contains(t, warnings[2], "projects[1].calver_format is set")
+}
+
+func TestWriteConfigValidation_ListsWarnings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var output bytes.Buffer
+	globalConfig := &GlobalConfig{OnLimit: onLimitSkip}
+
+	// Act
+	err := writeConfigValidation(&output, globalConfig, nil)
+	validErr := writeConfigValidation(&bytes.Buffer{}, &GlobalConfig{}, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, validErr)
+	assert.Contains(t, output.String(), "The configuration is valid, but some options have no effect:\n- on_limit is set")
+}
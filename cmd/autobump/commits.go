@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// changelogSourceChangelog releases the entries written by hand in the "Unreleased" section
+	changelogSourceChangelog = "changelog"
+	// changelogSourceCommits also writes the Conventional Commits made since the latest release in the section
+	changelogSourceCommits = "commits"
+)
+
+var ErrInvalidChangelogSource = errors.New("invalid changelog_source")
+
+var (
+	// conventionalCommitRegex matches the subject of a Conventional Commit, e.g. "feat(api)!: removed the v1 routes"
+	conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?:\s*(\S.*)$`)
+
+	// breakingChangeFooterRegex matches the footer describing the breaking change in the body of a commit
+	breakingChangeFooterRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*(.*)$`)
+)
+
+// commitTypeSections are the sections of the Conventional Commits types, the other types (e.g. "chore", "docs" or
+// "ci") not being released
+var commitTypeSections = map[string]string{
+	"feat":     "Added",
+	"feature":  "Added",
+	"fix":      "Fixed",
+	"perf":     "Changed",
+	"refactor": "Changed",
+	"security": "Security",
+}
+
+// validateChangelogSource checks where the entries of the releases come from
+func validateChangelogSource(projectConfig *ProjectConfig) error {
+	switch projectConfig.ChangelogSource {
+	case "", changelogSourceChangelog, changelogSourceCommits:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: %q (expected %s or %s)",
+			ErrInvalidChangelogSource, projectConfig.ChangelogSource, changelogSourceChangelog, changelogSourceCommits,
+		)
+	}
+}
+
+// populateUnreleasedFromCommits writes the Conventional Commits made since the latest release tag in the "Unreleased"
+// section, before it is read to bump the project. The entries already written there (e.g. by hand) are kept once.
+func populateUnreleasedFromCommits(ctx *RepoContext, changelogPath string) error {
+	if ctx.projectConfig.ChangelogSource != changelogSourceCommits {
+		return nil
+	}
+
+	head := ctx.baseCommit
+	if head == nil {
+		var err error
+		head, err = ctx.repo.CommitObject(ctx.head.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read the HEAD commit: %w", err)
+		}
+	}
+
+	tagName, tagHash, err := findLatestReleaseTag(ctx.repo)
+	if err != nil {
+		return err
+	}
+	since := "the first commit"
+	if tagName != "" {
+		since = "tag " + tagName
+	}
+
+	commits, err := getCommitsSince(head, tagHash)
+	if err != nil {
+		return err
+	}
+	sectionEntries := getCommitEntries(commits)
+	if len(sectionEntries) == 0 {
+		ctx.logger().Infof("No Conventional Commits to release since %s", since)
+		return nil
+	}
+
+	lines, err := readChangelogLines(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+	newLines, added := insertUnreleasedEntries(lines, sectionEntries, ctx.logger())
+	if added == 0 {
+		ctx.logger().Infof("The commits since %s are already in the CHANGELOG", since)
+		return nil
+	}
+
+	ctx.logger().Infof("Writing %d entries from the commits since %s in the CHANGELOG", added, since)
+	return writeLines(changelogPath, newLines)
+}
+
+// findLatestReleaseTag returns the tag of the highest released version (with or without the "v" prefix),
+// along with its commit. Both are empty when the repository has no release tag yet.
+func findLatestReleaseTag(repo *git.Repository) (string, plumbing.Hash, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("failed to list the tags: %w", err)
+	}
+
+	var latestName string
+	var latestVersion *semver.Version
+	_ = tags.ForEach(func(tag *plumbing.Reference) error {
+		version, parseErr := semver.StrictNewVersion(strings.TrimPrefix(tag.Name().Short(), versionPrefixV))
+		if parseErr == nil && (latestVersion == nil || version.GreaterThan(latestVersion)) {
+			latestName, latestVersion = tag.Name().Short(), version
+		}
+		return nil
+	})
+	if latestName == "" {
+		return "", plumbing.ZeroHash, nil
+	}
+
+	// the annotated tags point to a tag object, resolved to its commit
+	hash, err := repo.ResolveRevision(plumbing.Revision(plumbing.NewTagReferenceName(latestName)))
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("failed to resolve the tag %s: %w", latestName, err)
+	}
+	return latestName, *hash, nil
+}
+
+// getCommitsSince returns the commits reachable from the head but not through the release commit, the oldest first.
+// The history of the shallow clones ends at their last commit.
+func getCommitsSince(head *object.Commit, releaseHash plumbing.Hash) ([]*object.Commit, error) {
+	var ignored []plumbing.Hash
+	if !releaseHash.IsZero() {
+		ignored = append(ignored, releaseHash)
+	}
+
+	var commits []*object.Commit
+	iterator := object.NewCommitPreorderIter(head, nil, ignored)
+	err := iterator.ForEach(func(commit *object.Commit) error {
+		commits = append([]*object.Commit{commit}, commits...)
+		return nil
+	})
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("failed to read the commits: %w", err)
+	}
+	return commits, nil
+}
+
+// getCommitEntries translates the Conventional Commits into CHANGELOG entries per section. The breaking changes
+// ("!" or a "BREAKING CHANGE" footer) are marked as such, with the footer as their migration note.
+// The merge commits and the commits of the other types are left out.
+func getCommitEntries(commits []*object.Commit) map[string][]string {
+	sectionEntries := make(map[string][]string)
+	for _, commit := range commits {
+		if commit.NumParents() > 1 {
+			continue
+		}
+
+		subject, body, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		match := conventionalCommitRegex.FindStringSubmatch(strings.TrimSpace(subject))
+		if match == nil {
+			continue
+		}
+		section, found := commitTypeSections[strings.ToLower(match[1])]
+		if !found {
+			continue
+		}
+
+		entry := "- " + strings.TrimSpace(match[3])
+		footer := breakingChangeFooterRegex.FindStringSubmatch(body)
+		if match[2] != "" || footer != nil {
+			entry = breakingEntryPrefix + " " + strings.TrimSpace(match[3])
+			if footer != nil && strings.TrimSpace(footer[1]) != "" {
+				entry += "\n  > " + strings.TrimSpace(footer[1])
+			}
+		}
+		sectionEntries[section] = append(sectionEntries[section], entry)
+	}
+	return sectionEntries
+}
+
+// insertUnreleasedEntries writes the entries below the "Unreleased" heading, grouped in their sections,
+// skipping those already in the section. The sections are merged with the existing ones when bumping.
+// It returns the amount of entries written.
+func insertUnreleasedEntries(lines []string, sectionEntries map[string][]string, logger *log.Entry) ([]string, int) {
+	unreleasedIndex := -1
+	for index := getFrontMatterLength(lines); index < len(lines); index++ {
+		if strings.Contains(lines[index], "[Unreleased]") {
+			unreleasedIndex = index
+			break
+		}
+	}
+	if unreleasedIndex == -1 {
+		logger.Warn("The CHANGELOG has no \"Unreleased\" section to write the commits in")
+		return lines, 0
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range lines[unreleasedIndex+1:] {
+		if strings.HasPrefix(line, "## ") {
+			break
+		}
+		existing[normalizeEntryText(line)] = true
+	}
+
+	var block []string
+	added := 0
+	for _, header := range changelogSectionsOrder {
+		var entries []string
+		for _, entry := range sectionEntries[header] {
+			if existing[normalizeEntryText(getEntryHeadline(entry))] {
+				continue
+			}
+			existing[normalizeEntryText(getEntryHeadline(entry))] = true
+			entries = append(entries, strings.Split(entry, "\n")...)
+			added++
+		}
+		if len(entries) > 0 {
+			block = append(block, "", "### "+header, "")
+			block = append(block, entries...)
+		}
+	}
+	if added == 0 {
+		return lines, 0
+	}
+
+	newLines := append([]string{}, lines[:unreleasedIndex+1]...)
+	newLines = append(newLines, block...)
+	return append(newLines, lines[unreleasedIndex+1:]...), added
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changelogReleased is a CHANGELOG with nothing written in its "Unreleased" section since the release 1.0.1
+const changelogReleased = changelogTemplate + `
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.
+`
+
+// commitMessage commits an empty change with the message
+func commitMessage(t *testing.T, repo *git.Repository, message string) {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+func TestPopulateUnreleasedFromCommits(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "https://gitlab.com/acme/api.git")
+	commitMessage(t, repo, "feat: added the first feature")
+	releaseHash := commitFile(t, repo, "CHANGELOG.md", changelogReleased)
+	_, err := repo.CreateTag("v1.0.1", releaseHash, nil)
+	require.NoError(t, err)
+	commitMessage(t, repo, "feat(api): added the export of the invoices")
+	commitMessage(t, repo, "chore: updated the dependencies")
+	commitMessage(t, repo, "fix: fixed the crash on empty invoices")
+	commitMessage(t, repo, "feat!: removed the v1 routes\n\nBREAKING CHANGE: call the v2 routes instead")
+
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: projectPath, Name: "api", ChangelogSource: changelogSourceCommits},
+	}
+	require.NoError(t, setupRepo(ctx))
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+
+	// Act
+	err = populateUnreleasedFromCommits(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	changelog, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Equal(t, changelogTemplate+`
+
+### Added
+
+- added the export of the invoices
+- **BREAKING CHANGE:** removed the v1 routes
+  > call the v2 routes instead
+
+### Fixed
+
+- fixed the crash on empty invoices
+
+## [1.0.1] - 1984-01-01
+
+### Added
+
+- New feature.
+`, string(changelog))
+	version, err := getNextVersion(ctx, changelogPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", version.String())
+}
+
+func TestPopulateUnreleasedFromCommits_KeepsTheWrittenEntries(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "https://gitlab.com/acme/api.git")
+	releaseHash := commitFile(t, repo, "CHANGELOG.md", changelogReleased)
+	_, err := repo.CreateTag("1.0.1", releaseHash, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Now()},
+		Message: "1.0.1",
+	})
+	require.NoError(t, err)
+	commitMessage(t, repo, "fix: Fixed the crash on empty invoices")
+	commitFile(t, repo, "CHANGELOG.md", changelogTemplate+`
+
+### Fixed
+
+- fixed the crash on empty invoices
+
+## [1.0.1] - 1984-01-01
+`)
+
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: projectPath, Name: "api", ChangelogSource: changelogSourceCommits},
+	}
+	require.NoError(t, setupRepo(ctx))
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	before, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+
+	// Act
+	err = populateUnreleasedFromCommits(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	after, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func TestPopulateUnreleasedFromCommits_ChangelogSource(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "https://gitlab.com/acme/api.git")
+	commitFile(t, repo, "CHANGELOG.md", changelogReleased)
+	commitMessage(t, repo, "feat: added the export of the invoices")
+
+	ctx := &RepoContext{globalConfig: &GlobalConfig{}, projectConfig: &ProjectConfig{Path: projectPath, Name: "api"}}
+	require.NoError(t, setupRepo(ctx))
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+
+	// Act
+	err := populateUnreleasedFromCommits(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	changelog, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Equal(t, changelogReleased, string(changelog))
+}
+
+func TestValidateChangelogSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		changelogSource string
+		valid           bool
+	}{
+		{name: "should accept the default source", changelogSource: "", valid: true},
+		{name: "should accept the CHANGELOG", changelogSource: changelogSourceChangelog, valid: true},
+		{name: "should accept the commits", changelogSource: changelogSourceCommits, valid: true},
+		{name: "should refuse an unknown source", changelogSource: "tags", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := validateChangelogSource(&ProjectConfig{ChangelogSource: test.changelogSource})
+
+			// Assert
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidChangelogSource)
+			}
+		})
+	}
+}
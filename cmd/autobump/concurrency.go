@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// field of the entries logged by the workers, with the name of the project they are processing
+const projectLogField = "project"
+
+var ErrInvalidConcurrency = errors.New("invalid concurrency")
+
+// projectFailure is a project that failed in a batch run, listed at the end of the run
+type projectFailure struct {
+	name string
+	err  error
+}
+
+// validateConcurrency checks the amount of projects processed at the same time, 0 being the default (one at a time)
+func validateConcurrency(concurrency int) error {
+	if concurrency < 0 {
+		return fmt.Errorf("%w: %d (expected 1 or more)", ErrInvalidConcurrency, concurrency)
+	}
+	return nil
+}
+
+// processProjects processes the projects, with up to "concurrency" of them at the same time. The remote projects
+// are cloned in their own directory, but the local ones may share a worktree, so they are processed one at a time.
+// The entries logged by each worker have the "project" field, the failures are listed together at the end,
+// and the errors of all the failed projects are returned.
+func processProjects(globalConfig *GlobalConfig, projects []ProjectConfig) error {
+	errs := make([]error, len(projects))
+	workers := min(max(globalConfig.Concurrency, 1), len(projects))
+	if workers <= 1 {
+		for index := range projects {
+			errs[index] = processProject(globalConfig, &projects[index], standardLogEntry())
+		}
+	} else {
+		log.Infof("Processing %d projects with %d workers", len(projects), workers)
+		indexes := make(chan int)
+		var waitGroup sync.WaitGroup
+		var localProjectsMutex sync.Mutex
+		for range workers {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for index := range indexes {
+					project := &projects[index]
+					if !isRemoteProject(project.Path) {
+						localProjectsMutex.Lock()
+					}
+					logger := log.WithField(projectLogField, getProjectLogName(project))
+					errs[index] = processProject(globalConfig, project, logger)
+					if !isRemoteProject(project.Path) {
+						localProjectsMutex.Unlock()
+					}
+				}
+			}()
+		}
+		for index := range projects {
+			indexes <- index
+		}
+		close(indexes)
+		waitGroup.Wait()
+	}
+
+	var failures []projectFailure
+	for index, err := range errs {
+		if err != nil {
+			failures = append(failures, projectFailure{name: getProjectLogName(&projects[index]), err: err})
+		}
+	}
+	logProjectFailures(failures, len(projects))
+
+	return errors.Join(errs...)
+}
+
+// processProject processes one project of a batch run with its logger, skipping it when its path doesn't exist
+func processProject(globalConfig *GlobalConfig, project *ProjectConfig, logger *log.Entry) error {
+	// verify if the project path exists
+	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+		// if the project path does not exist, check if it is a remote repository
+		if !isRemoteProject(project.Path) {
+			// if it is neither a local path nor a remote repository, skip the project
+			logger.Errorf("Project path does not exist: %s\n", project.Path)
+			logger.Warn("Skipping project")
+			recordProjectFailure(globalConfig, project, ErrProjectPathDoesNotExist)
+			return ErrProjectPathDoesNotExist
+		}
+	}
+
+	err := processRepo(globalConfig, project, logger)
+	if status := getRepositoryPathStatus(err); status != "" {
+		logger.Errorf("Skipping project at %s (%s): %v\n", project.Path, status, err)
+	} else if err != nil {
+		logger.Errorf("Error processing project at %s: %v\n", project.Path, err)
+	}
+	return err
+}
+
+// logProjectFailures lists the failed projects together, since their errors are spread in the logs of the run
+func logProjectFailures(failures []projectFailure, total int) {
+	if len(failures) == 0 {
+		return
+	}
+
+	log.Errorf("%d of %d projects failed:", len(failures), total)
+	for _, failure := range failures {
+		log.Errorf("- %s: %v", failure.name, failure.err)
+	}
+}
+
+// getProjectLogName returns the name of the project in the logs, its path when it has no name
+func getProjectLogName(project *ProjectConfig) string {
+	return firstNonEmpty(project.Name, redactURLCredentials(project.Path))
+}
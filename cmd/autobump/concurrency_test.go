@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // the failures are read from the global logger
+func TestProcessProjects_ListsTheFailuresAtTheEnd(t *testing.T) {
+	// Arrange
+	hook := test.NewGlobal()
+	missingPath := filepath.Join(t.TempDir(), "missing")
+	projects := []ProjectConfig{
+		{Name: "api", Path: filepath.Join(missingPath, "api")},
+		{Name: "web", Path: filepath.Join(missingPath, "web")},
+		{Path: filepath.Join(missingPath, "worker")},
+	}
+
+	// Act
+	err := processProjects(&GlobalConfig{Concurrency: 2}, projects)
+
+	// Assert
+	require.ErrorIs(t, err, ErrProjectPathDoesNotExist)
+	assert.Len(t, strings.Split(err.Error(), "\n"), 3, "the errors of all the projects should be returned")
+	var messages []string
+	projectMessages := make(map[string]string)
+	for _, entry := range hook.AllEntries() {
+		messages = append(messages, entry.Message)
+		if project, found := entry.Data[projectLogField]; found && entry.Level == log.ErrorLevel {
+			projectMessages[project.(string)] = entry.Message
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"api":                                "Project path does not exist: " + filepath.Join(missingPath, "api") + "\n",
+		"web":                                "Project path does not exist: " + filepath.Join(missingPath, "web") + "\n",
+		filepath.Join(missingPath, "worker"): "Project path does not exist: " + filepath.Join(missingPath, "worker") + "\n",
+	}, projectMessages)
+	require.Contains(t, messages, "3 of 3 projects failed:")
+	summary := messages[slices.Index(messages, "3 of 3 projects failed:"):]
+	assert.Equal(t, []string{
+		"3 of 3 projects failed:",
+		"- api: project path does not exist",
+		"- web: project path does not exist",
+		"- " + filepath.Join(missingPath, "worker") + ": project path does not exist",
+	}, summary)
+}
+
+func TestValidateConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	require.NoError(t, validateConcurrency(0))
+	require.NoError(t, validateConcurrency(8))
+	require.ErrorIs(t, validateConcurrency(-1), ErrInvalidConcurrency)
+}
+
+//nolint:paralleltest // the entries are read from the global logger
+func TestProcessProjects_LogsTheProjectOfEachEntry(t *testing.T) {
+	// Arrange
+	hook := test.NewGlobal()
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	t.Cleanup(func() { log.SetLevel(level) })
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	require.NoError(t, os.WriteFile(
+		filepath.Join(os.Getenv("HOME"), ".gitconfig"),
+		[]byte("[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+		0o600,
+	))
+	var projects []ProjectConfig
+	for _, name := range []string{"api", "web"} {
+		projectPath, repo := newRedirectRepo(t, "https://gitlab.com/company/"+name+".git")
+		commitFile(t, repo, "CHANGELOG.md", changelogOriginal+"\n")
+		commitFile(t, repo, "version.txt", "version=1.0.1\n")
+		projects = append(projects, ProjectConfig{Name: name, Path: projectPath, Language: "text"})
+	}
+	globalConfig := &GlobalConfig{
+		Concurrency: 2,
+		LanguagesConfig: map[string]LanguageConfig{
+			"text": {VersionFiles: []VersionFile{{Path: "version.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}}}},
+		},
+	}
+
+	// Act
+	_ = processProjects(globalConfig, projects)
+
+	// Assert
+	entries := hook.AllEntries()
+	start := slices.IndexFunc(entries, func(entry *log.Entry) bool {
+		return entry.Message == "Processing 2 projects with 2 workers"
+	})
+	require.NotEqual(t, -1, start)
+	end := slices.IndexFunc(entries, func(entry *log.Entry) bool {
+		return strings.HasSuffix(entry.Message, "projects failed:")
+	})
+	if end == -1 {
+		end = len(entries)
+	}
+	logged := make(map[string]int)
+	for _, entry := range entries[start+1 : end] {
+		project, found := entry.Data[projectLogField]
+		assert.True(t, found, "the entry %q should have the project", entry.Message)
+		if found {
+			logged[project.(string)]++
+		}
+	}
+	assert.Positive(t, logged["api"])
+	assert.Positive(t, logged["web"])
+}
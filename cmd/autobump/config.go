@@ -5,21 +5,161 @@ import (
 	"fmt"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
 type GlobalConfig struct {
 	Projects               []ProjectConfig           `yaml:"projects"`
+	ProjectDefaults        ProjectConfig             `yaml:"project_defaults"`
 	LanguagesConfig        map[string]LanguageConfig `yaml:"languages"`
 	GpgKeyPath             string                    `yaml:"gpg_key_path"`
+	SSHKeyPath             string                    `yaml:"ssh_key_path"`
+	SignCommits            string                    `yaml:"sign_commits"`
 	GitLabAccessToken      string                    `yaml:"gitlab_access_token"`
+	GitHubAccessToken      string                    `yaml:"github_access_token"`
 	AzureDevOpsAccessToken string                    `yaml:"azure_devops_access_token"`
+	BitbucketAccessToken   string                    `yaml:"bitbucket_access_token"`
 	GitLabCIJobToken       string                    `yaml:"gitlab_ci_job_token"`
+	MaxFileSize            int64                     `yaml:"max_file_size"`
+	Changelog              ChangelogConfig           `yaml:"changelog"`
+	ChangelogLint          ChangelogLintConfig       `yaml:"changelog_lint"`
+	MaxPRsPerRun           int                       `yaml:"max_prs_per_run"`
+	MaxPRsPerOrg           int                       `yaml:"max_prs_per_org"`
+	Concurrency            int                       `yaml:"concurrency"`
+	OnLimit                string                    `yaml:"on_limit"`
+	OnConflict             string                    `yaml:"on_conflict"`
+	APIHeaders             map[string]string         `yaml:"api_headers"`
+	UserAgent              string                    `yaml:"user_agent"`
+	DigestOut              string                    `yaml:"digest_out"`
+	DigestWebhook          string                    `yaml:"digest_webhook"`
+	ReportOut              string                    `yaml:"report_out"`
+	ChangelogTemplatePath  string                    `yaml:"changelog_template_path"`
+	ChangelogTemplateURL   string                    `yaml:"changelog_template_url"`
+	Reproducible           bool                      `yaml:"reproducible"`
+	RunGitHooks            string                    `yaml:"run_git_hooks"`
+	RequirePR              bool                      `yaml:"require_pr"`
+	PrecheckUnreleased     bool                      `yaml:"precheck_unreleased"`
+	StrictPermissions      bool                      `yaml:"strict_permissions"`
+	VersionPolicy          VersionPolicyConfig       `yaml:"version_policy"`
+	AutoTidy               bool                      `yaml:"auto_tidy"`
+	// fail the projects requesting features their remote service doesn't support, instead of skipping them
+	StrictFeatures bool `yaml:"strict_features"`
+	// self-hosted instances of the remote services (e.g. an on-premises GitLab)
+	Providers []ProviderConfig `yaml:"providers"`
+	// GitHub Enterprise Server instance, along with its API and its token
+	GitHubEnterprise GitHubEnterpriseConfig `yaml:"github_enterprise"`
+	// oldest AutoBump reading the configuration, checked before anything else
+	MinAutoBumpVersion string `yaml:"min_autobump_version"`
+	// trailers appended to the bump commit, below the DCO sign-off
+	CommitTrailers CommitTrailersConfig `yaml:"commit_trailers"`
+	// tag the latest release once its bump was merged, pushing the tag
+	CreateTag bool `yaml:"create_tag"`
+	// publish the tagged releases on the remote service (GitHub or GitLab), with their entries as notes
+	CreateRelease bool `yaml:"create_release"`
+	// template of the bump branch, "chore/bump-{{.Version}}" by default
+	BranchTemplate string `yaml:"branch_template"`
+	// template of the bump commit subject and pull request title, "chore(bump): bumped version to {{.Version}}"
+	// by default
+	CommitMessageTemplate string `yaml:"commit_message_template"`
+	// template of the pull request title, the commit subject by default
+	PullRequestTitleTemplate string `yaml:"pr_title_template"`
+	// template of the pull request body, with the changes of the release ({{.Changelog}}) and the notes of AutoBump
+	PullRequestBodyTemplate string `yaml:"pr_body_template"`
+
+	// counter of the pull requests created in the current run
+	pullRequestLimiter *pullRequestLimiter
+	// releases prepared in the current run
+	releaseDigest *releaseDigest
+	// remote projects checked before cloning them in the current run
+	precheck *unreleasedPrecheck
+	// content of the CHANGELOG template, loaded from the template path
+	changelogTemplate string
+	// date of the releases, pinned by SOURCE_DATE_EPOCH in the reproducible mode
+	releaseDate time.Time
+	// whether this run is the release train, releasing the projects with "release_train"
+	train bool
+	// order and amount of the projects processed by the batch run
+	selection projectSelection
+	// tokens read from files, which are read again when rotated
+	tokenFiles *tokenFiles
+	// headers of the API requests of the providers, by host name
+	apiHeaders map[string]map[string]string
+	// template downloaded for the new CHANGELOG files in the current run
+	templateCache *changelogTemplateCache
+	// metadata of the repositories fetched from the provider APIs in the current run
+	repoMetadata *repoMetadataCache
+	// releases propagated to the umbrella repositories at the end of the batch run
+	propagations *propagationQueue
+}
+
+type ChangelogConfig struct {
+	NormalizeEntries          bool                      `yaml:"normalize_entries"`
+	MaxEntriesPerSection      int                       `yaml:"max_entries_per_section"`
+	EntryClassification       []EntryClassificationRule `yaml:"entry_classification"`
+	ClassifyDependencyUpdates bool                      `yaml:"classify_dependency_updates"`
+	IgnoreConflictMarkers     bool                      `yaml:"ignore_conflict_markers"`
+	NonBumpingSections        []string                  `yaml:"non_bumping_sections"`
+	SkipIfSubsetOfLastRelease bool                      `yaml:"skip_if_subset_of_last_release"`
+	CrossSectionDedup         bool                      `yaml:"cross_section_dedup"`
+	DedupPrecedence           []string                  `yaml:"dedup_precedence"`
+	SectionAliases            map[string]string         `yaml:"section_aliases"`
+	OutputHeadings            string                    `yaml:"output_headings"`
+	ArchiveReleasesOlderThan  string                    `yaml:"archive_releases_older_than"`
+	MaxReleasesInMainFile     int                       `yaml:"max_releases_in_main_file"`
+	ChangelogProfile          string                    `yaml:"changelog_profile"`
+
+	// URL of the repository remote, used to build the links
+	RepositoryURL string `yaml:"-"`
+	// versioning scheme of the project (and its CalVer format), used to compute the next version
+	VersioningScheme string `yaml:"-"`
+	CalVerFormat     string `yaml:"-"`
+	// date stamped on the new version (the current date when empty)
+	Date time.Time `yaml:"-"`
+	// independent versions released from the same CHANGELOG, and the prefix of the version being released
+	VersionStreams       []VersionStream `yaml:"-"`
+	DefaultVersionStream string          `yaml:"-"`
+	VersionPrefix        string          `yaml:"-"`
+	// rules rewriting or vetoing the computed version, and the directory where its command runs
+	VersionPolicy    VersionPolicyConfig `yaml:"-"`
+	VersionPolicyDir string              `yaml:"-"`
+	// entry logging the messages of the project, with its name when the projects are processed concurrently
+	logEntry *log.Entry
+}
+
+// logger returns the entry logging the messages of the project, the standard logger when it has none
+func (c ChangelogConfig) logger() *log.Entry {
+	if c.logEntry == nil {
+		return standardLogEntry()
+	}
+	return c.logEntry
+}
+
+type VersionPolicyConfig struct {
+	Pre1BreakingIsMinor bool   `yaml:"pre_1_0_breaking_is_minor"`
+	Command             string `yaml:"command"`
+}
+
+type CommitTrailersConfig struct {
+	Version  bool              `yaml:"version"`
+	Previous bool              `yaml:"previous"`
+	RunID    bool              `yaml:"run_id"`
+	Static   map[string]string `yaml:"static"`
+}
+
+type ChangelogLintConfig struct {
+	Spellcheck  bool     `yaml:"spellcheck"`
+	LintMode    string   `yaml:"lint_mode"`
+	IgnoreWords []string `yaml:"ignore_words"`
+	// review comments of the pull request suggesting the fixes of the CHANGELOG lines it adds
+	LintSuggestions bool `yaml:"lint_suggestions"`
+	MaxSuggestions  int  `yaml:"max_suggestions"`
 }
 
 type LanguageConfig struct {
@@ -34,13 +174,65 @@ type VersionFile struct {
 }
 
 type ProjectConfig struct {
-	Path               string `yaml:"path"`
-	Name               string `yaml:"name"`
-	Language           string `yaml:"language"`
-	ProjectAccessToken string `yaml:"project_access_token"`
-	NewVersion         string `yaml:"new_version"`
+	Path                  string          `yaml:"path"`
+	Name                  string          `yaml:"name"`
+	Language              string          `yaml:"language"`
+	ProjectAccessToken    string          `yaml:"project_access_token"`
+	NewVersion            string          `yaml:"new_version"`
+	BaseRef               string          `yaml:"base_ref"`
+	VersioningScheme      string          `yaml:"versioning_scheme"`
+	CalVerFormat          string          `yaml:"calver_format"`
+	ChangelogTemplatePath string          `yaml:"changelog_template_path"`
+	VersionStreams        []VersionStream `yaml:"version_streams"`
+	DefaultVersionStream  string          `yaml:"default_version_stream"`
+	ReleaseTrain          bool            `yaml:"release_train"`
+	WorkspacePropagation  bool            `yaml:"workspace_propagation"`
+	PropagateToPrivate    bool            `yaml:"propagate_to_private"`
+	SignCommits           string          `yaml:"sign_commits"`
+	VersionFileStrategy   string          `yaml:"version_file_strategy"`
+	DevSuffix             string          `yaml:"dev_suffix"`
+	NextDevIncrement      string          `yaml:"next_dev_increment"`
+	// update the version files matched by a glob even when the repository ignores them
+	AllowIgnoredVersionFiles bool `yaml:"allow_ignored_version_files"`
+	// rename the CHANGELOG with another case (e.g. "Changelog.md") to "CHANGELOG.md" in the bump commit
+	NormalizeChangelogFilename bool `yaml:"normalize_changelog_filename"`
+	// GitLab users asked to review the merge request, and group mentioned in it to notify the approvers
+	Reviewers   []string `yaml:"reviewers"`
+	NotifyGroup string   `yaml:"notify_group"`
+	// repository keeping the CHANGELOG of the project, released along with it
+	ChangelogRedirect *ChangelogRedirect `yaml:"changelog_redirect"`
+	// set the pull request to be merged with the mechanism fitting the merge settings of the repository
+	AutoMerge bool `yaml:"auto_merge"`
+	// manifest of an umbrella repository pinning the version of the project, updated after the bump
+	PropagateTo *PropagationTarget `yaml:"propagate_to"`
+	// prefix of the released versions ("v" or none), or "auto" to follow the style of the CHANGELOG
+	VersionPrefix string `yaml:"version_prefix"`
+	// where the released entries come from: the "Unreleased" section, or the Conventional Commits too
+	ChangelogSource string `yaml:"changelog_source"`
+	// tag the latest release once its bump was merged, overriding the global create_tag
+	CreateTag *bool `yaml:"create_tag"`
+	// publish the tagged releases on the remote service, overriding the global create_release
+	CreateRelease *bool `yaml:"create_release"`
+	// template of the bump branch, overriding the global branch_template
+	BranchTemplate string `yaml:"branch_template"`
+	// template of the bump commit subject, overriding the global commit_message_template
+	CommitMessageTemplate string `yaml:"commit_message_template"`
+	// template of the pull request title, overriding the global pr_title_template
+	PullRequestTitleTemplate string `yaml:"pr_title_template"`
+	// template of the pull request body, overriding the global pr_body_template
+	PullRequestBodyTemplate string `yaml:"pr_body_template"`
+
+	// stable identifier of the project across runs, computed from the project path before cloning
+	id string
+	// credentials found embedded in the project URL, prioritized over any other token
+	embeddedAuth *http.BasicAuth
+	// content of the CHANGELOG template, loaded from the template path
+	changelogTemplate string
 }
 
+// defaultMaxFileSize is the maximum size of the files read by AutoBump when not configured (10 MiB)
+const defaultMaxFileSize = 10 * 1024 * 1024
+
 const defaultConfigURL = "https://raw.githubusercontent.com/rios0rios0/autobump/" +
 	"main/configs/autobump.yaml"
 
@@ -63,23 +255,49 @@ func readConfig(configPath string) (*GlobalConfig, error) {
 	}
 
 	for i := range globalConfig.Projects {
-		if globalConfig.Projects[i].Name == "" {
-			basename := path.Base(globalConfig.Projects[i].Path)
-			basename = strings.TrimSuffix(basename, ".git")
-			globalConfig.Projects[i].Name = basename
-		}
+		prepareProject(&globalConfig.Projects[i])
 	}
 
-	handleTokenFile("GitLab", &globalConfig.GitLabAccessToken)
-	handleTokenFile("Azure DevOps", &globalConfig.AzureDevOpsAccessToken)
+	maxFileSize := getMaxFileSize(globalConfig)
+	globalConfig.templateCache = &changelogTemplateCache{}
+	globalConfig.repoMetadata = &repoMetadataCache{}
+
+	strict := globalConfig.StrictPermissions
+	globalConfig.tokenFiles = &tokenFiles{maxFileSize: maxFileSize}
+	for _, provider := range []struct {
+		name  string
+		token *string
+	}{
+		{name: "GitLab", token: &globalConfig.GitLabAccessToken},
+		{name: "GitHub", token: &globalConfig.GitHubAccessToken},
+		{name: "Azure DevOps", token: &globalConfig.AzureDevOpsAccessToken},
+		{name: "Bitbucket", token: &globalConfig.BitbucketAccessToken},
+		{name: "GitHub Enterprise", token: &globalConfig.GitHubEnterprise.AccessToken},
+	} {
+		tokenPath, err := handleTokenFile(provider.name, provider.token, maxFileSize, strict)
+		if err != nil {
+			return nil, err
+		}
+		globalConfig.tokenFiles.add(provider.name, tokenPath, *provider.token)
+	}
+	// the credentials stored by "autobump auth" are the last resort
+	resolveStoredTokens(globalConfig)
+	globalConfig.apiHeaders, err = resolveAPIHeaders(globalConfig, maxFileSize, strict)
+	if err != nil {
+		return nil, err
+	}
 
 	globalConfig.GitLabCIJobToken = os.Getenv("CI_JOB_TOKEN")
 
 	return globalConfig, nil
 }
 
-// readData reads data from a file or a URL
+// readData reads data from a file or a URL, the defaults of the AutoBump repository are cached locally
 func readData(configPath string) ([]byte, error) {
+	if entry, cached := cachedDefaults[configPath]; cached {
+		return defaultsCache.get(configPath, entry.name, entry.validate)
+	}
+
 	uri, err := url.Parse(configPath)
 	if err != nil || uri.Scheme == "" || uri.Host == "" {
 		// It's not a URL, read the data from file
@@ -94,36 +312,75 @@ func readData(configPath string) ([]byte, error) {
 	return downloadFile(configPath)
 }
 
-// handleTokenFile reads the token from a file if it exists and replaces the token string
-func handleTokenFile(name string, token *string) {
+// handleTokenFile reads the token from a file if it exists and replaces the token string, returning the path
+// of the file read. The files readable by other users are refused in the strict mode.
+func handleTokenFile(name string, token *string, maxFileSize int64, strict bool) (string, error) {
 	if *token != "" {
-		if _, err := os.Stat(*token); !os.IsNotExist(err) {
-			log.Infof("Reading %s access token from file %s", name, *token)
-			var fileToken []byte
-			fileToken, err = os.ReadFile(*token)
+		if info, err := os.Stat(*token); !os.IsNotExist(err) {
+			tokenPath := *token
+			log.Infof("Reading %s access token from file %s", name, tokenPath)
+			if info != nil {
+				err = checkTokenFilePermissions(name, tokenPath, info.Mode(), strict)
+				if err != nil {
+					return "", err
+				}
+			}
+
+			fileToken, err := readTokenFile(tokenPath, maxFileSize)
 			if err != nil {
 				log.Errorf("failed to read %s access token: %v", name, err)
-			} else {
-				*token = strings.TrimSpace(string(fileToken))
+				return "", nil
 			}
+			*token = fileToken
+			return tokenPath, nil
 		}
 	}
+	return "", nil
+}
+
+// getMaxFileSize returns the maximum size of the files read by AutoBump
+func getMaxFileSize(globalConfig *GlobalConfig) int64 {
+	if globalConfig.MaxFileSize > 0 {
+		return globalConfig.MaxFileSize
+	}
+	return defaultMaxFileSize
 }
 
 // decodeConfig decodes the config file and returns a GlobalConfig struct
 func decodeConfig(data []byte) (*GlobalConfig, error) {
 	var globalConfig GlobalConfig
 
+	err := checkMinAutoBumpVersion(data, version)
+	if err != nil {
+		return nil, err
+	}
+
 	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
 	decoder.KnownFields(true)
-	err := decoder.Decode(&globalConfig)
+	err = decoder.Decode(&globalConfig)
 	if err != nil {
+		// the parser stops at the first unknown key, while the migration lists all of them
+		var typeError *yaml.TypeError
+		if errors.As(err, &typeError) && slices.ContainsFunc(typeError.Errors, isUnknownFieldError) {
+			return nil, fmt.Errorf(
+				"failed to decode config, run `autobump config migrate` to list the unknown keys: %w", err,
+			)
+		}
 		return nil, fmt.Errorf("failed to decode config: %w", err)
 	}
 
+	err = applyProjectDefaults(data, &globalConfig)
+	if err != nil {
+		return nil, err
+	}
 	return &globalConfig, nil
 }
 
+// isUnknownFieldError tells whether the decoding error is about a key unknown to the schema
+func isUnknownFieldError(message string) bool {
+	return strings.Contains(message, "not found in type")
+}
+
 // validateGlobalConfig validates the global config and reports missing keys and errors
 func validateGlobalConfig(globalConfig *GlobalConfig, batch bool) error {
 	var missingKeys []string
@@ -149,6 +406,72 @@ func validateGlobalConfig(globalConfig *GlobalConfig, batch bool) error {
 		}
 	}
 
+	if err := validateConcurrency(globalConfig.Concurrency); err != nil {
+		return err
+	}
+
+	if err := validateOnLimitMode(globalConfig.OnLimit); err != nil {
+		return err
+	}
+
+	if err := validateOnConflictMode(globalConfig.OnConflict); err != nil {
+		return err
+	}
+
+	if err := validateGitHooksMode(globalConfig.RunGitHooks); err != nil {
+		return err
+	}
+
+	if err := validateLintMode(globalConfig.ChangelogLint.LintMode); err != nil {
+		return err
+	}
+
+	if err := validateSignCommits(globalConfig, getGlobalSigningKeyID); err != nil {
+		return err
+	}
+
+	if err := validateBumpTemplates(globalConfig, &ProjectConfig{}); err != nil {
+		return err
+	}
+
+	for projectIndex := range globalConfig.Projects {
+		if err := validateProjectConfig(globalConfig, &globalConfig.Projects[projectIndex]); err != nil {
+			return fmt.Errorf("projects[%d]: %w", projectIndex, err)
+		}
+	}
+
+	if _, err := getEntryClassifiers(globalConfig.Changelog); err != nil {
+		return err
+	}
+
+	if err := validateSectionAliases(globalConfig.Changelog); err != nil {
+		return err
+	}
+
+	if err := validateChangelogArchive(globalConfig.Changelog); err != nil {
+		return err
+	}
+
+	if err := validateChangelogProfile(globalConfig.Changelog); err != nil {
+		return err
+	}
+
+	if err := validateCommitTrailers(globalConfig.CommitTrailers); err != nil {
+		return err
+	}
+
+	if err := loadProviders(globalConfig); err != nil {
+		return err
+	}
+
+	if err := loadReleaseDate(globalConfig); err != nil {
+		return err
+	}
+
+	if err := loadChangelogTemplates(globalConfig); err != nil {
+		return err
+	}
+
 	if len(missingKeys) > 0 {
 		return fmt.Errorf("%w: %s", ErrConfigKeyMissingError, strings.Join(missingKeys, ", "))
 	}
@@ -160,6 +483,32 @@ func validateGlobalConfig(globalConfig *GlobalConfig, batch bool) error {
 	return nil
 }
 
+// validateProjectConfig validates the settings of a project, whether configured or read from a projects file
+func validateProjectConfig(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
+	if err := validateVersioningScheme(projectConfig); err != nil {
+		return err
+	}
+	if err := validateVersionStreams(projectConfig); err != nil {
+		return err
+	}
+	if err := validateChangelogRedirect(projectConfig); err != nil {
+		return err
+	}
+	if err := validateVersionFileStrategy(projectConfig); err != nil {
+		return err
+	}
+	if err := validatePropagationTarget(projectConfig); err != nil {
+		return err
+	}
+	if err := validateVersionPrefix(projectConfig); err != nil {
+		return err
+	}
+	if err := validateChangelogSource(projectConfig); err != nil {
+		return err
+	}
+	return validateBumpTemplates(globalConfig, projectConfig)
+}
+
 // findConfigOnMissing finds the config file if not manually set
 func findConfigOnMissing(configPath string) string {
 	if configPath == "" {
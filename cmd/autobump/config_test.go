@@ -1,9 +1,11 @@
 package main
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -96,3 +98,37 @@ func TestValidateGlobalConfig_MissingLanguagesConfig(t *testing.T) {
 	// Assert
 	require.ErrorIs(t, err, ErrLanguagesKeyMissingError)
 }
+
+func TestDecodeConfig_UnknownKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		wantHint bool
+	}{
+		{
+			name:     "should point to the migration when a key is unknown",
+			data:     "projects:\n  - path: /tmp\n    colour: blue\n",
+			wantHint: true,
+		},
+		{
+			name:     "should not point to the migration when a value has the wrong type",
+			data:     "concurrency: many\n",
+			wantHint: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := decodeConfig([]byte(tt.data))
+
+			// Assert
+			require.Error(t, err)
+			assert.Equal(t, tt.wantHint, strings.Contains(err.Error(), "run `autobump config migrate`"))
+		})
+	}
+}
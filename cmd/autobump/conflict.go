@@ -0,0 +1,260 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	log "github.com/sirupsen/logrus"
+)
+
+// actions taken when the pull request conflicts with its target branch
+const (
+	onConflictReport = "report" // only report the conflict
+	onConflictRebase = "rebase" // bump again on top of the target branch, and force-push the bump branch
+	onConflictFail   = "fail"   // report the conflict and fail the project
+)
+
+// mergeability of the pull request, as computed by the provider
+const (
+	mergeabilityMergeable  = "mergeable"
+	mergeabilityConflicted = "conflicted"
+	// the provider didn't compute it before the end of the poll, or couldn't be asked
+	mergeabilityUnknown = "unknown"
+	// the provider is still computing it
+	mergeabilityChecking = "checking"
+)
+
+const (
+	// mergeabilityPollAttempts is how many times the mergeability is asked, since the providers compute it
+	// asynchronously after the creation of the pull request and after each push
+	mergeabilityPollAttempts = 5
+	// mergeabilityPollInterval is how long to wait between the attempts
+	mergeabilityPollInterval = 2 * time.Second
+)
+
+var (
+	ErrInvalidOnConflictMode = errors.New("invalid on_conflict mode")
+	ErrPullRequestConflicted = errors.New("the pull request conflicts with its target branch")
+	ErrNothingLeftToRelease  = errors.New("the target branch already released all the entries")
+)
+
+// MergeabilityResult is whether the pull request can be merged into its target branch, written in the run report
+type MergeabilityResult struct {
+	Status string `json:"status"`
+	// whether the bump branch was rebased on the target branch to solve a conflict
+	Rebased bool `json:"rebased,omitempty"`
+	// why the conflict couldn't be solved, when it wasn't
+	Error string `json:"error,omitempty"`
+}
+
+// pullRequestHandle reaches the pull request once created, whatever its provider
+type pullRequestHandle struct {
+	// returns the mergeability of the pull request
+	getMergeability func() (string, error)
+	// replaces the title and the description of the pull request
+	update func(title string, description string) error
+	// how long to wait between the attempts of the poll
+	pollInterval time.Duration
+}
+
+// validateOnConflictMode checks whether the action taken when the pull request conflicts is supported
+func validateOnConflictMode(mode string) error {
+	switch mode {
+	case "", onConflictReport, onConflictRebase, onConflictFail:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: %s (expected %s, %s or %s)", ErrInvalidOnConflictMode, mode, onConflictReport, onConflictRebase, onConflictFail,
+		)
+	}
+}
+
+// pollMergeability asks the mergeability until the provider computed it, up to the attempts
+func pollMergeability(handle *pullRequestHandle, attempts int, logger *log.Entry) string {
+	for attempt := 1; attempt <= attempts; attempt++ {
+		mergeability, err := handle.getMergeability()
+		if err != nil {
+			logger.Warnf("Unable to get the mergeability of the pull request: %v", err)
+			return mergeabilityUnknown
+		}
+		if mergeability != mergeabilityChecking {
+			return mergeability
+		}
+		if attempt < attempts {
+			logger.Debugf("The provider is still checking the mergeability of the pull request (attempt %d)", attempt)
+			time.Sleep(handle.pollInterval)
+		}
+	}
+	return mergeabilityUnknown
+}
+
+// checkPullRequestConflicts records whether the pull request conflicts with its target branch (e.g. another bump was
+// merged after the clone), and rebases the bump branch or fails the project when configured so.
+// Nothing is checked unless on_conflict is set and supported by the remote service.
+func checkPullRequestConflicts(ctx *RepoContext, changelogPath string, branchName string) error {
+	handle := ctx.state.pullRequest
+	if ctx.globalConfig.OnConflict == "" || handle == nil || isFeatureSkipped(&ctx.state, featureOnConflict) {
+		return nil
+	}
+
+	result := &MergeabilityResult{Status: pollMergeability(handle, mergeabilityPollAttempts, ctx.logger())}
+	ctx.state.mergeability = result
+	if result.Status != mergeabilityConflicted {
+		ctx.logger().Infof("The pull request is %s", result.Status)
+		return nil
+	}
+
+	switch ctx.globalConfig.OnConflict {
+	case onConflictRebase:
+		err := remediateConflict(ctx, changelogPath, branchName, handle)
+		if err != nil {
+			ctx.logger().Warnf("Unable to solve the conflict of the pull request, solve it by hand: %v", err)
+			result.Error = err.Error()
+			return nil
+		}
+		result.Rebased = true
+		result.Status = pollMergeability(handle, mergeabilityPollAttempts, ctx.logger())
+		ctx.logger().Infof("The bump branch was rebased on the target branch, the pull request is %s", result.Status)
+	case onConflictFail:
+		return fmt.Errorf("%w: %s", ErrPullRequestConflicted, ctx.pullRequestURL)
+	default:
+		ctx.logger().Warnf("The pull request conflicts with its target branch, solve it by hand: %s", ctx.pullRequestURL)
+	}
+	return nil
+}
+
+// remediateConflict bumps again on top of the target branch, force-pushes the bump branch
+// (only if nobody else pushed it meanwhile) and updates the pull request with the new version
+func remediateConflict(ctx *RepoContext, changelogPath string, branchName string, handle *pullRequestHandle) error {
+	defer func() {
+		if checkoutErr := checkoutToMainBranch(ctx); checkoutErr != nil {
+			ctx.logger().Warnf("Unable to switch back to the main branch: %v", checkoutErr)
+		}
+	}()
+	lease, err := rebaseBumpBranch(ctx, changelogPath, branchName)
+	if err != nil {
+		return err
+	}
+
+	stopTimer := ctx.timer.start(phasePush)
+	err = pushBranch(ctx, branchName, lease)
+	stopTimer()
+	if err != nil {
+		return err
+	}
+
+	return handle.update(
+		getPullRequestTitle(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.projectConfig.NewVersion, ctx.logger()),
+		getPullRequestDescription(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger()),
+	)
+}
+
+// rebaseBumpBranch fetches the target branch and bumps again on top of it, so the entries the other releases left
+// in the "Unreleased" section are released on top of them. The bump branch is replaced by the new bump commit,
+// returning the lease of its force-push: the bump commit pushed before.
+func rebaseBumpBranch(ctx *RepoContext, changelogPath string, branchName string) (*git.ForceWithLease, error) {
+	branchRef, err := ctx.repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the bump branch: %w", err)
+	}
+	lease := &git.ForceWithLease{RefName: branchRef.Name(), Hash: branchRef.Hash()}
+
+	targetBranch := getTargetBranch(ctx.projectConfig)
+	target, err := fetchTargetBranch(ctx, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.logger().Infof("Rebasing the bump branch '%s' on '%s' (%s)", branchName, targetBranch, target.Hash)
+	err = ctx.repo.Storer.SetReference(plumbing.NewHashReference(branchRef.Name(), target.Hash))
+	if err != nil {
+		return nil, fmt.Errorf("could not reset the bump branch: %w", err)
+	}
+	err = checkoutBranch(ctx.repo, ctx.worktree, branchName, ctx.logger())
+	if err != nil {
+		return nil, err
+	}
+
+	// the CHANGELOG is read from the target branch, as fetched
+	ctx.baseCommit = target
+	bumpNeeded, err := shouldBumpProject(ctx, changelogPath)
+	if err != nil {
+		return nil, err
+	}
+	if !bumpNeeded {
+		return nil, fmt.Errorf("%w: close the pull request", ErrNothingLeftToRelease)
+	}
+
+	err = updateChangelogAndVersionFiles(ctx, changelogPath)
+	if err != nil {
+		return nil, err
+	}
+	stopTimer := ctx.timer.start(phaseCommit)
+	_, err = commitChangesWithGPG(ctx)
+	stopTimer()
+	if err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// fetchTargetBranch fetches the latest commit of the target branch from the origin remote
+func fetchTargetBranch(ctx *RepoContext, targetBranch string) (*object.Commit, error) {
+	remoteRef := plumbing.NewRemoteReferenceName("origin", targetBranch)
+	fetchOptions := &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec("+refs/heads/" + targetBranch + ":" + remoteRef.String())},
+		Depth:      1,
+		Progress:   newProgressLogger("fetch", ctx.logger()),
+	}
+
+	remote, err := ctx.repo.Remote("origin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote origin: %w", err)
+	}
+
+	// the SSH transport authenticates with the SSH agent by default
+	authMethods := []transport.AuthMethod{nil}
+	remoteURL := remote.Config().URLs[0]
+	if strings.HasPrefix(remoteURL, "https://") || strings.HasPrefix(remoteURL, "http://") {
+		authMethods, err = getAuthMethods(
+			remoteURL,
+			ctx.globalGitConfig.Raw.Section("user").Option("name"),
+			ctx.globalConfig,
+			ctx.projectConfig,
+			ctx.logger(),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, auth := range authMethods {
+		fetchOptions.Auth = auth
+		err = ctx.repo.Fetch(fetchOptions)
+		if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+			err = nil
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", targetBranch, err)
+	}
+
+	ref, err := ctx.repo.Reference(remoteRef, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the fetched %s: %w", targetBranch, err)
+	}
+	commit, err := ctx.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the commit of %s: %w", targetBranch, err)
+	}
+	return commit, nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScriptedPullRequest is a pull request whose provider answers the mergeabilities in order, the last one repeated
+func newScriptedPullRequest(mergeabilities ...string) (*pullRequestHandle, *int) {
+	calls := 0
+	return &pullRequestHandle{
+		getMergeability: func() (string, error) {
+			mergeability := mergeabilities[min(calls, len(mergeabilities)-1)]
+			calls++
+			return mergeability, nil
+		},
+		update: func(string, string) error { return nil },
+	}, &calls
+}
+
+// newConflictedBump clones a repository and bumps it, then merges another bump into its "main" branch,
+// with an entry added after it, so the bump branch conflicts with it
+func newConflictedBump(t *testing.T) (*RepoContext, string, plumbing.Hash, plumbing.Hash) {
+	t.Helper()
+
+	remotePath := t.TempDir()
+	remoteRepo, err := git.PlainInit(remotePath, false)
+	require.NoError(t, err)
+	require.NoError(t, remoteRepo.Storer.SetReference(
+		plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main")),
+	))
+	commitFile(t, remoteRepo, "CHANGELOG.md", changelogOriginal+"\n")
+	commitFile(t, remoteRepo, "version.txt", "version=1.0.1\n")
+
+	localPath := t.TempDir()
+	_, err = git.PlainClone(localPath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+	branchName := bumpProject(t, localPath)
+
+	merged := strings.Replace(
+		fmt.Sprintf(changelogExpected, "2024-06-01"), "## [Unreleased]", "## [Unreleased]\n\n### Fixed\n\n- Fixed the retries.", 1,
+	)
+	commitFile(t, remoteRepo, "CHANGELOG.md", merged+"\n")
+	targetHash := commitFile(t, remoteRepo, "version.txt", "version=1.1.0\n")
+
+	ctx := &RepoContext{
+		globalConfig: &GlobalConfig{
+			LanguagesConfig: map[string]LanguageConfig{
+				"text": {VersionFiles: []VersionFile{{Path: "version.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}}}},
+			},
+			releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+		},
+		projectConfig:   &ProjectConfig{Path: localPath, Name: "api", Language: "text"},
+		globalGitConfig: newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+		timer:           newPhaseTimer(standardLogEntry()),
+	}
+	require.NoError(t, setupRepo(ctx))
+	require.NoError(t, checkoutToMainBranch(ctx))
+
+	bumpRef, err := ctx.repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	require.NoError(t, err)
+	return ctx, branchName, bumpRef.Hash(), targetHash
+}
+
+func TestRebaseBumpBranch_ReleasesTheEntriesLeftOnTheTarget(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, branchName, bumpHash, targetHash := newConflictedBump(t)
+
+	// Act
+	lease, err := rebaseBumpBranch(ctx, filepath.Join(ctx.projectConfig.Path, "CHANGELOG.md"), branchName)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, bumpHash, lease.Hash, "the force-push should expect the bump commit pushed before")
+	assert.Equal(t, plumbing.NewBranchReferenceName(branchName), lease.RefName)
+	assert.Equal(t, "1.1.1", ctx.projectConfig.NewVersion)
+
+	bumpRef, err := ctx.repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	require.NoError(t, err)
+	bumpCommit, err := ctx.repo.CommitObject(bumpRef.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{targetHash}, bumpCommit.ParentHashes, "the bump should be on top of the target")
+
+	changelog := readBranchFile(t, ctx.repo, branchName, "CHANGELOG.md")
+	assert.Contains(t, changelog, "## [1.1.1] - 2024-06-08\n\n### Fixed\n\n- Fixed the retries.")
+	assert.Contains(t, changelog, "## [1.1.0] - 2024-06-01\n\n### Added\n\n- Another new feature.")
+	assert.Equal(t, "version=1.1.1\n", readBranchFile(t, ctx.repo, branchName, "version.txt"))
+}
+
+func TestRebaseBumpBranch_NothingLeftToRelease(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, branchName, _, _ := newConflictedBump(t)
+	remote, err := ctx.repo.Remote("origin")
+	require.NoError(t, err)
+	remoteRepo, err := git.PlainOpen(remote.Config().URLs[0])
+	require.NoError(t, err)
+	commitFile(t, remoteRepo, "CHANGELOG.md", fmt.Sprintf(changelogExpected, "2024-06-01")+"\n")
+
+	// Act
+	_, err = rebaseBumpBranch(ctx, filepath.Join(ctx.projectConfig.Path, "CHANGELOG.md"), branchName)
+
+	// Assert
+	require.ErrorIs(t, err, ErrNothingLeftToRelease)
+}
+
+func TestPollMergeability_ConflictedThenClean(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	handle, calls := newScriptedPullRequest(
+		mergeabilityChecking, mergeabilityConflicted, mergeabilityChecking, mergeabilityMergeable,
+	)
+
+	// Act
+	beforeRebase := pollMergeability(handle, mergeabilityPollAttempts, standardLogEntry())
+	afterRebase := pollMergeability(handle, mergeabilityPollAttempts, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, mergeabilityConflicted, beforeRebase)
+	assert.Equal(t, mergeabilityMergeable, afterRebase)
+	assert.Equal(t, 4, *calls)
+}
+
+func TestPollMergeability_GivesUpWhileTheProviderChecks(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	handle, calls := newScriptedPullRequest(mergeabilityChecking)
+
+	// Act
+	mergeability := pollMergeability(handle, 3, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, mergeabilityUnknown, mergeability)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestCheckPullRequestConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		onConflict   string
+		mergeability string
+		expected     *MergeabilityResult
+		err          error
+	}{
+		{name: "should not check without on_conflict", mergeability: mergeabilityConflicted},
+		{
+			name:         "should report the conflict",
+			onConflict:   onConflictReport,
+			mergeability: mergeabilityConflicted,
+			expected:     &MergeabilityResult{Status: mergeabilityConflicted},
+		},
+		{
+			name:         "should fail on the conflict",
+			onConflict:   onConflictFail,
+			mergeability: mergeabilityConflicted,
+			expected:     &MergeabilityResult{Status: mergeabilityConflicted},
+			err:          ErrPullRequestConflicted,
+		},
+		{
+			name:         "should record the mergeable pull request",
+			onConflict:   onConflictRebase,
+			mergeability: mergeabilityMergeable,
+			expected:     &MergeabilityResult{Status: mergeabilityMergeable},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			handle, _ := newScriptedPullRequest(test.mergeability)
+			ctx := &RepoContext{
+				globalConfig:  &GlobalConfig{OnConflict: test.onConflict},
+				projectConfig: &ProjectConfig{},
+				state:         projectState{pullRequest: handle},
+			}
+
+			// Act
+			err := checkPullRequestConflicts(ctx, "CHANGELOG.md", "chore/bump-1.1.0")
+
+			// Assert
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, ctx.state.mergeability)
+		})
+	}
+}
+
+func TestGetGitLabMergeability(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{name: "should wait for the check", response: `{"detailed_merge_status": "checking"}`, expected: "checking"},
+		{name: "should find the conflict", response: `{"detailed_merge_status": "conflict"}`, expected: "conflicted"},
+		{
+			name:     "should ignore the other blockers",
+			response: `{"detailed_merge_status": "not_approved", "has_conflicts": false}`,
+			expected: "mergeable",
+		},
+		{
+			name:     "should read the merge status of the older versions",
+			response: `{"merge_status": "cannot_be_merged"}`,
+			expected: "conflicted",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			mergeability := getGitLabMergeability(newGitLabMergeRequest(t, test.response))
+
+			// Assert
+			assert.Equal(t, test.expected, mergeability)
+		})
+	}
+}
+
+func TestNewGitLabPullRequestHandle_ReachesTheMergeRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /projects/42/merge_requests/3": `{"iid": 3, "detailed_merge_status": "conflict"}`,
+		"PUT /projects/42/merge_requests/3": `{"iid": 3}`,
+	})
+	handle := newGitLabPullRequestHandle(client, 42, 3)
+
+	// Act
+	mergeability, err := handle.getMergeability()
+	require.NoError(t, err)
+	err = handle.update("chore(bump): bumped version to 1.1.1", "### Fixed")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, mergeabilityConflicted, mergeability)
+	requests := getRequests()
+	require.Len(t, requests, 2)
+	assert.JSONEq(t, `{"title": "chore(bump): bumped version to 1.1.1", "description": "### Fixed"}`, requests[1].body)
+}
+
+func TestGetAzureDevOpsMergeability(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	assert.Equal(t, mergeabilityChecking, getAzureDevOpsMergeability("queued"))
+	assert.Equal(t, mergeabilityConflicted, getAzureDevOpsMergeability("conflicts"))
+	assert.Equal(t, mergeabilityMergeable, getAzureDevOpsMergeability("rejectedByPolicy"))
+	assert.Equal(t, mergeabilityUnknown, getAzureDevOpsMergeability("failure"))
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// service and user of the keyring item holding the credentials
+	credentialsKeyringService = "autobump"
+	credentialsKeyringUser    = "credentials"
+
+	// encrypts the credentials file when there is no keyring (e.g. on a headless Linux)
+	credentialsPassphraseEnv = "AUTOBUMP_CREDENTIALS_PASSPHRASE"
+
+	credentialsFileName          = "credentials.enc"
+	plaintextCredentialsFileName = "credentials"
+
+	credentialsSaltSize = 16
+	credentialsKeySize  = 32
+	// cost parameters of scrypt recommended for the interactive logins
+	credentialsScryptN = 1 << 15
+	credentialsScryptR = 8
+	credentialsScryptP = 1
+)
+
+var (
+	ErrNoCredentialStore        = errors.New("no keyring to store the credentials")
+	ErrInvalidCredentialsFile   = errors.New("invalid credentials file")
+	ErrWrongCredentialsPassword = errors.New("wrong passphrase of the credentials file")
+)
+
+// credentialStore keeps the credentials of "autobump auth", keyed by their provider and host (see getCredentialKey)
+type credentialStore interface {
+	read() (map[string]StoredCredential, error)
+	write(credentials map[string]StoredCredential) error
+	// String describes where the credentials are stored, for the messages of the commands
+	String() string
+}
+
+// getCredentialKey returns the key of the credential of the provider on the host, so the instances of a provider
+// (e.g. gitlab.com and a self-hosted GitLab) have their own credential
+func getCredentialKey(provider, host string) string {
+	return provider + "@" + getCredentialHost(host)
+}
+
+// getCredentialHost returns the host (and port) of the instance, without its scheme, path or case
+func getCredentialHost(host string) string {
+	baseURL, err := url.Parse(getHostBaseURL(host))
+	if err != nil || baseURL.Host == "" {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(baseURL.Host)
+}
+
+// keyringStore keeps the credentials in the keyring of the system (e.g. the macOS Keychain,
+// the Windows Credential Manager or the Secret Service of Linux)
+type keyringStore struct{}
+
+func (keyringStore) read() (map[string]StoredCredential, error) {
+	credentials := make(map[string]StoredCredential)
+
+	data, err := keyring.Get(credentialsKeyringService, credentialsKeyringUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials from the keyring: %w", err)
+	}
+
+	err = json.Unmarshal([]byte(data), &credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+func (keyringStore) write(credentials map[string]StoredCredential) error {
+	if len(credentials) == 0 {
+		err := keyring.Delete(credentialsKeyringService, credentialsKeyringUser)
+		if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("failed to remove credentials from the keyring: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	err = keyring.Set(credentialsKeyringService, credentialsKeyringUser, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write credentials to the keyring: %w", err)
+	}
+	return nil
+}
+
+func (keyringStore) String() string {
+	return "the system keyring"
+}
+
+// encryptedFileStore keeps the credentials in a file encrypted with AES-GCM,
+// whose key is derived with scrypt from a passphrase
+type encryptedFileStore struct {
+	path       string
+	passphrase string
+}
+
+func (s encryptedFileStore) read() (map[string]StoredCredential, error) {
+	credentials := make(map[string]StoredCredential)
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	// the file is the salt of the key, followed by the nonce and the sealed credentials
+	if len(data) < credentialsSaltSize {
+		return nil, ErrInvalidCredentialsFile
+	}
+	aead, err := newCredentialsCipher(s.passphrase, data[:credentialsSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	sealed := data[credentialsSaltSize:]
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCredentialsFile
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWrongCredentialsPassword, s.path)
+	}
+
+	err = json.Unmarshal(plaintext, &credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+func (s encryptedFileStore) write(credentials map[string]StoredCredential) error {
+	err := os.MkdirAll(filepath.Dir(s.path), 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	// a new salt and nonce on each write, so the same credentials are never encrypted twice with the same key
+	salt := make([]byte, credentialsSaltSize)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("failed to generate the salt: %w", err)
+	}
+	aead, err := newCredentialsCipher(s.passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate the nonce: %w", err)
+	}
+	data := append(salt, aead.Seal(nonce, nonce, plaintext, nil)...)
+
+	// write to a temporary file first, so the credentials are never left half-written
+	tempPath := s.path + ".tmp"
+	err = os.WriteFile(tempPath, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	err = os.Rename(tempPath, s.path)
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+func (s encryptedFileStore) String() string {
+	return s.path
+}
+
+// newCredentialsCipher derives the key of the credentials file from the passphrase and its salt
+func newCredentialsCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(
+		[]byte(passphrase), salt, credentialsScryptN, credentialsScryptR, credentialsScryptP, credentialsKeySize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the credentials key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the credentials cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the credentials cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// getCredentialsDir returns the directory of the credentials files
+func getCredentialsDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "autobump"), nil
+}
+
+// openCredentialStore returns the keyring of the system when there is one, and the credentials file encrypted with
+// the passphrase of AUTOBUMP_CREDENTIALS_PASSPHRASE otherwise. The plaintext credentials of the previous versions
+// are moved into the store.
+func openCredentialStore(credentialsDir string) (credentialStore, error) {
+	var store credentialStore
+	_, err := keyring.Get(credentialsKeyringService, credentialsKeyringUser)
+	if err == nil || errors.Is(err, keyring.ErrNotFound) {
+		store = keyringStore{}
+	} else if passphrase := os.Getenv(credentialsPassphraseEnv); passphrase != "" {
+		registerSecret(passphrase)
+		log.Debugf("Storing the credentials in an encrypted file, the keyring is unavailable: %v", err)
+		store = encryptedFileStore{path: filepath.Join(credentialsDir, credentialsFileName), passphrase: passphrase}
+	} else {
+		return nil, fmt.Errorf("%w (%w), set %s to store them in an encrypted file instead",
+			ErrNoCredentialStore, err, credentialsPassphraseEnv)
+	}
+
+	err = migratePlaintextCredentials(filepath.Join(credentialsDir, plaintextCredentialsFileName), store)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// migratePlaintextCredentials moves the credentials of the plaintext file, keyed only by their provider,
+// into the store and removes the file
+func migratePlaintextCredentials(plaintextPath string, store credentialStore) error {
+	data, err := os.ReadFile(plaintextPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var plaintextCredentials map[string]StoredCredential
+	err = json.Unmarshal(data, &plaintextCredentials)
+	if err != nil {
+		return fmt.Errorf("failed to decode credentials: %w", err)
+	}
+
+	credentials, err := store.read()
+	if err != nil {
+		return err
+	}
+	for provider, credential := range plaintextCredentials {
+		key := getCredentialKey(provider, credential.Host)
+		// the credentials logged in since then are more recent
+		if _, found := credentials[key]; !found {
+			credentials[key] = credential
+		}
+	}
+	err = store.write(credentials)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(plaintextPath)
+	if err != nil {
+		return fmt.Errorf("failed to remove the plaintext credentials: %w", err)
+	}
+	log.Infof("Moved the plaintext credentials of %s to %s", plaintextPath, store)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestGetCredentialKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{name: "should key the host alone", host: "gitlab.com", expected: "gitlab@gitlab.com"},
+		{name: "should drop the scheme and the path", host: "https://GitLab.company.io/", expected: "gitlab@gitlab.company.io"},
+		{name: "should keep the port", host: "http://127.0.0.1:8080", expected: "gitlab@127.0.0.1:8080"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			key := getCredentialKey(authProviderGitLab, test.host)
+
+			// Assert
+			assert.Equal(t, test.expected, key)
+		})
+	}
+}
+
+func TestEncryptedFileStore_WrongPassphrase(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	store := newCredentialsFile(t)
+	require.NoError(t, store.write(map[string]StoredCredential{
+		"gitlab@gitlab.com": {Host: defaultGitLabHost, AccessToken: "gitlab-token"},
+	}))
+	store.passphrase = "another passphrase"
+
+	// Act
+	_, err := store.read()
+
+	// Assert
+	require.ErrorIs(t, err, ErrWrongCredentialsPassword)
+}
+
+//nolint:paralleltest // the keyring is mocked for the whole process
+func TestOpenCredentialStore_MovesThePlaintextCredentialsToTheKeyring(t *testing.T) {
+	// Arrange
+	keyring.MockInit()
+	credentialsDir := t.TempDir()
+	plaintextPath := filepath.Join(credentialsDir, plaintextCredentialsFileName)
+	require.NoError(t, os.WriteFile(plaintextPath,
+		[]byte(`{"gitlab": {"host": "gitlab.company.io", "access_token": "gitlab-token"}}`), 0o600))
+
+	// Act
+	store, err := openCredentialStore(credentialsDir)
+
+	// Assert
+	require.NoError(t, err)
+	assert.IsType(t, keyringStore{}, store)
+	assert.NoFileExists(t, plaintextPath)
+	credentials, err := store.read()
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab-token", credentials["gitlab@gitlab.company.io"].AccessToken)
+}
+
+//nolint:paralleltest // the keyring is mocked for the whole process
+func TestOpenCredentialStore_WithoutKeyring(t *testing.T) {
+	// Arrange
+	keyring.MockInitWithError(errors.New("no secret service"))
+	credentialsDir := t.TempDir()
+
+	// Act
+	_, missingErr := openCredentialStore(credentialsDir)
+	t.Setenv(credentialsPassphraseEnv, "test-credentials-passphrase")
+	store, err := openCredentialStore(credentialsDir)
+
+	// Assert
+	require.ErrorIs(t, missingErr, ErrNoCredentialStore)
+	require.NoError(t, err)
+	assert.Equal(t, encryptedFileStore{
+		path:       filepath.Join(credentialsDir, credentialsFileName),
+		passphrase: "test-credentials-passphrase",
+	}, store)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultDedupPrecedence is the precedence of the sections keeping the entries duplicated across sections,
+// the sections not listed come after them
+var defaultDedupPrecedence = []string{"Added", "Changed", "Fixed"}
+
+var (
+	// inlineLinkRegex matches the inline links and images (e.g. "[text](url "title")"), capturing the text and the URL
+	inlineLinkRegex = regexp.MustCompile(`!?\[([^\]]*)\]\(\s*<?([^)\s>]*)>?(?:\s+"[^"]*")?\s*\)`)
+	// referenceLinkRegex matches the reference-style links (e.g. "[text][label]"), capturing the text and the label
+	referenceLinkRegex = regexp.MustCompile(`\[([^\]]+)\]\[([^\]]*)\]`)
+	// autoLinkRegex matches the autolinks (e.g. "<https://example.com>"), capturing the URL
+	autoLinkRegex = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	// issueReferenceRegex matches the references to the issues and pull requests (e.g. "#214" or "!12")
+	issueReferenceRegex = regexp.MustCompile(`(?:^|[^\w&])[#!]\d+\b`)
+)
+
+// dedupRemoval is an entry removed because it is duplicated in another section
+type dedupRemoval struct {
+	Entry       string
+	Section     string
+	KeptSection string
+}
+
+func (r dedupRemoval) String() string {
+	return fmt.Sprintf("removed %q from %q, duplicated in %q", r.Entry, r.Section, r.KeptSection)
+}
+
+// dedupEntry is an entry of the "Unreleased" section, along with its section and its comparison key
+type dedupEntry struct {
+	index      int
+	section    string
+	key        string
+	references int
+}
+
+// getDedupRank returns the precedence of the section, the lower the rank the higher the precedence
+func getDedupRank(precedence []string, section string) int {
+	if rank := slices.Index(precedence, section); rank != -1 {
+		return rank
+	}
+	return len(precedence)
+}
+
+// getDedupEntryText returns the text of a top-level entry (e.g. "- text"), and whether the line is one.
+// The lines are only checked by their first characters, since most of them are entries.
+func getDedupEntryText(line string) (string, bool) {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 2 || !strings.ContainsRune("-*+", rune(line[0])) || !strings.ContainsRune(" \t\n\f\r", rune(line[1])) {
+		return "", false
+	}
+	return line[2:], true
+}
+
+// getDedupKey returns the text of the entry compared to find the duplicates: without the case,
+// the repeated spaces and the final period. It is built in a single pass, since every entry has one.
+func getDedupKey(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+	pendingSpace := false
+	for _, char := range text {
+		if unicode.IsSpace(char) {
+			pendingSpace = builder.Len() > 0
+			continue
+		}
+		if pendingSpace {
+			builder.WriteByte(' ')
+			pendingSpace = false
+		}
+		builder.WriteRune(unicode.ToLower(char))
+	}
+	return strings.TrimSuffix(builder.String(), ".")
+}
+
+// stripMarkdown returns the text of the entry without the Markdown syntax: the links are reduced to their text
+// and the emphasis and code markers are removed, so the linked and plain phrasings of a change are compared equal
+func stripMarkdown(text string) string {
+	if !strings.ContainsAny(text, "[<*`") {
+		return text
+	}
+	text = inlineLinkRegex.ReplaceAllString(text, "$1")
+	text = referenceLinkRegex.ReplaceAllString(text, "$1")
+	text = autoLinkRegex.ReplaceAllString(text, "$1")
+	return strings.NewReplacer("**", "", "*", "", "`", "").Replace(text)
+}
+
+// getLinkTargets returns the sorted targets of the links of the entry (the URLs, or the labels of the
+// reference-style links), which tell apart the entries whose text is the same
+func getLinkTargets(text string) []string {
+	var targets []string
+	for _, match := range inlineLinkRegex.FindAllStringSubmatch(text, -1) {
+		targets = append(targets, match[2])
+	}
+	for _, match := range referenceLinkRegex.FindAllStringSubmatch(text, -1) {
+		label := match[2]
+		if label == "" {
+			// collapsed reference (e.g. "[text][]"), the text is the label
+			label = match[1]
+		}
+		targets = append(targets, "["+strings.ToLower(label)+"]")
+	}
+	for _, match := range autoLinkRegex.FindAllStringSubmatch(text, -1) {
+		targets = append(targets, match[1])
+	}
+	sort.Strings(targets)
+	return slices.Compact(targets)
+}
+
+// countEntryReferences counts the links and the references to issues of the entry, the richer duplicate
+// being kept
+func countEntryReferences(text string) int {
+	return len(inlineLinkRegex.FindAllStringIndex(text, -1)) +
+		len(referenceLinkRegex.FindAllStringIndex(text, -1)) +
+		len(autoLinkRegex.FindAllStringIndex(text, -1)) +
+		len(issueReferenceRegex.FindAllStringIndex(text, -1))
+}
+
+// sortEntries sorts the entries alphabetically by their text without the Markdown syntax, so the URLs of the links
+// don't change their order, byte-wise (so it doesn't depend on the locale) and breaking the ties by the whole entry
+func sortEntries(entries []string) {
+	keys := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		keys[entry] = stripMarkdown(entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if keys[entries[i]] != keys[entries[j]] {
+			return keys[entries[i]] < keys[entries[j]]
+		}
+		return entries[i] < entries[j]
+	})
+}
+
+// deduplicateEntries removes the duplicated entries of the "Unreleased" section (e.g. pasted twice).
+// The duplicates in the same section are removed, keeping the first one. The duplicates in different sections
+// are kept, unless the cross-section deduplication is enabled: they are then kept in the section of the highest
+// precedence, so the bump isn't downgraded (e.g. an entry under both "Added" and "Changed" is kept as a minor change).
+// The entries are compared without their Markdown syntax, so a linked phrasing and a plain one are duplicates,
+// while the entries linking different targets are kept. Among the duplicates of the same section, the one carrying
+// the most links and references is kept.
+// The removals across sections are returned, since they may change the bump.
+func deduplicateEntries(unreleasedSection []string, changelogConfig ChangelogConfig) ([]string, []dedupRemoval) {
+	precedence := changelogConfig.DedupPrecedence
+	if len(precedence) == 0 {
+		precedence = defaultDedupPrecedence
+	}
+
+	keys := make([]string, 0, len(unreleasedSection))
+	groups := make(map[string][]dedupEntry, len(unreleasedSection))
+	linkedGroups := make(map[string]string)
+	section := ""
+	insideCodeBlock := false
+	for index, line := range unreleasedSection {
+		if isCodeFence(line) {
+			insideCodeBlock = !insideCodeBlock
+		}
+		if insideCodeBlock {
+			continue
+		}
+		if heading, found := strings.CutPrefix(line, "### "); found {
+			section = strings.TrimSpace(heading)
+			continue
+		}
+
+		text, isEntry := getDedupEntryText(line)
+		if !isEntry {
+			continue
+		}
+		key := getDedupKey(stripMarkdown(text))
+		if !changelogConfig.CrossSectionDedup {
+			// the entries are only duplicates of the ones of their section
+			key = section + "\x00" + key
+		}
+		groupKey := key
+		if targets := getLinkTargets(text); len(targets) > 0 {
+			groupKey = key + "\x00" + strings.Join(targets, "\x00")
+			if _, exists := linkedGroups[key]; !exists {
+				linkedGroups[key] = groupKey
+			}
+		}
+		if _, exists := groups[groupKey]; !exists {
+			keys = append(keys, groupKey)
+		}
+		groups[groupKey] = append(groups[groupKey], dedupEntry{
+			index: index, section: section, key: key, references: countEntryReferences(text),
+		})
+	}
+
+	// the plain entries are duplicates of the first linked entry with the same text
+	for key, groupKey := range linkedGroups {
+		if plain, exists := groups[key]; exists {
+			groups[groupKey] = append(groups[groupKey], plain...)
+			slices.SortFunc(groups[groupKey], func(a, b dedupEntry) int { return a.index - b.index })
+			delete(groups, key)
+		}
+	}
+
+	removed := make(map[int]bool)
+	var removals []dedupRemoval
+	for _, key := range keys {
+		entries := groups[key]
+		if len(entries) <= 1 {
+			continue
+		}
+
+		// the first entry of the section with the highest precedence is kept, preferring the most references
+		kept := entries[0]
+		for _, entry := range entries[1:] {
+			rank, keptRank := getDedupRank(precedence, entry.section), getDedupRank(precedence, kept.section)
+			if rank < keptRank || rank == keptRank && entry.references > kept.references {
+				kept = entry
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.index == kept.index {
+				continue
+			}
+
+			text := strings.TrimSpace(unreleasedSection[entry.index])
+			if entry.section == kept.section {
+				changelogConfig.logger().Infof("Entry %q removed, duplicated in the section %q", text, entry.section)
+			} else {
+				removal := dedupRemoval{Entry: text, Section: entry.section, KeptSection: kept.section}
+				changelogConfig.logger().Warnf("Entry %s (which may lower the bump)", removal)
+				removals = append(removals, removal)
+			}
+			removed[entry.index] = true
+		}
+	}
+
+	if len(removed) == 0 {
+		return unreleasedSection, nil
+	}
+	// the migration notes are removed along with their entries
+	for index, owner := range findMigrationNotes(unreleasedSection) {
+		if removed[owner] {
+			removed[index] = true
+		}
+	}
+	deduplicated := make([]string, 0, len(unreleasedSection)-len(removed))
+	for index, line := range unreleasedSection {
+		if !removed[index] {
+			deduplicated = append(deduplicated, line)
+		}
+	}
+	return deduplicated, removals
+}
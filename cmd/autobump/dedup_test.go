@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crossSectionChangelog has the same entry under "Added" and "Changed"
+const crossSectionChangelog = `# Changelog
+
+## [Unreleased]
+
+### Added
+
+- added the export of the reports to CSV
+
+### Changed
+
+- Added the export of the reports to CSV.
+
+## [1.2.0] - 2024-01-01
+
+### Added
+
+- added the reports
+`
+
+func TestDeduplicateEntries_RemovesDuplicatesOfSameSection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Fixed", "", "- fixed the login", "- fixed the logout", "-  Fixed the login.", "",
+	}
+
+	// Act
+	deduplicated, removals := deduplicateEntries(unreleasedSection, ChangelogConfig{})
+
+	// Assert
+	assert.Equal(t, []string{"### Fixed", "", "- fixed the login", "- fixed the logout", ""}, deduplicated)
+	assert.Empty(t, removals, "the removals of the same section don't change the bump")
+}
+
+func TestDeduplicateEntries_KeepsEntriesInsideCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{"### Added", "- added the flag", "```", "- added the flag", "```"}
+
+	// Act
+	deduplicated, _ := deduplicateEntries(unreleasedSection, ChangelogConfig{})
+
+	// Assert
+	assert.Equal(t, unreleasedSection, deduplicated)
+}
+
+func TestProcessChangelog_CrossSectionDuplicateKeptInAdded(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(crossSectionChangelog, "\n")
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, ChangelogConfig{CrossSectionDedup: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", versionString(version), "the duplicate must not downgrade the bump")
+	content := strings.Join(newChangelog, "\n")
+	assert.Contains(t, content, "### Added\n\n- added the export of the reports to CSV")
+	assert.NotContains(t, content, "### Changed")
+}
+
+func TestProcessChangelog_CrossSectionDuplicateWithConfiguredPrecedence(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(crossSectionChangelog, "\n")
+	changelogConfig := ChangelogConfig{CrossSectionDedup: true, DedupPrecedence: []string{"Changed", "Added"}}
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.1", versionString(version))
+	content := strings.Join(newChangelog, "\n")
+	assert.Contains(t, content, "### Changed\n\n- Added the export of the reports to CSV.")
+	assert.NotContains(t, content, "- added the export of the reports to CSV")
+}
+
+func TestProcessChangelog_CrossSectionDuplicatesKeptByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(crossSectionChangelog, "\n")
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", versionString(version))
+	content := strings.Join(newChangelog, "\n")
+	assert.Contains(t, content, "- added the export of the reports to CSV")
+	assert.Contains(t, content, "- Added the export of the reports to CSV.")
+}
+
+func TestDeduplicateEntries_KeepsDuplicatesOfOtherSectionsByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Added", "- added the export", "- Added the export.", "### Changed", "- added the [export](#12)",
+		"- added the export",
+	}
+
+	// Act
+	deduplicated, removals := deduplicateEntries(unreleasedSection, ChangelogConfig{})
+
+	// Assert
+	assert.Equal(t, []string{"### Added", "- added the export", "### Changed", "- added the [export](#12)"}, deduplicated)
+	assert.Empty(t, removals)
+}
+
+func TestDeduplicateEntries_RecordsCrossSectionRemovals(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Added", "- added the export", "### Changed", "- added the export", "### Fixed", "- Added the export.",
+	}
+
+	// Act
+	deduplicated, removals := deduplicateEntries(unreleasedSection, ChangelogConfig{CrossSectionDedup: true})
+
+	// Assert
+	assert.Equal(t, []string{"### Added", "- added the export", "### Changed", "### Fixed"}, deduplicated)
+	require.Len(t, removals, 2)
+	assert.Equal(t, `removed "- added the export" from "Changed", duplicated in "Added"`, removals[0].String())
+	assert.Equal(t, `removed "- Added the export." from "Fixed", duplicated in "Added"`, removals[1].String())
+}
+
+// readDedupFixture reads the lines of a fixture of the deduplication
+func readDedupFixture(t *testing.T, name string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "dedup", name))
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestDeduplicateEntries_KeepsRecordedSurvivors(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := readDedupFixture(t, "unreleased.md")
+
+	// Act
+	deduplicated, _ := deduplicateEntries(unreleasedSection, ChangelogConfig{CrossSectionDedup: true})
+
+	// Assert
+	assert.Equal(t, readDedupFixture(t, "deduplicated.md"), deduplicated)
+}
+
+func TestDeduplicateEntries_LargeSection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := newDependencyBumpSection(1200)
+	unreleasedSection = append(unreleasedSection, unreleasedSection[2:]...)
+
+	// Act
+	startTime := time.Now()
+	deduplicated, removals := deduplicateEntries(unreleasedSection, ChangelogConfig{})
+	elapsed := time.Since(startTime)
+
+	// Assert
+	assert.Len(t, deduplicated, 1202)
+	assert.Empty(t, removals)
+	assert.Less(t, elapsed, time.Second, "the deduplication must stay linear")
+}
+
+// newDependencyBumpSection generates an "Unreleased" section with many dependency bumps, some of them repeated
+func newDependencyBumpSection(entries int) []string {
+	section := []string{"### Changed", ""}
+	for index := range entries {
+		section = append(section, fmt.Sprintf("- bumped `dependency-%d` from 1.%d.0 to 1.%d.1", index%1000, index, index))
+	}
+	return section
+}
+
+func BenchmarkDeduplicateEntries(b *testing.B) {
+	unreleasedSection := newDependencyBumpSection(1200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		deduplicateEntries(unreleasedSection, ChangelogConfig{})
+	}
+}
+
+func TestDeduplicateEntries_KeepsTheLinkedDuplicate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Fixed",
+		"",
+		"- fixed crash in parser (#214)",
+		"- fixed crash in [parser](docs/parser.md) ([#214](https://github.com/org/repo/issues/214))",
+		"- fixed crash in **parser** (#214).",
+		"",
+	}
+
+	// Act
+	deduplicated, removals := deduplicateEntries(unreleasedSection, ChangelogConfig{})
+
+	// Assert
+	assert.Equal(t, []string{
+		"### Fixed",
+		"",
+		"- fixed crash in [parser](docs/parser.md) ([#214](https://github.com/org/repo/issues/214))",
+		"",
+	}, deduplicated)
+	assert.Empty(t, removals)
+}
+
+func TestDeduplicateEntries_KeepsEntriesLinkingDifferentTargets(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Changed",
+		"- changed the [guide](docs/install.md)",
+		"- changed the [guide](docs/upgrade.md)",
+		"- changed the [guide][upgrade]",
+		"- changed the [guide](docs/install.md).",
+	}
+
+	// Act
+	deduplicated, _ := deduplicateEntries(unreleasedSection, ChangelogConfig{})
+
+	// Assert
+	assert.Equal(t, []string{
+		"### Changed",
+		"- changed the [guide](docs/install.md)",
+		"- changed the [guide](docs/upgrade.md)",
+		"- changed the [guide][upgrade]",
+	}, deduplicated)
+}
+
+func TestSortEntries_IgnoresTheMarkdownSyntax(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	entries := []string{
+		"- fixed the [uploads](https://example.com/a)",
+		"- fixed the `timeouts`",
+		"- fixed the **retries**",
+		"- fixed the [uploads](https://example.com/0)",
+		"- fixed the <https://example.com> link",
+	}
+
+	// Act
+	sortEntries(entries)
+
+	// Assert
+	assert.Equal(t, []string{
+		"- fixed the <https://example.com> link",
+		"- fixed the **retries**",
+		"- fixed the `timeouts`",
+		"- fixed the [uploads](https://example.com/0)",
+		"- fixed the [uploads](https://example.com/a)",
+	}, entries)
+}
+
+func TestSortEntries_IsStable(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	entries := []string{"- b", "- [a](2.md)", "- a", "- [a](1.md)"}
+	reversed := []string{"- [a](1.md)", "- a", "- [a](2.md)", "- b"}
+
+	// Act
+	sortEntries(entries)
+	sortEntries(reversed)
+
+	// Assert
+	assert.Equal(t, []string{"- [a](1.md)", "- [a](2.md)", "- a", "- b"}, entries)
+	assert.Equal(t, entries, reversed)
+}
+
+func TestCountEntryReferences(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	plain := countEntryReferences("fixed crash in parser (#214)")
+	linked := countEntryReferences("fixed crash in [parser](docs/parser.md) ([#214](https://example.com/214))")
+	none := countEntryReferences("fixed the HTML &#214; entity")
+
+	// Assert
+	assert.Equal(t, 1, plain)
+	assert.Equal(t, 3, linked)
+	assert.Equal(t, 0, none)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// strategies of the version written in the version files
+const (
+	versionFileStrategyRelease = "release"  // the released version
+	versionFileStrategyNextDev = "next-dev" // the next development version (e.g. "1.6.0-dev" after releasing 1.5.0)
+)
+
+const (
+	defaultDevSuffix = "-dev"
+	// versionCorePattern is how the version patterns of the version files match the versions
+	versionCorePattern = `\d+\.\d+\.\d+`
+	// preReleasePattern matches the pre-release suffix of a version (e.g. "-dev" or "-rc.1")
+	preReleasePattern = `(?:-[0-9A-Za-z.-]+)?`
+)
+
+var ErrInvalidVersionFileStrategy = errors.New("invalid version_file_strategy")
+
+// validateVersionFileStrategy checks the strategy of the version files and the next development version
+func validateVersionFileStrategy(projectConfig *ProjectConfig) error {
+	switch projectConfig.VersionFileStrategy {
+	case "", versionFileStrategyRelease, versionFileStrategyNextDev:
+	default:
+		return fmt.Errorf(
+			"%w: %s (expected %s or %s)",
+			ErrInvalidVersionFileStrategy, projectConfig.VersionFileStrategy,
+			versionFileStrategyRelease, versionFileStrategyNextDev,
+		)
+	}
+
+	switch projectConfig.NextDevIncrement {
+	case "", "minor", "patch":
+	default:
+		return fmt.Errorf(
+			"%w: next_dev_increment %s (expected minor or patch)",
+			ErrInvalidVersionFileStrategy, projectConfig.NextDevIncrement,
+		)
+	}
+
+	if projectConfig.VersionFileStrategy == versionFileStrategyNextDev {
+		if projectConfig.VersioningScheme == versioningSchemeCalVer || len(projectConfig.VersionStreams) > 0 {
+			return fmt.Errorf(
+				"%w: %s only supports the SemVer projects without version streams",
+				ErrInvalidVersionFileStrategy, versionFileStrategyNextDev,
+			)
+		}
+		if _, err := semver.NewVersion("1.0.0" + getDevSuffix(projectConfig)); err != nil {
+			return fmt.Errorf("%w: dev_suffix %q: %w", ErrInvalidVersionFileStrategy, projectConfig.DevSuffix, err)
+		}
+	}
+	return nil
+}
+
+// getDevSuffix returns the suffix of the development versions
+func getDevSuffix(projectConfig *ProjectConfig) string {
+	if projectConfig.DevSuffix == "" {
+		return defaultDevSuffix
+	}
+	return projectConfig.DevSuffix
+}
+
+// getVersionFilesVersion returns the version written in the version files: the released version,
+// or the next development version after it (the next minor by default) in the "next-dev" strategy
+func getVersionFilesVersion(projectConfig *ProjectConfig) (string, error) {
+	if projectConfig.VersionFileStrategy != versionFileStrategyNextDev {
+		return projectConfig.NewVersion, nil
+	}
+
+	released, err := semver.NewVersion(projectConfig.NewVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the released version %s: %w", projectConfig.NewVersion, err)
+	}
+
+	next := released.IncMinor()
+	if projectConfig.NextDevIncrement == "patch" {
+		next = released.IncPatch()
+	}
+	return versionString(&next) + getDevSuffix(projectConfig), nil
+}
+
+// widenVersionPatterns makes the version patterns match the pre-release suffixes too, so the development
+// version written by the previous release is replaced as a whole (instead of "1.6.0-dev-dev")
+func widenVersionPatterns(versionFiles []VersionFile) []VersionFile {
+	widened := make([]VersionFile, 0, len(versionFiles))
+	for _, versionFile := range versionFiles {
+		patterns := make([]string, 0, len(versionFile.Patterns))
+		for _, pattern := range versionFile.Patterns {
+			patterns = append(patterns, strings.ReplaceAll(pattern, versionCorePattern, versionCorePattern+preReleasePattern))
+		}
+		widened = append(widened, VersionFile{Path: versionFile.Path, Patterns: patterns})
+	}
+	return widened
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersionFilesVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		project  ProjectConfig
+		expected string
+	}{
+		{"release", ProjectConfig{NewVersion: "1.5.0"}, "1.5.0"},
+		{"next minor", ProjectConfig{NewVersion: "1.5.0", VersionFileStrategy: versionFileStrategyNextDev}, "1.6.0-dev"},
+		{"after a major", ProjectConfig{NewVersion: "2.0.0", VersionFileStrategy: versionFileStrategyNextDev}, "2.1.0-dev"},
+		{
+			"after a patch",
+			ProjectConfig{NewVersion: "1.5.3", VersionFileStrategy: versionFileStrategyNextDev},
+			"1.6.0-dev",
+		},
+		{
+			"next patch",
+			ProjectConfig{NewVersion: "1.5.0", VersionFileStrategy: versionFileStrategyNextDev, NextDevIncrement: "patch"},
+			"1.5.1-dev",
+		},
+		{
+			"custom suffix",
+			ProjectConfig{NewVersion: "1.5.0", VersionFileStrategy: versionFileStrategyNextDev, DevSuffix: "-SNAPSHOT"},
+			"1.6.0-SNAPSHOT",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			version, err := getVersionFilesVersion(&test.project)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+func TestValidateVersionFileStrategy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		project ProjectConfig
+		valid   bool
+	}{
+		{"default", ProjectConfig{}, true},
+		{"next-dev", ProjectConfig{VersionFileStrategy: versionFileStrategyNextDev, NextDevIncrement: "patch"}, true},
+		{"unknown strategy", ProjectConfig{VersionFileStrategy: "snapshot"}, false},
+		{"unknown increment", ProjectConfig{VersionFileStrategy: versionFileStrategyNextDev, NextDevIncrement: "major"}, false},
+		{"invalid suffix", ProjectConfig{VersionFileStrategy: versionFileStrategyNextDev, DevSuffix: "-dev!"}, false},
+		{
+			"CalVer",
+			ProjectConfig{VersionFileStrategy: versionFileStrategyNextDev, VersioningScheme: versioningSchemeCalVer},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := validateVersionFileStrategy(&test.project)
+
+			// Assert
+			if test.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidVersionFileStrategy)
+		})
+	}
+}
+
+func TestUpdateChangelogAndVersionFiles_NextDevVersion(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	repo, err := git.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, "CHANGELOG.md", changelogOriginal+"\n")
+	commitFile(t, repo, "version.txt", "version=1.1.0-dev\n")
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+
+	ctx := &RepoContext{
+		globalConfig: &GlobalConfig{
+			LanguagesConfig: map[string]LanguageConfig{
+				"text": {VersionFiles: []VersionFile{{Path: "version.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}}}},
+			},
+			releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+		},
+		projectConfig: &ProjectConfig{
+			Path:                projectPath,
+			Name:                "project",
+			Language:            "text",
+			VersionFileStrategy: versionFileStrategyNextDev,
+		},
+	}
+	require.NoError(t, setupRepo(ctx))
+	branchName, err := createBumpBranch(ctx, changelogPath)
+	require.NoError(t, err)
+
+	// Act
+	err = updateChangelogAndVersionFiles(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "chore/bump-1.1.0", branchName, "the branch names the released version")
+	changelog, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(changelog), "## [1.1.0] - 2024-06-08")
+	versionFile, err := os.ReadFile(filepath.Join(projectPath, "version.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "version=1.2.0-dev\n", string(versionFile), "the development suffix must not be repeated")
+	assert.Equal(t, "1.1.0", ctx.projectConfig.NewVersion)
+	assert.Equal(t, "1.2.0-dev", ctx.state.versionFilesVersion)
+}
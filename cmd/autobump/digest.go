@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+const digestWebhookTimeout = 10 * time.Second
+
+var ErrFailedToPostDigest = errors.New("failed to post the digest")
+
+// digestSectionsOrder is the order used to pick the top change of a release, after the breaking changes
+var digestSectionsOrder = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// ProjectResult holds the release prepared for a project in a batch run
+type ProjectResult struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Forge           string `json:"forge"`
+	Organization    string `json:"organization"`
+	PreviousVersion string `json:"previous_version"`
+	NextVersion     string `json:"next_version"`
+	Bump            string `json:"bump"`
+	TopChange       string `json:"top_change"`
+	MoreChanges     int    `json:"more_changes"`
+	PullRequestURL  string `json:"pull_request_url,omitempty"`
+	Status          string `json:"status,omitempty"`
+	CompareURL      string `json:"compare_url,omitempty"`
+	// "bumped", "skipped" or "failed", the status telling why
+	Outcome string `json:"outcome,omitempty"`
+	// bump branch of the project, the one it would push when it was only previewed
+	Branch string `json:"branch,omitempty"`
+	// error stopping the project, its credentials being redacted
+	Error string `json:"error,omitempty"`
+	// pull request propagating the version to the umbrella repository
+	PropagationURL string `json:"propagation_url,omitempty"`
+	// version written in the version files, when it isn't the released one (e.g. "1.6.0-dev")
+	VersionFilesVersion string `json:"version_files_version,omitempty"`
+	// version files matched by a glob but skipped, since the repository ignores them
+	IgnoredVersionFiles []string `json:"ignored_version_files,omitempty"`
+	// versions moved from the CHANGELOG into its archive
+	ArchivedReleases []string `json:"archived_releases,omitempty"`
+	// approvals required by the pull request and present after its creation, when the provider reports them
+	ApprovalsRequired *int `json:"approvals_required,omitempty"`
+	Approvals         *int `json:"approvals,omitempty"`
+	// how the pull request was set to be merged, with the preconditions not met yet
+	AutoMerge *AutoMergeResult `json:"auto_merge,omitempty"`
+	// whether the pull request conflicts with its target branch, when it was checked
+	Mergeability *MergeabilityResult `json:"mergeability,omitempty"`
+	// features requested for the project but skipped, since its remote service can't do them
+	SkippedFeatures []string `json:"skipped_features,omitempty"`
+	// pull request the project would create, when it was only previewed
+	PullRequestPreview *PullRequestPreview `json:"pr_preview,omitempty"`
+	// time spent in each phase of the processing
+	Timings []PhaseTiming `json:"timings,omitempty"`
+}
+
+// DigestPayload is the body posted to the digest webhook, "text" is the field read by Slack incoming webhooks
+type DigestPayload struct {
+	Text     string          `json:"text"`
+	Projects []ProjectResult `json:"projects"`
+}
+
+// releaseDigest aggregates the releases prepared in a batch run. It is safe to be shared by concurrent workers.
+type releaseDigest struct {
+	mutex   sync.Mutex
+	results []ProjectResult
+}
+
+// newReleaseDigest creates a digest when it is written to a file or posted to a webhook (or when the run report
+// is written or printed), returning nil otherwise
+func newReleaseDigest(globalConfig *GlobalConfig) *releaseDigest {
+	if globalConfig.DigestOut == "" && globalConfig.DigestWebhook == "" && globalConfig.ReportOut == "" &&
+		!isOutputFormat(outputJSON) {
+		return nil
+	}
+	return &releaseDigest{}
+}
+
+// add records the release prepared for a project
+func (d *releaseDigest) add(result ProjectResult) {
+	if d == nil {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	result.Outcome = getProjectOutcome(result)
+	d.results = append(d.results, result)
+}
+
+// addFailure records the error stopping a project, on its recorded release when it failed after recording it
+func (d *releaseDigest) addFailure(failure ProjectResult) {
+	if d == nil {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for index := range d.results {
+		if d.results[index].ID == failure.ID && d.results[index].Name == failure.Name {
+			d.results[index].Error = failure.Error
+			d.results[index].Outcome = getProjectOutcome(d.results[index])
+			return
+		}
+	}
+	failure.Outcome = getProjectOutcome(failure)
+	d.results = append(d.results, failure)
+}
+
+// getProjectOutcome tells whether the project was bumped, skipped (e.g. its content was already released)
+// or failed. The projects skipped because of their path are also skipped, even though they carry an error.
+func getProjectOutcome(result ProjectResult) string {
+	switch result.Status {
+	case projectStatusAlreadyReleased, projectStatusSkippedPrechecked, projectStatusVersionVetoed,
+		projectStatusNotAGitRepository, projectStatusBareRepository, projectStatusDryRun:
+		return projectOutcomeSkipped
+	}
+	if result.Error != "" {
+		return projectOutcomeFailed
+	}
+	return projectOutcomeBumped
+}
+
+// updateResult changes the recorded release of a project, once it was processed further (e.g. propagated)
+func (d *releaseDigest) updateResult(id string, name string, update func(result *ProjectResult)) {
+	if d == nil {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for index := range d.results {
+		if d.results[index].ID == id && d.results[index].Name == name {
+			update(&d.results[index])
+		}
+	}
+}
+
+// getResults returns the recorded releases sorted by forge, organization and project name
+func (d *releaseDigest) getResults() []ProjectResult {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	results := append([]ProjectResult{}, d.results...)
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Forge != results[j].Forge {
+			return results[i].Forge < results[j].Forge
+		}
+		if results[i].Organization != results[j].Organization {
+			return results[i].Organization < results[j].Organization
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// renderDigest renders the releases as a Markdown document grouped by forge and organization
+func renderDigest(results []ProjectResult) string {
+	var builder strings.Builder
+	builder.WriteString("# Release digest\n")
+	if len(results) == 0 {
+		builder.WriteString("\nNo projects were bumped.\n")
+		return builder.String()
+	}
+
+	group := ""
+	for index, result := range results {
+		resultGroup := strings.Trim(result.Forge+"/"+result.Organization, "/")
+		if resultGroup == "" {
+			resultGroup = "unknown"
+		}
+		if index == 0 || resultGroup != group {
+			group = resultGroup
+			builder.WriteString(fmt.Sprintf("\n## %s\n\n", group))
+		}
+
+		name := result.Name
+		switch {
+		case result.PullRequestURL != "":
+			name = fmt.Sprintf("[%s](%s)", result.Name, result.PullRequestURL)
+		case result.CompareURL != "":
+			name = fmt.Sprintf("[%s](%s)", result.Name, result.CompareURL)
+		}
+		switch result.Status {
+		case pullRequestStatusPushedNoPR:
+			name += " (pushed, no PR)"
+		case projectStatusDryRun:
+			name += " (dry-run)"
+		case projectStatusPropagationFailed:
+			name += " (propagation failed)"
+		}
+		if result.Mergeability != nil && result.Mergeability.Status == mergeabilityConflicted {
+			name += " (conflicted)"
+		}
+		if result.Status == projectStatusAlreadyReleased {
+			builder.WriteString(fmt.Sprintf("- %s %s (already released content)\n", name, result.PreviousVersion))
+			continue
+		}
+		// the projects failing before their release was prepared have no version
+		if result.NextVersion == "" {
+			builder.WriteString(fmt.Sprintf("- %s (%s)\n", name, firstNonEmpty(result.Status, result.Outcome)))
+			continue
+		}
+		line := fmt.Sprintf("- %s %s → %s (%s)", name, result.PreviousVersion, result.NextVersion, result.Bump)
+		if result.TopChange != "" {
+			line += ": " + result.TopChange
+		}
+		if result.MoreChanges > 0 {
+			line += fmt.Sprintf(" (+%d more)", result.MoreChanges)
+		}
+		if missing := getResultMissingApprovals(result); missing > 0 {
+			line += fmt.Sprintf(" (needs %d more approvals)", missing)
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	renderPullRequestPreviews(&builder, results)
+
+	if percentiles := getPhasePercentiles(results); len(percentiles) > 0 {
+		builder.WriteString("\n## Timings\n\n")
+		for _, phase := range percentiles {
+			projects := fmt.Sprintf("%d projects", phase.projects)
+			if phase.projects == 1 {
+				projects = "1 project"
+			}
+			builder.WriteString(fmt.Sprintf(
+				"- %s: p50 %s, p95 %s (%s)\n",
+				phase.phase, formatPhaseDuration(phase.p50), formatPhaseDuration(phase.p95), projects,
+			))
+		}
+	}
+	return builder.String()
+}
+
+// getResultMissingApprovals returns how many approvals the pull request of the project still needs
+func getResultMissingApprovals(result ProjectResult) int {
+	if result.ApprovalsRequired == nil || result.Approvals == nil {
+		return 0
+	}
+	approvals := &PullRequestApprovals{Required: *result.ApprovalsRequired, Given: *result.Approvals}
+	return approvals.getMissingApprovals()
+}
+
+// renderPullRequestPreviews renders the pull requests of the previewed projects, grouped after the releases
+func renderPullRequestPreviews(builder *strings.Builder, results []ProjectResult) {
+	header := false
+	for _, result := range results {
+		if result.PullRequestPreview == nil {
+			continue
+		}
+		if !header {
+			builder.WriteString("\n## Pull request previews\n")
+			header = true
+		}
+		builder.WriteString(fmt.Sprintf("\n### %s\n\n", result.Name))
+		builder.WriteString(renderPullRequestPreview(result.PullRequestPreview))
+	}
+}
+
+// selectTopChange picks the change summarizing a release: the first breaking change,
+// then the first added entry, falling back to the first entry. It also returns the amount of other entries.
+func selectTopChange(sectionEntries map[string][]string) (string, int) {
+	var entries []string
+	for _, header := range digestSectionsOrder {
+		entries = append(entries, sectionEntries[header]...)
+	}
+	if len(entries) == 0 {
+		return "", 0
+	}
+
+	topChange := entries[0]
+	for _, entry := range entries {
+		if strings.Contains(entry, "**BREAKING CHANGE:**") {
+			topChange = entry
+			break
+		}
+	}
+	topChange = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(getEntryHeadline(topChange)), "-*+"))
+	return topChange, len(entries) - 1
+}
+
+// getBumpKind returns which part of the version was bumped
+func getBumpKind(previousVersion *semver.Version, nextVersion *semver.Version, versioningScheme string) string {
+	switch {
+	case versioningScheme == versioningSchemeCalVer:
+		return versioningSchemeCalVer
+	case nextVersion.Major() != previousVersion.Major():
+		return "major"
+	case nextVersion.Minor() != previousVersion.Minor():
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
+// recordProjectResult adds the release prepared for the project to the digest
+func recordProjectResult(ctx *RepoContext) {
+	digest := ctx.globalConfig.releaseDigest
+	if digest == nil || ctx.unreleased.LatestVersion == nil {
+		return
+	}
+
+	result := ProjectResult{
+		ID:                  ctx.projectConfig.id,
+		Name:                ctx.projectConfig.Name,
+		PreviousVersion:     versionString(ctx.unreleased.LatestVersion),
+		PullRequestURL:      ctx.pullRequestURL,
+		Status:              ctx.status,
+		CompareURL:          ctx.compareURL,
+		Branch:              ctx.branchName,
+		PullRequestPreview:  ctx.pullRequestPreview,
+		IgnoredVersionFiles: ctx.state.ignoredVersionFiles,
+		ArchivedReleases:    ctx.state.archivedReleases,
+		AutoMerge:           ctx.state.autoMerge,
+		Mergeability:        ctx.state.mergeability,
+		SkippedFeatures:     ctx.state.skippedFeatures,
+		Timings:             ctx.timer.getTimings(),
+	}
+	if version := ctx.state.versionFilesVersion; version != ctx.projectConfig.NewVersion {
+		result.VersionFilesVersion = version
+	}
+	if approvals := ctx.state.pullRequestApprovals; approvals != nil {
+		result.ApprovalsRequired = &approvals.Required
+		result.Approvals = &approvals.Given
+	}
+
+	// the projects whose content was already released have no next version
+	if ctx.status != projectStatusAlreadyReleased {
+		nextVersion, err := semver.NewVersion(ctx.projectConfig.NewVersion)
+		if err != nil {
+			ctx.logger().Warnf("Unable to add project %s to the digest: %v", ctx.projectConfig.Name, err)
+			return
+		}
+		result.NextVersion = versionString(nextVersion)
+		result.Bump = getBumpKind(ctx.unreleased.LatestVersion, nextVersion, ctx.projectConfig.VersioningScheme)
+		result.TopChange, result.MoreChanges = selectTopChange(ctx.unreleased.SectionEntries)
+	}
+
+	if remoteURL, remoteErr := getRemoteRepoURL(ctx.repo); remoteErr == nil {
+		result.Organization = getRepositoryOrganization(remoteURL)
+		if webURL, parseErr := url.Parse(getRepositoryWebURL(remoteURL)); parseErr == nil {
+			result.Forge = webURL.Host
+		}
+	}
+
+	digest.add(result)
+}
+
+// recordProjectFailure adds the error stopping the project to the digest, so the report lists every project
+func recordProjectFailure(globalConfig *GlobalConfig, projectConfig *ProjectConfig, err error) {
+	digest := globalConfig.releaseDigest
+	if digest == nil || err == nil {
+		return
+	}
+
+	if projectConfig.id == "" {
+		projectConfig.id = getRepositoryIdentity(projectConfig.Path)
+	}
+	digest.addFailure(ProjectResult{
+		ID:     projectConfig.id,
+		Name:   projectConfig.Name,
+		Status: getRepositoryPathStatus(err),
+		Error:  redactSecrets(redactURLCredentials(err.Error())),
+	})
+}
+
+// publishDigest writes the digest to the configured file and posts it to the configured webhook
+func publishDigest(globalConfig *GlobalConfig) {
+	digest := globalConfig.releaseDigest
+	if digest == nil {
+		return
+	}
+
+	results := digest.getResults()
+	markdown := renderDigest(results)
+
+	if globalConfig.DigestOut != "" {
+		err := os.WriteFile(globalConfig.DigestOut, []byte(markdown), 0o644) //nolint:gosec // the digest is not sensitive
+		if err != nil {
+			log.Errorf("Failed to write the digest: %v", err)
+		} else {
+			log.Infof("Digest written to %s", globalConfig.DigestOut)
+		}
+	}
+
+	if globalConfig.DigestWebhook != "" {
+		// the webhook isn't a provider, so it gets neither the API headers nor the tokens of the providers
+		client := &http.Client{Timeout: digestWebhookTimeout}
+		err := postDigest(client, globalConfig.DigestWebhook, DigestPayload{
+			Text:     markdown,
+			Projects: results,
+		})
+		if err != nil {
+			log.Errorf("Failed to post the digest: %v", err)
+		}
+	}
+}
+
+// postDigest sends the digest as JSON to the webhook
+func postDigest(client *http.Client, webhookURL string, payload DigestPayload) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), digestWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToPostDigest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: %d - %s", ErrFailedToPostDigest, resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDigest_GroupedByForgeAndOrganization(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	digest := &releaseDigest{}
+	digest.add(ProjectResult{
+		Name: "web", Forge: "github.com", Organization: "acme",
+		PreviousVersion: "2.0.0", NextVersion: "2.0.1", Bump: "patch", TopChange: "Fixed the login.",
+	})
+	digest.add(ProjectResult{
+		Name: "payments-api", Forge: "gitlab.com", Organization: "finance",
+		PreviousVersion: "1.4.2", NextVersion: "1.5.0", Bump: "minor",
+		TopChange: "Added SEPA payout support.", MoreChanges: 3,
+		PullRequestURL: "https://gitlab.com/finance/payments-api/-/merge_requests/7",
+	})
+	digest.add(ProjectResult{
+		Name: "api", Forge: "github.com", Organization: "acme",
+		PreviousVersion: "0.1.0", NextVersion: "1.0.0", Bump: "major",
+	})
+
+	// Act
+	markdown := renderDigest(digest.getResults())
+
+	// Assert
+	assert.Equal(t, `# Release digest
+
+## github.com/acme
+
+- api 0.1.0 → 1.0.0 (major)
+- web 2.0.0 → 2.0.1 (patch): Fixed the login.
+
+## gitlab.com/finance
+
+- [payments-api](https://gitlab.com/finance/payments-api/-/merge_requests/7) 1.4.2 → 1.5.0 (minor): Added SEPA payout support. (+3 more)
+`, markdown)
+}
+
+func TestSelectTopChange(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cases := []struct {
+		name     string
+		entries  map[string][]string
+		expected string
+		more     int
+	}{
+		{
+			name: "breaking change first",
+			entries: map[string][]string{
+				"Added": {"- Added the export."},
+				"Fixed": {"- Fixed the import.", "- **BREAKING CHANGE:** removed the v1 API."},
+			},
+			expected: "**BREAKING CHANGE:** removed the v1 API.",
+			more:     2,
+		},
+		{
+			name: "added entries before the other sections",
+			entries: map[string][]string{
+				"Fixed": {"- Fixed the import."},
+				"Added": {"- Added the export."},
+			},
+			expected: "Added the export.",
+			more:     1,
+		},
+		{
+			name:     "first entry as fallback",
+			entries:  map[string][]string{"Security": {"- Bumped the dependencies."}},
+			expected: "Bumped the dependencies.",
+		},
+		{
+			name:    "no entries",
+			entries: map[string][]string{},
+		},
+	}
+
+	for _, testCase := range cases {
+		// Act
+		topChange, more := selectTopChange(testCase.entries)
+
+		// Assert
+		assert.Equal(t, testCase.expected, topChange, testCase.name)
+		assert.Equal(t, testCase.more, more, testCase.name)
+	}
+}
+
+func TestGetBumpKind(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	previousVersion := semver.MustParse("1.4.2")
+
+	// Act & Assert
+	assert.Equal(t, "major", getBumpKind(previousVersion, semver.MustParse("2.0.0"), ""))
+	assert.Equal(t, "minor", getBumpKind(previousVersion, semver.MustParse("1.5.0"), ""))
+	assert.Equal(t, "patch", getBumpKind(previousVersion, semver.MustParse("1.4.3"), versioningSchemeSemVer))
+	assert.Equal(t, "calver", getBumpKind(previousVersion, semver.MustParse("2024.06.0"), versioningSchemeCalVer))
+}
+
+func TestPostDigest_PayloadShape(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var received map[string]any
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	results := []ProjectResult{{
+		ID: "gitlab.com/finance/payments-api", Name: "payments-api", Forge: "gitlab.com", Organization: "finance",
+		PreviousVersion: "1.4.2", NextVersion: "1.5.0", Bump: "minor", TopChange: "Added SEPA payout support.",
+	}}
+
+	// Act
+	err := postDigest(server.Client(), server.URL, DigestPayload{Text: renderDigest(results), Projects: results})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Contains(t, received["text"], "payments-api 1.4.2 → 1.5.0 (minor)")
+	projects, ok := received["projects"].([]any)
+	require.True(t, ok)
+	require.Len(t, projects, 1)
+	assert.Equal(t, map[string]any{
+		"id":               "gitlab.com/finance/payments-api",
+		"name":             "payments-api",
+		"forge":            "gitlab.com",
+		"organization":     "finance",
+		"previous_version": "1.4.2",
+		"next_version":     "1.5.0",
+		"bump":             "minor",
+		"top_change":       "Added SEPA payout support.",
+		"more_changes":     float64(0),
+	}, projects[0])
+}
+
+func TestPostDigest_ErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	// Act
+	err := postDigest(server.Client(), server.URL, DigestPayload{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrFailedToPostDigest)
+}
+
+func TestRenderDigest_PushedWithoutPullRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	results := []ProjectResult{{
+		Name: "payments-api", Forge: "gitlab.com", Organization: "finance",
+		PreviousVersion: "1.4.2", NextVersion: "1.4.3", Bump: "patch",
+		Status:     pullRequestStatusPushedNoPR,
+		CompareURL: "https://gitlab.com/finance/payments-api/-/merge_requests/new",
+	}}
+
+	// Act
+	markdown := renderDigest(results)
+
+	// Assert
+	assert.Contains(t, markdown,
+		"- [payments-api](https://gitlab.com/finance/payments-api/-/merge_requests/new) (pushed, no PR) "+
+			"1.4.2 → 1.4.3 (patch)\n",
+	)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const mixFileName = "mix.exs"
+
+var (
+	// mixProjectRegex matches the start of the project definition of mix.exs ("def project do" or "def project, do:")
+	mixProjectRegex = regexp.MustCompile(`\bdef\s+project\b`)
+	// mixNextFunctionRegex matches the start of the function after the project definition
+	mixNextFunctionRegex = regexp.MustCompile(`(?m)^\s*defp?\s`)
+	// mixAppRegex captures the application name from the "app:" atom
+	mixAppRegex = regexp.MustCompile(`\bapp:\s*:(\w+)`)
+	// mixVersionRegex captures the value of the "version:" key
+	mixVersionRegex = regexp.MustCompile(`\bversion:\s*([^,\n]+)`)
+	// mixVersionAttributeRegex captures the value of the "@version" module attribute definition
+	mixVersionAttributeRegex = regexp.MustCompile(`(?m)^\s*@version\s+([^\n]+)`)
+	// mixReadFileRegex captures the path of the file read with File.read!/1 (e.g. "VERSION")
+	mixReadFileRegex = regexp.MustCompile(`File\.read!\(\s*"([^"]+)"\s*\)`)
+)
+
+const (
+	// mixProjectVersionPattern updates the literal version of the project definition, never the dependencies
+	mixProjectVersionPattern = `(?s)(\bdef\s+project\b.*?\bversion:\s*")\d+\.\d+\.\d+(")`
+	// mixAttributeVersionPattern updates the literal version of the "@version" module attribute
+	mixAttributeVersionPattern = `(?m)(^\s*@version\s+")\d+\.\d+\.\d+(")`
+	// plainVersionPattern updates a file only containing the version (e.g. VERSION)
+	plainVersionPattern = `(?m)(^\s*)\d+\.\d+\.\d+(\s*$)`
+)
+
+var (
+	ErrMixProjectNotFound = errors.New("project definition not found in mix.exs")
+	ErrMixVersionNotFound = errors.New("version not found in the project definition of mix.exs")
+)
+
+type Elixir struct {
+	ProjectConfig ProjectConfig
+}
+
+func (e Elixir) GetProjectName() (string, error) {
+	project, err := readMixProject(e.ProjectConfig.Path)
+	if err != nil {
+		return "", err
+	}
+
+	match := mixAppRegex.FindStringSubmatch(project)
+	if match == nil {
+		return "", nil
+	}
+	return match[1], nil
+}
+
+// GetVersionFiles follows the version of the project definition to where it is written:
+// a literal in mix.exs, the "@version" module attribute or a file read by either of them (e.g. VERSION)
+func (e Elixir) GetVersionFiles() ([]VersionFile, error) {
+	project, err := readMixProject(e.ProjectConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	match := mixVersionRegex.FindStringSubmatch(project)
+	if match == nil {
+		return nil, ErrMixVersionNotFound
+	}
+	value := strings.TrimSpace(match[1])
+	mixPath := filepath.Join(e.ProjectConfig.Path, mixFileName)
+
+	if strings.HasPrefix(value, "@version") {
+		var content []byte
+		content, err = os.ReadFile(mixPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", mixFileName, err)
+		}
+
+		attribute := mixVersionAttributeRegex.FindStringSubmatch(string(content))
+		if attribute == nil {
+			return nil, fmt.Errorf("%w: @version is not defined", ErrMixVersionNotFound)
+		}
+		value = strings.TrimSpace(attribute[1])
+		if strings.HasPrefix(value, `"`) {
+			return []VersionFile{{Path: mixPath, Patterns: []string{mixAttributeVersionPattern}}}, nil
+		}
+	} else if strings.HasPrefix(value, `"`) {
+		return []VersionFile{{Path: mixPath, Patterns: []string{mixProjectVersionPattern}}}, nil
+	}
+
+	readFile := mixReadFileRegex.FindStringSubmatch(value)
+	if readFile == nil {
+		return nil, fmt.Errorf("%w: unsupported value %s", ErrMixVersionNotFound, value)
+	}
+	return []VersionFile{{
+		Path:     filepath.Join(e.ProjectConfig.Path, filepath.FromSlash(readFile[1])),
+		Patterns: []string{plainVersionPattern},
+	}}, nil
+}
+
+// readMixProject returns the project definition of mix.exs, up to the next function
+func readMixProject(projectPath string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(projectPath, mixFileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", mixFileName, err)
+	}
+
+	location := mixProjectRegex.FindIndex(content)
+	if location == nil {
+		return "", ErrMixProjectNotFound
+	}
+
+	project := content[location[1]:]
+	if next := mixNextFunctionRegex.FindIndex(project); next != nil {
+		project = project[:next[0]]
+	}
+	return string(project), nil
+}
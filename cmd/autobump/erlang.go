@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// appSrcNameRegex captures the application name of an OTP application resource file (*.app.src)
+var appSrcNameRegex = regexp.MustCompile(`\{\s*application\s*,\s*'?(\w+)'?\s*,`)
+
+type Erlang struct {
+	ProjectConfig ProjectConfig
+}
+
+// GetProjectName returns the application of "src/*.app.src", the umbrella projects (with their applications
+// inside "apps") have none and use the configured name
+func (e Erlang) GetProjectName() (string, error) {
+	matches, err := filepath.Glob(filepath.Join(e.ProjectConfig.Path, "src", "*.app.src"))
+	if err != nil || len(matches) == 0 {
+		return "", err
+	}
+
+	content, err := os.ReadFile(matches[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", matches[0], err)
+	}
+
+	match := appSrcNameRegex.FindStringSubmatch(string(content))
+	if match == nil {
+		return "", nil
+	}
+	return match[1], nil
+}
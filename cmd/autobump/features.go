@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// features of the pull requests requested in the configuration, named after their option
+const (
+	featureReviewers       = "reviewers"
+	featureNotifyGroup     = "notify_group"
+	featureLintSuggestions = "lint_suggestions"
+	featureAutoMerge       = "auto_merge"
+	featureOnConflict      = "on_conflict"
+)
+
+var ErrUnsupportedFeature = errors.New("the remote service doesn't support the requested features")
+
+// requestedFeature is an option needing an operation on the remote service, skipped when the service can't do it
+type requestedFeature struct {
+	name      string
+	requested func(globalConfig *GlobalConfig, projectConfig *ProjectConfig) bool
+	supported func(capabilities ServiceCapabilities) bool
+}
+
+// requestedFeatures are the options checked against the capabilities of the service, a new option is one more entry
+var requestedFeatures = []requestedFeature{
+	{
+		name:      featureReviewers,
+		requested: func(_ *GlobalConfig, projectConfig *ProjectConfig) bool { return len(projectConfig.Reviewers) > 0 },
+		supported: func(capabilities ServiceCapabilities) bool { return capabilities.RequestReviews },
+	},
+	{
+		name:      featureNotifyGroup,
+		requested: func(_ *GlobalConfig, projectConfig *ProjectConfig) bool { return projectConfig.NotifyGroup != "" },
+		supported: func(capabilities ServiceCapabilities) bool { return capabilities.RequestReviews },
+	},
+	{
+		name: featureLintSuggestions,
+		requested: func(globalConfig *GlobalConfig, _ *ProjectConfig) bool {
+			return globalConfig.ChangelogLint.LintSuggestions
+		},
+		supported: func(capabilities ServiceCapabilities) bool { return capabilities.Comment },
+	},
+	{
+		name:      featureAutoMerge,
+		requested: func(_ *GlobalConfig, projectConfig *ProjectConfig) bool { return projectConfig.AutoMerge },
+		supported: func(capabilities ServiceCapabilities) bool { return capabilities.AutoMerge },
+	},
+	{
+		name:      featureOnConflict,
+		requested: func(globalConfig *GlobalConfig, _ *ProjectConfig) bool { return globalConfig.OnConflict != "" },
+		supported: func(capabilities ServiceCapabilities) bool { return capabilities.UpdatePullRequest },
+	},
+}
+
+// checkRequestedFeatures returns the features requested for the project that the service can't do. They are skipped
+// with a warning each and listed in the run report, or they fail the project with "strict_features".
+func checkRequestedFeatures(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	service serviceInfo,
+	logger *log.Entry,
+) ([]string, error) {
+	var unsupported []string
+	for _, feature := range requestedFeatures {
+		if feature.requested(globalConfig, projectConfig) && !feature.supported(service.capabilities) {
+			unsupported = append(unsupported, feature.name)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil, nil
+	}
+
+	if globalConfig.StrictFeatures {
+		return nil, fmt.Errorf("%w: %s can't do %s", ErrUnsupportedFeature, service.name, strings.Join(unsupported, ", "))
+	}
+	for _, feature := range unsupported {
+		logger.Warnf("%s isn't supported on %s, skipping it for project %s", feature, service.name, projectConfig.Name)
+	}
+	return unsupported, nil
+}
+
+// isFeatureSkipped tells whether the feature requested for the project is skipped, since the service can't do it
+func isFeatureSkipped(state *projectState, feature string) bool {
+	return slices.Contains(state.skippedFeatures, feature)
+}
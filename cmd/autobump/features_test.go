@@ -0,0 +1,147 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeService creates pull requests and comments on them, without any other capability
+var fakeService = serviceInfo{
+	name:         "fake",
+	capabilities: ServiceCapabilities{CreatePullRequest: true, Comment: true},
+}
+
+// newFeaturesRequest requests every feature of the pull requests
+func newFeaturesRequest() (*GlobalConfig, *ProjectConfig) {
+	globalConfig := &GlobalConfig{
+		ChangelogLint: ChangelogLintConfig{LintSuggestions: true},
+		OnConflict:    onConflictReport,
+	}
+	projectConfig := &ProjectConfig{Name: "api", Reviewers: []string{"alice"}, AutoMerge: true}
+	return globalConfig, projectConfig
+}
+
+//nolint:paralleltest // the warnings are read from the global logger
+func TestCheckRequestedFeatures_WarnsAndSkipsTheUnsupportedFeatures(t *testing.T) {
+	// Arrange
+	hook := test.NewGlobal()
+	globalConfig, projectConfig := newFeaturesRequest()
+
+	// Act
+	skippedFeatures, err := checkRequestedFeatures(globalConfig, projectConfig, fakeService, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{featureReviewers, featureAutoMerge, featureOnConflict}, skippedFeatures)
+	assert.False(t, isFeatureSkipped(&projectState{skippedFeatures: skippedFeatures}, featureLintSuggestions))
+
+	var warnings []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel && strings.Contains(entry.Message, "on fake") {
+			warnings = append(warnings, entry.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"reviewers isn't supported on fake, skipping it for project api",
+		"auto_merge isn't supported on fake, skipping it for project api",
+		"on_conflict isn't supported on fake, skipping it for project api",
+	}, warnings)
+}
+
+func TestCheckRequestedFeatures_StrictFailsTheProject(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newFeaturesRequest()
+	globalConfig.StrictFeatures = true
+
+	// Act
+	skippedFeatures, err := checkRequestedFeatures(globalConfig, projectConfig, fakeService, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrUnsupportedFeature)
+	assert.Contains(t, err.Error(), "fake can't do reviewers, auto_merge, on_conflict")
+	assert.Empty(t, skippedFeatures)
+}
+
+func TestCheckRequestedFeatures_BackfilledServices(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		serviceType ServiceType
+		expected    []string
+	}{
+		{name: "should do every feature on GitLab", serviceType: GITLAB},
+		{
+			name:        "should skip the reviews and the merge on Azure DevOps",
+			serviceType: AZUREDEVOPS,
+			expected:    []string{featureReviewers, featureAutoMerge},
+		},
+		{
+			name:        "should skip the features of the pull requests on GitHub",
+			serviceType: GITHUB,
+			expected:    []string{featureReviewers, featureLintSuggestions, featureAutoMerge, featureOnConflict},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			globalConfig, projectConfig := newFeaturesRequest()
+
+			// Act
+			skippedFeatures, err := checkRequestedFeatures(
+				globalConfig, projectConfig, getServiceInfo(test.serviceType), standardLogEntry(),
+			)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, skippedFeatures)
+		})
+	}
+}
+
+func TestCheckPullRequestConflicts_DoesNotAskTheSkippedMergeability(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	handle, calls := newScriptedPullRequest(mergeabilityConflicted)
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{OnConflict: onConflictFail},
+		projectConfig: &ProjectConfig{},
+		state:         projectState{pullRequest: handle, skippedFeatures: []string{featureOnConflict}},
+	}
+
+	// Act
+	err := checkPullRequestConflicts(ctx, "CHANGELOG.md", "chore/bump-1.1.0")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Zero(t, *calls)
+	assert.Nil(t, ctx.state.mergeability)
+}
+
+func TestPrepareLintSuggestions_DoesNotPrepareTheSkippedSuggestions(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{ChangelogLint: ChangelogLintConfig{LintSuggestions: true}},
+		projectConfig: &ProjectConfig{},
+		state:         projectState{skippedFeatures: []string{featureLintSuggestions}},
+	}
+
+	// Act
+	prepareLintSuggestions(ctx, "CHANGELOG.md")
+
+	// Assert
+	assert.Nil(t, ctx.state.lintSuggestions)
+}
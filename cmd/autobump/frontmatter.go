@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// getFrontMatterLength returns the amount of lines of the YAML front matter starting the CHANGELOG
+// (e.g. "---\ntitle: Changelog\n---"), used by the static site generators, or 0 when there is none.
+// A block which is never closed isn't a front matter.
+func getFrontMatterLength(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t\r") != "---" {
+		return 0
+	}
+
+	for index := 1; index < len(lines); index++ {
+		delimiter := strings.TrimRight(lines[index], " \t\r")
+		if delimiter == "---" || delimiter == "..." {
+			return index + 1
+		}
+	}
+	return 0
+}
+
+// splitFrontMatter splits the front matter (if any) from the Markdown content below it
+func splitFrontMatter(lines []string) ([]string, []string) {
+	length := getFrontMatterLength(lines)
+	return lines[:length], lines[length:]
+}
+
+// joinFrontMatter puts the front matter back above the processed content, without changing it
+func joinFrontMatter(frontMatter []string, content []string) []string {
+	return slices.Concat(frontMatter, content)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frontMatter is the front matter of a CHANGELOG published by a static site generator,
+// with lines which would be mistaken for CHANGELOG content
+const frontMatter = `---
+title: Changelog
+
+
+summary: "## [9.0.0] was the last release of the old site"
+---
+`
+
+// changelogWithFrontMatter is a CHANGELOG with a front matter and unreleased changes
+const changelogWithFrontMatter = frontMatter + `# Changelog
+
+## [Unreleased]
+
+### Added
+
+- added the new feature
+
+## [1.0.0] - 2024-01-01
+
+### Added
+
+- added the first feature
+`
+
+func TestGetFrontMatterLength(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cases := map[string]int{
+		changelogWithFrontMatter:                  6,
+		"---\ntitle: Changelog\n...\n# Changelog": 3,
+		"---\ntitle: Changelog\n# Changelog":      0,
+		changelogOriginal:                         0,
+	}
+
+	for content, expected := range cases {
+		// Act
+		length := getFrontMatterLength(strings.Split(content, "\n"))
+
+		// Assert
+		assert.Equal(t, expected, length)
+	}
+}
+
+func TestProcessChangelog_KeepsFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithFrontMatter, "\n")
+
+	// Act
+	version, newChangelog, err := processChangelog(lines, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", versionString(version))
+	content := strings.Join(newChangelog, "\n")
+	assert.True(t, strings.HasPrefix(content, frontMatter+"# Changelog\n\n## [Unreleased]\n"))
+	assert.Contains(t, content, "## [1.1.0] - ")
+	assert.Contains(t, content, "- added the new feature")
+}
+
+func TestProcessVersionStreams_KeepsFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(frontMatter+`# Changelog
+
+## [Unreleased]
+
+### Added
+
+- [api] added the new endpoint
+
+## [api-1.0.0] - 2024-01-01
+
+### Added
+
+- added the API
+`, "\n")
+	changelogConfig := ChangelogConfig{VersionStreams: []VersionStream{{Name: "api"}}}
+
+	// Act
+	versions, newChangelog, err := processVersionStreams(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", versionString(versions["api"]))
+	assert.True(t, strings.HasPrefix(strings.Join(newChangelog, "\n"), frontMatter+"# Changelog\n"))
+}
+
+func TestGetUnreleasedSummary_SkipsFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithFrontMatter, "\n")
+
+	// Act
+	summary, err := getUnreleasedSummary(lines, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", versionString(summary.LatestVersion))
+	assert.Equal(t, 9, summary.UnreleasedLine)
+	assert.Equal(t, 1, summary.RecognizedEntries)
+}
+
+func TestTidyChangelog_KeepsFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(strings.Replace(changelogWithFrontMatter, "## [1.0.0]", "\n\n## [1.0.0]", 1), "\n")
+
+	// Act
+	tidiedLines, repairs := tidyChangelog(lines, "")
+
+	// Assert
+	assert.Equal(t, []tidyRepair{{Line: 15, Message: "removed the consecutive blank lines"}}, repairs)
+	assert.Equal(t, changelogWithFrontMatter, strings.Join(tidiedLines, "\n"))
+}
@@ -9,10 +9,11 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
-	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -69,10 +70,18 @@ func getGlobalGitConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
-// openRepo opens a git repository at the given path
-func openRepo(projectPath string) (*git.Repository, error) {
-	log.Infof("Opening repository at %s", projectPath)
-	repo, err := git.PlainOpen(projectPath)
+// openRepo opens a git repository at the given path, following the linked worktrees to their repository
+func openRepo(projectPath string, logger *log.Entry) (*git.Repository, error) {
+	logger.Infof("Opening repository at %s", projectPath)
+	err := checkRepositoryPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the project might be a directory of the repository (e.g. a component of a monorepo)
+	repo, err := git.PlainOpenWithOptions(
+		projectPath, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true},
+	)
 	if err != nil {
 		return nil, fmt.Errorf("could not open repository: %w", err)
 	}
@@ -105,55 +114,192 @@ func createAndSwitchBranch(
 	workTree *git.Worktree,
 	branchName string,
 	hash plumbing.Hash,
+	logger *log.Entry,
 ) error {
-	log.Infof("Creating and switching to new branch '%s'", branchName)
+	logger.Infof("Creating and switching to new branch '%s'", branchName)
 	ref := plumbing.NewHashReference(plumbing.ReferenceName("refs/heads/"+branchName), hash)
 	err := repo.Storer.SetReference(ref)
 	if err != nil {
 		return fmt.Errorf("could not create branch: %w", err)
 	}
 
-	return checkoutBranch(workTree, branchName)
+	return checkoutBranch(repo, workTree, branchName, logger)
 }
 
-// checkoutBranch switches to the given branch
-func checkoutBranch(w *git.Worktree, branchName string) error {
-	log.Infof("Switching to branch '%s'", branchName)
-	err := w.Checkout(&git.CheckoutOptions{
-		Branch: plumbing.ReferenceName("refs/heads/" + branchName),
-	})
+// checkoutBranch switches to the given branch, refusing the worktree with unstaged changes like "git checkout"
+func checkoutBranch(repo *git.Repository, workTree *git.Worktree, branchName string, logger *log.Entry) error {
+	logger.Infof("Switching to branch '%s'", branchName)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return fmt.Errorf("could not checkout branch: %w", err)
+	}
+
+	status, err := workTree.Status()
+	if err != nil {
+		return fmt.Errorf("could not checkout branch: %w", err)
+	}
+	for _, fileStatus := range status {
+		if fileStatus.Worktree != git.Unmodified && fileStatus.Worktree != git.Untracked {
+			return fmt.Errorf("could not checkout branch: %w", git.ErrUnstagedChanges)
+		}
+	}
+
+	err = switchHead(repo, workTree, ref.Name(), ref.Hash())
 	if err != nil {
 		return fmt.Errorf("could not checkout branch: %w", err)
 	}
 	return nil
 }
 
+// switchHead points the HEAD to the branch (or to the commit, when detached), updating only the files which differ
+// between the commits, like "git checkout" does. The checkouts of go-git are avoided, since they delete the untracked
+// files of the worktree.
+func switchHead(repo *git.Repository, workTree *git.Worktree, name plumbing.ReferenceName, hash plumbing.Hash) error {
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("could not read the HEAD: %w", err)
+	}
+	if head.Hash() != hash {
+		err = checkoutTreeChanges(repo, workTree, head.Hash(), hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	headRef := plumbing.NewSymbolicReference(plumbing.HEAD, name)
+	if !name.IsBranch() {
+		headRef = plumbing.NewHashReference(plumbing.HEAD, hash)
+	}
+	err = repo.Storer.SetReference(headRef)
+	if err != nil {
+		return fmt.Errorf("could not update the HEAD: %w", err)
+	}
+
+	// the mixed reset only writes the index, leaving the worktree as it is
+	err = workTree.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset})
+	if err != nil {
+		return fmt.Errorf("could not reset the index: %w", err)
+	}
+	return nil
+}
+
+// checkoutTreeChanges writes the files which differ between the commits with their content in the target commit,
+// deleting the files missing from it. The other files of the worktree are kept as they are.
+func checkoutTreeChanges(repo *git.Repository, workTree *git.Worktree, from plumbing.Hash, to plumbing.Hash) error {
+	fromTree, err := getCommitTree(repo, from)
+	if err != nil {
+		return err
+	}
+	toTree, err := getCommitTree(repo, to)
+	if err != nil {
+		return err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return fmt.Errorf("could not compare the commits: %w", err)
+	}
+
+	for _, change := range changes {
+		if change.To.Name == "" {
+			err = workTree.Filesystem.Remove(change.From.Name)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("could not delete %s: %w", change.From.Name, err)
+			}
+			continue
+		}
+
+		var file *object.File
+		file, err = toTree.File(change.To.Name)
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", change.To.Name, err)
+		}
+		var content string
+		content, err = file.Contents()
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", change.To.Name, err)
+		}
+		if file.Mode == filemode.Symlink {
+			_ = workTree.Filesystem.Remove(change.To.Name)
+			err = workTree.Filesystem.Symlink(content, change.To.Name)
+			if err != nil {
+				return fmt.Errorf("could not write %s: %w", change.To.Name, err)
+			}
+			continue
+		}
+		var mode os.FileMode
+		mode, err = file.Mode.ToOSFileMode()
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", change.To.Name, err)
+		}
+		err = util.WriteFile(workTree.Filesystem, change.To.Name, []byte(content), mode)
+		if err != nil {
+			return fmt.Errorf("could not write %s: %w", change.To.Name, err)
+		}
+	}
+	return nil
+}
+
+// getCommitTree returns the tree of the commit
+func getCommitTree(repo *git.Repository, hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the tree of %s: %w", hash, err)
+	}
+	return tree, nil
+}
+
 // commitChanges commits the changes in the given worktree
 func commitChanges(
 	workTree *git.Worktree,
 	commitMessage string,
-	signKey *openpgp.Entity,
+	trailers string,
+	signer git.Signer,
 	name string,
 	email string,
+	date time.Time,
+	logger *log.Entry,
 ) (plumbing.Hash, error) {
-	log.Info("Committing changes")
+	logger.Info("Committing changes")
 
-	// add DCO sign-off
-	signoff := fmt.Sprintf("\n\nSigned-off-by: %s <%s>", name, email)
-	commitMessage += signoff
+	// add DCO sign-off, followed by the other trailers in the same paragraph
+	commitMessage += formatSignoff(name, email) + trailers
 
-	commit, err := workTree.Commit(commitMessage, &git.CommitOptions{SignKey: signKey})
+	options := &git.CommitOptions{Signer: signer}
+	// pin the timestamps of the commit, making it reproducible
+	if !date.IsZero() {
+		signature := &object.Signature{Name: name, Email: email, When: date}
+		options.Author = signature
+		options.Committer = signature
+	}
+
+	commit, err := workTree.Commit(commitMessage, options)
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("could not commit changes: %w", err)
 	}
 	return commit, nil
 }
 
+// formatSignoff returns the DCO sign-off appended to the commit messages
+func formatSignoff(name string, email string) string {
+	return fmt.Sprintf("\n\nSigned-off-by: %s <%s>", name, email)
+}
+
 // pushChangesSSH pushes the changes to the remote repository over SSH
-func pushChangesSSH(repo *git.Repository, refSpec config.RefSpec) error {
-	log.Info("Pushing local changes to remote repository through SSH")
+func pushChangesSSH(
+	repo *git.Repository,
+	refSpec config.RefSpec,
+	lease *git.ForceWithLease,
+	logger *log.Entry,
+) error {
+	logger.Info("Pushing local changes to remote repository through SSH")
 	err := repo.Push(&git.PushOptions{
-		RefSpecs: []config.RefSpec{refSpec},
+		RefSpecs:       []config.RefSpec{refSpec},
+		Progress:       newProgressLogger("push", logger),
+		ForceWithLease: lease,
 	})
 	if err != nil {
 		return fmt.Errorf("could not push changes to remote repository: %w", err)
@@ -166,20 +312,24 @@ func pushChangesHTTPS(
 	repo *git.Repository,
 	repoCfg *config.Config,
 	refSpec config.RefSpec,
+	lease *git.ForceWithLease,
 	globalConfig *GlobalConfig,
 	projectConfig *ProjectConfig,
+	logger *log.Entry,
 ) error {
-	log.Info("Pushing local changes to remote repository through HTTPS")
+	logger.Info("Pushing local changes to remote repository through HTTPS")
 	pushOptions := &git.PushOptions{
-		RefSpecs:   []config.RefSpec{refSpec},
-		RemoteName: "origin",
+		RefSpecs:       []config.RefSpec{refSpec},
+		RemoteName:     "origin",
+		Progress:       newProgressLogger("push", logger),
+		ForceWithLease: lease,
 	}
 
-	service, err := getRemoteServiceType(repo)
+	remoteURL, err := getRemoteRepoURL(repo)
 	if err != nil {
 		return err
 	}
-	authMethods, err := getAuthMethods(service, repoCfg.User.Name, globalConfig, projectConfig)
+	authMethods, err := getAuthMethods(remoteURL, repoCfg.User.Name, globalConfig, projectConfig, logger)
 	if err != nil {
 		return err
 	}
@@ -201,21 +351,29 @@ func pushChangesHTTPS(
 	return nil
 }
 
-// getAuthMethods returns the authentication method to use for cloning/pushing changes
+// getAuthMethods returns the authentication method to use for cloning/pushing changes to the remote URL
 func getAuthMethods(
-	service ServiceType,
+	remoteURL string,
 	username string,
 	globalConfig *GlobalConfig,
 	projectConfig *ProjectConfig,
+	logger *log.Entry,
 ) ([]transport.AuthMethod, error) {
 	var authMethods []transport.AuthMethod
+	service := getServiceTypeByURL(remoteURL)
+
+	// credentials embedded in the project URL
+	if projectConfig.embeddedAuth != nil {
+		logger.Infof("Using credentials embedded in the project URL to authenticate")
+		authMethods = append(authMethods, projectConfig.embeddedAuth)
+	}
 
 	switch service { //nolint:exhaustive // Unimplemented services are handled by the default case
 	case GITLAB:
 		// TODO: this lines of code MUST be refactored to avoid code duplication
 		// project access token
 		if projectConfig.ProjectAccessToken != "" {
-			log.Infof("Using project access token to authenticate")
+			logger.Infof("Using project access token to authenticate")
 			authMethods = append(authMethods, &http.BasicAuth{
 				Username: "oauth2",
 				Password: projectConfig.ProjectAccessToken,
@@ -224,7 +382,7 @@ func getAuthMethods(
 
 		// GitLab personal access token
 		if globalConfig.GitLabAccessToken != "" {
-			log.Infof("Using GitLab access token to authenticate")
+			logger.Infof("Using GitLab access token to authenticate")
 			authMethods = append(authMethods, &http.BasicAuth{
 				Username: username,
 				Password: globalConfig.GitLabAccessToken,
@@ -233,34 +391,70 @@ func getAuthMethods(
 
 		// CI job token
 		if globalConfig.GitLabCIJobToken != "" {
-			log.Infof("Using GitLab CI job token to authenticate")
+			logger.Infof("Using GitLab CI job token to authenticate")
 			authMethods = append(authMethods, &http.BasicAuth{
 				Username: "gitlab-ci-token",
 				Password: globalConfig.GitLabCIJobToken,
 			})
 		}
-	case AZUREDEVOPS:
-		log.Infof("Using Azure DevOps access token to authenticate")
-		transport.UnsupportedCapabilities = []capability.Capability{
-			capability.ThinPack,
+	case GITHUB:
+		if token := getGitHubAccessToken(globalConfig, remoteURL); token != "" {
+			logger.Infof("Using GitHub access token to authenticate")
+			authMethods = append(authMethods, &http.BasicAuth{
+				Username: "x-access-token",
+				Password: token,
+			})
 		}
+	case BITBUCKET:
+		// repository access token
+		if projectConfig.ProjectAccessToken != "" {
+			logger.Infof("Using project access token to authenticate")
+			authMethods = append(authMethods, getBitbucketGitAuth(projectConfig.ProjectAccessToken))
+		}
+
+		// app password or access token of the workspace
+		if globalConfig.BitbucketAccessToken != "" {
+			logger.Infof("Using Bitbucket access token to authenticate")
+			authMethods = append(authMethods, getBitbucketGitAuth(globalConfig.BitbucketAccessToken))
+		}
+	case AZUREDEVOPS:
+		logger.Infof("Using Azure DevOps access token to authenticate")
 		authMethods = append(authMethods, &http.BasicAuth{
 			Username: username,
 			Password: globalConfig.AzureDevOpsAccessToken,
 		})
 	default:
-		log.Errorf("No authentication mechanism implemented for service type '%v'", service)
-		return nil, ErrAuthNotImplemented
+		if len(authMethods) == 0 {
+			logger.Errorf("No authentication mechanism implemented for service type '%v'", service)
+			return nil, ErrAuthNotImplemented
+		}
 	}
 
 	if len(authMethods) == 0 {
-		log.Error("No authentication credentials found for any authentication method")
+		logger.Error("No authentication credentials found for any authentication method")
 		return nil, ErrNoAuthMethodFound
 	}
 
 	return authMethods, nil
 }
 
+// getBitbucketGitAuth returns the Git credentials of an app password ("username:app_password") or an access token
+func getBitbucketGitAuth(credentials string) *http.BasicAuth {
+	if username, appPassword, found := strings.Cut(credentials, ":"); found {
+		return &http.BasicAuth{Username: username, Password: appPassword}
+	}
+	// the access tokens are sent along with this fixed username
+	return &http.BasicAuth{Username: "x-token-auth", Password: credentials}
+}
+
+// configureAzureDevOpsTransport enables the multi_ack capabilities required by Azure DevOps.
+// The transport capabilities are process-wide, so they are set once in main, before any project is processed.
+func configureAzureDevOpsTransport() {
+	transport.UnsupportedCapabilities = []capability.Capability{
+		capability.ThinPack,
+	}
+}
+
 // getRemoteServiceType returns the type of the remote service (e.g. GitHub, GitLab)
 func getRemoteServiceType(repo *git.Repository) (ServiceType, error) {
 	cfg, err := repo.Config()
@@ -277,23 +471,52 @@ func getRemoteServiceType(repo *git.Repository) (ServiceType, error) {
 	return getServiceTypeByURL(firstRemote), nil
 }
 
-// getServiceTypeByURL returns the type of the remote service (e.g. GitHub, GitLab) by URL
-func getServiceTypeByURL(remoteURL string) ServiceType {
-	// TODO: this could be better using the Adapter pattern
-	switch {
-	case strings.Contains(remoteURL, "gitlab.com"):
-		return GITLAB
-	case strings.Contains(remoteURL, "github.com"):
-		return GITHUB
-	case strings.Contains(remoteURL, "bitbucket.org"):
-		return BITBUCKET
-	case strings.Contains(remoteURL, "git-codecommit"):
-		return CODECOMMIT
-	case strings.Contains(remoteURL, "dev.azure.com"):
-		return AZUREDEVOPS
-	default:
-		return UNKNOWN
+// scrubRepositoryCredentials removes the credentials from the remote URLs, the URL rewrites
+// and the credential helpers of the repository config, so they are never persisted on disk
+func scrubRepositoryCredentials(repo *git.Repository, logger *log.Entry) error {
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("could not get repository config: %w", err)
+	}
+
+	changed := false
+	for _, remote := range repoConfig.Remotes {
+		for i, remoteURL := range remote.URLs {
+			sanitizedURL, _ := sanitizeRemoteURL(remoteURL)
+			if sanitizedURL != remoteURL {
+				remote.URLs[i] = sanitizedURL
+				changed = true
+			}
+		}
 	}
+
+	for name, rewrite := range repoConfig.URLs {
+		sanitizedName, _ := sanitizeRemoteURL(rewrite.Name)
+		sanitizedInsteadOf, _ := sanitizeRemoteURL(rewrite.InsteadOf)
+		if sanitizedName != rewrite.Name || sanitizedInsteadOf != rewrite.InsteadOf {
+			delete(repoConfig.URLs, name)
+			rewrite.Name = sanitizedName
+			rewrite.InsteadOf = sanitizedInsteadOf
+			repoConfig.URLs[sanitizedName] = rewrite
+			changed = true
+		}
+	}
+
+	if repoConfig.Raw.HasSection("credential") {
+		repoConfig.Raw.RemoveSection("credential")
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	logger.Info("Removed the credentials found in the repository config")
+	err = repo.SetConfig(repoConfig)
+	if err != nil {
+		return fmt.Errorf("could not write repository config: %w", err)
+	}
+	return nil
 }
 
 // getRemoteRepoURL returns the URL of the remote repository
@@ -304,7 +527,9 @@ func getRemoteRepoURL(repo *git.Repository) (string, error) {
 	}
 
 	if len(remote.Config().URLs) > 0 {
-		return remote.Config().URLs[0], nil // return the first URL configured for the remote
+		// return the first URL configured for the remote, without any embedded credentials
+		remoteURL, _ := sanitizeRemoteURL(remote.Config().URLs[0])
+		return remoteURL, nil
 	}
 
 	return "", ErrNoRemoteURL
@@ -330,7 +555,7 @@ func getAmountCommits(repo *git.Repository) (int, error) {
 }
 
 // getLatestTag find the latest tag in the Git history
-func getLatestTag(repo *git.Repository) (*LatestTag, error) {
+func getLatestTag(repo *git.Repository, logger *log.Entry) (*LatestTag, error) {
 	tags, err := repo.Tags()
 	if err != nil {
 		log.Fatal(err)
@@ -347,7 +572,7 @@ func getLatestTag(repo *git.Repository) (*LatestTag, error) {
 		// if the project is already started with no tags in the history
 		// TODO: review this section
 		if numCommits >= maxAcceptableInitialCommits {
-			log.Warnf("No tags found in Git history, falling back to '%s'", defaultGitTag)
+			logger.Warnf("No tags found in Git history, falling back to '%s'", defaultGitTag)
 			version, _ := semver.NewVersion(defaultGitTag)
 			return &LatestTag{
 				Tag:  version,
@@ -356,7 +581,7 @@ func getLatestTag(repo *git.Repository) (*LatestTag, error) {
 		}
 
 		// if the project is new, we should not use any tag and just commit the file
-		log.Warn("This project seems be a new project, the CHANGELOG should be committed by itself.")
+		logger.Warn("This project seems be a new project, the CHANGELOG should be committed by itself.")
 		return nil, ErrNoTagsFound
 	}
 
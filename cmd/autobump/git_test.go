@@ -4,13 +4,18 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-faker/faker/v4"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +36,9 @@ func TestGetAuthMethods_Success(t *testing.T) {
 	}
 
 	// Act
-	authMethods, err := getAuthMethods(GITLAB, faker.Username(), &globalConfig, &projectConfig)
+	authMethods, err := getAuthMethods(
+		"https://gitlab.com/group/project.git", faker.Username(), &globalConfig, &projectConfig, standardLogEntry(),
+	)
 
 	// Assert
 	require.NoError(t, err)
@@ -59,7 +66,9 @@ func TestGetAuthMethods_NoAuthMethodFound(t *testing.T) {
 	projectConfig := ProjectConfig{}
 
 	// Act
-	authMethods, err := getAuthMethods(GITLAB, faker.Username(), &globalConfig, &projectConfig)
+	authMethods, err := getAuthMethods(
+		"https://gitlab.com/group/project.git", faker.Username(), &globalConfig, &projectConfig, standardLogEntry(),
+	)
 
 	// Assert
 	require.ErrorIs(t, err, ErrNoAuthMethodFound)
@@ -74,13 +83,69 @@ func TestGetAuthMethods_AuthNotImplemented(t *testing.T) {
 	projectConfig := ProjectConfig{}
 
 	// Act
-	authMethods, err := getAuthMethods(UNKNOWN, faker.Username(), &globalConfig, &projectConfig)
+	authMethods, err := getAuthMethods(
+		"https://git.example.com/group/project.git", faker.Username(), &globalConfig, &projectConfig, standardLogEntry(),
+	)
 
 	// Assert
 	require.ErrorIs(t, err, ErrAuthNotImplemented)
 	assert.Empty(t, authMethods)
 }
 
+func TestGetAuthMethods_EmbeddedCredentialsFirst(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	embeddedToken := faker.Password()
+	globalConfig := GlobalConfig{
+		GitLabAccessToken: faker.Password(),
+	}
+	projectConfig := ProjectConfig{
+		embeddedAuth: &http.BasicAuth{Username: faker.Username(), Password: embeddedToken},
+	}
+
+	// Act
+	authMethods, err := getAuthMethods(
+		"https://gitlab.com/group/project.git", faker.Username(), &globalConfig, &projectConfig, standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, authMethods, 2)
+	assert.Equal(t, embeddedToken, authMethods[0].(*http.BasicAuth).Password)
+}
+
+func TestGetAuthMethods_AzureDevOps(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := GlobalConfig{AzureDevOpsAccessToken: faker.Password()}
+
+	// Act
+	authMethods, err := getAuthMethods(
+		"https://dev.azure.com/org/project/_git/repo", faker.Username(), &globalConfig, &ProjectConfig{},
+		standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, authMethods, 1)
+	assert.Equal(t, globalConfig.AzureDevOpsAccessToken, authMethods[0].(*http.BasicAuth).Password)
+}
+
+//nolint:paralleltest // the transport capabilities are process-wide
+func TestConfigureAzureDevOpsTransport(t *testing.T) {
+	// Arrange
+	capabilities := transport.UnsupportedCapabilities
+	t.Cleanup(func() { transport.UnsupportedCapabilities = capabilities })
+
+	// Act
+	configureAzureDevOpsTransport()
+
+	// Assert
+	assert.Equal(t, []capability.Capability{capability.ThinPack}, transport.UnsupportedCapabilities)
+}
+
 func TestGetRemoteServiceType_Success(t *testing.T) {
 	t.Parallel()
 
@@ -175,7 +240,7 @@ func TestGetLatestTag_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	// Act
-	tag, err := getLatestTag(repo)
+	tag, err := getLatestTag(repo, standardLogEntry())
 
 	// Assert
 	require.NoError(t, err)
@@ -218,8 +283,84 @@ func TestGetLatestTag_NoTagsFound(t *testing.T) {
 	require.NoError(t, err)
 
 	// Act
-	_, err = getLatestTag(repo)
+	_, err = getLatestTag(repo, standardLogEntry())
 	// Assert
 
 	require.ErrorIs(t, err, ErrNoTagsFound)
 }
+
+func TestScrubRepositoryCredentials_NoTokenInConfig(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	token := faker.Password()
+	remoteURLs := []string{
+		"https://oauth2:" + token + "@gitlab.com/group/project.git",
+		"https://user:" + token + "@github.com/user/repo.git",
+		"https://org:" + token + "@dev.azure.com/org/project/_git/repo",
+		"https://x-token-auth:" + token + "@bitbucket.org/workspace/repo.git",
+	}
+
+	for _, remoteURL := range remoteURLs {
+		repoPath := t.TempDir()
+		repo, err := git.PlainInit(repoPath, false)
+		require.NoError(t, err)
+
+		repoConfig, err := repo.Config()
+		require.NoError(t, err)
+		repoConfig.Remotes["origin"] = &config.RemoteConfig{Name: "origin", URLs: []string{remoteURL}}
+		repoConfig.URLs[remoteURL] = &config.URL{Name: remoteURL, InsteadOf: "git@example.com:"}
+		repoConfig.Raw.Section("credential").SetOption("helper", "store --file=/tmp/"+token)
+		require.NoError(t, repo.SetConfig(repoConfig))
+
+		// Act
+		err = scrubRepositoryCredentials(repo, standardLogEntry())
+
+		// Assert
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(repoPath, ".git", "config"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), token, remoteURL)
+		sanitizedURL, _ := sanitizeRemoteURL(remoteURL)
+		assert.Contains(t, string(content), sanitizedURL, remoteURL)
+	}
+}
+
+func TestCheckoutBranch_KeepsTheUntrackedFiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, "version.txt", "1.0.0\n")
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, createAndSwitchBranch(repo, worktree, "feature", mustHead(t, repo), standardLogEntry()))
+	commitFile(t, repo, "version.txt", "1.1.0\n")
+	commitFile(t, repo, "feature.txt", "feature\n")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("notes\n"), 0o600))
+
+	// Act
+	err = checkoutBranch(repo, worktree, "master", standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	version, err := os.ReadFile(filepath.Join(repoPath, "version.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0\n", string(version))
+	assert.NoFileExists(t, filepath.Join(repoPath, "feature.txt"))
+	assert.FileExists(t, filepath.Join(repoPath, "notes.txt"))
+	status, err := worktree.Status()
+	require.NoError(t, err)
+	assert.Len(t, status, 1, "only the untracked file should differ from the HEAD")
+}
+
+// mustHead returns the commit of the HEAD
+func mustHead(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	return head.Hash()
+}
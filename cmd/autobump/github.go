@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// gitHubPullRequest is the part of a GitHub pull request read by AutoBump
+type gitHubPullRequest struct {
+	HTMLURL string `json:"html_url"`
+}
+
+// getGitHubRepositoryName returns the "owner/repository" name of the repository in the GitHub API
+func getGitHubRepositoryName(remoteURL string) (string, error) {
+	webURL, err := url.Parse(getRepositoryWebURL(remoteURL))
+	if err != nil || webURL.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRepoURL, redactURLCredentials(remoteURL))
+	}
+	return strings.Trim(webURL.Path, "/"), nil
+}
+
+// setGitHubHeaders sets the headers of the requests to the GitHub REST API, authenticated with the token of the
+// project, the one embedded in the URL or the one of the GitHub instance hosting the repository
+func setGitHubHeaders(req *http.Request, globalConfig *GlobalConfig, projectConfig *ProjectConfig, remoteURL string) {
+	// the tokens embedded in the URL are used when there is none configured
+	var embeddedToken string
+	if projectConfig.embeddedAuth != nil {
+		embeddedToken = projectConfig.embeddedAuth.Password
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	token := firstNonEmpty(projectConfig.ProjectAccessToken, embeddedToken, getGitHubAccessToken(globalConfig, remoteURL))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// createGitHubPullRequest creates a new pull request on GitHub or GitHub Enterprise Server, returning its URL.
+// The open pull request of the branch is returned instead when there is already one.
+func createGitHubPullRequest(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	repo *git.Repository,
+	sourceBranch string,
+	targetBranch string,
+	newVersion string,
+	logger *log.Entry,
+) (string, error) {
+	logger.Info("Creating GitHub pull request")
+
+	remoteURL, err := getRemoteRepoURL(repo)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+	return submitGitHubPullRequest(
+		ctx,
+		newAPIClient(globalConfig),
+		globalConfig,
+		projectConfig,
+		remoteURL,
+		sourceBranch,
+		targetBranch,
+		getPullRequestTitle(globalConfig, projectConfig, state, newVersion, logger),
+		getPullRequestDescription(globalConfig, projectConfig, state, logger),
+		logger,
+	)
+}
+
+// submitGitHubPullRequest creates the pull request through the API of the GitHub instance hosting the repository,
+// unless the branch already has an open one
+func submitGitHubPullRequest(
+	ctx context.Context,
+	client *http.Client,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	remoteURL string,
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+	logger *log.Entry,
+) (string, error) {
+	existingURL, err := findGitHubPullRequest(ctx, client, globalConfig, projectConfig, remoteURL, sourceBranch)
+	if err != nil {
+		return "", err
+	}
+	if existingURL != "" {
+		logger.Infof("The pull request of %s already exists: %s", sourceBranch, existingURL)
+		return existingURL, nil
+	}
+
+	req, err := buildGitHubPullRequestRequest(
+		ctx, globalConfig, projectConfig, remoteURL, sourceBranch, targetBranch, title, description,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("POST %s", req.URL)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated {
+		return "", classifyPullRequestError(
+			resp.StatusCode, fmt.Errorf("%w: %d - %s", ErrFailedToCreatePullRequest, resp.StatusCode, body),
+		)
+	}
+
+	var pullRequest gitHubPullRequest
+	_ = json.Unmarshal(body, &pullRequest)
+
+	logger.Info("Successfully created GitHub pull request")
+	return pullRequest.HTMLURL, nil
+}
+
+// findGitHubPullRequest returns the URL of the open pull request of the branch, empty when there is none
+func findGitHubPullRequest(
+	ctx context.Context,
+	client *http.Client,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	remoteURL string,
+	sourceBranch string,
+) (string, error) {
+	req, err := buildGitHubPullRequestSearchRequest(ctx, globalConfig, projectConfig, remoteURL, sourceBranch)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the pull requests: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", classifyPullRequestError(
+			resp.StatusCode, fmt.Errorf("failed to list the pull requests: %d - %s", resp.StatusCode, body),
+		)
+	}
+
+	var pullRequests []gitHubPullRequest
+	err = json.Unmarshal(body, &pullRequests)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the pull requests: %w", err)
+	}
+	if len(pullRequests) == 0 {
+		return "", nil
+	}
+	return pullRequests[0].HTMLURL, nil
+}
+
+// buildGitHubPullRequestSearchRequest builds the request listing the open pull requests of the branch.
+// The branch is qualified with the owner of the repository, since the pull requests of the forks are listed too.
+func buildGitHubPullRequestSearchRequest(
+	ctx context.Context,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	remoteURL string,
+	sourceBranch string,
+) (*http.Request, error) {
+	repositoryName, err := getGitHubRepositoryName(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	owner, _, _ := strings.Cut(repositoryName, "/")
+
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("head", owner+":"+sourceBranch)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(
+		"%s/repos/%s/pulls?%s", getGitHubAPIURL(globalConfig, remoteURL), repositoryName, query.Encode(),
+	), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	setGitHubHeaders(req, globalConfig, projectConfig, remoteURL)
+	return req, nil
+}
+
+// buildGitHubPullRequestRequest builds the request creating the pull request with the GitHub REST API
+func buildGitHubPullRequestRequest(
+	ctx context.Context,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	remoteURL string,
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) (*http.Request, error) {
+	repositoryName, err := getGitHubRepositoryName(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]string{"title": title, "head": sourceBranch, "base": targetBranch}
+	if description != "" {
+		payload["body"] = description
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(
+		"%s/repos/%s/pulls", getGitHubAPIURL(globalConfig, remoteURL), repositoryName,
+	), bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setGitHubHeaders(req, globalConfig, projectConfig, remoteURL)
+	return req, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitHubPullsServer mocks the pull requests API of GitHub, listing the open pull requests given
+// and recording the requests received
+func newGitHubPullsServer(t *testing.T, openPullRequests string, requests *[]*http.Request) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(openPullRequests))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]string
+		if json.Unmarshal(body, &payload) != nil || payload["head"] == "" || payload["base"] == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url": "https://github.com/acme/api/pull/7"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBuildGitHubPullRequestRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{GitHubAccessToken: "github-token"}
+	projectConfig := &ProjectConfig{Path: "https://github.com/acme/api.git"}
+
+	// Act
+	req, err := buildGitHubPullRequestRequest(
+		context.Background(), globalConfig, projectConfig, "git@github.com:acme/api.git", "chore/bump-1.1.0", "main",
+		"chore(bump): bumped version to 1.1.0", "### Added",
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/repos/acme/api/pulls", req.URL.String())
+	assert.Equal(t, "Bearer github-token", req.Header.Get("Authorization"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "chore(bump): bumped version to 1.1.0",
+		"body": "### Added",
+		"head": "chore/bump-1.1.0",
+		"base": "main"
+	}`, string(body))
+}
+
+func TestSubmitGitHubPullRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		remoteURL        string
+		openPullRequests string
+		enterprise       bool
+		expectedPath     string
+		expectedToken    string
+		expectedURL      string
+		expectedMethods  []string
+	}{
+		{
+			name:             "should create the pull request on github.com",
+			remoteURL:        "https://github.com/acme/api.git",
+			openPullRequests: `[]`,
+			expectedPath:     "/repos/acme/api/pulls",
+			expectedToken:    "Bearer github-token",
+			expectedURL:      "https://github.com/acme/api/pull/7",
+			expectedMethods:  []string{http.MethodGet, http.MethodPost},
+		},
+		{
+			name:             "should create the pull request on the API of GitHub Enterprise Server",
+			remoteURL:        "https://github.pulls.test/acme/api.git",
+			openPullRequests: `[]`,
+			enterprise:       true,
+			expectedPath:     "/api/v3/repos/acme/api/pulls",
+			expectedToken:    "Bearer enterprise-token",
+			expectedURL:      "https://github.com/acme/api/pull/7",
+			expectedMethods:  []string{http.MethodGet, http.MethodPost},
+		},
+		{
+			name:             "should return the open pull request of the branch",
+			remoteURL:        "https://github.com/acme/api.git",
+			openPullRequests: `[{"html_url": "https://github.com/acme/api/pull/3"}]`,
+			expectedPath:     "/repos/acme/api/pulls",
+			expectedToken:    "Bearer github-token",
+			expectedURL:      "https://github.com/acme/api/pull/3",
+			expectedMethods:  []string{http.MethodGet},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			var requests []*http.Request
+			server := newGitHubPullsServer(t, test.openPullRequests, &requests)
+			globalConfig := &GlobalConfig{GitHubAccessToken: "github-token"}
+			client := server.Client()
+			if test.enterprise {
+				globalConfig.GitHubEnterprise = GitHubEnterpriseConfig{
+					BaseURL:     "https://github.pulls.test",
+					APIURL:      server.URL + "/api/v3",
+					AccessToken: "enterprise-token",
+				}
+				require.NoError(t, loadProviders(globalConfig))
+			} else {
+				target, err := url.Parse(server.URL)
+				require.NoError(t, err)
+				client = &http.Client{Transport: &redirectTransport{target: target}}
+			}
+			projectConfig := &ProjectConfig{Path: test.remoteURL}
+
+			// Act
+			pullRequestURL, err := submitGitHubPullRequest(
+				context.Background(), client, globalConfig, projectConfig, test.remoteURL, "chore/bump-1.1.0", "main",
+				"chore(bump): bumped version to 1.1.0", "### Added", standardLogEntry(),
+			)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedURL, pullRequestURL)
+			var methods []string
+			for _, req := range requests {
+				methods = append(methods, req.Method)
+				assert.Equal(t, test.expectedPath, req.URL.Path)
+				assert.Equal(t, test.expectedToken, req.Header.Get("Authorization"))
+			}
+			assert.Equal(t, test.expectedMethods, methods)
+			assert.Equal(t, "acme:chore/bump-1.1.0", requests[0].URL.Query().Get("head"))
+			assert.Equal(t, "open", requests[0].URL.Query().Get("state"))
+		})
+	}
+}
+
+func TestSubmitGitHubPullRequest_Forbidden(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &redirectTransport{target: target}}
+
+	// Act
+	_, err = submitGitHubPullRequest(
+		context.Background(), client, &GlobalConfig{}, &ProjectConfig{}, "https://github.com/acme/api.git",
+		"chore/bump-1.1.0", "main", "chore(bump): bumped version to 1.1.0", "", standardLogEntry(),
+	)
+
+	// Assert
+	require.ErrorIs(t, err, ErrPullRequestForbidden)
+	require.ErrorIs(t, err, ErrFailedToCreatePullRequest)
+}
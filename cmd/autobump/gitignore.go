@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	log "github.com/sirupsen/logrus"
+)
+
+// ignorePattern is a pattern of the ignore files of a repository, along with where it is written
+type ignorePattern struct {
+	pattern gitignore.Pattern
+	source  string // e.g. "build/ (.gitignore:3)"
+}
+
+// readIgnorePatterns reads the patterns of "info/exclude" and of the ".gitignore" files of the repository,
+// ordered from the least to the most specific (the last matching pattern decides)
+func readIgnorePatterns(repoPath string) ([]ignorePattern, error) {
+	patterns, err := readIgnoreFile(repoPath, filepath.Join(".git", "info", "exclude"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.WalkDir(repoPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || entry.Name() != ".gitignore" {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+		var domain []string
+		if relativeDir := filepath.ToSlash(filepath.Dir(relativePath)); relativeDir != "." {
+			domain = strings.Split(relativeDir, "/")
+		}
+		filePatterns, err := readIgnoreFile(repoPath, relativePath, domain)
+		patterns = append(patterns, filePatterns...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ignore files: %w", err)
+	}
+	return patterns, nil
+}
+
+// readIgnoreFile reads the patterns of an ignore file, which apply to the files below its directory (the domain)
+func readIgnoreFile(repoPath string, relativePath string, domain []string) ([]ignorePattern, error) {
+	file, err := os.Open(filepath.Join(repoPath, relativePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", relativePath, err)
+	}
+	defer file.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, ignorePattern{
+			pattern: gitignore.ParsePattern(line, domain),
+			source:  fmt.Sprintf("%s (%s:%d)", line, filepath.ToSlash(relativePath), lineNumber),
+		})
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", relativePath, err)
+	}
+	return patterns, nil
+}
+
+// findIgnorePattern returns the pattern ignoring the file of the repository, or an empty string when it isn't ignored
+func findIgnorePattern(patterns []ignorePattern, repoPath string, filePath string) string {
+	relativePath, err := filepath.Rel(repoPath, filePath)
+	if err != nil {
+		return ""
+	}
+	path := strings.Split(filepath.ToSlash(relativePath), "/")
+	for index := len(patterns) - 1; index >= 0; index-- {
+		switch patterns[index].pattern.Match(path, false) {
+		case gitignore.Exclude:
+			return patterns[index].source
+		case gitignore.Include:
+			return ""
+		case gitignore.NoMatch:
+		}
+	}
+	return ""
+}
+
+// skipIgnoredVersionFile tells whether the version file matched by a glob is ignored by the repository,
+// reporting it once per project. The ignored files would be force-added to the bump commit (e.g. build artifacts).
+func skipIgnoredVersionFile(
+	projectConfig *ProjectConfig,
+	state *projectState,
+	patterns []ignorePattern,
+	filePath string,
+	logger *log.Entry,
+) bool {
+	source := findIgnorePattern(patterns, projectConfig.Path, filePath)
+	if source == "" {
+		return false
+	}
+
+	relativePath, err := filepath.Rel(projectConfig.Path, filePath)
+	if err != nil {
+		relativePath = filePath
+	}
+	relativePath = filepath.ToSlash(relativePath)
+	if !slices.Contains(state.ignoredVersionFiles, relativePath) {
+		state.ignoredVersionFiles = append(state.ignoredVersionFiles, relativePath)
+		reportFinding(Finding{
+			Level: findingWarning,
+			File:  relativePath,
+			Message: fmt.Sprintf(
+				"skipping the version file, it is ignored by %s (list it without a glob "+
+					"or set allow_ignored_version_files to update it)",
+				source,
+			),
+		}, logger)
+	}
+	return true
+}
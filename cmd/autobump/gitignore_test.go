@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIgnoredVersionFilesFixture creates a project whose "build" directory is ignored, with a version file
+// in it and another one in "src", both written in the given path of the version files
+func newIgnoredVersionFilesFixture(t *testing.T, versionFilePath string) (*GlobalConfig, *ProjectConfig) {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, ".gitignore"), []byte("# artifacts\nbuild/\n"), 0o600))
+	for _, dir := range []string{"build", "src"} {
+		require.NoError(t, os.Mkdir(filepath.Join(projectPath, dir), 0o700))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(projectPath, dir, "version.py"), []byte("__version__ = \"1.0.0\"\n"), 0o600,
+		))
+	}
+
+	globalConfig := &GlobalConfig{
+		LanguagesConfig: map[string]LanguageConfig{
+			"text": {
+				VersionFiles: []VersionFile{
+					{Path: versionFilePath, Patterns: []string{`(__version__ = ")\d+\.\d+\.\d+(")`}},
+				},
+			},
+		},
+	}
+	projectConfig := &ProjectConfig{Path: projectPath, Name: "project", Language: "text"}
+	return globalConfig, projectConfig
+}
+
+func TestGetVersionFiles_SkipsIgnoredGlobMatches(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newIgnoredVersionFilesFixture(t, "*/version.py")
+	state := &projectState{}
+
+	// Act
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, state, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, versionFiles, 1)
+	assert.Equal(t, filepath.Join(projectConfig.Path, "src", "version.py"), versionFiles[0].Path)
+	assert.Equal(t, []string{"build/version.py"}, state.ignoredVersionFiles)
+
+	// the skip is only recorded once, while the version files are resolved several times
+	_, err = getVersionFiles(globalConfig, projectConfig, state, standardLogEntry())
+	require.NoError(t, err)
+	assert.Len(t, state.ignoredVersionFiles, 1)
+}
+
+func TestGetVersionFiles_KeepsIgnoredFilesListedExplicitly(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newIgnoredVersionFilesFixture(t, "build/version.py")
+	state := &projectState{}
+
+	// Act
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, state, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, versionFiles, 1)
+	assert.Equal(t, filepath.Join(projectConfig.Path, "build", "version.py"), versionFiles[0].Path)
+	assert.Empty(t, state.ignoredVersionFiles)
+}
+
+func TestGetVersionFiles_AllowsIgnoredFiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newIgnoredVersionFilesFixture(t, "*/version.py")
+	projectConfig.AllowIgnoredVersionFiles = true
+	state := &projectState{}
+
+	// Act
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, state, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, versionFiles, 2)
+	assert.Empty(t, state.ignoredVersionFiles)
+}
+
+func TestFindIgnorePattern(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repoPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, ".git", "info"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".git", "info", "exclude"), []byte("*.local\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".gitignore"), []byte("*.gen\n!keep.gen\n"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(repoPath, "sub"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "sub", ".gitignore"), []byte("version.txt\n"), 0o600))
+
+	patterns, err := readIgnorePatterns(repoPath)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "version.gen", expected: "*.gen (.gitignore:1)"},
+		{path: "keep.gen", expected: ""},
+		{path: "settings.local", expected: "*.local (.git/info/exclude:1)"},
+		{path: "sub/version.txt", expected: "version.txt (sub/.gitignore:1)"},
+		{path: "version.txt", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			source := findIgnorePattern(patterns, repoPath, filepath.Join(repoPath, filepath.FromSlash(test.path)))
+
+			// Assert
+			assert.Equal(t, test.expected, source)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
@@ -20,15 +22,18 @@ var (
 //
 //	(interface with many providers and implementing the methods)
 //
-// createGitLabMergeRequest creates a new merge request on GitLab
+// createGitLabMergeRequest creates a new merge request on GitLab, returning its URL
 func createGitLabMergeRequest(
 	globalConfig *GlobalConfig,
 	projectConfig *ProjectConfig,
+	state *projectState,
 	repo *git.Repository,
 	sourceBranch string,
+	targetBranch string,
 	newVersion string,
-) error {
-	log.Info("Creating GitLab merge request")
+	logger *log.Entry,
+) (string, error) {
+	logger.Info("Creating GitLab merge request")
 
 	var accessToken string
 	if projectConfig.ProjectAccessToken != "" {
@@ -37,38 +42,154 @@ func createGitLabMergeRequest(
 		accessToken = globalConfig.GitLabAccessToken
 	}
 
-	gitlabClient, err := gitlab.NewClient(accessToken)
+	gitlabClient, err := newGitLabClient(globalConfig, repo, accessToken)
 	if err != nil {
-		return fmt.Errorf("failed to create GitLab client: %w", err)
+		return "", fmt.Errorf("failed to create GitLab client: %w", err)
 	}
 
 	// Get the project owner and name
 	projectName, err := getRemoteRepoFullProjectName(repo)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Get the project ID using the GitLab API
-	project, _, err := gitlabClient.Projects.GetProject(projectName, &gitlab.GetProjectOptions{})
+	metadata, err := getGitLabRepoMetadata(globalConfig, gitlabClient, projectName)
+	if err != nil {
+		return "", err
+	}
+	projectID, err := strconv.Atoi(metadata.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get project ID: %w", err)
+		return "", fmt.Errorf("invalid GitLab project ID %q: %w", metadata.ID, err)
 	}
-	projectID := project.ID
 
-	mrTitle := "chore(bump): bumped version to " + newVersion
+	mergeRequestOptions := buildGitLabMergeRequestOptions(
+		sourceBranch,
+		targetBranch,
+		getPullRequestTitle(globalConfig, projectConfig, state, newVersion, logger),
+		getPullRequestDescription(globalConfig, projectConfig, state, logger),
+	)
+	mergeRequest, response, err := gitlabClient.MergeRequests.CreateMergeRequest(projectID, mergeRequestOptions)
+	if err != nil {
+		return "", classifyPullRequestError(
+			getGitLabStatusCode(response), fmt.Errorf("failed to create merge request: %w", err),
+		)
+	}
+	followUpGitLabMergeRequest(gitlabClient, projectConfig, state, projectID, mergeRequest.IID, logger)
+	postGitLabLintSuggestions(gitlabClient, projectConfig, state, projectID, mergeRequest, logger)
+	enableGitLabAutoMerge(gitlabClient, projectConfig, state, projectID, mergeRequest, logger)
+	state.pullRequest = newGitLabPullRequestHandle(gitlabClient, projectID, mergeRequest.IID)
+	return mergeRequest.WebURL, nil
+}
 
-	mergeRequestOptions := &gitlab.CreateMergeRequestOptions{
+// newGitLabPullRequestHandle reaches the merge request once created
+func newGitLabPullRequestHandle(gitlabClient *gitlab.Client, projectID int, mergeRequestIID int) *pullRequestHandle {
+	return &pullRequestHandle{
+		getMergeability: func() (string, error) {
+			mergeRequest, _, err := gitlabClient.MergeRequests.GetMergeRequest(projectID, mergeRequestIID, nil)
+			if err != nil {
+				return "", fmt.Errorf("failed to get the merge request: %w", err)
+			}
+			return getGitLabMergeability(mergeRequest), nil
+		},
+		update: func(title string, description string) error {
+			_, _, err := gitlabClient.MergeRequests.UpdateMergeRequest(
+				projectID,
+				mergeRequestIID,
+				&gitlab.UpdateMergeRequestOptions{Title: gitlab.Ptr(title), Description: gitlab.Ptr(description)},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to update the merge request: %w", err)
+			}
+			return nil
+		},
+		pollInterval: mergeabilityPollInterval,
+	}
+}
+
+// getGitLabMergeability reads the mergeability from the detailed merge status of the merge request,
+// or from the merge status of the GitLab versions without it
+func getGitLabMergeability(mergeRequest *gitlab.MergeRequest) string {
+	switch mergeRequest.DetailedMergeStatus {
+	case "unchecked", "checking", "preparing", "approvals_syncing":
+		return mergeabilityChecking
+	case "conflict", "broken_status":
+		return mergeabilityConflicted
+	case "":
+		switch mergeRequest.MergeStatus {
+		case "unchecked", "checking", "cannot_be_merged_recheck":
+			return mergeabilityChecking
+		case "cannot_be_merged":
+			return mergeabilityConflicted
+		case "can_be_merged":
+			return mergeabilityMergeable
+		default:
+			return mergeabilityUnknown
+		}
+	default:
+		// the other statuses (e.g. not_approved) don't prevent the merge because of the changes
+		if mergeRequest.HasConflicts {
+			return mergeabilityConflicted
+		}
+		return mergeabilityMergeable
+	}
+}
+
+// getGitLabRepoMetadata returns the metadata of the project, fetched once per run
+func getGitLabRepoMetadata(
+	globalConfig *GlobalConfig,
+	gitlabClient *gitlab.Client,
+	projectName string,
+) (RepoMetadata, error) {
+	key := repoMetadataKey{host: gitlabClient.BaseURL().Host, repository: projectName}
+	return globalConfig.repoMetadata.get(key, func() (RepoMetadata, error) {
+		project, response, err := gitlabClient.Projects.GetProject(projectName, &gitlab.GetProjectOptions{})
+		if err != nil {
+			return RepoMetadata{}, classifyPullRequestError(
+				getGitLabStatusCode(response), fmt.Errorf("failed to get project ID: %w", err),
+			)
+		}
+		return RepoMetadata{ID: strconv.Itoa(project.ID), DefaultBranch: project.DefaultBranch}, nil
+	})
+}
+
+// getGitLabStatusCode returns the HTTP status code of the GitLab API response, 0 when there is no response
+func getGitLabStatusCode(response *gitlab.Response) int {
+	if response == nil || response.Response == nil {
+		return 0
+	}
+	return response.StatusCode
+}
+
+// buildGitLabMergeRequestOptions builds the payload of the merge request bumping the version
+func buildGitLabMergeRequestOptions(
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) *gitlab.CreateMergeRequestOptions {
+	options := &gitlab.CreateMergeRequestOptions{
 		SourceBranch:       gitlab.Ptr(sourceBranch),
-		TargetBranch:       gitlab.Ptr("main"),
-		Title:              &mrTitle,
+		TargetBranch:       gitlab.Ptr(targetBranch),
+		Title:              gitlab.Ptr(title),
 		RemoveSourceBranch: gitlab.Ptr(true),
 	}
+	if description != "" {
+		options.Description = gitlab.Ptr(description)
+	}
+	return options
+}
 
-	_, _, err = gitlabClient.MergeRequests.CreateMergeRequest(projectID, mergeRequestOptions)
-	if err != nil {
-		return fmt.Errorf("failed to create merge request: %w", err)
+// newGitLabClient creates the client of the GitLab API hosting the repository,
+// the one of its self-hosted instance when it is configured in "providers"
+func newGitLabClient(globalConfig *GlobalConfig, repo *git.Repository, accessToken string) (*gitlab.Client, error) {
+	options := []gitlab.ClientOptionFunc{gitlab.WithHTTPClient(newAPIClient(globalConfig))}
+	if remoteURL, err := getRemoteRepoURL(repo); err == nil {
+		if baseURL := getProviderBaseURL(remoteURL); baseURL != "" {
+			options = append(options, gitlab.WithBaseURL(baseURL))
+		}
 	}
-	return nil
+	return gitlab.NewClient(accessToken, options...)
 }
 
 // getRemoteRepoFullProjectName returns the full project name of the remote repository
@@ -83,18 +204,20 @@ func getRemoteRepoFullProjectName(repo *git.Repository) (string, error) {
 
 	var fullProjectName string
 	switch {
-	case strings.HasPrefix(trimmedURL, "git@"):
-		parts := strings.Split(trimmedURL, ":")
-		if len(parts) == 2 { //nolint:mnd // 2 is the minimum number of parts
-			fullProjectName = parts[len(parts)-1]
-		} else {
+	case isSSHURL(trimmedURL):
+		// e.g. git@gitlab.com:group/project or ssh://git@gitlab.company.io:2222/group/project
+		_, path, found := parseSSHURL(trimmedURL)
+		if !found || !strings.Contains(path, "/") {
 			return "", ErrInvalidSSHRepoURL
 		}
+		fullProjectName = path
 	case strings.HasPrefix(trimmedURL, "https://"):
-		parts := strings.Split(trimmedURL, "/")
-		if len(parts) >= 4 { //nolint:mnd // 4 is the minimum number of parts
-			fullProjectName = parts[len(parts)-1]
-		} else {
+		uri, err := url.Parse(trimmedURL)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrCannotParseRepoURL, err)
+		}
+		fullProjectName = trimProviderBasePath(remoteURL, strings.Trim(uri.Path, "/"))
+		if !strings.Contains(fullProjectName, "/") {
 			return "", ErrCannotParseRepoURL
 		}
 	default:
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/xanzy/go-gitlab"
+)
+
+// PullRequestApprovals is the approval state of a pull request after its creation
+type PullRequestApprovals struct {
+	Required int `json:"required"`
+	Given    int `json:"given"`
+}
+
+// getMissingApprovals returns how many approvals the pull request still needs before being merged
+func (a *PullRequestApprovals) getMissingApprovals() int {
+	if a == nil || a.Given >= a.Required {
+		return 0
+	}
+	return a.Required - a.Given
+}
+
+// followUpGitLabMergeRequest asks for the review of the merge request and reads its approval state.
+// Each step is best effort: the merge request already exists, so the failures are only logged.
+func followUpGitLabMergeRequest(
+	gitlabClient *gitlab.Client,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	projectID int,
+	mergeRequestIID int,
+	logger *log.Entry,
+) {
+	if len(projectConfig.Reviewers) > 0 {
+		err := requestGitLabReviews(gitlabClient, projectID, mergeRequestIID, projectConfig.Reviewers, logger)
+		if err != nil {
+			logger.Warnf("Unable to request the reviews of the merge request: %v", err)
+		}
+	}
+
+	if projectConfig.NotifyGroup != "" {
+		err := notifyGitLabGroup(gitlabClient, projectID, mergeRequestIID, projectConfig.NotifyGroup)
+		if err != nil {
+			logger.Warnf("Unable to notify %s in the merge request: %v", projectConfig.NotifyGroup, err)
+		}
+	}
+
+	approvals, err := getGitLabApprovals(gitlabClient, projectID, mergeRequestIID)
+	if err != nil {
+		logger.Warnf("Unable to read the approvals of the merge request: %v", err)
+		return
+	}
+	state.pullRequestApprovals = approvals
+	if missing := approvals.getMissingApprovals(); missing > 0 {
+		logger.Infof("The merge request needs %d more approval(s) (%d required)", missing, approvals.Required)
+	}
+}
+
+// requestGitLabReviews sets the reviewers of the merge request, resolving their usernames to user IDs
+func requestGitLabReviews(
+	gitlabClient *gitlab.Client, projectID int, mergeRequestIID int, reviewers []string, logger *log.Entry,
+) error {
+	reviewerIDs := make([]int, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		username := strings.TrimPrefix(reviewer, "@")
+		users, _, err := gitlabClient.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(username)})
+		if err != nil {
+			return fmt.Errorf("failed to find user %s: %w", username, err)
+		}
+		if len(users) == 0 {
+			logger.Warnf("Reviewer %s not found, skipping it", username)
+			continue
+		}
+		reviewerIDs = append(reviewerIDs, users[0].ID)
+	}
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+
+	_, _, err := gitlabClient.MergeRequests.UpdateMergeRequest(
+		projectID, mergeRequestIID, &gitlab.UpdateMergeRequestOptions{ReviewerIDs: &reviewerIDs},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set the reviewers: %w", err)
+	}
+	return nil
+}
+
+// notifyGitLabGroup starts a thread in the merge request mentioning the group, whose members are notified
+func notifyGitLabGroup(gitlabClient *gitlab.Client, projectID int, mergeRequestIID int, group string) error {
+	body := fmt.Sprintf("@%s this release is waiting for your approval.", strings.TrimPrefix(group, "@"))
+	_, _, err := gitlabClient.Discussions.CreateMergeRequestDiscussion(
+		projectID, mergeRequestIID, &gitlab.CreateMergeRequestDiscussionOptions{Body: gitlab.Ptr(body)},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create the thread: %w", err)
+	}
+	return nil
+}
+
+// getGitLabApprovals reads how many approvals the merge request requires and how many it has
+func getGitLabApprovals(
+	gitlabClient *gitlab.Client,
+	projectID int,
+	mergeRequestIID int,
+) (*PullRequestApprovals, error) {
+	approvals, _, err := gitlabClient.MergeRequestApprovals.GetConfiguration(projectID, mergeRequestIID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the approvals: %w", err)
+	}
+	return &PullRequestApprovals{Required: approvals.ApprovalsRequired, Given: len(approvals.ApprovedBy)}, nil
+}
+
+// postGitLabLintSuggestions starts a thread on each CHANGELOG line with a lint suggestion, applied with one click.
+// The diff of the merge request is addressed by the commits of the bump when GitLab didn't compute it yet.
+func postGitLabLintSuggestions(
+	gitlabClient *gitlab.Client,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	projectID int,
+	mergeRequest *gitlab.MergeRequest,
+	logger *log.Entry,
+) {
+	suggestions := state.lintSuggestions
+	if suggestions == nil {
+		return
+	}
+
+	baseSHA, startSHA, headSHA := suggestions.baseSHA, suggestions.baseSHA, suggestions.headSHA
+	if refs := mergeRequest.DiffRefs; refs.HeadSha != "" {
+		baseSHA, startSHA, headSHA = refs.BaseSha, refs.StartSha, refs.HeadSha
+	}
+	for _, suggestion := range suggestions.additions {
+		_, _, err := gitlabClient.Discussions.CreateMergeRequestDiscussion(
+			projectID, mergeRequest.IID, &gitlab.CreateMergeRequestDiscussionOptions{
+				Body: gitlab.Ptr(formatLintSuggestion(suggestion)),
+				Position: &gitlab.PositionOptions{
+					BaseSHA:      gitlab.Ptr(baseSHA),
+					StartSHA:     gitlab.Ptr(startSHA),
+					HeadSHA:      gitlab.Ptr(headSHA),
+					OldPath:      gitlab.Ptr(suggestions.path),
+					NewPath:      gitlab.Ptr(suggestions.path),
+					PositionType: gitlab.Ptr("text"),
+					NewLine:      gitlab.Ptr(suggestion.Line),
+				},
+			},
+		)
+		if err != nil {
+			logger.Warnf("Unable to suggest the fix of line %d of %s: %v", suggestion.Line, suggestions.path, err)
+		}
+	}
+}
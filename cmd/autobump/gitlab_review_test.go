@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// gitLabMockRequest is a request received by the GitLab mock
+type gitLabMockRequest struct {
+	method string
+	path   string
+	query  string
+	body   string
+}
+
+// newGitLabMock creates a GitLab client sending the requests to a local server answering with the response of
+// the "METHOD path" route, or a 404 otherwise. It also returns the received requests.
+func newGitLabMock(t *testing.T, routes map[string]string) (*gitlab.Client, func() []gitLabMockRequest) {
+	t.Helper()
+
+	var mutex sync.Mutex
+	var requests []gitLabMockRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		mutex.Lock()
+		requests = append(requests, gitLabMockRequest{
+			method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, body: string(body),
+		})
+		mutex.Unlock()
+
+		response, found := routes[r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v4")]
+		w.Header().Set("Content-Type", "application/json")
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "404 Not Found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gitlab.NewClient("glpat-secret", gitlab.WithBaseURL(server.URL))
+	require.NoError(t, err)
+	return client, func() []gitLabMockRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]gitLabMockRequest{}, requests...)
+	}
+}
+
+func TestFollowUpGitLabMergeRequest_RequestsReviewsNotifiesAndReadsApprovals(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /users":                                     `[{"id": 7, "username": "alice"}]`,
+		"PUT /projects/42/merge_requests/3":              `{"iid": 3}`,
+		"POST /projects/42/merge_requests/3/discussions": `{"id": "abc"}`,
+		"GET /projects/42/merge_requests/3/approvals":    `{"approvals_required": 2, "approved_by": [{"user": {"id": 9}}]}`,
+	})
+	projectConfig := &ProjectConfig{Reviewers: []string{"@alice"}, NotifyGroup: "@release-approvers"}
+	state := &projectState{}
+
+	// Act
+	followUpGitLabMergeRequest(client, projectConfig, state, 42, 3, standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, "username=alice", requests[0].query)
+
+	var update map[string][]int
+	require.NoError(t, json.Unmarshal([]byte(requests[1].body), &update))
+	assert.Equal(t, []int{7}, update["reviewer_ids"])
+
+	var discussion map[string]string
+	require.NoError(t, json.Unmarshal([]byte(requests[2].body), &discussion))
+	assert.Equal(t, "@release-approvers this release is waiting for your approval.", discussion["body"])
+
+	assert.Equal(t, &PullRequestApprovals{Required: 2, Given: 1}, state.pullRequestApprovals)
+	assert.Equal(t, 1, state.pullRequestApprovals.getMissingApprovals())
+}
+
+func TestFollowUpGitLabMergeRequest_OnlyReadsApprovalsWithoutReviewers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /projects/42/merge_requests/3/approvals": `{"approvals_required": 0, "approved_by": []}`,
+	})
+	projectConfig := &ProjectConfig{}
+	state := &projectState{}
+
+	// Act
+	followUpGitLabMergeRequest(client, projectConfig, state, 42, 3, standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/api/v4/projects/42/merge_requests/3/approvals", requests[0].path)
+	assert.Equal(t, 0, state.pullRequestApprovals.getMissingApprovals())
+}
+
+func TestFollowUpGitLabMergeRequest_SkipsUnknownReviewers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"GET /users": `[]`,
+		"GET /projects/42/merge_requests/3/approvals": `{"approvals_required": 1, "approved_by": []}`,
+	})
+	projectConfig := &ProjectConfig{Reviewers: []string{"ghost"}}
+	state := &projectState{}
+
+	// Act
+	followUpGitLabMergeRequest(client, projectConfig, state, 42, 3, standardLogEntry())
+
+	// Assert
+	for _, request := range getRequests() {
+		assert.NotEqual(t, http.MethodPut, request.method, "no reviewer is set")
+	}
+	assert.Equal(t, 1, state.pullRequestApprovals.getMissingApprovals())
+}
+
+func TestFollowUpGitLabMergeRequest_KeepsGoingWhenTheApprovalsAreUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"POST /projects/42/merge_requests/3/discussions": `{"id": "abc"}`,
+	})
+	projectConfig := &ProjectConfig{NotifyGroup: "release-approvers"}
+	state := &projectState{}
+
+	// Act
+	followUpGitLabMergeRequest(client, projectConfig, state, 42, 3, standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 2)
+	assert.Contains(t, requests[0].body, "@release-approvers")
+	assert.Nil(t, state.pullRequestApprovals)
+}
+
+func TestRenderDigest_FlagsMergeRequestsNeedingApprovals(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	required, given := 2, 0
+	results := []ProjectResult{{
+		Name:              "payments",
+		Forge:             "gitlab.com",
+		PreviousVersion:   "1.0.0",
+		NextVersion:       "1.1.0",
+		Bump:              "minor",
+		ApprovalsRequired: &required,
+		Approvals:         &given,
+	}}
+
+	// Act
+	digest := renderDigest(results)
+
+	// Assert
+	assert.Contains(t, digest, "- payments 1.0.0 → 1.1.0 (minor) (needs 2 more approvals)\n")
+}
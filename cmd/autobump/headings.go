@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// modes of the section headings written in the released versions
+const (
+	outputHeadingsPreserve = "preserve" // keep the headings in the language they were written (e.g. "Añadido")
+	outputHeadingsEnglish  = "english"  // write the Keep a Changelog headings (e.g. "Added")
+)
+
+var ErrInvalidSectionAlias = errors.New("invalid section alias")
+
+// anyHeadingRegex matches the headings with any level, capturing the heading after the hashes
+var anyHeadingRegex = regexp.MustCompile(`^\s*#+\s*(\S.*)$`)
+
+// builtinSectionAliases are the translations of the Keep a Changelog sections in Spanish, Portuguese,
+// German, French and Italian
+var builtinSectionAliases = map[string][]string{
+	"Added":      {"Añadido", "Agregado", "Adicionado", "Hinzugefügt", "Ajouté", "Aggiunto"},
+	"Changed":    {"Cambiado", "Modificado", "Alterado", "Geändert", "Modifié", "Modificato"},
+	"Deprecated": {"Obsoleto", "Obsoletos", "Veraltet", "Déprécié", "Obsolète", "Deprecato"},
+	"Removed":    {"Eliminado", "Removido", "Entfernt", "Supprimé", "Rimosso"},
+	"Fixed":      {"Corregido", "Arreglado", "Corrigido", "Behoben", "Corrigé", "Corretto"},
+	"Security":   {"Seguridad", "Segurança", "Sicherheit", "Sécurité", "Sicurezza"},
+}
+
+// sectionAlias is a heading recognized as another section (e.g. "Añadido" as "Added")
+type sectionAlias struct {
+	alias   string
+	section string
+}
+
+// getSectionAliases returns the built-in translations of the sections along with the configured aliases,
+// which take precedence. The longest aliases come first, so they are matched before their prefixes.
+func getSectionAliases(changelogConfig ChangelogConfig) []sectionAlias {
+	sections := make(map[string]string)
+	for section, aliases := range builtinSectionAliases {
+		for _, alias := range aliases {
+			sections[strings.ToLower(alias)] = section
+		}
+	}
+	aliasNames := make(map[string]string, len(sections)+len(changelogConfig.SectionAliases))
+	for alias := range sections {
+		aliasNames[alias] = alias
+	}
+	for alias, section := range changelogConfig.SectionAliases {
+		sections[strings.ToLower(alias)] = section
+		aliasNames[strings.ToLower(alias)] = alias
+	}
+
+	aliases := make([]sectionAlias, 0, len(sections))
+	for key, section := range sections {
+		aliases = append(aliases, sectionAlias{alias: aliasNames[key], section: section})
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		if len(aliases[i].alias) != len(aliases[j].alias) {
+			return len(aliases[i].alias) > len(aliases[j].alias)
+		}
+		return aliases[i].alias < aliases[j].alias
+	})
+	return aliases
+}
+
+// validateSectionAliases checks that the configured aliases name a Keep a Changelog or a non-bumping section
+func validateSectionAliases(changelogConfig ChangelogConfig) error {
+	switch changelogConfig.OutputHeadings {
+	case "", outputHeadingsPreserve, outputHeadingsEnglish:
+	default:
+		return fmt.Errorf(
+			"%w: output_headings %q (expected %s or %s)",
+			ErrInvalidSectionAlias, changelogConfig.OutputHeadings, outputHeadingsPreserve, outputHeadingsEnglish,
+		)
+	}
+
+	for alias, section := range changelogConfig.SectionAliases {
+		if !slices.Contains(changelogSectionsOrder, section) &&
+			!slices.Contains(changelogConfig.NonBumpingSections, section) {
+			return fmt.Errorf(
+				"%w: %q is an alias of %q, which is neither a Keep a Changelog section nor a non-bumping section",
+				ErrInvalidSectionAlias, alias, section,
+			)
+		}
+	}
+	return nil
+}
+
+// findSectionAlias returns the alias the heading starts with, matched as a whole word and regardless of the case
+func findSectionAlias(heading string, aliases []sectionAlias) (sectionAlias, bool) {
+	for _, alias := range aliases {
+		if len(heading) < len(alias.alias) || !strings.EqualFold(heading[:len(alias.alias)], alias.alias) {
+			continue
+		}
+		next, _ := utf8.DecodeRuneInString(heading[len(alias.alias):])
+		if next == utf8.RuneError || (!unicode.IsLetter(next) && !unicode.IsDigit(next)) {
+			return alias, true
+		}
+	}
+	return sectionAlias{}, false
+}
+
+// getOutputHeadings returns the headings written in the released version for the sections whose heading was
+// translated, or nil when the headings are written in English
+func getOutputHeadings(changelogConfig ChangelogConfig, translatedHeadings map[string]string) map[string]string {
+	if changelogConfig.OutputHeadings == outputHeadingsEnglish {
+		return nil
+	}
+	return translatedHeadings
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogSpanish = changelogTemplate + `
+
+### Añadido
+
+- Se añadió la exportación de los informes.
+
+### Corregido
+
+- Se corrigió la redirección del inicio de sesión.
+
+## [1.0.1] - 1984-01-01
+
+### Añadido
+
+- Se añadió el inicio de sesión.`
+
+func TestProcessChangelog_PreservesTranslatedHeadings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogSpanish, "\n")
+	changelogConfig := ChangelogConfig{Date: time.Date(1984, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", version.String())
+	content := strings.Join(newChangelog, "\n")
+	assert.Contains(t, content, "## [1.1.0] - 1984-01-02\n\n### Añadido\n\n- Se añadió la exportación de los informes.")
+	assert.Contains(t, content, "### Corregido\n\n- Se corrigió la redirección del inicio de sesión.")
+	assert.NotContains(t, content, "### Added")
+}
+
+func TestProcessChangelog_WritesEnglishHeadings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogSpanish, "\n")
+	changelogConfig := ChangelogConfig{
+		OutputHeadings: outputHeadingsEnglish,
+		Date:           time.Date(1984, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", version.String())
+	content := strings.Join(newChangelog, "\n")
+	assert.Contains(t, content, "## [1.1.0] - 1984-01-02\n\n### Added\n\n- Se añadió la exportación de los informes.")
+	assert.Contains(t, content, "### Fixed\n\n- Se corrigió la redirección del inicio de sesión.")
+}
+
+func TestGetUnreleasedSummary_ConfiguredSectionAlias(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(strings.Replace(changelogSpanish, "### Añadido", "### Novedades", 1), "\n")
+	changelogConfig := ChangelogConfig{SectionAliases: map[string]string{"Novedades": "Added"}}
+
+	// Act
+	summary, err := getUnreleasedSummary(changelog, changelogConfig)
+	unknown, unknownErr := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, unknownErr)
+	assert.Equal(t, []string{"- Se añadió la exportación de los informes."}, summary.SectionEntries["Added"])
+	assert.Empty(t, unknown.SectionEntries["Added"])
+}
+
+func TestGetUnreleasedSummary_EmptyTranslatedSections(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogTemplate+`
+
+### Añadido
+
+### Corregido
+
+## [1.0.1] - 1984-01-01
+
+### Añadido
+
+- Se añadió el inicio de sesión.`, "\n")
+
+	// Act
+	summary, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, summary.Empty)
+}
+
+func TestValidateSectionAliases(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	valid := ChangelogConfig{
+		SectionAliases:     map[string]string{"Novedades": "Added", "Interno": "Internal"},
+		NonBumpingSections: []string{"Internal"},
+		OutputHeadings:     outputHeadingsEnglish,
+	}
+	unknownSection := ChangelogConfig{SectionAliases: map[string]string{"Novedades": "News"}}
+	unknownMode := ChangelogConfig{OutputHeadings: "spanish"}
+
+	// Act
+	validErr := validateSectionAliases(valid)
+	unknownSectionErr := validateSectionAliases(unknownSection)
+	unknownModeErr := validateSectionAliases(unknownMode)
+
+	// Assert
+	require.NoError(t, validErr)
+	require.ErrorIs(t, unknownSectionErr, ErrInvalidSectionAlias)
+	require.ErrorIs(t, unknownModeErr, ErrInvalidSectionAlias)
+}
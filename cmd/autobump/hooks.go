@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	gitHooksNone      = "false"
+	gitHooksCommitMsg = "commit-msg"
+	gitHooksAll       = "all"
+
+	// maximum time a hook can run before the bump is aborted
+	gitHookTimeout = 2 * time.Minute
+)
+
+var (
+	ErrInvalidGitHooksMode = errors.New("invalid run_git_hooks mode")
+	ErrGitHookRejected     = errors.New("the Git hook rejected the commit")
+)
+
+// validateGitHooksMode validates which Git hooks run before the bump commit
+func validateGitHooksMode(mode string) error {
+	switch mode {
+	case "", gitHooksNone, gitHooksCommitMsg, gitHooksAll:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: %q (expected %q, %q or %q)", ErrInvalidGitHooksMode, mode, gitHooksNone, gitHooksCommitMsg, gitHooksAll,
+		)
+	}
+}
+
+// getHooksDir returns the directory of the Git hooks: the "core.hooksPath" of the repository or the global
+// configuration (relative to the project root), or the "hooks" directory inside ".git"
+func getHooksDir(repoCfg, globalGitConfig *config.Config, projectPath string) string {
+	hooksPath := getOptionFromConfig(repoCfg, globalGitConfig, "core", "hooksPath")
+	if hooksPath == "" {
+		return filepath.Join(projectPath, ".git", "hooks")
+	}
+
+	if strings.HasPrefix(hooksPath, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			hooksPath = filepath.Join(homeDir, hooksPath[2:])
+		}
+	}
+	if !filepath.IsAbs(hooksPath) {
+		hooksPath = filepath.Join(projectPath, hooksPath)
+	}
+	return hooksPath
+}
+
+// runCommitHooks runs the Git hooks enabled by the mode before committing the message, like "git commit" would.
+// The changes made to the message by the "commit-msg" hook are not applied.
+func runCommitHooks(mode string, hooksDir string, projectPath string, commitMessage string, logger *log.Entry) error {
+	if mode == "" || mode == gitHooksNone {
+		return nil
+	}
+
+	if mode == gitHooksAll {
+		err := runGitHook(hooksDir, "pre-commit", projectPath, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	messageFile, err := os.CreateTemp("", "autobump-commit-msg-")
+	if err != nil {
+		return fmt.Errorf("failed to create the commit message file: %w", err)
+	}
+	defer os.Remove(messageFile.Name())
+
+	_, err = messageFile.WriteString(commitMessage + "\n")
+	if closeErr := messageFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the commit message file: %w", err)
+	}
+
+	return runGitHook(hooksDir, "commit-msg", projectPath, logger, messageFile.Name())
+}
+
+// runGitHook runs a hook inside the project, logging its output. Missing and non-executable hooks are skipped.
+func runGitHook(hooksDir string, name string, projectPath string, logger *log.Entry, args ...string) error {
+	hookPath := filepath.Join(hooksDir, name)
+	info, err := os.Stat(hookPath)
+	if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+		logger.Debugf("Skipping the %s hook, it isn't an executable at %s", name, hookPath)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gitHookTimeout)
+	defer cancel()
+
+	logger.Infof("Running the %s hook", name)
+	cmd := exec.CommandContext(ctx, hookPath, args...)
+	cmd.Dir = projectPath
+	output, err := cmd.CombinedOutput()
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		logger.Infof("[%s] %s", name, scanner.Text())
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %s timed out after %s", ErrGitHookRejected, name, gitHookTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrGitHookRejected, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGitHooksMode(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	for _, mode := range []string{"", gitHooksNone, gitHooksCommitMsg, gitHooksAll} {
+		require.NoError(t, validateGitHooksMode(mode), mode)
+	}
+	require.ErrorIs(t, validateGitHooksMode("pre-push"), ErrInvalidGitHooksMode)
+}
+
+func TestDecodeConfig_RunGitHooksFalse(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	globalConfig, err := decodeConfig([]byte("run_git_hooks: false\n"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, gitHooksNone, globalConfig.RunGitHooks)
+}
+
+func TestGetHooksDir_Default(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+
+	// Act
+	hooksDir := getHooksDir(config.NewConfig(), config.NewConfig(), projectPath)
+
+	// Assert
+	assert.Equal(t, filepath.Join(projectPath, ".git", "hooks"), hooksDir)
+}
+
+func TestGetHooksDir_RepositoryHooksPathFirst(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	repoCfg := config.NewConfig()
+	repoCfg.Raw.Section("core").SetOption("hooksPath", ".husky")
+	globalCfg := config.NewConfig()
+	globalCfg.Raw.Section("core").SetOption("hooksPath", "/etc/git/hooks")
+
+	// Act
+	hooksDir := getHooksDir(repoCfg, globalCfg, projectPath)
+	globalHooksDir := getHooksDir(config.NewConfig(), globalCfg, projectPath)
+
+	// Assert
+	assert.Equal(t, filepath.Join(projectPath, ".husky"), hooksDir)
+	assert.Equal(t, "/etc/git/hooks", globalHooksDir)
+}
@@ -0,0 +1,115 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ticketHook rejects the commit messages without a ticket reference (e.g. "ABC-123")
+const ticketHook = `#!/bin/sh
+if ! grep -qE '[A-Z]+-[0-9]+' "$1"; then
+  echo "missing ticket reference"
+  exit 1
+fi
+`
+
+// newHooksFixture creates a project with the hooks in its ".git/hooks" directory
+func newHooksFixture(t *testing.T, hooks map[string]string) (string, string) {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	hooksDir := filepath.Join(projectPath, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hooksDir, 0o755))
+	for name, content := range hooks {
+		require.NoError(t, os.WriteFile(filepath.Join(hooksDir, name), []byte(content), 0o700)) //nolint:gosec // hooks must be executable
+	}
+	return projectPath, hooksDir
+}
+
+func TestRunCommitHooks_CommitMsgRejects(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, hooksDir := newHooksFixture(t, map[string]string{"commit-msg": ticketHook})
+
+	// Act
+	err := runCommitHooks(
+		gitHooksCommitMsg, hooksDir, projectPath, "chore(bump): bumped version to 1.1.0", standardLogEntry(),
+	)
+
+	// Assert
+	require.ErrorIs(t, err, ErrGitHookRejected)
+	assert.Contains(t, err.Error(), "commit-msg")
+}
+
+func TestRunCommitHooks_CommitMsgAccepts(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, hooksDir := newHooksFixture(t, map[string]string{"commit-msg": ticketHook})
+
+	// Act
+	err := runCommitHooks(
+		gitHooksCommitMsg, hooksDir, projectPath, "chore(bump): bumped version to 1.1.0 (OPS-42)", standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+}
+
+func TestRunCommitHooks_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, hooksDir := newHooksFixture(t, map[string]string{"commit-msg": ticketHook})
+
+	// Act
+	err := runCommitHooks("", hooksDir, projectPath, "chore(bump): bumped version to 1.1.0", standardLogEntry())
+	disabledErr := runCommitHooks(
+		gitHooksNone, hooksDir, projectPath, "chore(bump): bumped version to 1.1.0", standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, disabledErr)
+}
+
+func TestRunCommitHooks_PreCommitOnlyWithAll(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, hooksDir := newHooksFixture(t, map[string]string{"pre-commit": "#!/bin/sh\nexit 1\n"})
+
+	// Act
+	commitMsgErr := runCommitHooks(
+		gitHooksCommitMsg, hooksDir, projectPath, "chore(bump): bumped version to 1.1.0", standardLogEntry(),
+	)
+	allErr := runCommitHooks(
+		gitHooksAll, hooksDir, projectPath, "chore(bump): bumped version to 1.1.0", standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, commitMsgErr)
+	require.ErrorIs(t, allErr, ErrGitHookRejected)
+	assert.Contains(t, allErr.Error(), "pre-commit")
+}
+
+func TestRunGitHook_SkipsNonExecutable(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, hooksDir := newHooksFixture(t, nil)
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "commit-msg"), []byte("#!/bin/sh\nexit 1\n"), 0o600))
+
+	// Act
+	err := runGitHook(hooksDir, "commit-msg", projectPath, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+}
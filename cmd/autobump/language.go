@@ -4,8 +4,21 @@ type Language interface {
 	GetProjectName() (string, error)
 }
 
+// VersionFilesLanguage is implemented by the languages that find their version files by parsing the project,
+// these files are updated in addition to the version files of the configuration
+type VersionFilesLanguage interface {
+	GetVersionFiles() ([]VersionFile, error)
+}
+
 func getLanguageInterface(projectConfig ProjectConfig, languageInterface *Language) {
-	if projectConfig.Language == "python" {
+	switch projectConfig.Language {
+	case "python":
 		*languageInterface = &Python{ProjectConfig: projectConfig}
+	case "elixir":
+		*languageInterface = &Elixir{ProjectConfig: projectConfig}
+	case "erlang":
+		*languageInterface = &Erlang{ProjectConfig: projectConfig}
+	case "typescript":
+		*languageInterface = &TypeScript{ProjectConfig: projectConfig}
 	}
 }
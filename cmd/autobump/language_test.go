@@ -0,0 +1,237 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLanguageFixture copies a project of testdata into a temporary directory, using the default languages
+func newLanguageFixture(t *testing.T, fixture string, language string) (*GlobalConfig, *ProjectConfig) {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	require.NoError(t, os.CopyFS(projectPath, os.DirFS(filepath.Join("testdata", fixture))))
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "configs", "autobump.yaml"))
+	require.NoError(t, err)
+	globalConfig, err := decodeConfig(data)
+	require.NoError(t, err)
+
+	projectConfig := &ProjectConfig{Path: projectPath, Name: "project", Language: language, NewVersion: "1.3.0"}
+	return globalConfig, projectConfig
+}
+
+// readFixtureFile reads a file of the project copied by newLanguageFixture
+func readFixtureFile(t *testing.T, projectConfig *ProjectConfig, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(projectConfig.Path, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestUpdateVersion_ElixirDirectVersion(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "elixir/direct", "elixir")
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	mix := readFixtureFile(t, projectConfig, "mix.exs")
+	assert.Contains(t, mix, `version: "1.3.0",`)
+	assert.Contains(t, mix, `tag: "v1.0.0", version: "1.0.0"}`)
+	assert.Contains(t, mix, `elixir: "~> 1.14"`)
+}
+
+func TestUpdateVersion_ElixirVersionAttribute(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "elixir/attribute", "elixir")
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	mix := readFixtureFile(t, projectConfig, "mix.exs")
+	assert.Contains(t, mix, `@version "1.3.0"`)
+	assert.Contains(t, mix, `version: @version,`)
+	assert.Contains(t, mix, `{:legacy, version: "1.0.0"}`)
+}
+
+func TestUpdateVersion_ElixirVersionFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "elixir/version_file", "elixir")
+	original := readFixtureFile(t, projectConfig, "mix.exs")
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0\n", readFixtureFile(t, projectConfig, "VERSION"))
+	assert.Equal(t, original, readFixtureFile(t, projectConfig, "mix.exs"))
+}
+
+func TestElixirGetProjectName(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectConfig := newLanguageFixture(t, "elixir/attribute", "elixir")
+
+	// Act
+	name, err := Elixir{ProjectConfig: *projectConfig}.GetProjectName()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "payments", name)
+}
+
+func TestElixirGetVersionFiles_NoProjectDefinition(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, mixFileName), []byte("defmodule Empty do\nend\n"), 0o600))
+
+	// Act
+	_, err := Elixir{ProjectConfig: ProjectConfig{Path: projectPath}}.GetVersionFiles()
+
+	// Assert
+	require.ErrorIs(t, err, ErrMixProjectNotFound)
+}
+
+func TestUpdateVersion_ErlangUmbrella(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "erlang/umbrella", "erlang")
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "apps/billing/src/billing.app.src"), `{vsn, "1.3.0"}`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "apps/ledger/src/ledger.app.src"), `{vsn, "1.3.0"}`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "rebar.config"), `{cowboy, "2.10.0"}`)
+}
+
+func TestErlangGetProjectName(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectConfig := newLanguageFixture(t, "erlang/umbrella/apps/billing", "erlang")
+
+	// Act
+	name, err := Erlang{ProjectConfig: *projectConfig}.GetProjectName()
+	umbrella := Erlang{ProjectConfig: ProjectConfig{Path: filepath.Join("testdata", "erlang", "umbrella")}}
+	umbrellaName, umbrellaErr := umbrella.GetProjectName()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "billing", name)
+	require.NoError(t, umbrellaErr)
+	assert.Empty(t, umbrellaName)
+}
+
+func TestDetectProjectLanguage_Elixir(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "elixir/direct", "")
+
+	// Act
+	language, err := detectProjectLanguage(globalConfig, projectConfig.Path, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "elixir", language)
+}
+
+func TestUpdateVersion_PnpmWorkspacePropagation(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "typescript/pnpm", "typescript")
+	projectConfig.WorkspacePropagation = true
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "package.json"), `"version": "1.3.0"`)
+	web := readFixtureFile(t, projectConfig, "packages/web/package.json")
+	assert.Contains(t, web, `"version": "1.3.0"`)
+	assert.Contains(t, web, `"@acme/ui": "workspace:*"`)
+	assert.Contains(t, web, `"react": "^18.2.0"`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "packages/ui/package.json"), `"version": "1.3.0"`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "packages/internal/package.json"), `"version": "1.2.0"`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "packages/legacy/package.json"), `"version": "1.2.0"`)
+}
+
+func TestUpdateVersion_YarnWorkspacePropagationToPrivate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "typescript/yarn", "typescript")
+	projectConfig.WorkspacePropagation = true
+	projectConfig.PropagateToPrivate = true
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	app := readFixtureFile(t, projectConfig, "packages/app/package.json")
+	assert.Contains(t, app, `"version": "1.3.0"`)
+	assert.Contains(t, app, `"tools": "workspace:^1.2.0"`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "packages/tools/package.json"), `"version": "1.3.0"`)
+}
+
+func TestUpdateVersion_WorkspacePropagationDisabled(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "typescript/yarn", "typescript")
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "package.json"), `"version": "1.3.0"`)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "packages/app/package.json"), `"version": "1.2.0"`)
+}
+
+func TestUpdateVersion_NpmWorkspacePropagationSyncsLockFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newLanguageFixture(t, "typescript/npm", "typescript")
+	projectConfig.WorkspacePropagation = true
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, readFixtureFile(t, projectConfig, "packages/lib/package.json"), `"version": "1.3.0"`)
+	lock := readFixtureFile(t, projectConfig, "package-lock.json")
+	assert.Equal(t, 3, strings.Count(lock, `"version": "1.3.0"`))
+	assert.Contains(t, lock, `"version": "1.2.0",
+      "resolved": "https://registry.npmjs.org/left-pad/-/left-pad-1.2.0.tgz"`)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	onLimitDryRun = "dry-run"
+	onLimitSkip   = "skip"
+)
+
+var ErrInvalidOnLimitMode = errors.New("invalid on_limit mode")
+
+// pullRequestLimiter counts the pull requests created in a run, so a misconfigured run
+// cannot open an unbounded amount of them. It is safe to be shared by concurrent workers.
+type pullRequestLimiter struct {
+	mutex         sync.Mutex
+	maxPerRun     int
+	maxPerOrg     int
+	mode          string
+	created       int
+	createdPerOrg map[string]int
+	limited       []string
+}
+
+// newPullRequestLimiter creates a limiter from the global config, returning nil when no limit is configured
+func newPullRequestLimiter(globalConfig *GlobalConfig) *pullRequestLimiter {
+	if globalConfig.MaxPRsPerRun <= 0 && globalConfig.MaxPRsPerOrg <= 0 {
+		return nil
+	}
+
+	mode := globalConfig.OnLimit
+	if mode == "" {
+		mode = onLimitDryRun
+	}
+
+	return &pullRequestLimiter{
+		maxPerRun:     globalConfig.MaxPRsPerRun,
+		maxPerOrg:     globalConfig.MaxPRsPerOrg,
+		mode:          mode,
+		createdPerOrg: make(map[string]int),
+	}
+}
+
+// reserve takes one of the pull requests allowed for the organization, returning false when a limit was reached
+func (l *pullRequestLimiter) reserve(organization string) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.maxPerRun > 0 && l.created >= l.maxPerRun {
+		return false
+	}
+	if l.maxPerOrg > 0 && l.createdPerOrg[organization] >= l.maxPerOrg {
+		return false
+	}
+
+	l.created++
+	l.createdPerOrg[organization]++
+	return true
+}
+
+// release gives back a reserved pull request when it could not be created
+func (l *pullRequestLimiter) release(organization string) {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.created--
+	l.createdPerOrg[organization]--
+}
+
+// markLimited records a project that was not bumped because a limit was reached
+func (l *pullRequestLimiter) markLimited(projectName string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.limited = append(l.limited, projectName)
+}
+
+// logSummary warns about the projects left behind when a limit was reached
+func (l *pullRequestLimiter) logSummary() {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.limited) == 0 {
+		return
+	}
+
+	log.Warnf(
+		"Pull request limit reached after creating %d pull requests (max_prs_per_run: %d, max_prs_per_org: %d)",
+		l.created, l.maxPerRun, l.maxPerOrg,
+	)
+	log.Warnf(
+		"%d projects were not bumped (on_limit: %s): %s",
+		len(l.limited), l.mode, strings.Join(l.limited, ", "),
+	)
+	log.Warn("Merge the created pull requests and run AutoBump again to process the remaining projects")
+}
+
+// validateOnLimitMode checks whether the action taken when a limit is reached is supported
+func validateOnLimitMode(mode string) error {
+	switch mode {
+	case "", onLimitDryRun, onLimitSkip:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s (expected %s or %s)", ErrInvalidOnLimitMode, mode, onLimitDryRun, onLimitSkip)
+	}
+}
+
+// reservePullRequest reserves a pull request for the project, handling it according to
+// the on_limit mode when a limit was reached. It returns the organization used to count it.
+func reservePullRequest(ctx *RepoContext, changelogPath string) (string, bool, error) {
+	limiter := ctx.globalConfig.pullRequestLimiter
+	if limiter == nil {
+		return "", true, nil
+	}
+
+	remoteURL, err := getRemoteRepoURL(ctx.repo)
+	if err != nil {
+		return "", false, err
+	}
+
+	organization := getRepositoryOrganization(remoteURL)
+	if limiter.reserve(organization) {
+		return organization, true, nil
+	}
+
+	limiter.markLimited(ctx.projectConfig.Name)
+	if limiter.mode == onLimitSkip {
+		ctx.logger().Warnf("Pull request limit reached, skipping project %s", ctx.projectConfig.Name)
+		return organization, false, nil
+	}
+
+	releaseName, err := getNextReleaseName(ctx, changelogPath)
+	if err != nil {
+		return organization, false, err
+	}
+	ctx.logger().Warnf(
+		"Pull request limit reached, dry-run: project %s would be bumped to %s",
+		ctx.projectConfig.Name, releaseName,
+	)
+	return organization, false, previewPullRequest(ctx, releaseName)
+}
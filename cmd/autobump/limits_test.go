@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPullRequestLimiter_Unlimited(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	limiter := newPullRequestLimiter(&GlobalConfig{})
+
+	// Assert
+	assert.Nil(t, limiter)
+	assert.True(t, limiter.reserve("org"))
+}
+
+func TestPullRequestLimiter_MaxPerRun(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	limiter := newPullRequestLimiter(&GlobalConfig{MaxPRsPerRun: 2})
+	created := 0
+
+	// Act
+	for _, organization := range []string{"first", "second", "third", "fourth"} {
+		if limiter.reserve(organization) {
+			created++
+		} else {
+			limiter.markLimited(organization)
+		}
+	}
+
+	// Assert
+	assert.Equal(t, 2, created)
+	assert.Equal(t, []string{"third", "fourth"}, limiter.limited)
+	assert.Equal(t, onLimitDryRun, limiter.mode)
+}
+
+func TestPullRequestLimiter_MaxPerOrg(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	limiter := newPullRequestLimiter(&GlobalConfig{MaxPRsPerOrg: 1, OnLimit: onLimitSkip})
+
+	// Act
+	firstOrg := limiter.reserve("first")
+	firstOrgAgain := limiter.reserve("first")
+	secondOrg := limiter.reserve("second")
+
+	// Assert
+	assert.True(t, firstOrg)
+	assert.False(t, firstOrgAgain)
+	assert.True(t, secondOrg)
+	assert.Equal(t, onLimitSkip, limiter.mode)
+}
+
+func TestPullRequestLimiter_ReleaseOnFailure(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	limiter := newPullRequestLimiter(&GlobalConfig{MaxPRsPerRun: 1})
+	require.True(t, limiter.reserve("org"))
+
+	// Act
+	limiter.release("org")
+
+	// Assert
+	assert.True(t, limiter.reserve("org"))
+	assert.False(t, limiter.reserve("org"))
+}
+
+func TestValidateOnLimitMode(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	require.NoError(t, validateOnLimitMode(""))
+	require.NoError(t, validateOnLimitMode(onLimitDryRun))
+	require.NoError(t, validateOnLimitMode(onLimitSkip))
+	require.ErrorIs(t, validateOnLimitMode("abort"), ErrInvalidOnLimitMode)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultMaxLintSuggestions is the maximum amount of suggestions posted on a pull request when not configured
+const defaultMaxLintSuggestions = 10
+
+var (
+	// lintEntryRegex matches the entries, with their indentation, bullet and text
+	lintEntryRegex = regexp.MustCompile(`^(\s*)([-*+])(\s+)(.*)$`)
+	// lintHeadingRegex matches the Markdown headings, with their level and text
+	lintHeadingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*$`)
+	// diffHunkRegex matches the header of a hunk of a unified diff, with the ranges of the old and the new file
+	diffHunkRegex = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// lintSuggestion is a fixable lint finding of the CHANGELOG, with the line replacing the original one
+type lintSuggestion struct {
+	Line        int
+	Original    string
+	Replacement string
+	Message     string
+	// position of the line in the diff of the pull request, as GitHub addresses the review comments
+	Position int
+}
+
+// lintSuggestions are the suggestions posted on the pull request of a release, with the commits of its diff
+type lintSuggestions struct {
+	path      string
+	baseSHA   string
+	headSHA   string
+	additions []lintSuggestion
+}
+
+// diffLine is a line of the new file shown in a diff
+type diffLine struct {
+	position int
+	added    bool
+}
+
+// findFixableLintFindings returns the lint findings which can be fixed by replacing their line: the bullets other
+// than "-", the entries ending with a period, and the sections of the releases with another level than 3
+func findFixableLintFindings(lines []string) []lintSuggestion {
+	frontMatter, content := splitFrontMatter(lines)
+
+	var suggestions []lintSuggestion
+	insideCodeBlock := false
+	for index, line := range content {
+		if isCodeFence(line) {
+			insideCodeBlock = !insideCodeBlock
+			continue
+		}
+		if insideCodeBlock {
+			continue
+		}
+
+		replacement, messages := fixLintLine(line)
+		if len(messages) > 0 {
+			suggestions = append(suggestions, lintSuggestion{
+				Line:        len(frontMatter) + index + 1,
+				Original:    line,
+				Replacement: replacement,
+				Message:     strings.Join(messages, ", "),
+			})
+		}
+	}
+	return suggestions
+}
+
+// fixLintLine returns the line with its fixable findings fixed, along with the messages of the findings
+func fixLintLine(line string) (string, []string) {
+	var messages []string
+	if match := lintHeadingRegex.FindStringSubmatch(line); match != nil {
+		if len(match[1]) != 3 && slices.Contains(digestSectionsOrder, match[2]) {
+			messages = append(messages, fmt.Sprintf("the section %s should be a level 3 heading", match[2]))
+			return "### " + match[2], messages
+		}
+		return line, nil
+	}
+
+	match := lintEntryRegex.FindStringSubmatch(line)
+	if match == nil {
+		return line, nil
+	}
+	bullet, text := match[2], match[4]
+	if bullet != "-" {
+		messages = append(messages, fmt.Sprintf("the entries should use the \"-\" bullet instead of %q", bullet))
+		bullet = "-"
+	}
+	if strings.HasSuffix(text, ".") && !strings.HasSuffix(text, "..") {
+		messages = append(messages, "the entries shouldn't end with a period")
+		text = strings.TrimSuffix(text, ".")
+	}
+	return match[1] + bullet + match[3] + text, messages
+}
+
+// mapDiffPositions maps the lines of the new file to their position in the unified diff, for the file of the path.
+// As GitHub counts them, the position 1 is the line below the first hunk header, and the next headers count.
+func mapDiffPositions(diff string, path string) map[int]diffLine {
+	positions := make(map[int]diffLine)
+	insideFile := false
+	position, newLine, oldRemaining, newRemaining := 0, 0, 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		if oldRemaining > 0 || newRemaining > 0 {
+			position++
+			switch {
+			case strings.HasPrefix(line, "+"):
+				positions[newLine] = diffLine{position: position, added: true}
+				newLine++
+				newRemaining--
+			case strings.HasPrefix(line, "-"):
+				oldRemaining--
+			case strings.HasPrefix(line, "\\"):
+				position--
+			default:
+				positions[newLine] = diffLine{position: position}
+				newLine++
+				oldRemaining--
+				newRemaining--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			insideFile = false
+		case strings.HasPrefix(line, "+++ "):
+			insideFile = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/") == path
+			position = 0
+		case insideFile && strings.HasPrefix(line, "@@"):
+			match := diffHunkRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			if position > 0 {
+				position++
+			}
+			oldRemaining = parseHunkLength(match[1])
+			newLine, _ = strconv.Atoi(match[2])
+			newRemaining = parseHunkLength(match[3])
+		}
+		if !insideFile {
+			oldRemaining, newRemaining = 0, 0
+		}
+	}
+	return positions
+}
+
+// parseHunkLength returns the amount of lines of a hunk range, which is 1 when omitted
+func parseHunkLength(length string) int {
+	if length == "" {
+		return 1
+	}
+	value, _ := strconv.Atoi(length)
+	return value
+}
+
+// selectLintSuggestions keeps the suggestions of the lines added by the pull request, up to the maximum
+func selectLintSuggestions(
+	suggestions []lintSuggestion,
+	positions map[int]diffLine,
+	maxSuggestions int,
+	logger *log.Entry,
+) []lintSuggestion {
+	var selected []lintSuggestion
+	for _, suggestion := range suggestions {
+		line, found := positions[suggestion.Line]
+		if !found || !line.added {
+			continue
+		}
+		if len(selected) == maxSuggestions {
+			logger.Infof("Skipping the other lint suggestions, the pull request has %d already", maxSuggestions)
+			break
+		}
+		suggestion.Position = line.position
+		selected = append(selected, suggestion)
+	}
+	return selected
+}
+
+// getMaxLintSuggestions returns the maximum amount of suggestions posted on a pull request
+func getMaxLintSuggestions(lintConfig ChangelogLintConfig) int {
+	if lintConfig.MaxSuggestions <= 0 {
+		return defaultMaxLintSuggestions
+	}
+	return lintConfig.MaxSuggestions
+}
+
+// prepareLintSuggestions finds the fixable lint findings of the CHANGELOG among the lines changed by the bump commit,
+// to be posted on its pull request. Nothing is suggested when the suggestions are disabled, and the failures
+// are only logged, since the suggestions are optional.
+func prepareLintSuggestions(ctx *RepoContext, changelogPath string) {
+	if !ctx.globalConfig.ChangelogLint.LintSuggestions || isFeatureSkipped(&ctx.state, featureLintSuggestions) {
+		return
+	}
+
+	suggestions, err := getLintSuggestions(ctx, changelogPath)
+	if err != nil {
+		ctx.logger().Warnf("Unable to prepare the lint suggestions of the pull request: %v", err)
+		return
+	}
+	if len(suggestions.additions) > 0 {
+		ctx.state.lintSuggestions = suggestions
+	}
+}
+
+// getLintSuggestions compares the bump commit with its parent to find the fixable lines it added to the CHANGELOG
+func getLintSuggestions(ctx *RepoContext, changelogPath string) (*lintSuggestions, error) {
+	relativePath, err := getWorktreePath(ctx, changelogPath)
+	if err != nil {
+		return nil, err
+	}
+	relativePath = filepath.ToSlash(relativePath)
+
+	head, err := ctx.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the bump commit: %w", err)
+	}
+	bumpCommit, err := ctx.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the bump commit: %w", err)
+	}
+	baseCommit, err := bumpCommit.Parent(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the parent of the bump commit: %w", err)
+	}
+	patch, err := baseCommit.Patch(bumpCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the diff of the bump commit: %w", err)
+	}
+
+	lines, err := readCommitLines(bumpCommit, relativePath, "the bump commit", getMaxFileSize(ctx.globalConfig))
+	if err != nil {
+		return nil, err
+	}
+	return &lintSuggestions{
+		path:    relativePath,
+		baseSHA: baseCommit.Hash.String(),
+		headSHA: bumpCommit.Hash.String(),
+		additions: selectLintSuggestions(
+			findFixableLintFindings(lines),
+			mapDiffPositions(patch.String(), relativePath),
+			getMaxLintSuggestions(ctx.globalConfig.ChangelogLint),
+			ctx.logger(),
+		),
+	}, nil
+}
+
+// formatLintSuggestion writes the suggestion as a comment applied with one click by the forges supporting it
+func formatLintSuggestion(suggestion lintSuggestion) string {
+	return fmt.Sprintf("**Lint:** %s.\n\n```suggestion:-0+0\n%s\n```", suggestion.Message, suggestion.Replacement)
+}
+
+// formatLintSuggestionThread writes the suggestion as a plain comment, for the forges without suggestions
+func formatLintSuggestionThread(suggestion lintSuggestion) string {
+	return fmt.Sprintf(
+		"**Lint:** %s, this line should be:\n\n```markdown\n%s\n```", suggestion.Message, suggestion.Replacement,
+	)
+}
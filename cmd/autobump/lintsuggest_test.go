@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// lintSuggestionsDiff is the diff of a bump commit, releasing the entries of the CHANGELOG and bumping a version file
+const lintSuggestionsDiff = `diff --git a/CHANGELOG.md b/CHANGELOG.md
+index 1111111..2222222 100644
+--- a/CHANGELOG.md
++++ b/CHANGELOG.md
+@@ -1,7 +1,10 @@
+ # Changelog
+
+ ## [Unreleased]
+
++## [1.1.0] - 2026-10-15
++
+ #### Added
+
+-* added the retries.
++* added the retries.
++- added the timeouts
+@@ -20,3 +24,4 @@
+ ## [1.0.0] - 2026-01-01
+
+ - initial release
++--- not a header
+diff --git a/version.txt b/version.txt
+index 3333333..4444444 100644
+--- a/version.txt
++++ b/version.txt
+@@ -1 +1 @@
+-version=1.0.0
++version=1.1.0
+`
+
+// newGitLabMergeRequest decodes a merge request answered by the GitLab API
+func newGitLabMergeRequest(t *testing.T, response string) *gitlab.MergeRequest {
+	t.Helper()
+
+	var mergeRequest gitlab.MergeRequest
+	require.NoError(t, json.Unmarshal([]byte(response), &mergeRequest))
+	return &mergeRequest
+}
+
+func TestFindFixableLintFindings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := []string{
+		"# Changelog",
+		"",
+		"## [1.1.0] - 2026-10-15",
+		"",
+		"#### Added",
+		"",
+		"* added the retries.",
+		"  + nested entry",
+		"- loading...",
+		"",
+		"```",
+		"* kept in the code block.",
+		"```",
+		"### Fixed",
+	}
+
+	// Act
+	suggestions := findFixableLintFindings(lines)
+
+	// Assert
+	require.Len(t, suggestions, 3)
+	assert.Equal(t, lintSuggestion{
+		Line:        5,
+		Original:    "#### Added",
+		Replacement: "### Added",
+		Message:     "the section Added should be a level 3 heading",
+	}, suggestions[0])
+	assert.Equal(t, 7, suggestions[1].Line)
+	assert.Equal(t, "- added the retries", suggestions[1].Replacement)
+	assert.Equal(t,
+		`the entries should use the "-" bullet instead of "*", the entries shouldn't end with a period`,
+		suggestions[1].Message,
+	)
+	assert.Equal(t, "  - nested entry", suggestions[2].Replacement)
+}
+
+func TestMapDiffPositions(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	positions := mapDiffPositions(lintSuggestionsDiff, "CHANGELOG.md")
+
+	// Assert
+	assert.Equal(t, diffLine{position: 1}, positions[1])
+	assert.Equal(t, diffLine{position: 5, added: true}, positions[5])
+	assert.Equal(t, diffLine{position: 7}, positions[7])
+	assert.Equal(t, diffLine{position: 10, added: true}, positions[9], "the removed lines should count")
+	assert.Equal(t, diffLine{position: 11, added: true}, positions[10])
+	assert.Equal(t, diffLine{position: 13}, positions[24], "the header of the next hunk should count")
+	assert.Equal(t, diffLine{position: 16, added: true}, positions[27])
+	assert.NotContains(t, positions, 11, "the lines between the hunks aren't in the diff")
+	assert.Len(t, positions, 14)
+	assert.Len(t, mapDiffPositions(lintSuggestionsDiff, "version.txt"), 1)
+}
+
+func TestSelectLintSuggestions_KeepsTheAddedLinesUpToTheMaximum(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	positions := mapDiffPositions(lintSuggestionsDiff, "CHANGELOG.md")
+	suggestions := []lintSuggestion{{Line: 7}, {Line: 9}, {Line: 10}, {Line: 27}}
+
+	// Act
+	selected := selectLintSuggestions(suggestions, positions, 2, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, []lintSuggestion{{Line: 9, Position: 10}, {Line: 10, Position: 11}}, selected)
+}
+
+func TestGetLintSuggestions_SuggestsTheLinesOfTheBumpCommit(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, "CHANGELOG.md", "# Changelog\n\n## [Unreleased]\n\n## [1.0.0] - 2026-01-01\n\n* initial release.\n")
+	commitFile(t, repo, "CHANGELOG.md",
+		"# Changelog\n\n## [Unreleased]\n\n## [1.1.0] - 2026-10-15\n\n### Added\n\n* added the retries.\n\n"+
+			"## [1.0.0] - 2026-01-01\n\n* initial release.\n",
+	)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: repoPath},
+		repo:          repo,
+		worktree:      worktree,
+	}
+
+	// Act
+	suggestions, err := getLintSuggestions(ctx, filepath.Join(repoPath, "CHANGELOG.md"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "CHANGELOG.md", suggestions.path)
+	require.Len(t, suggestions.additions, 1, "the lines of the previous releases shouldn't be suggested")
+	assert.Equal(t, 9, suggestions.additions[0].Line)
+	assert.Equal(t, "- added the retries", suggestions.additions[0].Replacement)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), suggestions.headSHA)
+	assert.NotEqual(t, suggestions.headSHA, suggestions.baseSHA)
+}
+
+func TestPostGitLabLintSuggestions_PostsTheSuggestionsOnTheLines(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"POST /projects/42/merge_requests/3/discussions": `{"id": "abc"}`,
+	})
+	projectConfig := &ProjectConfig{}
+	state := &projectState{
+		lintSuggestions: &lintSuggestions{
+			path:      "CHANGELOG.md",
+			baseSHA:   "base",
+			headSHA:   "head",
+			additions: []lintSuggestion{{Line: 9, Replacement: "- added the retries", Message: "no period"}},
+		},
+	}
+	mergeRequest := newGitLabMergeRequest(t, `{"iid": 3, "diff_refs": {}}`)
+
+	// Act
+	postGitLabLintSuggestions(client, projectConfig, state, 42, mergeRequest, standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 1)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal([]byte(requests[0].body), &payload))
+	assert.Equal(t, "**Lint:** no period.\n\n```suggestion:-0+0\n- added the retries\n```", payload["body"])
+	assert.Equal(t, map[string]any{
+		"base_sha":      "base",
+		"start_sha":     "base",
+		"head_sha":      "head",
+		"old_path":      "CHANGELOG.md",
+		"new_path":      "CHANGELOG.md",
+		"position_type": "text",
+		"new_line":      float64(9),
+	}, payload["position"])
+}
+
+func TestPostGitLabLintSuggestions_UsesTheDiffOfTheMergeRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, getRequests := newGitLabMock(t, map[string]string{
+		"POST /projects/42/merge_requests/3/discussions": `{"id": "abc"}`,
+	})
+	projectConfig := &ProjectConfig{}
+	state := &projectState{
+		lintSuggestions: &lintSuggestions{
+			path: "CHANGELOG.md", baseSHA: "base", headSHA: "head", additions: []lintSuggestion{{Line: 9}},
+		},
+	}
+	mergeRequest := newGitLabMergeRequest(t,
+		`{"iid": 3, "diff_refs": {"base_sha": "mr-base", "start_sha": "mr-start", "head_sha": "mr-head"}}`,
+	)
+
+	// Act
+	postGitLabLintSuggestions(client, projectConfig, state, 42, mergeRequest, standardLogEntry())
+
+	// Assert
+	requests := getRequests()
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0].body, `"base_sha":"mr-base","head_sha":"mr-head","start_sha":"mr-start"`)
+}
+
+func TestBuildAzureDevOpsThreadRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	azureInfo := AzureDevOpsInfo{OrganizationName: "org", ProjectName: "project", RepositoryID: "repo-guid"}
+	suggestion := lintSuggestion{
+		Line: 9, Original: "* added the retries.", Replacement: "- added the retries", Message: "no period",
+	}
+
+	// Act
+	req, err := buildAzureDevOpsThreadRequest(context.Background(), azureInfo, "pat", 12, "CHANGELOG.md", suggestion)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t,
+		"https://dev.azure.com/org/project/_apis/git/repositories/repo-guid/pullRequests/12/threads?api-version=6.0",
+		req.URL.String(),
+	)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"comments": [{
+			"parentCommentId": 0,
+			"content": "**Lint:** no period, this line should be:\n\n`+"```markdown\\n- added the retries\\n```"+`",
+			"commentType": 1
+		}],
+		"status": "active",
+		"threadContext": {
+			"filePath": "/CHANGELOG.md",
+			"rightFileStart": {"line": 9, "offset": 1},
+			"rightFileEnd": {"line": 9, "offset": 21}
+		}
+	}`, string(body))
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// runLockName is the name of the lock file of the batch runs, in the cache directory
+	runLockName = "autobump.lock"
+	// staleRunLockAge is the age of the lock files broken even when their run can't be checked (e.g. on another host)
+	staleRunLockAge = 24 * time.Hour
+	// runLockPollInterval is how often the lock is tried again while waiting for it
+	runLockPollInterval = 500 * time.Millisecond
+	// exitCodeRunLocked is the exit code when another run holds the lock (EX_TEMPFAIL, the run can be retried later)
+	exitCodeRunLocked = 75
+)
+
+var ErrRunLocked = errors.New("another AutoBump run holds the lock of the cache directory")
+
+// runLockHolder is the run holding the lock, recorded in the lock file
+type runLockHolder struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (h runLockHolder) String() string {
+	if h.PID == 0 {
+		return "an unknown run"
+	}
+	return fmt.Sprintf("the run of PID %d on %s, started at %s", h.PID, h.Hostname, h.StartedAt.Format(time.RFC3339))
+}
+
+// runLock is the advisory lock of the cache directory held by a batch run, so an overlapping run (e.g. a manual
+// one during a scheduled one) doesn't write into the cache at the same time. The lock file is locked with flock
+// where supported, or else created atomically.
+type runLock struct {
+	path string
+	file *os.File
+}
+
+// acquireRunLock locks the directory for the current run, waiting up to the timeout while another run holds it.
+// The stale locks (of a run which is gone, or too old) are broken. Nothing is locked when the directory is empty.
+func acquireRunLock(dir string, timeout time.Duration) (*runLock, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the lock directory: %w", err)
+	}
+
+	lockPath := filepath.Join(dir, runLockName)
+	deadline := time.Now().Add(timeout)
+	for {
+		var file *os.File
+		file, err = tryLockFile(lockPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if file != nil {
+			lock := &runLock{path: lockPath, file: file}
+			return lock, lock.writeHolder()
+		}
+
+		holder, modTime := readRunLockHolder(lockPath)
+		hostname, _ := os.Hostname()
+		if isStaleRunLock(holder, modTime, hostname, time.Now()) {
+			log.Warnf("Breaking the stale lock %s of %s", lockPath, holder)
+			err = os.Remove(lockPath)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to break the stale lock %s: %w", lockPath, err)
+			}
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %s is held by %s", ErrRunLocked, lockPath, holder)
+		}
+		log.Debugf("Waiting for %s, held by %s", lockPath, holder)
+		time.Sleep(runLockPollInterval)
+	}
+}
+
+// writeHolder records the current run in the lock file
+func (l *runLock) writeHolder() error {
+	hostname, _ := os.Hostname()
+	data, err := json.Marshal(runLockHolder{PID: os.Getpid(), Hostname: hostname, StartedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the lock holder: %w", err)
+	}
+
+	err = l.file.Truncate(0)
+	if err == nil {
+		_, err = l.file.WriteAt(data, 0)
+	}
+	if err != nil {
+		l.release()
+		return fmt.Errorf("failed to write the lock file: %w", err)
+	}
+	return nil
+}
+
+// release removes the lock file, letting the next run acquire it.
+// The file isn't removed when it was broken as stale and acquired by another run meanwhile.
+func (l *runLock) release() {
+	if l == nil {
+		return
+	}
+	pathInfo, pathErr := os.Stat(l.path)
+	fileInfo, fileErr := l.file.Stat()
+	if pathErr == nil && fileErr == nil && os.SameFile(pathInfo, fileInfo) {
+		_ = os.Remove(l.path)
+	}
+	_ = l.file.Close()
+}
+
+// readRunLockHolder reads the run recorded in the lock file and its modification time,
+// the holder being unknown when the file can't be read (e.g. while it is written)
+func readRunLockHolder(lockPath string) (runLockHolder, time.Time) {
+	var holder runLockHolder
+	info, err := os.Stat(lockPath)
+	if err != nil {
+		return holder, time.Time{}
+	}
+	data, err := os.ReadFile(lockPath)
+	if err == nil {
+		_ = json.Unmarshal(data, &holder)
+	}
+	return holder, info.ModTime()
+}
+
+// isStaleRunLock checks whether the lock was left behind: its run is gone from the current host,
+// or it is older than staleRunLockAge
+func isStaleRunLock(holder runLockHolder, modTime time.Time, hostname string, now time.Time) bool {
+	if holder.PID != 0 && holder.Hostname == hostname && !isProcessAlive(holder.PID) {
+		return true
+	}
+	return now.Sub(modTime) > staleRunLockAge
+}
+
+// isRunLocked checks whether another run holds the lock of the directory, returning its holder
+func isRunLocked(dir string) (runLockHolder, bool) {
+	if dir == "" {
+		return runLockHolder{}, false
+	}
+
+	lockPath := filepath.Join(dir, runLockName)
+	holder, modTime := readRunLockHolder(lockPath)
+	if modTime.IsZero() {
+		return holder, false
+	}
+	hostname, _ := os.Hostname()
+	if isStaleRunLock(holder, modTime, hostname, time.Now()) {
+		return holder, false
+	}
+
+	file, err := tryLockFile(lockPath)
+	if err != nil || file == nil {
+		return holder, true
+	}
+	// the file was left by a run which is gone, since nobody holds it
+	(&runLock{path: lockPath, file: file}).release()
+	return holder, false
+}
+
+// shareDefaultsCache keeps the cache read-only while a batch run holds its lock, so the commands which don't take
+// the lock (e.g. "config validate" or a single project) never write into it at the same time
+func shareDefaultsCache(cache *downloadCache) {
+	if holder, locked := isRunLocked(cache.dir); locked {
+		log.Debugf("The cache %s is locked by %s, using it read-only", cache.dir, holder)
+		cache.readOnly = true
+	}
+}
+
+// exitOnRunLocked stops the run when another one holds the lock, with a distinct exit code
+func exitOnRunLocked(err error) {
+	if errors.Is(err, ErrRunLocked) {
+		log.Errorf("%v (wait for it with --lock-timeout)", err)
+		os.Exit(exitCodeRunLocked)
+	}
+	log.Fatalf("Failed to lock the cache directory: %v", err)
+}
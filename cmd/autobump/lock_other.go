@@ -0,0 +1,27 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+)
+
+// tryLockFile creates the file atomically, returning nil when it already exists.
+// The file is left behind when the process crashes, until it is broken as stale.
+func tryLockFile(lockPath string) (*os.File, error) {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o600)
+	if os.IsExist(err) {
+		return nil, nil
+	}
+	return file, err
+}
+
+// isProcessAlive checks whether the process exists
+func isProcessAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
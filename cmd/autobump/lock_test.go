@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadPID is a process ID above the limits of the operating systems, so no process has it
+const deadPID = 1 << 30
+
+// writeRunLockHolder replaces the run recorded in the lock file of the directory
+func writeRunLockHolder(t *testing.T, dir string, holder runLockHolder, modTime time.Time) {
+	t.Helper()
+
+	data, err := json.Marshal(holder)
+	require.NoError(t, err)
+	lockPath := filepath.Join(dir, runLockName)
+	require.NoError(t, os.WriteFile(lockPath, data, 0o600))
+	require.NoError(t, os.Chtimes(lockPath, modTime, modTime))
+}
+
+func TestAcquireRunLock_RecordsTheRunAndReleasesTheLock(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	// Act
+	lock, err := acquireRunLock(dir, 0)
+
+	// Assert
+	require.NoError(t, err)
+	holder, _ := readRunLockHolder(filepath.Join(dir, runLockName))
+	hostname, _ := os.Hostname()
+	assert.Equal(t, os.Getpid(), holder.PID)
+	assert.Equal(t, hostname, holder.Hostname)
+	assert.WithinDuration(t, time.Now(), holder.StartedAt, time.Minute)
+
+	lock.release()
+	assert.NoFileExists(t, filepath.Join(dir, runLockName))
+	lock, err = acquireRunLock(dir, 0)
+	require.NoError(t, err, "the released lock should be acquired again")
+	lock.release()
+}
+
+func TestAcquireRunLock_RefusesTheLockHeldByAnotherRun(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+	lock, err := acquireRunLock(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(lock.release)
+
+	// Act
+	_, err = acquireRunLock(dir, 0)
+	holder, locked := isRunLocked(dir)
+
+	// Assert
+	require.ErrorIs(t, err, ErrRunLocked)
+	assert.Contains(t, err.Error(), holder.String())
+	assert.True(t, locked)
+	assert.Equal(t, os.Getpid(), holder.PID)
+}
+
+func TestAcquireRunLock_WaitsForTheLock(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+	lock, err := acquireRunLock(dir, 0)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(runLockPollInterval)
+		lock.release()
+	}()
+
+	// Act
+	waitingLock, err := acquireRunLock(dir, time.Minute)
+
+	// Assert
+	require.NoError(t, err)
+	waitingLock.release()
+}
+
+func TestAcquireRunLock_BreaksTheLockOfARunWhichIsGone(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+	staleLock, err := acquireRunLock(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = staleLock.file.Close() })
+	hostname, _ := os.Hostname()
+	writeRunLockHolder(t, dir, runLockHolder{PID: deadPID, Hostname: hostname}, time.Now())
+
+	// Act
+	lock, err := acquireRunLock(dir, 0)
+
+	// Assert
+	require.NoError(t, err)
+	holder, _ := readRunLockHolder(filepath.Join(dir, runLockName))
+	assert.Equal(t, os.Getpid(), holder.PID)
+	staleLock.release()
+	assert.FileExists(t, filepath.Join(dir, runLockName), "the broken lock shouldn't release the new one")
+	lock.release()
+}
+
+func TestIsStaleRunLock(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	now := time.Now()
+	old := now.Add(-2 * staleRunLockAge)
+	alive := runLockHolder{PID: os.Getpid(), Hostname: "runner"}
+	gone := runLockHolder{PID: deadPID, Hostname: "runner"}
+
+	// Act & Assert
+	assert.False(t, isStaleRunLock(alive, now, "runner", now))
+	assert.True(t, isStaleRunLock(gone, now, "runner", now))
+	assert.False(t, isStaleRunLock(gone, now, "other-runner", now), "the runs of other hosts can't be checked")
+	assert.True(t, isStaleRunLock(gone, old, "other-runner", now))
+	assert.False(t, isStaleRunLock(runLockHolder{}, now, "runner", now), "the file may still be written")
+	assert.True(t, isStaleRunLock(runLockHolder{}, old, "runner", now))
+}
+
+func TestShareDefaultsCache_ReadOnlyWhileLocked(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, downloads := newCountingServer(t, "languages: {}\n")
+	dir := t.TempDir()
+	lock, err := acquireRunLock(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(lock.release)
+	cache := newDownloadCache(dir, time.Hour)
+
+	// Act
+	shareDefaultsCache(cache)
+	data, err := cache.get(server.URL, "default-config.yaml", nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, cache.readOnly)
+	assert.Equal(t, "languages: {}\n", string(data))
+	assert.Equal(t, int32(1), downloads.Load())
+	assert.NoFileExists(t, filepath.Join(dir, "default-config.yaml"), "the locked cache shouldn't be written")
+}
+
+func TestShareDefaultsCache_WritableWithoutLock(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+	hostname, _ := os.Hostname()
+	writeRunLockHolder(t, dir, runLockHolder{PID: deadPID, Hostname: hostname}, time.Now())
+	cache := newDownloadCache(dir, time.Hour)
+
+	// Act
+	shareDefaultsCache(cache)
+
+	// Assert
+	assert.False(t, cache.readOnly, "the lock left by a run which is gone shouldn't make the cache read-only")
+}
@@ -0,0 +1,44 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile locks the file with flock, returning nil when another process holds it.
+// The lock is released by the kernel when the process ends, even when it crashes.
+func tryLockFile(lockPath string) (*os.File, error) {
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+		if err != nil {
+			return nil, err
+		}
+
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			_ = file.Close()
+			return nil, nil
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+
+		// the holder removes the file when releasing it, so the lock is only valid when the file is still there
+		pathInfo, pathErr := os.Stat(lockPath)
+		fileInfo, fileErr := file.Stat()
+		if pathErr == nil && fileErr == nil && os.SameFile(pathInfo, fileInfo) {
+			return file, nil
+		}
+		_ = file.Close()
+	}
+}
+
+// isProcessAlive checks whether the process exists, even when owned by another user
+func isProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -12,43 +14,101 @@ import (
 type Config struct {
 	language   string
 	configPath string
+	baseRef    string
+	write      bool
+	maxPRs     int
+	digestOut  string
+	reportOut  string
+	report     string
+	output     string
+	fromStdin  bool
+	fix        bool
+	allowEmpty bool
+	versionOut string
+	authHost   string
+	clientID   string
+
+	refreshDefaults       bool
+	ignoreConflictMarkers bool
+	projectRoot           string
+	train                 bool
+	projectsFile          string
+	projectsStdin         bool
+	limit                 int
+	shuffle               bool
+	seed                  uint64
+	reason                string
+	lockTimeout           time.Duration
+	concurrency           int
+	fromCommits           bool
+
+	// the preview, the next version and the check have their own refs and formats,
+	// since the defaults of the flags are set when they are registered
+	previewBaseRef string
+	previewHeadRef string
+	previewFormat  string
+	nextFormat     string
+	checkOutput    string
 }
 
 func initRootCmd(config *Config) *cobra.Command {
 	return &cobra.Command{
-		Use:   "autobump",
-		Short: "AutoBump is a tool that automatically updates CHANGELOG.md",
+		Use:     "autobump",
+		Short:   "AutoBump is a tool that automatically updates CHANGELOG.md",
+		Version: version,
 		Run: func(_ *cobra.Command, _ []string) {
+			defaultsCache.refresh = config.refreshDefaults
 			globalConfig, err := findReadAndValidateConfig(config.configPath)
 			if err != nil {
-				log.Fatalf("Failed to read config: %v", err)
+				fatalOnConfigError(err)
+			}
+
+			outputFormat, err := resolveOutputFormat(config.output)
+			if err != nil {
+				log.Fatalf("Failed to set the output format: %v", err)
+			}
+			setOutputFormat(outputFormat)
+			if config.ignoreConflictMarkers {
+				globalConfig.Changelog.IgnoreConflictMarkers = true
+			}
+			if config.reportOut != "" {
+				globalConfig.ReportOut = config.reportOut
 			}
+			warnIncoherentConfig(globalConfig, invocationSingle)
 
 			cwd, err := os.Getwd()
 			if err != nil {
 				log.Fatalf("Failed to get the current working directory: %v", err)
 			}
+			projectRoot, err := resolveProjectRoot(cwd, config.projectRoot)
+			if err != nil {
+				log.Fatalf("Failed to find the project root: %v", err)
+			}
 
 			projectConfig := &ProjectConfig{
-				Path:     cwd,
+				Path:     projectRoot,
 				Language: config.language,
+				BaseRef:  config.baseRef,
+			}
+			if config.fromCommits {
+				projectConfig.ChangelogSource = changelogSourceCommits
 			}
 
 			// detect the project language if not manually set
 			if projectConfig.Language == "" {
 				var projectLanguage string
-				projectLanguage, err = detectProjectLanguage(globalConfig, projectConfig.Path)
+				projectLanguage, err = detectProjectLanguage(globalConfig, projectConfig.Path, standardLogEntry())
 				if err != nil {
 					log.Fatalf("Failed to detect project language: %v", err)
 				}
 				projectConfig.Language = projectLanguage
 			}
 
-			err = processRepo(globalConfig, projectConfig)
+			globalConfig.releaseDigest = newReleaseDigest(globalConfig)
+			err = processRepo(globalConfig, projectConfig, log.NewEntry(log.StandardLogger()))
+			writeRunReport(globalConfig)
 			if err != nil {
 				log.Fatalf("Failed to process repo: %v", err)
-				// TODO: rollback the process removing the branch if exists,
-				//       reverting the files and going back to main
 			}
 		},
 	}
@@ -58,12 +118,53 @@ func initBatchCmd(config *Config) *cobra.Command {
 	return &cobra.Command{
 		Use:   "batch",
 		Short: "Run AutoBump for all projects in the configuration",
-		Run: func(_ *cobra.Command, _ []string) {
+		Run: func(cmd *cobra.Command, _ []string) {
+			// overlapping batch runs (e.g. a manual one during a scheduled one) would write into the cache together
+			lock, err := acquireRunLock(defaultsCache.dir, config.lockTimeout)
+			if err != nil {
+				exitOnRunLocked(err)
+			}
+			defer lock.release()
+			// the cache is refreshed in the background, while the lock is still held
+			defer defaultsCache.wait()
+
+			defaultsCache.refresh = config.refreshDefaults
 			globalConfig, err := findReadAndValidateConfig(config.configPath)
 			if err != nil {
-				log.Fatalf("Failed to read config: %v", err)
+				fatalOnConfigError(err)
 			}
 
+			outputFormat, err := resolveOutputFormat(config.output)
+			if err != nil {
+				log.Fatalf("Failed to set the output format: %v", err)
+			}
+			setOutputFormat(outputFormat)
+			if config.ignoreConflictMarkers {
+				globalConfig.Changelog.IgnoreConflictMarkers = true
+			}
+
+			if config.maxPRs > 0 {
+				globalConfig.MaxPRsPerRun = config.maxPRs
+			}
+			if config.concurrency > 0 {
+				globalConfig.Concurrency = config.concurrency
+			}
+			if config.digestOut != "" {
+				globalConfig.DigestOut = config.digestOut
+			}
+			if config.reportOut != "" {
+				globalConfig.ReportOut = config.reportOut
+			}
+			globalConfig.train = config.train
+			globalConfig.selection = newProjectSelection(
+				config.limit, config.shuffle, config.seed, cmd.Flags().Changed("seed"),
+			)
+			err = loadBatchProjects(globalConfig, config.projectsFile, config.projectsStdin, cmd.InOrStdin())
+			if err != nil {
+				log.Fatalf("Failed to read the projects: %v", err)
+			}
+			warnIncoherentConfig(globalConfig, invocationBatch)
+
 			err = iterateProjects(globalConfig)
 			if err != nil {
 				log.Fatalf("Failed to iterate projects: %v", err)
@@ -72,6 +173,324 @@ func initBatchCmd(config *Config) *cobra.Command {
 	}
 }
 
+func initConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Manage the AutoBump configuration file",
+	}
+}
+
+func initConfigMigrateCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Check a configuration file against the current schema, listing the keys it does not know",
+		Run: func(_ *cobra.Command, _ []string) {
+			configPath := findConfigOnMissing(config.configPath)
+			err := migrateConfigFile(configPath, config.write)
+			if err != nil {
+				log.Fatalf("Failed to migrate config: %v", err)
+			}
+		},
+	}
+}
+
+func initConfigValidateCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration file, warning about the options without effect",
+		Run: func(cmd *cobra.Command, _ []string) {
+			globalConfig, err := findReadAndValidateConfig(config.configPath)
+			if err != nil {
+				fatalOnConfigError(err)
+			}
+
+			globalGitConfig, err := getGlobalGitConfig()
+			if err != nil {
+				log.Warnf("Unable to read the global Git config: %v", err)
+			}
+
+			err = writeConfigValidation(cmd.OutOrStdout(), globalConfig, globalGitConfig)
+			if err != nil {
+				log.Fatalf("Failed to validate config: %v", err)
+			}
+		},
+	}
+}
+
+func initConfigSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration file, for the validation and completion of the editors",
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := writeConfigSchema(cmd.OutOrStdout())
+			if err != nil {
+				log.Fatalf("Failed to generate the config schema: %v", err)
+			}
+		},
+	}
+}
+
+func initCIOutputCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ci-output",
+		Short: "Translate the JSON report of a run into CI outputs (GitHub Actions outputs or dotenv variables)",
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := runCIOutput(cmd.OutOrStdout(), config.report, config.output)
+			if err != nil {
+				log.Fatalf("Failed to write the CI outputs: %v", err)
+			}
+		},
+	}
+}
+
+func initPreviewCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "preview",
+		Short: "Preview the bump of the CHANGELOG entries added by a ref (e.g. a pull request) to another",
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := runBumpPreview(cmd.OutOrStdout(), config)
+			if err != nil {
+				log.Fatalf("Failed to preview the bump: %v", err)
+			}
+		},
+	}
+}
+
+func initYankCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "yank <version>",
+		Short: "Mark a released version as yanked in the CHANGELOG, opening a pull request",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			globalConfig, err := findReadAndValidateConfig(config.configPath)
+			if err != nil {
+				fatalOnConfigError(err)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				log.Fatalf("Failed to get the current working directory: %v", err)
+			}
+			projectRoot, err := resolveProjectRoot(cwd, config.projectRoot)
+			if err != nil {
+				log.Fatalf("Failed to find the project root: %v", err)
+			}
+
+			projectConfig := &ProjectConfig{Path: projectRoot, Language: config.language}
+			err = runYank(globalConfig, projectConfig, strings.TrimPrefix(args[0], "v"), config.reason)
+			if err != nil {
+				log.Fatalf("Failed to yank the release: %v", err)
+			}
+		},
+	}
+}
+
+func initTagCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tag",
+		Short: "Tag the HEAD with the latest release of the CHANGELOG (e.g. after merging the bump), pushing the tag",
+		Run: func(_ *cobra.Command, _ []string) {
+			globalConfig, err := findReadAndValidateConfig(config.configPath)
+			if err != nil {
+				fatalOnConfigError(err)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				log.Fatalf("Failed to get the current working directory: %v", err)
+			}
+			projectRoot, err := resolveProjectRoot(cwd, config.projectRoot)
+			if err != nil {
+				log.Fatalf("Failed to find the project root: %v", err)
+			}
+
+			err = runTag(globalConfig, &ProjectConfig{Path: projectRoot, Language: config.language})
+			if err != nil {
+				log.Fatalf("Failed to tag the release: %v", err)
+			}
+		},
+	}
+}
+
+func initReleaseCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "release",
+		Short: "Publish the latest release of the CHANGELOG on GitHub or GitLab, tagging the HEAD when it isn't tagged",
+		Run: func(_ *cobra.Command, _ []string) {
+			globalConfig, err := findReadAndValidateConfig(config.configPath)
+			if err != nil {
+				fatalOnConfigError(err)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				log.Fatalf("Failed to get the current working directory: %v", err)
+			}
+			projectRoot, err := resolveProjectRoot(cwd, config.projectRoot)
+			if err != nil {
+				log.Fatalf("Failed to find the project root: %v", err)
+			}
+
+			err = runRelease(globalConfig, &ProjectConfig{Path: projectRoot, Language: config.language})
+			if err != nil {
+				log.Fatalf("Failed to publish the release: %v", err)
+			}
+		},
+	}
+}
+
+func initChangelogCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "changelog",
+		Short: "Work with CHANGELOG files without touching any repository",
+	}
+}
+
+func initChangelogProcessCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "process",
+		Short: "Release the unreleased changes of a CHANGELOG, printing the new CHANGELOG and version",
+		Run: func(cmd *cobra.Command, _ []string) {
+			if !config.fromStdin {
+				log.Fatal("The CHANGELOG must be piped with --from-stdin")
+			}
+
+			exitCode := runChangelogProcess(config, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+			if exitCode != exitCodeSuccess {
+				os.Exit(exitCode)
+			}
+		},
+	}
+}
+
+func initChangelogTidyCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tidy [CHANGELOG.md]",
+		Short: "Check the CHANGELOG for the artifacts left by the previous versions, repairing them with --fix",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			changelogPath := "CHANGELOG.md"
+			if len(args) > 0 {
+				changelogPath = args[0]
+			}
+
+			err := runChangelogTidy(cmd.OutOrStdout(), changelogPath, config.fix)
+			if err != nil {
+				log.Fatalf("Failed to tidy the CHANGELOG: %v", err)
+			}
+		},
+	}
+}
+
+func initCheckCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check [CHANGELOG.md]",
+		Short: "Check the format of the CHANGELOG without bumping it, failing with the list of its problems",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			changelogPath := "CHANGELOG.md"
+			if len(args) > 0 {
+				changelogPath = args[0]
+			}
+
+			outputFormat, err := resolveOutputFormat(config.checkOutput)
+			if err != nil {
+				log.Fatalf("Failed to set the output format: %v", err)
+			}
+			setOutputFormat(outputFormat)
+
+			err = runCheck(cmd.OutOrStdout(), config.configPath, changelogPath, config.allowEmpty)
+			if err != nil {
+				log.Fatalf("Failed to check the CHANGELOG: %v", err)
+			}
+		},
+	}
+}
+
+func initNextCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "next [CHANGELOG.md]",
+		Short: "Print the next version of the CHANGELOG without changing anything",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			changelogPath := "CHANGELOG.md"
+			if len(args) > 0 {
+				changelogPath = args[0]
+			}
+
+			exitCode := runNext(cmd.OutOrStdout(), config, changelogPath)
+			if exitCode != exitCodeSuccess {
+				os.Exit(exitCode)
+			}
+		},
+	}
+}
+
+func initAuthCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "auth",
+		Short: "Manage the credentials stored for local interactive use",
+	}
+}
+
+func initAuthLoginCmd(config *Config, provider string, name string) *cobra.Command {
+	serviceType, _ := parseServiceType(provider)
+	return &cobra.Command{
+		Use:     provider,
+		Aliases: getServiceInfo(serviceType).aliases,
+		Short:   fmt.Sprintf("Authenticate to %s with the OAuth device flow and store the token", name),
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := runDeviceFlowLogin(provider, config.authHost, config.clientID, cmd.OutOrStdout())
+			if err != nil {
+				log.Fatalf("Failed to authenticate to %s: %v", name, err)
+			}
+		},
+	}
+}
+
+func initAuthStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the providers with stored credentials",
+		Run: func(cmd *cobra.Command, _ []string) {
+			store, err := openDefaultCredentialStore()
+			if err == nil {
+				err = writeAuthStatus(store, cmd.OutOrStdout())
+			}
+			if err != nil {
+				log.Fatalf("Failed to read the stored credentials: %v", err)
+			}
+		},
+	}
+}
+
+func initAuthLogoutCmd(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:       "logout [gitlab|github]",
+		Short:     "Remove the stored credentials of a provider (or of all providers)",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{authProviderGitLab, authProviderGitHub},
+		Run: func(cmd *cobra.Command, args []string) {
+			var provider string
+			var err error
+			if len(args) > 0 {
+				provider, err = parseAuthProvider(args[0])
+				if err != nil {
+					log.Fatalf("Failed to remove the stored credentials: %v", err)
+				}
+			}
+
+			store, err := openDefaultCredentialStore()
+			if err == nil {
+				err = logout(store, provider, config.authHost, cmd.OutOrStdout())
+			}
+			if err != nil {
+				log.Fatalf("Failed to remove the stored credentials: %v", err)
+			}
+		},
+	}
+}
+
 // findReadAndValidateConfig finds, reads and validates the config file
 func findReadAndValidateConfig(configPath string) (*GlobalConfig, error) {
 	// find the config file if not manually set
@@ -88,7 +507,7 @@ func findReadAndValidateConfig(configPath string) (*GlobalConfig, error) {
 		log.Warn("Missing languages key, using the default configuration")
 
 		var data []byte
-		data, err = downloadFile(defaultConfigURL)
+		data, err = readData(defaultConfigURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to download default config: %w", err)
 		}
@@ -109,17 +528,196 @@ func findReadAndValidateConfig(configPath string) (*GlobalConfig, error) {
 }
 
 func main() {
+	log.AddHook(&credentialsRedactingHook{})
+	log.AddHook(&runIDHook{})
+	configureAzureDevOpsTransport()
+
 	config := &Config{}
 	rootCmd := initRootCmd(config)
 	batchCmd := initBatchCmd(config)
 
 	rootCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
 	rootCmd.Flags().StringVarP(&config.language, "language", "l", "", "project language")
+	rootCmd.Flags().StringVarP(
+		&config.baseRef, "base-ref", "b", "", "ref to compute the bump against and to target the PR (instead of HEAD)",
+	)
+	rootCmd.Flags().StringVar(
+		&config.projectRoot, "project-root", "",
+		"directory of the project (by default, the working directory when it has a CHANGELOG.md, or else its repository)",
+	)
+	rootCmd.Flags().StringVar(
+		&config.output, "output", "",
+		"findings output format: text, github-actions (default in GitHub Actions) or json (also prints the run report)",
+	)
+	rootCmd.Flags().BoolVar(
+		&config.refreshDefaults, "refresh-defaults", false, "download the defaults again, ignoring the local cache",
+	)
+	rootCmd.Flags().BoolVar(
+		&config.fromCommits, "from-commits", false,
+		"write the Conventional Commits since the last tag in the Unreleased section before bumping",
+	)
+	rootCmd.Flags().StringVar(
+		&config.reportOut, "report-out", "", "path of the JSON report of the release prepared in this run",
+	)
+	rootCmd.Flags().BoolVar(
+		&config.ignoreConflictMarkers, "ignore-conflict-markers", false,
+		"process CHANGELOG files with merge conflict markers (refused by default)",
+	)
 	batchCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	batchCmd.Flags().BoolVar(
+		&config.ignoreConflictMarkers, "ignore-conflict-markers", false,
+		"process CHANGELOG files with merge conflict markers (refused by default)",
+	)
+	batchCmd.Flags().BoolVar(
+		&config.refreshDefaults, "refresh-defaults", false, "download the defaults again, ignoring the local cache",
+	)
+	batchCmd.Flags().StringVar(
+		&config.output, "output", "",
+		"findings output format: text, github-actions (default in GitHub Actions) or json (also prints the run report)",
+	)
+	batchCmd.Flags().IntVar(
+		&config.maxPRs, "max-prs", 0, "maximum amount of pull requests created in this run (overrides max_prs_per_run)",
+	)
+	batchCmd.Flags().IntVar(
+		&config.concurrency, "concurrency", 0,
+		"amount of projects processed at the same time (overrides concurrency, one at a time by default)",
+	)
+	batchCmd.Flags().BoolVar(
+		&config.train, "train", false, "run the release train, releasing the projects with release_train",
+	)
+	batchCmd.Flags().IntVar(
+		&config.limit, "limit", 0, "maximum amount of projects processed in this run (e.g. to canary a config change)",
+	)
+	batchCmd.Flags().BoolVar(
+		&config.shuffle, "shuffle", false, "process the projects in a random order instead of the config order",
+	)
+	batchCmd.Flags().Uint64Var(&config.seed, "seed", 0, "seed of --shuffle, to reproduce the same order")
+	batchCmd.Flags().StringVar(
+		&config.projectsFile, "projects-file", "",
+		"file with the projects to process (YAML or one URL per line), replacing the projects of the config file",
+	)
+	batchCmd.Flags().BoolVar(
+		&config.projectsStdin, "projects-stdin", false,
+		"read the projects to process from stdin (YAML or one URL per line), replacing the projects of the config file",
+	)
+	batchCmd.Flags().StringVar(
+		&config.digestOut, "digest-out", "", "path of the Markdown digest of the releases prepared in this run",
+	)
+	batchCmd.Flags().StringVar(
+		&config.reportOut, "report-out", "", "path of the JSON report of the releases prepared in this run",
+	)
+	batchCmd.Flags().DurationVar(
+		&config.lockTimeout, "lock-timeout", 0,
+		"how long to wait for another batch run holding the lock of the cache directory (e.g. 10m)",
+	)
+
+	// the other commands don't take the lock, using the cache read-only while a batch run holds it
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
+		if cmd != batchCmd {
+			shareDefaultsCache(defaultsCache)
+		}
+	}
+
+	configCmd := initConfigCmd()
+	configMigrateCmd := initConfigMigrateCmd(config)
+	configMigrateCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	configMigrateCmd.Flags().BoolVarP(
+		&config.write, "write", "w", false, "write the migrated config in place (keeping a .bak copy)",
+	)
+	configCmd.AddCommand(configMigrateCmd)
+	configValidateCmd := initConfigValidateCmd(config)
+	configValidateCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(initConfigSchemaCmd())
+
+	changelogCmd := initChangelogCmd()
+	changelogProcessCmd := initChangelogProcessCmd(config)
+	changelogProcessCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	changelogProcessCmd.Flags().BoolVar(&config.fromStdin, "from-stdin", false, "read the CHANGELOG from stdin")
+	changelogProcessCmd.Flags().StringVar(
+		&config.versionOut, "version-out", "", "file to write the new version to (instead of stderr)",
+	)
+	changelogProcessCmd.Flags().BoolVar(
+		&config.ignoreConflictMarkers, "ignore-conflict-markers", false,
+		"process CHANGELOG files with merge conflict markers (refused by default)",
+	)
+	changelogCmd.AddCommand(changelogProcessCmd)
+	changelogTidyCmd := initChangelogTidyCmd(config)
+	changelogTidyCmd.Flags().BoolVar(&config.fix, "fix", false, "repair the artifacts in place")
+	changelogCmd.AddCommand(changelogTidyCmd)
+
+	authCmd := initAuthCmd()
+	authGitLabCmd := initAuthLoginCmd(config, authProviderGitLab, "GitLab")
+	authGitHubCmd := initAuthLoginCmd(config, authProviderGitHub, "GitHub")
+	for _, authLoginCmd := range []*cobra.Command{authGitLabCmd, authGitHubCmd} {
+		authLoginCmd.Flags().StringVar(
+			&config.authHost, "host", "", "host of the provider (defaults to the public one, e.g. gitlab.com)",
+		)
+		authLoginCmd.Flags().StringVar(
+			&config.clientID, "client-id", "", "client ID of the OAuth application with the device flow enabled",
+		)
+		_ = authLoginCmd.MarkFlagRequired("client-id")
+		authCmd.AddCommand(authLoginCmd)
+	}
+	authCmd.AddCommand(initAuthStatusCmd())
+	authLogoutCmd := initAuthLogoutCmd(config)
+	authLogoutCmd.Flags().StringVar(
+		&config.authHost, "host", "", "host of the provider to log out of (defaults to all the hosts of the provider)",
+	)
+	authCmd.AddCommand(authLogoutCmd)
 
 	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(changelogCmd)
+	rootCmd.AddCommand(authCmd)
+
+	ciOutputCmd := initCIOutputCmd(config)
+	ciOutputCmd.Flags().StringVar(&config.report, "report", "", "path of the JSON report written with --report-out")
+	ciOutputCmd.Flags().StringVar(
+		&config.output, "format", "", "outputs format: github-actions or dotenv (default outside GitHub Actions)",
+	)
+	_ = ciOutputCmd.MarkFlagRequired("report")
+	rootCmd.AddCommand(ciOutputCmd)
+
+	previewCmd := initPreviewCmd(config)
+	previewCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	previewCmd.Flags().StringVar(&config.previewBaseRef, "base-ref", "origin/main", "ref the entries are added to")
+	previewCmd.Flags().StringVar(&config.previewHeadRef, "head-ref", "HEAD", "ref adding the entries")
+	previewCmd.Flags().StringVar(&config.previewFormat, "format", bumpPreviewFormatText, "output format: text or json")
+	rootCmd.AddCommand(previewCmd)
+
+	yankCmd := initYankCmd(config)
+	yankCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	yankCmd.Flags().StringVar(&config.reason, "reason", "", "reason of the yank, written below the release header")
+	yankCmd.Flags().StringVar(&config.projectRoot, "project-root", "", "root of the project, when not the current one")
+	rootCmd.AddCommand(yankCmd)
+
+	tagCmd := initTagCmd(config)
+	tagCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	tagCmd.Flags().StringVar(&config.projectRoot, "project-root", "", "root of the project, when not the current one")
+	rootCmd.AddCommand(tagCmd)
+	releaseCmd := initReleaseCmd(config)
+	releaseCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	releaseCmd.Flags().StringVar(&config.projectRoot, "project-root", "", "root of the project, when not the current one")
+	rootCmd.AddCommand(releaseCmd)
+	checkCmd := initCheckCmd(config)
+	checkCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	checkCmd.Flags().BoolVar(
+		&config.allowEmpty, "allow-empty", false, "accept the \"Unreleased\" section without entries",
+	)
+	checkCmd.Flags().StringVar(
+		&config.checkOutput, "output", "", "findings output format: text or github-actions (default in GitHub Actions)",
+	)
+	rootCmd.AddCommand(checkCmd)
+	nextCmd := initNextCmd(config)
+	nextCmd.Flags().StringVarP(&config.configPath, "config", "c", "", "config file path")
+	nextCmd.Flags().StringVar(&config.nextFormat, "format", nextVersionFormatText, "output format: text or json")
+	rootCmd.AddCommand(nextCmd)
 	err := rootCmd.Execute()
 	if err != nil {
 		log.Fatalf("Uncaught error: %v", err)
 	}
+
+	// let the stale defaults be refreshed for the next runs
+	defaultsCache.wait()
 }
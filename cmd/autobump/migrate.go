@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const yamlIndentation = 2
+
+var ErrCannotWriteRemoteConfig = errors.New("only local config files can be written")
+
+// ConfigMigration holds the result of migrating a config file to the current schema
+type ConfigMigration struct {
+	Data        []byte
+	UnknownKeys []string
+}
+
+// migrateConfig reads a config with lenient decoding and lists the keys unknown to the current schema.
+// No key was renamed since the first configuration files, so they are kept as they are.
+func migrateConfig(data []byte) (*ConfigMigration, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	migration := &ConfigMigration{}
+	if len(document.Content) > 0 {
+		migrateNode(document.Content[0], reflect.TypeOf(GlobalConfig{}), "", migration)
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(yamlIndentation)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	migration.Data = buffer.Bytes()
+
+	return migration, nil
+}
+
+// migrateNode walks a YAML node with the struct type it is decoded into, listing the unknown keys by their path
+func migrateNode(node *yaml.Node, typ reflect.Type, path string, migration *ConfigMigration) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() { //nolint:exhaustive // only containers need to be walked
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := getYAMLFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			fieldType, known := fields[key]
+			if !known {
+				migration.UnknownKeys = append(migration.UnknownKeys, joinConfigPath(path, key))
+				continue
+			}
+			migrateNode(node.Content[i+1], fieldType, joinConfigPath(path, key), migration)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for index, item := range node.Content {
+			migrateNode(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, index), migration)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			migrateNode(node.Content[i+1], typ.Elem(), joinConfigPath(path, node.Content[i].Value), migration)
+		}
+	}
+}
+
+// joinConfigPath joins the parent path and the key with a dot
+func joinConfigPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// getYAMLFields returns the YAML keys accepted by a struct type and their types
+func getYAMLFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			fields[name] = field.Type
+		}
+	}
+	return fields
+}
+
+// migrateConfigFile migrates the config file, printing the result or writing it in place (keeping a backup)
+func migrateConfigFile(configPath string, write bool) error {
+	data, err := readData(configPath)
+	if err != nil {
+		return err
+	}
+
+	migration, err := migrateConfig(data)
+	if err != nil {
+		return err
+	}
+
+	for _, unknownKey := range migration.UnknownKeys {
+		log.Warnf("Unknown key '%s' must be reviewed manually", unknownKey)
+	}
+
+	if !write {
+		fmt.Print(string(migration.Data)) //nolint:forbidigo // the migrated config is the command output
+		return nil
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrCannotWriteRemoteConfig, configPath)
+	}
+
+	backupPath := configPath + ".bak"
+	err = os.WriteFile(backupPath, data, info.Mode())
+	if err != nil {
+		return fmt.Errorf("failed to write config backup: %w", err)
+	}
+
+	err = os.WriteFile(configPath, migration.Data, info.Mode())
+	if err != nil {
+		return fmt.Errorf("failed to write migrated config: %w", err)
+	}
+
+	log.Infof("Migrated config written to %s (backup at %s)", configPath, backupPath)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// oldConfig is the example configuration shipped by the first releases, with a key that was never supported
+const oldConfig = `# GitLab/Azure DevOps personal access token used to create MRs/PRs
+gitlab_access_token: "glpat-TOKEN"
+azure_devops_access_token: "azure-devops-token"
+languages:
+  python:
+    extensions:
+      - "py"
+    special_patterns:
+      - "pyproject.toml"
+    version_files:
+      - path: "{project_name}/__init__.py"
+        patterns: ["(__version__\\s*=\\s*\")\\d+\\.\\d+\\.\\d+(\")"]
+projects:
+  # path is simply the path of the repository
+  - path: "/home/user/repo1"
+  - path: "/home/user/repo2"
+    language: "Java"
+  - path: "https://gitlab.com/user/repo4.git"
+    project_access_token: "glpat-TOKEN"
+    colour: "blue"
+`
+
+func TestMigrateConfig_UnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	migration, err := migrateConfig([]byte("unknown: true\nprojects:\n  - path: /tmp\n    colour: blue\n"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unknown", "projects[0].colour"}, migration.UnknownKeys)
+}
+
+func TestMigrateConfig_OldExampleConfig(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	data := []byte(strings.ReplaceAll(oldConfig, "    colour: \"blue\"\n", ""))
+
+	// Act
+	migration, err := migrateConfig(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, migration.UnknownKeys)
+	globalConfig, err := decodeConfig(migration.Data)
+	require.NoError(t, err)
+	assert.Equal(t, "glpat-TOKEN", globalConfig.GitLabAccessToken)
+	assert.Equal(t, "azure-devops-token", globalConfig.AzureDevOpsAccessToken)
+	assert.Len(t, globalConfig.LanguagesConfig["python"].VersionFiles, 1)
+	assert.Equal(t, "Java", globalConfig.Projects[1].Language)
+	assert.Equal(t, "glpat-TOKEN", globalConfig.Projects[2].ProjectAccessToken)
+}
+
+func TestMigrateConfigFile_Write(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	configPath := filepath.Join(t.TempDir(), "autobump.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(oldConfig), 0o600))
+
+	// Act
+	err := migrateConfigFile(configPath, true)
+
+	// Assert
+	require.NoError(t, err)
+
+	backup, err := os.ReadFile(configPath + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, oldConfig, string(backup))
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# path is simply the path of the repository")
+
+	// the unknown keys are kept, to be reviewed manually
+	migration, err := migrateConfig(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"projects[2].colour"}, migration.UnknownKeys)
+}
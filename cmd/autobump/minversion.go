@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// exitCodeConfigTooNew is the exit code when the configuration requires a newer AutoBump
+const exitCodeConfigTooNew = 2
+
+var (
+	ErrAutoBumpTooOld            = errors.New("AutoBump is too old for this configuration")
+	ErrInvalidMinAutoBumpVersion = errors.New("invalid min_autobump_version")
+)
+
+// checkMinAutoBumpVersion compares the version required by the configuration with the running one.
+// The key is read on its own, before the strict decoding, so an old binary fails with this error
+// instead of the unknown keys of the configuration.
+func checkMinAutoBumpVersion(data []byte, binaryVersion string) error {
+	var document struct {
+		MinAutoBumpVersion string `yaml:"min_autobump_version"`
+	}
+	if yaml.Unmarshal(data, &document) != nil || document.MinAutoBumpVersion == "" {
+		// the invalid documents are reported by the strict decoding
+		return nil
+	}
+
+	required, err := semver.NewVersion(document.MinAutoBumpVersion)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrInvalidMinAutoBumpVersion, document.MinAutoBumpVersion, err)
+	}
+	running, err := semver.NewVersion(binaryVersion)
+	if err != nil {
+		// the development builds have no version to compare
+		log.Debugf("Not checking min_autobump_version, the running version is %q", binaryVersion)
+		return nil
+	}
+	if running.LessThan(required) {
+		return fmt.Errorf(
+			"%w: this configuration requires autobump >= %s, you have %s",
+			ErrAutoBumpTooOld, versionString(required), versionString(running),
+		)
+	}
+	return nil
+}
+
+// fatalOnConfigError exits when the configuration couldn't be read, with a dedicated exit code
+// when it requires a newer AutoBump
+func fatalOnConfigError(err error) {
+	if errors.Is(err, ErrAutoBumpTooOld) {
+		log.Errorf("Failed to read config: %v", err)
+		os.Exit(exitCodeConfigTooNew)
+	}
+	log.Fatalf("Failed to read config: %v", err)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMinAutoBumpVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		minVersion    string
+		binaryVersion string
+		expectedErr   error
+	}{
+		{name: "older binary", minVersion: "3.2.0", binaryVersion: "3.0.1", expectedErr: ErrAutoBumpTooOld},
+		{name: "equal binary", minVersion: "3.2.0", binaryVersion: "3.2.0"},
+		{name: "newer binary", minVersion: "3.2.0", binaryVersion: "3.10.0"},
+		{name: "prefixed binary", minVersion: "3.2.0", binaryVersion: "v3.2.1"},
+		{name: "development binary", minVersion: "3.2.0", binaryVersion: "dev"},
+		{name: "malformed version", minVersion: "three", binaryVersion: "3.2.0", expectedErr: ErrInvalidMinAutoBumpVersion},
+		{name: "no version", binaryVersion: "3.2.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			data := []byte("min_autobump_version: \"" + test.minVersion + "\"\n")
+
+			// Act
+			err := checkMinAutoBumpVersion(data, test.binaryVersion)
+
+			// Assert
+			if test.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestCheckMinAutoBumpVersion_ExplainsTheRequirement(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := checkMinAutoBumpVersion([]byte("min_autobump_version: 3.2.0\n"), "3.0.1")
+
+	// Assert
+	require.ErrorIs(t, err, ErrAutoBumpTooOld)
+	assert.Contains(t, err.Error(), "this configuration requires autobump >= 3.2.0, you have 3.0.1")
+}
+
+func TestCheckMinAutoBumpVersion_ComesBeforeTheUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	data := []byte("min_autobump_version: \"999.0.0\"\nan_option_of_the_future: true\n")
+
+	// Act
+	err := checkMinAutoBumpVersion(data, "3.0.1")
+
+	// Assert
+	require.ErrorIs(t, err, ErrAutoBumpTooOld)
+}
+
+func TestDecodeConfig_AcceptsTheMinAutoBumpVersion(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	globalConfig, err := decodeConfig([]byte("min_autobump_version: \"0.0.1\"\n"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.1", globalConfig.MinAutoBumpVersion)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// formats of the next version
+const (
+	nextVersionFormatText = "text"
+	nextVersionFormatJSON = "json"
+)
+
+var ErrInvalidNextVersionFormat = errors.New("invalid next version format")
+
+// NextVersion is the version the CHANGELOG would be released with, along with the part of the version bumped
+type NextVersion struct {
+	PreviousVersion string `json:"previous_version"`
+	NextVersion     string `json:"next_version"`
+	Bump            string `json:"bump"`
+}
+
+// computeNextVersion computes the next version with the same rules as a release (e.g. the deduplication of the
+// entries and the non-bumping sections), without changing the CHANGELOG
+func computeNextVersion(lines []string, changelogConfig ChangelogConfig) (*NextVersion, error) {
+	latestVersion, err := findLatestVersion(lines)
+	if err != nil {
+		return nil, err
+	}
+	nextVersion, _, err := processChangelog(lines, changelogConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &NextVersion{
+		PreviousVersion: versionString(latestVersion),
+		NextVersion:     versionString(nextVersion),
+		Bump:            getBumpKind(latestVersion, nextVersion, changelogConfig.VersioningScheme),
+	}, nil
+}
+
+// writeNextVersion writes the next version alone for the scripts, or as JSON along with the bump
+func writeNextVersion(output io.Writer, next *NextVersion, format string) error {
+	var err error
+	switch format {
+	case "", nextVersionFormatText:
+		_, err = fmt.Fprintln(output, next.NextVersion)
+	case nextVersionFormatJSON:
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(next)
+	default:
+		return fmt.Errorf(
+			"%w: %q (expected %s or %s)", ErrInvalidNextVersionFormat, format, nextVersionFormatText, nextVersionFormatJSON,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the next version: %w", err)
+	}
+	return nil
+}
+
+// runNext prints the next version of the CHANGELOG, returning the exit code of the command:
+// the CHANGELOG without unreleased changes exits like "changelog process" does
+func runNext(output io.Writer, config *Config, changelogPath string) int {
+	globalConfig, err := readStandaloneConfig(config.configPath)
+	if err != nil {
+		log.Errorf("Failed to read config: %v", err)
+		return exitCodeFailure
+	}
+
+	changelogConfig := globalConfig.Changelog
+	changelogConfig.Date = getReleaseDate(globalConfig)
+	changelogConfig.VersionPolicy = globalConfig.VersionPolicy
+
+	lines, err := readLines(changelogPath, getMaxFileSize(globalConfig))
+	if err != nil {
+		log.Errorf("Failed to read the CHANGELOG: %v", err)
+		return exitCodeFailure
+	}
+
+	next, err := computeNextVersion(lines, changelogConfig)
+	switch {
+	case errors.Is(err, ErrNoChangesFoundInUnreleased):
+		log.Warnf("Nothing to release: %v", err)
+		return exitCodeEmptyUnreleased
+	case err != nil:
+		log.Errorf("Failed to compute the next version: %v", err)
+		return exitCodeInvalidChangelog
+	}
+
+	err = writeNextVersion(output, next, config.nextFormat)
+	if err != nil {
+		log.Error(err)
+		return exitCodeFailure
+	}
+	return exitCodeSuccess
+}
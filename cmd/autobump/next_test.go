@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeNextVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected *NextVersion
+	}{
+		{
+			name:     "should bump the minor version of the added entries",
+			content:  changelogOriginal,
+			expected: &NextVersion{PreviousVersion: "1.0.1", NextVersion: "1.1.0", Bump: "minor"},
+		},
+		{
+			name: "should bump the major version of the breaking changes",
+			content: changelogTemplate + "\n\n### Changed\n\n- **BREAKING CHANGE:** removed the v1 API\n\n" +
+				"## [1.0.1] - 1984-01-01\n",
+			expected: &NextVersion{PreviousVersion: "1.0.1", NextVersion: "2.0.0", Bump: "major"},
+		},
+		{
+			name: "should count the duplicated entries once",
+			content: changelogTemplate + "\n\n### Fixed\n\n- fixed the crash\n\n### Fixed\n\n- fixed the crash\n\n" +
+				"## [1.0.1] - 1984-01-01\n",
+			expected: &NextVersion{PreviousVersion: "1.0.1", NextVersion: "1.0.2", Bump: "patch"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			lines := strings.Split(test.content, "\n")
+
+			// Act
+			next, err := computeNextVersion(lines, ChangelogConfig{})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, next)
+		})
+	}
+}
+
+func TestWriteNextVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "should print the version alone", format: nextVersionFormatText, expected: "1.1.0\n"},
+		{
+			name:     "should print the bump in JSON",
+			format:   nextVersionFormatJSON,
+			expected: "{\n  \"previous_version\": \"1.0.1\",\n  \"next_version\": \"1.1.0\",\n  \"bump\": \"minor\"\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			next := &NextVersion{PreviousVersion: "1.0.1", NextVersion: "1.1.0", Bump: "minor"}
+			var output bytes.Buffer
+
+			// Act
+			err := writeNextVersion(&output, next, test.format)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, output.String())
+		})
+	}
+}
+
+func TestWriteNextVersion_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := writeNextVersion(&bytes.Buffer{}, &NextVersion{}, "yaml")
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidNextVersionFormat)
+}
+
+func TestRunNext_KeepsTheChangelog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		content          string
+		expectedExitCode int
+		expectedOutput   string
+	}{
+		{
+			name:             "should print the next version",
+			content:          changelogOriginal,
+			expectedExitCode: exitCodeSuccess,
+			expectedOutput:   "1.1.0\n",
+		},
+		{
+			name:             "should exit like changelog process without unreleased changes",
+			content:          changelogTagged,
+			expectedExitCode: exitCodeEmptyUnreleased,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			dir := t.TempDir()
+			configPath := filepath.Join(dir, "autobump.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte("changelog: {}\n"), 0o600))
+			changelogPath := filepath.Join(dir, "CHANGELOG.md")
+			require.NoError(t, os.WriteFile(changelogPath, []byte(test.content), 0o600))
+			var output bytes.Buffer
+
+			// Act
+			exitCode := runNext(&output, &Config{configPath: configPath}, changelogPath)
+
+			// Assert
+			assert.Equal(t, test.expectedExitCode, exitCode)
+			assert.Equal(t, test.expectedOutput, output.String())
+			unchanged, err := os.ReadFile(changelogPath)
+			require.NoError(t, err)
+			assert.Equal(t, test.content, string(unchanged))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// breakingEntryPrefix starts the entries of the breaking changes
+const breakingEntryPrefix = "- **BREAKING CHANGE:**"
+
+// migrationNotePrefixRegex matches the "migration:" prefix starting a migration note written without indentation
+var migrationNotePrefixRegex = regexp.MustCompile(`(?i)^>\s*migration:\s*`)
+
+// findMigrationNotes returns the lines of the migration notes, mapped to the line of the breaking entry they follow.
+// A note is a block quote right below the entry, either indented or starting with "> migration:".
+func findMigrationNotes(unreleasedSection []string) map[int]int {
+	notes := make(map[int]int)
+	owner := -1
+	insideNote := false
+	for index, line := range unreleasedSection {
+		trimmedLine := strings.TrimSpace(line)
+		indented := line != strings.TrimLeft(line, " \t")
+		if owner != -1 && strings.HasPrefix(trimmedLine, ">") &&
+			(insideNote || indented || migrationNotePrefixRegex.MatchString(trimmedLine)) {
+			notes[index] = owner
+			insideNote = true
+			continue
+		}
+
+		insideNote = false
+		owner = -1
+		if strings.HasPrefix(line, breakingEntryPrefix) {
+			owner = index
+		}
+	}
+	return notes
+}
+
+// getEntryHeadline returns the first line of the entry, without the migration note attached to it
+func getEntryHeadline(entry string) string {
+	headline, _, _ := strings.Cut(entry, "\n")
+	return headline
+}
+
+// formatMigrationNotes lists the migration notes of the breaking entries, each one collapsed under its entry
+func formatMigrationNotes(sectionEntries map[string][]string) string {
+	var blocks []string
+	for _, key := range getSectionsOrder(sectionEntries) {
+		for _, entry := range sectionEntries[key] {
+			headline, note, found := strings.Cut(entry, "\n")
+			if !found || !strings.HasPrefix(headline, breakingEntryPrefix) {
+				continue
+			}
+
+			var noteLines []string
+			for _, line := range strings.Split(note, "\n") {
+				line = migrationNotePrefixRegex.ReplaceAllString(strings.TrimSpace(line), "")
+				noteLines = append(noteLines, strings.TrimSpace(strings.TrimPrefix(line, ">")))
+			}
+			summary := strings.TrimSpace(strings.TrimPrefix(headline, breakingEntryPrefix))
+			blocks = append(blocks, fmt.Sprintf(
+				"<details>\n<summary>%s</summary>\n\n%s\n\n</details>", summary, strings.Join(noteLines, "\n"),
+			))
+		}
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+	return "### Migration notes\n\n" + strings.Join(blocks, "\n\n")
+}
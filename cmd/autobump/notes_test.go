@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// migrationNoteChangelog has a breaking entry with a migration note, written below an entry sorted after it
+const migrationNoteChangelog = `# Changelog
+
+## [Unreleased]
+
+### Changed
+
+- **BREAKING CHANGE:** renamed the ` + "`--out`" + ` flag to ` + "`--output`" + `
+  > Replace ` + "`--out`" + ` by ` + "`--output`" + ` in the scripts.
+  > The short flag ` + "`-o`" + ` is unchanged.
+- (cli) changed the colors of the logs
+
+## [1.2.0] - 2024-01-01
+
+### Added
+
+- added the reports
+`
+
+func TestProcessChangelog_MigrationNoteFollowsItsEntry(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(migrationNoteChangelog, "\n")
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", versionString(version))
+	assert.Contains(t, strings.Join(newChangelog, "\n"), "### Changed\n\n"+
+		"- (cli) changed the colors of the logs\n"+
+		"- **BREAKING CHANGE:** renamed the `--out` flag to `--output`\n"+
+		"  > Replace `--out` by `--output` in the scripts.\n"+
+		"  > The short flag `-o` is unchanged.\n")
+}
+
+func TestGetUnreleasedSummary_MigrationNoteIsNotAnEntry(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(migrationNoteChangelog, "\n")
+
+	// Act
+	summary, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.SectionCounts["Changed"])
+	assert.Empty(t, summary.UnrecognizedLines)
+}
+
+func TestFindMigrationNotes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Changed",
+		"- **BREAKING CHANGE:** removed the v1 API",
+		"> migration: call the v2 API",
+		"> with the same parameters",
+		"- changed the logs",
+		"  > not a note, the entry isn't breaking",
+		"- **BREAKING CHANGE:** removed the XML output",
+		"> not a note, neither indented nor prefixed",
+	}
+
+	// Act
+	notes := findMigrationNotes(unreleasedSection)
+
+	// Assert
+	assert.Equal(t, map[int]int{2: 1, 3: 1}, notes)
+}
+
+func TestGetPullRequestDescription_MigrationNotes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	summary, err := getUnreleasedSummary(strings.Split(migrationNoteChangelog, "\n"), ChangelogConfig{})
+	require.NoError(t, err)
+	projectConfig := &ProjectConfig{}
+	state := &projectState{migrationNotes: formatMigrationNotes(summary.SectionEntries)}
+
+	// Act
+	description := getPullRequestDescription(&GlobalConfig{}, projectConfig, state, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "### Migration notes\n\n"+
+		"<details>\n"+
+		"<summary>renamed the `--out` flag to `--output`</summary>\n\n"+
+		"Replace `--out` by `--output` in the scripts.\n"+
+		"The short flag `-o` is unchanged.\n\n"+
+		"</details>", description)
+}
+
+func TestDeduplicateEntries_RemovesMigrationNoteOfRemovedEntry(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	unreleasedSection := []string{
+		"### Added",
+		"- **BREAKING CHANGE:** replaced the configuration",
+		"### Changed",
+		"- **BREAKING CHANGE:** replaced the configuration",
+		"  > move the settings to autobump.yaml",
+	}
+
+	// Act
+	deduplicated, _ := deduplicateEntries(unreleasedSection, ChangelogConfig{CrossSectionDedup: true})
+
+	// Assert
+	assert.Equal(t, []string{"### Added", "- **BREAKING CHANGE:** replaced the configuration", "### Changed"}, deduplicated)
+}
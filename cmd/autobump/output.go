@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	outputText          = "text"
+	outputGitHubActions = "github-actions"
+	// the logs are kept, while the report of the run is printed at its end
+	outputJSON = "json"
+)
+
+const (
+	findingError   = "error"
+	findingWarning = "warning"
+	findingNotice  = "notice"
+)
+
+var ErrInvalidOutputFormat = errors.New("invalid output format")
+
+var (
+	outputFormatMutex sync.RWMutex
+	outputFormat      = outputText
+)
+
+// Finding is a problem (or information) about a file, reported in the logs and as a CI annotation
+type Finding struct {
+	Level   string
+	File    string
+	Line    int
+	Message string
+}
+
+// resolveOutputFormat returns the output format, enabling the GitHub Actions annotations
+// when running in GitHub Actions and the format was not set
+func resolveOutputFormat(format string) (string, error) {
+	switch format {
+	case "":
+		if os.Getenv("GITHUB_ACTIONS") == "true" {
+			return outputGitHubActions, nil
+		}
+		return outputText, nil
+	case outputText, outputGitHubActions, outputJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf(
+			"%w: %s (expected %s, %s or %s)", ErrInvalidOutputFormat, format, outputText, outputGitHubActions, outputJSON,
+		)
+	}
+}
+
+// setOutputFormat sets the format used to report the findings
+func setOutputFormat(format string) {
+	outputFormatMutex.Lock()
+	defer outputFormatMutex.Unlock()
+	outputFormat = format
+}
+
+// isOutputFormat tells whether the output format was set to the format
+func isOutputFormat(format string) bool {
+	outputFormatMutex.RLock()
+	defer outputFormatMutex.RUnlock()
+	return outputFormat == format
+}
+
+// reportFinding logs the finding, also printing it as a workflow command when running in GitHub Actions
+func reportFinding(finding Finding, logger *log.Entry) {
+	message := finding.Message
+	if finding.File != "" {
+		message = fmt.Sprintf("%s:%d: %s", finding.File, finding.Line, finding.Message)
+	}
+
+	switch finding.Level {
+	case findingError:
+		logger.Error(message)
+	case findingWarning:
+		logger.Warn(message)
+	default:
+		logger.Info(message)
+	}
+
+	outputFormatMutex.RLock()
+	defer outputFormatMutex.RUnlock()
+	if outputFormat == outputGitHubActions {
+		fmt.Println(formatWorkflowCommand(finding)) //nolint:forbidigo // workflow commands are read from stdout
+	}
+}
+
+// formatWorkflowCommand formats the finding as a GitHub Actions workflow command (e.g. "::error file=...::...")
+func formatWorkflowCommand(finding Finding) string {
+	var properties []string
+	if finding.File != "" {
+		properties = append(properties, "file="+escapeWorkflowProperty(finding.File))
+		if finding.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", finding.Line))
+		}
+	}
+
+	command := "::" + finding.Level
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	return command + "::" + escapeWorkflowData(finding.Message)
+}
+
+// escapeWorkflowData escapes the message of a workflow command
+func escapeWorkflowData(data string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(data)
+}
+
+// escapeWorkflowProperty escapes the property values of a workflow command
+func escapeWorkflowProperty(property string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(property)
+}
+
+// getUnreleasedFindings returns the findings about the entries of the "Unreleased" section
+func getUnreleasedFindings(summary UnreleasedSummary, changelogFile string) []Finding {
+	var findings []Finding
+	// the entries of the non-bumping sections are recognized, even if they don't make a release
+	if summary.RecognizedEntries == 0 && summary.CandidateLines > 0 {
+		findings = append(findings, Finding{
+			Level: findingWarning,
+			File:  changelogFile,
+			Line:  summary.UnreleasedLine,
+			Message: fmt.Sprintf(
+				"Unreleased has %d lines but 0 recognized entries — check formatting",
+				summary.CandidateLines,
+			),
+		})
+	}
+
+	for _, line := range summary.UnrecognizedLines {
+		findings = append(findings, Finding{
+			Level:   findingWarning,
+			File:    changelogFile,
+			Line:    line,
+			Message: "line is not under a known section heading, it will not be released",
+		})
+	}
+	return findings
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnreleasedFindings_WorkflowCommands(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(changelogTemplate+`
+
+* An entry above the sections.
+Some text without bullet.
+
+#### Addded
+
+- An entry under an unknown section.
+
+## [1.0.0] - 2024-01-01
+
+### Added
+
+- Initial release.`, "\n")
+	summary, err := getUnreleasedSummary(changelog, ChangelogConfig{})
+	require.NoError(t, err)
+
+	// Act
+	var commands []string
+	for _, finding := range getUnreleasedFindings(summary, "docs/CHANGELOG.md") {
+		commands = append(commands, formatWorkflowCommand(finding))
+	}
+
+	// Assert
+	assert.Equal(t, []string{
+		"::warning file=docs/CHANGELOG.md,line=8::Unreleased has 3 lines but 0 recognized entries — check formatting",
+		"::warning file=docs/CHANGELOG.md,line=10::line is not under a known section heading, it will not be released",
+		"::warning file=docs/CHANGELOG.md,line=11::line is not under a known section heading, it will not be released",
+		"::warning file=docs/CHANGELOG.md,line=15::line is not under a known section heading, it will not be released",
+	}, commands)
+}
+
+func TestFormatWorkflowCommand_Escaping(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	errorCommand := formatWorkflowCommand(Finding{
+		Level:   findingError,
+		File:    "a,b:c.md",
+		Line:    1,
+		Message: "100% broken\nsecond line",
+	})
+	noticeCommand := formatWorkflowCommand(Finding{Level: findingNotice, Message: "project bumped from 1.0.0 to 1.1.0"})
+
+	// Assert
+	assert.Equal(t, "::error file=a%2Cb%3Ac.md,line=1::100%25 broken%0Asecond line", errorCommand)
+	assert.Equal(t, "::notice::project bumped from 1.0.0 to 1.1.0", noticeCommand)
+}
+
+func TestResolveOutputFormat(t *testing.T) {
+	// Arrange
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	// Act
+	autoFormat, autoErr := resolveOutputFormat("")
+	overriddenFormat, overriddenErr := resolveOutputFormat(outputText)
+	_, invalidErr := resolveOutputFormat("yaml")
+
+	// Assert
+	require.NoError(t, autoErr)
+	assert.Equal(t, outputGitHubActions, autoFormat)
+	require.NoError(t, overriddenErr)
+	assert.Equal(t, outputText, overriddenFormat)
+	require.ErrorIs(t, invalidErr, ErrInvalidOutputFormat)
+}
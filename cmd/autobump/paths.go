@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrPathOutsideRepository = errors.New("the path is outside the repository")
+
+// ensureWithinRepo checks that the path (absolute or relative to the root) is inside the repository, after
+// resolving the ".." elements and the symlinks, returning it relative to the root. The files that don't exist
+// yet are checked through their closest existing parent directory.
+func ensureWithinRepo(root string, path string) (string, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the repository path: %w", err)
+	}
+	realRoot, err = filepath.Abs(realRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the repository path: %w", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	realPath, err := evalExistingSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	relativePath, err := filepath.Rel(realRoot, realPath)
+	if err == nil && !isParentTraversal(relativePath) {
+		return relativePath, nil
+	}
+
+	// on case-insensitive filesystems the root can be written with another case, so look for
+	// a parent directory which is the same directory as the root
+	rootInfo, err := os.Stat(realRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat the repository path: %w", err)
+	}
+	for dir := filepath.Dir(realPath); ; dir = filepath.Dir(dir) {
+		if info, statErr := os.Stat(dir); statErr == nil && os.SameFile(rootInfo, info) {
+			relativePath, err = filepath.Rel(dir, realPath)
+			if err == nil {
+				return relativePath, nil
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrPathOutsideRepository, realPath)
+}
+
+// evalExistingSymlinks resolves the symlinks of the longest existing part of the path, keeping the rest as is
+func evalExistingSymlinks(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	var missing []string
+	for {
+		realPath, evalErr := filepath.EvalSymlinks(path)
+		if evalErr == nil {
+			return filepath.Join(append([]string{realPath}, missing...)...), nil
+		}
+		if !os.IsNotExist(evalErr) {
+			return "", fmt.Errorf("failed to resolve %s: %w", path, evalErr)
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("failed to resolve %s: %w", path, evalErr)
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// isParentTraversal checks whether the relative path goes up from its base directory
+func isParentTraversal(relativePath string) bool {
+	return relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMonorepoFixture creates a directory with a project and a shared directory next to it
+func newMonorepoFixture(t *testing.T) (string, string) {
+	t.Helper()
+
+	monorepoPath := t.TempDir()
+	projectPath := filepath.Join(monorepoPath, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "src"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(monorepoPath, "shared"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "src", "version.txt"), []byte("1.0.0"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(monorepoPath, "shared", "version.txt"), []byte("1.0.0"), 0o600))
+	return monorepoPath, projectPath
+}
+
+func TestEnsureWithinRepo_RelativePathInside(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectPath := newMonorepoFixture(t)
+
+	// Act
+	relativePath, err := ensureWithinRepo(projectPath, filepath.Join("src", "..", "src", "version.txt"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("src", "version.txt"), relativePath)
+}
+
+func TestEnsureWithinRepo_ParentTraversal(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectPath := newMonorepoFixture(t)
+
+	// Act
+	_, err := ensureWithinRepo(projectPath, filepath.Join("..", "shared", "version.txt"))
+	_, joinedErr := ensureWithinRepo(projectPath, filepath.Join(projectPath, "..", "shared", "version.txt"))
+
+	// Assert
+	require.ErrorIs(t, err, ErrPathOutsideRepository)
+	require.ErrorIs(t, joinedErr, ErrPathOutsideRepository)
+}
+
+func TestEnsureWithinRepo_AbsolutePaths(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	monorepoPath, projectPath := newMonorepoFixture(t)
+
+	// Act
+	insidePath, insideErr := ensureWithinRepo(projectPath, filepath.Join(projectPath, "src", "version.txt"))
+	_, outsideErr := ensureWithinRepo(projectPath, filepath.Join(monorepoPath, "shared", "version.txt"))
+	_, rootErr := ensureWithinRepo(projectPath, monorepoPath)
+
+	// Assert
+	require.NoError(t, insideErr)
+	assert.Equal(t, filepath.Join("src", "version.txt"), insidePath)
+	require.ErrorIs(t, outsideErr, ErrPathOutsideRepository)
+	require.ErrorIs(t, rootErr, ErrPathOutsideRepository)
+}
+
+func TestEnsureWithinRepo_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectPath := newMonorepoFixture(t)
+
+	// Act
+	relativePath, err := ensureWithinRepo(projectPath, filepath.Join("docs", "CHANGELOG.md"))
+	_, outsideErr := ensureWithinRepo(projectPath, filepath.Join("..", "docs", "CHANGELOG.md"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("docs", "CHANGELOG.md"), relativePath)
+	require.ErrorIs(t, outsideErr, ErrPathOutsideRepository)
+}
+
+func TestEnsureWithinRepo_SiblingWithSamePrefix(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	monorepoPath, projectPath := newMonorepoFixture(t)
+	siblingPath := filepath.Join(monorepoPath, "project-other", "version.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(siblingPath), 0o755))
+	require.NoError(t, os.WriteFile(siblingPath, []byte("1.0.0"), 0o600))
+
+	// Act
+	_, err := ensureWithinRepo(projectPath, siblingPath)
+
+	// Assert
+	require.ErrorIs(t, err, ErrPathOutsideRepository)
+}
+
+func TestEnsureWithinRepo_CaseInsensitiveFilesystem(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectPath := newMonorepoFixture(t)
+	if _, err := os.Stat(strings.ToUpper(projectPath)); err != nil {
+		t.Skip("the filesystem is case-sensitive")
+	}
+
+	// Act
+	relativePath, err := ensureWithinRepo(projectPath, filepath.Join(strings.ToUpper(projectPath), "src", "version.txt"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("SRC", "VERSION.TXT"), strings.ToUpper(relativePath))
+}
+
+func TestGetVersionFiles_OutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, projectPath := newMonorepoFixture(t)
+	globalConfig := &GlobalConfig{LanguagesConfig: map[string]LanguageConfig{
+		"text": {VersionFiles: []VersionFile{{Path: "../shared/version.txt", Patterns: []string{`()\d+\.\d+\.\d+()`}}}},
+	}}
+	projectConfig := &ProjectConfig{Path: projectPath, Name: "project", Language: "text"}
+
+	// Act
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrPathOutsideRepository)
+	assert.Contains(t, err.Error(), `"../shared/version.txt"`)
+	assert.Nil(t, versionFiles)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrBranchCollision = errors.New("projects would create the same bump branch")
+
+// bumpPlan is the bump expected for a project, computed before changing anything
+type bumpPlan struct {
+	project    string
+	repository string // identity of the repository, the same for every path pointing to it
+	branch     string // bump branch, or the base ref when it can only be known after cloning
+}
+
+// planBumps computes the plan of each project without cloning: the bump branch is computed
+// for the local projects, while for the remote ones the CHANGELOG is the same when the base ref is
+func planBumps(globalConfig *GlobalConfig) []bumpPlan {
+	plans := make([]bumpPlan, 0, len(globalConfig.Projects))
+	for _, project := range globalConfig.Projects {
+		plan := bumpPlan{
+			project:    project.Name,
+			repository: getRepositoryIdentity(project.Path),
+			branch:     "base:" + project.BaseRef,
+		}
+
+		if project.BaseRef == "" && !isRemoteProject(project.Path) {
+			if branch, err := planLocalBumpBranch(globalConfig, project); err == nil {
+				plan.branch = branch
+			} else {
+				log.Debugf("Unable to plan the bump of project %s: %v", project.Name, err)
+			}
+		}
+		plans = append(plans, plan)
+	}
+	return plans
+}
+
+// planLocalBumpBranch computes the bump branch of a local project from its CHANGELOG
+func planLocalBumpBranch(globalConfig *GlobalConfig, project ProjectConfig) (string, error) {
+	lines, err := readLines(findChangelogPath(&project, standardLogEntry()), getMaxFileSize(globalConfig))
+	if err != nil {
+		return "", err
+	}
+
+	changelogConfig := globalConfig.Changelog
+	changelogConfig.VersioningScheme = project.VersioningScheme
+	changelogConfig.CalVerFormat = project.CalVerFormat
+	changelogConfig.VersionPolicy = globalConfig.VersionPolicy
+	changelogConfig.VersionPolicyDir = project.Path
+	if len(project.VersionStreams) > 0 {
+		changelogConfig.VersionStreams = project.VersionStreams
+		changelogConfig.DefaultVersionStream = project.DefaultVersionStream
+		versions, _, err := processVersionStreams(lines, changelogConfig)
+		if err != nil {
+			return "", err
+		}
+		releaseName := formatStreamVersions(project.VersionStreams, versions)
+		return getBumpBranchName(globalConfig, &project, &projectState{}, releaseName, standardLogEntry()), nil
+	}
+
+	nextVersion, _, err := processChangelog(lines, changelogConfig)
+	if err != nil {
+		return "", err
+	}
+	state := &projectState{resolvedVersionPrefix: resolveVersionPrefix(&project, lines, nil, standardLogEntry())}
+	return getBumpBranchName(globalConfig, &project, state, versionString(nextVersion), standardLogEntry()), nil
+}
+
+// checkBranchCollisions fails when two projects point to the same repository and would create the same branch
+func checkBranchCollisions(plans []bumpPlan) error {
+	seen := make(map[string]string)
+	var collisions []string
+	for _, plan := range plans {
+		key := plan.repository + "\x00" + plan.branch
+		if previous, exists := seen[key]; exists {
+			collisions = append(collisions, fmt.Sprintf(
+				"%s and %s (%s on %s)",
+				previous,
+				plan.project,
+				strings.TrimPrefix(plan.branch, "base:"),
+				plan.repository,
+			))
+			continue
+		}
+		seen[key] = plan.project
+	}
+
+	if len(collisions) > 0 {
+		return fmt.Errorf("%w: %s", ErrBranchCollision, strings.Join(collisions, ", "))
+	}
+	return nil
+}
+
+// getRepositoryIdentity normalizes the project path, so the different ways of pointing to the same repository
+// (e.g. SSH or HTTPS, with or without credentials, port or the ".git" suffix) are equal. The remote repositories
+// are identified by their host and path (e.g. "gitlab.com/group/project") and the local ones by their absolute path.
+func getRepositoryIdentity(projectPath string) string {
+	if isRemoteProject(projectPath) {
+		webURL, err := url.Parse(getRepositoryWebURL(projectPath))
+		if err != nil || webURL.Hostname() == "" {
+			return strings.ToLower(projectPath)
+		}
+		return strings.ToLower(webURL.Hostname() + "/" + strings.Trim(webURL.Path, "/"))
+	}
+
+	absolutePath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return projectPath
+	}
+	if realPath, err := filepath.EvalSymlinks(absolutePath); err == nil {
+		absolutePath = realPath
+	}
+	return strings.TrimSuffix(absolutePath, string(os.PathSeparator))
+}
+
+// isRemoteProject checks whether the project path is a remote repository URL
+func isRemoteProject(projectPath string) bool {
+	return strings.HasPrefix(projectPath, "https://") || isSSHURL(projectPath) || isReadOnlyURL(projectPath)
+}
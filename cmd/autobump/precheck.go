@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// projectStatusSkippedPrechecked is the status of the remote projects skipped without cloning them,
+// because the CHANGELOG fetched from the provider API has nothing to release
+const projectStatusSkippedPrechecked = "skipped-empty-prechecked"
+
+// maximum time to fetch the CHANGELOG before falling back to the clone
+const precheckTimeout = 30 * time.Second
+
+var (
+	ErrPrecheckNotSupported = errors.New("the provider doesn't support fetching the CHANGELOG without cloning")
+	ErrFailedToFetchFile    = errors.New("failed to fetch the file")
+)
+
+// unreleasedPrecheck counts the remote projects checked before cloning them in a batch run.
+// It is safe to be shared by concurrent workers.
+type unreleasedPrecheck struct {
+	mutex         sync.Mutex
+	checked       int
+	clonesAvoided int
+}
+
+// newUnreleasedPrecheck creates the pre-check when it is enabled, returning nil otherwise
+func newUnreleasedPrecheck(globalConfig *GlobalConfig) *unreleasedPrecheck {
+	if !globalConfig.PrecheckUnreleased {
+		return nil
+	}
+	return &unreleasedPrecheck{}
+}
+
+// record counts a project checked through the provider API, and whether its clone was avoided
+func (p *unreleasedPrecheck) record(cloneAvoided bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.checked++
+	if cloneAvoided {
+		p.clonesAvoided++
+	}
+}
+
+// logSummary reports how many clones were avoided by the pre-check
+func (p *unreleasedPrecheck) logSummary() {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	log.Infof(
+		"Pre-checked %d projects through the provider API, %d clones avoided (%s)",
+		p.checked, p.clonesAvoided, projectStatusSkippedPrechecked,
+	)
+}
+
+// skipPrecheckedProject checks the "Unreleased" section of a remote project through the provider API,
+// returning true when it is empty and the project can be skipped without cloning it.
+// The projects which can't be checked (e.g. unsupported providers or missing files) are cloned as usual.
+func skipPrecheckedProject(
+	client *http.Client, globalConfig *GlobalConfig, projectConfig *ProjectConfig, logger *log.Entry,
+) bool {
+	precheck := globalConfig.precheck
+	// the entries of the projects released from their commits aren't in the CHANGELOG yet,
+	// and the projects tagging their releases may have a release to tag without anything to bump
+	if precheck == nil || !isRemoteProject(projectConfig.Path) ||
+		projectConfig.ChangelogSource == changelogSourceCommits || isTagCreated(globalConfig, projectConfig) {
+		return false
+	}
+
+	content, err := fetchRemoteChangelog(client, globalConfig, projectConfig)
+	if err != nil {
+		logger.Debugf("Unable to pre-check project %s, cloning it: %v", projectConfig.Name, err)
+		return false
+	}
+
+	summary, err := getUnreleasedSummary(strings.Split(content, "\n"), globalConfig.Changelog)
+	if err != nil {
+		logger.Debugf("Unable to pre-check project %s, cloning it: %v", projectConfig.Name, err)
+		return false
+	}
+
+	precheck.record(summary.Empty)
+	if summary.Empty {
+		logger.Infof("Bump is empty, skipping project %s without cloning it (%s)",
+			projectConfig.Name, projectStatusSkippedPrechecked)
+	}
+	return summary.Empty
+}
+
+// fetchRemoteChangelog fetches the CHANGELOG of the default branch through the provider API
+func fetchRemoteChangelog(
+	client *http.Client,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), precheckTimeout)
+	defer cancel()
+
+	req, err := buildFileContentsRequest(ctx, globalConfig, projectConfig, "CHANGELOG.md")
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToFetchFile, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %d", ErrFailedToFetchFile, resp.StatusCode)
+	}
+
+	maxFileSize := getMaxFileSize(globalConfig)
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToFetchFile, err)
+	}
+	if int64(len(content)) > maxFileSize {
+		return "", fmt.Errorf("%w: larger than %d bytes", ErrFailedToFetchFile, maxFileSize)
+	}
+	return string(content), nil
+}
+
+// buildFileContentsRequest builds the request fetching the raw content of a file of the default branch
+func buildFileContentsRequest(
+	ctx context.Context,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	filePath string,
+) (*http.Request, error) {
+	remoteURL := projectConfig.Path
+	webURL, err := url.Parse(getRepositoryWebURL(remoteURL))
+	if err != nil || webURL.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrPrecheckNotSupported, redactURLCredentials(remoteURL))
+	}
+	fullProjectName := strings.Trim(webURL.Path, "/")
+
+	// the tokens embedded in the URL are used when there is none configured
+	var embeddedToken string
+	if _, auth := sanitizeRemoteURL(remoteURL); auth != nil {
+		embeddedToken = auth.Password
+	}
+	token := firstNonEmpty(projectConfig.ProjectAccessToken, embeddedToken)
+
+	var req *http.Request
+	switch getServiceTypeByURL(remoteURL) { //nolint:exhaustive // unsupported service types are cloned
+	case GITHUB:
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(
+			"%s/repos/%s/contents/%s", getGitHubAPIURL(globalConfig, remoteURL), fullProjectName, filePath,
+		), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/vnd.github.raw")
+		if token = firstNonEmpty(token, getGitHubAccessToken(globalConfig, remoteURL)); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case GITLAB:
+		// "HEAD" is the default branch
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(
+			"%s/api/v4/projects/%s/repository/files/%s/raw?ref=HEAD",
+			firstNonEmpty(getProviderBaseURL(remoteURL), "https://"+webURL.Host),
+			url.PathEscape(trimProviderBasePath(remoteURL, fullProjectName)), url.PathEscape(filePath),
+		), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if token = firstNonEmpty(token, globalConfig.GitLabAccessToken); token != "" {
+			req.Header.Set("Private-Token", token)
+		}
+	case AZUREDEVOPS:
+		organization, project, repository, parseErr := parseAzureDevOpsURL(remoteURL)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		// without a version descriptor, the item is read from the default branch
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(
+			"https://dev.azure.com/%s/%s/_apis/git/repositories/%s/items?path=%s&api-version=6.0",
+			organization, project, repository, url.QueryEscape("/"+filePath),
+		), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "text/plain")
+		if token = firstNonEmpty(token, globalConfig.AzureDevOpsAccessToken); token != "" {
+			setAzureDevOpsAuthorization(req, token)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrPrecheckNotSupported, webURL.Host)
+	}
+	return req, nil
+}
+
+// firstNonEmpty returns the first value which isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// projectStatusDryRun is the status of the projects previewed, but not bumped (e.g. "on_limit: dry-run")
+const projectStatusDryRun = "dry-run"
+
+// PullRequestPreview is the pull request the provider would receive, rendered from the same payload
+// sent when creating it
+type PullRequestPreview struct {
+	Provider     string          `json:"provider"`
+	SourceBranch string          `json:"source_branch"`
+	TargetBranch string          `json:"target_branch"`
+	Title        string          `json:"title"`
+	Description  string          `json:"description,omitempty"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// buildPullRequestPreview builds the preview of the pull request from the payload of the provider,
+// returning nil for the providers whose pull requests aren't created
+func buildPullRequestPreview(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	serviceType ServiceType,
+	remoteURL string,
+	sourceBranch string,
+	targetBranch string,
+	newVersion string,
+	logger *log.Entry,
+) (*PullRequestPreview, error) {
+	title := getPullRequestTitle(globalConfig, projectConfig, state, newVersion, logger)
+	description := getPullRequestDescription(globalConfig, projectConfig, state, logger)
+
+	var payload []byte
+	switch serviceType { //nolint:exhaustive // unsupported service types have no pull request
+	case GITLAB:
+		options := buildGitLabMergeRequestOptions(sourceBranch, targetBranch, title, description)
+		var err error
+		payload, err = json.Marshal(options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+	case AZUREDEVOPS:
+		organizationName, projectName, repositoryName, err := parseAzureDevOpsURL(remoteURL)
+		if err != nil {
+			return nil, err
+		}
+		// the repository ID is only known through the API, while the payload doesn't depend on it
+		azureInfo := AzureDevOpsInfo{
+			OrganizationName: organizationName,
+			ProjectName:      projectName,
+			RepositoryID:     repositoryName,
+		}
+		req, err := buildAzureDevOpsPullRequestRequest(
+			context.Background(), azureInfo, "", sourceBranch, targetBranch, title, description,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payload, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+	case GITHUB:
+		req, err := buildGitHubPullRequestRequest(
+			context.Background(), globalConfig, projectConfig, remoteURL, sourceBranch, targetBranch, title, description,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payload, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+	case BITBUCKET:
+		bitbucketInfo, err := parseBitbucketURL(remoteURL)
+		if err != nil {
+			return nil, err
+		}
+		req, err := buildBitbucketPullRequestRequest(
+			context.Background(), bitbucketInfo, "", sourceBranch, targetBranch, title, description,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payload, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	var fields struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Body        string `json:"body"`
+	}
+	err := json.Unmarshal(payload, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	return &PullRequestPreview{
+		Provider:     serviceType.String(),
+		SourceBranch: sourceBranch,
+		TargetBranch: targetBranch,
+		Title:        fields.Title,
+		Description:  firstNonEmpty(fields.Description, fields.Body),
+		Payload:      payload,
+	}, nil
+}
+
+// renderPullRequestPreview renders the preview as Markdown: the title, the branches, the description
+// and the payload sent to the provider
+func renderPullRequestPreview(preview *PullRequestPreview) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		"**%s** (%s → %s, %s)\n", preview.Title, preview.SourceBranch, preview.TargetBranch, preview.Provider,
+	))
+	if preview.Description != "" {
+		builder.WriteString("\n" + preview.Description + "\n")
+	}
+
+	var payload bytes.Buffer
+	if json.Indent(&payload, preview.Payload, "", "  ") != nil {
+		payload.Reset()
+		payload.Write(preview.Payload)
+	}
+	builder.WriteString("\n```json\n" + payload.String() + "\n```\n")
+	return builder.String()
+}
+
+// previewPullRequest logs the pull request the project would create, keeping it for the digest and the report
+func previewPullRequest(ctx *RepoContext, releaseName string) error {
+	remoteURL, err := getRemoteRepoURL(ctx.repo)
+	if err != nil {
+		return err
+	}
+
+	setReleaseNotes(ctx)
+	ctx.projectConfig.NewVersion = releaseName
+	ctx.status = projectStatusDryRun
+	ctx.branchName = getBumpBranchName(ctx.globalConfig, ctx.projectConfig, &ctx.state, releaseName, ctx.logger())
+	ctx.pullRequestPreview, err = buildPullRequestPreview(
+		ctx.globalConfig,
+		ctx.projectConfig,
+		&ctx.state,
+		getServiceTypeByURL(remoteURL),
+		remoteURL,
+		ctx.branchName,
+		getTargetBranch(ctx.projectConfig),
+		releaseName,
+		ctx.logger(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if ctx.pullRequestPreview != nil {
+		ctx.logger().Infof(
+			"Pull request preview of project %s:\n%s",
+			ctx.projectConfig.Name, renderPullRequestPreview(ctx.pullRequestPreview),
+		)
+	}
+	recordProjectResult(ctx)
+	return nil
+}
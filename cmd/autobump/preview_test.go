@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readGoldenPayload returns the JSON body of the request recorded in a golden file of the snapshot tests
+func readGoldenPayload(t *testing.T, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "golden", name+".golden"))
+	require.NoError(t, err)
+	_, body, found := strings.Cut(string(content), "\n\n")
+	require.True(t, found, "the golden file has no body")
+	return body
+}
+
+func TestBuildPullRequestPreview_MatchesSnapshotPayload(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		serviceType ServiceType
+		remoteURL   string
+		golden      string
+	}{
+		{"GitLab", GITLAB, "https://gitlab.com/group/project.git", "gitlab_create_merge_request"},
+		{"Azure DevOps", AZUREDEVOPS, "https://dev.azure.com/org/project/_git/repo", "azuredevops_create_pull_request"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			preview, err := buildPullRequestPreview(
+				&GlobalConfig{},
+				&ProjectConfig{},
+				&projectState{},
+				test.serviceType,
+				test.remoteURL,
+				snapshotFixture.sourceBranch,
+				snapshotFixture.targetBranch,
+				snapshotFixture.newVersion,
+				standardLogEntry(),
+			)
+
+			// Assert
+			require.NoError(t, err)
+			require.NotNil(t, preview)
+			assert.JSONEq(t, readGoldenPayload(t, test.golden), string(preview.Payload))
+			assert.Equal(t, "chore(bump): bumped version to 1.1.0", preview.Title)
+			assert.Equal(t, snapshotFixture.targetBranch, preview.TargetBranch)
+		})
+	}
+}
+
+func TestBuildPullRequestPreview_IncludesDescription(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectConfig := &ProjectConfig{}
+	state := &projectState{releaseNotes: "### Added\n\n- added the reports"}
+
+	// Act
+	preview, err := buildPullRequestPreview(
+		&GlobalConfig{}, projectConfig, state, GITLAB, "https://gitlab.com/group/project.git", "chore/bump-1.1.0", "main",
+		"1.1.0",
+		standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(
+		t, getPullRequestDescription(&GlobalConfig{}, projectConfig, state, standardLogEntry()), preview.Description,
+	)
+	assert.Contains(t, string(preview.Payload), `"description":`)
+}
+
+func TestBuildPullRequestPreview_UnsupportedProvider(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	preview, err := buildPullRequestPreview(
+		&GlobalConfig{}, &ProjectConfig{}, &projectState{}, CODECOMMIT, "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/project",
+		"chore/bump-1.1.0", "main", "1.1.0",
+		standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Nil(t, preview)
+}
+
+func TestRenderDigest_GroupsPullRequestPreviews(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	preview, err := buildPullRequestPreview(
+		&GlobalConfig{}, &ProjectConfig{}, &projectState{}, GITLAB, "https://gitlab.com/group/project.git",
+		"chore/bump-1.1.0", "main", "1.1.0",
+		standardLogEntry(),
+	)
+	require.NoError(t, err)
+	results := []ProjectResult{
+		{
+			Name: "project", Forge: "gitlab.com", Organization: "group", PreviousVersion: "1.0.0",
+			NextVersion: "1.1.0", Bump: "minor", Status: projectStatusDryRun, PullRequestPreview: preview,
+		},
+		{Name: "other", Forge: "gitlab.com", Organization: "group", PreviousVersion: "2.0.0", NextVersion: "2.0.1"},
+	}
+
+	// Act
+	markdown := renderDigest(results)
+
+	// Assert
+	assert.Contains(t, markdown, "- project (dry-run) 1.0.0 → 1.1.0 (minor)")
+	assert.Contains(t, markdown, "\n## Pull request previews\n\n### project\n\n"+
+		"**chore(bump): bumped version to 1.1.0** (chore/bump-1.1.0 → main, gitlab)\n")
+	assert.Contains(t, markdown, "\"remove_source_branch\": true")
+	assert.NotContains(t, markdown, "### other")
+}
+
+func TestGetCIOutputs_DryRunIsNotBumped(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	report := RunReport{Projects: []ProjectResult{{Name: "project", NextVersion: "1.1.0", Status: projectStatusDryRun}}}
+
+	// Act
+	outputs := getCIOutputs(report)
+
+	// Assert
+	assert.Equal(t, ciOutput{name: "bumped", value: "false"}, outputs[0])
+	assert.Equal(t, ciOutput{name: "status", value: projectStatusDryRun}, outputs[6])
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// changelogProfileV2Compat freezes the formatting of the CHANGELOG released by AutoBump 2.x
+const changelogProfileV2Compat = "v2-compat"
+
+var ErrUnknownChangelogProfile = errors.New("unknown changelog profile")
+
+// changelogProfile is how the entries of the "Unreleased" section are formatted in the released version
+type changelogProfile struct {
+	// whether the duplicated entries are removed before computing the bump
+	deduplicate bool
+	// whether the lines outside the known sections are reported, since they aren't released
+	reportOrphanEntries bool
+	// sorts the entries inside each released section
+	sortEntries func(entries []string)
+}
+
+// changelogProfiles are the formatting profiles, the empty one being the current formatting
+var changelogProfiles = map[string]changelogProfile{
+	"": {deduplicate: true, reportOrphanEntries: true, sortEntries: sortEntries},
+	// the entries were kept as written and sorted byte-wise, links included, and the orphan lines weren't reported
+	changelogProfileV2Compat: {deduplicate: false, reportOrphanEntries: false, sortEntries: sort.Strings},
+}
+
+// validateChangelogProfile checks that the configured profile is one of the formatting profiles
+func validateChangelogProfile(changelogConfig ChangelogConfig) error {
+	if _, found := changelogProfiles[changelogConfig.ChangelogProfile]; !found {
+		return fmt.Errorf(
+			"%w: %q (expected %s)",
+			ErrUnknownChangelogProfile, changelogConfig.ChangelogProfile, strings.Join(getChangelogProfileNames(), ", "),
+		)
+	}
+	return nil
+}
+
+// getChangelogProfileNames returns the sorted names of the formatting profiles, without the default one
+func getChangelogProfileNames() []string {
+	var names []string
+	for name := range changelogProfiles {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getChangelogProfile returns the formatting profile of the CHANGELOG, the current formatting by default
+func getChangelogProfile(changelogConfig ChangelogConfig) changelogProfile {
+	if profile, found := changelogProfiles[changelogConfig.ChangelogProfile]; found {
+		return profile
+	}
+	return changelogProfiles[""]
+}
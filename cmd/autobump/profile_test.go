@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readProfileFixture reads the lines of a fixture of the formatting profiles
+func readProfileFixture(t *testing.T, name string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "profiles", name))
+	require.NoError(t, err)
+	return strings.Split(string(content), "\n")
+}
+
+func TestProcessChangelog_V2CompatMatchesTheRecordedOutputs(t *testing.T) {
+	t.Parallel()
+
+	// the golden files are the outputs recorded with AutoBump 2.x, they must not be regenerated
+	tests := []struct {
+		name    string
+		fixture string
+	}{
+		{name: "should keep the entries repeated across sections", fixture: "repeated"},
+		{name: "should sort the entries with their links", fixture: "links"},
+		{name: "should drop the lines outside the sections", fixture: "sections"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			lines := readProfileFixture(t, test.fixture+".md")
+			changelogConfig := ChangelogConfig{
+				ChangelogProfile: changelogProfileV2Compat,
+				Date:             time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+			}
+
+			// Act
+			_, v2Lines, v2Err := processChangelog(slices.Clone(lines), changelogConfig)
+			changelogConfig.ChangelogProfile = ""
+			_, currentLines, currentErr := processChangelog(slices.Clone(lines), changelogConfig)
+
+			// Assert
+			require.NoError(t, v2Err)
+			require.NoError(t, currentErr)
+			assert.Equal(t, readProfileFixture(t, test.fixture+".v2.golden"), v2Lines)
+			assert.NotEqual(t, v2Lines, currentLines, "the fixture should be formatted differently by now")
+		})
+	}
+}
+
+func TestGetUnreleasedSummary_V2CompatDoesNotReportTheOrphanEntries(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := readProfileFixture(t, "sections.md")
+
+	// Act
+	summary, err := getUnreleasedSummary(lines, ChangelogConfig{ChangelogProfile: changelogProfileV2Compat})
+	require.NoError(t, err)
+	currentSummary, err := getUnreleasedSummary(lines, ChangelogConfig{})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Empty(t, summary.UnrecognizedLines)
+	assert.Equal(t, []int{5}, currentSummary.UnrecognizedLines)
+	assert.Equal(t, currentSummary.CandidateLines, summary.CandidateLines)
+}
+
+func TestFormatReleaseNotes_V2CompatSortsTheLinks(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sectionEntries := map[string][]string{"Changed": {"- updated the parser", "- [migrated](https://example.com) the CI"}}
+
+	// Act
+	releaseNotes := formatReleaseNotes(sectionEntries, ChangelogConfig{ChangelogProfile: changelogProfileV2Compat})
+
+	// Assert
+	assert.Equal(t, "### Changed\n\n- [migrated](https://example.com) the CI\n- updated the parser\n", releaseNotes)
+}
+
+func TestValidateChangelogProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		profile string
+		valid   bool
+	}{
+		{name: "should accept the current formatting", profile: "", valid: true},
+		{name: "should accept the v2 compatibility", profile: changelogProfileV2Compat, valid: true},
+		{name: "should refuse an unknown profile", profile: "v1-compat"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := validateChangelogProfile(ChangelogConfig{ChangelogProfile: test.profile})
+
+			// Assert
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrUnknownChangelogProfile)
+				assert.Contains(t, err.Error(), "expected v2-compat")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+const progressStep = 10
+
+var (
+	progressCounterRegex = regexp.MustCompile(`^([^:]+):\s*(\d+)%\s*\((\d+)/(\d+)\)`)
+	progressTotalRegex   = regexp.MustCompile(`^Total\s+(\d+)`)
+)
+
+// progressLogger receives the sideband progress messages sent by the Git server
+// and forwards them through the logger instead of writing to stderr directly
+type progressLogger struct {
+	mutex       sync.Mutex
+	operation   string
+	logger      *log.Entry
+	interactive bool
+	pending     string
+	phase       string
+	lastPercent int
+	objects     int
+}
+
+// newProgressLogger creates a progress logger for the given operation (e.g. clone, push), logging through the entry
+func newProgressLogger(operation string, logger *log.Entry) *progressLogger {
+	return &progressLogger{
+		operation:   operation,
+		logger:      logger,
+		interactive: term.IsTerminal(int(os.Stderr.Fd())),
+		lastPercent: -1,
+	}
+}
+
+// Write implements io.Writer, splitting the stream on carriage returns and newlines
+func (p *progressLogger) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.pending += string(data)
+	for {
+		index := strings.IndexAny(p.pending, "\r\n")
+		if index < 0 {
+			break
+		}
+		line := strings.TrimSpace(p.pending[:index])
+		p.pending = p.pending[index+1:]
+		if line != "" {
+			p.handleLine(line)
+		}
+	}
+
+	return len(data), nil
+}
+
+// handleLine logs one progress line and keeps track of the amount of objects transferred
+func (p *progressLogger) handleLine(line string) {
+	p.logger.Debugf("[%s] %s", p.operation, line)
+
+	if match := progressTotalRegex.FindStringSubmatch(line); match != nil {
+		p.objects, _ = strconv.Atoi(match[1])
+		return
+	}
+
+	match := progressCounterRegex.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+
+	phase := match[1]
+	percent, _ := strconv.Atoi(match[2])
+	total, _ := strconv.Atoi(match[4])
+	if total > p.objects {
+		p.objects = total
+	}
+
+	if phase != p.phase {
+		p.phase = phase
+		p.lastPercent = -1
+	}
+
+	// only report a compact counter in intervals to avoid flooding the terminal
+	if p.interactive && (percent >= p.lastPercent+progressStep || percent == 100) && percent != p.lastPercent {
+		p.lastPercent = percent
+		p.logger.Infof("[%s] %s: %d%% (%s/%s)", p.operation, phase, percent, match[3], match[4])
+	}
+}
+
+// Objects returns the amount of objects reported by the server
+func (p *progressLogger) Objects() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.objects
+}
+
+// getDirectorySize returns the total size in bytes of the files inside a directory
+func getDirectorySize(path string) int64 {
+	var size int64
+	_ = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// formatBytes formats an amount of bytes into a human-readable string
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressLogger_CountsObjects(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	progress := newProgressLogger("clone", standardLogEntry())
+
+	// Act
+	_, err := progress.Write([]byte("Counting objects:  50% (5/10)\rCounting objects: 100% (10/10)"))
+	require.NoError(t, err)
+	_, err = progress.Write([]byte(", done.\nTotal 1234 (delta 3), reused 0 (delta 0)\n"))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1234, progress.Objects())
+}
+
+func TestProgressLogger_KeepsPartialLines(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	progress := newProgressLogger("clone", standardLogEntry())
+
+	// Act
+	_, err := progress.Write([]byte("Compressing objects:  10% (1/42"))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 0, progress.Objects())
+	assert.Equal(t, "Compressing objects:  10% (1/42", progress.pending)
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "2.0 GiB", formatBytes(2*1024*1024*1024))
+}
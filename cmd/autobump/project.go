@@ -3,15 +3,15 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	log "github.com/sirupsen/logrus"
 )
@@ -21,6 +21,8 @@ var (
 	ErrProjectPathDoesNotExist      = errors.New("project path does not exist")
 	ErrProjectLanguageNotRecognized = errors.New("project language not recognized")
 	ErrUnsupportedRemoteURL         = errors.New("unsupported remote URL")
+	ErrBaseRefNotFound              = errors.New("base ref not found")
+	ErrReadOnlyRemoteURL            = errors.New("the git:// protocol is read-only, the bump branch can't be pushed")
 )
 
 type RepoContext struct {
@@ -30,11 +32,86 @@ type RepoContext struct {
 	repo            *git.Repository
 	worktree        *git.Worktree
 	head            *plumbing.Reference
+	baseCommit      *object.Commit // commit of the base ref, used instead of HEAD when set
+	unreleased      UnreleasedSummary
+	pullRequestURL  string
+	// status of the project when it wasn't released as usual (e.g. pushed-no-pr),
+	// and where to open its pull request by hand
+	status     string
+	compareURL string
+	// pull request the project would create, when it is only previewed
+	pullRequestPreview *PullRequestPreview
+	// time spent in each phase of the processing
+	timer *phaseTimer
+	// bump branch of the project, once it was created
+	branchName string
+	// changes made to the local repository, undone when the project fails before its pull request is created
+	rollback *rollbackJournal
+	// entry logging the messages of the project, with its name when the projects are processed concurrently
+	logEntry *log.Entry
+	// state built while processing the project, kept apart from its configuration
+	state projectState
+}
+
+// projectState is what is found and built while processing a project (e.g. the notes of its pull request), so the
+// configuration of the project is never changed by a run
+type projectState struct {
+	// all the changes of the release, written in the pull request when the CHANGELOG summarizes some of them
+	releaseNotes string
+	// changes of the release, written in the pull request
+	changelogExcerpt string
+	// window of time covered by the release train, written in the pull request
+	trainNotes string
+	// migration notes of the breaking changes, written in the pull request
+	migrationNotes string
+	// version written in the version files, which is the next development version in the "next-dev" strategy
+	versionFilesVersion string
+	// where the other half of a release with a redirected CHANGELOG is, written in the pull request
+	redirectNotes string
+	// version files matched by a glob but skipped, since the repository ignores them
+	ignoredVersionFiles []string
+	// yanked release and the reason, written in the pull request of "autobump yank"
+	yankNotes string
+	// ID of the run, written in the pull request when it's a trailer of the bump commit
+	runNotes string
+	// approval state of the pull request, read after its creation
+	pullRequestApprovals *PullRequestApprovals
+	// archive receiving the old releases of the CHANGELOG, and the versions moved into it
+	changelogArchive string
+	archivedReleases []string
+	// subject of the commit and the pull request, replacing the one of the bump (e.g. in the umbrella repository)
+	commitSubject string
+	// pull requests of the releases propagated to the umbrella repository, written in its pull request
+	propagationNotes string
+	// fixes of the CHANGELOG lines added by the release, suggested in review comments of the pull request
+	lintSuggestions *lintSuggestions
+	// how the pull request was set to be merged
+	autoMerge *AutoMergeResult
+	// pull request created for the release, and whether it conflicts with its target branch
+	pullRequest  *pullRequestHandle
+	mergeability *MergeabilityResult
+	// prefix of the released version, as configured or detected from the CHANGELOG headers and the tags
+	resolvedVersionPrefix string
+	// features requested for the project but skipped, since its remote service can't do them
+	skippedFeatures []string
+}
+
+// logger returns the entry logging the messages of the project, the standard logger when it has none
+func (ctx *RepoContext) logger() *log.Entry {
+	if ctx.logEntry == nil {
+		return standardLogEntry()
+	}
+	return ctx.logEntry
+}
+
+// standardLogEntry returns an entry of the standard logger, for the messages of no project in particular
+func standardLogEntry() *log.Entry {
+	return log.NewEntry(log.StandardLogger())
 }
 
 // detectProjectLanguage detects the language of a project by looking at the files in the project
-func detectProjectLanguage(globalConfig *GlobalConfig, cwd string) (string, error) {
-	log.Info("Detecting project language")
+func detectProjectLanguage(globalConfig *GlobalConfig, cwd string, logger *log.Entry) (string, error) {
+	logger.Info("Detecting project language")
 
 	absPath, err := filepath.Abs(cwd)
 	if err != nil {
@@ -42,7 +119,7 @@ func detectProjectLanguage(globalConfig *GlobalConfig, cwd string) (string, erro
 	}
 
 	// Check the project type by special files
-	if language := detectBySpecialPatterns(globalConfig, absPath); language != "" {
+	if language := detectBySpecialPatterns(globalConfig, absPath, logger); language != "" {
 		return language, nil
 	}
 
@@ -59,12 +136,12 @@ func detectProjectLanguage(globalConfig *GlobalConfig, cwd string) (string, erro
 }
 
 // detectBySpecialPatterns checks the project type using special file patterns
-func detectBySpecialPatterns(globalConfig *GlobalConfig, absPath string) string {
+func detectBySpecialPatterns(globalConfig *GlobalConfig, absPath string, logger *log.Entry) string {
 	for language, config := range globalConfig.LanguagesConfig {
 		for _, pattern := range config.SpecialPatterns {
 			matches, _ := filepath.Glob(filepath.Join(absPath, pattern))
 			if len(matches) > 0 {
-				log.Infof("Project language detected as %s via file pattern '%s'", language, pattern)
+				logger.Infof("Project language detected as %s via file pattern '%s'", language, pattern)
 				return language
 			}
 		}
@@ -123,36 +200,53 @@ func cloneRepo(ctx *RepoContext) (string, error) {
 		return "", fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 
+	// never clone using a URL with credentials, otherwise they are stored in the cloned repository config
+	registerEmbeddedCredentials(ctx.projectConfig)
+
 	// setup the clone options
-	log.Infof("Cloning %s into %s", ctx.projectConfig.Path, tmpDir)
+	ctx.logger().Infof("Cloning %s into %s", ctx.projectConfig.Path, tmpDir)
+	progress := newProgressLogger("clone", ctx.logger())
 	cloneOptions := &git.CloneOptions{
-		URL:   ctx.projectConfig.Path,
-		Depth: 1,
+		URL:      ctx.projectConfig.Path,
+		Depth:    1,
+		Progress: progress,
+	}
+	// the commits since the latest release (or the commit releasing it, to tag it) are read from the history
+	if ctx.projectConfig.ChangelogSource == changelogSourceCommits || isTagCreated(ctx.globalConfig, ctx.projectConfig) {
+		cloneOptions.Depth = 0
 	}
 
-	service := getServiceTypeByURL(ctx.projectConfig.Path)
-
-	// get authentication methods
-	var authMethods []transport.AuthMethod
-	authMethods, err = getAuthMethods(
-		service,
-		ctx.globalGitConfig.Raw.Section("user").Option("name"),
-		ctx.globalConfig,
-		ctx.projectConfig,
-	)
-	if err != nil {
-		return "", err
+	// get authentication methods, the SSH transport authenticates with the SSH agent by default
+	authMethods := []transport.AuthMethod{nil}
+	if !isSSHURL(ctx.projectConfig.Path) {
+		authMethods, err = getAuthMethods(
+			ctx.projectConfig.Path,
+			ctx.globalGitConfig.Raw.Section("user").Option("name"),
+			ctx.globalConfig,
+			ctx.projectConfig,
+			ctx.logger(),
+		)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	// try each authentication method
 	clonedSuccessfully := false
+	startTime := time.Now()
 	for _, auth := range authMethods {
 		cloneOptions.Auth = auth
 		ctx.repo, err = git.PlainClone(tmpDir, false, cloneOptions)
 
 		// if action finished successfully, return
 		if err == nil {
-			log.Infof("Successfully cloned %s", ctx.projectConfig.Path)
+			ctx.logger().Infof(
+				"Successfully cloned %s in %s (%d objects, %s)",
+				ctx.projectConfig.Path,
+				time.Since(startTime).Round(time.Millisecond),
+				progress.Objects(),
+				formatBytes(getDirectorySize(filepath.Join(tmpDir, ".git"))),
+			)
 			ctx.projectConfig.Path = tmpDir
 			clonedSuccessfully = true
 			break
@@ -164,56 +258,151 @@ func cloneRepo(ctx *RepoContext) (string, error) {
 		return "", fmt.Errorf("failed to clone %s: %w", ctx.projectConfig.Path, err)
 	}
 
+	err = scrubRepositoryCredentials(ctx.repo, ctx.logger())
+	if err != nil {
+		return "", err
+	}
+
 	return tmpDir, nil
 }
 
+// getPullRequestDescription returns the description of the pull request, with the changes of the release (all of
+// them when the CHANGELOG summarizes some). The "pr_body_template" replaces it, along with the same notes.
+func getPullRequestDescription(
+	globalConfig *GlobalConfig, projectConfig *ProjectConfig, state *projectState, logger *log.Entry,
+) string {
+	var paragraphs []string
+	if state.yankNotes != "" {
+		paragraphs = append(paragraphs, state.yankNotes)
+	}
+	if state.redirectNotes != "" {
+		paragraphs = append(paragraphs, state.redirectNotes)
+	}
+	if state.propagationNotes != "" {
+		paragraphs = append(paragraphs, state.propagationNotes)
+	}
+	if state.trainNotes != "" {
+		paragraphs = append(paragraphs, state.trainNotes)
+	}
+	if state.migrationNotes != "" {
+		paragraphs = append(paragraphs, state.migrationNotes)
+	}
+	notes := append([]string(nil), paragraphs...)
+	if state.runNotes != "" {
+		notes = append(notes, state.runNotes)
+	}
+
+	// the changes of the release come before the notes of the run
+	if state.releaseNotes != "" {
+		paragraphs = append(paragraphs,
+			"Some sections of the CHANGELOG were summarized, these are all the changes of this release:\n\n"+
+				state.releaseNotes,
+		)
+	} else if state.changelogExcerpt != "" {
+		paragraphs = append(paragraphs,
+			"These are the changes of this release:\n\n"+strings.TrimSpace(state.changelogExcerpt),
+		)
+	}
+	if state.runNotes != "" {
+		paragraphs = append(paragraphs, state.runNotes)
+	}
+
+	description := strings.Join(paragraphs, "\n\n")
+	// the pull requests of the yanks and of the propagations keep their own body
+	text := getPullRequestBodyTemplate(globalConfig, projectConfig)
+	if text != "" && state.commitSubject == "" && state.yankNotes == "" {
+		return renderPullRequestBody(
+			globalConfig, projectConfig, state, text, strings.Join(notes, "\n\n"), description, logger,
+		)
+	}
+	return description
+}
+
+// setReleaseNotes keeps the migration notes and the changes of the release for the pull request, noting when the
+// CHANGELOG summarizes some of them
+func setReleaseNotes(ctx *RepoContext) {
+	ctx.state.migrationNotes = formatMigrationNotes(ctx.unreleased.SectionEntries)
+	ctx.state.runNotes = formatRunNotes(ctx.globalConfig)
+	ctx.state.changelogExcerpt = formatReleaseNotes(ctx.unreleased.SectionEntries, ctx.globalConfig.Changelog)
+	if hasSummarizedSections(ctx.unreleased.SectionEntries, ctx.globalConfig.Changelog.MaxEntriesPerSection) {
+		ctx.state.releaseNotes = ctx.state.changelogExcerpt
+	}
+}
+
+// createPullRequest creates the pull request in the remote service, returning its URL (if known)
 func createPullRequest(
 	globalConfig *GlobalConfig,
 	projectConfig *ProjectConfig,
+	state *projectState,
 	repo *git.Repository,
 	branchName string,
+	targetBranch string,
 	serviceType ServiceType,
-) error {
-	var err error
+	logger *log.Entry,
+) (string, error) {
 	switch serviceType { //nolint:exhaustive // unsupported service types are handled by the default case
 	case GITLAB:
-		err = createGitLabMergeRequest(
+		return createGitLabMergeRequest(
 			globalConfig,
 			projectConfig,
+			state,
 			repo,
 			branchName,
+			targetBranch,
 			projectConfig.NewVersion,
+			logger,
 		)
-		if err != nil {
-			return err
-		}
 	case AZUREDEVOPS:
-		err = createAzureDevOpsPullRequest(
+		return createAzureDevOpsPullRequest(
 			globalConfig,
 			projectConfig,
+			state,
 			repo,
 			branchName,
+			targetBranch,
 			projectConfig.NewVersion,
+			logger,
+		)
+	case GITHUB:
+		return createGitHubPullRequest(
+			globalConfig,
+			projectConfig,
+			state,
+			repo,
+			branchName,
+			targetBranch,
+			projectConfig.NewVersion,
+			logger,
+		)
+	case BITBUCKET:
+		return createBitbucketPullRequest(
+			globalConfig,
+			projectConfig,
+			state,
+			repo,
+			branchName,
+			targetBranch,
+			projectConfig.NewVersion,
+			logger,
 		)
-		if err != nil {
-			return err
-		}
 	default:
-		log.Warnf("Service type '%v' not supported yet...", serviceType)
+		return "", checkPullRequestSupport(serviceType)
 	}
-
-	return nil
 }
 
 func cloneRepoIfNeeded(ctx *RepoContext) (string, error) {
-	if strings.HasPrefix(ctx.projectConfig.Path, "https://") || strings.HasPrefix(ctx.projectConfig.Path, "git@") {
+	// fail before cloning, since the bump branch couldn't be pushed
+	if isReadOnlyURL(ctx.projectConfig.Path) {
+		return "", fmt.Errorf("%w: %s", ErrReadOnlyRemoteURL, ctx.projectConfig.Path)
+	}
+	if isRemoteProject(ctx.projectConfig.Path) {
 		return cloneRepo(ctx)
 	}
 	return "", nil
 }
 
 func setupChangelog(ctx *RepoContext, changelogPath string) error {
-	exists, err := createChangelogIfNotExists(changelogPath)
+	exists, err := createChangelogIfNotExists(ctx, changelogPath)
 	if err != nil {
 		return err
 	}
@@ -228,25 +417,155 @@ func setupChangelog(ctx *RepoContext, changelogPath string) error {
 }
 
 func shouldBumpProject(ctx *RepoContext, changelogPath string) (bool, error) {
-	lines, err := readLines(changelogPath)
+	lines, err := readChangelogLines(ctx, changelogPath)
+	if err != nil {
+		return false, err
+	}
+
+	changelogFile := getRelativeFileName(ctx, changelogPath)
+	if isLFSPointer(lines) {
+		reportFinding(Finding{
+			Level:   findingError,
+			File:    changelogFile,
+			Line:    1,
+			Message: ErrChangelogIsLFSPointer.Error(),
+		}, ctx.logger())
+		return false, fmt.Errorf("%w: %s", ErrChangelogIsLFSPointer, changelogPath)
+	}
+	if line := findConflictMarker(lines); line > 0 && !ctx.globalConfig.Changelog.IgnoreConflictMarkers {
+		reportFinding(Finding{
+			Level:   findingError,
+			File:    changelogFile,
+			Line:    line,
+			Message: ErrChangelogConflictMarkers.Error(),
+		}, ctx.logger())
+		return false, fmt.Errorf("%w: line %d of %s", ErrChangelogConflictMarkers, line, changelogPath)
+	}
+
+	var summary UnreleasedSummary
+	changelogConfig := ctx.globalConfig.Changelog
+	if len(ctx.projectConfig.VersionStreams) > 0 {
+		summary = getStreamsUnreleasedSummary(lines, changelogConfig)
+	} else {
+		summary, err = getUnreleasedSummary(lines, changelogConfig)
+	}
 	if err != nil {
+		reportFinding(Finding{Level: findingError, File: changelogFile, Line: 1, Message: err.Error()}, ctx.logger())
 		return false, err
 	}
+	ctx.unreleased = summary
+	ctx.state.resolvedVersionPrefix = resolveVersionPrefix(ctx.projectConfig, lines, ctx.repo, ctx.logger())
+	if ctx.projectConfig.ReleaseTrain {
+		ctx.state.trainNotes = formatTrainNotes(lines, getReleaseDate(ctx.globalConfig))
+	}
 
-	bumpEmpty, err := isChangelogUnreleasedEmpty(lines)
+	for _, finding := range getUnreleasedFindings(summary, changelogFile) {
+		reportFinding(finding, ctx.logger())
+	}
+	err = lintUnreleased(ctx, lines, summary.UnreleasedLine, changelogFile)
 	if err != nil {
 		return false, err
 	}
-	if bumpEmpty {
-		log.Infof("Bump is empty, skipping project %s", ctx.projectConfig.Name)
+	if summary.Empty {
+		if summary.CarriedEntries > 0 {
+			ctx.logger().Infof(
+				"Bump only has %d non-bumping entries, skipping project %s", summary.CarriedEntries, ctx.projectConfig.Name,
+			)
+		} else {
+			ctx.logger().Infof("Bump is empty, skipping project %s", ctx.projectConfig.Name)
+		}
 		return false, nil
 	}
+	if len(ctx.projectConfig.VersionStreams) == 0 {
+		line := findAlreadyReleasedContent(lines, summary, changelogConfig)
+		if line > 0 {
+			ctx.status = projectStatusAlreadyReleased
+			message := fmt.Sprintf(
+				"the Unreleased entries were already released in %s, remove them from the Unreleased section",
+				versionString(summary.LatestVersion),
+			)
+			reportFinding(Finding{Level: findingWarning, File: changelogFile, Line: line, Message: message}, ctx.logger())
+			ctx.logger().Warnf("Skipping project %s, %s (line %d of %s)", ctx.projectConfig.Name, message, line, changelogFile)
+			return false, nil
+		}
+	}
+	ctx.logger().Debugf("Unreleased entries per section of project %s: %v", ctx.projectConfig.Name, summary.SectionCounts)
 	return true, nil
 }
 
+// getRelativeFileName returns the path of the file relative to the project, as shown in the findings
+func getRelativeFileName(ctx *RepoContext, filePath string) string {
+	relativePath, err := filepath.Rel(ctx.projectConfig.Path, filePath)
+	if err != nil {
+		return filePath
+	}
+	return filepath.ToSlash(relativePath)
+}
+
+// readChangelogLines reads the CHANGELOG from the base ref when set, otherwise from the working tree
+func readChangelogLines(ctx *RepoContext, changelogPath string) ([]string, error) {
+	if ctx.baseCommit == nil {
+		return readLines(changelogPath, getMaxFileSize(ctx.globalConfig))
+	}
+
+	relativePath, err := getWorktreePath(ctx, changelogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return readCommitLines(
+		ctx.baseCommit, filepath.ToSlash(relativePath), "the base ref", getMaxFileSize(ctx.globalConfig),
+	)
+}
+
+// readCommitLines reads the lines of a file from the tree of a commit, without touching the worktree
+func readCommitLines(commit *object.Commit, relativePath string, refName string, maxFileSize int64) ([]string, error) {
+	file, err := commit.File(relativePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s in %s: %w", relativePath, refName, err)
+	}
+	if file.Size > maxFileSize {
+		return nil, fmt.Errorf("%w: %s has %s", ErrFileTooLarge, relativePath, formatBytes(file.Size))
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %w", relativePath, refName, err)
+	}
+	return strings.Split(strings.TrimSuffix(contents, "\n"), "\n"), nil
+}
+
+// resolveBaseRef resolves a branch, tag, or commit (locally or in the origin remote) into a commit
+func resolveBaseRef(repo *git.Repository, baseRef string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(baseRef))
+	if err != nil {
+		hash, err = repo.ResolveRevision(plumbing.Revision("origin/" + baseRef))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBaseRefNotFound, baseRef)
+		}
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the base ref commit: %w", err)
+	}
+	return commit, nil
+}
+
+// getTargetBranch returns the branch targeted by the pull request
+func getTargetBranch(projectConfig *ProjectConfig) string {
+	if projectConfig.BaseRef == "" {
+		return "main"
+	}
+
+	targetBranch := strings.TrimPrefix(projectConfig.BaseRef, "refs/heads/")
+	targetBranch = strings.TrimPrefix(targetBranch, "refs/remotes/")
+	return strings.TrimPrefix(targetBranch, "origin/")
+}
+
 func ensureProjectLanguage(ctx *RepoContext) error {
 	if ctx.projectConfig.Language == "" {
-		projectLanguage, err := detectProjectLanguage(ctx.globalConfig, ctx.projectConfig.Path)
+		projectLanguage, err := detectProjectLanguage(ctx.globalConfig, ctx.projectConfig.Path, ctx.logger())
 		if err != nil {
 			return err
 		}
@@ -258,7 +577,7 @@ func ensureProjectLanguage(ctx *RepoContext) error {
 func setupRepo(ctx *RepoContext) error {
 	if ctx.repo == nil {
 		var err error
-		ctx.repo, err = openRepo(ctx.projectConfig.Path)
+		ctx.repo, err = openRepo(ctx.projectConfig.Path, ctx.logger())
 		if err != nil {
 			return err
 		}
@@ -276,16 +595,33 @@ func setupRepo(ctx *RepoContext) error {
 	}
 	ctx.head = head
 
+	if ctx.projectConfig.BaseRef != "" {
+		ctx.baseCommit, err = resolveBaseRef(ctx.repo, ctx.projectConfig.BaseRef)
+		if err != nil {
+			return err
+		}
+		ctx.logger().Infof("Computing the bump against '%s' (%s)", ctx.projectConfig.BaseRef, ctx.baseCommit.Hash)
+	}
+
+	// local repositories might have the credentials embedded in the remote URL
+	if ctx.projectConfig.embeddedAuth == nil {
+		var remote *git.Remote
+		remote, err = ctx.repo.Remote("origin")
+		if err == nil && len(remote.Config().URLs) > 0 {
+			_, ctx.projectConfig.embeddedAuth = sanitizeRemoteURL(remote.Config().URLs[0])
+		}
+	}
+
 	return nil
 }
 
 func createBumpBranch(ctx *RepoContext, changelogPath string) (string, error) {
-	nextVersion, err := getNextVersion(changelogPath)
+	releaseName, err := getNextReleaseName(ctx, changelogPath)
 	if err != nil {
 		return "", err
 	}
 
-	branchName := "chore/bump-" + nextVersion.String()
+	branchName := getBumpBranchName(ctx.globalConfig, ctx.projectConfig, &ctx.state, releaseName, ctx.logger())
 
 	branchExists, err := checkBranchExists(ctx.repo, branchName)
 	if err != nil {
@@ -295,25 +631,53 @@ func createBumpBranch(ctx *RepoContext, changelogPath string) (string, error) {
 		return "", fmt.Errorf("%w: %s", ErrBranchExists, branchName)
 	}
 
-	err = createAndSwitchBranch(ctx.repo, ctx.worktree, branchName, ctx.head.Hash())
+	baseHash := ctx.head.Hash()
+	if ctx.baseCommit != nil {
+		baseHash = ctx.baseCommit.Hash
+	}
+
+	err = createAndSwitchBranch(ctx.repo, ctx.worktree, branchName, baseHash, ctx.logger())
 	if err != nil {
 		return "", err
 	}
+	recordBumpBranch(ctx, branchName)
 
 	return branchName, nil
 }
 
 func updateChangelogAndVersionFiles(ctx *RepoContext, changelogPath string) error {
-	log.Info("Updating CHANGELOG.md file")
-	version, err := updateChangelogFile(changelogPath)
+	recordFileChanges(ctx, changelogPath)
+	ctx.logger().Info("Updating CHANGELOG.md file")
+	if len(ctx.projectConfig.VersionStreams) > 0 {
+		defer ctx.timer.start(phaseChangelog)()
+		return updateStreamsChangelogAndVersionFiles(ctx, changelogPath)
+	}
+
+	stopTimer := ctx.timer.start(phaseChangelog)
+	version, err := updateChangelogFile(ctx, changelogPath)
+	stopTimer()
 	if err != nil {
-		log.Errorf("No version found in CHANGELOG.md for project at %s\n", ctx.projectConfig.Path)
+		ctx.logger().Errorf("No version found in CHANGELOG.md for project at %s\n", ctx.projectConfig.Path)
 		return err
 	}
 
-	ctx.projectConfig.NewVersion = version.String()
-	log.Infof("Updating version to %s", ctx.projectConfig.NewVersion)
-	err = updateVersion(ctx.globalConfig, ctx.projectConfig)
+	ctx.projectConfig.NewVersion = versionString(version)
+	setReleaseNotes(ctx)
+	if ctx.unreleased.LatestVersion != nil {
+		reportFinding(Finding{
+			Level: findingNotice,
+			File:  getRelativeFileName(ctx, changelogPath),
+			Message: fmt.Sprintf(
+				"%s bumped from %s to %s",
+				ctx.projectConfig.Name,
+				versionString(ctx.unreleased.LatestVersion),
+				ctx.projectConfig.NewVersion,
+			),
+		}, ctx.logger())
+	}
+	ctx.logger().Infof("Updating version to %s", ctx.projectConfig.NewVersion)
+	defer ctx.timer.start(phaseVersion)()
+	err = updateVersion(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger())
 	if err != nil {
 		return err
 	}
@@ -322,53 +686,79 @@ func updateChangelogAndVersionFiles(ctx *RepoContext, changelogPath string) erro
 }
 
 func addFilesToWorktree(ctx *RepoContext, changelogPath string) error {
-	versionFiles, err := getVersionFiles(ctx.globalConfig, ctx.projectConfig)
+	versionFiles, err := getVersionFiles(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger())
 	if err != nil {
 		return err
 	}
 
-	projectPath := ctx.projectConfig.Path
-
 	for _, versionFile := range versionFiles {
 		var versionFileRelativePath string
-		versionFileRelativePath, err = filepath.Rel(projectPath, versionFile.Path)
+		versionFileRelativePath, err = getWorktreePath(ctx, versionFile.Path)
 		if err != nil {
-			return fmt.Errorf("failed to get relative path for version file: %w", err)
+			return err
 		}
 
 		if _, err = os.Stat(versionFile.Path); os.IsNotExist(err) {
 			continue
 		}
 
-		log.Infof("Adding version file %s", versionFileRelativePath)
+		ctx.logger().Infof("Adding version file %s", versionFileRelativePath)
 		_, err = ctx.worktree.Add(versionFileRelativePath)
 		if err != nil {
 			return fmt.Errorf("failed to add version file: %w", err)
 		}
 	}
 
-	changelogRelativePath, err := filepath.Rel(projectPath, changelogPath)
+	err = addChangelogToWorktree(ctx, changelogPath)
 	if err != nil {
-		return fmt.Errorf("failed to get relative path for changelog file: %w", err)
+		return err
+	}
+
+	return addChangelogArchiveToWorktree(ctx)
+}
+
+// addChangelogArchiveToWorktree stages the archive of the CHANGELOG, when the release moved old releases into it
+func addChangelogArchiveToWorktree(ctx *RepoContext) error {
+	if ctx.state.changelogArchive == "" {
+		return nil
+	}
+	return addFileToWorktree(ctx, ctx.state.changelogArchive)
+}
+
+// addFileToWorktree stages a file of the project, when it exists
+func addFileToWorktree(ctx *RepoContext, filePath string) error {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil
 	}
-	_, err = ctx.worktree.Add(changelogRelativePath)
+
+	relativePath, err := getWorktreePath(ctx, filePath)
 	if err != nil {
-		return fmt.Errorf("failed to add changelog file: %w", err)
+		return err
 	}
 
+	ctx.logger().Infof("Adding file %s", relativePath)
+	_, err = ctx.worktree.Add(relativePath)
+	if err != nil {
+		return fmt.Errorf("failed to add file: %w", err)
+	}
 	return nil
 }
 
 func commitAndPushChanges(ctx *RepoContext, branchName string) error {
-	_, err := commitChangesWithGPG(ctx)
+	stopTimer := ctx.timer.start(phaseCommit)
+	hash, err := commitChangesWithGPG(ctx)
+	stopTimer()
 	if err != nil {
 		return err
 	}
+	recordCommit(ctx, hash)
 
+	stopTimer = ctx.timer.start(phasePush)
 	err = pushChanges(ctx, branchName)
+	stopTimer()
 	if err != nil {
 		if err.Error() == "object not found" {
-			log.Error("Got error object not found (remote branch already exists?)")
+			ctx.logger().Error("Got error object not found (remote branch already exists?)")
 		}
 		return err
 	}
@@ -382,54 +772,68 @@ func commitChangesWithGPG(ctx *RepoContext) (plumbing.Hash, error) {
 		return plumbing.Hash{}, fmt.Errorf("failed to get repo config: %w", err)
 	}
 
-	gpgSign := getOptionFromConfig(cfg, ctx.globalGitConfig, "commit", "gpgsign")
-	gpgFormat := getOptionFromConfig(cfg, ctx.globalGitConfig, "gpg", "format")
-
-	var signKey *openpgp.Entity
-	if gpgSign == "true" && gpgFormat != "ssh" {
-		log.Info("Signing commit with GPG key")
-		gpgKeyID := getOptionFromConfig(cfg, ctx.globalGitConfig, "user", "signingkey")
-
-		var gpgKeyReader *io.Reader
-		gpgKeyReader, err = getGpgKeyReader(gpgKeyID, ctx.globalConfig.GpgKeyPath)
-		if err != nil {
-			return plumbing.Hash{}, err
-		}
+	commitMessage := getCommitSubject(
+		ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.projectConfig.NewVersion, ctx.logger(),
+	)
+	name := ctx.globalGitConfig.Raw.Section("user").Option("name")
+	email := ctx.globalGitConfig.Raw.Section("user").Option("email")
+
+	// the hooks run at the root of the repository, like "git commit" does
+	repositoryRoot := ctx.worktree.Filesystem.Root()
+	hooksDir := getHooksDir(cfg, ctx.globalGitConfig, repositoryRoot)
+	trailers := getBumpCommitTrailers(ctx)
+	err = runCommitHooks(
+		ctx.globalConfig.RunGitHooks, hooksDir, repositoryRoot, commitMessage+formatSignoff(name, email)+trailers,
+		ctx.logger(),
+	)
+	if err != nil {
+		return plumbing.Hash{}, err
+	}
 
-		signKey, err = getGpgKey(*gpgKeyReader)
-		if err != nil {
-			return plumbing.Hash{}, err
-		}
+	signer, err := getCommitSigner(ctx, cfg)
+	if err != nil {
+		return plumbing.Hash{}, err
 	}
 
-	commitMessage := "chore(bump): bumped version to " + ctx.projectConfig.NewVersion
 	return commitChanges(
-		ctx.worktree,
-		commitMessage,
-		signKey,
-		ctx.globalGitConfig.Raw.Section("user").Option("name"),
-		ctx.globalGitConfig.Raw.Section("user").Option("email"),
+		ctx.worktree, commitMessage, trailers, signer, name, email, ctx.globalConfig.releaseDate, ctx.logger(),
 	)
 }
 
 func pushChanges(ctx *RepoContext, branchName string) error {
+	return pushBranch(ctx, branchName, nil)
+}
+
+// pushBranch pushes the branch to the origin remote, replacing it when the lease is set
+// and the remote branch is still the one expected by the lease
+func pushBranch(ctx *RepoContext, branchName string, lease *git.ForceWithLease) error {
 	refSpec := config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName)
+	if lease != nil {
+		refSpec = "+" + refSpec
+	}
+	return pushRefSpec(ctx, refSpec, lease)
+}
 
+// pushRefSpec pushes the refs (e.g. a branch or a tag) to the origin remote, through SSH or HTTPS
+func pushRefSpec(ctx *RepoContext, refSpec config.RefSpec, lease *git.ForceWithLease) error {
 	remoteCfg, err := ctx.repo.Remote("origin")
 	if err != nil {
 		return fmt.Errorf("failed to get remote origin: %w", err)
 	}
 
 	remoteURL := remoteCfg.Config().URLs[0]
-	if strings.HasPrefix(remoteURL, "git@") {
-		return pushChangesSSH(ctx.repo, refSpec)
+	if isReadOnlyURL(remoteURL) {
+		return fmt.Errorf("%w: %s", ErrReadOnlyRemoteURL, remoteURL)
+	}
+	if isSSHURL(remoteURL) {
+		return pushChangesSSH(ctx.repo, refSpec, lease, ctx.logger())
 	} else if strings.HasPrefix(remoteURL, "https://") || strings.HasPrefix(remoteURL, "http://") {
 		var cfg *config.Config
 		cfg, err = ctx.repo.Config()
 		if err != nil {
 			return fmt.Errorf("failed to get repo config: %w", err)
 		}
-		return pushChangesHTTPS(ctx.repo, cfg, refSpec, ctx.globalConfig, ctx.projectConfig)
+		return pushChangesHTTPS(ctx.repo, cfg, refSpec, lease, ctx.globalConfig, ctx.projectConfig, ctx.logger())
 	}
 
 	// If none of the conditions match, return an error
@@ -442,35 +846,50 @@ func createAndCheckoutPullRequest(ctx *RepoContext, branchName string) error {
 		return err
 	}
 
-	err = createPullRequest(ctx.globalConfig, ctx.projectConfig, ctx.repo, branchName, serviceType)
+	ctx.pullRequestURL, err = createPullRequest(
+		ctx.globalConfig,
+		ctx.projectConfig,
+		&ctx.state,
+		ctx.repo,
+		branchName,
+		getTargetBranch(ctx.projectConfig),
+		serviceType,
+		ctx.logger(),
+	)
 	if err != nil {
-		return err
+		degradable := errors.Is(err, ErrPullRequestForbidden) || errors.Is(err, ErrPullRequestNotSupported)
+		if !degradable || ctx.globalConfig.RequirePR {
+			return err
+		}
+		degradePullRequest(ctx, branchName, err)
 	}
 
 	return checkoutToMainBranch(ctx)
 }
 
 func checkoutToMainBranch(ctx *RepoContext) error {
-	err := checkoutBranch(ctx.worktree, "main")
+	err := checkoutBranch(ctx.repo, ctx.worktree, "main", ctx.logger())
 	if err != nil {
-		return checkoutBranch(ctx.worktree, "master")
+		return checkoutBranch(ctx.repo, ctx.worktree, "master", ctx.logger())
 	}
 	return nil
 }
 
 // addCurrentVersion adds the current version to the CHANGELOG file
 func addCurrentVersion(ctx *RepoContext, changelogPath string) error {
-	lines, err := readLines(changelogPath)
+	lines, err := readLines(changelogPath, getMaxFileSize(ctx.globalConfig))
 	if err != nil {
 		return err
 	}
 
-	latestTag, err := getLatestTag(ctx.repo)
+	latestTag, err := getLatestTag(ctx.repo, ctx.logger())
 	if err != nil {
 		return err
 	}
 
-	// TODO: we should replace <LINK TO THE PLATFORM TO OPEN THE PULL REQUEST> with the actual link
+	// replace the link placeholder of the template with the link to the repository
+	repositoryURL, _ := getRemoteRepoURL(ctx.repo)
+	lines, _ = replaceLinkPlaceholder(lines, repositoryURL, nil)
 
 	// add lines to the end of the file
 	lines = append(lines, []string{
@@ -493,11 +912,36 @@ func addCurrentVersion(ctx *RepoContext, changelogPath string) error {
 // - commits the changes
 // - pushes the branch to the remote repository
 // - creates a new merge request on GitLab
-func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
+//
+// The error stopping the project is recorded for the digest and the report.
+func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig, logger *log.Entry) error {
+	err := bumpRepo(globalConfig, projectConfig, logger)
+	recordProjectFailure(globalConfig, projectConfig, err)
+	return err
+}
+
+// bumpRepo goes through the steps of processRepo
+func bumpRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig, logger *log.Entry) error {
 	// Initialize RepoContext
 	ctx := &RepoContext{
 		globalConfig:  globalConfig,
 		projectConfig: projectConfig,
+		timer:         newPhaseTimer(logger),
+		rollback:      &rollbackJournal{},
+		logEntry:      logger,
+	}
+	defer ctx.timer.logSummary(projectConfig.Name)
+
+	if isWaitingForTrain(globalConfig, projectConfig) {
+		ctx.logger().Infof(
+			"Skipping project %s (%s), it's only released with --train", projectConfig.Name, releaseTrainWaiting,
+		)
+		return nil
+	}
+
+	// identify the project before its path is replaced by the cloned repository
+	if projectConfig.id == "" {
+		projectConfig.id = getRepositoryIdentity(projectConfig.Path)
 	}
 
 	// Get global Git config
@@ -507,16 +951,28 @@ func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error
 		return err
 	}
 
+	// The projects keeping their CHANGELOG in another repository are released in both repositories
+	if projectConfig.ChangelogRedirect != nil {
+		return processRedirectedRepo(ctx)
+	}
+
+	// Skip the remote projects with nothing to release before cloning them
+	if skipPrecheckedProject(newAPIClient(globalConfig), globalConfig, projectConfig, logger) {
+		return nil
+	}
+
 	// Clone repository if needed
 	var tmpDir string
+	stopTimer := ctx.timer.start(phaseClone)
 	tmpDir, err = cloneRepoIfNeeded(ctx)
+	stopTimer()
 	if err != nil {
 		return err
 	}
 	defer os.RemoveAll(tmpDir)
 
 	projectPath := ctx.projectConfig.Path
-	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	changelogPath := findChangelogPath(ctx.projectConfig, logger)
 
 	// Setup repository and worktree
 	err = setupRepo(ctx)
@@ -529,6 +985,28 @@ func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error
 	if err != nil {
 		return err
 	}
+	changelogPath, err = resolveChangelogPath(projectPath, changelogPath, logger)
+	if err != nil {
+		return err
+	}
+
+	// Repair the artifacts left by the previous versions before reading the changelog
+	err = tidyChangelogFile(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+
+	// Tag the latest release, once its bump was merged
+	err = tagLatestRelease(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+
+	// Write the Conventional Commits in the changelog, when they are its source
+	err = populateUnreleasedFromCommits(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
 
 	// Determine if bump is needed
 	bumpNeeded, err := shouldBumpProject(ctx, changelogPath)
@@ -536,6 +1014,9 @@ func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error
 		return err
 	}
 	if !bumpNeeded {
+		if ctx.status == projectStatusAlreadyReleased {
+			recordProjectResult(ctx)
+		}
 		return nil
 	}
 
@@ -545,11 +1026,50 @@ func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error
 		return err
 	}
 
+	// Validate the version files before creating the bump branch
+	err = validateVersionFiles(ctx.globalConfig, ctx.projectConfig, &ctx.state, logger)
+	if err != nil {
+		return err
+	}
+
+	// Skip the requested features the remote service can't do, or fail before creating the bump branch
+	serviceType, err := getRemoteServiceType(ctx.repo)
+	if err != nil {
+		return err
+	}
+	ctx.state.skippedFeatures, err = checkRequestedFeatures(
+		ctx.globalConfig, ctx.projectConfig, getServiceInfo(serviceType),
+		logger,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Let the version policy veto the bump before creating the bump branch
+	vetoed, err := isVersionVetoed(ctx, changelogPath)
+	if err != nil || vetoed {
+		return err
+	}
+
+	// Reserve the pull request when there is a limit of pull requests per run
+	organization, reserved, err := reservePullRequest(ctx, changelogPath)
+	if err != nil || !reserved {
+		return err
+	}
+	created := false
+	defer func() {
+		if !created {
+			ctx.globalConfig.pullRequestLimiter.release(organization)
+			ctx.rollback.rollback(ctx.projectConfig.Name, ctx.logger())
+		}
+	}()
+
 	// Create and switch to bump branch
 	branchName, err := createBumpBranch(ctx, changelogPath)
 	if err != nil {
 		return err
 	}
+	ctx.branchName = branchName
 
 	// Update changelog and version files
 	err = updateChangelogAndVersionFiles(ctx, changelogPath)
@@ -562,39 +1082,50 @@ func processRepo(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error
 	if err != nil {
 		return err
 	}
+	prepareLintSuggestions(ctx, changelogPath)
 
 	// Create and checkout pull request
+	stopTimer = ctx.timer.start(phasePR)
 	err = createAndCheckoutPullRequest(ctx, branchName)
+	stopTimer()
 	if err != nil {
 		return err
 	}
 
-	log.Infof("Successfully processed project '%s'", ctx.projectConfig.Name)
+	created = true
+	err = checkPullRequestConflicts(ctx, changelogPath, branchName)
+	recordProjectResult(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.logger().Infof("Successfully processed project '%s'", ctx.projectConfig.Name)
+	queuePropagation(ctx, branchName)
 	return nil
 }
 
 // iterateProjects iterates over the projects and processes them using the processRepo function
 func iterateProjects(globalConfig *GlobalConfig) error {
-	var err error
-	for _, project := range globalConfig.Projects {
-		// verify if the project path exists
-		if _, err = os.Stat(project.Path); os.IsNotExist(err) {
-			// if the project path does not exist, check if it is a remote repository
-			if !strings.HasPrefix(project.Path, "https://") &&
-				!strings.HasPrefix(project.Path, "git@") {
-				// if it is neither a local path nor a remote repository, skip the project
-				log.Errorf("Project path does not exist: %s\n", project.Path)
-				log.Warn("Skipping project")
-				err = ErrProjectPathDoesNotExist
-				continue
-			}
-		}
-
-		err = processRepo(globalConfig, &project)
-		if err != nil {
-			log.Errorf("Error processing project at %s: %v\n", project.Path, err)
-		}
+	// detect the conflicting projects before changing anything
+	err := checkBranchCollisions(planBumps(globalConfig))
+	if err != nil {
+		return err
 	}
 
-	return err
+	globalConfig.pullRequestLimiter = newPullRequestLimiter(globalConfig)
+	defer globalConfig.pullRequestLimiter.logSummary()
+	globalConfig.releaseDigest = newReleaseDigest(globalConfig)
+	defer publishDigest(globalConfig)
+	defer writeRunReport(globalConfig)
+	globalConfig.propagations = &propagationQueue{}
+	defer globalConfig.propagations.propagate(globalConfig)
+	globalConfig.precheck = newUnreleasedPrecheck(globalConfig)
+	defer globalConfig.precheck.logSummary()
+
+	projects, attempted, limited := globalConfig.selection.selectProjects(
+		globalConfig.Projects,
+		func(project *ProjectConfig) bool { return isWaitingForTrain(globalConfig, project) },
+	)
+	defer globalConfig.selection.logSummary(attempted, len(globalConfig.Projects), limited)
+
+	return processProjects(globalConfig, projects)
 }
@@ -1,11 +1,86 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-faker/faker/v4"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+const releaseBranchChangelog = changelogTemplate + `
+
+### Fixed
+
+- Backported fix.
+
+## [1.4.0] - 2024-01-01
+
+### Added
+
+- Old feature.`
+
+const mainBranchChangelog = changelogTemplate + `
+
+### Added
+
+- New feature.
+
+## [2.0.0] - 2024-06-01
+
+### Changed
+
+- **BREAKING CHANGE:** Changed everything.
+
+## [1.4.0] - 2024-01-01
+
+### Added
+
+- Old feature.`
+
+// commitFile writes a file in the repository working tree and commits it
+func commitFile(t *testing.T, repo *git.Repository, name string, content string) plumbing.Hash {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(worktree.Filesystem.Root(), name), []byte(content), 0o600))
+	_, err = worktree.Add(name)
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit(faker.Sentence(), &git.CommitOptions{
+		Author: &object.Signature{Name: faker.Name(), Email: faker.Email(), When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash
+}
+
+// newDivergedRepo creates a repository where "main" and "release/1.x" have diverged changelogs
+func newDivergedRepo(t *testing.T) string {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	repo, err := git.PlainInit(projectPath, false)
+	require.NoError(t, err)
+
+	releaseHash := commitFile(t, repo, "CHANGELOG.md", releaseBranchChangelog+"\n")
+	err = repo.Storer.SetReference(
+		plumbing.NewHashReference(plumbing.NewBranchReferenceName("release/1.x"), releaseHash),
+	)
+	require.NoError(t, err)
+	commitFile(t, repo, "CHANGELOG.md", mainBranchChangelog+"\n")
+
+	return projectPath
+}
+
 func TestHasMatchingExtension_True(t *testing.T) {
 	t.Parallel()
 
@@ -29,3 +104,123 @@ func TestHasMatchingExtension_False(t *testing.T) {
 		t.Error("Expected to not find a matching extension")
 	}
 }
+
+func TestGetNextVersion_BaseRef(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := newDivergedRepo(t)
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: projectPath, BaseRef: "release/1.x"},
+	}
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	version, err := getNextVersion(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.1", version.String())
+}
+
+func TestCreateBumpBranch_BaseRef(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := newDivergedRepo(t)
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: projectPath, BaseRef: "release/1.x"},
+	}
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	branchName, err := createBumpBranch(ctx, changelogPath)
+	require.NoError(t, err)
+	version, err := updateChangelogFile(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "chore/bump-1.4.1", branchName)
+	assert.Equal(t, "1.4.1", version.String())
+	assert.Equal(t, "release/1.x", getTargetBranch(ctx.projectConfig))
+
+	content, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- Backported fix.")
+	assert.NotContains(t, string(content), "## [2.0.0]")
+}
+
+func TestGetNextVersion_HEAD(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := newDivergedRepo(t)
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: projectPath},
+	}
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	version, err := getNextVersion(ctx, filepath.Join(projectPath, "CHANGELOG.md"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.0", version.String())
+	assert.Equal(t, "main", getTargetBranch(ctx.projectConfig))
+}
+
+func TestSetupRepo_BaseRefNotFound(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{Path: newDivergedRepo(t), BaseRef: "release/9.x"},
+	}
+
+	// Act
+	err := setupRepo(ctx)
+
+	// Assert
+	require.ErrorIs(t, err, ErrBaseRefNotFound)
+}
+
+func TestProcessRepo_WaitingForTrain(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{}
+	projectConfig := &ProjectConfig{Name: "project", Path: filepath.Join(t.TempDir(), "missing"), ReleaseTrain: true}
+
+	// Act
+	err := processRepo(globalConfig, projectConfig, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, projectConfig.id)
+}
+
+func TestGetPullRequestDescription_ReleaseTrain(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogOriginal, "\n")
+	projectConfig := &ProjectConfig{}
+	state := &projectState{
+		trainNotes:   formatTrainNotes(lines, time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)),
+		releaseNotes: "### Added\n\n- Another new feature.\n",
+	}
+
+	// Act
+	description := getPullRequestDescription(&GlobalConfig{}, projectConfig, state, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "This release train covers the changes from 1984-01-01 to 2024-06-08.\n\n"+
+		"Some sections of the CHANGELOG were summarized, these are all the changes of this release:\n\n"+
+		"### Added\n\n- Another new feature.\n", description)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// resolveProjectRoot returns the root of the project released from the working directory: the given root when set
+// (with "--project-root"), the working directory when it has its own CHANGELOG (a component of a monorepo),
+// or else the root of the repository enclosing it
+func resolveProjectRoot(workingDir string, projectRoot string) (string, error) {
+	if projectRoot != "" {
+		root, err := filepath.Abs(projectRoot)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve the project root %s: %w", projectRoot, err)
+		}
+		log.Infof("Using the project root %s", root)
+		return root, nil
+	}
+
+	repositoryRoot, found := findRepositoryRoot(workingDir)
+	if !found || repositoryRoot == workingDir {
+		// the working directory is reported as it is when it isn't in a repository
+		return workingDir, nil
+	}
+
+	if _, found = findDirChangelogName(workingDir); found {
+		log.Infof(
+			"Using %s as the project root, since it has its own CHANGELOG.md (the repository root is %s)",
+			workingDir, repositoryRoot,
+		)
+		return workingDir, nil
+	}
+	log.Infof("Using the repository root %s as the project root, since %s has no CHANGELOG.md", repositoryRoot, workingDir)
+	return repositoryRoot, nil
+}
+
+// findRepositoryRoot returns the root of the worktree of the repository enclosing the directory
+func findRepositoryRoot(dir string) (string, bool) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return "", false
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", false
+	}
+	return worktree.Filesystem.Root(), true
+}
+
+// getWorktreePath returns the path of the file relative to the root of the worktree, as it is staged.
+// It differs from the path relative to the project when the project is a directory of the repository.
+func getWorktreePath(ctx *RepoContext, filePath string) (string, error) {
+	root := ctx.projectConfig.Path
+	if ctx.worktree != nil {
+		root = ctx.worktree.Filesystem.Root()
+	}
+
+	relativePath, err := filepath.Rel(root, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get relative path for file: %w", err)
+	}
+	return relativePath, nil
+}
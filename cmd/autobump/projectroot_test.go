@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNestedProjectRepo creates a repository with the "services/api" directory, along with the CHANGELOG
+// and the version file either at the root of the repository or in that directory
+func newNestedProjectRepo(t *testing.T, componentRoot bool) (string, *git.Repository, string) {
+	t.Helper()
+
+	repoPath, repo := newRedirectRepo(t, "https://github.com/company/platform.git")
+	nestedPath := filepath.Join(repoPath, "services", "api")
+	require.NoError(t, os.MkdirAll(nestedPath, 0o755))
+	commitFile(t, repo, filepath.Join("services", "api", "main.txt"), "api\n")
+
+	prefix := ""
+	if componentRoot {
+		prefix = filepath.Join("services", "api")
+	}
+	commitFile(t, repo, filepath.Join(prefix, "CHANGELOG.md"), changelogOriginal+"\n")
+	commitFile(t, repo, filepath.Join(prefix, "version.txt"), "version=1.0.1\n")
+	return repoPath, repo, nestedPath
+}
+
+// bumpProject creates the bump branch and commit of the project, without pushing them
+func bumpProject(t *testing.T, projectPath string) string {
+	t.Helper()
+
+	ctx := &RepoContext{
+		globalConfig: &GlobalConfig{
+			LanguagesConfig: map[string]LanguageConfig{
+				"text": {VersionFiles: []VersionFile{{Path: "version.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}}}},
+			},
+			releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+		},
+		projectConfig:   &ProjectConfig{Path: projectPath, Name: "api", Language: "text"},
+		globalGitConfig: newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+		timer:           newPhaseTimer(standardLogEntry()),
+	}
+	require.NoError(t, setupRepo(ctx))
+
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	bumpNeeded, err := shouldBumpProject(ctx, changelogPath)
+	require.NoError(t, err)
+	require.True(t, bumpNeeded)
+	branchName, err := createBumpBranch(ctx, changelogPath)
+	require.NoError(t, err)
+	require.NoError(t, updateChangelogAndVersionFiles(ctx, changelogPath))
+	_, err = commitChangesWithGPG(ctx)
+	require.NoError(t, err)
+	return branchName
+}
+
+func TestResolveProjectRoot_RepositoryRoot(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repoPath, repo, nestedPath := newNestedProjectRepo(t, false)
+
+	// Act
+	projectRoot, err := resolveProjectRoot(nestedPath, "")
+	branchName := bumpProject(t, projectRoot)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, repoPath, projectRoot)
+	assert.Contains(t, readBranchFile(t, repo, branchName, "CHANGELOG.md"), "## [1.1.0] - 2024-06-08")
+	assert.Equal(t, "version=1.1.0\n", readBranchFile(t, repo, branchName, "version.txt"))
+}
+
+func TestResolveProjectRoot_ComponentRoot(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	_, repo, nestedPath := newNestedProjectRepo(t, true)
+
+	// Act
+	projectRoot, err := resolveProjectRoot(nestedPath, "")
+	branchName := bumpProject(t, projectRoot)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, nestedPath, projectRoot)
+	assert.Contains(t, readBranchFile(t, repo, branchName, "services/api/CHANGELOG.md"), "## [1.1.0] - 2024-06-08")
+	assert.Equal(t, "version=1.1.0\n", readBranchFile(t, repo, branchName, "services/api/version.txt"))
+}
+
+func TestResolveProjectRoot_Flag(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repoPath, _, nestedPath := newNestedProjectRepo(t, true)
+
+	// Act
+	projectRoot, err := resolveProjectRoot(nestedPath, repoPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, repoPath, projectRoot)
+}
+
+func TestResolveProjectRoot_OutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dir := t.TempDir()
+
+	// Act
+	projectRoot, err := resolveProjectRoot(dir, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, dir, projectRoot)
+}
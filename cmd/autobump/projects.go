@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// projectsStdinSource is the source of the projects piped to the batch command
+const projectsStdinSource = "stdin"
+
+var (
+	ErrInvalidProjectEntry = errors.New("invalid project entry")
+	ErrConflictingProjects = errors.New("--projects-file and --projects-stdin can't be used together")
+)
+
+// projectsDocument is a projects file with the "projects" key, like the configuration file
+type projectsDocument struct {
+	Projects []yaml.Node `yaml:"projects"`
+}
+
+// decodeProjectsWithDefaults decodes each project over the project defaults, so the projects inherit the options
+// they don't set (the ones they set, even when empty or false, are kept)
+func decodeProjectsWithDefaults(nodes []yaml.Node, defaults ProjectConfig) ([]ProjectConfig, error) {
+	projects := make([]ProjectConfig, 0, len(nodes))
+	for index := range nodes {
+		project := copyProjectDefaults(defaults)
+		err := nodes[index].Decode(&project)
+		if err != nil {
+			return nil, fmt.Errorf("projects[%d]: %w", index, err)
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// copyProjectDefaults returns a copy of the project defaults, which doesn't share the version streams
+// nor the reviewers
+func copyProjectDefaults(defaults ProjectConfig) ProjectConfig {
+	project := defaults
+	project.VersionStreams = append([]VersionStream(nil), defaults.VersionStreams...)
+	project.Reviewers = append([]string(nil), defaults.Reviewers...)
+	return project
+}
+
+// applyProjectDefaults decodes the projects of the configuration file again, over the project defaults
+func applyProjectDefaults(data []byte, globalConfig *GlobalConfig) error {
+	var document projectsDocument
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return fmt.Errorf("failed to decode the projects: %w", err)
+	}
+
+	globalConfig.Projects, err = decodeProjectsWithDefaults(document.Projects, globalConfig.ProjectDefaults)
+	return err
+}
+
+// prepareProject registers the credentials embedded in the project path and names the project after it
+func prepareProject(projectConfig *ProjectConfig) {
+	registerEmbeddedCredentials(projectConfig)
+	if projectConfig.Name == "" {
+		projectConfig.Name = strings.TrimSuffix(path.Base(projectConfig.Path), ".git")
+	}
+}
+
+// readProjectsFile reads the projects of the batch run from a file or from stdin, replacing the ones of the
+// configuration file. The input is either YAML (a list of projects, or a document with the "projects" key)
+// or a list of repository URLs, one per line, where the lines starting with "#" are comments.
+func readProjectsFile(reader io.Reader, source string, globalConfig *GlobalConfig) ([]ProjectConfig, error) {
+	maxFileSize := getMaxFileSize(globalConfig)
+	data, err := io.ReadAll(io.LimitReader(reader, maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the projects from %s: %w", source, err)
+	}
+	if int64(len(data)) > maxFileSize {
+		return nil, fmt.Errorf(
+			"%w: the projects from %s (limit is %s)", ErrFileTooLarge, source, formatBytes(maxFileSize),
+		)
+	}
+
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) == nil && len(root.Content) > 0 && root.Content[0].Kind != yaml.ScalarNode {
+		return readProjectsYAML(root.Content[0], source, globalConfig)
+	}
+	return readProjectsList(data, source, globalConfig)
+}
+
+// readProjectsYAML reads the projects of a YAML list, or of the "projects" key of a YAML document
+func readProjectsYAML(node *yaml.Node, source string, globalConfig *GlobalConfig) ([]ProjectConfig, error) {
+	var nodes []yaml.Node
+	switch node.Kind { //nolint:exhaustive // the scalars are read as a list of URLs
+	case yaml.SequenceNode:
+		err := node.Decode(&nodes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %w", ErrInvalidProjectEntry, source, node.Line, err)
+		}
+	case yaml.MappingNode:
+		var document projectsDocument
+		err := node.Decode(&document)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %w", ErrInvalidProjectEntry, source, node.Line, err)
+		}
+		nodes = document.Projects
+	default:
+		return nil, fmt.Errorf("%w: %s:%d: expected a list of projects", ErrInvalidProjectEntry, source, node.Line)
+	}
+
+	projects := make([]ProjectConfig, 0, len(nodes))
+	for index := range nodes {
+		project := copyProjectDefaults(globalConfig.ProjectDefaults)
+
+		// the entries are decoded as strictly as the configuration file
+		content, err := yaml.Marshal(&nodes[index])
+		if err == nil {
+			decoder := yaml.NewDecoder(bytes.NewReader(content))
+			decoder.KnownFields(true)
+			err = decoder.Decode(&project)
+		}
+		if err == nil {
+			err = validateProjectEntry(globalConfig, &project)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %w", ErrInvalidProjectEntry, source, nodes[index].Line, err)
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// readProjectsList reads the repository URLs, one per line, skipping the blank lines and the comments
+func readProjectsList(data []byte, source string, globalConfig *GlobalConfig) ([]ProjectConfig, error) {
+	var projects []ProjectConfig
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.ContainsAny(line, " \t") {
+			return nil, fmt.Errorf(
+				"%w: %s:%d: expected a single repository URL", ErrInvalidProjectEntry, source, lineNumber,
+			)
+		}
+
+		project := copyProjectDefaults(globalConfig.ProjectDefaults)
+		project.Path = line
+		err := validateProjectEntry(globalConfig, &project)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %w", ErrInvalidProjectEntry, source, lineNumber, err)
+		}
+		projects = append(projects, project)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the projects from %s: %w", source, err)
+	}
+	return projects, nil
+}
+
+// validateProjectEntry validates a project read from a projects file with the rules of the configured projects
+func validateProjectEntry(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
+	if projectConfig.Path == "" {
+		return fmt.Errorf("%w: path", ErrConfigKeyMissingError)
+	}
+	prepareProject(projectConfig)
+
+	err := validateProjectConfig(globalConfig, projectConfig)
+	if err != nil {
+		return err
+	}
+
+	if projectConfig.ChangelogTemplatePath != "" {
+		projectConfig.changelogTemplate, err = loadChangelogTemplate(projectConfig.ChangelogTemplatePath)
+	}
+	return err
+}
+
+// loadBatchProjects replaces the projects of the configuration file with the ones of the projects file or stdin
+func loadBatchProjects(globalConfig *GlobalConfig, projectsFile string, fromStdin bool, stdin io.Reader) error {
+	var projects []ProjectConfig
+	var err error
+	switch {
+	case projectsFile != "" && fromStdin:
+		return ErrConflictingProjects
+	case projectsFile != "":
+		var file *os.File
+		file, err = os.Open(projectsFile)
+		if err != nil {
+			return fmt.Errorf("failed to open the projects file: %w", err)
+		}
+		defer file.Close()
+		projects, err = readProjectsFile(file, projectsFile, globalConfig)
+	case fromStdin:
+		projects, err = readProjectsFile(stdin, projectsStdinSource, globalConfig)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(globalConfig.Projects) > 0 {
+		log.Infof(
+			"Replacing the %d projects of the config file with the %d read", len(globalConfig.Projects), len(projects),
+		)
+	}
+	globalConfig.Projects = projects
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProjectsFile_URLList(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader(`# generated by the inventory
+https://github.com/rios0rios0/autobump.git
+
+git@gitlab.com:group/project.git
+`)
+	globalConfig := &GlobalConfig{ProjectDefaults: ProjectConfig{Language: "go"}}
+
+	// Act
+	projects, err := readProjectsFile(input, projectsStdinSource, globalConfig)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "https://github.com/rios0rios0/autobump.git", projects[0].Path)
+	assert.Equal(t, "autobump", projects[0].Name)
+	assert.Equal(t, "go", projects[0].Language)
+	assert.Equal(t, "project", projects[1].Name)
+	assert.Equal(t, "go", projects[1].Language)
+}
+
+func TestReadProjectsFile_YAMLList(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader(`- path: https://github.com/rios0rios0/autobump.git
+- path: https://gitlab.com/group/project.git
+  name: renamed
+  language: python
+`)
+	globalConfig := &GlobalConfig{ProjectDefaults: ProjectConfig{Language: "go", ReleaseTrain: true}}
+
+	// Act
+	projects, err := readProjectsFile(input, "projects.yaml", globalConfig)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "go", projects[0].Language)
+	assert.True(t, projects[0].ReleaseTrain)
+	assert.Equal(t, "renamed", projects[1].Name)
+	assert.Equal(t, "python", projects[1].Language)
+	assert.True(t, projects[1].ReleaseTrain)
+}
+
+func TestReadProjectsFile_YAMLDocument(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader(`projects:
+  - path: https://github.com/rios0rios0/autobump.git
+    release_train: false
+`)
+	globalConfig := &GlobalConfig{ProjectDefaults: ProjectConfig{ReleaseTrain: true}}
+
+	// Act
+	projects, err := readProjectsFile(input, "projects.yaml", globalConfig)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.False(t, projects[0].ReleaseTrain)
+}
+
+func TestReadProjectsFile_MalformedYAMLEntry(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader(`- path: https://github.com/rios0rios0/autobump.git
+- path: https://gitlab.com/group/project.git
+  versioning_scheme: roman
+`)
+
+	// Act
+	_, err := readProjectsFile(input, "projects.yaml", &GlobalConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidProjectEntry)
+	require.ErrorIs(t, err, ErrInvalidVersioningScheme)
+	assert.ErrorContains(t, err, "projects.yaml:2:")
+}
+
+func TestReadProjectsFile_UnknownYAMLKey(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader(`- path: https://github.com/rios0rios0/autobump.git
+  lang: go
+`)
+
+	// Act
+	_, err := readProjectsFile(input, "projects.yaml", &GlobalConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidProjectEntry)
+	assert.ErrorContains(t, err, "projects.yaml:1:")
+}
+
+func TestReadProjectsFile_ManifestOutsideTheUmbrella(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader(`- path: https://gitlab.com/group/project.git
+  propagate_to:
+    path: https://gitlab.com/group/umbrella.git
+    file: ../../x.yaml
+`)
+
+	// Act
+	_, err := readProjectsFile(input, "projects.yaml", &GlobalConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidProjectEntry)
+	require.ErrorIs(t, err, ErrInvalidPropagationTarget)
+	assert.ErrorContains(t, err, "projects.yaml:1:")
+}
+
+func TestReadProjectsFile_MalformedURLLine(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	input := strings.NewReader("https://github.com/rios0rios0/autobump.git\n\nhttps://gitlab.com/group/project.git extra\n")
+
+	// Act
+	_, err := readProjectsFile(input, projectsStdinSource, &GlobalConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidProjectEntry)
+	assert.ErrorContains(t, err, "stdin:3:")
+}
+
+func TestLoadBatchProjects_ReplacesConfigProjects(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectsFile := filepath.Join(t.TempDir(), "projects.txt")
+	require.NoError(t, os.WriteFile(projectsFile, []byte("https://github.com/rios0rios0/autobump.git\n"), 0o600))
+	globalConfig := &GlobalConfig{Projects: []ProjectConfig{{Path: "/configured", Name: "configured"}}}
+
+	// Act
+	err := loadBatchProjects(globalConfig, projectsFile, false, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, globalConfig.Projects, 1)
+	assert.Equal(t, "autobump", globalConfig.Projects[0].Name)
+}
+
+func TestLoadBatchProjects_ConflictingSources(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{}
+
+	// Act
+	err := loadBatchProjects(globalConfig, "projects.txt", true, strings.NewReader(""))
+
+	// Assert
+	require.ErrorIs(t, err, ErrConflictingProjects)
+}
+
+func TestDecodeConfig_ProjectDefaults(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	data := []byte(`project_defaults:
+  language: go
+  release_train: true
+projects:
+  - path: /first
+  - path: /second
+    language: python
+    release_train: false
+`)
+
+	// Act
+	globalConfig, err := decodeConfig(data)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, globalConfig.Projects, 2)
+	assert.Equal(t, "go", globalConfig.Projects[0].Language)
+	assert.True(t, globalConfig.Projects[0].ReleaseTrain)
+	assert.Equal(t, "python", globalConfig.Projects[1].Language)
+	assert.False(t, globalConfig.Projects[1].ReleaseTrain)
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// projectStatusPropagationFailed is the status of the projects whose pull request was created,
+	// but whose version couldn't be propagated to the umbrella repository
+	projectStatusPropagationFailed = "propagation-failed"
+
+	defaultPropagationBranch = "chore/propagate-" +
+		"{{range $index, $release := .Releases}}{{if $index}}-{{end}}{{.Name}}-{{.Version}}{{end}}"
+	defaultPropagationTitle = "chore(propagate): bumped " +
+		"{{range $index, $release := .Releases}}{{if $index}}, {{end}}{{.Name}} to {{.Version}}{{end}}"
+)
+
+var (
+	ErrInvalidPropagationTarget = errors.New("invalid propagate_to")
+	ErrPropagationKeyNotFound   = errors.New("the key of the version isn't in the manifest")
+	ErrUnsupportedManifestValue = errors.New("the version of the manifest can't be replaced in place")
+)
+
+// PropagationTarget is the manifest of an "umbrella" repository (e.g. the deployment repository) pinning the version
+// of the project, which is updated by a pull request of its own once the project is bumped
+type PropagationTarget struct {
+	Path   string `yaml:"path"`
+	File   string `yaml:"file"`
+	Key    string `yaml:"key"`
+	Branch string `yaml:"branch"`
+	Title  string `yaml:"title"`
+}
+
+// getKeyPath returns the path of the version in the manifest, which is the name of the project by default
+func (t *PropagationTarget) getKeyPath(projectName string) []string {
+	if t.Key == "" {
+		return []string{projectName}
+	}
+	return strings.Split(t.Key, ".")
+}
+
+// getBranchTemplate returns the template of the branch in the umbrella repository
+func (t *PropagationTarget) getBranchTemplate() string {
+	if t.Branch == "" {
+		return defaultPropagationBranch
+	}
+	return t.Branch
+}
+
+// getTitleTemplate returns the template of the commit and the pull request in the umbrella repository
+func (t *PropagationTarget) getTitleTemplate() string {
+	if t.Title == "" {
+		return defaultPropagationTitle
+	}
+	return t.Title
+}
+
+// validatePropagationTarget checks the umbrella manifest of the project, which must be a YAML or JSON file
+// inside the repository
+func validatePropagationTarget(projectConfig *ProjectConfig) error {
+	target := projectConfig.PropagateTo
+	if target == nil {
+		return nil
+	}
+	if target.Path == "" {
+		return fmt.Errorf("%w: the path of the repository is missing", ErrInvalidPropagationTarget)
+	}
+	if target.File == "" {
+		return fmt.Errorf("%w: the file of the manifest is missing", ErrInvalidPropagationTarget)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(target.File)) {
+		return fmt.Errorf("%w: %s is outside the repository", ErrInvalidPropagationTarget, target.File)
+	}
+	switch strings.ToLower(filepath.Ext(target.File)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return fmt.Errorf("%w: %s isn't a YAML or JSON file", ErrInvalidPropagationTarget, target.File)
+	}
+	for _, keyPart := range target.getKeyPath(projectConfig.Name) {
+		if keyPart == "" {
+			return fmt.Errorf("%w: the key %q has an empty part", ErrInvalidPropagationTarget, target.Key)
+		}
+	}
+	for _, text := range []string{target.getBranchTemplate(), target.getTitleTemplate()} {
+		if _, err := template.New("propagate_to").Parse(text); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPropagationTarget, err)
+		}
+	}
+	return nil
+}
+
+// propagatedRelease is a release of a project waiting to be propagated to its umbrella repository
+type propagatedRelease struct {
+	Name    string
+	Version string
+	// pull request of the release, or where to open it when it wasn't created
+	PullRequestURL string
+
+	id          string
+	keyPath     []string
+	target      PropagationTarget
+	accessToken string
+	signCommits string
+}
+
+// propagationTemplateData is the data of the branch and title templates: the first release, and all the releases
+// when several projects are propagated to the same manifest
+type propagationTemplateData struct {
+	Name     string
+	Version  string
+	Releases []propagatedRelease
+}
+
+// propagationQueue keeps the releases of a batch run to propagate at its end, so the projects pinned in the same
+// manifest are propagated together. It is safe to be shared by concurrent workers.
+type propagationQueue struct {
+	mutex    sync.Mutex
+	releases []propagatedRelease
+}
+
+// add queues the release of a project
+func (q *propagationQueue) add(release propagatedRelease) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.releases = append(q.releases, release)
+}
+
+// propagate propagates the queued releases to their umbrella repositories
+func (q *propagationQueue) propagate(globalConfig *GlobalConfig) {
+	if q == nil {
+		return
+	}
+
+	q.mutex.Lock()
+	releases := q.releases
+	q.releases = nil
+	q.mutex.Unlock()
+	propagateReleases(globalConfig, releases)
+}
+
+// queuePropagation queues the release of the project for its umbrella repository,
+// propagating it right away outside the batch runs
+func queuePropagation(ctx *RepoContext, branchName string) {
+	target := ctx.projectConfig.PropagateTo
+	if target == nil {
+		return
+	}
+
+	release := propagatedRelease{
+		Name:           ctx.projectConfig.Name,
+		Version:        ctx.projectConfig.NewVersion,
+		PullRequestURL: getRedirectLink(ctx, branchName),
+		id:             ctx.projectConfig.id,
+		keyPath:        target.getKeyPath(ctx.projectConfig.Name),
+		target:         *target,
+		accessToken:    ctx.projectConfig.ProjectAccessToken,
+		signCommits:    ctx.projectConfig.SignCommits,
+	}
+	if ctx.globalConfig.propagations == nil {
+		propagateReleases(ctx.globalConfig, []propagatedRelease{release})
+		return
+	}
+	ctx.globalConfig.propagations.add(release)
+}
+
+// groupPropagations groups the releases updating the same manifest with the same templates, so each group is
+// a single pull request. The releases updating a key already updated by the group start another one,
+// propagated after it with its own branch.
+func groupPropagations(releases []propagatedRelease) [][]propagatedRelease {
+	var groups [][]propagatedRelease
+	for _, release := range releases {
+		grouped := false
+		for index, group := range groups {
+			if group[0].target.Path != release.target.Path ||
+				filepath.FromSlash(group[0].target.File) != filepath.FromSlash(release.target.File) ||
+				group[0].target.getBranchTemplate() != release.target.getBranchTemplate() ||
+				group[0].target.getTitleTemplate() != release.target.getTitleTemplate() ||
+				hasPropagatedKey(group, release.keyPath) {
+				continue
+			}
+			groups[index] = append(group, release)
+			grouped = true
+			break
+		}
+		if !grouped {
+			groups = append(groups, []propagatedRelease{release})
+		}
+	}
+	return groups
+}
+
+// hasPropagatedKey checks whether a release of the group updates the key
+func hasPropagatedKey(group []propagatedRelease, keyPath []string) bool {
+	for _, release := range group {
+		if strings.Join(release.keyPath, ".") == strings.Join(keyPath, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// propagateReleases opens a pull request in the umbrella repositories for each group of releases.
+// The failures don't fail the releases, whose pull requests exist already, but mark them as partial.
+func propagateReleases(globalConfig *GlobalConfig, releases []propagatedRelease) {
+	for _, group := range groupPropagations(releases) {
+		pullRequestURL, err := propagateGroup(globalConfig, group)
+		for _, release := range group {
+			if err != nil {
+				log.Errorf(
+					"Project %s (%s): the pull request was created (%s), but the version %s couldn't be "+
+						"propagated to %s of %s: %v",
+					release.Name, projectStatusPropagationFailed, release.PullRequestURL,
+					release.Version, release.target.File, release.target.Path, err,
+				)
+			}
+			globalConfig.releaseDigest.updateResult(release.id, release.Name, func(result *ProjectResult) {
+				if err != nil {
+					result.Status = projectStatusPropagationFailed
+					return
+				}
+				result.PropagationURL = pullRequestURL
+			})
+		}
+	}
+}
+
+// newPropagationContext creates the context of the umbrella repository, which is changed with the credentials
+// and the commit signature of the first propagated project
+func newPropagationContext(globalConfig *GlobalConfig, releases []propagatedRelease) *RepoContext {
+	return &RepoContext{
+		globalConfig: globalConfig,
+		projectConfig: &ProjectConfig{
+			Path:               releases[0].target.Path,
+			Name:               strings.TrimSuffix(path.Base(releases[0].target.Path), ".git") + " (umbrella)",
+			ProjectAccessToken: releases[0].accessToken,
+			SignCommits:        releases[0].signCommits,
+		},
+		timer: newPhaseTimer(standardLogEntry()),
+	}
+}
+
+// propagateGroup updates the manifest of the umbrella repository with the versions of the releases,
+// returning the pull request of the update (or where to open it)
+func propagateGroup(globalConfig *GlobalConfig, releases []propagatedRelease) (string, error) {
+	ctx := newPropagationContext(globalConfig, releases)
+	var err error
+	ctx.globalGitConfig, err = getGlobalGitConfig()
+	if err != nil {
+		return "", err
+	}
+
+	umbrellaDir, err := cloneRepoIfNeeded(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(umbrellaDir)
+
+	err = setupRepo(ctx)
+	if err != nil {
+		return "", err
+	}
+	branchName, err := preparePropagation(ctx, releases)
+	if err != nil {
+		return "", err
+	}
+	err = pushChanges(ctx, branchName)
+	if err != nil {
+		return "", err
+	}
+	err = createAndCheckoutPullRequest(ctx, branchName)
+	if err != nil {
+		return "", err
+	}
+	return getRedirectLink(ctx, branchName), nil
+}
+
+// preparePropagation creates the branch of the umbrella repository and commits the versions of the releases
+// into the manifest, without pushing it
+func preparePropagation(ctx *RepoContext, releases []propagatedRelease) (string, error) {
+	data := propagationTemplateData{Name: releases[0].Name, Version: releases[0].Version, Releases: releases}
+	branchName, err := renderPropagationTemplate(releases[0].target.getBranchTemplate(), data)
+	if err != nil {
+		return "", err
+	}
+	ctx.state.commitSubject, err = renderPropagationTemplate(releases[0].target.getTitleTemplate(), data)
+	if err != nil {
+		return "", err
+	}
+	ctx.state.propagationNotes = formatPropagationNotes(releases)
+
+	branchExists, err := checkBranchExists(ctx.repo, branchName)
+	if err != nil {
+		return "", err
+	}
+	if branchExists {
+		return "", fmt.Errorf("%w: %s", ErrBranchExists, branchName)
+	}
+	err = createAndSwitchBranch(ctx.repo, ctx.worktree, branchName, ctx.head.Hash(), ctx.logger())
+	if err != nil {
+		return "", err
+	}
+
+	// the manifest is checked again once cloned, since it may be a symlink leaving the repository
+	relativePath, err := ensureWithinRepo(ctx.projectConfig.Path, filepath.FromSlash(releases[0].target.File))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidPropagationTarget, err)
+	}
+	manifestPath := filepath.Join(ctx.projectConfig.Path, relativePath)
+	err = checkFileSize(manifestPath, getMaxFileSize(ctx.globalConfig))
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the manifest: %w", err)
+	}
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the manifest: %w", err)
+	}
+	for _, release := range releases {
+		ctx.logger().Infof("Propagating %s %s to %s", release.Name, release.Version, releases[0].target.File)
+		content, err = setManifestValue(content, release.keyPath, release.Version)
+		if err != nil {
+			return "", fmt.Errorf("%s of %s: %w", strings.Join(release.keyPath, "."), release.target.File, err)
+		}
+	}
+	err = os.WriteFile(manifestPath, content, info.Mode().Perm())
+	if err != nil {
+		return "", fmt.Errorf("failed to write the manifest: %w", err)
+	}
+	err = addFileToWorktree(ctx, manifestPath)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = commitChangesWithGPG(ctx)
+	if err != nil {
+		return "", err
+	}
+	return branchName, nil
+}
+
+// renderPropagationTemplate renders the branch or the title of the propagation
+func renderPropagationTemplate(text string, data propagationTemplateData) (string, error) {
+	tmpl, err := template.New("propagate_to").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the template %q: %w", text, err)
+	}
+	var builder strings.Builder
+	err = tmpl.Execute(&builder, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the template %q: %w", text, err)
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// formatPropagationNotes writes in the pull request of the umbrella repository the pull requests of the releases
+func formatPropagationNotes(releases []propagatedRelease) string {
+	lines := []string{"This pull request propagates the releases of:", ""}
+	for _, release := range releases {
+		lines = append(lines, fmt.Sprintf("- %s %s, released by %s", release.Name, release.Version, release.PullRequestURL))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setManifestValue replaces the value of the key in the YAML or JSON manifest, keeping the rest of the file
+// (e.g. the comments and the quotes) byte for byte. The key must exist with a scalar value.
+func setManifestValue(content []byte, keyPath []string, value string) ([]byte, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, ErrPropagationKeyNotFound
+	}
+
+	node := document.Content[0]
+	for _, key := range keyPath {
+		if node.Kind != yaml.MappingNode {
+			return nil, ErrPropagationKeyNotFound
+		}
+		var child *yaml.Node
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			if node.Content[index].Value == key {
+				child = node.Content[index+1]
+			}
+		}
+		if child == nil {
+			return nil, ErrPropagationKeyNotFound
+		}
+		node = child
+	}
+	if node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("%w: the value isn't a scalar", ErrUnsupportedManifestValue)
+	}
+	if node.Value == value {
+		return content, nil
+	}
+
+	quote := ""
+	switch node.Style { //nolint:exhaustive // the other styles span several lines
+	case 0:
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = "'"
+	default:
+		return nil, fmt.Errorf("%w: the value isn't on a single line", ErrUnsupportedManifestValue)
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	line := []rune(lines[node.Line-1])
+	start := node.Column - 1
+	token := []rune(quote + node.Value + quote)
+	if start+len(token) > len(line) || string(line[start:start+len(token)]) != string(token) {
+		return nil, fmt.Errorf("%w: the value is escaped or spans several lines", ErrUnsupportedManifestValue)
+	}
+	lines[node.Line-1] = string(line[:start]) + quote + value + quote + string(line[start+len(token):])
+	return []byte(strings.Join(lines, "")), nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const umbrellaManifest = `# versions deployed to production
+services:
+  payments-api: 1.4.2 # pinned during the freeze
+  orders-api: "2.0.0"
+  billing-api: '0.9.0'
+`
+
+// newUmbrellaRelease creates the release of a service pinned in the manifest of the umbrella repository
+func newUmbrellaRelease(umbrellaPath string, name string, version string) propagatedRelease {
+	target := PropagationTarget{Path: umbrellaPath, File: "deploy/versions.yaml", Key: "services." + name}
+	return propagatedRelease{
+		Name:           name,
+		Version:        version,
+		PullRequestURL: "https://gitlab.com/company/" + name + "/-/merge_requests/1",
+		id:             "gitlab.com/company/" + name,
+		keyPath:        target.getKeyPath(name),
+		target:         target,
+	}
+}
+
+func TestPreparePropagation_CombinesTheServicesOfTheManifest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	umbrellaPath, umbrellaRepo := newRedirectRepo(t, "https://gitlab.com/company/deploy.git")
+	require.NoError(t, os.MkdirAll(filepath.Join(umbrellaPath, "deploy"), 0o755))
+	commitFile(t, umbrellaRepo, filepath.Join("deploy", "versions.yaml"), umbrellaManifest)
+	releases := []propagatedRelease{
+		newUmbrellaRelease(umbrellaPath, "payments-api", "1.5.0"),
+		newUmbrellaRelease(umbrellaPath, "orders-api", "2.1.0"),
+	}
+	groups := groupPropagations(releases)
+	require.Len(t, groups, 1, "the services of the same manifest should be propagated together")
+
+	globalConfig := &GlobalConfig{releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)}
+	ctx := newPropagationContext(globalConfig, groups[0])
+	ctx.globalGitConfig = newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n")
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	branchName, err := preparePropagation(ctx, groups[0])
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "chore/propagate-payments-api-1.5.0-orders-api-2.1.0", branchName)
+	assert.Equal(t, `# versions deployed to production
+services:
+  payments-api: 1.5.0 # pinned during the freeze
+  orders-api: "2.1.0"
+  billing-api: '0.9.0'
+`, readBranchFile(t, umbrellaRepo, branchName, "deploy/versions.yaml"))
+	assert.Equal(t, "chore(propagate): bumped payments-api to 1.5.0, orders-api to 2.1.0",
+		getCommitSubject(ctx.globalConfig, ctx.projectConfig, &ctx.state, "", standardLogEntry()))
+	description := getPullRequestDescription(ctx.globalConfig, ctx.projectConfig, &ctx.state, standardLogEntry())
+	assert.Contains(t, description, "- payments-api 1.5.0, released by "+releases[0].PullRequestURL)
+	assert.Contains(t, description, "- orders-api 2.1.0, released by "+releases[1].PullRequestURL)
+}
+
+func TestPropagateReleases_MarksTheFailureAsPartial(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	missingPath := filepath.Join(t.TempDir(), "missing")
+	releases := []propagatedRelease{
+		newUmbrellaRelease(missingPath, "payments-api", "1.5.0"),
+		newUmbrellaRelease(missingPath, "orders-api", "2.1.0"),
+	}
+	globalConfig := &GlobalConfig{releaseDigest: &releaseDigest{}}
+	for _, release := range releases {
+		globalConfig.releaseDigest.add(ProjectResult{
+			ID: release.id, Name: release.Name, NextVersion: release.Version, PullRequestURL: release.PullRequestURL,
+		})
+	}
+	globalConfig.releaseDigest.add(ProjectResult{ID: "gitlab.com/company/users-api", Name: "users-api"})
+
+	// Act
+	propagateReleases(globalConfig, releases)
+
+	// Assert
+	results := globalConfig.releaseDigest.getResults()
+	require.Len(t, results, 3)
+	for _, result := range results {
+		if result.Name == "users-api" {
+			assert.Empty(t, result.Status, "the projects without propagation shouldn't be changed")
+			continue
+		}
+		assert.Equal(t, projectStatusPropagationFailed, result.Status)
+		assert.NotEmpty(t, result.PullRequestURL, "the pull request of the release should be kept")
+		assert.Empty(t, result.PropagationURL)
+	}
+}
+
+func TestGroupPropagations_SeparatesTheConflictingReleases(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	payments := newUmbrellaRelease("/deploy", "payments-api", "1.5.0")
+	paymentsAgain := newUmbrellaRelease("/deploy", "payments-api", "1.6.0")
+	staging := newUmbrellaRelease("/deploy", "orders-api", "2.1.0")
+	staging.target.File = "deploy/staging.yaml"
+	customBranch := newUmbrellaRelease("/deploy", "billing-api", "0.9.1")
+	customBranch.target.Branch = "chore/deploy-{{.Name}}"
+	orders := newUmbrellaRelease("/deploy", "orders-api", "2.1.0")
+
+	// Act
+	groups := groupPropagations([]propagatedRelease{payments, paymentsAgain, staging, customBranch, orders})
+
+	// Assert
+	require.Len(t, groups, 4)
+	assert.Equal(t, []propagatedRelease{payments, orders}, groups[0])
+	assert.Equal(t, []propagatedRelease{paymentsAgain}, groups[1], "the same key should be updated by another branch")
+	assert.Equal(t, []propagatedRelease{staging}, groups[2])
+	assert.Equal(t, []propagatedRelease{customBranch}, groups[3])
+}
+
+func TestSetManifestValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		keyPath  []string
+		expected string
+		err      error
+	}{
+		{
+			name:     "should keep the comments of the YAML",
+			content:  "# pinned\npayments-api: 1.4.2 # freeze\n",
+			keyPath:  []string{"payments-api"},
+			expected: "# pinned\npayments-api: 2.0.0 # freeze\n",
+		},
+		{
+			name:     "should keep the quotes of the JSON",
+			content:  "{\n  \"services\": {\n    \"payments-api\": \"1.4.2\"\n  }\n}\n",
+			keyPath:  []string{"services", "payments-api"},
+			expected: "{\n  \"services\": {\n    \"payments-api\": \"2.0.0\"\n  }\n}\n",
+		},
+		{
+			name:     "should keep the flow mappings",
+			content:  "services: {payments-api: 1.4.2, orders-api: 1.4.2}\n",
+			keyPath:  []string{"services", "orders-api"},
+			expected: "services: {payments-api: 1.4.2, orders-api: 2.0.0}\n",
+		},
+		{
+			name:    "should refuse a missing key",
+			content: "services:\n  orders-api: 1.4.2\n",
+			keyPath: []string{"services", "payments-api"},
+			err:     ErrPropagationKeyNotFound,
+		},
+		{
+			name:    "should refuse a value which isn't a scalar",
+			content: "payments-api:\n  image: payments\n",
+			keyPath: []string{"payments-api"},
+			err:     ErrUnsupportedManifestValue,
+		},
+		{
+			name:    "should refuse a value spanning several lines",
+			content: "payments-api: >\n  1.4.2\n",
+			keyPath: []string{"payments-api"},
+			err:     ErrUnsupportedManifestValue,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			content, err := setManifestValue([]byte(test.content), test.keyPath, "2.0.0")
+
+			// Assert
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
+
+func TestValidatePropagationTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		target *PropagationTarget
+		valid  bool
+	}{
+		{name: "should accept no target", valid: true},
+		{
+			name:   "should accept a YAML manifest",
+			target: &PropagationTarget{Path: "https://gitlab.com/company/deploy.git", File: "versions.yaml"},
+			valid:  true,
+		},
+		{name: "should refuse a target without path", target: &PropagationTarget{File: "versions.yaml"}},
+		{name: "should refuse a target without file", target: &PropagationTarget{Path: "/deploy"}},
+		{name: "should refuse a file outside", target: &PropagationTarget{Path: "/deploy", File: "../versions.yaml"}},
+		{name: "should refuse another format", target: &PropagationTarget{Path: "/deploy", File: "versions.toml"}},
+		{
+			name:   "should refuse an empty part of the key",
+			target: &PropagationTarget{Path: "/deploy", File: "versions.yaml", Key: "services..payments"},
+		},
+		{
+			name:   "should refuse an invalid template",
+			target: &PropagationTarget{Path: "/deploy", File: "versions.yaml", Branch: "chore/{{.Name"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := validatePropagationTarget(&ProjectConfig{Name: "payments-api", PropagateTo: test.target})
+
+			// Assert
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidPropagationTarget)
+			}
+		})
+	}
+}
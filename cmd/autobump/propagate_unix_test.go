@@ -0,0 +1,41 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreparePropagation_RefusesAManifestOutsideTheUmbrella(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	umbrellaPath, umbrellaRepo := newRedirectRepo(t, "https://gitlab.com/company/deploy.git")
+	commitFile(t, umbrellaRepo, "README.md", "The versions deployed to production.\n")
+	outsidePath := filepath.Join(t.TempDir(), "versions.yaml")
+	require.NoError(t, os.WriteFile(outsidePath, []byte(umbrellaManifest), 0o600))
+	require.NoError(t, os.Symlink(outsidePath, filepath.Join(umbrellaPath, "versions.yaml")))
+	release := newUmbrellaRelease(umbrellaPath, "payments-api", "1.5.0")
+	release.target.File = "versions.yaml"
+
+	globalConfig := &GlobalConfig{releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)}
+	ctx := newPropagationContext(globalConfig, []propagatedRelease{release})
+	ctx.globalGitConfig = newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n")
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	_, err := preparePropagation(ctx, []propagatedRelease{release})
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidPropagationTarget)
+	require.ErrorIs(t, err, ErrPathOutsideRepository)
+	content, readErr := os.ReadFile(outsidePath)
+	require.NoError(t, readErr)
+	assert.Equal(t, umbrellaManifest, string(content), "the file outside the repository shouldn't be changed")
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidProviderConfig = errors.New("invalid provider configuration")
+
+// ProviderConfig points a remote service to one of its self-hosted instances
+type ProviderConfig struct {
+	// name of the service (e.g. "gitlab")
+	Type string `yaml:"type"`
+	// URL of the instance, along with its relative path when it isn't served at the root
+	BaseURL string `yaml:"base_url"`
+	// headers added to the API requests of the instance (e.g. for an API gateway)
+	APIHeaders map[string]string `yaml:"api_headers"`
+}
+
+// GitHubEnterpriseConfig points to a GitHub Enterprise Server instance
+type GitHubEnterpriseConfig struct {
+	// URL of the instance (e.g. "https://github.mycompany.com")
+	BaseURL string `yaml:"base_url"`
+	// URL of the REST API, "<base_url>/api/v3" by default
+	APIURL string `yaml:"api_url"`
+	// token of the instance, the "github_access_token" being kept for github.com
+	AccessToken string `yaml:"access_token"`
+	// headers added to the API requests of the instance (e.g. for an API gateway)
+	APIHeaders map[string]string `yaml:"api_headers"`
+}
+
+// selfHostedProvider is a self-hosted instance whose URLs are recognized as those of its service
+type selfHostedProvider struct {
+	serviceType ServiceType
+	baseURL     *url.URL
+}
+
+var (
+	selfHostedProvidersMutex sync.RWMutex
+	selfHostedProviders      []selfHostedProvider
+)
+
+// loadProviders validates the self-hosted instances of the configuration and registers them,
+// so their URLs are matched, authenticated and called like those of the public service
+func loadProviders(globalConfig *GlobalConfig) error {
+	if err := loadGitHubEnterprise(globalConfig.GitHubEnterprise); err != nil {
+		return err
+	}
+
+	for index, provider := range globalConfig.Providers {
+		serviceType, err := parseServiceType(provider.Type)
+		if err != nil {
+			return fmt.Errorf("%w: providers[%d]: %w", ErrInvalidProviderConfig, index, err)
+		}
+		if !getServiceInfo(serviceType).selfHosted {
+			return fmt.Errorf(
+				"%w: providers[%d]: %s can't be configured in providers", ErrInvalidProviderConfig, index, serviceType,
+			)
+		}
+
+		baseURL, err := parseProviderURL(provider.BaseURL)
+		if err != nil {
+			return fmt.Errorf("%w: providers[%d]: base_url %w", ErrInvalidProviderConfig, index, err)
+		}
+		registerSelfHostedProvider(serviceType, baseURL)
+	}
+	return nil
+}
+
+// loadGitHubEnterprise validates the GitHub Enterprise Server instance and registers it, when there is one
+func loadGitHubEnterprise(gitHubEnterprise GitHubEnterpriseConfig) error {
+	if gitHubEnterprise.BaseURL == "" {
+		if gitHubEnterprise.APIURL != "" || gitHubEnterprise.AccessToken != "" || len(gitHubEnterprise.APIHeaders) > 0 {
+			return fmt.Errorf("%w: github_enterprise.base_url is missing", ErrInvalidProviderConfig)
+		}
+		return nil
+	}
+
+	baseURL, err := parseProviderURL(gitHubEnterprise.BaseURL)
+	if err != nil {
+		return fmt.Errorf("%w: github_enterprise.base_url %w", ErrInvalidProviderConfig, err)
+	}
+	if gitHubEnterprise.APIURL != "" {
+		if _, err = parseProviderURL(gitHubEnterprise.APIURL); err != nil {
+			return fmt.Errorf("%w: github_enterprise.api_url %w", ErrInvalidProviderConfig, err)
+		}
+	}
+	registerSelfHostedProvider(GITHUB, baseURL)
+	return nil
+}
+
+// parseProviderURL parses the URL of an instance or of its API, without its trailing slash
+func parseProviderURL(rawURL string) (*url.URL, error) {
+	parsedURL, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(rawURL), "/"))
+	if err != nil || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") || parsedURL.Hostname() == "" {
+		return nil, fmt.Errorf("must be an HTTP(S) URL, got %q", rawURL)
+	}
+	return parsedURL, nil
+}
+
+// registerSelfHostedProvider records the instance, once per base URL
+func registerSelfHostedProvider(serviceType ServiceType, baseURL *url.URL) {
+	selfHostedProvidersMutex.Lock()
+	defer selfHostedProvidersMutex.Unlock()
+	for _, provider := range selfHostedProviders {
+		if provider.baseURL.String() == baseURL.String() {
+			return
+		}
+	}
+	selfHostedProviders = append(selfHostedProviders, selfHostedProvider{serviceType: serviceType, baseURL: baseURL})
+}
+
+// findSelfHostedProvider returns the self-hosted instance hosting the repository, matched by its host name.
+// The SSH URLs don't carry the relative path of the instance, so it is only compared for the HTTP(S) URLs.
+func findSelfHostedProvider(remoteURL string) (selfHostedProvider, bool) {
+	webURL, err := url.Parse(getRepositoryWebURL(remoteURL))
+	if err != nil || webURL.Hostname() == "" {
+		return selfHostedProvider{}, false
+	}
+
+	selfHostedProvidersMutex.RLock()
+	defer selfHostedProvidersMutex.RUnlock()
+	for _, provider := range selfHostedProviders {
+		if !strings.EqualFold(webURL.Hostname(), provider.baseURL.Hostname()) {
+			continue
+		}
+		if !isSSHURL(remoteURL) && !strings.HasPrefix(webURL.Path, provider.baseURL.Path+"/") {
+			continue
+		}
+		return provider, true
+	}
+	return selfHostedProvider{}, false
+}
+
+// getProviderBaseURL returns the base URL of the self-hosted instance hosting the repository,
+// empty for the repositories of the public services
+func getProviderBaseURL(remoteURL string) string {
+	provider, found := findSelfHostedProvider(remoteURL)
+	if !found {
+		return ""
+	}
+	return provider.baseURL.String()
+}
+
+// trimProviderBasePath removes the relative path of the self-hosted instance from the path of the repository,
+// e.g. "gitlab/group/project" is "group/project" on the instance served at "https://example.com/gitlab"
+func trimProviderBasePath(remoteURL string, repositoryPath string) string {
+	provider, found := findSelfHostedProvider(remoteURL)
+	if !found || provider.baseURL.Path == "" {
+		return repositoryPath
+	}
+	basePath := strings.Trim(provider.baseURL.Path, "/") + "/"
+	return strings.TrimPrefix(repositoryPath, basePath)
+}
+
+// isGitHubEnterpriseURL tells whether the repository is hosted on the configured GitHub Enterprise Server instance
+func isGitHubEnterpriseURL(globalConfig *GlobalConfig, remoteURL string) bool {
+	provider, found := findSelfHostedProvider(remoteURL)
+	if !found || provider.serviceType != GITHUB {
+		return false
+	}
+	baseURL, err := parseProviderURL(globalConfig.GitHubEnterprise.BaseURL)
+	return err == nil && strings.EqualFold(baseURL.Hostname(), provider.baseURL.Hostname())
+}
+
+// getGitHubAPIURL returns the URL of the REST API of the GitHub instance hosting the repository
+func getGitHubAPIURL(globalConfig *GlobalConfig, remoteURL string) string {
+	if !isGitHubEnterpriseURL(globalConfig, remoteURL) {
+		return "https://api.github.com"
+	}
+	gitHubEnterprise := globalConfig.GitHubEnterprise
+	return strings.TrimSuffix(
+		firstNonEmpty(gitHubEnterprise.APIURL, strings.TrimSuffix(gitHubEnterprise.BaseURL, "/")+"/api/v3"), "/",
+	)
+}
+
+// getGitHubAccessToken returns the token of the GitHub instance hosting the repository
+func getGitHubAccessToken(globalConfig *GlobalConfig, remoteURL string) string {
+	if isGitHubEnterpriseURL(globalConfig, remoteURL) {
+		return globalConfig.GitHubEnterprise.AccessToken
+	}
+	return globalConfig.GitHubAccessToken
+}
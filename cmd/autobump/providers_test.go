@@ -0,0 +1,267 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRemoteRepo creates an in-memory repository whose "origin" remote is the URL
+func newRemoteRepo(t *testing.T, remoteURL string) *git.Repository {
+	t.Helper()
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteURL}})
+	require.NoError(t, err)
+	return repo
+}
+
+func TestLoadProviders_MatchesTheSelfHostedInstances(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	require.NoError(t, loadProviders(&GlobalConfig{Providers: []ProviderConfig{
+		{Type: "gitlab", BaseURL: "https://gitlab.matching.test/"},
+		{Type: "GitLab", BaseURL: "https://tools.matching.test/gitlab"},
+	}}))
+
+	tests := []struct {
+		name      string
+		remoteURL string
+		expected  ServiceType
+	}{
+		{name: "should match the HTTPS URL", remoteURL: "https://gitlab.matching.test/group/api.git", expected: GITLAB},
+		{name: "should match the SSH URL", remoteURL: "git@gitlab.matching.test:group/api.git", expected: GITLAB},
+		{
+			name:      "should match the HTTPS URL under the relative path",
+			remoteURL: "https://tools.matching.test/gitlab/group/api.git",
+			expected:  GITLAB,
+		},
+		{
+			name:      "should not match the HTTPS URL outside of the relative path",
+			remoteURL: "https://tools.matching.test/gitea/group/api.git",
+			expected:  UNKNOWN,
+		},
+		{name: "should not match another host", remoteURL: "https://git.matching.test/group/api.git", expected: UNKNOWN},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			serviceType := getServiceTypeByURL(test.remoteURL)
+
+			// Assert
+			assert.Equal(t, test.expected, serviceType)
+		})
+	}
+}
+
+func TestLoadProviders_InvalidProviders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider ProviderConfig
+	}{
+		{
+			name:     "should refuse an unknown service",
+			provider: ProviderConfig{Type: "gitea", BaseURL: "https://git.invalid.test"},
+		},
+		{
+			name:     "should refuse a service without self-hosted instances",
+			provider: ProviderConfig{Type: "github", BaseURL: "https://github.invalid.test"},
+		},
+		{name: "should refuse a URL without host", provider: ProviderConfig{Type: "gitlab", BaseURL: "gitlab.invalid.test"}},
+		{
+			name:     "should refuse a URL that isn't HTTP(S)",
+			provider: ProviderConfig{Type: "gitlab", BaseURL: "ssh://gitlab.invalid.test"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := loadProviders(&GlobalConfig{Providers: []ProviderConfig{test.provider}})
+
+			// Assert
+			require.ErrorIs(t, err, ErrInvalidProviderConfig)
+		})
+	}
+}
+
+func TestGetRemoteRepoFullProjectName_SelfHostedRelativePath(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	require.NoError(t, loadProviders(&GlobalConfig{Providers: []ProviderConfig{
+		{Type: "gitlab", BaseURL: "https://tools.project.test/gitlab"},
+	}}))
+	repo := newRemoteRepo(t, "https://tools.project.test/gitlab/group/subgroup/api.git")
+
+	// Act
+	projectName, err := getRemoteRepoFullProjectName(repo)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "group/subgroup/api", projectName)
+}
+
+func TestNewGitLabClient_UsesTheSelfHostedInstance(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	require.NoError(t, loadProviders(&GlobalConfig{Providers: []ProviderConfig{
+		{Type: "gitlab", BaseURL: "https://gitlab.client.test"},
+	}}))
+
+	tests := []struct {
+		name      string
+		remoteURL string
+		expected  string
+	}{
+		{
+			name:      "should call the API of the self-hosted instance",
+			remoteURL: "git@gitlab.client.test:group/api.git",
+			expected:  "https://gitlab.client.test/api/v4/",
+		},
+		{
+			name:      "should call the API of gitlab.com",
+			remoteURL: "https://gitlab.com/group/api.git",
+			expected:  "https://gitlab.com/api/v4/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			client, err := newGitLabClient(&GlobalConfig{}, newRemoteRepo(t, test.remoteURL), "glpat-token")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, client.BaseURL().String())
+		})
+	}
+}
+
+func TestLoadProviders_GitHubEnterprise(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{
+		GitHubAccessToken: "github-token",
+		GitHubEnterprise: GitHubEnterpriseConfig{
+			BaseURL:     "https://github.enterprise.test/",
+			AccessToken: "enterprise-token",
+		},
+	}
+	require.NoError(t, loadProviders(globalConfig))
+
+	tests := []struct {
+		name            string
+		remoteURL       string
+		expectedService ServiceType
+		expectedAPIURL  string
+		expectedToken   string
+	}{
+		{
+			name:            "should use the API and the token of the instance",
+			remoteURL:       "https://github.enterprise.test/acme/api.git",
+			expectedService: GITHUB,
+			expectedAPIURL:  "https://github.enterprise.test/api/v3",
+			expectedToken:   "enterprise-token",
+		},
+		{
+			name:            "should use the API and the token of github.com",
+			remoteURL:       "https://github.com/acme/api.git",
+			expectedService: GITHUB,
+			expectedAPIURL:  "https://api.github.com",
+			expectedToken:   "github-token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			serviceType := getServiceTypeByURL(test.remoteURL)
+			apiURL := getGitHubAPIURL(globalConfig, test.remoteURL)
+			authMethods, err := getAuthMethods(test.remoteURL, "AutoBump", globalConfig, &ProjectConfig{}, standardLogEntry())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedService, serviceType)
+			assert.Equal(t, test.expectedAPIURL, apiURL)
+			assert.Equal(t, []transport.AuthMethod{
+				&http.BasicAuth{Username: "x-access-token", Password: test.expectedToken},
+			}, authMethods)
+		})
+	}
+}
+
+func TestGetGitHubAPIURL_ConfiguredAPIURL(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{GitHubEnterprise: GitHubEnterpriseConfig{
+		BaseURL: "https://github.api.test",
+		APIURL:  "https://api.github.api.test/",
+	}}
+	require.NoError(t, loadProviders(globalConfig))
+
+	// Act
+	apiURL := getGitHubAPIURL(globalConfig, "git@github.api.test:acme/api.git")
+
+	// Assert
+	assert.Equal(t, "https://api.github.api.test", apiURL)
+}
+
+func TestLoadProviders_InvalidGitHubEnterprise(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		gitHubEnterprise GitHubEnterpriseConfig
+	}{
+		{
+			name:             "should refuse a token without base URL",
+			gitHubEnterprise: GitHubEnterpriseConfig{AccessToken: "enterprise-token"},
+		},
+		{
+			name:             "should refuse a base URL that isn't HTTP(S)",
+			gitHubEnterprise: GitHubEnterpriseConfig{BaseURL: "github.invalid.test"},
+		},
+		{
+			name: "should refuse an API URL that isn't HTTP(S)",
+			gitHubEnterprise: GitHubEnterpriseConfig{
+				BaseURL: "https://github.invalid.test",
+				APIURL:  "github.invalid.test/api/v3",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := loadProviders(&GlobalConfig{GitHubEnterprise: test.gitHubEnterprise})
+
+			// Assert
+			require.ErrorIs(t, err, ErrInvalidProviderConfig)
+		})
+	}
+}
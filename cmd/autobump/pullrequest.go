@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// pullRequestStatusPushedNoPR is the status of the projects whose bump branch was pushed,
+// but whose pull request couldn't be created by the token
+const pullRequestStatusPushedNoPR = "pushed-no-pr"
+
+var ErrPullRequestForbidden = errors.New("the token is not allowed to create pull requests")
+
+// classifyPullRequestError marks the errors of the provider API forbidding the pull request creation
+func classifyPullRequestError(statusCode int, err error) error {
+	if statusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w", ErrPullRequestForbidden, err)
+	}
+	return err
+}
+
+// degradePullRequest keeps the pushed branch of the project when its pull request is forbidden,
+// logging the URL to open the pull request by hand
+func degradePullRequest(ctx *RepoContext, branchName string, err error) {
+	ctx.status = pullRequestStatusPushedNoPR
+	if remoteURL, remoteErr := getRemoteRepoURL(ctx.repo); remoteErr == nil {
+		ctx.compareURL = buildBranchCompareURL(remoteURL, getTargetBranch(ctx.projectConfig), branchName)
+	}
+
+	ctx.logger().Warnf("Branch %s was pushed, but the pull request couldn't be created: %v", branchName, err)
+	if ctx.compareURL != "" {
+		ctx.logger().Warnf("Open the pull request at %s", ctx.compareURL)
+	}
+}
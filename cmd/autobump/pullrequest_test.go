@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyPullRequestError_Forbidden(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	apiErr := errors.New("403 Forbidden")
+
+	// Act
+	err := classifyPullRequestError(http.StatusForbidden, apiErr)
+
+	// Assert
+	require.ErrorIs(t, err, ErrPullRequestForbidden)
+	require.ErrorIs(t, err, apiErr)
+}
+
+func TestClassifyPullRequestError_OtherStatus(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	apiErr := errors.New("404 Not Found")
+
+	// Act
+	notFoundErr := classifyPullRequestError(http.StatusNotFound, apiErr)
+	noResponseErr := classifyPullRequestError(0, apiErr)
+
+	// Assert
+	require.NotErrorIs(t, notFoundErr, ErrPullRequestForbidden)
+	require.NotErrorIs(t, noResponseErr, ErrPullRequestForbidden)
+}
+
+func TestBuildBranchCompareURL_Forges(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	remoteURLs := map[string]string{
+		"https://github.com/acme/web.git": "https://github.com/acme/web/compare/main...chore%2Fbump-1.2.0?expand=1",
+		"git@gitlab.com:finance/payments-api.git": "https://gitlab.com/finance/payments-api/-/merge_requests/new" +
+			"?merge_request%5Bsource_branch%5D=chore%2Fbump-1.2.0&merge_request%5Btarget_branch%5D=main",
+		"https://bitbucket.org/acme/api.git": "https://bitbucket.org/acme/api/pull-requests/new" +
+			"?source=chore%2Fbump-1.2.0&dest=main",
+		"git@ssh.dev.azure.com:v3/org/project/repo": "https://dev.azure.com/org/project/_git/repo/pullrequestcreate" +
+			"?sourceRef=chore%2Fbump-1.2.0&targetRef=main",
+	}
+
+	for remoteURL, expected := range remoteURLs {
+		// Act
+		compareURL := buildBranchCompareURL(remoteURL, "main", "chore/bump-1.2.0")
+
+		// Assert
+		assert.Equal(t, expected, compareURL, remoteURL)
+	}
+}
+
+func TestDegradePullRequest_RecordsCompareURL(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"https://gitlab.com/finance/payments-api.git"},
+	})
+	require.NoError(t, err)
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{},
+		projectConfig: &ProjectConfig{},
+		repo:          repo,
+	}
+
+	// Act
+	degradePullRequest(ctx, "chore/bump", ErrPullRequestForbidden)
+
+	// Assert
+	assert.Equal(t, pullRequestStatusPushedNoPR, ctx.status)
+	assert.Equal(t,
+		"https://gitlab.com/finance/payments-api/-/merge_requests/new"+
+			"?merge_request%5Bsource_branch%5D=chore%2Fbump&merge_request%5Btarget_branch%5D="+
+			getTargetBranch(ctx.projectConfig),
+		ctx.compareURL,
+	)
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrInvalidChangelogRedirect = errors.New("invalid changelog_redirect")
+	ErrPartialRedirectRelease   = errors.New("the release was only published in one of the repositories")
+)
+
+// ChangelogRedirect points to the repository keeping the CHANGELOG of the project (e.g. a docs repository),
+// while the project only keeps its version files
+type ChangelogRedirect struct {
+	Path      string `yaml:"path"`
+	Changelog string `yaml:"changelog"`
+}
+
+// getChangelog returns the path of the CHANGELOG in the repository
+func (r *ChangelogRedirect) getChangelog() string {
+	if r.Changelog == "" {
+		return "CHANGELOG.md"
+	}
+	return filepath.FromSlash(r.Changelog)
+}
+
+// validateChangelogRedirect checks the redirect of the project, whose CHANGELOG must be inside the repository
+func validateChangelogRedirect(projectConfig *ProjectConfig) error {
+	redirect := projectConfig.ChangelogRedirect
+	if redirect == nil {
+		return nil
+	}
+	if redirect.Path == "" {
+		return fmt.Errorf("%w: the path of the repository is missing", ErrInvalidChangelogRedirect)
+	}
+	if !filepath.IsLocal(redirect.getChangelog()) {
+		return fmt.Errorf("%w: %s is outside the repository", ErrInvalidChangelogRedirect, redirect.Changelog)
+	}
+	if len(projectConfig.VersionStreams) > 0 {
+		return fmt.Errorf("%w: the version streams aren't supported", ErrInvalidChangelogRedirect)
+	}
+	return nil
+}
+
+// newRedirectContext creates the context of the repository keeping the CHANGELOG of the project,
+// which is released with the same settings and credentials as the project
+func newRedirectContext(ctx *RepoContext) *RepoContext {
+	projectConfig := ctx.projectConfig
+	return &RepoContext{
+		globalConfig: ctx.globalConfig,
+		projectConfig: &ProjectConfig{
+			Path:                  projectConfig.ChangelogRedirect.Path,
+			Name:                  projectConfig.Name + " (CHANGELOG)",
+			ProjectAccessToken:    projectConfig.ProjectAccessToken,
+			BaseRef:               projectConfig.BaseRef,
+			VersioningScheme:      projectConfig.VersioningScheme,
+			CalVerFormat:          projectConfig.CalVerFormat,
+			ChangelogTemplatePath: projectConfig.ChangelogTemplatePath,
+			SignCommits:           projectConfig.SignCommits,
+			changelogTemplate:     projectConfig.changelogTemplate,
+		},
+		globalGitConfig: ctx.globalGitConfig,
+		timer:           ctx.timer,
+		logEntry:        ctx.logEntry,
+	}
+}
+
+// processRedirectedRepo releases a project whose CHANGELOG is kept in another repository:
+// the CHANGELOG is released there and the version files in the project, with a bump branch and pull request
+// in each repository sharing the same version and linking each other
+func processRedirectedRepo(ctx *RepoContext) error {
+	changelogCtx := newRedirectContext(ctx)
+	ctx.logger().Infof(
+		"Project %s keeps its CHANGELOG in %s", ctx.projectConfig.Name, ctx.projectConfig.ChangelogRedirect.Path,
+	)
+
+	stopTimer := ctx.timer.start(phaseClone)
+	changelogDir, err := cloneRepoIfNeeded(changelogCtx)
+	stopTimer()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(changelogDir)
+
+	err = setupRepo(changelogCtx)
+	if err != nil {
+		return err
+	}
+	changelogPath := filepath.Join(changelogCtx.projectConfig.Path, ctx.projectConfig.ChangelogRedirect.getChangelog())
+
+	// the CHANGELOG of the other repository decides whether the project is bumped
+	bumpNeeded, err := shouldBumpProject(changelogCtx, changelogPath)
+	if err != nil || !bumpNeeded {
+		return err
+	}
+
+	stopTimer = ctx.timer.start(phaseClone)
+	projectDir, err := cloneRepoIfNeeded(ctx)
+	stopTimer()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(projectDir)
+
+	err = setupRepo(ctx)
+	if err != nil {
+		return err
+	}
+	err = ensureProjectLanguage(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateVersionFiles(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger())
+	if err != nil {
+		return err
+	}
+
+	branchName, err := prepareRedirectedBump(ctx, changelogCtx, changelogPath)
+	if err != nil {
+		return err
+	}
+	ctx.branchName = branchName
+	err = publishRedirectedBump(ctx, changelogCtx, branchName)
+	if err != nil {
+		return err
+	}
+
+	recordProjectResult(ctx)
+	ctx.logger().Infof("Successfully processed project '%s'", ctx.projectConfig.Name)
+	return nil
+}
+
+// prepareRedirectedBump creates the bump branch and commit of both repositories, without pushing them:
+// the CHANGELOG is released in its repository and the version files of the project receive the same version
+func prepareRedirectedBump(ctx *RepoContext, changelogCtx *RepoContext, changelogPath string) (string, error) {
+	branchName, err := createBumpBranch(changelogCtx, changelogPath)
+	if err != nil {
+		return "", err
+	}
+
+	stopTimer := ctx.timer.start(phaseChangelog)
+	version, err := updateChangelogFile(changelogCtx, changelogPath)
+	stopTimer()
+	if err != nil {
+		return "", err
+	}
+	changelogCtx.projectConfig.NewVersion = versionString(version)
+	setReleaseNotes(changelogCtx)
+	err = addFileToWorktree(changelogCtx, changelogPath)
+	if err != nil {
+		return "", err
+	}
+	err = addChangelogArchiveToWorktree(changelogCtx)
+	if err != nil {
+		return "", err
+	}
+
+	// the project is released with the version and the changes of the CHANGELOG
+	ctx.unreleased = changelogCtx.unreleased
+	ctx.projectConfig.NewVersion = changelogCtx.projectConfig.NewVersion
+	ctx.state.releaseNotes = changelogCtx.state.releaseNotes
+	ctx.state.changelogExcerpt = changelogCtx.state.changelogExcerpt
+	ctx.state.runNotes = changelogCtx.state.runNotes
+
+	branchExists, err := checkBranchExists(ctx.repo, branchName)
+	if err != nil {
+		return "", err
+	}
+	if branchExists {
+		return "", fmt.Errorf("%w: %s", ErrBranchExists, branchName)
+	}
+	baseHash := ctx.head.Hash()
+	if ctx.baseCommit != nil {
+		baseHash = ctx.baseCommit.Hash
+	}
+	err = createAndSwitchBranch(ctx.repo, ctx.worktree, branchName, baseHash, ctx.logger())
+	if err != nil {
+		return "", err
+	}
+
+	ctx.logger().Infof("Updating version to %s", ctx.projectConfig.NewVersion)
+	stopTimer = ctx.timer.start(phaseVersion)
+	err = updateVersion(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger())
+	stopTimer()
+	if err != nil {
+		return "", err
+	}
+	versionFiles, err := getVersionFiles(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger())
+	if err != nil {
+		return "", err
+	}
+	for _, versionFile := range versionFiles {
+		err = addFileToWorktree(ctx, versionFile.Path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	stopTimer = ctx.timer.start(phaseCommit)
+	defer stopTimer()
+	_, err = commitChangesWithGPG(changelogCtx)
+	if err != nil {
+		return "", err
+	}
+	_, err = commitChangesWithGPG(ctx)
+	if err != nil {
+		return "", err
+	}
+	return branchName, nil
+}
+
+// setRedirectNotes writes in the description of each pull request where the other half of the release is:
+// the pull request of the CHANGELOG when it was created, or the bump branch otherwise
+func setRedirectNotes(ctx *RepoContext, changelogCtx *RepoContext, branchName string) {
+	changelogLink := getRedirectLink(changelogCtx, branchName)
+	ctx.state.redirectNotes = fmt.Sprintf(
+		"The CHANGELOG of this release is kept in another repository, it is released by %s.", changelogLink,
+	)
+	changelogCtx.state.redirectNotes = fmt.Sprintf(
+		"This release of %s is completed by %s, updating its version files.",
+		ctx.projectConfig.Name, getRedirectLink(ctx, branchName),
+	)
+}
+
+// getRedirectLink returns the pull request of the repository, or where to open it when it wasn't created yet
+func getRedirectLink(ctx *RepoContext, branchName string) string {
+	if ctx.pullRequestURL != "" {
+		return ctx.pullRequestURL
+	}
+
+	remoteURL, err := getRemoteRepoURL(ctx.repo)
+	if err != nil {
+		return fmt.Sprintf("the branch %s", branchName)
+	}
+	if compareURL := buildBranchCompareURL(remoteURL, getTargetBranch(ctx.projectConfig), branchName); compareURL != "" {
+		return compareURL
+	}
+	return fmt.Sprintf("the branch %s of %s", branchName, getRepositoryWebURL(remoteURL))
+}
+
+// publishRedirectedBump pushes the bump branches and creates the pull requests, the CHANGELOG first.
+// Once the CHANGELOG is published, the failures aren't rolled back, but explain what is left to do by hand.
+func publishRedirectedBump(ctx *RepoContext, changelogCtx *RepoContext, branchName string) error {
+	stopTimer := ctx.timer.start(phasePush)
+	err := pushChanges(changelogCtx, branchName)
+	stopTimer()
+	if err != nil {
+		return err
+	}
+
+	remediation := func(step string, err error) error {
+		return fmt.Errorf(
+			"%w: %s of %s failed, while the CHANGELOG was released by %s: bump the version files of %s to %s "+
+				"by hand (branch %s), or close the pull request of the CHANGELOG and delete its branch: %w",
+			ErrPartialRedirectRelease,
+			step,
+			ctx.projectConfig.Name,
+			getRedirectLink(changelogCtx, branchName),
+			ctx.projectConfig.Name,
+			ctx.projectConfig.NewVersion,
+			branchName,
+			err,
+		)
+	}
+
+	stopTimer = ctx.timer.start(phasePush)
+	err = pushChanges(ctx, branchName)
+	stopTimer()
+	if err != nil {
+		return remediation("pushing the version files", err)
+	}
+
+	stopTimer = ctx.timer.start(phasePR)
+	defer stopTimer()
+	setRedirectNotes(ctx, changelogCtx, branchName)
+	err = createAndCheckoutPullRequest(changelogCtx, branchName)
+	if err != nil {
+		return remediation("creating the pull request of the CHANGELOG", err)
+	}
+
+	// the pull request of the project links the one of the CHANGELOG, now that it exists
+	setRedirectNotes(ctx, changelogCtx, branchName)
+	err = createAndCheckoutPullRequest(ctx, branchName)
+	if err != nil {
+		return remediation("creating the pull request", err)
+	}
+	return nil
+}
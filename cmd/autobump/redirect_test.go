@@ -0,0 +1,195 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRedirectRepo creates a repository whose origin is the given remote
+func newRedirectRepo(t *testing.T, remoteURL string) (string, *git.Repository) {
+	t.Helper()
+
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteURL}})
+	require.NoError(t, err)
+	return repoPath, repo
+}
+
+// newRedirectFixture creates a service whose CHANGELOG is kept in a docs repository, with the context of both
+func newRedirectFixture(t *testing.T) (*RepoContext, *RepoContext, string) {
+	t.Helper()
+
+	docsPath, docsRepo := newRedirectRepo(t, "https://gitlab.com/company/docs.git")
+	require.NoError(t, os.MkdirAll(filepath.Join(docsPath, "services", "payments"), 0o755))
+	commitFile(t, docsRepo, filepath.Join("services", "payments", "CHANGELOG.md"), changelogOriginal+"\n")
+
+	servicePath, serviceRepo := newRedirectRepo(t, "https://gitlab.com/company/payments.git")
+	commitFile(t, serviceRepo, "CHANGELOG.md", "The CHANGELOG is kept in the docs repository.\n")
+	commitFile(t, serviceRepo, "version.txt", "version=1.0.1\n")
+
+	globalConfig := &GlobalConfig{
+		LanguagesConfig: map[string]LanguageConfig{
+			"text": {VersionFiles: []VersionFile{{Path: "version.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}}}},
+		},
+		// the commits are authored with a fixed date, since the global Git config isn't read
+		releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+	}
+	ctx := &RepoContext{
+		globalConfig: globalConfig,
+		projectConfig: &ProjectConfig{
+			Path:              servicePath,
+			Name:              "payments",
+			Language:          "text",
+			ChangelogRedirect: &ChangelogRedirect{Path: docsPath, Changelog: "services/payments/CHANGELOG.md"},
+		},
+		globalGitConfig: newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+	}
+	changelogCtx := newRedirectContext(ctx)
+	require.NoError(t, setupRepo(ctx))
+	require.NoError(t, setupRepo(changelogCtx))
+
+	changelogPath := filepath.Join(docsPath, ctx.projectConfig.ChangelogRedirect.getChangelog())
+	bumpNeeded, err := shouldBumpProject(changelogCtx, changelogPath)
+	require.NoError(t, err)
+	require.True(t, bumpNeeded)
+	return ctx, changelogCtx, changelogPath
+}
+
+// readBranchFile reads a file from the last commit of a branch
+func readBranchFile(t *testing.T, repo *git.Repository, branchName string, name string) string {
+	t.Helper()
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	file, err := commit.File(name)
+	require.NoError(t, err)
+	content, err := file.Contents()
+	require.NoError(t, err)
+	return content
+}
+
+func TestPrepareRedirectedBump_SharesTheVersion(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, changelogCtx, changelogPath := newRedirectFixture(t)
+
+	// Act
+	branchName, err := prepareRedirectedBump(ctx, changelogCtx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "chore/bump-1.1.0", branchName)
+	assert.Equal(t, "1.1.0", ctx.projectConfig.NewVersion)
+	assert.Equal(t, "1.1.0", changelogCtx.projectConfig.NewVersion)
+
+	changelog := readBranchFile(t, changelogCtx.repo, branchName, "services/payments/CHANGELOG.md")
+	assert.Contains(t, changelog, "## [1.1.0] - 2024-06-08")
+	assert.Equal(t, "version=1.1.0\n", readBranchFile(t, ctx.repo, branchName, "version.txt"))
+	assert.Equal(
+		t,
+		"The CHANGELOG is kept in the docs repository.\n",
+		readBranchFile(t, ctx.repo, branchName, "CHANGELOG.md"),
+		"the CHANGELOG of the project is only a pointer",
+	)
+}
+
+func TestSetRedirectNotes_CrossLinksThePullRequests(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, changelogCtx, changelogPath := newRedirectFixture(t)
+	branchName, err := prepareRedirectedBump(ctx, changelogCtx, changelogPath)
+	require.NoError(t, err)
+
+	// Act
+	setRedirectNotes(ctx, changelogCtx, branchName)
+	changelogPreview, changelogErr := buildPullRequestPreview(
+		changelogCtx.globalConfig, changelogCtx.projectConfig, &changelogCtx.state, GITLAB,
+		"https://gitlab.com/company/docs.git", branchName, "main", "1.1.0",
+		standardLogEntry(),
+	)
+	changelogCtx.pullRequestURL = "https://gitlab.com/company/docs/-/merge_requests/7"
+	setRedirectNotes(ctx, changelogCtx, branchName)
+	projectPreview, projectErr := buildPullRequestPreview(
+		ctx.globalConfig, ctx.projectConfig, &ctx.state, GITLAB,
+		"https://gitlab.com/company/payments.git", branchName, "main", "1.1.0",
+		standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, changelogErr)
+	require.NoError(t, projectErr)
+	assert.Equal(t, projectPreview.Title, changelogPreview.Title, "both pull requests release the same version")
+	assert.Equal(
+		t,
+		"This release of payments is completed by https://gitlab.com/company/payments/-/merge_requests/new?"+
+			"merge_request%5Bsource_branch%5D=chore%2Fbump-1.1.0&merge_request%5Btarget_branch%5D=main, "+
+			"updating its version files.\n\n"+
+			"These are the changes of this release:\n\n### Added\n\n- Another new feature.",
+		changelogPreview.Description,
+	)
+	assert.Equal(
+		t,
+		"The CHANGELOG of this release is kept in another repository, it is released by "+
+			"https://gitlab.com/company/docs/-/merge_requests/7.\n\n"+
+			"These are the changes of this release:\n\n### Added\n\n- Another new feature.",
+		projectPreview.Description,
+	)
+	assert.Contains(t, string(projectPreview.Payload), "merge_requests/7")
+}
+
+func TestValidateChangelogRedirect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		project  ProjectConfig
+		expected error
+	}{
+		{"without redirect", ProjectConfig{}, nil},
+		{"valid", ProjectConfig{ChangelogRedirect: &ChangelogRedirect{Path: "https://gitlab.com/company/docs.git"}}, nil},
+		{"missing path", ProjectConfig{ChangelogRedirect: &ChangelogRedirect{}}, ErrInvalidChangelogRedirect},
+		{
+			"outside the repository",
+			ProjectConfig{ChangelogRedirect: &ChangelogRedirect{Path: "docs", Changelog: "../CHANGELOG.md"}},
+			ErrInvalidChangelogRedirect,
+		},
+		{
+			"with version streams",
+			ProjectConfig{
+				ChangelogRedirect: &ChangelogRedirect{Path: "docs"},
+				VersionStreams:    []VersionStream{{Name: "lts"}},
+			},
+			ErrInvalidChangelogRedirect,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := validateChangelogRedirect(&test.project)
+
+			// Assert
+			if test.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.expected)
+		})
+	}
+}
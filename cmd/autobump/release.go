@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/xanzy/go-gitlab"
+)
+
+var (
+	ErrReleaseNotSupported   = errors.New("the releases aren't supported")
+	ErrFailedToCreateRelease = errors.New("failed to create the release")
+)
+
+// isReleaseCreated tells whether the tagged releases of the project are published on its remote service,
+// the project setting overriding the global one
+func isReleaseCreated(globalConfig *GlobalConfig, projectConfig *ProjectConfig) bool {
+	if projectConfig.CreateRelease != nil {
+		return *projectConfig.CreateRelease
+	}
+	return globalConfig.CreateRelease
+}
+
+// getReleaseNotes returns the entries of the release in the CHANGELOG, empty when it has none
+func getReleaseNotes(lines []string, version *semver.Version) string {
+	_, section := getReleaseSection(lines, version)
+	return strings.TrimSpace(strings.Join(section, "\n"))
+}
+
+// checkReleaseSupport tells why the releases of the service can't be published, nil when they can
+func checkReleaseSupport(serviceType ServiceType) error {
+	service := getServiceInfo(serviceType)
+	switch {
+	case service.capabilities.CreateRelease:
+		return nil
+	case serviceType == UNKNOWN:
+		return fmt.Errorf("%w: the remote service isn't recognized", ErrReleaseNotSupported)
+	default:
+		return fmt.Errorf("%w: %s is recognized but not yet supported for releases", ErrReleaseNotSupported, service.name)
+	}
+}
+
+// runRelease publishes the latest release of the CHANGELOG of the project, see releaseHead
+func runRelease(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
+	ctx := &RepoContext{
+		globalConfig:  globalConfig,
+		projectConfig: projectConfig,
+		timer:         newPhaseTimer(standardLogEntry()),
+	}
+
+	var err error
+	ctx.globalGitConfig, err = getGlobalGitConfig()
+	if err != nil {
+		return err
+	}
+	err = setupRepo(ctx)
+	if err != nil {
+		return err
+	}
+	return releaseHead(ctx)
+}
+
+// releaseHead publishes the latest release of the CHANGELOG on the remote service, tagging the HEAD with it first
+// when it isn't tagged yet. The release already published is left as it is.
+func releaseHead(ctx *RepoContext) error {
+	lines, err := readLines(findChangelogPath(ctx.projectConfig, ctx.logger()), getMaxFileSize(ctx.globalConfig))
+	if err != nil {
+		return err
+	}
+	version, err := findLatestVersion(lines)
+	if err != nil {
+		return err
+	}
+
+	tagName := getReleaseTagName(ctx.repo, version, ctx.logger())
+	if _, err = ctx.repo.Tag(tagName); err != nil {
+		err = tagHead(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return publishRelease(ctx, tagName, getReleaseNotes(lines, version))
+}
+
+// publishRelease publishes the release of the tag on the remote service, with the entries of the release as its
+// notes. The services without releases are skipped with a warning, or fail the project with "strict_features".
+func publishRelease(ctx *RepoContext, tagName string, notes string) error {
+	serviceType, err := getRemoteServiceType(ctx.repo)
+	if err != nil {
+		return err
+	}
+	if err = checkReleaseSupport(serviceType); err != nil {
+		if ctx.globalConfig.StrictFeatures {
+			return err
+		}
+		ctx.logger().Warnf("The release %s isn't published: %v", tagName, err)
+		return nil
+	}
+
+	releaseURL, err := createRelease(
+		ctx.globalConfig, ctx.projectConfig, ctx.repo, tagName, notes, serviceType, ctx.logger(),
+	)
+	if err != nil {
+		return err
+	}
+	ctx.logger().Infof("Published the release %s: %s", tagName, releaseURL)
+	return nil
+}
+
+// createRelease creates the release of the tag on the remote service, returning its URL
+func createRelease(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	repo *git.Repository,
+	tagName string,
+	notes string,
+	serviceType ServiceType,
+	logger *log.Entry,
+) (string, error) {
+	switch serviceType { //nolint:exhaustive // unsupported service types are handled by the default case
+	case GITLAB:
+		return createGitLabRelease(globalConfig, projectConfig, repo, tagName, notes, logger)
+	case GITHUB:
+		return createGitHubRelease(globalConfig, projectConfig, repo, tagName, notes, logger)
+	default:
+		return "", checkReleaseSupport(serviceType)
+	}
+}
+
+// createGitLabRelease creates the release of the tag on GitLab, returning its URL.
+// The release already published for the tag is kept as it is.
+func createGitLabRelease(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	repo *git.Repository,
+	tagName string,
+	notes string,
+	logger *log.Entry,
+) (string, error) {
+	logger.Infof("Creating the GitLab release %s", tagName)
+
+	accessToken := firstNonEmpty(projectConfig.ProjectAccessToken, globalConfig.GitLabAccessToken)
+	gitlabClient, err := newGitLabClient(globalConfig, repo, accessToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitLab client: %w", err)
+	}
+	projectName, err := getRemoteRepoFullProjectName(repo)
+	if err != nil {
+		return "", err
+	}
+	metadata, err := getGitLabRepoMetadata(globalConfig, gitlabClient, projectName)
+	if err != nil {
+		return "", err
+	}
+	projectID, err := strconv.Atoi(metadata.ID)
+	if err != nil {
+		return "", fmt.Errorf("invalid GitLab project ID %q: %w", metadata.ID, err)
+	}
+
+	release, response, err := gitlabClient.Releases.CreateRelease(projectID, &gitlab.CreateReleaseOptions{
+		Name:        gitlab.Ptr(tagName),
+		TagName:     gitlab.Ptr(tagName),
+		Description: gitlab.Ptr(notes),
+	})
+	if getGitLabStatusCode(response) == http.StatusConflict {
+		logger.Infof("The release %s is already published", tagName)
+		return getGitLabReleaseURL(repo, tagName), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToCreateRelease, err)
+	}
+	if release.Links.Self != "" {
+		return release.Links.Self, nil
+	}
+	return getGitLabReleaseURL(repo, tagName), nil
+}
+
+// getGitLabReleaseURL returns the web page of the release of the tag
+func getGitLabReleaseURL(repo *git.Repository, tagName string) string {
+	remoteURL, err := getRemoteRepoURL(repo)
+	if err != nil {
+		return ""
+	}
+	return getRepositoryWebURL(remoteURL) + "/-/releases/" + url.PathEscape(tagName)
+}
+
+// gitHubRelease is the part of the GitHub release read by AutoBump
+type gitHubRelease struct {
+	HTMLURL string `json:"html_url"`
+}
+
+// createGitHubRelease creates the release of the tag on GitHub, returning its URL.
+// The release already published for the tag is kept as it is.
+func createGitHubRelease(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	repo *git.Repository,
+	tagName string,
+	notes string,
+	logger *log.Entry,
+) (string, error) {
+	logger.Infof("Creating the GitHub release %s", tagName)
+
+	remoteURL, err := getRemoteRepoURL(repo)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+	client := newAPIClient(globalConfig)
+
+	req, err := buildGitHubReleaseRequest(ctx, globalConfig, projectConfig, remoteURL, tagName, notes)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("POST %s", req.URL)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToCreateRelease, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	releasesURL := getRepositoryWebURL(remoteURL) + "/releases/tag/" + url.PathEscape(tagName)
+	switch {
+	case resp.StatusCode == http.StatusUnprocessableEntity && bytes.Contains(body, []byte("already_exists")):
+		logger.Infof("The release %s is already published", tagName)
+		return releasesURL, nil
+	case resp.StatusCode != http.StatusCreated:
+		return "", fmt.Errorf("%w: %d - %s", ErrFailedToCreateRelease, resp.StatusCode, body)
+	}
+
+	var release gitHubRelease
+	if json.Unmarshal(body, &release) == nil && release.HTMLURL != "" {
+		return release.HTMLURL, nil
+	}
+	return releasesURL, nil
+}
+
+// buildGitHubReleaseRequest builds the request creating the release of the tag with the GitHub REST API
+func buildGitHubReleaseRequest(
+	ctx context.Context,
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	remoteURL string,
+	tagName string,
+	notes string,
+) (*http.Request, error) {
+	repositoryName, err := getGitHubRepositoryName(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadBytes, err := json.Marshal(map[string]string{"tag_name": tagName, "name": tagName, "body": notes})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(
+		"%s/repos/%s/releases", getGitHubAPIURL(globalConfig, remoteURL), repositoryName,
+	), bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setGitHubHeaders(req, globalConfig, projectConfig, remoteURL)
+	return req, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGitHubReleaseRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{GitHubAccessToken: "github-token"}
+	projectConfig := &ProjectConfig{Path: "https://github.com/acme/web.git"}
+
+	// Act
+	req, err := buildGitHubReleaseRequest(
+		context.Background(), globalConfig, projectConfig, "git@github.com:acme/web.git", "v1.0.1", "### Fixed",
+	)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/repos/acme/web/releases", req.URL.String())
+	assert.Equal(t, "Bearer github-token", req.Header.Get("Authorization"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, map[string]string{"tag_name": "v1.0.1", "name": "v1.0.1", "body": "### Fixed"}, payload)
+}
+
+func TestGetReleaseNotes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogTagged, "\n")
+
+	// Act
+	notes := getReleaseNotes(lines, semver.MustParse("1.0.1"))
+
+	// Assert
+	assert.Equal(t, "### Fixed\n\n- Fixed the crash on empty invoices.", notes)
+}
+
+func TestPublishRelease_UnsupportedService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		strictFeatures bool
+		expectedErr    error
+	}{
+		{name: "should skip the release with a warning"},
+		{name: "should fail with strict_features", strictFeatures: true, expectedErr: ErrReleaseNotSupported},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			_, repo := newRedirectRepo(t, "https://bitbucket.org/acme/api.git")
+			ctx := &RepoContext{globalConfig: &GlobalConfig{StrictFeatures: test.strictFeatures}, repo: repo}
+
+			// Act
+			err := publishRelease(ctx, "v1.0.1", "### Fixed")
+
+			// Assert
+			if test.expectedErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseSupport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		serviceType ServiceType
+		supported   bool
+	}{
+		{name: "should support GitHub", serviceType: GITHUB, supported: true},
+		{name: "should support GitLab", serviceType: GITLAB, supported: true},
+		{name: "should not support Bitbucket", serviceType: BITBUCKET},
+		{name: "should not support an unknown service", serviceType: UNKNOWN},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := checkReleaseSupport(test.serviceType)
+
+			// Assert
+			if test.supported {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrReleaseNotSupported)
+			}
+		})
+	}
+}
+
+func TestIsReleaseCreated(t *testing.T) {
+	t.Parallel()
+
+	enabled, disabled := true, false
+	tests := []struct {
+		name          string
+		globalConfig  *GlobalConfig
+		projectConfig *ProjectConfig
+		expected      bool
+	}{
+		{name: "should not publish by default", globalConfig: &GlobalConfig{}, projectConfig: &ProjectConfig{}},
+		{
+			name:          "should follow the global setting",
+			globalConfig:  &GlobalConfig{CreateRelease: true},
+			projectConfig: &ProjectConfig{},
+			expected:      true,
+		},
+		{
+			name:          "should let the project disable the releases",
+			globalConfig:  &GlobalConfig{CreateRelease: true},
+			projectConfig: &ProjectConfig{CreateRelease: &disabled},
+		},
+		{
+			name:          "should let the project enable the releases",
+			globalConfig:  &GlobalConfig{},
+			projectConfig: &ProjectConfig{CreateRelease: &enabled},
+			expected:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			created := isReleaseCreated(test.globalConfig, test.projectConfig)
+
+			// Assert
+			assert.Equal(t, test.expected, created)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// projectStatusAlreadyReleased is the status of the projects whose "Unreleased" entries were released already,
+// usually because someone released them by hand without clearing the "Unreleased" section
+const projectStatusAlreadyReleased = "already-released-content"
+
+// findAlreadyReleasedContent compares the "Unreleased" entries with the entries of the latest release,
+// returning the line of the latest release header when they are the same (or a subset of them, when allowed),
+// or 0 when there is new content to release
+func findAlreadyReleasedContent(
+	lines []string,
+	summary UnreleasedSummary,
+	changelogConfig ChangelogConfig,
+) int {
+	if summary.LatestVersion == nil {
+		return 0
+	}
+
+	unreleasedEntries := make(map[string]bool)
+	for _, entries := range summary.SectionEntries {
+		for _, entry := range entries {
+			unreleasedEntries[normalizeEntryText(entry)] = true
+		}
+	}
+	if len(unreleasedEntries) == 0 {
+		return 0
+	}
+
+	headerLine, releaseSection := getReleaseSection(lines, summary.LatestVersion)
+	if headerLine == 0 {
+		return 0
+	}
+	fixSectionHeadings(releaseSection, getSectionAliases(changelogConfig))
+
+	sections := newChangelogSections(changelogConfig.NonBumpingSections)
+	majorChanges, minorChanges, patchChanges := 0, 0, 0
+	parseUnreleasedIntoSections(
+		releaseSection, sections, nil, nil, nil, &majorChanges, &minorChanges, &patchChanges, changelogConfig.logger(),
+	)
+	releasedEntries := make(map[string]bool)
+	for _, section := range sections {
+		for _, entry := range *section {
+			releasedEntries[normalizeEntryText(entry)] = true
+		}
+	}
+
+	for entry := range unreleasedEntries {
+		if !releasedEntries[entry] {
+			return 0
+		}
+	}
+	if !changelogConfig.SkipIfSubsetOfLastRelease && len(unreleasedEntries) != len(releasedEntries) {
+		return 0
+	}
+	return headerLine
+}
+
+// getReleaseSection returns the position (starting at 1) of the header of the released version and a copy of its
+// lines, up to the next release
+func getReleaseSection(lines []string, version *semver.Version) (int, []string) {
+	for index, line := range lines {
+		if !isVersionHeader(line, version) {
+			continue
+		}
+
+		var section []string
+		for _, sectionLine := range lines[index+1:] {
+			if strings.HasPrefix(strings.TrimSpace(sectionLine), "## ") {
+				break
+			}
+			section = append(section, sectionLine)
+		}
+		return index + 1, section
+	}
+	return 0, nil
+}
+
+// normalizeEntryText converts the entry to the style used to compare the entries,
+// ignoring the bullet, the Conventional Commits prefix, the case and the whitespaces
+func normalizeEntryText(entry string) string {
+	normalized := strings.TrimSpace(entry)
+	if match := bulletRegex.FindStringSubmatch(normalized); match != nil {
+		normalized = "- " + stripConventionalPrefix(normalized[len(match[0]):])
+	}
+	return strings.ToLower(strings.Join(strings.Fields(normalized), " "))
+}
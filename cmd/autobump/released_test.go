@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogReleasedDuplicate = changelogTemplate + `
+
+### Added
+
+- feat: added the export of the reports.
+
+### Fixed
+
+* Fixed the login   redirection.
+
+## [1.1.0] - 1984-01-02
+
+### Added
+
+- Added the export of the reports.
+
+### Fixed
+
+- Fixed the login redirection.
+
+## [1.0.0] - 1984-01-01
+
+### Added
+
+- Added the login.`
+
+const changelogReleasedSubset = changelogTemplate + `
+
+### Fixed
+
+- Fixed the login redirection.
+
+## [1.1.0] - 1984-01-02
+
+### Added
+
+- Added the export of the reports.
+
+### Fixed
+
+- Fixed the login redirection.`
+
+const changelogReleasedNewContent = changelogTemplate + `
+
+### Added
+
+- Added the export of the reports.
+- Added the import of the reports.
+
+## [1.1.0] - 1984-01-02
+
+### Added
+
+- Added the export of the reports.`
+
+// getReleasedTestSummary parses the "Unreleased" section of the fixture
+func getReleasedTestSummary(t *testing.T, changelog string) ([]string, UnreleasedSummary) {
+	t.Helper()
+
+	lines := strings.Split(changelog, "\n")
+	summary, err := getUnreleasedSummary(lines, ChangelogConfig{})
+	require.NoError(t, err)
+	return lines, summary
+}
+
+func TestFindAlreadyReleasedContent_ExactDuplicate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines, summary := getReleasedTestSummary(t, changelogReleasedDuplicate)
+
+	// Act
+	line := findAlreadyReleasedContent(lines, summary, ChangelogConfig{})
+
+	// Assert
+	require.Equal(t, 18, line)
+	assert.Equal(t, "## [1.1.0] - 1984-01-02", lines[line-1])
+}
+
+func TestFindAlreadyReleasedContent_Subset(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines, summary := getReleasedTestSummary(t, changelogReleasedSubset)
+
+	// Act
+	line := findAlreadyReleasedContent(lines, summary, ChangelogConfig{})
+	subsetLine := findAlreadyReleasedContent(lines, summary, ChangelogConfig{SkipIfSubsetOfLastRelease: true})
+
+	// Assert
+	assert.Zero(t, line)
+	require.Positive(t, subsetLine)
+	assert.Equal(t, "## [1.1.0] - 1984-01-02", lines[subsetLine-1])
+}
+
+func TestFindAlreadyReleasedContent_NewContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines, summary := getReleasedTestSummary(t, changelogReleasedNewContent)
+
+	// Act
+	line := findAlreadyReleasedContent(lines, summary, ChangelogConfig{SkipIfSubsetOfLastRelease: true})
+
+	// Assert
+	assert.Zero(t, line)
+}
+
+func TestRenderDigest_AlreadyReleasedContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	results := []ProjectResult{{
+		Name: "web", Forge: "github.com", Organization: "acme",
+		PreviousVersion: "1.1.0", Status: projectStatusAlreadyReleased,
+	}}
+
+	// Act
+	markdown := renderDigest(results)
+
+	// Assert
+	assert.Contains(t, markdown, "- web 1.1.0 (already released content)\n")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RepoMetadata is the information of a repository fetched from the API of its provider
+type RepoMetadata struct {
+	ID            string // the GUID on Azure DevOps, the number on GitLab
+	DefaultBranch string // without the "refs/heads/" prefix
+}
+
+// repoMetadataKey identifies a repository across the providers
+type repoMetadataKey struct {
+	host         string
+	organization string
+	project      string
+	repository   string
+}
+
+// repoMetadataCache keeps the metadata of the repositories fetched in a run, so the operations on the same
+// repository (e.g. the components of a monorepo, or the CHANGELOG redirected to another repository) fetch it once.
+// Nothing is invalidated, since the metadata doesn't change during a run. It is safe to be shared by
+// concurrent workers.
+type repoMetadataCache struct {
+	mutex   sync.Mutex
+	entries map[repoMetadataKey]*repoMetadataEntry
+}
+
+// repoMetadataEntry is the metadata of a repository, fetched by the first operation needing it
+type repoMetadataEntry struct {
+	mutex    sync.Mutex
+	metadata *RepoMetadata
+}
+
+// get returns the metadata of the repository, fetching it the first time. The failed fetches aren't kept,
+// so the next operation on the repository tries again.
+func (c *repoMetadataCache) get(key repoMetadataKey, fetch func() (RepoMetadata, error)) (RepoMetadata, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	c.mutex.Lock()
+	if c.entries == nil {
+		c.entries = make(map[repoMetadataKey]*repoMetadataEntry)
+	}
+	entry, found := c.entries[key]
+	if !found {
+		entry = &repoMetadataEntry{}
+		c.entries[key] = entry
+	}
+	c.mutex.Unlock()
+
+	// the concurrent operations on the same repository wait for the first fetch
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if entry.metadata != nil {
+		log.Debugf("Using the metadata of the repository %s fetched before in the run", key.repository)
+		return *entry.metadata, nil
+	}
+
+	metadata, err := fetch()
+	if err != nil {
+		return RepoMetadata{}, err
+	}
+	entry.metadata = &metadata
+	return metadata, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAzureDevOpsRepositoryAPIClient mocks the repositories API of Azure DevOps, counting the requests
+func newAzureDevOpsRepositoryAPIClient(t *testing.T, statusCodes ...int) (*http.Client, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := int(requests.Add(1))
+		if request <= len(statusCodes) && statusCodes[request-1] != http.StatusOK {
+			w.WriteHeader(statusCodes[request-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "repo-guid", "name": "repo", "defaultBranch": "refs/heads/trunk"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return &http.Client{Transport: &redirectTransport{target: target}}, &requests
+}
+
+func TestGetAzureDevOpsRepoMetadata_FetchesOncePerRepository(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, requests := newAzureDevOpsRepositoryAPIClient(t)
+	globalConfig := &GlobalConfig{repoMetadata: &repoMetadataCache{}}
+
+	// Act
+	var results []RepoMetadata
+	for range 3 {
+		metadata, err := getAzureDevOpsRepoMetadata(globalConfig, client, "org", "project", "repo", "pat", standardLogEntry())
+		require.NoError(t, err)
+		results = append(results, metadata)
+	}
+	_, err := getAzureDevOpsRepoMetadata(globalConfig, client, "org", "project", "other", "pat", standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "one fetch per repository expected")
+	for _, metadata := range results {
+		assert.Equal(t, RepoMetadata{ID: "repo-guid", DefaultBranch: "trunk"}, metadata)
+	}
+}
+
+func TestGetAzureDevOpsRepoMetadata_FetchesAgainAfterAFailure(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, requests := newAzureDevOpsRepositoryAPIClient(t, http.StatusForbidden)
+	globalConfig := &GlobalConfig{repoMetadata: &repoMetadataCache{}}
+
+	// Act
+	_, firstErr := getAzureDevOpsRepoMetadata(globalConfig, client, "org", "project", "repo", "pat", standardLogEntry())
+	metadata, secondErr := getAzureDevOpsRepoMetadata(
+		globalConfig, client, "org", "project", "repo", "pat", standardLogEntry(),
+	)
+	_, thirdErr := getAzureDevOpsRepoMetadata(globalConfig, client, "org", "project", "repo", "pat", standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, firstErr, ErrFailedToFetchRepository)
+	require.ErrorIs(t, firstErr, ErrPullRequestForbidden)
+	require.NoError(t, secondErr)
+	require.NoError(t, thirdErr)
+	assert.Equal(t, "repo-guid", metadata.ID)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestGetGitLabRepoMetadata_FetchesOncePerProject(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, requests := newGitLabMock(t, map[string]string{
+		"GET /projects/group/subgroup/api": `{"id": 42, "default_branch": "develop"}`,
+	})
+	globalConfig := &GlobalConfig{repoMetadata: &repoMetadataCache{}}
+
+	// Act
+	first, firstErr := getGitLabRepoMetadata(globalConfig, client, "group/subgroup/api")
+	second, secondErr := getGitLabRepoMetadata(globalConfig, client, "group/subgroup/api")
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.Equal(t, RepoMetadata{ID: "42", DefaultBranch: "develop"}, first)
+	assert.Equal(t, first, second)
+	assert.Len(t, requests(), 1)
+}
+
+func TestRepoMetadataCache_WithoutCacheFetchesEveryTime(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var cache *repoMetadataCache
+	fetches := 0
+	fetch := func() (RepoMetadata, error) {
+		fetches++
+		return RepoMetadata{ID: "1"}, nil
+	}
+
+	// Act
+	_, firstErr := cache.get(repoMetadataKey{repository: "api"}, fetch)
+	_, secondErr := cache.get(repoMetadataKey{repository: "api"}, fetch)
+
+	// Assert
+	require.NoError(t, errors.Join(firstErr, secondErr))
+	assert.Equal(t, 2, fetches)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// statuses of the project paths which aren't Git repositories with a worktree
+const (
+	projectStatusNotAGitRepository = "not-a-git-repository"
+	projectStatusBareRepository    = "bare-repository-unsupported"
+)
+
+// mercurialHint explains how to bump the Mercurial checkouts
+const mercurialHint = "it is a Mercurial checkout, convert it to Git (e.g. with git-remote-hg) " +
+	"or point the project to a Git mirror"
+
+var (
+	ErrNotAGitRepository         = errors.New("not a Git repository")
+	ErrBareRepositoryUnsupported = errors.New("bare repositories have no worktree to commit the bump")
+)
+
+// checkRepositoryPath tells why the project path can't be opened as a Git repository with a worktree.
+// The ".git" of the linked worktrees is a file pointing to their repository, which is followed when opening it,
+// and the project might be a directory of a repository (e.g. a component of a monorepo).
+func checkRepositoryPath(projectPath string) error {
+	_, err := os.Stat(filepath.Join(projectPath, ".git"))
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to inspect %s: %w", projectPath, err)
+	}
+
+	if isBareRepository(projectPath) {
+		return fmt.Errorf("%w: %s (clone it, or point the project to its URL)", ErrBareRepositoryUnsupported, projectPath)
+	}
+	if _, err = os.Stat(filepath.Join(projectPath, ".hg")); err == nil {
+		return fmt.Errorf("%w: %s, %s", ErrNotAGitRepository, projectPath, mercurialHint)
+	}
+	if _, found := findRepositoryRoot(projectPath); found {
+		return nil
+	}
+	return fmt.Errorf("%w: %s has no .git directory", ErrNotAGitRepository, projectPath)
+}
+
+// isBareRepository tells whether the directory is a Git repository without worktree
+func isBareRepository(projectPath string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(projectPath, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// getRepositoryPathStatus returns the status of the project whose path isn't a Git repository with a worktree,
+// or an empty string for the other errors
+func getRepositoryPathStatus(err error) string {
+	switch {
+	case errors.Is(err, ErrNotAGitRepository):
+		return projectStatusNotAGitRepository
+	case errors.Is(err, ErrBareRepositoryUnsupported):
+		return projectStatusBareRepository
+	default:
+		return ""
+	}
+}
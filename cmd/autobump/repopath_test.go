@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenRepo_PlainDirectory(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "artifact.zip"), []byte("zip"), 0o600))
+
+	// Act
+	_, err := openRepo(projectPath, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrNotAGitRepository)
+	assert.Equal(t, projectStatusNotAGitRepository, getRepositoryPathStatus(err))
+	assert.NotContains(t, err.Error(), "Mercurial")
+}
+
+func TestOpenRepo_MercurialCheckout(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(projectPath, ".hg"), 0o700))
+
+	// Act
+	_, err := openRepo(projectPath, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrNotAGitRepository)
+	assert.Equal(t, projectStatusNotAGitRepository, getRepositoryPathStatus(err))
+	assert.Contains(t, err.Error(), mercurialHint)
+}
+
+func TestOpenRepo_BareRepository(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	_, err := git.PlainInit(projectPath, true)
+	require.NoError(t, err)
+
+	// Act
+	_, err = openRepo(projectPath, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrBareRepositoryUnsupported)
+	assert.Equal(t, projectStatusBareRepository, getRepositoryPathStatus(err))
+}
+
+func TestOpenRepo_LinkedWorktree(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	mainPath := t.TempDir()
+	repo, err := git.PlainInit(mainPath, false)
+	require.NoError(t, err)
+	hash := commitFile(t, repo, "CHANGELOG.md", "# Changelog\n")
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// the layout written by "git worktree add": a ".git" file pointing to the administrative directory
+	// of the worktree, which points back to the common directory of the repository
+	adminPath := filepath.Join(mainPath, ".git", "worktrees", "linked")
+	require.NoError(t, os.MkdirAll(adminPath, 0o700))
+	worktreePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte("gitdir: "+adminPath+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(adminPath, "commondir"), []byte("../..\n"), 0o600))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(adminPath, "gitdir"), []byte(filepath.Join(worktreePath, ".git")+"\n"), 0o600,
+	))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(adminPath, "HEAD"), []byte("ref: "+head.Name().String()+"\n"), 0o600,
+	))
+
+	// Act
+	linkedRepo, err := openRepo(worktreePath, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	linkedHead, err := linkedRepo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, hash, linkedHead.Hash())
+	worktree, err := linkedRepo.Worktree()
+	require.NoError(t, err)
+	assert.Equal(t, worktreePath, worktree.Filesystem.Root())
+}
+
+func TestGetRepositoryPathStatus_OtherErrors(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	status := getRepositoryPathStatus(ErrProjectPathDoesNotExist)
+
+	// Assert
+	assert.Empty(t, status)
+}
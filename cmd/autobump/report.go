@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ciOutputDotenv is the format of the CI outputs read by the GitLab dotenv reports
+const ciOutputDotenv = "dotenv"
+
+const (
+	projectOutcomeBumped  = "bumped"
+	projectOutcomeSkipped = "skipped"
+	projectOutcomeFailed  = "failed"
+)
+
+// RunReport is the JSON report of the releases prepared in a run. Its fields are stable,
+// since they are read by the CI wrappers (through "autobump ci-output") and by the scripts of the users.
+type RunReport struct {
+	Version  string          `json:"version"`
+	RunID    string          `json:"run_id"`
+	Projects []ProjectResult `json:"projects"`
+	// amount of CHANGELOG files created from each source ("template", "network", "cache" or "embedded")
+	CreatedChangelogs map[string]int `json:"created_changelogs,omitempty"`
+}
+
+// writeRunReport writes the JSON report of the run to the configured file, and prints it with the JSON output
+func writeRunReport(globalConfig *GlobalConfig) {
+	printed := isOutputFormat(outputJSON)
+	if (globalConfig.ReportOut == "" && !printed) || globalConfig.releaseDigest == nil {
+		return
+	}
+
+	report := RunReport{
+		Version:           version,
+		RunID:             runID,
+		Projects:          globalConfig.releaseDigest.getResults(),
+		CreatedChangelogs: globalConfig.templateCache.getCreated(),
+	}
+	if report.Projects == nil {
+		report.Projects = []ProjectResult{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Errorf("Failed to write the report: %v", err)
+		return
+	}
+	if printed {
+		fmt.Println(string(data)) //nolint:forbidigo // the JSON output is read from stdout, the logs going to stderr
+	}
+	if globalConfig.ReportOut == "" {
+		return
+	}
+
+	err = os.WriteFile(globalConfig.ReportOut, append(data, '\n'), 0o644) //nolint:gosec // the report is not sensitive
+	if err != nil {
+		log.Errorf("Failed to write the report: %v", err)
+		return
+	}
+	log.Infof("Report written to %s", globalConfig.ReportOut)
+}
+
+// readRunReport reads the JSON report of a run
+func readRunReport(reportPath string) (RunReport, error) {
+	var report RunReport
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return report, fmt.Errorf("failed to read the report: %w", err)
+	}
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		return report, fmt.Errorf("failed to decode the report %s: %w", reportPath, err)
+	}
+	return report, nil
+}
+
+// ciOutput is an output of the CI wrappers
+type ciOutput struct {
+	name  string
+	value string
+}
+
+// getCIOutputs returns the outputs of the CI wrappers: whether any project was bumped, how many of them,
+// and the release of the first bumped project (the only one when running for the current project)
+func getCIOutputs(report RunReport) []ciOutput {
+	var bumped []ProjectResult
+	for _, result := range report.Projects {
+		if result.NextVersion != "" && result.Status != projectStatusDryRun {
+			bumped = append(bumped, result)
+		}
+	}
+
+	var first ProjectResult
+	switch {
+	case len(bumped) > 0:
+		first = bumped[0]
+	case len(report.Projects) > 0:
+		first = report.Projects[0]
+	}
+	return []ciOutput{
+		{name: "bumped", value: strconv.FormatBool(len(bumped) > 0)},
+		{name: "bumped_projects", value: strconv.Itoa(len(bumped))},
+		{name: "new_version", value: first.NextVersion},
+		{name: "previous_version", value: first.PreviousVersion},
+		{name: "bump", value: first.Bump},
+		{name: "pr_url", value: first.PullRequestURL},
+		{name: "status", value: first.Status},
+	}
+}
+
+// resolveCIOutputFormat returns the format of the CI outputs, the GitHub Actions outputs when running there
+// and the dotenv variables otherwise (e.g. for the GitLab dotenv reports)
+func resolveCIOutputFormat(format string) (string, error) {
+	switch format {
+	case "":
+		if os.Getenv("GITHUB_ACTIONS") == "true" {
+			return outputGitHubActions, nil
+		}
+		return ciOutputDotenv, nil
+	case outputGitHubActions, ciOutputDotenv:
+		return format, nil
+	default:
+		return "", fmt.Errorf(
+			"%w: %s (expected %s or %s)", ErrInvalidOutputFormat, format, outputGitHubActions, ciOutputDotenv,
+		)
+	}
+}
+
+// writeCIOutputs writes the outputs as "name=value" lines, the dotenv variables being prefixed with "AUTOBUMP_"
+func writeCIOutputs(output io.Writer, outputs []ciOutput, format string) error {
+	for _, item := range outputs {
+		name := item.name
+		if format == ciOutputDotenv {
+			name = "AUTOBUMP_" + strings.ToUpper(name)
+		}
+		// the values are single lines, since a line break would start another output
+		value := strings.Join(strings.Fields(item.value), " ")
+		_, err := fmt.Fprintf(output, "%s=%s\n", name, value)
+		if err != nil {
+			return fmt.Errorf("failed to write the CI outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCIOutput translates the report of a run into the CI outputs. The GitHub Actions outputs are appended
+// to the file of GITHUB_OUTPUT (when set), while the others are written to the output.
+func runCIOutput(output io.Writer, reportPath string, format string) error {
+	format, err := resolveCIOutputFormat(format)
+	if err != nil {
+		return err
+	}
+	report, err := readRunReport(reportPath)
+	if err != nil {
+		return err
+	}
+
+	if outputPath := os.Getenv("GITHUB_OUTPUT"); format == outputGitHubActions && outputPath != "" {
+		var file *os.File
+		file, err = os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gosec // set by the runner
+		if err != nil {
+			return fmt.Errorf("failed to open GITHUB_OUTPUT: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	return writeCIOutputs(output, getCIOutputs(report), format)
+}
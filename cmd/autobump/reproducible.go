@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// sourceDateEpochEnv is the standard variable pinning the timestamps of reproducible builds
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+var ErrInvalidSourceDateEpoch = errors.New("invalid " + sourceDateEpochEnv)
+
+// loadReleaseDate pins the date of the releases to SOURCE_DATE_EPOCH when running in the reproducible mode
+func loadReleaseDate(globalConfig *GlobalConfig) error {
+	if !globalConfig.Reproducible {
+		return nil
+	}
+
+	epoch := os.Getenv(sourceDateEpochEnv)
+	if epoch == "" {
+		log.Warnf("%s is not set, the reproducible mode will use the current date in UTC", sourceDateEpochEnv)
+		return nil
+	}
+
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil || seconds < 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidSourceDateEpoch, epoch)
+	}
+	globalConfig.releaseDate = time.Unix(seconds, 0).UTC()
+	return nil
+}
+
+// getReleaseDate returns the date stamped on the releases, always in UTC when running in the reproducible mode
+func getReleaseDate(globalConfig *GlobalConfig) time.Time {
+	if !globalConfig.releaseDate.IsZero() {
+		return globalConfig.releaseDate
+	}
+	if globalConfig.Reproducible {
+		return time.Now().UTC()
+	}
+	return time.Now()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runReproducibleBump bumps the fixture CHANGELOG in a new repository, returning the content hash and the commit
+func runReproducibleBump(t *testing.T, globalConfig *GlobalConfig) ([32]byte, string) {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	repo, err := git.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	changelogConfig := ChangelogConfig{Date: getReleaseDate(globalConfig)}
+	_, newLines, err := processChangelog(strings.Split(changelogOriginal, "\n"), changelogConfig)
+	require.NoError(t, err)
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	require.NoError(t, writeLines(changelogPath, newLines))
+	_, err = worktree.Add("CHANGELOG.md")
+	require.NoError(t, err)
+
+	hash, err := commitChanges(
+		worktree,
+		"chore(bump): bumped version to 1.1.0",
+		"",
+		nil,
+		"AutoBump",
+		"autobump@example.com",
+		globalConfig.releaseDate,
+		standardLogEntry(),
+	)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	return sha256.Sum256(content), hash.String()
+}
+
+func TestReproducibleMode_IdenticalOutput(t *testing.T) {
+	// Arrange
+	t.Setenv(sourceDateEpochEnv, "1700000000")
+	globalConfig := &GlobalConfig{Reproducible: true}
+	require.NoError(t, loadReleaseDate(globalConfig))
+
+	// Act
+	firstContent, firstCommit := runReproducibleBump(t, globalConfig)
+	secondContent, secondCommit := runReproducibleBump(t, globalConfig)
+
+	// Assert
+	assert.Equal(t, firstContent, secondContent)
+	assert.Equal(t, firstCommit, secondCommit)
+}
+
+func TestLoadReleaseDate_SourceDateEpoch(t *testing.T) {
+	// Arrange
+	t.Setenv(sourceDateEpochEnv, "1700000000")
+	globalConfig := &GlobalConfig{Reproducible: true}
+
+	// Act
+	err := loadReleaseDate(globalConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), getReleaseDate(globalConfig))
+}
+
+func TestLoadReleaseDate_InvalidSourceDateEpoch(t *testing.T) {
+	// Arrange
+	t.Setenv(sourceDateEpochEnv, "yesterday")
+	globalConfig := &GlobalConfig{Reproducible: true}
+
+	// Act
+	err := loadReleaseDate(globalConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidSourceDateEpoch)
+}
+
+func TestLoadReleaseDate_IgnoredWhenNotReproducible(t *testing.T) {
+	// Arrange
+	t.Setenv(sourceDateEpochEnv, "1700000000")
+	globalConfig := &GlobalConfig{}
+
+	// Act
+	err := loadReleaseDate(globalConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, globalConfig.releaseDate.IsZero())
+}
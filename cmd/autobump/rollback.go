@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	log "github.com/sirupsen/logrus"
+)
+
+// rollbackStep is a change made to the local repository, along with the way to undo it
+type rollbackStep struct {
+	description string
+	revert      func() error
+}
+
+// rollbackJournal records the changes made to the local repository while bumping a project,
+// so a failing step doesn't leave the repository on a half-finished bump branch with modified files
+type rollbackJournal struct {
+	steps []rollbackStep
+}
+
+// record adds a change to the journal, the contexts without journal record nothing
+func (j *rollbackJournal) record(description string, revert func() error) {
+	if j == nil {
+		return
+	}
+	j.steps = append(j.steps, rollbackStep{description: description, revert: revert})
+}
+
+// rollback undoes the recorded changes, the latest first. Every change is undone even when one fails,
+// since the repository is left closer to its original state, and the failures are only logged.
+func (j *rollbackJournal) rollback(projectName string, logger *log.Entry) {
+	if j == nil || len(j.steps) == 0 {
+		return
+	}
+
+	logger.Warnf("Rolling back the changes made to project %s", projectName)
+	for index := len(j.steps) - 1; index >= 0; index-- {
+		step := j.steps[index]
+		if err := step.revert(); err != nil {
+			logger.Errorf("Failed to roll back (%s): %v", step.description, err)
+			continue
+		}
+		logger.Infof("Rolled back: %s", step.description)
+	}
+	j.steps = nil
+}
+
+// recordBumpBranch records the creation of the bump branch, undone by going back to the original branch
+// (or commit, when the HEAD was detached) and deleting the bump branch
+func recordBumpBranch(ctx *RepoContext, branchName string) {
+	originalHead := ctx.head
+	ctx.rollback.record(fmt.Sprintf("branch %s created", branchName), func() error {
+		err := switchHead(ctx.repo, ctx.worktree, originalHead.Name(), originalHead.Hash())
+		if err != nil {
+			return fmt.Errorf("could not checkout %s: %w", originalHead.Name().Short(), err)
+		}
+
+		err = ctx.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(branchName))
+		if err != nil {
+			return fmt.Errorf("could not delete branch: %w", err)
+		}
+		return nil
+	})
+}
+
+// originalFile is the content of a file before the bump wrote it, the missing files being deleted on rollback
+type originalFile struct {
+	path    string
+	exists  bool
+	content []byte
+	mode    os.FileMode
+}
+
+// recordFileChanges records the original content of the files written by the bump (the CHANGELOG, its archive and
+// the version files), undone by writing them back. The other files of the worktree are never touched.
+func recordFileChanges(ctx *RepoContext, changelogPath string) {
+	if ctx.rollback == nil {
+		return
+	}
+
+	var originals []originalFile
+	for _, filePath := range getBumpedFiles(ctx, changelogPath) {
+		info, err := os.Stat(filePath)
+		if os.IsNotExist(err) {
+			originals = append(originals, originalFile{path: filePath})
+			continue
+		}
+		var content []byte
+		if err == nil {
+			content, err = os.ReadFile(filePath)
+		}
+		if err != nil {
+			ctx.logger().Warnf("The file %s can't be rolled back: %v", filePath, err)
+			continue
+		}
+		originals = append(originals, originalFile{path: filePath, exists: true, content: content, mode: info.Mode()})
+	}
+
+	ctx.rollback.record("files modified", func() error {
+		var errs []error
+		for _, original := range originals {
+			var err error
+			if original.exists {
+				err = os.WriteFile(original.path, original.content, original.mode)
+			} else if err = os.Remove(original.path); os.IsNotExist(err) {
+				err = nil
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not restore %s: %w", original.path, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// getBumpedFiles returns the files the bump may write: the CHANGELOG, its archive and the version files
+// of the language and of the version streams
+func getBumpedFiles(ctx *RepoContext, changelogPath string) []string {
+	filePaths := []string{changelogPath, getChangelogArchivePath(changelogPath)}
+	if versionFiles, err := getVersionFiles(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger()); err == nil {
+		for _, versionFile := range versionFiles {
+			filePaths = append(filePaths, versionFile.Path)
+		}
+	}
+	for _, stream := range ctx.projectConfig.VersionStreams {
+		if versionFiles, err := getStreamVersionFiles(ctx.globalConfig, ctx.projectConfig, stream, ctx.logger()); err == nil {
+			for _, versionFile := range versionFiles {
+				filePaths = append(filePaths, versionFile.Path)
+			}
+		}
+	}
+
+	slices.Sort(filePaths)
+	return slices.Compact(filePaths)
+}
+
+// recordCommit records the commit made on the bump branch, undone by moving the branch (and the index) back to its
+// parent, the files being restored by the rollback of their changes
+func recordCommit(ctx *RepoContext, hash plumbing.Hash) {
+	ctx.rollback.record(fmt.Sprintf("commit %s made", hash.String()[:7]), func() error {
+		commit, err := ctx.repo.CommitObject(hash)
+		if err != nil {
+			return fmt.Errorf("could not read the commit: %w", err)
+		}
+		if commit.NumParents() == 0 {
+			return nil
+		}
+
+		err = ctx.worktree.Reset(&git.ResetOptions{Commit: commit.ParentHashes[0], Mode: git.MixedReset})
+		if err != nil {
+			return fmt.Errorf("could not undo the commit: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollback_UndoesTheFailedBump(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "git://example.test/acme/api.git")
+	commitFile(t, repo, "CHANGELOG.md", changelogOriginal+"\n")
+	commitFile(t, repo, "version.txt", "version=1.0.1\n")
+
+	ctx := &RepoContext{
+		globalConfig: &GlobalConfig{
+			LanguagesConfig: map[string]LanguageConfig{
+				"text": {VersionFiles: []VersionFile{{Path: "version.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}}}},
+			},
+			releaseDate: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+		},
+		projectConfig:   &ProjectConfig{Path: projectPath, Name: "api", Language: "text"},
+		globalGitConfig: newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+		timer:           newPhaseTimer(standardLogEntry()),
+		rollback:        &rollbackJournal{},
+	}
+	require.NoError(t, setupRepo(ctx))
+	untrackedPath := filepath.Join(projectPath, "notes-untracked.txt")
+	require.NoError(t, os.WriteFile(untrackedPath, []byte("my notes\n"), 0o600))
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	_, err := shouldBumpProject(ctx, changelogPath)
+	require.NoError(t, err)
+	branchName, err := createBumpBranch(ctx, changelogPath)
+	require.NoError(t, err)
+	require.NoError(t, updateChangelogAndVersionFiles(ctx, changelogPath))
+	createdPath := getChangelogArchivePath(changelogPath)
+	require.NoError(t, os.WriteFile(createdPath, []byte("# Archive\n"), 0o600))
+	_, err = ctx.worktree.Add(filepath.Base(createdPath))
+	require.NoError(t, err)
+	pushErr := commitAndPushChanges(ctx, branchName)
+
+	// Act
+	ctx.rollback.rollback(ctx.projectConfig.Name, ctx.logger())
+
+	// Assert
+	require.ErrorIs(t, pushErr, ErrReadOnlyRemoteURL)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, "master", head.Name().Short())
+	branchExists, err := checkBranchExists(repo, branchName)
+	require.NoError(t, err)
+	assert.False(t, branchExists, "the bump branch should be deleted")
+
+	changelog, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Equal(t, changelogOriginal+"\n", string(changelog))
+	version, err := os.ReadFile(filepath.Join(projectPath, "version.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "version=1.0.1\n", string(version))
+	assert.NoFileExists(t, createdPath)
+	untracked, err := os.ReadFile(untrackedPath)
+	require.NoError(t, err, "the untracked files should be kept")
+	assert.Equal(t, "my notes\n", string(untracked))
+	status, err := ctx.worktree.Status()
+	require.NoError(t, err)
+	assert.Equal(t, git.Untracked, status.File("notes-untracked.txt").Worktree)
+	assert.Len(t, status, 1, "only the untracked file should differ from the HEAD")
+}
+
+func TestRollbackJournal_UndoesTheLatestChangeFirst(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var reverted []string
+	journal := &rollbackJournal{}
+	for _, description := range []string{"branch created", "files modified", "commit made"} {
+		journal.record(description, func() error {
+			reverted = append(reverted, description)
+			return nil
+		})
+	}
+
+	// Act
+	journal.rollback("api", standardLogEntry())
+	journal.rollback("api", standardLogEntry())
+
+	// Assert
+	assert.Equal(t, []string{"commit made", "files modified", "branch created"}, reverted)
+}
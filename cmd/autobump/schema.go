@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// configSchemaDraft is the JSON Schema dialect of the generated schema
+const configSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema describing the configuration file
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+}
+
+// schemaAnnotation is the description and the accepted values of an option, which the struct tags can't tell
+type schemaAnnotation struct {
+	description string
+	enum        []string
+}
+
+// configSchemaAnnotations annotates the options of the configuration file, keyed by "<struct>.<key>",
+// so the options of the shared structs (e.g. the projects and the project defaults) are annotated once
+var configSchemaAnnotations = map[string]schemaAnnotation{
+	"GlobalConfig.projects": {description: "projects managed by AutoBump"},
+	"GlobalConfig.project_defaults": {
+		description: "options shared by all the projects, each project overrides the ones it sets",
+	},
+	"GlobalConfig.languages":    {description: "rules for detecting the languages of the projects, keyed by language"},
+	"GlobalConfig.gpg_key_path": {description: "path of the password-protected GPG private key signing the commits"},
+	"GlobalConfig.ssh_key_path": {
+		description: "path of the SSH private key signing the commits, instead of the user.signingkey of the Git config",
+	},
+	"GlobalConfig.sign_commits": {
+		description: "signature of the bump commits: following commit.gpgsign of the Git config, always or never",
+		enum:        []string{signCommitsAuto, signCommitsAlways, signCommitsNever},
+	},
+	"GlobalConfig.gitlab_access_token": {
+		description: "GitLab personal access token creating the merge requests, or the path of a file with it",
+	},
+	"GlobalConfig.github_access_token": {
+		description: "GitHub token creating the pull requests, or the path of a file with it",
+	},
+	"GlobalConfig.azure_devops_access_token": {
+		description: "Azure DevOps personal access token creating the pull requests, or the path of a file with it",
+	},
+	"GlobalConfig.bitbucket_access_token": {
+		description: "Bitbucket app password (username:app_password) or access token creating the pull requests, " +
+			"or the path of a file with it",
+	},
+	"GlobalConfig.gitlab_ci_job_token": {description: "GitLab CI job token, used when no other GitLab token is set"},
+	"GlobalConfig.max_file_size":       {description: "maximum size in bytes of the files read by AutoBump"},
+	"GlobalConfig.changelog":           {description: "settings for the CHANGELOG processing"},
+	"GlobalConfig.changelog_lint":      {description: "lint rules of the \"Unreleased\" entries"},
+	"GlobalConfig.concurrency":         {description: "amount of projects processed at the same time in a batch run"},
+	"GlobalConfig.max_prs_per_run":     {description: "maximum amount of pull requests created in a batch run"},
+	"GlobalConfig.max_prs_per_org":     {description: "maximum amount of pull requests per organization in a batch run"},
+	"GlobalConfig.on_limit": {
+		description: "what to do with the remaining projects once a limit is reached",
+		enum:        []string{onLimitDryRun, onLimitSkip},
+	},
+	"GlobalConfig.on_conflict": {
+		description: "what to do when the pull request conflicts with its target branch, not checked by default",
+		enum:        []string{onConflictReport, onConflictRebase, onConflictFail},
+	},
+	"GlobalConfig.api_headers": {
+		description: "headers added to the API requests of every provider, the values can be paths of files with them",
+	},
+	"GlobalConfig.user_agent":     {description: "user agent of the provider API requests"},
+	"GlobalConfig.digest_out":     {description: "path of the Markdown digest of the releases prepared in a batch run"},
+	"GlobalConfig.digest_webhook": {description: "webhook receiving the digest of the releases as JSON"},
+	"GlobalConfig.report_out":     {description: "path of the JSON report of the releases prepared in a run"},
+	"GlobalConfig.changelog_template_path": {
+		description: "template (local path or URL) of the CHANGELOG created for the projects without one",
+	},
+	"GlobalConfig.changelog_template_url": {
+		description: "URL of the template of the new CHANGELOG files when no template is set, downloaded once per run",
+	},
+	"GlobalConfig.reproducible": {description: "produce byte-identical CHANGELOG files and commits for the same input"},
+	"GlobalConfig.run_git_hooks": {
+		description: "Git hooks run before the bump commit",
+		enum:        []string{gitHooksNone, gitHooksCommitMsg, gitHooksAll},
+	},
+	"GlobalConfig.require_pr": {
+		description: "fail the project when the bump branch is pushed but the pull request can't be created",
+	},
+	"GlobalConfig.strict_features": {
+		description: "fail the projects requesting features their remote service doesn't support, instead of skipping them",
+	},
+	"GlobalConfig.providers": {
+		description: "self-hosted instances of the remote services, whose repositories are matched by their host",
+	},
+	"GlobalConfig.github_enterprise": {
+		description: "GitHub Enterprise Server instance, whose repositories are matched by their host",
+	},
+	"GlobalConfig.precheck_unreleased": {
+		description: "skip the clone of the remote projects without anything to release, fetching only their CHANGELOG",
+	},
+	"GlobalConfig.strict_permissions": {description: "refuse the token files readable by other users"},
+	"GlobalConfig.version_policy":     {description: "rules rewriting or vetoing the computed next version"},
+	"GlobalConfig.auto_tidy":          {description: "repair the artifacts of the previous versions in the CHANGELOG"},
+	"GlobalConfig.min_autobump_version": {
+		description: "oldest version of AutoBump reading the configuration, the older ones refuse to run",
+	},
+	"GlobalConfig.commit_trailers": {description: "trailers appended to the bump commit, below the DCO sign-off"},
+	"GlobalConfig.create_tag": {
+		description: "tag the latest release of the projects once its bump was merged, and push the tag",
+	},
+	"GlobalConfig.branch_template": {
+		description: "template of the bump branch, with the {{.Version}}, {{.Project}} and {{.Date}} variables",
+	},
+	"GlobalConfig.commit_message_template": {
+		description: "template of the bump commit subject and pull request title, with the same variables",
+	},
+	"GlobalConfig.pr_title_template": {
+		description: "template of the pull request title, with the same variables (the commit subject by default)",
+	},
+	"GlobalConfig.pr_body_template": {
+		description: "template of the pull request body, with the {{.Changelog}} of the release and the {{.Notes}} too",
+	},
+	"GlobalConfig.create_release": {
+		description: "publish the tagged releases on GitHub or GitLab, with their entries as notes (implies create_tag)",
+	},
+
+	"ChangelogConfig.normalize_entries":       {description: "normalize the style of the released entries"},
+	"ChangelogConfig.max_entries_per_section": {description: "maximum amount of entries per released section"},
+	"ChangelogConfig.entry_classification": {
+		description: "change level of the entries matching the patterns, regardless of their section",
+	},
+	"ChangelogConfig.classify_dependency_updates": {description: "never bump above patch for the dependency updates"},
+	"ChangelogConfig.ignore_conflict_markers":     {description: "process the CHANGELOG files with conflict markers"},
+	"ChangelogConfig.non_bumping_sections":        {description: "sections whose entries never trigger a bump alone"},
+	"ChangelogConfig.skip_if_subset_of_last_release": {
+		description: "skip the projects whose \"Unreleased\" entries are part of the latest release",
+	},
+	"ChangelogConfig.cross_section_dedup": {
+		description: "remove the entries duplicated in a section with a higher precedence, only the ones of the same section by default",
+	},
+	"ChangelogConfig.dedup_precedence": {
+		description: "precedence of the sections keeping the duplicated entries, defaults to Added, Changed and Fixed",
+	},
+	"ChangelogConfig.section_aliases": {
+		description: "section headings in other languages, mapped to the section they stand for (e.g. Añadido: Added)",
+	},
+	"ChangelogConfig.archive_releases_older_than": {
+		description: "move the releases older than this age (e.g. 90d, 6w, 18m or 2y) into the CHANGELOG archive",
+	},
+	"ChangelogConfig.max_releases_in_main_file": {
+		description: "move the releases beyond this amount into the CHANGELOG archive",
+	},
+	"ChangelogConfig.changelog_profile": {
+		description: "formatting profile of the released entries, v2-compat keeps the formatting of AutoBump 2.x",
+		enum:        []string{changelogProfileV2Compat},
+	},
+	"ChangelogConfig.output_headings": {
+		description: "language of the released section headings, kept as written by default",
+		enum:        []string{outputHeadingsPreserve, outputHeadingsEnglish},
+	},
+	"EntryClassificationRule.pattern": {description: "regular expression matching the entries"},
+	"EntryClassificationRule.level": {
+		description: "change level of the matching entries",
+		enum:        []string{changeLevelMajor, changeLevelMinor, changeLevelPatch},
+	},
+
+	"ChangelogLintConfig.spellcheck": {description: "spell check the entries with an English word list"},
+	"ChangelogLintConfig.lint_mode": {
+		description: "level of the lint findings, the errors fail the project",
+		enum:        []string{lintModeWarning, lintModeError},
+	},
+	"ChangelogLintConfig.ignore_words": {description: "words accepted by the spell check"},
+	"ChangelogLintConfig.lint_suggestions": {
+		description: "comment on the pull request the fixes of the CHANGELOG lines it adds, as suggestions where supported",
+	},
+	"ChangelogLintConfig.max_suggestions": {description: "maximum amount of suggestions per pull request, 10 by default"},
+
+	"VersionPolicyConfig.pre_1_0_breaking_is_minor": {
+		description: "the breaking changes of the 0.y.z versions bump the minor version",
+	},
+	"VersionPolicyConfig.command": {
+		description: "command receiving the computed version as JSON, printing another version or vetoing it",
+	},
+	"CommitTrailersConfig.version":  {description: "add the released version as the Autobump-Version trailer"},
+	"CommitTrailersConfig.previous": {description: "add the previous version as the Autobump-Previous trailer"},
+	"CommitTrailersConfig.run_id": {
+		description: "add the ID of the run as the Autobump-Run-Id trailer, also written in the pull request",
+	},
+	"CommitTrailersConfig.static": {description: "trailers added as they are, e.g. Team: payments"},
+
+	"GitHubEnterpriseConfig.base_url": {description: "URL of the instance"},
+	"GitHubEnterpriseConfig.api_url":  {description: "URL of the REST API, <base_url>/api/v3 by default"},
+	"GitHubEnterpriseConfig.access_token": {
+		description: "token of the instance pushing the bump branches, or the path of a file with it",
+	},
+	"GitHubEnterpriseConfig.api_headers": {
+		description: "headers added to the API requests of the instance, the values can be paths of files with them",
+	},
+
+	"LanguageConfig.extensions":       {description: "file extensions indicating the language"},
+	"LanguageConfig.special_patterns": {description: "files indicating the language"},
+	"LanguageConfig.version_files":    {description: "files where the version of the projects is written"},
+	"ProviderConfig.type":             {description: "remote service of the instance", enum: []string{"gitlab"}},
+	"ProviderConfig.base_url":         {description: "URL of the instance, with its relative path if any"},
+	"ProviderConfig.api_headers": {
+		description: "headers added to the API requests of the instance, the values can be paths of files with them",
+	},
+	"VersionFile.path": {description: "glob of the version files, relative to the project"},
+	"VersionFile.patterns": {
+		description: "regular expressions matching the version, with the text around it in capture groups",
+	},
+
+	"ProjectConfig.path":                 {description: "local path or Git URL of the repository"},
+	"ProjectConfig.name":                 {description: "name of the project, defaults to the last element of the path"},
+	"ProjectConfig.language":             {description: "language of the project, detected when omitted"},
+	"ProjectConfig.project_access_token": {description: "token of the project, prioritized over any other token"},
+	"ProjectConfig.new_version":          {description: "version to be released instead of the computed one"},
+	"ProjectConfig.base_ref":             {description: "ref the bump is computed against and the pull request targets"},
+	"ProjectConfig.versioning_scheme": {
+		description: "versioning scheme of the project",
+		enum:        []string{versioningSchemeSemVer, versioningSchemeCalVer},
+	},
+	"ProjectConfig.calver_format":           {description: "format of the CalVer versions (e.g. \"YYYY.0M.MICRO\")"},
+	"ProjectConfig.changelog_template_path": {description: "template of the CHANGELOG created for the project"},
+	"ProjectConfig.version_streams":         {description: "independent versions released from the same CHANGELOG"},
+	"ProjectConfig.default_version_stream":  {description: "version stream of the untagged entries"},
+	"ProjectConfig.release_train":           {description: "release the project only in the runs of the release train"},
+	"ProjectConfig.workspace_propagation":   {description: "propagate the version to the members of the workspace"},
+	"ProjectConfig.sign_commits": {
+		description: "signature of the bump commits of the project, overriding the global sign_commits",
+		enum:        []string{signCommitsAuto, signCommitsAlways, signCommitsNever},
+	},
+	"ProjectConfig.propagate_to_private": {description: "propagate the version to the private members too"},
+	"ProjectConfig.version_file_strategy": {
+		description: "version written in the version files: the released one or the next development version",
+		enum:        []string{versionFileStrategyRelease, versionFileStrategyNextDev},
+	},
+	"ProjectConfig.dev_suffix": {description: "suffix of the next development version, defaults to \"-dev\""},
+	"ProjectConfig.next_dev_increment": {
+		description: "part of the released version incremented for the next development version, defaults to minor",
+		enum:        []string{"minor", "patch"},
+	},
+	"ProjectConfig.allow_ignored_version_files": {
+		description: "update the version files matched by a glob even when the repository ignores them",
+	},
+	"ProjectConfig.normalize_changelog_filename": {
+		description: "rename the CHANGELOG with another case (e.g. Changelog.md) to CHANGELOG.md in the bump commit",
+	},
+	"ProjectConfig.reviewers": {description: "GitLab usernames asked to review the merge request"},
+	"ProjectConfig.notify_group": {
+		description: "GitLab group mentioned in a thread of the merge request, notifying the approvers",
+	},
+	"ProjectConfig.changelog_redirect": {
+		description: "repository keeping the CHANGELOG of the project, released with a pull request of its own",
+	},
+
+	"ProjectConfig.auto_merge": {
+		description: "merge the pull request with the merge train or once its pipeline succeeds (GitLab only)",
+	},
+	"ProjectConfig.propagate_to": {
+		description: "manifest of an umbrella repository pinning the version, updated by a pull request after the bump",
+	},
+	"ProjectConfig.version_prefix": {
+		description: "prefix of the released versions, or auto to follow the style of the CHANGELOG headers and the tags",
+		enum:        []string{"v", "", "auto"},
+	},
+	"ProjectConfig.changelog_source": {
+		description: "entries released: those of the Unreleased section, or the Conventional Commits since the last tag too",
+		enum:        []string{changelogSourceChangelog, changelogSourceCommits},
+	},
+	"ProjectConfig.create_tag":      {description: "tag the latest release once its bump was merged, overriding create_tag"},
+	"ProjectConfig.branch_template": {description: "template of the bump branch, overriding branch_template"},
+	"ProjectConfig.commit_message_template": {
+		description: "template of the bump commit subject, overriding commit_message_template",
+	},
+	"ProjectConfig.pr_title_template": {
+		description: "template of the pull request title, overriding pr_title_template",
+	},
+	"ProjectConfig.pr_body_template": {description: "template of the pull request body, overriding pr_body_template"},
+	"ProjectConfig.create_release": {
+		description: "publish the tagged releases on the remote service, overriding create_release",
+	},
+
+	"ChangelogRedirect.path":      {description: "URL (or local path) of the repository keeping the CHANGELOG"},
+	"ChangelogRedirect.changelog": {description: "path of the CHANGELOG in that repository, defaults to CHANGELOG.md"},
+
+	"PropagationTarget.path": {description: "URL (or local path) of the umbrella repository"},
+	"PropagationTarget.file": {description: "path of the YAML or JSON manifest in that repository"},
+	"PropagationTarget.key":  {description: "dotted path of the version in the manifest, defaults to the project name"},
+	"PropagationTarget.branch": {
+		description: "template of the branch, with .Name, .Version and .Releases, defaults to chore/propagate-...",
+	},
+	"PropagationTarget.title": {description: "template of the commit and the pull request, with the same fields"},
+
+	"VersionStream.name":          {description: "name of the version stream"},
+	"VersionStream.header_prefix": {description: "prefix of the release headers, defaults to \"<name>-\""},
+	"VersionStream.entry_tag":     {description: "regular expression tagging the entries, defaults to \"[<name>]\""},
+	"VersionStream.version_files": {description: "version files of the stream, relative to the project"},
+}
+
+// generateConfigSchema generates the JSON Schema of the configuration file from the struct tags,
+// so it always has the options the strict decoder accepts
+func generateConfigSchema() *jsonSchema {
+	schema := generateTypeSchema(reflect.TypeOf(GlobalConfig{}))
+	schema.Schema = configSchemaDraft
+	schema.Title = "AutoBump configuration"
+	return schema
+}
+
+// generateTypeSchema generates the schema of the values decoded into the type
+func generateTypeSchema(typ reflect.Type) *jsonSchema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() { //nolint:exhaustive // the other kinds aren't used by the configuration
+	case reflect.Struct:
+		schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: false}
+		for key, fieldType := range getYAMLFields(typ) {
+			property := generateTypeSchema(fieldType)
+			annotation := configSchemaAnnotations[typ.Name()+"."+key]
+			property.Description = annotation.description
+			property.Enum = annotation.enum
+			schema.Properties[key] = property
+		}
+		return schema
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: generateTypeSchema(typ.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: generateTypeSchema(typ.Elem())}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// writeConfigSchema writes the JSON Schema of the configuration file, to be referenced by the editors
+// (e.g. with the "# yaml-language-server: $schema=autobump.schema.json" header)
+func writeConfigSchema(output io.Writer) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(generateConfigSchema())
+	if err != nil {
+		return fmt.Errorf("failed to write the config schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// configsDir is the directory of the example configuration and its schema
+var configsDir = filepath.Join("..", "..", "configs")
+
+// validateSchemaNode validates a YAML node with the subset of JSON Schema generated for the configuration,
+// returning the path of each violation
+func validateSchemaNode(schema *jsonSchema, node *yaml.Node, path string) []string {
+	if node.Kind == yaml.DocumentNode {
+		return validateSchemaNode(schema, node.Content[0], path)
+	}
+
+	var violations []string
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			return []string{path + ": expected an object"}
+		}
+		for index := 0; index < len(node.Content); index += 2 {
+			key, value := node.Content[index].Value, node.Content[index+1]
+			property, exists := schema.Properties[key]
+			switch additional := schema.AdditionalProperties.(type) {
+			case *jsonSchema:
+				property, exists = additional, true
+			case bool:
+				exists = exists || additional
+			}
+			if !exists {
+				violations = append(violations, fmt.Sprintf("%s.%s: unknown property", path, key))
+				continue
+			}
+			if property != nil {
+				violations = append(violations, validateSchemaNode(property, value, path+"."+key)...)
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return []string{path + ": expected an array"}
+		}
+		for index, item := range node.Content {
+			violations = append(violations, validateSchemaNode(schema.Items, item, fmt.Sprintf("%s[%d]", path, index))...)
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			return []string{fmt.Sprintf("%s: expected a %s", path, schema.Type)}
+		}
+		if !isSchemaScalar(schema.Type, node) {
+			violations = append(violations, fmt.Sprintf("%s: expected a %s, got %q", path, schema.Type, node.Value))
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, node.Value) {
+			violations = append(violations, fmt.Sprintf("%s: %q isn't one of %v", path, node.Value, schema.Enum))
+		}
+	}
+	return violations
+}
+
+// isSchemaScalar tells whether the YAML scalar has the JSON type
+func isSchemaScalar(schemaType string, node *yaml.Node) bool {
+	switch schemaType {
+	case "boolean":
+		_, err := strconv.ParseBool(node.Value)
+		return err == nil
+	case "integer":
+		_, err := strconv.ParseInt(node.Value, 10, 64)
+		return err == nil
+	case "number":
+		_, err := strconv.ParseFloat(node.Value, 64)
+		return err == nil
+	default:
+		return node.Tag == "!!str"
+	}
+}
+
+// validateConfigWithSchema validates the configuration with the generated schema
+func validateConfigWithSchema(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	var document yaml.Node
+	require.NoError(t, yaml.Unmarshal(data, &document))
+	return validateSchemaNode(generateConfigSchema(), &document, "$")
+}
+
+func TestWriteConfigSchema_MatchesConfigsSchema(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	schemaPath := filepath.Join(configsDir, "autobump.schema.json")
+	var output bytes.Buffer
+
+	// Act
+	err := writeConfigSchema(&output)
+
+	// Assert
+	require.NoError(t, err)
+	if *updateGoldens {
+		require.NoError(t, os.WriteFile(schemaPath, output.Bytes(), 0o600))
+	}
+	expected, err := os.ReadFile(schemaPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), output.String(), "run the tests with -update to regenerate the schema")
+}
+
+func TestGenerateConfigSchema_ValidatesExampleConfig(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	data, err := os.ReadFile(filepath.Join(configsDir, "autobump.yaml"))
+	require.NoError(t, err)
+
+	// Act
+	violations := validateConfigWithSchema(t, data)
+
+	// Assert
+	assert.Empty(t, violations)
+}
+
+func TestGenerateConfigSchema_RejectsMisspelledKey(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	data := []byte("projects:\n  - path: \"/home/user/repo\"\n    versoning_scheme: \"calver\"\n")
+
+	// Act
+	violations := validateConfigWithSchema(t, data)
+
+	// Assert
+	assert.Equal(t, []string{"$.projects[0].versoning_scheme: unknown property"}, violations)
+}
+
+func TestGenerateConfigSchema_RejectsUnknownEnumValue(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	data := []byte("on_limit: \"abort\"\nproject_defaults:\n  versioning_scheme: \"calver\"\n")
+
+	// Act
+	violations := validateConfigWithSchema(t, data)
+
+	// Assert
+	assert.Equal(t, []string{`$.on_limit: "abort" isn't one of [dry-run skip]`}, violations)
+}
+
+func TestGenerateConfigSchema_AnnotatesEveryOption(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	options := make(map[string]bool)
+	var collect func(typ reflect.Type)
+	collect = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return
+		}
+		for key, fieldType := range getYAMLFields(typ) {
+			options[typ.Name()+"."+key] = true
+			collect(fieldType)
+		}
+	}
+
+	// Act
+	collect(reflect.TypeOf(GlobalConfig{}))
+
+	// Assert
+	for option := range options {
+		assert.NotEmpty(t, configSchemaAnnotations[option].description, "option %s has no description", option)
+	}
+	for option := range configSchemaAnnotations {
+		assert.True(t, options[option], "annotation %s has no option", option)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// projectSelection is the order and the amount of the projects processed by a batch run.
+// The projects are processed in the order of the configuration, unless they are shuffled.
+type projectSelection struct {
+	limit   int // maximum amount of projects attempted, 0 for all of them
+	shuffle bool
+	seed    uint64
+}
+
+// newProjectSelection creates the selection of the batch run, the shuffle is seeded with the current time
+// when no seed is given (it is logged, so the same order can be reproduced)
+func newProjectSelection(limit int, shuffle bool, seed uint64, seeded bool) projectSelection {
+	if shuffle && !seeded {
+		seed = uint64(time.Now().UnixNano()) //nolint:gosec // the nanoseconds are never negative
+	}
+	return projectSelection{limit: limit, shuffle: shuffle, seed: seed}
+}
+
+// orderProjects returns the projects in the order they are processed, without changing the configuration
+func (s projectSelection) orderProjects(projects []ProjectConfig) []ProjectConfig {
+	ordered := append([]ProjectConfig{}, projects...)
+	if s.shuffle {
+		log.Infof("Shuffling the projects with --seed %d", s.seed)
+		random := rand.New(rand.NewPCG(s.seed, s.seed)) //nolint:gosec // the sampling doesn't need to be secure
+		random.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+	}
+	return ordered
+}
+
+// selectProjects returns the projects to process in order, up to the limit of attempted projects.
+// The projects skipped by the filters are kept (so their skip is logged), but don't count for the limit.
+func (s projectSelection) selectProjects(
+	projects []ProjectConfig,
+	isFiltered func(project *ProjectConfig) bool,
+) ([]ProjectConfig, int, bool) {
+	var selected []ProjectConfig
+	attempted := 0
+	for _, project := range s.orderProjects(projects) {
+		if !isFiltered(&project) {
+			if s.limit > 0 && attempted >= s.limit {
+				return selected, attempted, true
+			}
+			attempted++
+		}
+		selected = append(selected, project)
+	}
+	return selected, attempted, false
+}
+
+// logSummary reports how many projects were attempted, and whether the run was limited
+func (s projectSelection) logSummary(attempted int, total int, limited bool) {
+	if limited {
+		log.Infof("Processed %d of %d projects, limited by --limit %d", attempted, total, s.limit)
+		return
+	}
+	log.Infof("Processed %d of %d projects", attempted, total)
+}
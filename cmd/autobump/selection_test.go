@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSelectionProjects creates the projects named "project-1" to "project-<amount>"
+func newSelectionProjects(amount int) []ProjectConfig {
+	projects := make([]ProjectConfig, 0, amount)
+	for index := 1; index <= amount; index++ {
+		projects = append(projects, ProjectConfig{Name: fmt.Sprintf("project-%d", index)})
+	}
+	return projects
+}
+
+// getProjectNames returns the names of the projects, in order
+func getProjectNames(projects []ProjectConfig) []string {
+	names := make([]string, 0, len(projects))
+	for _, project := range projects {
+		names = append(names, project.Name)
+	}
+	return names
+}
+
+func noProjectFiltered(_ *ProjectConfig) bool {
+	return false
+}
+
+func TestSelectProjects_ConfigOrder(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	selection := newProjectSelection(0, false, 0, false)
+
+	// Act
+	selected, attempted, limited := selection.selectProjects(newSelectionProjects(4), noProjectFiltered)
+
+	// Assert
+	assert.Equal(t, []string{"project-1", "project-2", "project-3", "project-4"}, getProjectNames(selected))
+	assert.Equal(t, 4, attempted)
+	assert.False(t, limited)
+}
+
+func TestSelectProjects_Limit(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	selection := newProjectSelection(2, false, 0, false)
+
+	// Act
+	selected, attempted, limited := selection.selectProjects(newSelectionProjects(4), noProjectFiltered)
+
+	// Assert
+	assert.Equal(t, []string{"project-1", "project-2"}, getProjectNames(selected))
+	assert.Equal(t, 2, attempted)
+	assert.True(t, limited)
+}
+
+func TestSelectProjects_LimitNotReached(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	selection := newProjectSelection(4, false, 0, false)
+
+	// Act
+	_, attempted, limited := selection.selectProjects(newSelectionProjects(4), noProjectFiltered)
+
+	// Assert
+	assert.Equal(t, 4, attempted)
+	assert.False(t, limited)
+}
+
+func TestSelectProjects_FilteredProjectsDontCount(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	selection := newProjectSelection(2, false, 0, false)
+	projects := newSelectionProjects(5)
+	projects[0].ReleaseTrain = true
+	projects[2].ReleaseTrain = true
+	globalConfig := &GlobalConfig{}
+
+	// Act
+	selected, attempted, limited := selection.selectProjects(projects, func(project *ProjectConfig) bool {
+		return isWaitingForTrain(globalConfig, project)
+	})
+
+	// Assert
+	assert.Equal(t, []string{"project-1", "project-2", "project-3", "project-4"}, getProjectNames(selected))
+	assert.Equal(t, 2, attempted)
+	assert.True(t, limited)
+}
+
+func TestSelectProjects_SeededShuffleIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projects := newSelectionProjects(20)
+
+	// Act
+	first, _, _ := newProjectSelection(0, true, 42, true).selectProjects(projects, noProjectFiltered)
+	second, _, _ := newProjectSelection(0, true, 42, true).selectProjects(projects, noProjectFiltered)
+	other, _, _ := newProjectSelection(0, true, 7, true).selectProjects(projects, noProjectFiltered)
+
+	// Assert
+	assert.Equal(t, getProjectNames(first), getProjectNames(second))
+	assert.NotEqual(t, getProjectNames(first), getProjectNames(other))
+	assert.NotEqual(t, getProjectNames(projects), getProjectNames(first))
+	assert.ElementsMatch(t, getProjectNames(projects), getProjectNames(first))
+	assert.Equal(t, "project-1", projects[0].Name)
+}
+
+func TestSelectProjects_ShuffleWithLimit(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projects := newSelectionProjects(20)
+	selection := newProjectSelection(5, true, 42, true)
+	shuffled, _, _ := newProjectSelection(0, true, 42, true).selectProjects(projects, noProjectFiltered)
+
+	// Act
+	selected, attempted, limited := selection.selectProjects(projects, noProjectFiltered)
+
+	// Assert
+	assert.Equal(t, getProjectNames(shuffled[:5]), getProjectNames(selected))
+	assert.Equal(t, 5, attempted)
+	assert.True(t, limited)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// serviceInfo is what AutoBump knows about a remote service: its canonical name,
+// the hosts recognizing its URLs and the features supported for it
+type serviceInfo struct {
+	serviceType ServiceType
+	name        string
+	hosts       []string
+	// other names of the service accepted on the command line (e.g. "gh")
+	aliases      []string
+	capabilities ServiceCapabilities
+	// the instances of the service can be self-hosted, pointed to by the "providers" option
+	selfHosted bool
+}
+
+// ServiceCapabilities are the operations AutoBump does through the API of a remote service
+type ServiceCapabilities struct {
+	// the pull requests are created
+	CreatePullRequest bool
+	// the CHANGELOG is fetched without cloning ("precheck_unreleased")
+	ReadContents bool
+	// the reviews are requested and the approvers are notified in the pull request ("reviewers" and "notify_group")
+	RequestReviews bool
+	// the review comments are posted on the lines of the pull request ("lint_suggestions")
+	Comment bool
+	// the pull request is set to be merged ("auto_merge")
+	AutoMerge bool
+	// the mergeability of the pull request is read, and the pull request is updated ("on_conflict")
+	UpdatePullRequest bool
+	// the releases are published from their tag ("create_release")
+	CreateRelease bool
+}
+
+// services is the registry of the remote services, in the order their hosts are matched.
+// A new service is one more entry, along with its adapters.
+var services = []serviceInfo{
+	{
+		serviceType: GITLAB, name: "gitlab", hosts: []string{"gitlab.com"}, aliases: []string{"gl"}, selfHosted: true,
+		capabilities: ServiceCapabilities{
+			CreatePullRequest: true,
+			ReadContents:      true,
+			RequestReviews:    true,
+			Comment:           true,
+			AutoMerge:         true,
+			UpdatePullRequest: true,
+			CreateRelease:     true,
+		},
+	},
+	{
+		serviceType: GITHUB, name: "github", hosts: []string{"github.com"}, aliases: []string{"gh"},
+		capabilities: ServiceCapabilities{CreatePullRequest: true, ReadContents: true, CreateRelease: true},
+	},
+	{
+		serviceType: BITBUCKET, name: "bitbucket", hosts: []string{"bitbucket.org"}, aliases: []string{"bb"},
+		capabilities: ServiceCapabilities{CreatePullRequest: true},
+	},
+	{serviceType: CODECOMMIT, name: "codecommit", hosts: []string{"git-codecommit"}, aliases: []string{"aws-codecommit"}},
+	{
+		serviceType: AZUREDEVOPS, name: "azure-devops", hosts: []string{"dev.azure.com"}, aliases: []string{"ado", "azure"},
+		capabilities: ServiceCapabilities{
+			CreatePullRequest: true,
+			ReadContents:      true,
+			Comment:           true,
+			UpdatePullRequest: true,
+		},
+	},
+}
+
+// unknownService describes the URLs of no recognized service
+var unknownService = serviceInfo{serviceType: UNKNOWN, name: "unknown"}
+
+var (
+	ErrPullRequestNotSupported = errors.New("the pull requests aren't supported")
+	ErrUnknownService          = errors.New("unknown service")
+)
+
+// getServiceInfo returns what is known about the service type
+func getServiceInfo(serviceType ServiceType) serviceInfo {
+	for _, service := range services {
+		if service.serviceType == serviceType {
+			return service
+		}
+	}
+	return unknownService
+}
+
+// String returns the canonical name of the service (e.g. "azure-devops")
+func (s ServiceType) String() string {
+	return getServiceInfo(s).name
+}
+
+// Capabilities returns the operations AutoBump does on the service
+func (s ServiceType) Capabilities() ServiceCapabilities {
+	return getServiceInfo(s).capabilities
+}
+
+// normalizeServiceName lowercases the name of a service and removes its separators,
+// so "Azure_DevOps", "azure-devops" and "azuredevops" are the same name
+func normalizeServiceName(name string) string {
+	return strings.Map(func(char rune) rune {
+		if strings.ContainsRune(" -_.", char) {
+			return -1
+		}
+		return unicode.ToLower(char)
+	}, strings.TrimSpace(name))
+}
+
+// parseServiceType returns the service named by its canonical name or one of its aliases, regardless of the case
+// and the separators. The unknown names are refused, listing the services and suggesting the nearest one.
+func parseServiceType(name string) (ServiceType, error) {
+	normalized := normalizeServiceName(name)
+	suggestion, suggestionDistance := "", -1
+	names := make([]string, 0, len(services))
+	for _, service := range services {
+		names = append(names, service.name)
+		for _, candidate := range append([]string{service.name}, service.aliases...) {
+			candidate = normalizeServiceName(candidate)
+			if candidate == normalized {
+				return service.serviceType, nil
+			}
+			distance := getEditDistance(normalized, candidate)
+			if suggestionDistance == -1 || distance < suggestionDistance {
+				suggestion, suggestionDistance = service.name, distance
+			}
+		}
+	}
+
+	err := fmt.Errorf("%w: %q (supported: %s)", ErrUnknownService, name, strings.Join(names, ", "))
+	// the suggestion is only given when at most a third of the name differs
+	if normalized != "" && suggestionDistance*3 <= len(normalized) {
+		err = fmt.Errorf("%w, did you mean %q?", err, suggestion)
+	}
+	return UNKNOWN, err
+}
+
+// getEditDistance returns the Levenshtein distance between the two strings
+func getEditDistance(first, second string) int {
+	firstRunes, secondRunes := []rune(first), []rune(second)
+	previous := make([]int, len(secondRunes)+1)
+	current := make([]int, len(secondRunes)+1)
+	for index := range previous {
+		previous[index] = index
+	}
+	for i := 1; i <= len(firstRunes); i++ {
+		current[0] = i
+		for j := 1; j <= len(secondRunes); j++ {
+			substitution := previous[j-1]
+			if firstRunes[i-1] != secondRunes[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(secondRunes)]
+}
+
+// getServiceTypeByURL returns the type of the remote service (e.g. GitHub, GitLab) by URL,
+// the configured self-hosted instances being matched before the public hosts
+func getServiceTypeByURL(remoteURL string) ServiceType {
+	if provider, found := findSelfHostedProvider(remoteURL); found {
+		return provider.serviceType
+	}
+	for _, service := range services {
+		for _, host := range service.hosts {
+			if strings.Contains(remoteURL, host) {
+				return service.serviceType
+			}
+		}
+	}
+	return UNKNOWN
+}
+
+// checkPullRequestSupport tells why the pull requests of the service can't be created, nil when they can
+func checkPullRequestSupport(serviceType ServiceType) error {
+	service := getServiceInfo(serviceType)
+	switch {
+	case service.capabilities.CreatePullRequest:
+		return nil
+	case serviceType == UNKNOWN:
+		return fmt.Errorf("%w: the remote service isn't recognized", ErrPullRequestNotSupported)
+	default:
+		return fmt.Errorf(
+			"%w: %s is recognized but not yet supported for pull request creation", ErrPullRequestNotSupported, service.name,
+		)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceURLs are URLs of every service type
+var serviceURLs = map[ServiceType]string{
+	UNKNOWN:     "https://git.example.com/group/project.git",
+	GITHUB:      "https://github.com/owner/project.git",
+	GITLAB:      "https://gitlab.com/group/project.git",
+	AZUREDEVOPS: "https://dev.azure.com/organization/project/_git/repository",
+	BITBUCKET:   "https://bitbucket.org/workspace/project.git",
+	CODECOMMIT:  "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/project",
+}
+
+func TestServices_ConsistentAcrossCallSites(t *testing.T) {
+	t.Parallel()
+
+	names := make(map[string]ServiceType)
+	for serviceType := UNKNOWN; serviceType <= CODECOMMIT; serviceType++ {
+		remoteURL, found := serviceURLs[serviceType]
+		require.True(t, found, "missing URL of service type %d", serviceType)
+		service := getServiceInfo(serviceType)
+
+		t.Run(service.name, func(t *testing.T) {
+			t.Parallel()
+
+			// URL detection
+			assert.Equal(t, serviceType, getServiceTypeByURL(remoteURL))
+			assert.Equal(t, serviceType, service.serviceType)
+
+			// pull request creation
+			supportErr := checkPullRequestSupport(serviceType)
+			assert.Equal(t, service.capabilities.CreatePullRequest, supportErr == nil)
+			projectConfig := &ProjectConfig{Path: remoteURL, NewVersion: "1.1.0"}
+			state := &projectState{}
+			preview, err := buildPullRequestPreview(
+				&GlobalConfig{}, projectConfig, state, serviceType, remoteURL, "chore/bump-1.1.0", "main", "1.1.0",
+				standardLogEntry(),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, service.capabilities.CreatePullRequest, preview != nil)
+			if preview != nil {
+				assert.Equal(t, service.name, preview.Provider)
+			}
+			assert.Equal(t, service.capabilities, serviceType.Capabilities())
+			if !service.capabilities.CreatePullRequest {
+				// the operations on the pull requests need them to be created
+				assert.Equal(t, ServiceCapabilities{
+					ReadContents:  service.capabilities.ReadContents,
+					CreateRelease: service.capabilities.CreateRelease,
+				}, service.capabilities)
+				_, err = createPullRequest(
+					&GlobalConfig{}, projectConfig, state, nil, "chore/bump-1.1.0", "main", serviceType, standardLogEntry(),
+				)
+				require.ErrorIs(t, err, ErrPullRequestNotSupported)
+			}
+
+			// CHANGELOG fetched without cloning
+			_, err = buildFileContentsRequest(context.Background(), &GlobalConfig{}, projectConfig, "CHANGELOG.md")
+			assert.Equal(t, service.capabilities.ReadContents, err == nil, "precheck error: %v", err)
+
+			// releases published from their tag
+			assert.Equal(t, service.capabilities.CreateRelease, checkReleaseSupport(serviceType) == nil)
+		})
+
+		previous, duplicated := names[service.name]
+		assert.False(t, duplicated, "%s is the name of service types %d and %d", service.name, previous, serviceType)
+		names[service.name] = serviceType
+		assert.Equal(t, service.name, serviceType.String())
+		for _, name := range append([]string{service.name}, service.aliases...) {
+			if serviceType == UNKNOWN {
+				break
+			}
+			parsed, err := parseServiceType(name)
+			require.NoError(t, err)
+			assert.Equal(t, serviceType, parsed, "%s names another service", name)
+		}
+	}
+}
+
+func TestCheckPullRequestSupport_NamesTheRecognizedService(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := checkPullRequestSupport(CODECOMMIT)
+
+	// Assert
+	require.ErrorIs(t, err, ErrPullRequestNotSupported)
+	assert.Contains(t, err.Error(), "codecommit is recognized but not yet supported for pull request creation")
+}
+
+func TestCheckUnsupportedServices(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{
+		PrecheckUnreleased: true,
+		Projects: []ProjectConfig{
+			{Path: serviceURLs[GITLAB]},
+			{Path: serviceURLs[CODECOMMIT]},
+			{Path: "/local/project"},
+		},
+	}
+
+	// Act
+	warnings := checkUnsupportedServices(coherenceInput{globalConfig: globalConfig, mode: invocationBatch})
+
+	// Assert
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "projects[1]: the pull requests aren't supported: codecommit is recognized")
+	assert.Contains(t, warnings[1], "projects[1]: precheck_unreleased is set, but the CHANGELOG of codecommit")
+}
+
+func TestParseServiceType_Aliases(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]ServiceType{
+		"gh":           GITHUB,
+		"gl":           GITLAB,
+		"ado":          AZUREDEVOPS,
+		"azure":        AZUREDEVOPS,
+		"azuredevops":  AZUREDEVOPS,
+		"azure-devops": AZUREDEVOPS,
+		"bb":           BITBUCKET,
+	} {
+		// Act
+		serviceType, err := parseServiceType(name)
+
+		// Assert
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, serviceType, name)
+	}
+}
+
+func TestParseServiceType_CaseAndSeparators(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"Azure_DevOps", " AZURE DEVOPS ", "azure.devops", "Azure-Dev-Ops"} {
+		// Act
+		serviceType, err := parseServiceType(name)
+
+		// Assert
+		require.NoError(t, err, name)
+		assert.Equal(t, AZUREDEVOPS, serviceType, name)
+	}
+	serviceType, err := parseServiceType("GitHub")
+	require.NoError(t, err)
+	assert.Equal(t, GITHUB, serviceType)
+}
+
+func TestParseServiceType_SuggestsTheNearestService(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, typoErr := parseServiceType("guthub")
+	_, unknownErr := parseServiceType("sourcehut")
+
+	// Assert
+	require.ErrorIs(t, typoErr, ErrUnknownService)
+	assert.Equal(
+		t,
+		`unknown service: "guthub" (supported: gitlab, github, bitbucket, codecommit, azure-devops), did you mean "github"?`,
+		typoErr.Error(),
+	)
+	require.ErrorIs(t, unknownErr, ErrUnknownService)
+	assert.NotContains(t, unknownErr.Error(), "did you mean", "no service is near enough")
+}
+
+func TestGetEditDistance(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, getEditDistance("github", "github"))
+	assert.Equal(t, 1, getEditDistance("guthub", "github"))
+	assert.Equal(t, 2, getEditDistance("gitlba", "gitlab"))
+	assert.Equal(t, 3, getEditDistance("", "ado"))
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+)
+
+// modes of the signature of the bump commits
+const (
+	signCommitsAuto   = "auto"   // follow "commit.gpgsign" of the repository or global Git config
+	signCommitsAlways = "always" // sign with the configured key, regardless of the Git config
+	signCommitsNever  = "never"  // never sign, regardless of the Git config
+)
+
+// signingFormatSSH is the "gpg.format" of the SSH signatures
+const signingFormatSSH = "ssh"
+
+var (
+	ErrInvalidSignCommitsMode = errors.New("invalid sign_commits mode")
+	ErrMissingSigningKey      = errors.New("sign_commits is \"always\", but no signing key is configured")
+)
+
+// validateSignCommitsMode checks whether the signature mode of the bump commits is supported
+func validateSignCommitsMode(mode string) error {
+	switch mode {
+	case "", signCommitsAuto, signCommitsAlways, signCommitsNever:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: %q (expected %q, %q or %q)",
+			ErrInvalidSignCommitsMode, mode, signCommitsAuto, signCommitsAlways, signCommitsNever,
+		)
+	}
+}
+
+// getSignCommitsMode returns the signature mode of the project, which overrides the global one
+func getSignCommitsMode(globalConfig *GlobalConfig, projectConfig *ProjectConfig) string {
+	mode := projectConfig.SignCommits
+	if mode == "" {
+		mode = globalConfig.SignCommits
+	}
+	if mode == "" {
+		return signCommitsAuto
+	}
+	return mode
+}
+
+// validateSignCommits checks the signature modes of the configuration. The projects always signing need a key,
+// from "gpg_key_path", "ssh_key_path" or "user.signingkey" of the global Git config (the clones have no config
+// of their own).
+func validateSignCommits(globalConfig *GlobalConfig, getSigningKeyID func() string) error {
+	err := validateSignCommitsMode(globalConfig.SignCommits)
+	if err != nil {
+		return err
+	}
+
+	alwaysSigning := globalConfig.SignCommits == signCommitsAlways
+	for index := range globalConfig.Projects {
+		projectConfig := &globalConfig.Projects[index]
+		err = validateSignCommitsMode(projectConfig.SignCommits)
+		if err != nil {
+			return fmt.Errorf("projects[%d]: %w", index, err)
+		}
+		alwaysSigning = alwaysSigning || getSignCommitsMode(globalConfig, projectConfig) == signCommitsAlways
+	}
+
+	if alwaysSigning && globalConfig.GpgKeyPath == "" && globalConfig.SSHKeyPath == "" && getSigningKeyID() == "" {
+		return fmt.Errorf(
+			"%w: set gpg_key_path, ssh_key_path or user.signingkey in the global Git config", ErrMissingSigningKey,
+		)
+	}
+	return nil
+}
+
+// getGlobalSigningKeyID returns the signing key of the global Git config, if any
+func getGlobalSigningKeyID() string {
+	globalGitConfig, err := getGlobalGitConfig()
+	if err != nil {
+		return ""
+	}
+	return globalGitConfig.Raw.Section("user").Option("signingkey")
+}
+
+// isSigningCommit tells whether the bump commit is signed, following the Git config in the "auto" mode
+func isSigningCommit(mode string, cfg, globalGitConfig *config.Config) bool {
+	switch mode {
+	case signCommitsAlways:
+		return true
+	case signCommitsNever:
+		return false
+	default:
+		return getOptionFromConfig(cfg, globalGitConfig, "commit", "gpgsign") == "true"
+	}
+}
+
+// isSSHSigning tells whether the commits are signed with an SSH key, when "gpg.format" is "ssh" in the Git config
+// or when "ssh_key_path" is set without any other format
+func isSSHSigning(globalConfig *GlobalConfig, cfg, globalGitConfig *config.Config) bool {
+	gpgFormat := getOptionFromConfig(cfg, globalGitConfig, "gpg", "format")
+	return gpgFormat == signingFormatSSH || (gpgFormat == "" && globalConfig.SSHKeyPath != "")
+}
+
+// getCommitSigner returns the signer of the bump commit, with an SSH or a GPG key, or nil when it isn't signed
+func getCommitSigner(ctx *RepoContext, cfg *config.Config) (git.Signer, error) {
+	mode := getSignCommitsMode(ctx.globalConfig, ctx.projectConfig)
+	if isSigningCommit(mode, cfg, ctx.globalGitConfig) && isSSHSigning(ctx.globalConfig, cfg, ctx.globalGitConfig) {
+		keyPath, err := getSSHSigningKeyPath(
+			ctx.globalConfig.SSHKeyPath, getOptionFromConfig(cfg, ctx.globalGitConfig, "user", "signingkey"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := getSSHSigner(keyPath, ctx.logger())
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	signKey, err := getCommitSignKey(ctx, cfg)
+	if err != nil || signKey == nil {
+		return nil, err
+	}
+	return &gpgSigner{entity: signKey}, nil
+}
+
+// getCommitSignKey returns the GPG key signing the bump commit or the release tag, or nil when it isn't signed.
+// The tags can't be signed with SSH keys, so they aren't signed when the commits are.
+func getCommitSignKey(ctx *RepoContext, cfg *config.Config) (*openpgp.Entity, error) {
+	mode := getSignCommitsMode(ctx.globalConfig, ctx.projectConfig)
+	if !isSigningCommit(mode, cfg, ctx.globalGitConfig) {
+		if mode == signCommitsNever {
+			ctx.logger().Debugf("Not signing the commit, sign_commits is %q", mode)
+		}
+		return nil, nil
+	}
+	if isSSHSigning(ctx.globalConfig, cfg, ctx.globalGitConfig) {
+		ctx.logger().Warn("The tags can't be signed with SSH keys, so the tag isn't signed")
+		return nil, nil
+	}
+
+	ctx.logger().Info("Signing commit with GPG key")
+	gpgKeyID := getOptionFromConfig(cfg, ctx.globalGitConfig, "user", "signingkey")
+	gpgKeyReader, err := getGpgKeyReader(gpgKeyID, ctx.globalConfig.GpgKeyPath, ctx.logger())
+	if err != nil {
+		return nil, err
+	}
+	return getGpgKey(*gpgKeyReader, ctx.logger())
+}
+
+// gpgSigner signs the commits with a GPG key, like go-git does with the "SignKey" of the commit
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+// Sign returns the armored detached GPG signature of the encoded commit
+func (s *gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buffer, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("failed to sign with the GPG key: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSigningCommit_FollowsModeAndGitConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		mode     string
+		repo     string // the local path has the repository config, the temporary clones have none
+		global   string
+		expected bool
+	}{
+		{"auto with gpgsign in the repository", signCommitsAuto, "[commit]\n\tgpgsign = true\n", "", true},
+		{"auto with gpgsign in the global config", signCommitsAuto, "", "[commit]\n\tgpgsign = true\n", true},
+		{"auto without gpgsign", signCommitsAuto, "", "", false},
+		{"auto with SSH signatures", signCommitsAuto, "[commit]\n\tgpgsign = true\n[gpg]\n\tformat = ssh\n", "", true},
+		{"always in a temporary clone", signCommitsAlways, "", "", true},
+		{"never with gpgsign in the repository", signCommitsNever, "[commit]\n\tgpgsign = true\n", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			repoConfig := newGitConfig(t, test.repo)
+			globalGitConfig := newGitConfig(t, test.global)
+
+			// Act
+			signing := isSigningCommit(test.mode, repoConfig, globalGitConfig)
+
+			// Assert
+			assert.Equal(t, test.expected, signing)
+		})
+	}
+}
+
+func TestGetSignCommitsMode_ProjectOverridesGlobal(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{SignCommits: signCommitsAlways}
+
+	// Act
+	projectMode := getSignCommitsMode(globalConfig, &ProjectConfig{SignCommits: signCommitsNever})
+	inheritedMode := getSignCommitsMode(globalConfig, &ProjectConfig{})
+	defaultMode := getSignCommitsMode(&GlobalConfig{}, &ProjectConfig{})
+
+	// Assert
+	assert.Equal(t, signCommitsNever, projectMode)
+	assert.Equal(t, signCommitsAlways, inheritedMode)
+	assert.Equal(t, signCommitsAuto, defaultMode)
+}
+
+func TestValidateSignCommits_RejectsInvalidMode(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{Projects: []ProjectConfig{{SignCommits: "sometimes"}}}
+
+	// Act
+	err := validateSignCommits(globalConfig, func() string { return "" })
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidSignCommitsMode)
+	assert.Contains(t, err.Error(), "projects[0]")
+}
+
+func TestValidateSignCommits_AlwaysRequiresSigningKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		globalConfig *GlobalConfig
+		signingKeyID string
+		expectedErr  error
+	}{
+		{
+			name:         "without any key",
+			globalConfig: &GlobalConfig{Projects: []ProjectConfig{{SignCommits: signCommitsAlways}}},
+			expectedErr:  ErrMissingSigningKey,
+		},
+		{
+			name:         "with gpg_key_path",
+			globalConfig: &GlobalConfig{SignCommits: signCommitsAlways, GpgKeyPath: "/keys/bump.asc"},
+		},
+		{
+			name:         "with ssh_key_path",
+			globalConfig: &GlobalConfig{SignCommits: signCommitsAlways, SSHKeyPath: "~/.ssh/id_ed25519"},
+		},
+		{
+			name:         "with user.signingkey",
+			globalConfig: &GlobalConfig{SignCommits: signCommitsAlways},
+			signingKeyID: "ABCDEF0123456789",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := validateSignCommits(test.globalConfig, func() string { return test.signingKeyID })
+
+			// Assert
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// run "go test ./cmd/autobump -run Snapshot -update" to regenerate the golden files
+var updateGoldens = flag.Bool("update", false, "update the golden files of the snapshot tests")
+
+// snapshotIgnoredHeaders are set by the HTTP transport and don't depend on the provider code
+var snapshotIgnoredHeaders = map[string]bool{"Accept-Encoding": true, "Content-Length": true}
+
+// snapshotRedactedHeaders carry the credentials, which must never be written to the golden files
+var snapshotRedactedHeaders = map[string]bool{"Authorization": true, "Private-Token": true}
+
+// snapshotFixture is the fixed configuration used to render the provider requests
+var snapshotFixture = struct {
+	globalConfig *GlobalConfig
+	sourceBranch string
+	targetBranch string
+	newVersion   string
+}{
+	globalConfig: &GlobalConfig{
+		UserAgent: "autobump/snapshot",
+	},
+	sourceBranch: "chore/bump-1.1.0",
+	targetBranch: "main",
+	newVersion:   "1.1.0",
+}
+
+// renderRequestSnapshot renders the method, URL, headers (credentials redacted) and JSON body of a request
+func renderRequestSnapshot(t *testing.T, method string, url string, header http.Header, body []byte) string {
+	t.Helper()
+
+	var builder strings.Builder
+	builder.WriteString(method + " " + url + "\n")
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if !snapshotIgnoredHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := header.Get(name)
+		if snapshotRedactedHeaders[name] {
+			value = "***"
+		}
+		builder.WriteString(name + ": " + value + "\n")
+	}
+
+	if len(body) > 0 {
+		var indented bytes.Buffer
+		require.NoError(t, json.Indent(&indented, body, "", "  "))
+		builder.WriteString("\n" + indented.String() + "\n")
+	}
+
+	return builder.String()
+}
+
+// assertGolden compares the snapshot with the golden file, or rewrites it when the update flag is set
+func assertGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name+".golden")
+	if *updateGoldens {
+		require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0o755))
+		require.NoError(t, os.WriteFile(goldenPath, []byte(actual), 0o600))
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	require.NoError(t, err, "run the tests with -update to create the golden file")
+	assert.Equal(t, string(expected), actual)
+}
+
+// newCapturingGitLabClient creates a GitLab client sending the requests to a local server that records them
+func newCapturingGitLabClient(t *testing.T, responseBody string) (*gitlab.Client, *[]string) {
+	t.Helper()
+
+	var snapshots []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		snapshots = append(
+			snapshots,
+			renderRequestSnapshot(t, r.Method, "https://gitlab.com"+r.URL.RequestURI(), r.Header, body),
+		)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gitlab.NewClient(
+		"glpat-secret",
+		gitlab.WithBaseURL(server.URL),
+		gitlab.WithHTTPClient(newAPIClient(snapshotFixture.globalConfig)),
+	)
+	require.NoError(t, err)
+	return client, &snapshots
+}
+
+func TestSnapshot_GitLabGetProject(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, snapshots := newCapturingGitLabClient(t, `{"id": 42}`)
+
+	// Act
+	_, _, err := client.Projects.GetProject("group/subgroup/project", &gitlab.GetProjectOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, *snapshots, 1)
+	assertGolden(t, "gitlab_get_project", (*snapshots)[0])
+}
+
+func TestSnapshot_GitLabCreateMergeRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client, snapshots := newCapturingGitLabClient(t, `{"iid": 1}`)
+	options := buildGitLabMergeRequestOptions(
+		snapshotFixture.sourceBranch,
+		snapshotFixture.targetBranch,
+		getCommitSubject(&GlobalConfig{}, &ProjectConfig{}, &projectState{}, snapshotFixture.newVersion, standardLogEntry()),
+		"",
+	)
+
+	// Act
+	_, _, err := client.MergeRequests.CreateMergeRequest(42, options)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, *snapshots, 1)
+	assertGolden(t, "gitlab_create_merge_request", (*snapshots)[0])
+}
+
+func TestSnapshot_AzureDevOpsGetRepository(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	req, err := buildAzureDevOpsRepositoryRequest(context.Background(), "org", "project", "repo", "pat-secret")
+
+	// Assert
+	require.NoError(t, err)
+	assertGolden(
+		t,
+		"azuredevops_get_repository",
+		renderRequestSnapshot(t, req.Method, req.URL.String(), req.Header, nil),
+	)
+}
+
+func TestSnapshot_AzureDevOpsCreatePullRequest(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	azureInfo := AzureDevOpsInfo{OrganizationName: "org", ProjectName: "project", RepositoryID: "repo-id"}
+
+	// Act
+	req, err := buildAzureDevOpsPullRequestRequest(
+		context.Background(),
+		azureInfo,
+		"pat-secret",
+		snapshotFixture.sourceBranch,
+		snapshotFixture.targetBranch,
+		getCommitSubject(&GlobalConfig{}, &ProjectConfig{}, &projectState{}, snapshotFixture.newVersion, standardLogEntry()),
+		"",
+	)
+
+	// Assert
+	require.NoError(t, err)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assertGolden(
+		t,
+		"azuredevops_create_pull_request",
+		renderRequestSnapshot(t, req.Method, req.URL.String(), req.Header, body),
+	)
+}
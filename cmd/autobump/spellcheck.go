@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	lintModeWarning = "warning"
+	lintModeError   = "error"
+
+	// dictionaryFileName is the file of the project with its own words, one per line
+	dictionaryFileName = ".autobump-dictionary.txt"
+)
+
+var (
+	ErrInvalidLintMode = errors.New("invalid lint_mode")
+	ErrChangelogLint   = errors.New("the CHANGELOG has lint errors")
+)
+
+// englishWords is the word list used by the spell check, common English along with the usual technical terms
+//
+//go:embed words/english.txt
+var englishWords string
+
+var (
+	englishDictionary     map[string]bool
+	englishDictionaryOnce sync.Once
+
+	// spellcheckSkippedRegex matches the parts of an entry which are not prose: code spans, URLs (also of the
+	// Markdown links and the autolinks) and e-mail addresses
+	spellcheckSkippedRegex = regexp.MustCompile("`[^`]*`|\\]\\([^)]*\\)|<[^>]*>|https?://\\S+|\\S+@\\S+\\.\\S+")
+	// spellcheckWordRegex matches the words, along with their apostrophes (e.g. "doesn't")
+	spellcheckWordRegex = regexp.MustCompile(`[\p{L}]+(?:['’][\p{L}]+)*`)
+)
+
+// spellcheckSuffixes are removed from the unknown words to find them in the dictionary (e.g. "bumps" and "bumping")
+var spellcheckSuffixes = []string{"'s", "’s", "n't", "n’t", "s", "es", "ed", "d", "ing", "ly"}
+
+// validateLintMode validates the level of the CHANGELOG lint findings
+func validateLintMode(mode string) error {
+	switch mode {
+	case "", lintModeWarning, lintModeError:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q (expected %q or %q)", ErrInvalidLintMode, mode, lintModeWarning, lintModeError)
+	}
+}
+
+// getEnglishDictionary returns the embedded word list, parsed on the first use
+func getEnglishDictionary() map[string]bool {
+	englishDictionaryOnce.Do(func() {
+		englishDictionary = make(map[string]bool)
+		for _, word := range strings.Fields(englishWords) {
+			englishDictionary[word] = true
+		}
+	})
+	return englishDictionary
+}
+
+// loadProjectDictionary reads the words of the project dictionary (if any) along with the ignored words
+// of the configuration, lines starting with "#" are comments
+func loadProjectDictionary(projectPath string, ignoredWords []string) (map[string]bool, error) {
+	dictionary := make(map[string]bool, len(ignoredWords))
+	for _, word := range ignoredWords {
+		dictionary[strings.ToLower(word)] = true
+	}
+
+	file, err := os.Open(filepath.Join(projectPath, dictionaryFileName))
+	if os.IsNotExist(err) {
+		return dictionary, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", dictionaryFileName, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" && !strings.HasPrefix(word, "#") {
+			dictionary[strings.ToLower(word)] = true
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dictionaryFileName, err)
+	}
+	return dictionary, nil
+}
+
+// spellcheckUnreleased checks the words of the "Unreleased" entries, skipping the headings and the code blocks,
+// and returns a finding for each line with unknown words
+func spellcheckUnreleased(
+	lines []string,
+	unreleasedLine int,
+	dictionary map[string]bool,
+	changelogFile string,
+	level string,
+) []Finding {
+	if unreleasedLine <= 0 {
+		return nil
+	}
+
+	var findings []Finding
+	insideCodeBlock := false
+	for index := unreleasedLine; index < len(lines); index++ {
+		line := lines[index]
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "## ") {
+			break
+		}
+		if isCodeFence(line) {
+			insideCodeBlock = !insideCodeBlock
+			continue
+		}
+		if insideCodeBlock || trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		unknownWords := findUnknownWords(trimmedLine, dictionary)
+		if len(unknownWords) > 0 {
+			findings = append(findings, Finding{
+				Level:   level,
+				File:    changelogFile,
+				Line:    index + 1,
+				Message: fmt.Sprintf("possible typos: %s", strings.Join(unknownWords, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+// findUnknownWords returns the words of the text which are neither in the English nor in the project dictionary.
+// The acronyms and the words with inner capitals (e.g. "API" and "GitHub") are names and never checked.
+func findUnknownWords(text string, dictionary map[string]bool) []string {
+	var unknownWords []string
+	for _, word := range spellcheckWordRegex.FindAllString(spellcheckSkippedRegex.ReplaceAllString(text, " "), -1) {
+		runes := []rune(word)
+		if len(runes) < 3 || strings.ToLower(string(runes[1:])) != string(runes[1:]) {
+			continue
+		}
+
+		if !isKnownWord(strings.ToLower(word), dictionary) && !slices.Contains(unknownWords, word) {
+			unknownWords = append(unknownWords, word)
+		}
+	}
+	return unknownWords
+}
+
+// isKnownWord checks whether the word (or its stem) is in the English or in the project dictionary
+func isKnownWord(word string, dictionary map[string]bool) bool {
+	englishDictionary := getEnglishDictionary()
+	isKnown := func(candidate string) bool {
+		return englishDictionary[candidate] || dictionary[candidate]
+	}
+
+	if isKnown(word) {
+		return true
+	}
+	for _, suffix := range spellcheckSuffixes {
+		stem, found := strings.CutSuffix(word, suffix)
+		if found && len(stem) > 1 && (isKnown(stem) || isKnown(stem+"e")) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintUnreleased reports the findings of the enabled lint rules about the "Unreleased" entries,
+// failing when they are errors
+func lintUnreleased(ctx *RepoContext, lines []string, unreleasedLine int, changelogFile string) error {
+	lintConfig := ctx.globalConfig.ChangelogLint
+	findings, err := getLintFindings(lintConfig, ctx.projectConfig.Path, lines, unreleasedLine, changelogFile)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		reportFinding(finding, ctx.logger())
+	}
+	if lintConfig.LintMode == lintModeError && len(findings) > 0 {
+		return fmt.Errorf("%w: %d lines with possible typos in %s", ErrChangelogLint, len(findings), changelogFile)
+	}
+	return nil
+}
+
+// getLintFindings returns the findings of the enabled lint rules about the "Unreleased" entries,
+// with the words of the dictionary of the project
+func getLintFindings(
+	lintConfig ChangelogLintConfig,
+	projectPath string,
+	lines []string,
+	unreleasedLine int,
+	changelogFile string,
+) ([]Finding, error) {
+	if !lintConfig.Spellcheck {
+		return nil, nil
+	}
+
+	dictionary, err := loadProjectDictionary(projectPath, lintConfig.IgnoreWords)
+	if err != nil {
+		return nil, err
+	}
+
+	level := findingWarning
+	if lintConfig.LintMode == lintModeError {
+		level = findingError
+	}
+	return spellcheckUnreleased(lines, unreleasedLine, dictionary, changelogFile, level), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogWithTypos = changelogTemplate + `
+
+### Added
+
+- Added the export of the reports to [the storage](https://example.com/storge/docs).
+- Added the ` + "`recieve_timeout`" + ` option, see https://example.com/recieve and <https://example.com/adress>.
+- Fixed the redirection wich was broken for GitHub and API users.
+
+` + "```yaml\nseperate: true\n```" + `
+
+### Fixed
+
+- Fixed the Kubernetes probes untill the startup.
+
+## [1.0.0] - 1984-01-01
+
+### Added
+
+- Added the recieve feature.`
+
+func TestSpellcheckUnreleased_FindsTyposWithLines(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithTypos, "\n")
+
+	// Act
+	findings := spellcheckUnreleased(lines, 8, map[string]bool{}, "CHANGELOG.md", findingWarning)
+
+	// Assert
+	assert.Equal(t, []Finding{
+		{Level: findingWarning, File: "CHANGELOG.md", Line: 14, Message: "possible typos: wich"},
+		{Level: findingWarning, File: "CHANGELOG.md", Line: 22, Message: "possible typos: Kubernetes, untill"},
+	}, findings)
+}
+
+func TestSpellcheckUnreleased_ProjectDictionary(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(projectPath, dictionaryFileName), []byte("# names\nkubernetes\n\n"), 0o600,
+	))
+	lines := strings.Split(changelogWithTypos, "\n")
+
+	// Act
+	dictionary, err := loadProjectDictionary(projectPath, []string{"Wich"})
+	findings := spellcheckUnreleased(lines, 8, dictionary, "CHANGELOG.md", findingError)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{Level: findingError, File: "CHANGELOG.md", Line: 22, Message: "possible typos: untill"},
+	}, findings)
+}
+
+func TestLoadProjectDictionary_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	dictionary, err := loadProjectDictionary(t.TempDir(), nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, dictionary)
+}
+
+func TestFindUnknownWords_Inflections(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	unknownWords := findUnknownWords("Bumped the versions, it doesn't stop the running jobs anymore", nil)
+
+	// Assert
+	assert.Empty(t, unknownWords)
+}
+
+func TestValidateLintMode(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	validErr := validateLintMode(lintModeError)
+	invalidErr := validateLintMode("fatal")
+
+	// Assert
+	require.NoError(t, validErr)
+	require.ErrorIs(t, invalidErr, ErrInvalidLintMode)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const (
+	// sshSignatureMagic starts the SSH signatures and the data they sign, see the PROTOCOL.sshsig of OpenSSH
+	sshSignatureMagic = "SSHSIG"
+	// sshSignatureNamespace is the namespace of the signatures of the Git objects, checked by "git verify-commit"
+	sshSignatureNamespace = "git"
+	// sshSignatureHashAlgorithm is the hash of the signed data, the default of "ssh-keygen -Y sign"
+	sshSignatureHashAlgorithm = "sha512"
+	// sshSignatureLineLength is the length of the lines of the armored signature
+	sshSignatureLineLength = 70
+)
+
+var ErrInvalidSSHSigningKey = errors.New("invalid SSH signing key")
+
+// sshSigner signs the commits with an SSH key, like "git commit -S" does with "gpg.format = ssh"
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+// Sign returns the armored SSH signature of the encoded commit
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	hash := sha512.New()
+	if _, err := io.Copy(hash, message); err != nil {
+		return nil, fmt.Errorf("failed to read the signed object: %w", err)
+	}
+
+	// the signed data wraps the hash of the message, so the signatures of the other namespaces can't be reused
+	signedData := ssh.Marshal(struct {
+		Magic         [6]byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          string
+	}{
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: sshSignatureHashAlgorithm,
+		Hash:          string(hash.Sum(nil)),
+	})
+	copy(signedData, sshSignatureMagic)
+
+	signature, err := signSSHData(s.signer, signedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with the SSH key: %w", err)
+	}
+
+	blob := ssh.Marshal(struct {
+		Magic         [6]byte
+		Version       uint32
+		PublicKey     string
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     string
+	}{
+		Version:       1,
+		PublicKey:     string(s.signer.PublicKey().Marshal()),
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: sshSignatureHashAlgorithm,
+		Signature:     string(ssh.Marshal(signature)),
+	})
+	copy(blob, sshSignatureMagic)
+	return armorSSHSignature(blob), nil
+}
+
+// signSSHData signs the data, with SHA-512 for the RSA keys since their SHA-1 signatures are refused
+func signSSHData(signer ssh.Signer, data []byte) (*ssh.Signature, error) {
+	if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		return algorithmSigner.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA512)
+	}
+	return signer.Sign(rand.Reader, data)
+}
+
+// armorSSHSignature writes the signature between the "SSH SIGNATURE" markers, in lines of 70 characters
+func armorSSHSignature(blob []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var buffer bytes.Buffer
+	buffer.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > sshSignatureLineLength {
+		buffer.WriteString(encoded[:sshSignatureLineLength] + "\n")
+		encoded = encoded[sshSignatureLineLength:]
+	}
+	buffer.WriteString(encoded + "\n")
+	buffer.WriteString("-----END SSH SIGNATURE-----\n")
+	return buffer.Bytes()
+}
+
+// getSSHSigningKeyPath returns the private key signing the commits, from "ssh_key_path" or from the
+// "user.signingkey" of the Git config. The public keys (".pub") point to the private key next to them.
+func getSSHSigningKeyPath(sshKeyPath string, signingKey string) (string, error) {
+	keyPath := firstNonEmpty(sshKeyPath, signingKey)
+	switch {
+	case keyPath == "":
+		return "", fmt.Errorf("%w: set ssh_key_path or user.signingkey in the global Git config", ErrMissingSigningKey)
+	case strings.HasPrefix(keyPath, "key::"):
+		return "", fmt.Errorf(
+			"%w: user.signingkey is a literal public key, set ssh_key_path with its private key",
+			ErrInvalidSSHSigningKey,
+		)
+	}
+
+	if strings.HasPrefix(keyPath, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			keyPath = filepath.Join(homeDir, keyPath[2:])
+		}
+	}
+	return strings.TrimSuffix(keyPath, ".pub"), nil
+}
+
+// getSSHSigner reads the private SSH key signing the commits, prompting for its passphrase when it has one
+func getSSHSigner(keyPath string, logger *log.Entry) (*sshSigner, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the SSH signing key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	var passphraseMissing *ssh.PassphraseMissingError
+	if errors.As(err, &passphraseMissing) {
+		fmt.Print("Enter the passphrase for your SSH key: ") //nolint:forbidigo // this line is not for debugging
+		var passphrase []byte
+		passphrase, err = term.ReadPassword(0)
+		fmt.Println() //nolint:forbidigo // this line is not for debugging
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSSHSigningKey, keyPath, err)
+	}
+
+	logger.Infof("Signing commit with SSH key %s", ssh.FingerprintSHA256(signer.PublicKey()))
+	return &sshSigner{signer: signer}, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// writeSSHKey writes a new ed25519 private key in the OpenSSH format, returning its path
+func writeSSHKey(t *testing.T) string {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	return keyPath
+}
+
+// verifySSHSignature checks the armored signature of the message like "ssh-keygen -Y verify -n git" does
+func verifySSHSignature(t *testing.T, publicKey ssh.PublicKey, armored string, message string) {
+	t.Helper()
+
+	body := strings.TrimPrefix(strings.TrimSpace(armored), "-----BEGIN SSH SIGNATURE-----")
+	body = strings.TrimSuffix(body, "-----END SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\n", ""))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(blob, []byte(sshSignatureMagic)))
+
+	var envelope struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	require.NoError(t, ssh.Unmarshal(blob[len(sshSignatureMagic):], &envelope))
+	assert.Equal(t, publicKey.Marshal(), envelope.PublicKey)
+	assert.Equal(t, sshSignatureNamespace, envelope.Namespace)
+
+	var signature ssh.Signature
+	require.NoError(t, ssh.Unmarshal(envelope.Signature, &signature))
+	hash := sha512.Sum512([]byte(message))
+	signedData := ssh.Marshal(struct {
+		Magic         [6]byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          string
+	}{Namespace: sshSignatureNamespace, HashAlgorithm: sshSignatureHashAlgorithm, Hash: string(hash[:])})
+	copy(signedData, sshSignatureMagic)
+	require.NoError(t, publicKey.Verify(signedData, &signature))
+}
+
+func TestCommitChanges_SSHSignature(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "CHANGELOG.md"), []byte(changelogTemplate), 0o600))
+	_, err = worktree.Add("CHANGELOG.md")
+	require.NoError(t, err)
+	signer, err := getSSHSigner(writeSSHKey(t), standardLogEntry())
+	require.NoError(t, err)
+	// Act
+	hash, err := commitChanges(
+		worktree, "chore(bump): bumped version to 1.0.1", "", signer, "AutoBump", "autobump@example.com", time.Now(),
+		standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	require.Contains(t, commit.PGPSignature, "-----BEGIN SSH SIGNATURE-----")
+	encoded := &plumbing.MemoryObject{}
+	require.NoError(t, commit.EncodeWithoutSignature(encoded))
+	reader, err := encoded.Reader()
+	require.NoError(t, err)
+	message, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	verifySSHSignature(t, signer.signer.PublicKey(), commit.PGPSignature, string(message))
+}
+
+func TestGetSSHSigningKeyPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sshKeyPath  string
+		signingKey  string
+		expected    string
+		expectedErr error
+	}{
+		{name: "should prefer ssh_key_path", sshKeyPath: "/keys/bump", signingKey: "/keys/other", expected: "/keys/bump"},
+		{name: "should read the private key of user.signingkey", signingKey: "/keys/bump.pub", expected: "/keys/bump"},
+		{
+			name:        "should refuse the literal public keys",
+			signingKey:  "key::ssh-ed25519 AAAA",
+			expectedErr: ErrInvalidSSHSigningKey,
+		},
+		{name: "should require a key", expectedErr: ErrMissingSigningKey},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			keyPath, err := getSSHSigningKeyPath(test.sshKeyPath, test.signingKey)
+
+			// Assert
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, keyPath)
+		})
+	}
+}
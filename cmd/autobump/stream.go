@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// exit codes of the "changelog process" command, telling the callers why nothing was released
+const (
+	exitCodeSuccess          = 0
+	exitCodeFailure          = 1
+	exitCodeEmptyUnreleased  = 2
+	exitCodeInvalidChangelog = 3
+)
+
+// runChangelogProcess releases the unreleased changes of the CHANGELOG read from the input,
+// writing the new CHANGELOG to the output and the new version to the version output (or file),
+// it doesn't touch any repository and returns the exit code of the command
+func runChangelogProcess(config *Config, input io.Reader, output io.Writer, versionOutput io.Writer) int {
+	globalConfig, err := readStandaloneConfig(config.configPath)
+	if err != nil {
+		log.Errorf("Failed to read config: %v", err)
+		return exitCodeFailure
+	}
+
+	changelogConfig := globalConfig.Changelog
+	changelogConfig.Date = getReleaseDate(globalConfig)
+	changelogConfig.VersionPolicy = globalConfig.VersionPolicy
+	if config.ignoreConflictMarkers {
+		changelogConfig.IgnoreConflictMarkers = true
+	}
+
+	nextVersion, err := processChangelogStream(input, output, changelogConfig, getMaxFileSize(globalConfig))
+	switch {
+	case errors.Is(err, ErrNoChangesFoundInUnreleased):
+		log.Warnf("Nothing to release: %v", err)
+		return exitCodeEmptyUnreleased
+	case errors.Is(err, ErrFileTooLarge):
+		log.Errorf("Failed to read the CHANGELOG: %v", err)
+		return exitCodeFailure
+	case err != nil:
+		log.Errorf("Failed to process the CHANGELOG: %v", err)
+		return exitCodeInvalidChangelog
+	}
+
+	if config.versionOut != "" {
+		err = os.WriteFile(config.versionOut, []byte(versionString(nextVersion)+"\n"), 0o644) //nolint:gosec // not a secret
+		if err != nil {
+			log.Errorf("Failed to write the version file: %v", err)
+			return exitCodeFailure
+		}
+		return exitCodeSuccess
+	}
+
+	fmt.Fprintln(versionOutput, versionString(nextVersion))
+	return exitCodeSuccess
+}
+
+// readStandaloneConfig reads the CHANGELOG settings from the config file (if any), skipping the validations
+// that need a project or the network, since the standalone commands work only with the CHANGELOG content
+func readStandaloneConfig(configPath string) (*GlobalConfig, error) {
+	if configPath == "" {
+		var err error
+		configPath, err = findConfig()
+		if err != nil {
+			log.Info("No config file found, using the default CHANGELOG settings")
+			return &GlobalConfig{}, nil
+		}
+	}
+
+	globalConfig, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadReleaseDate(globalConfig)
+	if err != nil {
+		return nil, err
+	}
+	return globalConfig, nil
+}
+
+// processChangelogStream reads a whole CHANGELOG from the reader and writes it with the unreleased changes released
+func processChangelogStream(
+	reader io.Reader,
+	writer io.Writer,
+	changelogConfig ChangelogConfig,
+	maxFileSize int64,
+) (*semver.Version, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CHANGELOG: %w", err)
+	}
+	if int64(len(data)) > maxFileSize {
+		return nil, fmt.Errorf("%w: the input (limit is %s)", ErrFileTooLarge, formatBytes(maxFileSize))
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CHANGELOG: %w", err)
+	}
+
+	if isLFSPointer(lines) {
+		return nil, ErrChangelogIsLFSPointer
+	}
+
+	nextVersion, newLines, err := processChangelog(lines, changelogConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferedWriter := bufio.NewWriter(writer)
+	for _, line := range newLines {
+		fmt.Fprintln(bufferedWriter, line)
+	}
+	err = bufferedWriter.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the CHANGELOG: %w", err)
+	}
+	return nextVersion, nil
+}
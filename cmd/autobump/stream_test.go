@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStandaloneConfig writes a config file with only the CHANGELOG settings
+func newStandaloneConfig(t *testing.T) *Config {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "autobump.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("changelog:\n  normalize_entries: true\n"), 0o600))
+	return &Config{configPath: configPath, fromStdin: true}
+}
+
+func TestRunChangelogProcess_Success(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	config := newStandaloneConfig(t)
+	var output, versionOutput bytes.Buffer
+
+	// Act
+	exitCode := runChangelogProcess(config, strings.NewReader(changelogOriginal), &output, &versionOutput)
+
+	// Assert
+	assert.Equal(t, exitCodeSuccess, exitCode)
+	assert.Equal(t, fmt.Sprintf(changelogExpected, time.Now().Format("2006-01-02"))+"\n", output.String())
+	assert.Equal(t, "1.1.0\n", versionOutput.String())
+}
+
+func TestRunChangelogProcess_VersionOut(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	config := newStandaloneConfig(t)
+	config.versionOut = filepath.Join(t.TempDir(), "VERSION")
+	var output, versionOutput bytes.Buffer
+
+	// Act
+	exitCode := runChangelogProcess(config, strings.NewReader(changelogOriginal), &output, &versionOutput)
+
+	// Assert
+	assert.Equal(t, exitCodeSuccess, exitCode)
+	assert.Empty(t, versionOutput.String())
+	content, err := os.ReadFile(config.versionOut)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0\n", string(content))
+}
+
+func TestRunChangelogProcess_EmptyUnreleased(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	config := newStandaloneConfig(t)
+	changelog := changelogTemplate + "\n\n## [1.0.1] - 1984-01-01\n\n### Added\n\n- New feature.\n"
+	var output, versionOutput bytes.Buffer
+
+	// Act
+	exitCode := runChangelogProcess(config, strings.NewReader(changelog), &output, &versionOutput)
+
+	// Assert
+	assert.Equal(t, exitCodeEmptyUnreleased, exitCode)
+	assert.Empty(t, output.String())
+	assert.Empty(t, versionOutput.String())
+}
+
+func TestRunChangelogProcess_InvalidChangelog(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	config := newStandaloneConfig(t)
+	changelog := changelogTemplate + "\n\n### Added\n\n- New feature.\n\n## [not-a-version] - 1984-01-01\n"
+	var output, versionOutput bytes.Buffer
+
+	// Act
+	exitCode := runChangelogProcess(config, strings.NewReader(changelog), &output, &versionOutput)
+
+	// Assert
+	assert.Equal(t, exitCodeInvalidChangelog, exitCode)
+	assert.Empty(t, output.String())
+}
+
+func TestProcessChangelogStream_TooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var output bytes.Buffer
+
+	// Act
+	_, err := processChangelogStream(strings.NewReader(changelogOriginal), &output, ChangelogConfig{}, 10)
+
+	// Assert
+	require.ErrorIs(t, err, ErrFileTooLarge)
+	assert.Empty(t, output.String())
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrTagExists = errors.New("the tag already exists on another commit")
+
+// isTagCreated tells whether the releases of the project are tagged, the project setting overriding the global one.
+// The releases published on the remote service are always tagged, since they are published from their tag.
+func isTagCreated(globalConfig *GlobalConfig, projectConfig *ProjectConfig) bool {
+	if isReleaseCreated(globalConfig, projectConfig) {
+		return true
+	}
+	if projectConfig.CreateTag != nil {
+		return *projectConfig.CreateTag
+	}
+	return globalConfig.CreateTag
+}
+
+// getReleaseTagName returns the tag of the version, prefixed with "v" unless the tags of the repository aren't
+func getReleaseTagName(repo *git.Repository, version *semver.Version, logger *log.Entry) string {
+	prefix, found := detectTagVersionPrefix(repo, logger)
+	if !found {
+		prefix = versionPrefixV
+	}
+	return prefix + versionString(version)
+}
+
+// tagLatestRelease tags the latest release of the CHANGELOG when it isn't tagged yet, which happens once its bump
+// was merged. The tag points to the commit releasing it, or to the HEAD when the history is too shallow to find it.
+func tagLatestRelease(ctx *RepoContext, changelogPath string) error {
+	if !isTagCreated(ctx.globalConfig, ctx.projectConfig) {
+		return nil
+	}
+
+	lines, err := readChangelogLines(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+	version, err := findLatestVersion(lines)
+	if errors.Is(err, ErrNoVersionFoundInChangelog) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	tagName := getReleaseTagName(ctx.repo, version, ctx.logger())
+	if _, err = ctx.repo.Tag(tagName); err == nil {
+		ctx.logger().Debugf("The release %s is already tagged", versionString(version))
+		return nil
+	}
+
+	head, err := getTagHeadCommit(ctx)
+	if err != nil {
+		return err
+	}
+	relativePath, err := getWorktreePath(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+	target := findReleaseCommit(ctx.repo, head, relativePath, version)
+	err = createReleaseTag(ctx, lines, version, tagName, target)
+	if err != nil || !isReleaseCreated(ctx.globalConfig, ctx.projectConfig) {
+		return err
+	}
+	return publishRelease(ctx, tagName, getReleaseNotes(lines, version))
+}
+
+// runTag tags the HEAD of the project with its latest release, see tagHead
+func runTag(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
+	ctx := &RepoContext{
+		globalConfig:  globalConfig,
+		projectConfig: projectConfig,
+		timer:         newPhaseTimer(standardLogEntry()),
+	}
+
+	var err error
+	ctx.globalGitConfig, err = getGlobalGitConfig()
+	if err != nil {
+		return err
+	}
+	err = setupRepo(ctx)
+	if err != nil {
+		return err
+	}
+	return tagHead(ctx)
+}
+
+// tagHead tags the HEAD (e.g. the merge commit of the bump, in the pipeline of the default branch) with the latest
+// release of the CHANGELOG, and pushes the tag. It does nothing when the HEAD is already tagged with it.
+func tagHead(ctx *RepoContext) error {
+	lines, err := readLines(findChangelogPath(ctx.projectConfig, ctx.logger()), getMaxFileSize(ctx.globalConfig))
+	if err != nil {
+		return err
+	}
+	version, err := findLatestVersion(lines)
+	if err != nil {
+		return err
+	}
+
+	head, err := getTagHeadCommit(ctx)
+	if err != nil {
+		return err
+	}
+	tagName := getReleaseTagName(ctx.repo, version, ctx.logger())
+	if tag, tagErr := ctx.repo.Tag(tagName); tagErr == nil {
+		hash, resolveErr := ctx.repo.ResolveRevision(plumbing.Revision(tag.Name()))
+		if resolveErr != nil || *hash != head.Hash {
+			return fmt.Errorf("%w: %s", ErrTagExists, tagName)
+		}
+		ctx.logger().Infof("The HEAD is already tagged with %s", tagName)
+		return nil
+	}
+	return createReleaseTag(ctx, lines, version, tagName, head)
+}
+
+// getTagHeadCommit returns the commit of the HEAD, or of the base ref when it is set
+func getTagHeadCommit(ctx *RepoContext) (*object.Commit, error) {
+	if ctx.baseCommit != nil {
+		return ctx.baseCommit, nil
+	}
+	commit, err := ctx.repo.CommitObject(ctx.head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the HEAD commit: %w", err)
+	}
+	return commit, nil
+}
+
+// findReleaseCommit returns the commit adding the release to the CHANGELOG, walking back the commits changing it
+// while they have the release. The walk stops at the end of the shallow histories.
+func findReleaseCommit(
+	repo *git.Repository,
+	head *object.Commit,
+	changelogPath string,
+	version *semver.Version,
+) *object.Commit {
+	iterator, err := repo.Log(&git.LogOptions{From: head.Hash, FileName: &changelogPath})
+	if err != nil {
+		return head
+	}
+	defer iterator.Close()
+
+	releaseCommit := head
+	for {
+		commit, nextErr := iterator.Next()
+		if nextErr != nil {
+			return releaseCommit
+		}
+		file, fileErr := commit.File(changelogPath)
+		if fileErr != nil {
+			return releaseCommit
+		}
+		content, contentErr := file.Contents()
+		if contentErr != nil {
+			return releaseCommit
+		}
+		if line, _ := getReleaseSection(strings.Split(content, "\n"), version); line == 0 {
+			return releaseCommit
+		}
+		releaseCommit = commit
+	}
+}
+
+// createReleaseTag creates the annotated tag of the release on the commit, with the entries of the release as its
+// message, and pushes it. The tag is signed like the bump commits.
+func createReleaseTag(
+	ctx *RepoContext,
+	lines []string,
+	version *semver.Version,
+	tagName string,
+	target *object.Commit,
+) error {
+	cfg, err := ctx.repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to get repo config: %w", err)
+	}
+	signKey, err := getCommitSignKey(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	message := "Release " + tagName
+	if notes := getReleaseNotes(lines, version); notes != "" {
+		message += "\n\n" + notes
+	}
+
+	ctx.logger().Infof("Tagging the release %s on the commit %s", tagName, target.Hash.String()[:7])
+	_, err = ctx.repo.CreateTag(tagName, target.Hash, &git.CreateTagOptions{
+		Tagger: &object.Signature{
+			Name:  ctx.globalGitConfig.Raw.Section("user").Option("name"),
+			Email: ctx.globalGitConfig.Raw.Section("user").Option("email"),
+			When:  getReleaseDate(ctx.globalConfig),
+		},
+		Message: message,
+		SignKey: signKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the tag %s: %w", tagName, err)
+	}
+
+	refSpec := config.RefSpec(plumbing.NewTagReferenceName(tagName) + ":" + plumbing.NewTagReferenceName(tagName))
+	return pushRefSpec(ctx, refSpec, nil)
+}
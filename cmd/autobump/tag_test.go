@@ -0,0 +1,149 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changelogTagged is a CHANGELOG with the release 1.0.1, following the release 1.0.0
+const changelogTagged = changelogTemplate + `
+
+## [1.0.1] - 1984-01-02
+
+### Fixed
+
+- Fixed the crash on empty invoices.
+
+## [1.0.0] - 1984-01-01
+`
+
+func TestTagLatestRelease_TagsTheReleaseCommit(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "git://example.test/acme/api.git")
+	commitFile(t, repo, "CHANGELOG.md", changelogTemplate+"\n\n## [1.0.0] - 1984-01-01\n")
+	releaseHash := commitFile(t, repo, "CHANGELOG.md", changelogTagged)
+	commitFile(t, repo, "version.txt", "version=1.0.1\n")
+	commitFile(t, repo, "CHANGELOG.md", changelogTemplate+"\n\n### Added\n\n- Added the export.\n"+
+		changelogTagged[len(changelogTemplate):])
+
+	ctx := &RepoContext{
+		globalConfig:    &GlobalConfig{CreateTag: true},
+		projectConfig:   &ProjectConfig{Path: projectPath, Name: "api"},
+		globalGitConfig: newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+	}
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	err := tagLatestRelease(ctx, findChangelogPath(ctx.projectConfig, standardLogEntry()))
+
+	// Assert
+	require.ErrorIs(t, err, ErrReadOnlyRemoteURL)
+	hash, err := repo.ResolveRevision(plumbing.Revision(plumbing.NewTagReferenceName("v1.0.1")))
+	require.NoError(t, err)
+	assert.Equal(t, releaseHash, *hash)
+	tag, err := repo.Tag("v1.0.1")
+	require.NoError(t, err)
+	tagObject, err := repo.TagObject(tag.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Release v1.0.1\n\n### Fixed\n\n- Fixed the crash on empty invoices.\n", tagObject.Message)
+}
+
+func TestTagLatestRelease_AlreadyTagged(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "git://example.test/acme/api.git")
+	releaseHash := commitFile(t, repo, "CHANGELOG.md", changelogTagged)
+	_, err := repo.CreateTag("1.0.1", releaseHash, nil)
+	require.NoError(t, err)
+	commitFile(t, repo, "version.txt", "version=1.0.1\n")
+
+	ctx := &RepoContext{
+		globalConfig:    &GlobalConfig{CreateTag: true},
+		projectConfig:   &ProjectConfig{Path: projectPath, Name: "api"},
+		globalGitConfig: newGitConfig(t, "[user]\n\tname = AutoBump\n\temail = autobump@example.com\n"),
+	}
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	err = tagLatestRelease(ctx, findChangelogPath(ctx.projectConfig, standardLogEntry()))
+
+	// Assert
+	require.NoError(t, err)
+	_, err = repo.Tag("v1.0.1")
+	require.Error(t, err, "the release should not be tagged twice")
+}
+
+func TestTagHead_TagOnAnotherCommit(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath, repo := newRedirectRepo(t, "git://example.test/acme/api.git")
+	releaseHash := commitFile(t, repo, "CHANGELOG.md", changelogTagged)
+	_, err := repo.CreateTag("v1.0.1", releaseHash, nil)
+	require.NoError(t, err)
+	commitFile(t, repo, "version.txt", "version=1.0.1\n")
+
+	ctx := &RepoContext{globalConfig: &GlobalConfig{}, projectConfig: &ProjectConfig{Path: projectPath, Name: "api"}}
+	require.NoError(t, setupRepo(ctx))
+
+	// Act
+	err = tagHead(ctx)
+
+	// Assert
+	require.ErrorIs(t, err, ErrTagExists)
+}
+
+func TestIsTagCreated(t *testing.T) {
+	t.Parallel()
+
+	enabled, disabled := true, false
+	tests := []struct {
+		name          string
+		globalConfig  *GlobalConfig
+		projectConfig *ProjectConfig
+		expected      bool
+	}{
+		{name: "should not tag by default", globalConfig: &GlobalConfig{}, projectConfig: &ProjectConfig{}},
+		{
+			name:          "should follow the global setting",
+			globalConfig:  &GlobalConfig{CreateTag: true},
+			projectConfig: &ProjectConfig{},
+			expected:      true,
+		},
+		{
+			name:          "should let the project disable the tags",
+			globalConfig:  &GlobalConfig{CreateTag: true},
+			projectConfig: &ProjectConfig{CreateTag: &disabled},
+		},
+		{
+			name:          "should let the project enable the tags",
+			globalConfig:  &GlobalConfig{},
+			projectConfig: &ProjectConfig{CreateTag: &enabled},
+			expected:      true,
+		},
+		{
+			name:          "should tag the published releases",
+			globalConfig:  &GlobalConfig{CreateRelease: true},
+			projectConfig: &ProjectConfig{CreateTag: &disabled},
+			expected:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			created := isTagCreated(test.globalConfig, test.projectConfig)
+
+			// Assert
+			assert.Equal(t, test.expected, created)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultChangelogTemplate is used to create the missing CHANGELOG files when no template is available,
+// it's a minimal version of "configs/CHANGELOG.template.md"
+const defaultChangelogTemplate = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/), ` +
+	`and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [Unreleased]
+
+### Added
+
+-
+
+### Changed
+
+-
+
+### Removed
+
+-
+`
+
+// sources of the new CHANGELOG files, counted in the run report
+const (
+	changelogSourceTemplate = "template" // the template of the project or of the configuration
+	changelogSourceNetwork  = "network"  // the template downloaded for the first CHANGELOG of the run
+	changelogSourceCache    = "cache"    // the template downloaded before in the run
+	changelogSourceEmbedded = "embedded" // the default template, when the download failed
+)
+
+var ErrInvalidChangelogTemplate = errors.New("invalid CHANGELOG template")
+
+// changelogTemplateCache keeps the template downloaded for the new CHANGELOG files in memory, so it is downloaded
+// (or read from the disk cache) once per run, even when it fails, and counts the files created from each source
+type changelogTemplateCache struct {
+	mutex      sync.Mutex
+	downloaded bool
+	content    string
+	created    map[string]int
+}
+
+// get returns the template downloaded from the URL (downloading it the first time) and its source,
+// or the embedded default template when the download failed
+func (c *changelogTemplateCache) get(templateURL string, logger *log.Entry) (string, string) {
+	if c == nil {
+		content, err := loadChangelogTemplate(templateURL)
+		if err != nil {
+			logger.Errorf("It wasn't possible to download the CHANGELOG model file, using the default one: %v", err)
+			return defaultChangelogTemplate, changelogSourceEmbedded
+		}
+		return content, changelogSourceNetwork
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.downloaded {
+		if c.content == "" {
+			return defaultChangelogTemplate, changelogSourceEmbedded
+		}
+		return c.content, changelogSourceCache
+	}
+
+	c.downloaded = true
+	content, err := loadChangelogTemplate(templateURL)
+	if err != nil {
+		logger.Errorf(
+			"It wasn't possible to download the CHANGELOG model file, using the default one for this run: %v", err,
+		)
+		return defaultChangelogTemplate, changelogSourceEmbedded
+	}
+	c.content = content
+	return content, changelogSourceNetwork
+}
+
+// record counts a CHANGELOG file created from the source
+func (c *changelogTemplateCache) record(source string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.created == nil {
+		c.created = make(map[string]int)
+	}
+	c.created[source]++
+}
+
+// getCreated returns the amount of CHANGELOG files created from each source
+func (c *changelogTemplateCache) getCreated() map[string]int {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return maps.Clone(c.created)
+}
+
+// changelogTemplateData holds the variables available in the CHANGELOG templates
+type changelogTemplateData struct {
+	ProjectID   string
+	ProjectName string
+	Date        string
+}
+
+// loadChangelogTemplate reads a CHANGELOG template from a local path or a URL and validates it
+func loadChangelogTemplate(templatePath string) (string, error) {
+	data, err := readData(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read CHANGELOG template %s: %w", templatePath, err)
+	}
+
+	content := string(data)
+	err = validateChangelogTemplate(content)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", templatePath, err)
+	}
+	return content, nil
+}
+
+// validateChangelogTemplate checks whether the template has an "Unreleased" section and valid variables
+func validateChangelogTemplate(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("%w: empty template", ErrInvalidChangelogTemplate)
+	}
+	if !strings.Contains(content, "[Unreleased]") {
+		return fmt.Errorf("%w: missing the [Unreleased] section", ErrInvalidChangelogTemplate)
+	}
+
+	_, err := renderChangelogTemplate(content, changelogTemplateData{})
+	return err
+}
+
+// renderChangelogTemplate replaces the template variables (e.g. {{.ProjectName}} and {{.Date}})
+func renderChangelogTemplate(content string, data changelogTemplateData) ([]byte, error) {
+	tmpl, err := template.New("CHANGELOG").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChangelogTemplate, err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChangelogTemplate, err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// loadChangelogTemplates loads the global and per-project CHANGELOG templates, failing on invalid ones
+func loadChangelogTemplates(globalConfig *GlobalConfig) error {
+	var err error
+	if globalConfig.ChangelogTemplatePath != "" {
+		globalConfig.changelogTemplate, err = loadChangelogTemplate(globalConfig.ChangelogTemplatePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range globalConfig.Projects {
+		projectConfig := &globalConfig.Projects[i]
+		if projectConfig.ChangelogTemplatePath != "" {
+			projectConfig.changelogTemplate, err = loadChangelogTemplate(projectConfig.ChangelogTemplatePath)
+			if err != nil {
+				return fmt.Errorf("projects[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getChangelogContent renders the content of a new CHANGELOG using the project template, the global template,
+// the template downloaded from "changelog_template_url" (the AutoBump repository by default) or the embedded
+// default template, in this order. It returns the source of the content along with it.
+func getChangelogContent(globalConfig *GlobalConfig, projectConfig *ProjectConfig, logger *log.Entry) ([]byte, string) {
+	content, source := projectConfig.changelogTemplate, changelogSourceTemplate
+	if content == "" {
+		content = globalConfig.changelogTemplate
+	}
+	if content == "" {
+		templateURL := globalConfig.ChangelogTemplateURL
+		if templateURL == "" {
+			templateURL = defaultChangelogURL
+		}
+		content, source = globalConfig.templateCache.get(templateURL, logger)
+	}
+
+	data := changelogTemplateData{
+		ProjectID:   projectConfig.id,
+		ProjectName: projectConfig.Name,
+		Date:        getReleaseDate(globalConfig).Format("2006-01-02"),
+	}
+	rendered, err := renderChangelogTemplate(content, data)
+	if err != nil {
+		logger.Errorf("Failed to render the CHANGELOG template, using the default one: %v", err)
+		rendered, _ = renderChangelogTemplate(defaultChangelogTemplate, data)
+		source = changelogSourceEmbedded
+	}
+	return rendered, source
+}
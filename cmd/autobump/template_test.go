@@ -0,0 +1,195 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customChangelogTemplate = `# {{.ProjectName}} changelog
+
+Internal compliance notice, created on {{.Date}}.
+
+## [Unreleased]
+`
+
+func TestLoadChangelogTemplate_LocalPath(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	templatePath := filepath.Join(t.TempDir(), "CHANGELOG.template.md")
+	require.NoError(t, os.WriteFile(templatePath, []byte(customChangelogTemplate), 0o600))
+
+	// Act
+	content, err := loadChangelogTemplate(templatePath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, customChangelogTemplate, content)
+}
+
+func TestLoadChangelogTemplate_URL(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(customChangelogTemplate))
+	}))
+	defer server.Close()
+
+	// Act
+	content, err := loadChangelogTemplate(server.URL + "/CHANGELOG.template.md")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, customChangelogTemplate, content)
+}
+
+func TestLoadChangelogTemplate_MissingUnreleased(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	templatePath := filepath.Join(t.TempDir(), "CHANGELOG.template.md")
+	require.NoError(t, os.WriteFile(templatePath, []byte("# Changelog\n\n## [1.0.0]\n"), 0o600))
+
+	// Act
+	_, err := loadChangelogTemplate(templatePath)
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidChangelogTemplate)
+}
+
+func TestLoadChangelogTemplate_UnknownVariable(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := validateChangelogTemplate("# {{.Owner}}\n\n## [Unreleased]\n")
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidChangelogTemplate)
+}
+
+func TestGetChangelogContent_ProjectTemplateSubstitution(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{changelogTemplate: defaultChangelogTemplate}
+	projectConfig := &ProjectConfig{Name: "payments-api", changelogTemplate: customChangelogTemplate}
+
+	// Act
+	content, source := getChangelogContent(globalConfig, projectConfig, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "# payments-api changelog\n\nInternal compliance notice, created on "+
+		time.Now().Format("2006-01-02")+".\n\n## [Unreleased]\n", string(content))
+	assert.Equal(t, changelogSourceTemplate, source)
+}
+
+func TestGetChangelogContent_GlobalTemplate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig := &GlobalConfig{changelogTemplate: defaultChangelogTemplate}
+
+	// Act
+	content, source := getChangelogContent(globalConfig, &ProjectConfig{Name: "project"}, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, defaultChangelogTemplate, string(content))
+	assert.Equal(t, changelogSourceTemplate, source)
+}
+
+// newTemplateServer creates a server answering the template requests with the status and body, counting them
+func newTemplateServer(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCreateChangelogIfNotExists_DownloadsTemplateOnce(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, requests := newTemplateServer(t, http.StatusOK, customChangelogTemplate)
+	globalConfig := &GlobalConfig{ChangelogTemplateURL: server.URL, templateCache: &changelogTemplateCache{}}
+	projectsDir := t.TempDir()
+
+	// Act
+	for _, name := range []string{"api", "web", "worker"} {
+		ctx := &RepoContext{globalConfig: globalConfig, projectConfig: &ProjectConfig{Name: name}}
+		_, err := createChangelogIfNotExists(ctx, filepath.Join(projectsDir, name+".md"))
+		require.NoError(t, err)
+	}
+
+	// Assert
+	assert.Equal(t, int32(1), requests.Load())
+	content, err := os.ReadFile(filepath.Join(projectsDir, "worker.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# worker changelog")
+	assert.Equal(
+		t, map[string]int{changelogSourceNetwork: 1, changelogSourceCache: 2}, globalConfig.templateCache.getCreated(),
+	)
+}
+
+func TestCreateChangelogIfNotExists_RejectsInvalidDownloadedTemplate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, requests := newTemplateServer(t, http.StatusOK, "")
+	globalConfig := &GlobalConfig{ChangelogTemplateURL: server.URL, templateCache: &changelogTemplateCache{}}
+	projectsDir := t.TempDir()
+
+	// Act
+	for _, name := range []string{"api", "web"} {
+		ctx := &RepoContext{globalConfig: globalConfig, projectConfig: &ProjectConfig{Name: name}}
+		_, err := createChangelogIfNotExists(ctx, filepath.Join(projectsDir, name+".md"))
+		require.NoError(t, err)
+	}
+
+	// Assert
+	assert.Equal(t, int32(1), requests.Load(), "a failed download must not be retried in the same run")
+	content, err := os.ReadFile(filepath.Join(projectsDir, "api.md"))
+	require.NoError(t, err)
+	assert.Equal(t, defaultChangelogTemplate, string(content))
+	assert.Equal(t, map[string]int{changelogSourceEmbedded: 2}, globalConfig.templateCache.getCreated())
+}
+
+func TestGetChangelogContent_RateLimitedDownload(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server, _ := newTemplateServer(t, http.StatusTooManyRequests, "## [Unreleased]\nrate limited")
+	globalConfig := &GlobalConfig{ChangelogTemplateURL: server.URL, templateCache: &changelogTemplateCache{}}
+
+	// Act
+	content, source := getChangelogContent(globalConfig, &ProjectConfig{Name: "project"}, standardLogEntry())
+
+	// Assert
+	assert.Equal(t, defaultChangelogTemplate, string(content))
+	assert.Equal(t, changelogSourceEmbedded, source)
+}
+
+func TestValidateChangelogTemplate_Empty(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := validateChangelogTemplate(" \n")
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidChangelogTemplate)
+	assert.Contains(t, err.Error(), "empty template")
+}
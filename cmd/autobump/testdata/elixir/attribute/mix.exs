@@ -0,0 +1,19 @@
+defmodule Payments.MixProject do
+  use Mix.Project
+
+  @version "1.2.3"
+
+  def project do
+    [
+      app: :payments,
+      version: @version,
+      elixir: "~> 1.14",
+      docs: [source_ref: "v#{@version}"],
+      deps: deps()
+    ]
+  end
+
+  defp deps do
+    [{:legacy, version: "1.0.0"}]
+  end
+end
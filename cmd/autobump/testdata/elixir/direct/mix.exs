@@ -0,0 +1,19 @@
+defmodule Payments.MixProject do
+  use Mix.Project
+
+  def project do
+    [
+      app: :payments,
+      version: "1.2.3",
+      elixir: "~> 1.14",
+      deps: deps()
+    ]
+  end
+
+  defp deps do
+    [
+      {:jason, "~> 1.4"},
+      {:legacy, git: "https://github.com/example/legacy.git", tag: "v1.0.0", version: "1.0.0"}
+    ]
+  end
+end
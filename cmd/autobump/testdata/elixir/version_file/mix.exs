@@ -0,0 +1,17 @@
+defmodule Payments.MixProject do
+  use Mix.Project
+
+  @version File.read!("VERSION") |> String.trim()
+
+  def project do
+    [
+      app: :payments,
+      version: @version,
+      deps: deps()
+    ]
+  end
+
+  defp deps do
+    [{:legacy, version: "1.0.0"}]
+  end
+end
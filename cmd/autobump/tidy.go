@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// changelogLinkPlaceholder is the placeholder of the CHANGELOG template, left by the previous versions of AutoBump
+const changelogLinkPlaceholder = "<LINK TO THE PLATFORM TO OPEN THE PULL REQUEST>"
+
+var ErrChangelogNotTidy = errors.New("the CHANGELOG has artifacts to be tidied")
+
+// changelogLinkPlaceholderRegex matches the placeholder along with the words introducing it
+var changelogLinkPlaceholderRegex = regexp.MustCompile(`(?:\s+using)?\s*` + regexp.QuoteMeta(changelogLinkPlaceholder))
+
+// tidyRepair is an artifact repaired in the CHANGELOG, at the line of the original content
+type tidyRepair struct {
+	Line    int
+	Message string
+}
+
+// tidyChangelog repairs the artifacts left by the previous versions of AutoBump: the link placeholder of the template
+// (replaced by the link to the repository when known), the duplicated "Unreleased" sections of an interrupted run
+// (merged into the first one) and the consecutive blank lines. Anything else is kept as it is.
+func tidyChangelog(lines []string, repositoryURL string) ([]string, []tidyRepair) {
+	// the front matter is kept as it is, above the tidied content
+	frontMatter, lines := splitFrontMatter(lines)
+
+	var repairs []tidyRepair
+	lines, repairs = replaceLinkPlaceholder(lines, repositoryURL, repairs)
+
+	// the blank lines are reported before merging, so the repairs point to the lines of the original content
+	_, blankLineRepairs := collapseBlankLines(lines, nil)
+	lines, repairs = mergeDuplicatedUnreleased(lines, repairs)
+	lines, _ = collapseBlankLines(lines, nil)
+
+	repairs = append(repairs, blankLineRepairs...)
+	for index := range repairs {
+		repairs[index].Line += len(frontMatter)
+	}
+	return joinFrontMatter(frontMatter, lines), repairs
+}
+
+// replaceLinkPlaceholder replaces the link placeholder with the web URL of the repository, or removes it
+func replaceLinkPlaceholder(lines []string, repositoryURL string, repairs []tidyRepair) ([]string, []tidyRepair) {
+	webURL := ""
+	if repositoryURL != "" {
+		webURL = getRepositoryWebURL(repositoryURL)
+	}
+
+	tidiedLines := make([]string, 0, len(lines))
+	for index, line := range lines {
+		if strings.Contains(line, changelogLinkPlaceholder) {
+			if webURL != "" {
+				line = strings.ReplaceAll(line, changelogLinkPlaceholder, "<"+webURL+">")
+				repairs = append(repairs, tidyRepair{Line: index + 1, Message: "replaced the link placeholder with " + webURL})
+			} else {
+				line = changelogLinkPlaceholderRegex.ReplaceAllString(line, "")
+				repairs = append(repairs, tidyRepair{Line: index + 1, Message: "removed the link placeholder"})
+			}
+		}
+		tidiedLines = append(tidiedLines, line)
+	}
+	return tidiedLines, repairs
+}
+
+// mergeDuplicatedUnreleased moves the entries of the duplicated "Unreleased" sections to the end of the first one
+func mergeDuplicatedUnreleased(lines []string, repairs []tidyRepair) ([]string, []tidyRepair) {
+	var tidiedLines, mergedEntries []string
+	firstUnreleasedEnd := -1
+	foundUnreleased, insideFirst, insideDuplicate := false, false, false
+	for index, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			if insideFirst {
+				firstUnreleasedEnd = len(tidiedLines)
+				insideFirst = false
+			}
+
+			isUnreleased := strings.Contains(line, "[Unreleased]")
+			insideDuplicate = isUnreleased && foundUnreleased
+			if insideDuplicate {
+				repairs = append(repairs, tidyRepair{
+					Line:    index + 1,
+					Message: "merged the duplicated \"Unreleased\" section into the first one",
+				})
+				continue
+			}
+			if isUnreleased {
+				foundUnreleased, insideFirst = true, true
+			}
+		}
+
+		if insideDuplicate {
+			mergedEntries = append(mergedEntries, line)
+		} else {
+			tidiedLines = append(tidiedLines, line)
+		}
+	}
+	if insideFirst {
+		firstUnreleasedEnd = len(tidiedLines)
+	}
+
+	if firstUnreleasedEnd == -1 {
+		return tidiedLines, repairs
+	}
+	if len(mergedEntries) > 0 && strings.TrimSpace(mergedEntries[0]) != "" {
+		mergedEntries = append([]string{""}, mergedEntries...)
+	}
+	merged := append([]string{}, tidiedLines[:firstUnreleasedEnd]...)
+	merged = append(merged, mergedEntries...)
+	return append(merged, tidiedLines[firstUnreleasedEnd:]...), repairs
+}
+
+// collapseBlankLines replaces the consecutive blank lines with a single one, except inside the code blocks
+func collapseBlankLines(lines []string, repairs []tidyRepair) ([]string, []tidyRepair) {
+	tidiedLines := make([]string, 0, len(lines))
+	insideCodeBlock := false
+	for index, line := range lines {
+		if isCodeFence(line) {
+			insideCodeBlock = !insideCodeBlock
+		}
+
+		isRepeatedBlank := strings.TrimSpace(line) == "" && index > 0 && strings.TrimSpace(lines[index-1]) == ""
+		if insideCodeBlock || !isRepeatedBlank {
+			tidiedLines = append(tidiedLines, line)
+			continue
+		}
+
+		// a single repair for each sequence of blank lines
+		if index < 2 || strings.TrimSpace(lines[index-2]) != "" {
+			repairs = append(repairs, tidyRepair{Line: index + 1, Message: "removed the consecutive blank lines"})
+		}
+	}
+	return tidiedLines, repairs
+}
+
+// reportTidyRepairs reports each repair of the CHANGELOG as a finding
+func reportTidyRepairs(changelogFile string, repairs []tidyRepair, level string, logger *log.Entry) {
+	for _, repair := range repairs {
+		reportFinding(Finding{Level: level, File: changelogFile, Line: repair.Line, Message: repair.Message}, logger)
+	}
+}
+
+// tidyChangelogFile repairs the artifacts of the CHANGELOG before processing it, when "auto_tidy" is enabled
+func tidyChangelogFile(ctx *RepoContext, changelogPath string) error {
+	if !ctx.globalConfig.AutoTidy {
+		return nil
+	}
+
+	lines, err := readChangelogLines(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+
+	var repositoryURL string
+	if ctx.repo != nil {
+		repositoryURL, _ = getRemoteRepoURL(ctx.repo)
+	}
+	tidiedLines, repairs := tidyChangelog(lines, repositoryURL)
+	if len(repairs) == 0 {
+		return nil
+	}
+
+	ctx.logger().Infof("Tidying %d artifacts of the CHANGELOG", len(repairs))
+	reportTidyRepairs(getRelativeFileName(ctx, changelogPath), repairs, findingNotice, ctx.logger())
+	return writeLines(changelogPath, tidiedLines)
+}
+
+// writeTidyCheck writes the artifacts found in the CHANGELOG, failing when there is any and they weren't fixed
+func writeTidyCheck(output io.Writer, changelogFile string, repairs []tidyRepair, fixed bool) error {
+	var err error
+	switch {
+	case len(repairs) == 0:
+		_, err = fmt.Fprintf(output, "%s has no artifacts to be tidied\n", changelogFile)
+	case fixed:
+		_, err = fmt.Fprintf(output, "%s was tidied:\n", changelogFile)
+	default:
+		_, err = fmt.Fprintf(output, "%s has artifacts to be tidied (run with --fix to repair them):\n", changelogFile)
+	}
+	for _, repair := range repairs {
+		if err == nil {
+			_, err = fmt.Fprintf(output, "- %s:%d: %s\n", changelogFile, repair.Line, repair.Message)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the tidy check: %w", err)
+	}
+
+	if len(repairs) > 0 && !fixed {
+		return fmt.Errorf("%w: %d found in %s", ErrChangelogNotTidy, len(repairs), changelogFile)
+	}
+	return nil
+}
+
+// runChangelogTidy checks the CHANGELOG for artifacts, repairing them in place when fixing.
+// The link placeholder is replaced by the link to the repository of the CHANGELOG (when it is inside one).
+func runChangelogTidy(output io.Writer, changelogPath string, fix bool) error {
+	lines, err := readLines(changelogPath, getMaxFileSize(&GlobalConfig{}))
+	if err != nil {
+		return err
+	}
+
+	var repositoryURL string
+	repo, err := git.PlainOpenWithOptions(filepath.Dir(changelogPath), &git.PlainOpenOptions{DetectDotGit: true})
+	if err == nil {
+		repositoryURL, _ = getRemoteRepoURL(repo)
+	}
+
+	tidiedLines, repairs := tidyChangelog(lines, repositoryURL)
+	if fix && len(repairs) > 0 {
+		err = writeLines(changelogPath, tidiedLines)
+		if err != nil {
+			return err
+		}
+	}
+	return writeTidyCheck(output, changelogPath, repairs, fix)
+}
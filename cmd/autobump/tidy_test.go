@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readTidyFixture reads the lines of a CHANGELOG fixture with artifacts
+func readTidyFixture(t *testing.T, name string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "tidy", name))
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// assertTidyGolden compares the tidied CHANGELOG with its golden file, updating it with "-update"
+func assertTidyGolden(t *testing.T, name string, lines []string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name)
+	content := strings.Join(lines, "\n") + "\n"
+	if *updateGoldens {
+		require.NoError(t, os.WriteFile(goldenPath, []byte(content), 0o600))
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), content)
+}
+
+func TestTidyChangelog_WithRepositoryURL(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := readTidyFixture(t, "artifacts.md")
+
+	// Act
+	tidiedLines, repairs := tidyChangelog(lines, "git@github.com:rios0rios0/autobump.git")
+
+	// Assert
+	assertTidyGolden(t, "tidy_with_repository_url.golden", tidiedLines)
+	assert.Equal(t, []tidyRepair{
+		{Line: 8, Message: "replaced the link placeholder with https://github.com/rios0rios0/autobump"},
+		{Line: 17, Message: "merged the duplicated \"Unreleased\" section into the first one"},
+		{Line: 10, Message: "removed the consecutive blank lines"},
+		{Line: 23, Message: "removed the consecutive blank lines"},
+	}, repairs)
+}
+
+func TestTidyChangelog_WithoutRepositoryURL(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := readTidyFixture(t, "artifacts.md")
+
+	// Act
+	tidiedLines, repairs := tidyChangelog(lines, "")
+
+	// Assert
+	assertTidyGolden(t, "tidy_without_repository_url.golden", tidiedLines)
+	assert.Equal(t, tidyRepair{Line: 8, Message: "removed the link placeholder"}, repairs[0])
+}
+
+func TestTidyChangelog_KeepsTidyChangelog(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogOriginal, "\n")
+
+	// Act
+	tidiedLines, repairs := tidyChangelog(lines, "")
+
+	// Assert
+	assert.Empty(t, repairs)
+	assert.Equal(t, lines, tidiedLines)
+}
+
+func TestTidyChangelog_IsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines, _ := tidyChangelog(readTidyFixture(t, "artifacts.md"), "")
+
+	// Act
+	tidiedLines, repairs := tidyChangelog(lines, "")
+
+	// Assert
+	assert.Empty(t, repairs)
+	assert.Equal(t, lines, tidiedLines)
+}
+
+func TestRunChangelogTidy_CheckFailsWithoutFix(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelogPath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	content := strings.Join(readTidyFixture(t, "artifacts.md"), "\n") + "\n"
+	require.NoError(t, os.WriteFile(changelogPath, []byte(content), 0o600))
+	var output bytes.Buffer
+
+	// Act
+	err := runChangelogTidy(&output, changelogPath, false)
+
+	// Assert
+	require.ErrorIs(t, err, ErrChangelogNotTidy)
+	assert.Contains(t, output.String(), "run with --fix to repair them")
+	assert.Contains(t, output.String(), changelogPath+":8: removed the link placeholder")
+	unchanged, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(unchanged))
+}
+
+func TestRunChangelogTidy_Fix(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelogPath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	content := strings.Join(readTidyFixture(t, "artifacts.md"), "\n") + "\n"
+	require.NoError(t, os.WriteFile(changelogPath, []byte(content), 0o600))
+	var output bytes.Buffer
+
+	// Act
+	err := runChangelogTidy(&output, changelogPath, true)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "was tidied")
+	lines, err := readLines(changelogPath, getMaxFileSize(&GlobalConfig{}))
+	require.NoError(t, err)
+	_, repairs := tidyChangelog(lines, "")
+	assert.Empty(t, repairs)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// phases of the processing of a project, in the order they run
+const (
+	phaseClone     = "clone"
+	phaseChangelog = "changelog"
+	phaseVersion   = "version"
+	phaseCommit    = "commit"
+	phasePush      = "push"
+	phasePR        = "pr"
+)
+
+// PhaseTiming is the time spent in a phase of the processing of a project
+type PhaseTiming struct {
+	Phase   string  `json:"phase"`
+	Seconds float64 `json:"seconds"`
+}
+
+// phaseTimer measures the phases of the processing of a project, the clock can be replaced in the tests
+type phaseTimer struct {
+	now     func() time.Time
+	logger  *log.Entry
+	timings []PhaseTiming
+}
+
+// newPhaseTimer creates a timer using the wall clock, logging the phases through the entry
+func newPhaseTimer(logger *log.Entry) *phaseTimer {
+	return &phaseTimer{now: time.Now, logger: logger}
+}
+
+// start starts measuring a phase, returning the function that stops it.
+// The phases measured more than once (e.g. the changelog of each version stream) are added up.
+func (t *phaseTimer) start(phase string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	startTime := t.now()
+	return func() {
+		duration := t.now().Sub(startTime)
+		t.logger.Debugf("Phase %s took %s", phase, formatPhaseDuration(duration))
+
+		for index := range t.timings {
+			if t.timings[index].Phase == phase {
+				t.timings[index].Seconds += duration.Seconds()
+				return
+			}
+		}
+		t.timings = append(t.timings, PhaseTiming{Phase: phase, Seconds: duration.Seconds()})
+	}
+}
+
+// getTimings returns the measured phases, in the order they ran
+func (t *phaseTimer) getTimings() []PhaseTiming {
+	if t == nil {
+		return nil
+	}
+	return append([]PhaseTiming{}, t.timings...)
+}
+
+// logSummary logs the measured phases in a single line (e.g. "timings clone=38s changelog=0.1s push=12s pr=2.3s")
+func (t *phaseTimer) logSummary(projectName string) {
+	if t == nil || len(t.timings) == 0 {
+		return
+	}
+	t.logger.Infof("Project %s %s", projectName, formatTimings(t.timings))
+}
+
+// formatTimings formats the phases as "timings <phase>=<duration>..."
+func formatTimings(timings []PhaseTiming) string {
+	parts := make([]string, 0, len(timings)+1)
+	parts = append(parts, "timings")
+	for _, timing := range timings {
+		parts = append(parts, fmt.Sprintf("%s=%s", timing.Phase, formatPhaseDuration(secondsToDuration(timing.Seconds))))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatPhaseDuration formats the short durations with a decimal (e.g. "2.3s") and the longer ones
+// rounded to the second (e.g. "38s" and "4m2s")
+func formatPhaseDuration(duration time.Duration) string {
+	if duration < 10*time.Second {
+		return fmt.Sprintf("%.1fs", duration.Seconds())
+	}
+	return duration.Round(time.Second).String()
+}
+
+// secondsToDuration converts the seconds of a timing back to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
+
+// phasePercentiles are the percentiles of the time spent in a phase across the projects of a run
+type phasePercentiles struct {
+	phase    string
+	projects int
+	p50      time.Duration
+	p95      time.Duration
+}
+
+// getPhasePercentiles aggregates the timings of the projects per phase, in the order the phases run
+func getPhasePercentiles(results []ProjectResult) []phasePercentiles {
+	durations := make(map[string][]float64)
+	var phases []string
+	for _, result := range results {
+		for _, timing := range result.Timings {
+			if _, exists := durations[timing.Phase]; !exists {
+				phases = append(phases, timing.Phase)
+			}
+			durations[timing.Phase] = append(durations[timing.Phase], timing.Seconds)
+		}
+	}
+
+	phaseOrder := []string{phaseClone, phaseChangelog, phaseVersion, phaseCommit, phasePush, phasePR}
+	slices.SortStableFunc(phases, func(a, b string) int {
+		return slices.Index(phaseOrder, a) - slices.Index(phaseOrder, b)
+	})
+
+	percentiles := make([]phasePercentiles, 0, len(phases))
+	for _, phase := range phases {
+		values := durations[phase]
+		slices.Sort(values)
+		percentiles = append(percentiles, phasePercentiles{
+			phase:    phase,
+			projects: len(values),
+			p50:      secondsToDuration(getPercentile(values, 50)),
+			p95:      secondsToDuration(getPercentile(values, 95)),
+		})
+	}
+	return percentiles
+}
+
+// getPercentile returns the nearest-rank percentile of the sorted values
+func getPercentile(sortedValues []float64, percentile int) float64 {
+	if len(sortedValues) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(percentile) / 100 * float64(len(sortedValues))))
+	return sortedValues[max(rank, 1)-1]
+}
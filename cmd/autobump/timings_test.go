@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakePhaseTimer creates a timer whose clock moves forward by the given steps, one on each reading
+func newFakePhaseTimer(steps ...time.Duration) *phaseTimer {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &phaseTimer{now: func() time.Time {
+		if len(steps) > 0 {
+			current = current.Add(steps[0])
+			steps = steps[1:]
+		}
+		return current
+	}, logger: standardLogEntry()}
+}
+
+func TestPhaseTimer_FormatsSummary(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	timer := newFakePhaseTimer(0, 38*time.Second, 0, 100*time.Millisecond, 0, 12*time.Second, 0, 2300*time.Millisecond)
+
+	// Act
+	for _, phase := range []string{phaseClone, phaseChangelog, phasePush, phasePR} {
+		timer.start(phase)()
+	}
+
+	// Assert
+	assert.Equal(t, "timings clone=38s changelog=0.1s push=12s pr=2.3s", formatTimings(timer.getTimings()))
+}
+
+func TestPhaseTimer_AddsUpRepeatedPhases(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	timer := newFakePhaseTimer(0, time.Second, 0, 2*time.Second)
+
+	// Act
+	timer.start(phaseChangelog)()
+	timer.start(phaseChangelog)()
+
+	// Assert
+	assert.Equal(t, []PhaseTiming{{Phase: phaseChangelog, Seconds: 3}}, timer.getTimings())
+}
+
+func TestPhaseTimer_Nil(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var timer *phaseTimer
+
+	// Act
+	timer.start(phaseClone)()
+
+	// Assert
+	assert.Nil(t, timer.getTimings())
+}
+
+func TestFormatPhaseDuration(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	durations := map[time.Duration]string{
+		40 * time.Millisecond:                 "0.0s",
+		2340 * time.Millisecond:               "2.3s",
+		38400 * time.Millisecond:              "38s",
+		4*time.Minute + 2400*time.Millisecond: "4m2s",
+	}
+
+	for duration, expected := range durations {
+		// Act
+		formatted := formatPhaseDuration(duration)
+
+		// Assert
+		assert.Equal(t, expected, formatted)
+	}
+}
+
+func TestGetPhasePercentiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var results []ProjectResult
+	for seconds := 1; seconds <= 20; seconds++ {
+		results = append(results, ProjectResult{Timings: []PhaseTiming{
+			{Phase: phasePush, Seconds: float64(seconds)},
+			{Phase: phaseClone, Seconds: float64(seconds * 10)},
+		}})
+	}
+
+	// Act
+	percentiles := getPhasePercentiles(results)
+
+	// Assert
+	require.Len(t, percentiles, 2)
+	assert.Equal(t, phasePercentiles{phase: phaseClone, projects: 20, p50: 100 * time.Second, p95: 190 * time.Second},
+		percentiles[0])
+	assert.Equal(t, phasePercentiles{phase: phasePush, projects: 20, p50: 10 * time.Second, p95: 19 * time.Second},
+		percentiles[1])
+}
+
+func TestRenderDigest_Timings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	results := []ProjectResult{
+		{
+			Name: "first", Forge: "github.com", Organization: "org", PreviousVersion: "1.0.0", NextVersion: "1.1.0",
+			Bump: "minor", Timings: []PhaseTiming{{Phase: phaseClone, Seconds: 38}, {Phase: phasePR, Seconds: 2.3}},
+		},
+		{
+			Name: "second", Forge: "github.com", Organization: "org", PreviousVersion: "1.0.0", NextVersion: "1.0.1",
+			Bump: "patch", Timings: []PhaseTiming{{Phase: phaseClone, Seconds: 4}},
+		},
+	}
+
+	// Act
+	digest := renderDigest(results)
+
+	// Assert
+	assert.Contains(t, digest, "## Timings\n\n- clone: p50 4.0s, p95 38s (2 projects)\n- pr: p50 2.3s, p95 2.3s (1 project)\n")
+}
+
+func TestProjectResult_TimingsInJSON(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	result := ProjectResult{Name: "project", Timings: []PhaseTiming{{Phase: phaseClone, Seconds: 1.5}}}
+
+	// Act
+	data, err := json.Marshal(result)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"timings":[{"phase":"clone","seconds":1.5}]`)
+}
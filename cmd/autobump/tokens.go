@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrInsecureTokenFile = errors.New("the token file is readable by other users")
+
+// checkTokenFilePermissions warns about the token files readable by the group or the other users,
+// like SSH does for the private keys, refusing them in the strict mode
+func checkTokenFilePermissions(name string, tokenPath string, mode fs.FileMode, strict bool) error {
+	// the permission bits don't tell who can read the files on Windows
+	if runtime.GOOS == "windows" || mode.Perm()&0o077 == 0 {
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf(
+			"%w: %s access token %s has mode %04o (run \"chmod 600 %s\")",
+			ErrInsecureTokenFile, name, tokenPath, mode.Perm(), tokenPath,
+		)
+	}
+	log.Warnf(
+		"The %s access token file %s has mode %04o, it is readable by other users: run \"chmod 600 %s\" "+
+			"(or set strict_permissions to refuse it)",
+		name, tokenPath, mode.Perm(), tokenPath,
+	)
+	return nil
+}
+
+// readTokenFile reads the token of a file, without the surrounding whitespace
+func readTokenFile(tokenPath string, maxFileSize int64) (string, error) {
+	err := checkFileSize(tokenPath, maxFileSize)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(tokenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the token file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// tokenFile is a provider token read from a file, along with the values it had (to recognize the requests using them)
+type tokenFile struct {
+	name string
+	path string
+	// the last value is the current token
+	values []string
+}
+
+// tokenFiles are the provider tokens read from files, which are read again when a provider rejects them,
+// so the short-lived tokens rotated during a long run are picked up. The rotated tokens are kept here rather than
+// in the configuration read by the workers, so it is safe to be shared by concurrent workers.
+type tokenFiles struct {
+	mutex       sync.Mutex
+	maxFileSize int64
+	files       []*tokenFile
+}
+
+// add registers a token read from a file, the tokens which weren't read from a file are ignored
+func (f *tokenFiles) add(name string, tokenPath string, token string) {
+	if f == nil || tokenPath == "" {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.files = append(f.files, &tokenFile{name: name, path: tokenPath, values: []string{token}})
+}
+
+// refresh returns the request with the current tokens when it sends tokens rotated since then (e.g. the one of the
+// configuration), or nil when its tokens are current
+func (f *tokenFiles) refresh(req *http.Request) *http.Request {
+	if f == nil {
+		return nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var refreshed *http.Request
+	for _, file := range f.files {
+		currentToken := file.values[len(file.values)-1]
+		usedToken := findRequestToken(req, file.values)
+		if usedToken == "" || usedToken == currentToken {
+			continue
+		}
+
+		if refreshed == nil {
+			refreshed = req.Clone(req.Context())
+		}
+		replaceRequestToken(refreshed, usedToken, currentToken)
+	}
+	return refreshed
+}
+
+// rotate reads again the token files whose tokens are used by the request, returning the request with the new
+// tokens, or nil when none of them changed
+func (f *tokenFiles) rotate(req *http.Request) *http.Request {
+	if f == nil {
+		return nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var rotated *http.Request
+	for _, file := range f.files {
+		usedToken := findRequestToken(req, file.values)
+		if usedToken == "" {
+			continue
+		}
+
+		newToken, err := readTokenFile(file.path, f.maxFileSize)
+		if err != nil {
+			log.Warnf("Unable to read the %s access token again from %s: %v", file.name, file.path, err)
+			continue
+		}
+		if newToken == "" || newToken == usedToken {
+			continue
+		}
+
+		if newToken != file.values[len(file.values)-1] {
+			log.Infof("The %s access token was rejected, using the rotated token from %s", file.name, file.path)
+			file.values = append(file.values, newToken)
+			registerSecret(newToken)
+		}
+
+		if rotated == nil {
+			rotated = req.Clone(req.Context())
+		}
+		replaceRequestToken(rotated, usedToken, newToken)
+	}
+	return rotated
+}
+
+// findRequestToken returns the token (among the values) sent by the request, either as it is or in the
+// Basic authentication of Azure DevOps
+func findRequestToken(req *http.Request, values []string) string {
+	for _, headerValues := range req.Header {
+		for _, headerValue := range headerValues {
+			for _, value := range slices.Backward(values) {
+				if value != "" && (strings.Contains(headerValue, value) ||
+					strings.Contains(headerValue, encodeBasicToken(value))) {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// replaceRequestToken replaces the token sent by the request with the new one
+func replaceRequestToken(req *http.Request, oldToken string, newToken string) {
+	for name, headerValues := range req.Header {
+		for index, headerValue := range headerValues {
+			headerValue = strings.ReplaceAll(headerValue, oldToken, newToken)
+			headerValues[index] = strings.ReplaceAll(headerValue, encodeBasicToken(oldToken), encodeBasicToken(newToken))
+		}
+		req.Header[name] = headerValues
+	}
+}
+
+// encodeBasicToken encodes the token like the Basic authentication of Azure DevOps (with an empty user)
+func encodeBasicToken(token string) string {
+	return base64.StdEncoding.EncodeToString([]byte(":" + token))
+}
+
+// tokenRotationTransport sends the provider API requests with the tokens rotated during the run, and retries once
+// the requests rejected with 401 Unauthorized, when the token came from a file which has a new token
+type tokenRotationTransport struct {
+	base       http.RoundTripper
+	tokenFiles *tokenFiles
+}
+
+func (t *tokenRotationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if refreshed := t.tokenFiles.refresh(req); refreshed != nil {
+		req = refreshed
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// the requests with a body can only be sent again when it can be read again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	rotated := t.tokenFiles.rotate(req)
+	if rotated == nil {
+		return resp, nil
+	}
+	if req.GetBody != nil {
+		var body io.ReadCloser
+		if body, err = req.GetBody(); err != nil {
+			// the rejected response is kept when the body can't be read again
+			log.Warnf("Unable to retry the request with the rotated token: %v", err)
+			return resp, nil
+		}
+		rotated.Body = body
+	}
+
+	resp.Body.Close()
+	return t.base.RoundTrip(rotated)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRotatingTokenServer creates a server accepting only the current token, sent in the given header.
+// The token file is rewritten with the current token when the server rejects the old one.
+func newRotatingTokenServer(
+	t *testing.T, header string, encode func(string) string, tokenPath string, currentToken string,
+) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get(header) != encode(currentToken) {
+			// the token was rotated by an external process
+			assert.NoError(t, os.WriteFile(tokenPath, []byte(currentToken+"\n"), 0o600))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCheckTokenFilePermissions_AcceptsOwnerOnly(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := checkTokenFilePermissions("GitLab", "token.txt", 0o600, true)
+
+	// Assert
+	require.NoError(t, err)
+}
+
+func TestCheckTokenFilePermissions_WarnsWhenNotStrict(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	err := checkTokenFilePermissions("GitLab", "token.txt", 0o640, false)
+
+	// Assert
+	require.NoError(t, err)
+}
+
+func TestTokenRotationTransport_RetriesWithRotatedPrivateToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "gitlab-token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("old-token"), 0o600))
+	server, requests := newRotatingTokenServer(
+		t, "Private-Token", func(token string) string { return token }, tokenPath, "new-token",
+	)
+
+	files := &tokenFiles{maxFileSize: defaultMaxFileSize}
+	files.add("GitLab", tokenPath, "old-token")
+	client := &http.Client{Transport: &tokenRotationTransport{base: http.DefaultTransport, tokenFiles: files}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, strings.NewReader("{}"))
+	require.NoError(t, err)
+	req.Header.Set("Private-Token", "old-token")
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *requests)
+	assert.Equal(t, []string{"old-token", "new-token"}, files.files[0].values)
+	assert.Equal(t, "old-token", req.Header.Get("Private-Token"), "the original request must not be modified")
+}
+
+func TestTokenRotationTransport_RetriesWithRotatedBasicToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "azure-token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("old-token"), 0o600))
+	server, requests := newRotatingTokenServer(
+		t, "Authorization", func(token string) string { return "Basic " + encodeBasicToken(token) }, tokenPath, "new-token",
+	)
+
+	files := &tokenFiles{maxFileSize: defaultMaxFileSize}
+	files.add("Azure DevOps", tokenPath, "old-token")
+	client := &http.Client{Transport: &tokenRotationTransport{base: http.DefaultTransport, tokenFiles: files}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Basic "+encodeBasicToken("old-token"))
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *requests)
+	assert.Equal(t, []string{"old-token", "new-token"}, files.files[0].values)
+}
+
+func TestTokenRotationTransport_SendsTheRotatedToken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "gitlab-token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("old-token"), 0o600))
+	server, requests := newRotatingTokenServer(
+		t, "Private-Token", func(token string) string { return token }, tokenPath, "new-token",
+	)
+
+	files := &tokenFiles{maxFileSize: defaultMaxFileSize}
+	files.add("GitLab", tokenPath, "old-token")
+	client := &http.Client{Transport: &tokenRotationTransport{base: http.DefaultTransport, tokenFiles: files}}
+
+	// Act
+	var statusCodes []int
+	for range 2 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Private-Token", "old-token")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		statusCodes = append(statusCodes, resp.StatusCode)
+	}
+
+	// Assert
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statusCodes)
+	assert.Equal(t, 3, *requests, "the second request should be sent with the rotated token at once")
+}
+
+func TestTokenRotationTransport_RetriesOnlyOnce(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "github-token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("revoked-token"), 0o600))
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	files := &tokenFiles{maxFileSize: defaultMaxFileSize}
+	files.add("GitHub", tokenPath, "expired-token")
+	client := &http.Client{Transport: &tokenRotationTransport{base: http.DefaultTransport, tokenFiles: files}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer expired-token")
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestTokenRotationTransport_KeepsRejectionWithoutTokenFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	files := &tokenFiles{maxFileSize: defaultMaxFileSize}
+	files.add("GitLab", "", "inline-token")
+	client := &http.Client{Transport: &tokenRotationTransport{base: http.DefaultTransport, tokenFiles: files}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Private-Token", "inline-token")
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 1, requests)
+}
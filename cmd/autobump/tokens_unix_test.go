@@ -0,0 +1,64 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTokenFile_ReadsOwnerOnlyFileInStrictMode(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("file-token\n"), 0o600))
+	token := tokenPath
+
+	// Act
+	readPath, err := handleTokenFile("GitLab", &token, defaultMaxFileSize, true)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, tokenPath, readPath)
+	assert.Equal(t, "file-token", token)
+}
+
+func TestHandleTokenFile_WarnsAboutGroupReadableFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("file-token"), 0o600))
+	require.NoError(t, os.Chmod(tokenPath, 0o640))
+	token := tokenPath
+
+	// Act
+	_, err := handleTokenFile("GitLab", &token, defaultMaxFileSize, false)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "file-token", token)
+}
+
+func TestHandleTokenFile_RefusesWorldReadableFileInStrictMode(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("file-token"), 0o600))
+	require.NoError(t, os.Chmod(tokenPath, 0o644))
+	token := tokenPath
+
+	// Act
+	_, err := handleTokenFile("GitHub", &token, defaultMaxFileSize, true)
+
+	// Assert
+	require.ErrorIs(t, err, ErrInsecureTokenFile)
+	assert.Contains(t, err.Error(), "chmod 600 "+tokenPath)
+	assert.Equal(t, tokenPath, token, "the token must not be read")
+}
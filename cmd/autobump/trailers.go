@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// built-in trailers of the bump commit
+const (
+	trailerVersion  = "Autobump-Version"
+	trailerPrevious = "Autobump-Previous"
+	trailerRunID    = "Autobump-Run-Id"
+)
+
+var ErrInvalidCommitTrailer = errors.New("invalid commit trailer")
+
+// trailerKeyRegex matches the keys of the Git trailers (e.g. "Reviewed-by")
+var trailerKeyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// runID identifies the current run of AutoBump in the logs, the report, the commits and the pull requests
+var runID = newRunID()
+
+// newRunID returns a random UUID (version 4)
+func newRunID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
+// runIDHook is a logger hook adding the ID of the run to every entry, so the logs of a run can be correlated
+type runIDHook struct{}
+
+func (h *runIDHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *runIDHook) Fire(entry *log.Entry) error {
+	entry.Data["run_id"] = runID
+	return nil
+}
+
+// validateCommitTrailers checks that the static trailers have a valid key and a single line value
+func validateCommitTrailers(trailersConfig CommitTrailersConfig) error {
+	for key, value := range trailersConfig.Static {
+		if !trailerKeyRegex.MatchString(key) {
+			return fmt.Errorf("%w: %q (expected letters, digits and dashes)", ErrInvalidCommitTrailer, key)
+		}
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: the value of %q must be a single line", ErrInvalidCommitTrailer, key)
+		}
+	}
+	return nil
+}
+
+// formatTrailerKey writes the key with the casing of the Git trailers, each word capitalized (e.g. "Reviewed-By")
+func formatTrailerKey(key string) string {
+	words := strings.Split(key, "-")
+	for index, word := range words {
+		if word != "" {
+			words[index] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		}
+	}
+	return strings.Join(words, "-")
+}
+
+// formatCommitTrailers returns the trailers appended right below the DCO sign-off of the bump commit:
+// the enabled built-in ones, followed by the static ones sorted by key
+func formatCommitTrailers(trailersConfig CommitTrailersConfig, newVersion string, previousVersion string) string {
+	var trailers []string
+	if trailersConfig.Version && newVersion != "" {
+		trailers = append(trailers, trailerVersion+": "+newVersion)
+	}
+	if trailersConfig.Previous && previousVersion != "" {
+		trailers = append(trailers, trailerPrevious+": "+previousVersion)
+	}
+	if trailersConfig.RunID {
+		trailers = append(trailers, trailerRunID+": "+runID)
+	}
+
+	keys := make([]string, 0, len(trailersConfig.Static))
+	for key := range trailersConfig.Static {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		trailers = append(trailers, formatTrailerKey(key)+": "+strings.TrimSpace(trailersConfig.Static[key]))
+	}
+
+	if len(trailers) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(trailers, "\n")
+}
+
+// getBumpCommitTrailers returns the trailers of the bump commit of the project
+func getBumpCommitTrailers(ctx *RepoContext) string {
+	previousVersion := ""
+	if ctx.unreleased.LatestVersion != nil {
+		previousVersion = versionString(ctx.unreleased.LatestVersion)
+	}
+	return formatCommitTrailers(ctx.globalConfig.CommitTrailers, ctx.projectConfig.NewVersion, previousVersion)
+}
+
+// formatRunNotes returns the paragraph of the pull request with the ID of the run, when it's a trailer of the commit
+func formatRunNotes(globalConfig *GlobalConfig) string {
+	if !globalConfig.CommitTrailers.RunID {
+		return ""
+	}
+	return fmt.Sprintf("Prepared by the AutoBump run `%s`.", runID)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCommitTrailers_Order(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	trailersConfig := CommitTrailersConfig{
+		Version:  true,
+		Previous: true,
+		RunID:    true,
+		Static:   map[string]string{"team": "payments", "change-ticket": " CHG-42 "},
+	}
+
+	// Act
+	trailers := formatCommitTrailers(trailersConfig, "1.5.0", "1.4.2")
+
+	// Assert
+	assert.Equal(t, "\nAutobump-Version: 1.5.0\nAutobump-Previous: 1.4.2\nAutobump-Run-Id: "+runID+
+		"\nChange-Ticket: CHG-42\nTeam: payments", trailers)
+}
+
+func TestFormatCommitTrailers_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	trailers := formatCommitTrailers(CommitTrailersConfig{}, "1.5.0", "1.4.2")
+
+	// Assert
+	assert.Empty(t, trailers)
+}
+
+func TestCommitChanges_TrailersAfterSignoff(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	repo, err := git.PlainInit(projectPath, false)
+	require.NoError(t, err)
+	commitFile(t, repo, "version.txt", "version=1.4.2\n")
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	trailers := formatCommitTrailers(CommitTrailersConfig{Version: true, RunID: true}, "1.5.0", "1.4.2")
+
+	// Act
+	hash, err := commitChanges(
+		worktree,
+		"chore(bump): bumped version to 1.5.0",
+		trailers,
+		nil,
+		"AutoBump",
+		"autobump@example.com",
+		time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+		standardLogEntry(),
+	)
+
+	// Assert
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	assert.Equal(t, "chore(bump): bumped version to 1.5.0\n\n"+
+		"Signed-off-by: AutoBump <autobump@example.com>\n"+
+		"Autobump-Version: 1.5.0\n"+
+		"Autobump-Run-Id: "+runID, commit.Message)
+}
+
+func TestRunID_SameInReportAndLogs(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	globalConfig := &GlobalConfig{ReportOut: reportPath, CommitTrailers: CommitTrailersConfig{RunID: true}}
+	globalConfig.releaseDigest = newReleaseDigest(globalConfig)
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(&runIDHook{})
+	hook := test.NewLocal(logger)
+
+	// Act
+	writeRunReport(globalConfig)
+	logger.Info("Committing changes")
+	runNotes := formatRunNotes(globalConfig)
+
+	// Assert
+	report, err := readRunReport(reportPath)
+	require.NoError(t, err)
+	assert.Equal(t, runID, report.RunID)
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, runID, hook.LastEntry().Data["run_id"])
+	assert.Contains(t, runNotes, runID)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, runID)
+}
+
+func TestValidateCommitTrailers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	valid := CommitTrailersConfig{Static: map[string]string{"Team": "payments"}}
+	invalidKey := CommitTrailersConfig{Static: map[string]string{"Reviewed by": "alice"}}
+	multilineValue := CommitTrailersConfig{Static: map[string]string{"Team": "payments\nplatform"}}
+
+	// Act
+	validErr := validateCommitTrailers(valid)
+	invalidKeyErr := validateCommitTrailers(invalidKey)
+	multilineValueErr := validateCommitTrailers(multilineValue)
+
+	// Assert
+	require.NoError(t, validErr)
+	require.ErrorIs(t, invalidKeyErr, ErrInvalidCommitTrailer)
+	require.ErrorIs(t, multilineValueErr, ErrInvalidCommitTrailer)
+}
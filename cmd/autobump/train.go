@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// releaseTrainWaiting is the status of the release train projects when the run is not the train
+const releaseTrainWaiting = "waiting-for-train"
+
+// releaseDateRegex captures the date of a release header (e.g. "## [1.0.0] - 2024-06-01")
+var releaseDateRegex = regexp.MustCompile(`\s-\s(\d{4}-\d{2}-\d{2})\b`)
+
+// isWaitingForTrain checks whether the project only releases in the runs of the release train (with "--train")
+func isWaitingForTrain(globalConfig *GlobalConfig, projectConfig *ProjectConfig) bool {
+	return projectConfig.ReleaseTrain && !globalConfig.train
+}
+
+// findLatestReleaseDate returns the date of the first release header with a date
+func findLatestReleaseDate(lines []string) (time.Time, bool) {
+	for _, line := range lines {
+		if !isReleaseHeader(line) {
+			continue
+		}
+
+		match := releaseDateRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", match[1])
+		if err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatTrainNotes describes the window of time covered by the release train, since the previous release
+func formatTrainNotes(lines []string, releaseDate time.Time) string {
+	previousDate, found := findLatestReleaseDate(lines)
+	if !found {
+		return fmt.Sprintf("This release train covers all the changes up to %s.", releaseDate.Format("2006-01-02"))
+	}
+	return fmt.Sprintf(
+		"This release train covers the changes from %s to %s.",
+		previousDate.Format("2006-01-02"),
+		releaseDate.Format("2006-01-02"),
+	)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWaitingForTrain(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	trainProject := &ProjectConfig{ReleaseTrain: true}
+	regularProject := &ProjectConfig{}
+
+	// Act & Assert
+	assert.True(t, isWaitingForTrain(&GlobalConfig{}, trainProject))
+	assert.False(t, isWaitingForTrain(&GlobalConfig{train: true}, trainProject))
+	assert.False(t, isWaitingForTrain(&GlobalConfig{}, regularProject))
+	assert.False(t, isWaitingForTrain(&GlobalConfig{train: true}, regularProject))
+}
+
+func TestFormatTrainNotes_SincePreviousRelease(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogOriginal, "\n")
+
+	// Act
+	notes := formatTrainNotes(lines, time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC))
+
+	// Assert
+	assert.Equal(t, "This release train covers the changes from 1984-01-01 to 2024-06-08.", notes)
+}
+
+func TestFormatTrainNotes_FirstRelease(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogTemplate+"\n\n### Added\n\n- New feature.\n\n## [0.0.0]", "\n")
+
+	// Act
+	notes := formatTrainNotes(lines, time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC))
+
+	// Assert
+	assert.Equal(t, "This release train covers all the changes up to 2024-06-08.", notes)
+}
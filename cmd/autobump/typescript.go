@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	packageJSONFileName   = "package.json"
+	packageLockFileName   = "package-lock.json"
+	pnpmWorkspaceFileName = "pnpm-workspace.yaml"
+
+	// packageVersionPattern updates the "version" key of a package.json, never the dependency ranges
+	packageVersionPattern = `(?m)(^\s*"version"\s*:\s*")\d+\.\d+\.\d+(")`
+	// packageLockVersionPattern updates the top-level "version" of package-lock.json (always indented by 2 spaces)
+	packageLockVersionPattern = `(?m)(^ {2}"version"\s*:\s*")\d+\.\d+\.\d+(")`
+	// packageLockEntryVersionPattern updates the "version" of a package entry of package-lock.json
+	packageLockEntryVersionPattern = `(%s\s*:\s*\{[^{}]*?"version"\s*:\s*")\d+\.\d+\.\d+(")`
+)
+
+var ErrInvalidPackageJSON = errors.New("invalid package.json")
+
+type PackageJSON struct {
+	Name       string          `json:"name"`
+	Version    string          `json:"version"`
+	Private    bool            `json:"private"`
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+type PnpmWorkspace struct {
+	Packages []string `yaml:"packages"`
+}
+
+type TypeScript struct {
+	ProjectConfig ProjectConfig
+}
+
+func (t TypeScript) GetProjectName() (string, error) {
+	packageJSON, err := readPackageJSON(filepath.Join(t.ProjectConfig.Path, packageJSONFileName))
+	if err != nil {
+		return "", err
+	}
+	return packageJSON.Name, nil
+}
+
+// GetVersionFiles returns the package.json of the workspace members (from the "workspaces" of package.json or
+// from pnpm-workspace.yaml) when the version is propagated to them, along with the entries of package-lock.json
+func (t TypeScript) GetVersionFiles() ([]VersionFile, error) {
+	if !t.ProjectConfig.WorkspacePropagation {
+		return nil, nil
+	}
+
+	projectPath := t.ProjectConfig.Path
+	globs, err := getWorkspaceGlobs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := findWorkspaceMembers(projectPath, globs)
+	if err != nil {
+		return nil, err
+	}
+
+	var versionFiles []VersionFile
+	lockPatterns := []string{packageLockVersionPattern, fmt.Sprintf(packageLockEntryVersionPattern, `""`)}
+	for _, member := range members {
+		packageJSONPath := filepath.Join(projectPath, filepath.FromSlash(member), packageJSONFileName)
+		packageJSON, readErr := readPackageJSON(packageJSONPath)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		// the members without a version aren't published, and the private ones only when configured
+		if packageJSON.Version == "" || (packageJSON.Private && !t.ProjectConfig.PropagateToPrivate) {
+			continue
+		}
+
+		versionFiles = append(versionFiles, VersionFile{
+			Path:     packageJSONPath,
+			Patterns: []string{packageVersionPattern},
+		})
+		lockPatterns = append(
+			lockPatterns, fmt.Sprintf(packageLockEntryVersionPattern, regexp.QuoteMeta(`"`+member+`"`)),
+		)
+	}
+
+	// pnpm and Yarn lock files don't have the versions of the workspace members, only npm does
+	lockPath := filepath.Join(projectPath, packageLockFileName)
+	if _, statErr := os.Stat(lockPath); statErr == nil {
+		versionFiles = append(versionFiles, VersionFile{Path: lockPath, Patterns: lockPatterns})
+	}
+	return versionFiles, nil
+}
+
+// readPackageJSON reads the fields of package.json used by AutoBump
+func readPackageJSON(packageJSONPath string) (PackageJSON, error) {
+	var packageJSON PackageJSON
+	content, err := os.ReadFile(packageJSONPath)
+	if err != nil {
+		return packageJSON, fmt.Errorf("failed to read %s: %w", packageJSONPath, err)
+	}
+
+	err = json.Unmarshal(content, &packageJSON)
+	if err != nil {
+		return packageJSON, fmt.Errorf("%w: %s: %w", ErrInvalidPackageJSON, packageJSONPath, err)
+	}
+	return packageJSON, nil
+}
+
+// getWorkspaceGlobs returns the globs of the workspace members, from pnpm-workspace.yaml or from the "workspaces"
+// of package.json (either a list or the "packages" of an object, like Yarn classic allows)
+func getWorkspaceGlobs(projectPath string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(projectPath, pnpmWorkspaceFileName))
+	if err == nil {
+		var pnpmWorkspace PnpmWorkspace
+		err = yaml.Unmarshal(content, &pnpmWorkspace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", pnpmWorkspaceFileName, err)
+		}
+		return pnpmWorkspace.Packages, nil
+	}
+
+	packageJSON, err := readPackageJSON(filepath.Join(projectPath, packageJSONFileName))
+	if err != nil || len(packageJSON.Workspaces) == 0 {
+		return nil, err
+	}
+
+	var globs []string
+	if json.Unmarshal(packageJSON.Workspaces, &globs) == nil {
+		return globs, nil
+	}
+
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+	err = json.Unmarshal(packageJSON.Workspaces, &workspaces)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported workspaces: %w", ErrInvalidPackageJSON, err)
+	}
+	return workspaces.Packages, nil
+}
+
+// findWorkspaceMembers returns the directories (relative to the project, with slashes) with a package.json
+// matching the workspace globs, excluding the ones matching the negated globs (e.g. "!**/test/**")
+func findWorkspaceMembers(projectPath string, globs []string) ([]string, error) {
+	var included, excluded []*regexp.Regexp
+	for _, glob := range globs {
+		if negated, found := strings.CutPrefix(glob, "!"); found {
+			excluded = append(excluded, workspaceGlobToRegex(negated))
+		} else {
+			included = append(included, workspaceGlobToRegex(glob))
+		}
+	}
+	if len(included) == 0 {
+		return nil, nil
+	}
+
+	var members []string
+	err := filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if entry.Name() == "node_modules" || entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		relativePath, err := filepath.Rel(projectPath, path)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if !matchesAny(included, relativePath) || matchesAny(excluded, relativePath) {
+			return nil
+		}
+		if _, statErr := os.Stat(filepath.Join(path, packageJSONFileName)); statErr == nil {
+			members = append(members, relativePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the workspace members: %w", err)
+	}
+	return members, nil
+}
+
+// workspaceGlobToRegex converts a workspace glob to a regex: "**" matches any directories,
+// "*" and "?" match inside a single directory
+func workspaceGlobToRegex(glob string) *regexp.Regexp {
+	glob = strings.TrimSuffix(strings.TrimPrefix(glob, "./"), "/")
+
+	var builder strings.Builder
+	builder.WriteString("^")
+	for index := 0; index < len(glob); index++ {
+		switch {
+		case strings.HasPrefix(glob[index:], "**/"):
+			builder.WriteString("(?:.*/)?")
+			index += 2
+		case strings.HasPrefix(glob[index:], "**"):
+			builder.WriteString(".*")
+			index++
+		case glob[index] == '*':
+			builder.WriteString("[^/]*")
+		case glob[index] == '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(glob[index])))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.MustCompile(builder.String())
+}
+
+// matchesAny checks whether the path matches one of the regexes
+func matchesAny(regexes []*regexp.Regexp, path string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	log "github.com/sirupsen/logrus"
+)
+
+var credentialsInURLRegex = regexp.MustCompile(`(https?://)[^/\s@]+@`)
+
+var (
+	secretsMutex sync.RWMutex
+	secrets      []string
+)
+
+// sanitizeRemoteURL strips the credentials embedded in an HTTP(S) remote URL,
+// returning the credential-free URL and the credentials as an authentication method (if any)
+func sanitizeRemoteURL(remoteURL string) (string, *http.BasicAuth) {
+	if !strings.HasPrefix(remoteURL, "https://") && !strings.HasPrefix(remoteURL, "http://") {
+		return remoteURL, nil
+	}
+
+	uri, err := url.Parse(remoteURL)
+	if err != nil || uri.User == nil {
+		return remoteURL, nil
+	}
+
+	username := uri.User.Username()
+	password, hasPassword := uri.User.Password()
+	uri.User = nil
+
+	// URLs such as "https://org@dev.azure.com/..." carry only a username, which is not a credential
+	if !hasPassword {
+		return uri.String(), nil
+	}
+
+	return uri.String(), &http.BasicAuth{
+		Username: username,
+		Password: password,
+	}
+}
+
+// isSSHURL checks whether the remote URL uses SSH, either "git@host:path" or "ssh://git@host:port/path"
+func isSSHURL(remoteURL string) bool {
+	return strings.HasPrefix(remoteURL, "git@") || strings.HasPrefix(remoteURL, "ssh://")
+}
+
+// isReadOnlyURL checks whether the remote URL uses the Git protocol, which doesn't support pushes
+func isReadOnlyURL(remoteURL string) bool {
+	return strings.HasPrefix(remoteURL, "git://")
+}
+
+// parseSSHURL splits an SSH remote URL into the host (without the port) and the repository path
+func parseSSHURL(remoteURL string) (string, string, bool) {
+	switch {
+	case strings.HasPrefix(remoteURL, "ssh://"):
+		uri, err := url.Parse(remoteURL)
+		if err != nil || uri.Hostname() == "" {
+			return "", "", false
+		}
+		return uri.Hostname(), strings.Trim(uri.Path, "/"), true
+	case strings.HasPrefix(remoteURL, "git@"):
+		host, path, found := strings.Cut(strings.TrimPrefix(remoteURL, "git@"), ":")
+		return host, strings.Trim(path, "/"), found
+	default:
+		return "", "", false
+	}
+}
+
+// getRepositoryWebURL converts a remote URL (HTTPS, SSH or Git) into the repository web page URL
+func getRepositoryWebURL(remoteURL string) string {
+	remoteURL, _ = sanitizeRemoteURL(strings.TrimSuffix(remoteURL, ".git"))
+	switch {
+	case strings.HasPrefix(remoteURL, "https://") || strings.HasPrefix(remoteURL, "http://"):
+		return remoteURL
+	case isReadOnlyURL(remoteURL):
+		uri, err := url.Parse(remoteURL)
+		if err != nil || uri.Hostname() == "" {
+			return ""
+		}
+		return "https://" + uri.Hostname() + uri.Path
+	case isSSHURL(remoteURL):
+		host, path, found := parseSSHURL(remoteURL)
+		if !found {
+			return ""
+		}
+		if host == "ssh.dev.azure.com" {
+			organization, project, repository, err := parseAzureDevOpsURL(remoteURL)
+			if err != nil {
+				return ""
+			}
+			return fmt.Sprintf("https://dev.azure.com/%s/%s/_git/%s", organization, project, repository)
+		}
+		return "https://" + host + "/" + path
+	default:
+		return ""
+	}
+}
+
+// getRepositoryOrganization returns the owner of the repository (e.g. the GitHub organization,
+// the GitLab top-level group or the Azure DevOps organization)
+func getRepositoryOrganization(remoteURL string) string {
+	uri, err := url.Parse(getRepositoryWebURL(remoteURL))
+	if err != nil {
+		return ""
+	}
+	organization, _, _ := strings.Cut(strings.TrimPrefix(uri.Path, "/"), "/")
+	return organization
+}
+
+// buildCompareURL builds the URL of the page comparing two tags of the repository
+func buildCompareURL(remoteURL string, fromTag string, toTag string) string {
+	webURL := getRepositoryWebURL(remoteURL)
+	if webURL == "" {
+		return ""
+	}
+
+	switch getServiceTypeByURL(remoteURL) { //nolint:exhaustive // unsupported service types have no compare URL
+	case GITHUB:
+		return fmt.Sprintf("%s/compare/%s...%s", webURL, fromTag, toTag)
+	case GITLAB:
+		return fmt.Sprintf("%s/-/compare/%s...%s", webURL, fromTag, toTag)
+	case BITBUCKET:
+		return fmt.Sprintf("%s/branches/compare/%s%%0D%s", webURL, toTag, fromTag)
+	case AZUREDEVOPS:
+		return fmt.Sprintf("%s/branchCompare?baseVersion=GT%s&targetVersion=GT%s", webURL, fromTag, toTag)
+	default:
+		return ""
+	}
+}
+
+// buildBranchCompareURL builds the URL of the page opening a pull request from the branch to the target branch
+func buildBranchCompareURL(remoteURL string, targetBranch string, branch string) string {
+	webURL := getRepositoryWebURL(remoteURL)
+	if webURL == "" {
+		return ""
+	}
+
+	target, source := url.QueryEscape(targetBranch), url.QueryEscape(branch)
+	switch getServiceTypeByURL(remoteURL) { //nolint:exhaustive // unsupported service types have no compare URL
+	case GITHUB:
+		return fmt.Sprintf("%s/compare/%s...%s?expand=1", webURL, target, source)
+	case GITLAB:
+		return fmt.Sprintf(
+			"%s/-/merge_requests/new?merge_request%%5Bsource_branch%%5D=%s&merge_request%%5Btarget_branch%%5D=%s",
+			webURL, source, target,
+		)
+	case BITBUCKET:
+		return fmt.Sprintf("%s/pull-requests/new?source=%s&dest=%s", webURL, source, target)
+	case AZUREDEVOPS:
+		return fmt.Sprintf("%s/pullrequestcreate?sourceRef=%s&targetRef=%s", webURL, source, target)
+	default:
+		return ""
+	}
+}
+
+// redactURLCredentials replaces credentials embedded in any HTTP(S) URL found in the text
+func redactURLCredentials(text string) string {
+	return credentialsInURLRegex.ReplaceAllString(text, "${1}***@")
+}
+
+// registerSecret records a secret value (e.g. an API header) that must never be logged
+func registerSecret(secret string) {
+	if secret == "" {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	secrets = append(secrets, secret)
+}
+
+// redactSecrets replaces the registered secret values found in the text
+func redactSecrets(text string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, "***")
+	}
+	return text
+}
+
+// registerEmbeddedCredentials removes the credentials from the project path
+// and keeps them as the highest-priority authentication method for that project
+func registerEmbeddedCredentials(projectConfig *ProjectConfig) {
+	sanitizedPath, auth := sanitizeRemoteURL(projectConfig.Path)
+	projectConfig.Path = sanitizedPath
+	if auth != nil {
+		projectConfig.embeddedAuth = auth
+	}
+}
+
+// credentialsRedactingHook is a logger hook that prevents credentials embedded in URLs
+// and the registered secrets (e.g. the API header values) from being logged, in the messages and in the fields
+type credentialsRedactingHook struct{}
+
+func (h *credentialsRedactingHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *credentialsRedactingHook) Fire(entry *log.Entry) error {
+	entry.Message = redactSecrets(redactURLCredentials(entry.Message))
+	// the fields of the entry are a copy, so they can be replaced
+	for key, value := range entry.Data {
+		var text string
+		switch typedValue := value.(type) {
+		case string:
+			text = typedValue
+		case error:
+			text = typedValue.Error()
+		default:
+			continue
+		}
+		if redacted := redactSecrets(redactURLCredentials(text)); redacted != text {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	log "github.com/sirupsen/logrus"
@@ -18,16 +19,47 @@ import (
 
 var (
 	ErrFileNotFound                         = errors.New("file not found")
+	ErrFileTooLarge                         = errors.New("file exceeds the maximum file size")
+	ErrNotRegularFile                       = errors.New("not a regular file")
 	ErrCannotFindPrivKey                    = errors.New("cannot find private key")
 	ErrCannotFindPrivKeyMatchingFingerprint = errors.New(
 		"cannot find private key matching fingerprint",
 	)
 )
 
-const downloadTimeout = 10
+const downloadTimeout = 10 * time.Second
+
+// checkFileSize ensures the file is a regular file not bigger than the maximum file size
+func checkFileSize(filePath string, maxFileSize int64) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+	}
+
+	if info.Size() > maxFileSize {
+		return fmt.Errorf(
+			"%w: %s has %s (limit is %s)",
+			ErrFileTooLarge,
+			filePath,
+			formatBytes(info.Size()),
+			formatBytes(maxFileSize),
+		)
+	}
+
+	return nil
+}
+
+// readLines reads a whole file into memory, refusing files bigger than the maximum file size
+func readLines(filePath string, maxFileSize int64) ([]string, error) {
+	err := checkFileSize(filePath, maxFileSize)
+	if err != nil {
+		return nil, err
+	}
 
-// readLines reads a whole file into memory
-func readLines(filePath string) ([]string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -84,6 +116,11 @@ func downloadFile(url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	// the error pages (e.g. of the rate limits) must not be taken for the content
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned %d", ErrFailedToDownload, url, resp.StatusCode)
+	}
+
 	data, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
@@ -109,11 +146,11 @@ func exportGpgKey(gpgKeyID string, gpgKeyExportPath string) error {
 	return nil
 }
 
-func getGpgKeyReader(gpgKeyID string, gpgKeyPath string) (*io.Reader, error) {
+func getGpgKeyReader(gpgKeyID string, gpgKeyPath string, logger *log.Entry) (*io.Reader, error) {
 	// if no key path is provided, try to read the key from the default location
 	if gpgKeyPath == "" {
 		gpgKeyPath = os.ExpandEnv(fmt.Sprintf("$HOME/.gnupg/autobump-%s.asc", gpgKeyID))
-		log.Warnf("No key path provided, attempting to read (%s) at: %s", gpgKeyID, gpgKeyPath)
+		logger.Warnf("No key path provided, attempting to read (%s) at: %s", gpgKeyID, gpgKeyPath)
 
 		// if the key does not exist, try to export it from the keyring
 		if _, err := os.Stat(gpgKeyPath); os.IsNotExist(err) {
@@ -136,7 +173,7 @@ func getGpgKeyReader(gpgKeyID string, gpgKeyPath string) (*io.Reader, error) {
 
 // getGpgKey returns GPG key entity from the given path
 // it prompts for the passphrase to decrypt the key
-func getGpgKey(gpgKeyReader io.Reader) (*openpgp.Entity, error) {
+func getGpgKey(gpgKeyReader io.Reader, logger *log.Entry) (*openpgp.Entity, error) {
 	var err error
 
 	entityList, err := openpgp.ReadArmoredKeyRing(gpgKeyReader)
@@ -171,6 +208,6 @@ func getGpgKey(gpgKeyReader io.Reader) (*openpgp.Entity, error) {
 		return nil, fmt.Errorf("failed to decrypt GPG key: %w", err)
 	}
 
-	log.Info("Successfully decrypted GPG key")
+	logger.Info("Successfully decrypted GPG key")
 	return entity, nil
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
@@ -52,7 +54,7 @@ func TestGetGpgKey_ValidKey(t *testing.T) {
 	require.NoError(t, err)
 
 	// Act
-	key, err := getGpgKey(gpgKeyReader)
+	key, err := getGpgKey(gpgKeyReader, standardLogEntry())
 
 	// Assert
 	require.NoError(t, err)
@@ -67,7 +69,7 @@ func TestGetGpgKey_InvalidReader(t *testing.T) {
 	gpgKeyReader := bytes.NewReader([]byte("invalid key data"))
 
 	// Act
-	_, err := getGpgKey(gpgKeyReader)
+	_, err := getGpgKey(gpgKeyReader, standardLogEntry())
 
 	// Assert
 	require.Error(t, err)
@@ -78,3 +80,21 @@ func TestGetGpgKey_InvalidReader(t *testing.T) {
 	expectedErrMsg := "failed to read private key file: openpgp: invalid argument: no armored data found"
 	assert.Equal(t, expectedErrMsg, err.Error())
 }
+
+func TestReadLines_FileTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	filePath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	file, err := os.Create(filePath)
+	require.NoError(t, err)
+	require.NoError(t, file.Truncate(defaultMaxFileSize+1)) // sparse file, no disk usage
+	require.NoError(t, file.Close())
+
+	// Act
+	_, err = readLines(filePath, defaultMaxFileSize)
+
+	// Assert
+	require.ErrorIs(t, err, ErrFileTooLarge)
+	assert.Contains(t, err.Error(), filePath)
+}
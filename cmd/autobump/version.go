@@ -0,0 +1,5 @@
+package main
+
+// version is the version of the running binary, set at build time with:
+// go build -ldflags "-X main.version=1.2.3" ./cmd/autobump
+var version = "dev"
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// projectStatusVersionVetoed is the status of the projects skipped because the version policy vetoed the bump
+	projectStatusVersionVetoed = "version-vetoed"
+
+	// maximum time the version policy command can run before the bump is aborted
+	versionPolicyTimeout = time.Minute
+)
+
+var (
+	ErrVersionVetoed         = errors.New("the version policy vetoed the bump")
+	ErrVersionPolicyFailed   = errors.New("the version policy command failed")
+	ErrInvalidPolicyVersion  = errors.New("invalid version returned by the version policy")
+	ErrEmptyVersionPolicyCmd = errors.New("empty version_policy.command")
+)
+
+// versionPolicyInput is the explanation of the computed version, written as JSON to the policy command
+type versionPolicyInput struct {
+	PreviousVersion string `json:"previous_version"`
+	ComputedVersion string `json:"computed_version"`
+	Bump            string `json:"bump"`
+	MajorChanges    int    `json:"major_changes"`
+	MinorChanges    int    `json:"minor_changes"`
+	PatchChanges    int    `json:"patch_changes"`
+	// entries removed because they are duplicated in a section with a higher precedence
+	RemovedDuplicates []string `json:"removed_duplicates,omitempty"`
+}
+
+// isPre1BreakingMinor checks whether the breaking changes bump the minor version,
+// because the project is still in the initial development (0.y.z) and the policy says so
+func isPre1BreakingMinor(policy VersionPolicyConfig, previousVersion semver.Version) bool {
+	return policy.Pre1BreakingIsMinor && previousVersion.Major() == 0
+}
+
+// applyVersionPolicyCommand runs the version policy command (if any) with the computed version,
+// returning the version it prints (the computed one when it prints nothing).
+// The bump is vetoed when the command exits with a non-zero code, its output being the reason.
+func applyVersionPolicyCommand(
+	policy VersionPolicyConfig,
+	workingDir string,
+	input versionPolicyInput,
+	computedVersion semver.Version,
+	previousVersion semver.Version,
+	logger *log.Entry,
+) (semver.Version, error) {
+	if policy.Command == "" {
+		return computedVersion, nil
+	}
+
+	args := strings.Fields(policy.Command)
+	if len(args) == 0 {
+		return computedVersion, ErrEmptyVersionPolicyCmd
+	}
+
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return computedVersion, fmt.Errorf("failed to encode the version policy input: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionPolicyTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // the command comes from the configuration
+	cmd.Dir = workingDir
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	if ctx.Err() != nil {
+		return computedVersion, fmt.Errorf("%w: timed out after %s", ErrVersionPolicyFailed, versionPolicyTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		reason := firstNonEmpty(strings.TrimSpace(stderr.String()), strings.TrimSpace(stdout.String()), exitErr.Error())
+		return computedVersion, fmt.Errorf("%w: %s", ErrVersionVetoed, reason)
+	}
+	if err != nil {
+		return computedVersion, fmt.Errorf("%w: %w", ErrVersionPolicyFailed, err)
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return computedVersion, nil
+	}
+
+	overriddenVersion, err := semver.NewVersion(output)
+	if err != nil {
+		return computedVersion, fmt.Errorf("%w: %q: %w", ErrInvalidPolicyVersion, output, err)
+	}
+	if !overriddenVersion.GreaterThan(&previousVersion) {
+		return computedVersion, fmt.Errorf(
+			"%w: %s isn't greater than the previous version %s",
+			ErrInvalidPolicyVersion, versionString(overriddenVersion), versionString(&previousVersion),
+		)
+	}
+
+	if !overriddenVersion.Equal(&computedVersion) {
+		logger.Infof(
+			"The version policy overrode the computed version %s with %s",
+			versionString(&computedVersion), versionString(overriddenVersion),
+		)
+	}
+	return *overriddenVersion, nil
+}
+
+// isVersionVetoed computes the next version before creating the bump branch,
+// so the bumps vetoed by the version policy command are skipped without changing anything
+func isVersionVetoed(ctx *RepoContext, changelogPath string) (bool, error) {
+	if ctx.globalConfig.VersionPolicy.Command == "" {
+		return false, nil
+	}
+
+	_, err := getNextReleaseName(ctx, changelogPath)
+	if errors.Is(err, ErrVersionVetoed) {
+		ctx.status = projectStatusVersionVetoed
+		ctx.logger().Warnf("Skipping project %s (%s): %v", ctx.projectConfig.Name, projectStatusVersionVetoed, err)
+		return true, nil
+	}
+	return false, err
+}
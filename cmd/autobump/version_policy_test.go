@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// breakingChangelog has a breaking change to be released after a version of the initial development
+const breakingChangelog = `# Changelog
+
+## [Unreleased]
+
+### Changed
+
+- **BREAKING CHANGE:** removed the deprecated flags
+
+## [0.4.2] - 2024-01-01
+
+### Added
+
+- added the first feature
+`
+
+func TestProcessChangelog_Pre1BreakingIsMinor(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(breakingChangelog, "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Pre1BreakingIsMinor: true}}
+
+	// Act
+	version, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "0.5.0", versionString(version))
+}
+
+func TestProcessChangelog_Pre1BreakingIsMajorByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(breakingChangelog, "\n")
+
+	// Act
+	version, _, err := processChangelog(changelog, ChangelogConfig{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", versionString(version))
+}
+
+func TestProcessChangelog_Pre1BreakingIsMinorAfterStable(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(strings.ReplaceAll(breakingChangelog, "0.4.2", "1.4.2"), "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Pre1BreakingIsMinor: true}}
+
+	// Act
+	version, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", versionString(version))
+}
+
+func TestProcessChangelog_VersionPolicyCommandNotFound(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Split(breakingChangelog, "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Command: "autobump-missing-policy"}}
+
+	// Act
+	_, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrVersionPolicyFailed)
+}
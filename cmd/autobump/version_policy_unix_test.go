@@ -0,0 +1,151 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionPolicyCommand writes an executable version policy script, returning its path
+func newVersionPolicyCommand(t *testing.T, script string) string {
+	t.Helper()
+
+	commandPath := filepath.Join(t.TempDir(), "policy.sh")
+	require.NoError(t, os.WriteFile(commandPath, []byte(script), 0o700)) //nolint:gosec // the script must be executable
+	return commandPath
+}
+
+func TestProcessChangelog_VersionPolicyOverride(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	inputPath := filepath.Join(t.TempDir(), "input.json")
+	commandPath := newVersionPolicyCommand(t, "#!/bin/sh\ncat > "+inputPath+"\necho 0.4.3\n")
+	changelog := strings.Split(breakingChangelog, "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Command: commandPath}}
+
+	// Act
+	version, newChangelog, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "0.4.3", versionString(version))
+	assert.Contains(t, strings.Join(newChangelog, "\n"), "## [0.4.3]")
+
+	input, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"previous_version": "0.4.2",
+		"computed_version": "1.0.0",
+		"bump": "major",
+		"major_changes": 1,
+		"minor_changes": 0,
+		"patch_changes": 0
+	}`, string(input))
+}
+
+func TestProcessChangelog_VersionPolicyKeepsComputedVersion(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	commandPath := newVersionPolicyCommand(t, "#!/bin/sh\ncat > /dev/null\n")
+	changelog := strings.Split(breakingChangelog, "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Command: commandPath}}
+
+	// Act
+	version, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", versionString(version))
+}
+
+func TestProcessChangelog_VersionPolicyVeto(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	commandPath := newVersionPolicyCommand(t, "#!/bin/sh\necho 'no major releases this quarter' >&2\nexit 1\n")
+	changelog := strings.Split(breakingChangelog, "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Command: commandPath}}
+
+	// Act
+	_, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrVersionVetoed)
+	assert.ErrorContains(t, err, "no major releases this quarter")
+}
+
+func TestProcessChangelog_VersionPolicyRejectsOlderVersion(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	commandPath := newVersionPolicyCommand(t, "#!/bin/sh\necho 0.4.1\n")
+	changelog := strings.Split(breakingChangelog, "\n")
+	changelogConfig := ChangelogConfig{VersionPolicy: VersionPolicyConfig{Command: commandPath}}
+
+	// Act
+	_, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidPolicyVersion)
+}
+
+func TestIsVersionVetoed_SkipsProject(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	changelogPath := filepath.Join(projectPath, "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(changelogPath, []byte(breakingChangelog), 0o600))
+	commandPath := newVersionPolicyCommand(t, "#!/bin/sh\necho 'frozen'\nexit 3\n")
+	ctx := &RepoContext{
+		globalConfig:  &GlobalConfig{VersionPolicy: VersionPolicyConfig{Command: commandPath}},
+		projectConfig: &ProjectConfig{Name: "project", Path: projectPath},
+	}
+
+	// Act
+	vetoed, err := isVersionVetoed(ctx, changelogPath)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, vetoed)
+	assert.Equal(t, projectStatusVersionVetoed, ctx.status)
+}
+
+func TestProcessChangelog_VersionPolicyReceivesRemovedDuplicates(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	inputPath := filepath.Join(t.TempDir(), "input.json")
+	commandPath := newVersionPolicyCommand(t, "#!/bin/sh\ncat > "+inputPath+"\n")
+	changelog := strings.Split(crossSectionChangelog, "\n")
+	changelogConfig := ChangelogConfig{
+		CrossSectionDedup: true,
+		DedupPrecedence:   []string{"Changed", "Added"},
+		VersionPolicy:     VersionPolicyConfig{Command: commandPath},
+	}
+
+	// Act
+	_, _, err := processChangelog(changelog, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	input, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"previous_version": "1.2.0",
+		"computed_version": "1.2.1",
+		"bump": "patch",
+		"major_changes": 0,
+		"minor_changes": 0,
+		"patch_changes": 1,
+		"removed_duplicates": ["removed \"- added the export of the reports to CSV\" from \"Added\", duplicated in \"Changed\""]
+	}`, string(input))
+}
@@ -18,51 +18,136 @@ var (
 
 // updateVersion updates the version in the version files.
 // This function fails fast upon the first error.
-func updateVersion(globalConfig *GlobalConfig, projectConfig *ProjectConfig) error {
-	versionFiles, err := getVersionFiles(globalConfig, projectConfig)
+func updateVersion(
+	globalConfig *GlobalConfig, projectConfig *ProjectConfig, state *projectState, logger *log.Entry,
+) error {
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, state, logger)
 	if err != nil {
 		return err
 	}
 
+	version, err := getVersionFilesVersion(projectConfig)
+	if err != nil {
+		return err
+	}
+	if version != projectConfig.NewVersion {
+		logger.Infof("Writing the next development version %s in the version files", version)
+		versionFiles = widenVersionPatterns(versionFiles)
+	}
+	state.versionFilesVersion = version
+
+	oneVersionFileExists, err := updateVersionFiles(globalConfig, versionFiles, version, logger)
+	if err != nil {
+		return err
+	}
+
+	if !oneVersionFileExists {
+		return fmt.Errorf("%w: %s", ErrNoVersionFileFound, projectConfig.Language)
+	}
+
+	return nil
+}
+
+// validateVersionFiles resolves the version files of the project and of its version streams, so a misconfigured
+// path (e.g. outside the repository) fails the project before anything is changed
+func validateVersionFiles(
+	globalConfig *GlobalConfig, projectConfig *ProjectConfig, state *projectState, logger *log.Entry,
+) error {
+	_, err := getVersionFiles(globalConfig, projectConfig, state, logger)
+	if err != nil {
+		return err
+	}
+
+	for _, stream := range projectConfig.VersionStreams {
+		_, err = getStreamVersionFiles(globalConfig, projectConfig, stream, logger)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateVersionFiles writes the version in the given files, returning whether at least one of them exists
+func updateVersionFiles(
+	globalConfig *GlobalConfig, versionFiles []VersionFile, version string, logger *log.Entry,
+) (bool, error) {
 	oneVersionFileExists := false
 	for _, versionFile := range versionFiles {
 		// check if the file exists
-		var info os.FileInfo
-		info, err = os.Stat(versionFile.Path)
+		info, err := os.Stat(versionFile.Path)
 		if os.IsNotExist(err) {
-			log.Warnf("Version file %s does not exist", versionFile.Path)
+			logger.Warnf("Version file %s does not exist", versionFile.Path)
 			continue
 		}
-		log.Infof("Updating version file %s", versionFile.Path)
+		logger.Infof("Updating version file %s", versionFile.Path)
 
 		originalFileMode := info.Mode()
 		oneVersionFileExists = true
 
+		err = checkFileSize(versionFile.Path, getMaxFileSize(globalConfig))
+		if err != nil {
+			return oneVersionFileExists, err
+		}
+
 		var content []byte
 		content, err = os.ReadFile(versionFile.Path)
 		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", versionFile.Path, err)
+			return oneVersionFileExists, fmt.Errorf("failed to read file %s: %w", versionFile.Path, err)
 		}
 
-		updatedContent := string(content)
+		// the patterns are applied on the decoded text, so the BOM and the line endings are kept as they were
+		updatedContent, encoding := decodeText(content)
 		for _, pattern := range versionFile.Patterns {
 			re := regexp.MustCompile(pattern)
 			updatedContent = re.ReplaceAllStringFunc(updatedContent, func(match string) string {
-				return re.ReplaceAllString(match, "${1}"+projectConfig.NewVersion+"${2}")
+				return re.ReplaceAllString(match, "${1}"+keepVersionPrefix(re, match, version)+"${2}")
 			})
 		}
 
-		err = os.WriteFile(versionFile.Path, []byte(updatedContent), originalFileMode)
+		err = os.WriteFile(versionFile.Path, encoding.encode(updatedContent), originalFileMode)
 		if err != nil {
-			return fmt.Errorf("failed to write to file %s: %w", versionFile.Path, err)
+			return oneVersionFileExists, fmt.Errorf("failed to write to file %s: %w", versionFile.Path, err)
 		}
 	}
 
-	if !oneVersionFileExists {
-		return fmt.Errorf("%w: %s", ErrNoVersionFileFound, projectConfig.Language)
+	return oneVersionFileExists, nil
+}
+
+// utf8BOM is the byte order mark written by some editors (e.g. Visual Studio) at the start of UTF-8 files
+const utf8BOM = "\ufeff"
+
+// textEncoding is how a text file was written: with or without a BOM, and with CRLF or LF line endings
+type textEncoding struct {
+	bom  bool
+	crlf bool
+}
+
+// decodeText removes the BOM and converts the line endings to LF, when CRLF are the most used ones
+func decodeText(content []byte) (string, textEncoding) {
+	text := string(content)
+	var encoding textEncoding
+	if strings.HasPrefix(text, utf8BOM) {
+		encoding.bom = true
+		text = strings.TrimPrefix(text, utf8BOM)
 	}
 
-	return nil
+	crlfs := strings.Count(text, "\r\n")
+	if crlfs > strings.Count(text, "\n")-crlfs {
+		encoding.crlf = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	return text, encoding
+}
+
+// encode writes the text back with the BOM and the line endings it was read with
+func (e textEncoding) encode(text string) []byte {
+	if e.crlf {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	if e.bom {
+		text = utf8BOM + text
+	}
+	return []byte(text)
 }
 
 // getVersionFiles returns the files in a project that contains the software's version number
@@ -70,6 +155,8 @@ func updateVersion(globalConfig *GlobalConfig, projectConfig *ProjectConfig) err
 func getVersionFiles(
 	globalConfig *GlobalConfig,
 	projectConfig *ProjectConfig,
+	state *projectState,
+	logger *log.Entry,
 ) ([]VersionFile, error) {
 	if projectConfig.Name == "" {
 		projectConfig.Name = filepath.Base(projectConfig.Path)
@@ -84,11 +171,11 @@ func getVersionFiles(
 	if languageInterface != nil {
 		languageProjectName, err := languageInterface.GetProjectName()
 		if err == nil && languageProjectName != "" {
-			log.Infof("Using project name '%s' from language interface", languageProjectName)
+			logger.Infof("Using project name '%s' from language interface", languageProjectName)
 			projectName = strings.ReplaceAll(languageProjectName, "-", "_")
 		}
 	} else {
-		log.Infof("Language '%s' does not have a language interface", projectConfig.Language)
+		logger.Infof("Language '%s' does not have a language interface", projectConfig.Language)
 	}
 
 	languageConfig, exists := globalConfig.LanguagesConfig[projectConfig.Language]
@@ -96,7 +183,20 @@ func getVersionFiles(
 		return nil, fmt.Errorf("%w: %s", ErrLanguageNotFoundInConfig, projectConfig.Language)
 	}
 
+	var ignorePatterns []ignorePattern
+	ignorePatternsRead := false
 	for _, versionFile := range languageConfig.VersionFiles {
+		// the files matched by a glob might be generated (e.g. in "build/"), unlike the ones listed explicitly
+		checkIgnored := !projectConfig.AllowIgnoredVersionFiles && strings.ContainsAny(versionFile.Path, "*?[")
+		if checkIgnored && !ignorePatternsRead {
+			var err error
+			ignorePatterns, err = readIgnorePatterns(projectConfig.Path)
+			if err != nil {
+				return nil, err
+			}
+			ignorePatternsRead = true
+		}
+
 		matches, err := filepath.Glob(
 			filepath.Join(
 				projectConfig.Path,
@@ -107,6 +207,24 @@ func getVersionFiles(
 			return nil, fmt.Errorf("failed to get version files: %w", err)
 		}
 		for _, match := range matches {
+			// never touch files outside the repository (e.g. "../shared/version.txt")
+			_, err = ensureWithinRepo(projectConfig.Path, match)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"invalid version file %q of language %s: %w", versionFile.Path, projectConfig.Language, err,
+				)
+			}
+
+			// skip files that can't be safely read (e.g. sockets, devices and huge files)
+			err = checkFileSize(match, getMaxFileSize(globalConfig))
+			if err != nil {
+				logger.Warnf("Skipping version file: %v", err)
+				continue
+			}
+			if checkIgnored && skipIgnoredVersionFile(projectConfig, state, ignorePatterns, match, logger) {
+				continue
+			}
+
 			versionFiles = append(
 				versionFiles, VersionFile{
 					Path:     match,
@@ -115,5 +233,19 @@ func getVersionFiles(
 			)
 		}
 	}
+
+	if versionFilesLanguage, ok := languageInterface.(VersionFilesLanguage); ok {
+		languageVersionFiles, err := versionFilesLanguage.GetVersionFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get version files of %s: %w", projectConfig.Language, err)
+		}
+		for _, versionFile := range languageVersionFiles {
+			_, err = ensureWithinRepo(projectConfig.Path, versionFile.Path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version file of language %s: %w", projectConfig.Language, err)
+			}
+		}
+		versionFiles = append(versionFiles, languageVersionFiles...)
+	}
 	return versionFiles, nil
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionFilesFixture creates a project with a version file and the config pointing to it
+func newVersionFilesFixture(t *testing.T) (*GlobalConfig, *ProjectConfig) {
+	t.Helper()
+
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "version.txt"), []byte("version=1.0.0\n"), 0o600))
+
+	globalConfig := &GlobalConfig{
+		LanguagesConfig: map[string]LanguageConfig{
+			"text": {
+				VersionFiles: []VersionFile{
+					{Path: "*.txt", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}},
+					{Path: "*.db", Patterns: []string{`(version=)\d+\.\d+\.\d+()`}},
+				},
+			},
+		},
+	}
+	projectConfig := &ProjectConfig{Path: projectPath, Name: "project", Language: "text"}
+	return globalConfig, projectConfig
+}
+
+func TestGetVersionFiles_SkipsLargeFiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newVersionFilesFixture(t)
+	largeFile, err := os.Create(filepath.Join(projectConfig.Path, "huge.db"))
+	require.NoError(t, err)
+	require.NoError(t, largeFile.Truncate(defaultMaxFileSize+1)) // sparse file, no disk usage
+	require.NoError(t, largeFile.Close())
+
+	// Act
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, versionFiles, 1)
+	assert.Equal(t, filepath.Join(projectConfig.Path, "version.txt"), versionFiles[0].Path)
+}
+
+func TestUpdateVersion_ConfiguredMaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newVersionFilesFixture(t)
+	globalConfig.MaxFileSize = 4
+	projectConfig.NewVersion = "1.1.0"
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.ErrorIs(t, err, ErrNoVersionFileFound)
+}
+
+func TestUpdateVersion_Success(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newVersionFilesFixture(t)
+	projectConfig.NewVersion = "1.1.0"
+
+	// Act
+	err := updateVersion(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(projectConfig.Path, "version.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "version=1.1.0\n", string(content))
+}
+
+func TestUpdateVersionFiles_PreservesEncoding(t *testing.T) {
+	t.Parallel()
+
+	// the patterns of the C# projects start with "\s*", so they match the line endings before the tags
+	patterns := []string{
+		`(\s*<Version>)\d+\.\d+\.\d+(</Version>)`,
+		`(\s*<AssemblyVersion>)\d+\.\d+\.\d+(</AssemblyVersion>)`,
+		`(\s*<FileVersion>)\d+\.\d+\.\d+(</FileVersion>)`,
+	}
+
+	for _, fixture := range []string{"bom_crlf", "lf"} {
+		t.Run(fixture, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			original, err := os.ReadFile(filepath.Join("testdata", "versioning", fixture, "Project.csproj"))
+			require.NoError(t, err)
+			versionFilePath := filepath.Join(t.TempDir(), "Project.csproj")
+			require.NoError(t, os.WriteFile(versionFilePath, original, 0o600))
+			versionFiles := []VersionFile{{Path: versionFilePath, Patterns: patterns}}
+
+			// Act
+			exists, err := updateVersionFiles(&GlobalConfig{}, versionFiles, "1.1.0", standardLogEntry())
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, exists)
+			content, err := os.ReadFile(versionFilePath)
+			require.NoError(t, err)
+			assert.Equal(t, bytes.ReplaceAll(original, []byte("1.0.0"), []byte("1.1.0")), content)
+		})
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		text     string
+		encoding textEncoding
+	}{
+		{"LF", "a\nb\n", "a\nb\n", textEncoding{}},
+		{"CRLF with BOM", utf8BOM + "a\r\nb\r\n", "a\nb\n", textEncoding{bom: true, crlf: true}},
+		{"mostly LF", "a\r\nb\nc\n", "a\r\nb\nc\n", textEncoding{}},
+		{"no line ending", "a", "a", textEncoding{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			text, encoding := decodeText([]byte(test.content))
+
+			// Assert
+			assert.Equal(t, test.text, text)
+			assert.Equal(t, test.encoding, encoding)
+			assert.Equal(t, test.content, string(encoding.encode(text)), "the content must round-trip")
+		})
+	}
+}
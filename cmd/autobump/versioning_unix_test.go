@@ -0,0 +1,28 @@
+//go:build unix
+
+package main
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersionFiles_SkipsNonRegularFiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	globalConfig, projectConfig := newVersionFilesFixture(t)
+	require.NoError(t, syscall.Mkfifo(filepath.Join(projectConfig.Path, "pipe.db"), 0o600))
+
+	// Act
+	versionFiles, err := getVersionFiles(globalConfig, projectConfig, &projectState{}, standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, versionFiles, 1)
+	assert.Equal(t, filepath.Join(projectConfig.Path, "version.txt"), versionFiles[0].Path)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// versionPrefixV is the prefix of the versions written as "v1.4.2"
+	versionPrefixV = "v"
+	// versionPrefixAuto follows the prevailing style of the CHANGELOG headers, or of the tags without any header
+	versionPrefixAuto = "auto"
+)
+
+var ErrInvalidVersionPrefix = errors.New("invalid version_prefix")
+
+// validateVersionPrefix checks the prefix of the released versions
+func validateVersionPrefix(projectConfig *ProjectConfig) error {
+	switch projectConfig.VersionPrefix {
+	case "", versionPrefixV, versionPrefixAuto:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: %q (expected %q, %q or %s)",
+			ErrInvalidVersionPrefix, projectConfig.VersionPrefix, versionPrefixV, "", versionPrefixAuto,
+		)
+	}
+}
+
+// resolveVersionPrefix returns the prefix written before the released version in the CHANGELOG header, the bump
+// branch, the compare links and the pull request title. The version streams have their own header prefix.
+func resolveVersionPrefix(
+	projectConfig *ProjectConfig, lines []string, repo *git.Repository, logger *log.Entry,
+) string {
+	if projectConfig.VersionPrefix != versionPrefixAuto || len(projectConfig.VersionStreams) > 0 {
+		return projectConfig.VersionPrefix
+	}
+
+	headerPrefix, headerFound := detectHeaderVersionPrefix(lines, logger)
+	tagPrefix, tagFound := detectTagVersionPrefix(repo, logger)
+	switch {
+	case headerFound && tagFound && headerPrefix != tagPrefix:
+		logger.Warnf(
+			"The CHANGELOG headers are written with the prefix %q while the tags use %q, following the headers",
+			headerPrefix, tagPrefix,
+		)
+		return headerPrefix
+	case headerFound:
+		return headerPrefix
+	case tagFound:
+		return tagPrefix
+	default:
+		return ""
+	}
+}
+
+// detectHeaderVersionPrefix returns the prefix of most of the released headers, the one of the latest header when
+// there are as many of both, and whether any header was released
+func detectHeaderVersionPrefix(lines []string, logger *log.Entry) (string, bool) {
+	var versions []string
+	for _, line := range lines[getFrontMatterLength(lines):] {
+		match := versionHeaderRegex.FindStringSubmatch(line)
+		if match != nil && match[1] != "Unreleased" {
+			versions = append(versions, match[1])
+		}
+	}
+
+	prefix, mixed := getPrevailingVersionPrefix(versions)
+	if mixed {
+		logger.Warnf("The CHANGELOG headers are written with and without the \"v\" prefix, following the %q ones", prefix)
+	}
+	return prefix, len(versions) > 0
+}
+
+// detectTagVersionPrefix returns the prefix of most of the version tags of the repository, and whether there is any
+func detectTagVersionPrefix(repo *git.Repository, logger *log.Entry) (string, bool) {
+	if repo == nil {
+		return "", false
+	}
+	tags, err := repo.Tags()
+	if err != nil {
+		logger.Warnf("Unable to list the tags to detect the version prefix: %v", err)
+		return "", false
+	}
+
+	var versions []string
+	_ = tags.ForEach(func(tag *plumbing.Reference) error {
+		if _, parseErr := semver.StrictNewVersion(strings.TrimPrefix(tag.Name().Short(), versionPrefixV)); parseErr == nil {
+			versions = append(versions, tag.Name().Short())
+		}
+		return nil
+	})
+
+	prefix, _ := getPrevailingVersionPrefix(versions)
+	return prefix, len(versions) > 0
+}
+
+// getPrevailingVersionPrefix returns the prefix of most of the versions, the one of the first version when there are
+// as many of both, and whether the versions mix both
+func getPrevailingVersionPrefix(versions []string) (string, bool) {
+	prefixed := 0
+	for _, version := range versions {
+		if strings.HasPrefix(version, versionPrefixV) {
+			prefixed++
+		}
+	}
+
+	bare := len(versions) - prefixed
+	switch {
+	case prefixed > bare, prefixed == bare && prefixed > 0 && strings.HasPrefix(versions[0], versionPrefixV):
+		return versionPrefixV, bare > 0
+	default:
+		return "", prefixed > 0
+	}
+}
+
+// keepVersionPrefix writes the version with the "v" prefix when the value replaced in the version file carried it,
+// matched between the two groups of the pattern
+func keepVersionPrefix(re *regexp.Regexp, match string, version string) string {
+	indexes := re.FindStringSubmatchIndex(match)
+	if len(indexes) < 6 || indexes[3] < 0 || indexes[4] < indexes[3] {
+		return version
+	}
+	if strings.HasPrefix(match[indexes[3]:indexes[4]], versionPrefixV) {
+		return versionPrefixV + version
+	}
+	return version
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTaggedRepo creates a repository with a commit tagged with each of the tags
+func newTaggedRepo(t *testing.T, tags ...string) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+	for _, tag := range tags {
+		hash := commitFile(t, repo, "version.txt", tag+"\n")
+		_, err = repo.CreateTag(tag, hash, nil)
+		require.NoError(t, err)
+	}
+	return repo
+}
+
+func TestResolveVersionPrefix(t *testing.T) {
+	t.Parallel()
+
+	prefixedHeaders := strings.Split(strings.ReplaceAll(changelogOriginal, "## [1.0.1]", "## [v1.0.1]"), "\n")
+	bareHeaders := strings.Split(changelogOriginal, "\n")
+	mixedHeaders := append(
+		strings.Split(strings.ReplaceAll(changelogOriginal, "## [1.0.1]", "## [v1.0.1]"), "\n"),
+		"## [1.0.0] - 1983-01-01", "## [v0.9.0] - 1982-01-01",
+	)
+	noHeaders := strings.Split(changelogTemplate, "\n")
+
+	tests := []struct {
+		name     string
+		prefix   string
+		lines    []string
+		tags     []string
+		expected string
+	}{
+		{name: "should follow the prefixed headers", prefix: versionPrefixAuto, lines: prefixedHeaders, expected: "v"},
+		{name: "should follow the bare headers", prefix: versionPrefixAuto, lines: bareHeaders, expected: ""},
+		{
+			name:     "should follow the tags without any released header",
+			prefix:   versionPrefixAuto,
+			lines:    noHeaders,
+			tags:     []string{"v0.9.0", "v1.0.0", "nightly"},
+			expected: "v",
+		},
+		{
+			name:     "should prefer the headers to the tags",
+			prefix:   versionPrefixAuto,
+			lines:    bareHeaders,
+			tags:     []string{"v1.0.0", "v1.0.1"},
+			expected: "",
+		},
+		{
+			name:     "should follow most of the headers when they are mixed",
+			prefix:   versionPrefixAuto,
+			lines:    mixedHeaders,
+			tags:     []string{"1.0.0"},
+			expected: "v",
+		},
+		{name: "should default to the bare versions", prefix: versionPrefixAuto, lines: noHeaders, expected: ""},
+		{name: "should override the prefixed headers", prefix: "", lines: prefixedHeaders, expected: ""},
+		{name: "should override the bare headers", prefix: versionPrefixV, lines: bareHeaders, expected: "v"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			repo := newTaggedRepo(t, test.tags...)
+
+			// Act
+			prefix := resolveVersionPrefix(&ProjectConfig{VersionPrefix: test.prefix}, test.lines, repo, standardLogEntry())
+
+			// Assert
+			assert.Equal(t, test.expected, prefix)
+		})
+	}
+}
+
+func TestProcessChangelog_WritesTheVersionPrefix(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(
+		strings.ReplaceAll(changelogOriginal, "## [1.0.1] - 1984-01-01", "## [v1.0.10] - 1984-01-01\n\n## [1.0.9] - 1983-01-01"),
+		"\n",
+	)
+	changelogConfig := ChangelogConfig{
+		VersionPrefix: resolveVersionPrefix(&ProjectConfig{VersionPrefix: versionPrefixAuto}, lines, nil, standardLogEntry()),
+		Date:          time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	version, updatedLines, err := processChangelog(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", versionString(version), "the prefix shouldn't be compared")
+	assert.Contains(t, updatedLines, "## [v1.1.0] - 2024-06-08")
+	assert.Contains(t, updatedLines, "## [v1.0.10] - 1984-01-01")
+}
+
+func TestFormatVersionHeader_DoesNotRepeatThePrefixOfTheTags(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	previousVersion, nextVersion := *semver.MustParse("1.0.1"), *semver.MustParse("1.1.0")
+	style := versionHeaderStyle{inlineLink: true, tagPrefix: "v"}
+	releaseDate := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	header := formatVersionHeader(
+		previousVersion, nextVersion, "https://github.com/user/repo", style, releaseDate, "v", standardLogEntry(),
+	)
+
+	// Assert
+	assert.Equal(t, "## [v1.1.0](https://github.com/user/repo/compare/v1.0.1...v1.1.0) - 2024-06-08", header)
+}
+
+func TestGetBumpBranchName_WritesTheVersionPrefix(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectConfig := &ProjectConfig{}
+	state := &projectState{resolvedVersionPrefix: versionPrefixV}
+
+	// Act
+	branchName := getBumpBranchName(&GlobalConfig{}, projectConfig, state, "1.1.0", standardLogEntry())
+	title := getCommitSubject(&GlobalConfig{}, projectConfig, state, "1.1.0", standardLogEntry())
+
+	// Assert
+	assert.Equal(t, "chore/bump-v1.1.0", branchName)
+	assert.Equal(t, "chore(bump): bumped version to v1.1.0", title)
+}
+
+func TestUpdateVersionFiles_KeepsThePrefixOfTheReplacedValue(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectPath := t.TempDir()
+	prefixedPath := filepath.Join(projectPath, "prefixed.yaml")
+	barePath := filepath.Join(projectPath, "bare.yaml")
+	require.NoError(t, os.WriteFile(prefixedPath, []byte("version: v1.0.1\n"), 0o600))
+	require.NoError(t, os.WriteFile(barePath, []byte("version: 1.0.1\n"), 0o600))
+	patterns := []string{`(version: )v?\d+\.\d+\.\d+()`}
+
+	// Act
+	_, err := updateVersionFiles(&GlobalConfig{}, []VersionFile{
+		{Path: prefixedPath, Patterns: patterns},
+		{Path: barePath, Patterns: patterns},
+	}, "1.1.0", standardLogEntry())
+
+	// Assert
+	require.NoError(t, err)
+	prefixed, err := os.ReadFile(prefixedPath)
+	require.NoError(t, err)
+	bare, err := os.ReadFile(barePath)
+	require.NoError(t, err)
+	assert.Equal(t, "version: v1.1.0\n", string(prefixed))
+	assert.Equal(t, "version: 1.1.0\n", string(bare))
+}
+
+func TestValidateVersionPrefix(t *testing.T) {
+	t.Parallel()
+
+	// Act & Assert
+	require.NoError(t, validateVersionPrefix(&ProjectConfig{VersionPrefix: versionPrefixAuto}))
+	require.NoError(t, validateVersionPrefix(&ProjectConfig{}))
+	require.ErrorIs(t, validateVersionPrefix(&ProjectConfig{VersionPrefix: "V"}), ErrInvalidVersionPrefix)
+}
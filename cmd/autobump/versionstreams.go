@@ -0,0 +1,384 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidVersionStream = errors.New("invalid version stream")
+	ErrUntaggedEntry        = errors.New("entry not tagged with any version stream")
+)
+
+// entryBulletRegex matches the top-level entries, capturing the bullet and the text
+var entryBulletRegex = regexp.MustCompile(`^([-*+]\s+)(.*)$`)
+
+// VersionStream is an independent version released from the same CHANGELOG (e.g. the app and its API)
+type VersionStream struct {
+	Name         string        `yaml:"name"`
+	HeaderPrefix string        `yaml:"header_prefix"`
+	EntryTag     string        `yaml:"entry_tag"`
+	VersionFiles []VersionFile `yaml:"version_files"`
+}
+
+// validateVersionStreams checks the names, prefixes and tags of the version streams of a project
+func validateVersionStreams(projectConfig *ProjectConfig) error {
+	names := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for index, stream := range projectConfig.VersionStreams {
+		if stream.Name == "" {
+			return fmt.Errorf("%w: version_streams[%d] has no name", ErrInvalidVersionStream, index)
+		}
+		prefix := getStreamHeaderPrefix(stream)
+		if names[stream.Name] || prefixes[prefix] {
+			return fmt.Errorf("%w: %q is declared twice", ErrInvalidVersionStream, stream.Name)
+		}
+		names[stream.Name] = true
+		prefixes[prefix] = true
+
+		if _, err := getStreamEntryTag(stream); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidVersionStream, err)
+		}
+	}
+
+	if projectConfig.DefaultVersionStream != "" && !names[projectConfig.DefaultVersionStream] {
+		return fmt.Errorf(
+			"%w: the default stream %q is not declared",
+			ErrInvalidVersionStream,
+			projectConfig.DefaultVersionStream,
+		)
+	}
+	return nil
+}
+
+// getStreamHeaderPrefix returns the prefix of the version headers of the stream (e.g. "app-" for "## [app-1.4.0]")
+func getStreamHeaderPrefix(stream VersionStream) string {
+	if stream.HeaderPrefix != "" {
+		return stream.HeaderPrefix
+	}
+	return stream.Name + "-"
+}
+
+// getStreamEntryTag returns the pattern of the tag at the start of the entries of the stream (e.g. "[app]")
+func getStreamEntryTag(stream VersionStream) (*regexp.Regexp, error) {
+	if stream.EntryTag == "" {
+		return regexp.MustCompile(`^\[` + regexp.QuoteMeta(stream.Name) + `\]\s*`), nil
+	}
+
+	entryTag, err := regexp.Compile(stream.EntryTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the entry tag of stream %q: %w", stream.Name, err)
+	}
+	return entryTag, nil
+}
+
+// findLatestStreamVersion finds the latest version released by the stream with the given header prefix
+func findLatestStreamVersion(lines []string, prefix string) (*semver.Version, error) {
+	var latestVersion *semver.Version
+	for _, line := range lines[getFrontMatterLength(lines):] {
+		match := versionHeaderRegex.FindStringSubmatch(line)
+		if match == nil || !strings.HasPrefix(match[1], prefix) {
+			continue
+		}
+
+		version, err := semver.NewVersion(strings.TrimPrefix(match[1], prefix))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing version '%s': %w", match[1], err)
+		}
+		if latestVersion == nil || version.GreaterThan(latestVersion) {
+			latestVersion = version
+		}
+	}
+
+	if latestVersion == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoVersionFoundInChangelog, strings.TrimSuffix(prefix, "-"))
+	}
+	return latestVersion, nil
+}
+
+// isReleaseHeader checks whether the line is the header of a released version of any stream
+func isReleaseHeader(line string) bool {
+	match := versionHeaderRegex.FindStringSubmatch(line)
+	return match != nil && match[1] != "Unreleased"
+}
+
+// getStreamsUnreleasedSummary builds the summary of the "Unreleased" section of a CHANGELOG with version streams
+func getStreamsUnreleasedSummary(lines []string, changelogConfig ChangelogConfig) UnreleasedSummary {
+	return summarizeUnreleased(lines, changelogConfig, isReleaseHeader)
+}
+
+// processVersionStreams releases the unreleased entries of each stream in its own section,
+// returning the next version of the streams with entries and the new CHANGELOG content
+func processVersionStreams(
+	lines []string,
+	changelogConfig ChangelogConfig,
+) (map[string]*semver.Version, []string, error) {
+	err := checkConflictMarkers(lines, changelogConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the front matter is kept as it is, above the processed content
+	frontMatter, lines := splitFrontMatter(lines)
+	start := -1
+	end := len(lines)
+	for index, line := range lines {
+		if start < 0 && strings.Contains(line, "[Unreleased]") {
+			start = index
+		} else if start >= 0 && isReleaseHeader(line) {
+			end = index
+			break
+		}
+	}
+	if start < 0 {
+		return nil, nil, ErrNoChangesFoundInUnreleased
+	}
+
+	// copy the section to avoid changing the original lines
+	unreleasedSection := append([]string(nil), lines[start+1:end]...)
+	fixSectionHeadings(unreleasedSection, getSectionAliases(changelogConfig))
+	if changelogConfig.NormalizeEntries {
+		normalizeEntries(unreleasedSection, changelogConfig.logger())
+	}
+
+	streamSections, err := splitUnreleasedByStream(unreleasedSection, changelogConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerStyle := detectVersionHeaderStyle(lines)
+	versions := make(map[string]*semver.Version)
+	var releasedSections []string
+	for _, stream := range changelogConfig.VersionStreams {
+		if len(streamSections[stream.Name]) == 0 {
+			continue
+		}
+
+		prefix := getStreamHeaderPrefix(stream)
+		previousVersion, err := findLatestStreamVersion(lines, prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		streamConfig := changelogConfig
+		streamConfig.VersionPrefix = prefix
+		section := append([]string{"## [Unreleased]"}, streamSections[stream.Name]...)
+		updatedSection, nextVersion, err := updateSection(section, *previousVersion, streamConfig, headerStyle)
+		if errors.Is(err, ErrNoChangesFoundInUnreleased) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to release stream %s: %w", stream.Name, err)
+		}
+		changelogConfig.logger().Infof("Next calculated version of stream %s: %s", stream.Name, versionString(nextVersion))
+
+		// the new "Unreleased" section is written only once, above all the released streams
+		if len(releasedSections) > 0 {
+			updatedSection = updatedSection[2:]
+		}
+		releasedSections = append(releasedSections, updatedSection...)
+		versions[stream.Name] = nextVersion
+	}
+
+	if len(versions) == 0 {
+		return nil, nil, ErrNoChangesFoundInUnreleased
+	}
+
+	newContent := make([]string, 0, len(lines)+len(releasedSections))
+	newContent = append(newContent, lines[:start]...)
+	newContent = append(newContent, releasedSections...)
+	newContent = append(newContent, lines[end:]...)
+	return versions, joinFrontMatter(frontMatter, newContent), nil
+}
+
+// splitUnreleasedByStream distributes the entries between the streams by their tags (which are removed),
+// keeping the section headings of each entry. The untagged entries go to the default stream, when set.
+func splitUnreleasedByStream(unreleasedSection []string, changelogConfig ChangelogConfig) (map[string][]string, error) {
+	entryTags := make(map[string]*regexp.Regexp)
+	for _, stream := range changelogConfig.VersionStreams {
+		entryTag, err := getStreamEntryTag(stream)
+		if err != nil {
+			return nil, err
+		}
+		entryTags[stream.Name] = entryTag
+	}
+
+	streamSections := make(map[string][]string)
+	lastHeadings := make(map[string]string)
+	currentHeading := ""
+	currentStream := ""
+	for _, line := range unreleasedSection {
+		trimmedLine := strings.TrimSpace(line)
+		if trimmedLine == "" || trimmedLine == "-" {
+			continue
+		}
+		if strings.HasPrefix(trimmedLine, "#") {
+			currentHeading = trimmedLine
+			currentStream = ""
+			continue
+		}
+
+		// the lines that are not top-level entries (e.g. nested bullets) belong to the previous entry
+		if match := entryBulletRegex.FindStringSubmatch(line); match != nil {
+			currentStream = ""
+			for _, stream := range changelogConfig.VersionStreams {
+				location := entryTags[stream.Name].FindStringIndex(match[2])
+				if location != nil {
+					currentStream = stream.Name
+					line = match[1] + strings.TrimSpace(match[2][:location[0]]+match[2][location[1]:])
+					break
+				}
+			}
+			if currentStream == "" {
+				currentStream = changelogConfig.DefaultVersionStream
+			}
+		}
+		if currentStream == "" {
+			return nil, fmt.Errorf("%w: %s", ErrUntaggedEntry, trimmedLine)
+		}
+
+		if lastHeadings[currentStream] != currentHeading {
+			streamSections[currentStream] = append(streamSections[currentStream], currentHeading)
+			lastHeadings[currentStream] = currentHeading
+		}
+		streamSections[currentStream] = append(streamSections[currentStream], line)
+	}
+	return streamSections, nil
+}
+
+// formatStreamVersions joins the versions released by each stream (e.g. "app-1.5.0_api-2.2.0"),
+// naming the release in the branch, the commit and the pull request
+func formatStreamVersions(streams []VersionStream, versions map[string]*semver.Version) string {
+	var names []string
+	for _, stream := range streams {
+		if version, exists := versions[stream.Name]; exists {
+			names = append(names, getStreamHeaderPrefix(stream)+versionString(version))
+		}
+	}
+	return strings.Join(names, "_")
+}
+
+// getNextReleaseName returns the next version, or the next versions of the streams when the project has any
+func getNextReleaseName(ctx *RepoContext, changelogPath string) (string, error) {
+	if len(ctx.projectConfig.VersionStreams) == 0 {
+		nextVersion, err := getNextVersion(ctx, changelogPath)
+		if err != nil {
+			return "", err
+		}
+		return versionString(nextVersion), nil
+	}
+
+	lines, err := readChangelogLines(ctx, changelogPath)
+	if err != nil {
+		return "", err
+	}
+
+	versions, _, err := processVersionStreams(lines, getChangelogConfig(ctx))
+	if err != nil {
+		return "", err
+	}
+	return formatStreamVersions(ctx.projectConfig.VersionStreams, versions), nil
+}
+
+// updateStreamsChangelogAndVersionFiles releases the streams in the CHANGELOG and updates their version files,
+// the version files of the language receive the version of the default stream (if released)
+func updateStreamsChangelogAndVersionFiles(ctx *RepoContext, changelogPath string) error {
+	lines, err := readLines(changelogPath, getMaxFileSize(ctx.globalConfig))
+	if err != nil {
+		return err
+	}
+
+	versions, newContent, err := processVersionStreams(lines, getChangelogConfig(ctx))
+	if err != nil {
+		return err
+	}
+
+	newContent, err = archiveChangelogReleases(ctx, changelogPath, newContent)
+	if err != nil {
+		return err
+	}
+
+	err = writeLines(changelogPath, newContent)
+	if err != nil {
+		return err
+	}
+
+	ctx.projectConfig.NewVersion = formatStreamVersions(ctx.projectConfig.VersionStreams, versions)
+	ctx.logger().Infof("Updating versions to %s", ctx.projectConfig.NewVersion)
+
+	for _, stream := range ctx.projectConfig.VersionStreams {
+		version, released := versions[stream.Name]
+		if !released {
+			continue
+		}
+
+		var versionFiles []VersionFile
+		versionFiles, err = getStreamVersionFiles(ctx.globalConfig, ctx.projectConfig, stream, ctx.logger())
+		if err != nil {
+			return err
+		}
+
+		if stream.Name == ctx.projectConfig.DefaultVersionStream {
+			var languageVersionFiles []VersionFile
+			languageVersionFiles, err = getVersionFiles(ctx.globalConfig, ctx.projectConfig, &ctx.state, ctx.logger())
+			if err != nil {
+				return err
+			}
+			versionFiles = append(versionFiles, languageVersionFiles...)
+		}
+
+		_, err = updateVersionFiles(ctx.globalConfig, versionFiles, versionString(version), ctx.logger())
+		if err != nil {
+			return err
+		}
+
+		for _, versionFile := range versionFiles {
+			err = addFileToWorktree(ctx, versionFile.Path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = addChangelogToWorktree(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+	return addChangelogArchiveToWorktree(ctx)
+}
+
+// getStreamVersionFiles returns the version files of the stream, relative to the project
+func getStreamVersionFiles(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	stream VersionStream,
+	logger *log.Entry,
+) ([]VersionFile, error) {
+	var versionFiles []VersionFile
+	for _, versionFile := range stream.VersionFiles {
+		matches, err := filepath.Glob(filepath.Join(projectConfig.Path, versionFile.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get version files: %w", err)
+		}
+		for _, match := range matches {
+			_, err = ensureWithinRepo(projectConfig.Path, match)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version file %q of stream %s: %w", versionFile.Path, stream.Name, err)
+			}
+
+			err = checkFileSize(match, getMaxFileSize(globalConfig))
+			if err != nil {
+				logger.Warnf("Skipping version file: %v", err)
+				continue
+			}
+			versionFiles = append(versionFiles, VersionFile{Path: match, Patterns: versionFile.Patterns})
+		}
+	}
+	return versionFiles, nil
+}
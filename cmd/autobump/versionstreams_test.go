@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogWithStreams = changelogTemplate + `
+
+### Added
+
+- [app] Added the dark mode.
+
+### Fixed
+
+- [api] Fixed the pagination of the users endpoint.
+- [app] Fixed the login form.
+
+## [api-2.1.0] - 2024-02-01
+
+### Added
+
+- Added the users endpoint.
+
+## [app-1.4.0] - 2024-01-01
+
+### Added
+
+- Added the login form.`
+
+var testVersionStreams = []VersionStream{{Name: "app"}, {Name: "api"}}
+
+func TestProcessVersionStreams_BothStreams(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithStreams, "\n")
+	changelogConfig := ChangelogConfig{VersionStreams: testVersionStreams}
+	date := time.Now().Format("2006-01-02")
+
+	// Act
+	versions, newContent, err := processVersionStreams(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.0", versions["app"].String())
+	assert.Equal(t, "2.1.1", versions["api"].String())
+	assert.Equal(t, "app-1.5.0_api-2.1.1", formatStreamVersions(testVersionStreams, versions))
+	assert.Equal(t, changelogTemplate+fmt.Sprintf(`
+
+## [app-1.5.0] - %[1]s
+
+### Added
+
+- Added the dark mode.
+
+### Fixed
+
+- Fixed the login form.
+
+## [api-2.1.1] - %[1]s
+
+### Fixed
+
+- Fixed the pagination of the users endpoint.
+
+## [api-2.1.0] - 2024-02-01
+
+### Added
+
+- Added the users endpoint.
+
+## [app-1.4.0] - 2024-01-01
+
+### Added
+
+- Added the login form.`, date), strings.Join(newContent, "\n"))
+}
+
+func TestProcessVersionStreams_OnlyOneStream(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Replace(changelogWithStreams, `### Fixed
+
+- [api] Fixed the pagination of the users endpoint.
+- [app] Fixed the login form.
+
+`, "", 1)
+	lines := strings.Split(changelog, "\n")
+	changelogConfig := ChangelogConfig{VersionStreams: testVersionStreams}
+
+	// Act
+	versions, newContent, err := processVersionStreams(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+	assert.Equal(t, "1.5.0", versions["app"].String())
+	assert.Contains(t, newContent, "## [app-1.5.0] - "+time.Now().Format("2006-01-02"))
+	assert.NotContains(t, strings.Join(newContent, "\n"), "## [api-2.1.1]")
+}
+
+func TestProcessVersionStreams_UntaggedEntryGoesToTheDefaultStream(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Replace(changelogWithStreams, "- [app] Added the dark mode.", "- Added the dark mode.", 1)
+	lines := strings.Split(changelog, "\n")
+	changelogConfig := ChangelogConfig{VersionStreams: testVersionStreams, DefaultVersionStream: "app"}
+
+	// Act
+	versions, _, err := processVersionStreams(lines, changelogConfig)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.0", versions["app"].String())
+}
+
+func TestProcessVersionStreams_UntaggedEntryWithoutDefaultStream(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	changelog := strings.Replace(changelogWithStreams, "- [app] Added the dark mode.", "- Added the dark mode.", 1)
+	lines := strings.Split(changelog, "\n")
+	changelogConfig := ChangelogConfig{VersionStreams: testVersionStreams}
+
+	// Act
+	_, _, err := processVersionStreams(lines, changelogConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrUntaggedEntry)
+	assert.Contains(t, err.Error(), "Added the dark mode.")
+}
+
+func TestGetStreamsUnreleasedSummary(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lines := strings.Split(changelogWithStreams, "\n")
+
+	// Act
+	summary := getStreamsUnreleasedSummary(lines, ChangelogConfig{})
+
+	// Assert
+	assert.False(t, summary.Empty)
+	assert.Equal(t, 1, summary.SectionCounts["Added"])
+	assert.Equal(t, 2, summary.SectionCounts["Fixed"])
+}
+
+func TestValidateVersionStreams_UnknownDefaultStream(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	projectConfig := &ProjectConfig{VersionStreams: testVersionStreams, DefaultVersionStream: "web"}
+
+	// Act
+	err := validateVersionStreams(projectConfig)
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidVersionStream)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// yankedMarker is appended to the header of the yanked releases, as Keep a Changelog suggests
+const yankedMarker = "[YANKED]"
+
+var ErrVersionNotInChangelog = errors.New("version not found in the CHANGELOG")
+
+// yankRelease marks the release of the version as yanked: its header receives the marker and the reason
+// is written in italics right below it. Nothing else is changed, and a release already yanked is kept as it is,
+// in which case false is returned.
+func yankRelease(lines []string, version string, reason string) ([]string, bool, error) {
+	for index, line := range lines {
+		match := versionHeaderRegex.FindStringSubmatch(line)
+		if match == nil || match[1] != version || !isReleaseHeader(line) {
+			continue
+		}
+		if strings.HasSuffix(strings.TrimSpace(line), yankedMarker) {
+			return lines, false, nil
+		}
+
+		yanked := make([]string, 0, len(lines)+2)
+		yanked = append(yanked, lines[:index]...)
+		yanked = append(yanked, strings.TrimRight(line, " \t")+" "+yankedMarker)
+		if reason = strings.TrimSpace(reason); reason != "" {
+			yanked = append(yanked, "", "_"+reason+"_")
+		}
+		yanked = append(yanked, lines[index+1:]...)
+		return yanked, true, nil
+	}
+	return nil, false, fmt.Errorf("%w: %s", ErrVersionNotInChangelog, version)
+}
+
+// formatYankNotes describes the yanked release in the pull request
+func formatYankNotes(version string, reason string) string {
+	if reason == "" {
+		return fmt.Sprintf("This pull request marks the release %s as yanked.", version)
+	}
+	return fmt.Sprintf("This pull request marks the release %s as yanked: %s", version, reason)
+}
+
+// getCommitSubject returns the subject of the bump commit, which is also the title of the pull request
+func getCommitSubject(
+	globalConfig *GlobalConfig,
+	projectConfig *ProjectConfig,
+	state *projectState,
+	newVersion string,
+	logger *log.Entry,
+) string {
+	if state.commitSubject != "" {
+		return state.commitSubject
+	}
+	if state.yankNotes != "" {
+		return "chore(yank): yanked version " + newVersion
+	}
+	return getBumpCommitSubject(globalConfig, projectConfig, state, newVersion, logger)
+}
+
+// runYank marks a release of the project as yanked in its CHANGELOG, in the commit of a "chore/yank-{version}"
+// branch with its pull request. The project is only changed when the release exists and isn't yanked yet.
+func runYank(globalConfig *GlobalConfig, projectConfig *ProjectConfig, version string, reason string) error {
+	ctx := &RepoContext{
+		globalConfig:  globalConfig,
+		projectConfig: projectConfig,
+		timer:         newPhaseTimer(standardLogEntry()),
+	}
+
+	var err error
+	ctx.globalGitConfig, err = getGlobalGitConfig()
+	if err != nil {
+		return err
+	}
+	err = setupRepo(ctx)
+	if err != nil {
+		return err
+	}
+
+	changelogPath := findChangelogPath(projectConfig, ctx.logger())
+	lines, err := readLines(changelogPath, getMaxFileSize(globalConfig))
+	if err != nil {
+		return err
+	}
+	yankedLines, changed, err := yankRelease(lines, version, reason)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		ctx.logger().Infof("The release %s is already yanked", version)
+		return nil
+	}
+
+	branchName := "chore/yank-" + version
+	branchExists, err := checkBranchExists(ctx.repo, branchName)
+	if err != nil {
+		return err
+	}
+	if branchExists {
+		return fmt.Errorf("%w: %s", ErrBranchExists, branchName)
+	}
+	err = createAndSwitchBranch(ctx.repo, ctx.worktree, branchName, ctx.head.Hash(), ctx.logger())
+	if err != nil {
+		return err
+	}
+
+	ctx.logger().Infof("Marking the release %s as yanked", version)
+	err = writeLines(changelogPath, yankedLines)
+	if err != nil {
+		return err
+	}
+	err = addChangelogToWorktree(ctx, changelogPath)
+	if err != nil {
+		return err
+	}
+
+	projectConfig.NewVersion = version
+	ctx.state.yankNotes = formatYankNotes(version, strings.TrimSpace(reason))
+	err = commitAndPushChanges(ctx, branchName)
+	if err != nil {
+		return err
+	}
+	return createAndCheckoutPullRequest(ctx, branchName)
+}
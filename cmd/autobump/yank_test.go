@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var yankChangelog = []string{
+	"# Changelog",
+	"",
+	"## [Unreleased]",
+	"",
+	"## [1.4.2] - 2026-03-02",
+	"",
+	"### Fixed",
+	"",
+	"- fixed the retries",
+	"",
+	"## [1.4.1] - 2026-02-20",
+	"",
+	"### Fixed",
+	"",
+	"- fixed the timeouts",
+}
+
+func TestYankRelease_MarksTheHeaderAndWritesTheReason(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	lines, changed, err := yankRelease(yankChangelog, "1.4.2", "  broke the retries of the uploads ")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, changed)
+	expected := []string{
+		"# Changelog",
+		"",
+		"## [Unreleased]",
+		"",
+		"## [1.4.2] - 2026-03-02 [YANKED]",
+		"",
+		"_broke the retries of the uploads_",
+		"",
+		"### Fixed",
+		"",
+		"- fixed the retries",
+		"",
+		"## [1.4.1] - 2026-02-20",
+		"",
+		"### Fixed",
+		"",
+		"- fixed the timeouts",
+	}
+	assert.Equal(t, expected, lines)
+	assert.Equal(t, "## [1.4.2] - 2026-03-02", yankChangelog[4], "the original lines should be kept")
+}
+
+func TestYankRelease_WithoutReason(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	lines, changed, err := yankRelease(yankChangelog, "1.4.1", "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "## [1.4.1] - 2026-02-20 [YANKED]", lines[10])
+	assert.Len(t, lines, len(yankChangelog))
+}
+
+func TestYankRelease_IsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	yanked, _, err := yankRelease(yankChangelog, "1.4.2", "broke the retries")
+	require.NoError(t, err)
+
+	// Act
+	lines, changed, err := yankRelease(yanked, "1.4.2", "broke the retries")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, yanked, lines)
+}
+
+func TestYankRelease_RefusesUnknownVersions(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, _, unreleasedErr := yankRelease(yankChangelog, "Unreleased", "")
+	_, _, missingErr := yankRelease(yankChangelog, "1.5.0", "")
+
+	// Assert
+	require.ErrorIs(t, unreleasedErr, ErrVersionNotInChangelog)
+	require.ErrorIs(t, missingErr, ErrVersionNotInChangelog)
+	assert.Contains(t, missingErr.Error(), "1.5.0")
+}
+
+func TestGetCommitSubject(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	bump := getCommitSubject(&GlobalConfig{}, &ProjectConfig{}, &projectState{}, "1.5.0", standardLogEntry())
+	yank := getCommitSubject(
+		&GlobalConfig{}, &ProjectConfig{}, &projectState{yankNotes: formatYankNotes("1.4.2", "")}, "1.4.2",
+		standardLogEntry(),
+	)
+
+	// Assert
+	assert.Equal(t, "chore(bump): bumped version to 1.5.0", bump)
+	assert.Equal(t, "chore(yank): yanked version 1.4.2", yank)
+}